@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -173,6 +174,7 @@ type LocalActionsCache struct {
 	proj  *Project // might be nil
 	cache map[string]*ActionMetadata
 	dbg   io.Writer
+	read  func(string) ([]byte, error) // os.ReadFile when nil
 }
 
 // NewLocalActionsCache creates new LocalActionsCache instance for the given project.
@@ -252,10 +254,35 @@ func (c *LocalActionsCache) FindMetadata(spec string) (*ActionMetadata, bool, er
 	return &meta, false, nil
 }
 
+// compositeActions returns metadata of composite actions found in the cache. They are sorted by
+// their file paths and each action appears only once even if it was found with multiple specs.
+func (c *LocalActionsCache) compositeActions() []*ActionMetadata {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := map[string]struct{}{}
+	ret := []*ActionMetadata{}
+	for _, m := range c.cache {
+		if m == nil || m.Runs.Using != "composite" {
+			continue
+		}
+		p := m.Path()
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Path() < ret[j].Path()
+	})
+	return ret
+}
+
 func (c *LocalActionsCache) readLocalActionMetadataFile(dir string) ([]byte, string, bool) {
 	for _, f := range []string{"action.yaml", "action.yml"} {
 		p := filepath.Join(dir, f)
-		if b, err := os.ReadFile(p); err == nil {
+		if b, err := c.readFile(p); err == nil {
 			return b, f, true
 		}
 	}
@@ -263,6 +290,13 @@ func (c *LocalActionsCache) readLocalActionMetadataFile(dir string) ([]byte, str
 	return nil, "", false
 }
 
+func (c *LocalActionsCache) readFile(path string) ([]byte, error) {
+	if c.read != nil {
+		return c.read(path)
+	}
+	return os.ReadFile(path)
+}
+
 // LocalActionsCacheFactory is a factory to create LocalActionsCache instances. LocalActionsCache
 // should be created for each repositories. LocalActionsCacheFactory creates new LocalActionsCache
 // instance per repository (project).
@@ -287,6 +321,20 @@ func (f *LocalActionsCacheFactory) GetCache(p *Project) *LocalActionsCache {
 	return c
 }
 
+// all returns all caches created by the factory sorted by the root directories of their projects.
+func (f *LocalActionsCacheFactory) all() []*LocalActionsCache {
+	rs := make([]string, 0, len(f.caches))
+	for r := range f.caches {
+		rs = append(rs, r)
+	}
+	sort.Strings(rs)
+	ret := make([]*LocalActionsCache, 0, len(rs))
+	for _, r := range rs {
+		ret = append(ret, f.caches[r])
+	}
+	return ret
+}
+
 // NewLocalActionsCacheFactory creates a new LocalActionsCacheFactory instance.
 func NewLocalActionsCacheFactory(dbg io.Writer) *LocalActionsCacheFactory {
 	return &LocalActionsCacheFactory{map[string]*LocalActionsCache{}, dbg}
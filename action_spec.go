@@ -0,0 +1,191 @@
+package actionlint
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ActionSpecKind is kind of action specification at "uses:" of step.
+type ActionSpecKind int
+
+const (
+	// ActionSpecKindRepository is kind of action in GitHub repository like "{owner}/{repo}@{ref}" or
+	// "{owner}/{repo}/{path}@{ref}".
+	ActionSpecKindRepository ActionSpecKind = iota
+	// ActionSpecKindLocal is kind of action in the same repository like "./path/to/action".
+	ActionSpecKindLocal
+	// ActionSpecKindDocker is kind of Docker image action like "docker://alpine:3.8".
+	ActionSpecKindDocker
+)
+
+func (k ActionSpecKind) String() string {
+	switch k {
+	case ActionSpecKindRepository:
+		return "repository"
+	case ActionSpecKindLocal:
+		return "local"
+	case ActionSpecKindDocker:
+		return "docker"
+	default:
+		panic("unreachable")
+	}
+}
+
+// ActionSpec is a parsed action specification at "uses:" of step.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#jobsjob_idstepsuses
+type ActionSpec struct {
+	// Kind is kind of the action specification.
+	Kind ActionSpecKind
+	// Owner is an owner of the repository. This field is only set for repository actions.
+	Owner string
+	// Repo is a name of the repository. This field is only set for repository actions.
+	Repo string
+	// Path is a path to the directory of the action. For repository actions, it is a path in the
+	// repository and it is empty when the action is at the root of the repository. For local
+	// actions, it is a path relative to the repository root starting with "./".
+	Path string
+	// Ref is a ref of the repository like a branch name, a tag name, or a commit SHA. This field is
+	// only set for repository actions.
+	Ref string
+	// Image is a reference of the Docker image without "docker://" prefix like "alpine:3.8". This
+	// field is only set for Docker actions.
+	Image string
+}
+
+// IsCommitSHA returns true when the ref of the action is a full-length commit SHA.
+func (s *ActionSpec) IsCommitSHA() bool {
+	return reFullCommitSHA.MatchString(s.Ref)
+}
+
+// String returns the string representation of the specification which can be used at "uses:".
+func (s *ActionSpec) String() string {
+	switch s.Kind {
+	case ActionSpecKindLocal:
+		return s.Path
+	case ActionSpecKindDocker:
+		return "docker://" + s.Image
+	default:
+		r := s.Owner + "/" + s.Repo
+		if s.Path != "" {
+			r += "/" + s.Path
+		}
+		return r + "@" + s.Ref
+	}
+}
+
+// Owner names allow alphanumeric characters and single hyphens between them
+// https://docs.github.com/en/enterprise-cloud@latest/admin/identity-and-access-management/iam-configuration-reference/username-considerations-for-external-authentication
+var reActionOwner = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9]|-[a-zA-Z0-9])*$`)
+
+// Repository names allow alphanumeric characters, '-', '_', and '.'
+var reActionRepo = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+var (
+	reFullCommitSHA  = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	reShortCommitSHA = regexp.MustCompile(`^[0-9a-f]{7,39}$`)
+)
+
+// ParseActionSpec parses the action specification at "uses:" of step. Specifications containing
+// ${{ }} cannot be parsed. The error message describes why the specification is invalid.
+func ParseActionSpec(spec string) (*ActionSpec, error) {
+	if strings.HasPrefix(spec, "./") {
+		return parseLocalActionSpec(spec)
+	}
+	if strings.HasPrefix(spec, "docker://") {
+		i := strings.TrimPrefix(spec, "docker://")
+		if i == "" {
+			return nil, errors.New("image is missing")
+		}
+		return &ActionSpec{Kind: ActionSpecKindDocker, Image: i}, nil
+	}
+	if strings.HasPrefix(spec, ".") {
+		// Owner never starts with '.'. It is likely a path of local action like "../foo" or ".github/foo"
+		return nil, errors.New("path of local action must start with \"./\"")
+	}
+	return parseRepoActionSpec(spec)
+}
+
+// Parse ./{path}
+func parseLocalActionSpec(spec string) (*ActionSpec, error) {
+	if strings.ContainsRune(spec, '@') {
+		return nil, errors.New("ref cannot be specified for local action. local action is always at the same commit as the workflow")
+	}
+	if p := path.Clean(spec); p == ".." || strings.HasPrefix(p, "../") {
+		return nil, fmt.Errorf("path %q escapes the repository", p)
+	}
+	return &ActionSpec{Kind: ActionSpecKindLocal, Path: spec}, nil
+}
+
+// Parse {owner}/{repo}@{ref} or {owner}/{repo}/{path}@{ref}
+func parseRepoActionSpec(spec string) (*ActionSpec, error) {
+	s, ref, ok := strings.Cut(spec, "@")
+	if !ok {
+		return nil, errors.New("ref is missing")
+	}
+
+	owner, s, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil, errors.New("owner is missing")
+	}
+	repo, p, _ := strings.Cut(s, "/")
+
+	if owner == "" || repo == "" || ref == "" {
+		return nil, errors.New("owner and repo and ref should not be empty")
+	}
+	if len(owner) > 39 || !reActionOwner.MatchString(owner) {
+		return nil, fmt.Errorf("owner %q is invalid. owner name can only contain alphanumeric characters and single hyphens, cannot begin or end with a hyphen, and must be at most 39 characters", owner)
+	}
+	if len(repo) > 100 || repo == "." || repo == ".." || !reActionRepo.MatchString(repo) {
+		return nil, fmt.Errorf("repository name %q is invalid. repository name can only contain alphanumeric characters, \"-\", \"_\", and \".\"", repo)
+	}
+	if p != "" {
+		for _, c := range strings.Split(p, "/") {
+			if c == "" || c == "." || c == ".." {
+				return nil, fmt.Errorf("path %q in the repository is invalid. it must not contain empty, \".\", or \"..\" components", p)
+			}
+		}
+	}
+	if err := validateActionRef(ref); err != nil {
+		return nil, err
+	}
+
+	return &ActionSpec{
+		Kind:  ActionSpecKindRepository,
+		Owner: owner,
+		Repo:  repo,
+		Path:  p,
+		Ref:   ref,
+	}, nil
+}
+
+// validateActionRef validates the ref following the rules of `git check-ref-format`.
+// https://git-scm.com/docs/git-check-ref-format
+func validateActionRef(ref string) error {
+	if i := strings.IndexFunc(ref, func(r rune) bool { return r <= ' ' || r == 0x7f }); i >= 0 {
+		return fmt.Errorf("ref %q must not contain whitespaces or control characters", ref)
+	}
+	if i := strings.IndexAny(ref, "~^:?*[\\"); i >= 0 {
+		return fmt.Errorf("ref %q must not contain %q", ref, ref[i:i+1])
+	}
+	for _, s := range []string{"..", "@{", "//"} {
+		if strings.Contains(ref, s) {
+			return fmt.Errorf("ref %q must not contain %q", ref, s)
+		}
+	}
+	if strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".") {
+		return fmt.Errorf("ref %q must not start with \"/\" nor end with \"/\" or \".\"", ref)
+	}
+	for _, c := range strings.Split(ref, "/") {
+		if strings.HasPrefix(c, ".") || strings.HasSuffix(c, ".lock") {
+			return fmt.Errorf("component %q of ref %q must not start with \".\" nor end with \".lock\"", c, ref)
+		}
+	}
+	// Branches and tags are rarely named with only hex digits. Short SHA is not resolved by GitHub
+	if reShortCommitSHA.MatchString(ref) && strings.ContainsAny(ref, "abcdef") {
+		return fmt.Errorf("ref %q looks like a short commit SHA. GitHub only resolves full-length commit SHA", ref)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestActionSpecParseOK(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  *ActionSpec
+	}{
+		{
+			input: "actions/checkout@v4",
+			want:  &ActionSpec{Kind: ActionSpecKindRepository, Owner: "actions", Repo: "checkout", Ref: "v4"},
+		},
+		{
+			input: "github/codeql-action/init@v3",
+			want:  &ActionSpec{Kind: ActionSpecKindRepository, Owner: "github", Repo: "codeql-action", Path: "init", Ref: "v3"},
+		},
+		{
+			input: "owner/repo.js/path/to/action@releases/v1.2",
+			want:  &ActionSpec{Kind: ActionSpecKindRepository, Owner: "owner", Repo: "repo.js", Path: "path/to/action", Ref: "releases/v1.2"},
+		},
+		{
+			input: "actions/checkout@8e5e7e5ab8b370d6c329ec480221332ada57f0ab",
+			want:  &ActionSpec{Kind: ActionSpecKindRepository, Owner: "actions", Repo: "checkout", Ref: "8e5e7e5ab8b370d6c329ec480221332ada57f0ab"},
+		},
+		{
+			input: "owner/repo@1234567",
+			want:  &ActionSpec{Kind: ActionSpecKindRepository, Owner: "owner", Repo: "repo", Ref: "1234567"},
+		},
+		{
+			input: "./.github/actions/my-action",
+			want:  &ActionSpec{Kind: ActionSpecKindLocal, Path: "./.github/actions/my-action"},
+		},
+		{
+			input: "./",
+			want:  &ActionSpec{Kind: ActionSpecKindLocal, Path: "./"},
+		},
+		{
+			input: "docker://alpine:3.8",
+			want:  &ActionSpec{Kind: ActionSpecKindDocker, Image: "alpine:3.8"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			s, err := ParseActionSpec(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, s); diff != "" {
+				t.Fatal(diff)
+			}
+			if have := s.String(); have != tc.input {
+				t.Fatalf("string representation %q is not the same as input %q", have, tc.input)
+			}
+		})
+	}
+}
+
+func TestActionSpecParseError(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{"actions/checkout", "ref is missing"},
+		{"checkout@v4", "owner is missing"},
+		{"actions/@v4", "should not be empty"},
+		{"-actions/checkout@v4", `owner "-actions" is invalid`},
+		{"my--org/checkout@v4", `owner "my--org" is invalid`},
+		{"my_org/checkout@v4", `owner "my_org" is invalid`},
+		{"actions/check out@v4", `repository name "check out" is invalid`},
+		{"actions/../checkout@v4", `repository name ".." is invalid`},
+		{"owner/repo//path@v1", `path "/path" in the repository is invalid`},
+		{"owner/repo/path/../other@v1", `path "path/../other" in the repository is invalid`},
+		{"owner/repo/path/@v1", `path "path/" in the repository is invalid`},
+		{"actions/checkout@v4 ", "must not contain whitespaces"},
+		{"actions/checkout@v4..5", `must not contain ".."`},
+		{"actions/checkout@v4^", `must not contain "^"`},
+		{"actions/checkout@v4.", `must not start with "/" nor end with "/" or "."`},
+		{"actions/checkout@releases/.v4", `component ".v4" of ref`},
+		{"actions/checkout@v4.lock", `component "v4.lock" of ref`},
+		{"actions/checkout@8e5e7e5", "looks like a short commit SHA"},
+		{"./foo@v1", "ref cannot be specified for local action"},
+		{"./foo/../../bar", `path "../bar" escapes the repository`},
+		{"../foo", `must start with "./"`},
+		{".github/actions/foo", `must start with "./"`},
+		{"docker://", "image is missing"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			s, err := ParseActionSpec(tc.input)
+			if err == nil {
+				t.Fatalf("error did not occur: %#v", s)
+			}
+			if msg := err.Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
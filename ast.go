@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,12 +16,35 @@ type Pos struct {
 	Line int
 	// Col is a column number of the position. This value is 1-based.
 	Col int
+	// Offset is a byte offset of the position from the beginning of the source. This value is
+	// 1-based as Line and Col so that 0 means the offset is unknown. Positions in the syntax tree
+	// returned from the parser have their offsets. Use ByteOffset method to get the 0-based offset.
+	Offset int
 }
 
 func (p *Pos) String() string {
 	return fmt.Sprintf("line:%d,col:%d", p.Line, p.Col)
 }
 
+// ByteOffset returns the 0-based byte offset of the position from the beginning of the source. The
+// second return value is false when the offset is unknown.
+func (p *Pos) ByteOffset() (int, bool) {
+	if p.Offset <= 0 {
+		return 0, false
+	}
+	return p.Offset - 1, true
+}
+
+// advance returns the position moved forward by the text in the same line. The offset is also
+// moved when it is known. The text must not contain newlines.
+func (p *Pos) advance(text string) *Pos {
+	ret := &Pos{Line: p.Line, Col: p.Col + utf8.RuneCountInString(text)}
+	if p.Offset > 0 {
+		ret.Offset = p.Offset + len(text)
+	}
+	return ret
+}
+
 // IsBefore returns if the position is before the other position. If they are equal, this function returns false.
 func (p *Pos) IsBefore(other *Pos) bool {
 	if p.Line < other.Line {
@@ -438,6 +462,9 @@ type Input struct {
 type ExecAction struct {
 	// https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_idstepsuses
 	Uses *String
+	// Spec is the parsed specification at 'uses'. This field is nil when the specification is
+	// invalid or contains ${{ }}.
+	Spec *ActionSpec
 	// Inputs represents inputs to the action to execute in 'with' section. Keys are in lower case since they are case-insensitive.
 	Inputs map[string]*Input
 	// Entrypoint represents optional 'entrypoint' field in 'with' section. Nil field means nothing specified
@@ -890,6 +917,9 @@ type Job struct {
 	// WorkflowCall is a workflow call by 'uses:'.
 	// https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_iduses
 	WorkflowCall *WorkflowCall
+	// Broken is true when the job could not be parsed due to a YAML syntax error in it. Only ID and
+	// Pos are set to a broken job. It is a placeholder to check other jobs which depend on it.
+	Broken bool
 	// Pos is a position in source.
 	Pos *Pos
 }
@@ -918,6 +948,10 @@ type Workflow struct {
 	Concurrency *Concurrency
 	// Jobs is mappings from job ID to the job object. Keys are in lower case since they are case-insensitive.
 	Jobs map[string]*Job
+	// Aliases is a list of YAML aliases used in the workflow source. Value of each element is the
+	// name of the anchor and Pos is the position where the alias is used. The aliases and merge keys
+	// were already expanded by the parser.
+	Aliases []*String
 }
 
 // FindWorkflowCallEvent returns workflow_call event node if exists
@@ -1,6 +1,9 @@
 package actionlint
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestStringIsExpressionAssigned(t *testing.T) {
 	tests := []struct {
@@ -57,3 +60,34 @@ func TestStringContainsExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestPosByteOffsetFromParser(t *testing.T) {
+	src := "on: push\r\njobs:\n  # コメント\n  test:\n    runs-on: 'ubuntu-latest'\n    steps:\n      - run: echo\n"
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal("unexpected errors:", errs)
+	}
+
+	for _, tc := range []struct {
+		what string
+		pos  *Pos
+		want string
+	}{
+		{"job ID", w.Jobs["test"].ID.Pos, "test:"},
+		{"quoted runs-on", w.Jobs["test"].RunsOn.Labels[0].Pos, "'ubuntu-latest'"},
+		{"run", w.Jobs["test"].Steps[0].Exec.(*ExecRun).Run.Pos, "echo"},
+	} {
+		o, ok := tc.pos.ByteOffset()
+		if !ok {
+			t.Fatalf("offset of %s is unknown: %#v", tc.what, tc.pos)
+		}
+		if have := src[o:]; !strings.HasPrefix(have, tc.want) {
+			t.Errorf("source at offset %d of %s should start with %q but got %q", o, tc.what, tc.want, have)
+		}
+	}
+
+	p := &Pos{Line: 1, Col: 1}
+	if o, ok := p.ByteOffset(); ok {
+		t.Fatalf("offset should be unknown but got %d", o)
+	}
+}
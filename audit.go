@@ -0,0 +1,497 @@
+package actionlint
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// AuditRepository is a result of linting workflows in one repository while auditing many
+// repositories.
+type AuditRepository struct {
+	// Name is a path of the repository relative to the root directory of the audit. The path
+	// separator is always "/".
+	Name string
+	// Files is a number of workflow files linted in the repository.
+	Files int
+	// Errors is a list of errors found in the workflow files of the repository.
+	Errors []*Error
+	// Rules is numbers of the errors broken down by rule names. The counts are sorted in
+	// descending order.
+	Rules []ReportCount
+	// Failure is an error message when the repository could not be linted. For example, when its
+	// config file is broken. Empty when the repository was linted successfully.
+	Failure string
+}
+
+// AuditReport is an aggregation of errors in many repositories. It is useful to see the status of
+// workflows across an organization.
+type AuditReport struct {
+	// Repositories is a list of all audited repositories sorted by the numbers of errors in
+	// descending order. Top entries are the top offenders.
+	Repositories []*AuditRepository
+	// Rules is numbers of errors in all repositories broken down by rule names. The counts are
+	// sorted in descending order.
+	Rules []ReportCount
+	// Total is a total number of errors.
+	Total int
+	// Files is a total number of linted workflow files.
+	Files int
+	// Failures is a number of repositories which could not be linted.
+	Failures int
+}
+
+// findAuditRepositories finds all repositories under the root directory. A repository is a
+// directory which has both ".git" and ".github/workflows". Repositories nested in other
+// repositories are not searched. The returned paths are sorted.
+func findAuditRepositories(root string) ([]string, error) {
+	dirs := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if n := info.Name(); n == ".git" || n == "node_modules" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil { // Note: .git may be a file
+			return nil
+		}
+		if s, err := os.Stat(filepath.Join(path, ".github", "workflows")); err == nil && s.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return filepath.SkipDir // Do not search inside repositories
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not find repositories in %q: %w", root, err)
+	}
+	return dirs, nil
+}
+
+// Audit lints workflow files in all repositories found under the root directory and aggregates the
+// errors per repository. A repository is a directory which has both ".git" and ".github/workflows".
+// Workflow files in all repositories are linted in parallel. Config file of each repository is
+// used unless LinterOptions.ConfigFile is set. Repositories which cannot be linted (e.g. their
+// config files are broken) are recorded as failures instead of stopping the audit.
+func (l *Linter) Audit(root string) (*AuditReport, error) {
+	dirs, err := findAuditRepositories(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no repository was found in %q. repository must have both .git and .github/workflows", root)
+	}
+	l.log("Found", len(dirs), "repositories to audit in", root)
+
+	repos := make([]*AuditRepository, 0, len(dirs))
+	owners := map[string]*AuditRepository{}
+	files := []string{}
+	for _, d := range dirs {
+		r := &AuditRepository{Name: filepath.ToSlash(d)}
+		if rel, err := filepath.Rel(root, d); err == nil {
+			r.Name = filepath.ToSlash(rel)
+		}
+		repos = append(repos, r)
+
+		p, err := l.projects.At(d)
+		if err != nil {
+			r.Failure = err.Error()
+			continue
+		}
+		fs, err := findWorkflowFiles(p.WorkflowsDir())
+		if err != nil {
+			r.Failure = err.Error()
+			continue
+		}
+		r.Files = len(fs)
+		// Errors have file paths relative to the working directory. See LintFiles
+		if l.cwd != "" {
+			if rel, err := filepath.Rel(l.cwd, d); err == nil {
+				d = rel
+			}
+		}
+		owners[d] = r
+		files = append(files, fs...)
+	}
+
+	errs, err := l.LintFiles(files, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range errs {
+		// Errors can be reported in files other than workflows (e.g. action metadata files) so
+		// find the repository by the ancestor directories
+		for d := filepath.Dir(e.Filepath); ; d = filepath.Dir(d) {
+			if r, ok := owners[d]; ok {
+				r.Errors = append(r.Errors, e)
+				break
+			}
+			if p := filepath.Dir(d); p == d {
+				break
+			}
+		}
+	}
+
+	return newAuditReport(repos), nil
+}
+
+// auditRemoteRepository is a repository fetched via GitHub API on auditing.
+type auditRemoteRepository struct {
+	repo *AuditRepository
+	ws   []workspace
+}
+
+// AuditRemote lints workflow files in all repositories owned by the organization or the user via
+// GitHub API without cloning them. Only workflow files in ".github/workflows" and the config file
+// ".github/actionlint.yaml" (or ".yml") of each repository are fetched through the contents API and
+// they are linted in memory. Repositories are fetched in parallel. Repositories which have no
+// workflows directory are not included in the report. File paths of errors are in
+// "owner/repo/.github/workflows/file.yaml" form.
+func (l *Linter) AuditRemote(client *GitHubClient, owner string) (*AuditReport, error) {
+	names, err := client.Repositories(owner)
+	if err != nil {
+		return nil, err
+	}
+	l.log("Found", len(names), "repositories of", owner)
+
+	proc := l.newProcess(runtime.NumCPU())
+	rs := make([]*auditRemoteRepository, len(names))
+	eg := errgroup.Group{}
+	eg.SetLimit(8) // Bound concurrent API requests
+	for i, n := range names {
+		i, n := i, n
+		eg.Go(func() error {
+			r, err := l.auditRemoteRepository(client, n, proc)
+			rs[i] = r
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		proc.wait()
+		return nil, err
+	}
+
+	repos := []*AuditRepository{}
+	owners := map[string]*AuditRepository{}
+	ws := []workspace{}
+	for _, r := range rs {
+		if r == nil {
+			continue
+		}
+		repos = append(repos, r.repo)
+		for _, w := range r.ws {
+			owners[w.path] = r.repo
+		}
+		ws = append(ws, r.ws...)
+	}
+	if len(repos) == 0 {
+		proc.wait()
+		return nil, fmt.Errorf("no repository of %q has .github/workflows directory", owner)
+	}
+
+	ws, err = l.checkProjects(ws)
+	proc.wait() // See the comment in LintFiles
+	if err != nil {
+		return nil, err
+	}
+	errs, err := l.printWorkspaces(ws)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range errs {
+		if r, ok := owners[e.Filepath]; ok {
+			r.Errors = append(r.Errors, e)
+		}
+	}
+
+	return newAuditReport(repos), nil
+}
+
+// auditRemoteRepository fetches workflow files and the config file of the repository and checks the
+// workflows. It returns nil when the repository has no workflows directory. When fetching the files
+// failed, the failure is recorded in the result instead of returning an error.
+func (l *Linter) auditRemoteRepository(client *GitHubClient, repo string, proc *concurrentProcess) (*auditRemoteRepository, error) {
+	files, ok, err := client.Directory(repo, ".github/workflows", "")
+	if err != nil {
+		return &auditRemoteRepository{repo: &AuditRepository{Name: repo, Failure: err.Error()}}, nil
+	}
+	if !ok {
+		l.debug("Repository %s has no workflows directory", repo)
+		return nil, nil
+	}
+	r := &auditRemoteRepository{repo: &AuditRepository{Name: repo}}
+
+	srcs := map[string][]byte{}
+	paths := []string{}
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".yml") && !strings.HasSuffix(f, ".yaml") {
+			continue
+		}
+		b, ok, err := client.Content(repo, f, "")
+		if err != nil {
+			r.repo.Failure = err.Error()
+			return r, nil
+		}
+		if ok {
+			srcs[f] = b
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		r.repo.Failure = fmt.Sprintf("no YAML file was found in %q", repo+"/.github/workflows")
+		return r, nil
+	}
+
+	var cfg *Config
+	for _, n := range []string{".github/actionlint.yaml", ".github/actionlint.yml"} {
+		b, ok, err := client.Content(repo, n, "")
+		if err != nil {
+			r.repo.Failure = err.Error()
+			return r, nil
+		}
+		if !ok {
+			continue
+		}
+		c, err := ParseConfig(b)
+		if err != nil {
+			r.repo.Failure = fmt.Sprintf("could not parse config file %q: %s", repo+"/"+n, err)
+			return r, nil
+		}
+		// Commands in config files of remote repositories are never run
+		cfg = c.withoutCommands()
+		break
+	}
+
+	// The repository is virtually put in the working directory. Files are not read from the file
+	// system but from the fetched contents.
+	root := filepath.Join(l.cwd, filepath.FromSlash(repo))
+	proj := &Project{root, cfg}
+	read := func(p string) ([]byte, error) {
+		if rel, err := filepath.Rel(root, p); err == nil {
+			if b, ok := srcs[filepath.ToSlash(rel)]; ok {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("%q was not fetched from %s: %w", p, repo, os.ErrNotExist)
+	}
+	dbg := l.debugWriter()
+	localActions := NewLocalActionsCache(proj, dbg)
+	localActions.read = read
+	localReusableWorkflows := NewLocalReusableWorkflowCache(proj, l.cwd, dbg)
+	localReusableWorkflows.read = read
+
+	for _, f := range paths {
+		path := filepath.Join(filepath.FromSlash(repo), filepath.FromSlash(f))
+		w, err := l.check(path, srcs[f], proj, proc, localActions, localReusableWorkflows)
+		if err != nil {
+			return nil, fmt.Errorf("fatal error while checking %s: %w", path, err)
+		}
+		r.ws = append(r.ws, *w)
+	}
+	r.repo.Files = len(paths)
+
+	return r, nil
+}
+
+func newAuditReport(repos []*AuditRepository) *AuditReport {
+	rep := &AuditReport{Repositories: repos}
+	all := map[string]int{}
+	for _, r := range repos {
+		counts := map[string]int{}
+		for _, e := range r.Errors {
+			counts[e.Kind]++
+			all[e.Kind]++
+		}
+		r.Rules = sortReportCounts(counts)
+		rep.Total += len(r.Errors)
+		rep.Files += r.Files
+		if r.Failure != "" {
+			rep.Failures++
+		}
+	}
+	rep.Rules = sortReportCounts(all)
+	sort.SliceStable(repos, func(i, j int) bool {
+		if len(repos[i].Errors) != len(repos[j].Errors) {
+			return len(repos[i].Errors) > len(repos[j].Errors)
+		}
+		return repos[i].Name < repos[j].Name
+	})
+	return rep
+}
+
+// Print prints the report to the writer in the format. The format is one of "table", "csv", or
+// "json". "table" format prints the top offenders, the distribution of rules, and the failures in
+// human-readable text. The top parameter limits the number of the top offenders in "table" format.
+// 0 means no limit. "csv" format prints one row per repository with the numbers of errors of each
+// rule in columns.
+func (r *AuditReport) Print(w io.Writer, format string, top int) error {
+	switch format {
+	case "table", "":
+		return r.printTable(w, top)
+	case "csv":
+		return r.printCSV(w)
+	case "json":
+		return r.printJSON(w)
+	default:
+		return fmt.Errorf("unknown format %q of audit report. it must be one of \"table\", \"csv\", or \"json\"", format)
+	}
+}
+
+func (r *AuditReport) printTable(w io.Writer, top int) error {
+	repos := r.Repositories
+	if top > 0 && top < len(repos) {
+		repos = repos[:top]
+	}
+
+	t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(t, "Top offenders:")
+	fmt.Fprintln(t, "REPOSITORY\tFILES\tERRORS\tRULES")
+	for _, repo := range repos {
+		if repo.Failure != "" {
+			fmt.Fprintf(t, "%s\t-\t-\tfailed to lint\n", repo.Name)
+			continue
+		}
+		rules := make([]string, 0, len(repo.Rules))
+		for i, c := range repo.Rules {
+			if i == 3 {
+				rules = append(rules, "...")
+				break
+			}
+			rules = append(rules, fmt.Sprintf("%s (%d)", c.Key, c.Count))
+		}
+		if len(rules) == 0 {
+			rules = append(rules, "-")
+		}
+		fmt.Fprintf(t, "%s\t%d\t%d\t%s\n", repo.Name, repo.Files, len(repo.Errors), strings.Join(rules, ", "))
+	}
+
+	if len(r.Rules) > 0 {
+		fmt.Fprintln(t, "\nRule distribution:")
+		fmt.Fprintln(t, "RULE\tERRORS\tREPOSITORIES")
+		for _, c := range r.Rules {
+			n := 0
+			for _, repo := range r.Repositories {
+				for _, rc := range repo.Rules {
+					if rc.Key == c.Key {
+						n++
+						break
+					}
+				}
+			}
+			fmt.Fprintf(t, "%s\t%d\t%d\n", c.Key, c.Count, n)
+		}
+	}
+	if err := t.Flush(); err != nil {
+		return fmt.Errorf("could not write audit report as table: %w", err)
+	}
+
+	if r.Failures > 0 {
+		fmt.Fprintf(w, "\nFailed to lint %s:\n", pluralizeRepositories(r.Failures))
+		for _, repo := range r.Repositories {
+			if repo.Failure != "" {
+				fmt.Fprintf(w, "  %s: %s\n", repo.Name, repo.Failure)
+			}
+		}
+	}
+
+	fmt.Fprintf(
+		w,
+		"\n%s by %s in %s with %s\n",
+		pluralize(r.Total, "error"),
+		pluralize(len(r.Rules), "rule"),
+		pluralizeRepositories(len(r.Repositories)),
+		pluralize(r.Files, "file"),
+	)
+	return nil
+}
+
+func pluralizeRepositories(n int) string {
+	if n == 1 {
+		return "1 repository"
+	}
+	return fmt.Sprintf("%d repositories", n)
+}
+
+func (r *AuditReport) printCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	h := []string{"repository", "files", "errors", "failure"}
+	for _, rc := range r.Rules {
+		h = append(h, rc.Key)
+	}
+	c.Write(h)
+	for _, repo := range r.Repositories {
+		counts := make(map[string]int, len(repo.Rules))
+		for _, rc := range repo.Rules {
+			counts[rc.Key] = rc.Count
+		}
+		row := []string{repo.Name, strconv.Itoa(repo.Files), strconv.Itoa(len(repo.Errors)), repo.Failure}
+		for _, rc := range r.Rules {
+			row = append(row, strconv.Itoa(counts[rc.Key]))
+		}
+		c.Write(row)
+	}
+	c.Flush()
+	if err := c.Error(); err != nil {
+		return fmt.Errorf("could not write audit report as CSV: %w", err)
+	}
+	return nil
+}
+
+type auditRepositoryJSON struct {
+	Name    string         `json:"name"`
+	Files   int            `json:"files"`
+	Errors  int            `json:"errors"`
+	Rules   map[string]int `json:"rules"`
+	Failure string         `json:"failure,omitempty"`
+}
+
+type auditReportJSON struct {
+	Total        int                    `json:"total"`
+	Files        int                    `json:"files"`
+	Rules        map[string]int         `json:"rules"`
+	Repositories []*auditRepositoryJSON `json:"repositories"`
+}
+
+func auditRulesJSON(cs []ReportCount) map[string]int {
+	m := make(map[string]int, len(cs))
+	for _, c := range cs {
+		m[c.Key] = c.Count
+	}
+	return m
+}
+
+func (r *AuditReport) printJSON(w io.Writer) error {
+	j := &auditReportJSON{
+		Total:        r.Total,
+		Files:        r.Files,
+		Rules:        auditRulesJSON(r.Rules),
+		Repositories: make([]*auditRepositoryJSON, 0, len(r.Repositories)),
+	}
+	for _, repo := range r.Repositories {
+		j.Repositories = append(j.Repositories, &auditRepositoryJSON{
+			Name:    repo.Name,
+			Files:   repo.Files,
+			Errors:  len(repo.Errors),
+			Rules:   auditRulesJSON(repo.Rules),
+			Failure: repo.Failure,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(j); err != nil {
+		return fmt.Errorf("could not encode audit report to JSON: %w", err)
+	}
+	return nil
+}
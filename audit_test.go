@@ -0,0 +1,345 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testCreateAuditRepos(t *testing.T) string {
+	root := t.TempDir()
+	files := map[string]string{
+		"org/a/.github/workflows/ci.yaml": `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    foo: bar
+    steps:
+      - run: echo ${{ unknown.foo }}
+      - run: echo ${{ unknown.bar }}
+`,
+		"org/b/.github/workflows/ci.yaml": `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hello
+`,
+		"org/b-ui/.github/workflows/ci.yaml": `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ unknown.foo }}
+`,
+		"org/b-ui/.github/actionlint.yaml":   "{",
+		"org/no-workflows/README.md":         "hello",
+		"not-repo/.github/workflows/ci.yaml": "on: push\n",
+	}
+	for p, c := range files {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(p, []byte(c), 0644); err != nil {
+			panic(err)
+		}
+	}
+	for _, d := range []string{"org/a", "org/b", "org/b-ui", "org/no-workflows"} {
+		testEnsureDotGitDir(filepath.Join(root, filepath.FromSlash(d)))
+	}
+	return root
+}
+
+func TestLinterAudit(t *testing.T) {
+	root := testCreateAuditRepos(t)
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := l.Audit(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Total != 3 || r.Files != 2 || r.Failures != 1 {
+		t.Fatalf("unexpected summary: total=%d files=%d failures=%d", r.Total, r.Files, r.Failures)
+	}
+	want := []ReportCount{{"expression", 2}, {"syntax-check", 1}}
+	if diff := cmp.Diff(want, r.Rules); diff != "" {
+		t.Fatal(diff)
+	}
+
+	names := []string{}
+	for _, repo := range r.Repositories {
+		names = append(names, repo.Name)
+	}
+	if diff := cmp.Diff([]string{"org/a", "org/b", "org/b-ui"}, names); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(want, r.Repositories[0].Rules); diff != "" {
+		t.Fatal(diff)
+	}
+	if f := r.Repositories[2].Failure; !strings.Contains(f, "could not parse config file") {
+		t.Fatalf("unexpected failure of broken config: %q", f)
+	}
+}
+
+func TestLinterAuditNoRepository(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Audit(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "no repository was found") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestAuditReportPrint(t *testing.T) {
+	r := newAuditReport([]*AuditRepository{
+		{Name: "org/b", Files: 1},
+		{Name: "org/c", Failure: "broken config"},
+		{
+			Name:  "org/a",
+			Files: 2,
+			Errors: []*Error{
+				{Kind: "expression"},
+				{Kind: "expression"},
+				{Kind: "syntax-check"},
+			},
+		},
+	})
+
+	testCases := []struct {
+		format string
+		top    int
+		want   string
+	}{
+		{
+			format: "table",
+			want: `Top offenders:
+REPOSITORY  FILES  ERRORS  RULES
+org/a       2      3       expression (2), syntax-check (1)
+org/b       1      0       -
+org/c       -      -       failed to lint
+
+Rule distribution:
+RULE          ERRORS  REPOSITORIES
+expression    2       1
+syntax-check  1       1
+
+Failed to lint 1 repository:
+  org/c: broken config
+
+3 errors by 2 rules in 3 repositories with 3 files
+`,
+		},
+		{
+			format: "table",
+			top:    1,
+			want: `Top offenders:
+REPOSITORY  FILES  ERRORS  RULES
+org/a       2      3       expression (2), syntax-check (1)
+
+Rule distribution:
+RULE          ERRORS  REPOSITORIES
+expression    2       1
+syntax-check  1       1
+
+Failed to lint 1 repository:
+  org/c: broken config
+
+3 errors by 2 rules in 3 repositories with 3 files
+`,
+		},
+		{
+			format: "csv",
+			want: `repository,files,errors,failure,expression,syntax-check
+org/a,2,3,,2,1
+org/b,1,0,,0,0
+org/c,0,0,broken config,0,0
+`,
+		},
+		{
+			format: "json",
+			want: `{
+  "total": 3,
+  "files": 3,
+  "rules": {
+    "expression": 2,
+    "syntax-check": 1
+  },
+  "repositories": [
+    {
+      "name": "org/a",
+      "files": 2,
+      "errors": 3,
+      "rules": {
+        "expression": 2,
+        "syntax-check": 1
+      }
+    },
+    {
+      "name": "org/b",
+      "files": 1,
+      "errors": 0,
+      "rules": {}
+    },
+    {
+      "name": "org/c",
+      "files": 0,
+      "errors": 0,
+      "rules": {},
+      "failure": "broken config"
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := r.Print(&b, tc.format, tc.top); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	var b bytes.Buffer
+	err := r.Print(&b, "xml", 0)
+	if err == nil || !strings.Contains(err.Error(), `unknown format "xml" of audit report`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestCommandAudit(t *testing.T) {
+	root := testCreateAuditRepos(t)
+
+	var stdout, stderr bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &stdout, Stderr: &stderr}
+	status := cmd.Main([]string{"actionlint", "audit", "-root", root, "-format", "csv", "-shellcheck=", "-pyflakes="})
+	if status != ExitStatusSuccessProblemFound {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessProblemFound, status, stderr.String())
+	}
+	if want := "org/a,1,3,,2,1\n"; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	cfg := filepath.Join(root, "org.yaml")
+	if err := os.WriteFile(cfg, []byte("paths:\n  .github/workflows/**/*.yaml:\n    ignore:\n      - .+\n"), 0644); err != nil {
+		panic(err)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	status = cmd.Main([]string{"actionlint", "audit", "-root", root, "-config-file", cfg, "-format", "json"})
+	if status != ExitStatusSuccessProblemFound {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessProblemFound, status, stderr.String())
+	}
+	if want := `"total": 0`; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	for _, args := range [][]string{
+		{"-format", "xml"},
+		{root},
+		{"-org", "o", "-root", root},
+		{"-org", "o"},
+	} {
+		stderr.Reset()
+		status := cmd.Main(append([]string{"actionlint", "audit"}, args...))
+		if status != ExitStatusInvalidCommandOption {
+			t.Fatalf("exit status should be %d with %v but got %d: %s", ExitStatusInvalidCommandOption, args, status, stderr.String())
+		}
+	}
+}
+
+func TestLinterAuditRemote(t *testing.T) {
+	content := func(s string) string {
+		return fmt.Sprintf(`{"type":"file","encoding":"base64","content":%q}`, base64.StdEncoding.EncodeToString([]byte(s)))
+	}
+	c, _ := testGitHubServer(t, map[string]string{
+		"/orgs/o/repos": `[
+			{"full_name":"o/a","archived":false},
+			{"full_name":"o/b","archived":false},
+			{"full_name":"o/c","archived":false},
+			{"full_name":"o/d","archived":true}
+		]`,
+		"/repos/o/a/contents/.github/workflows": `[
+			{"type":"file","path":".github/workflows/call.yaml"},
+			{"type":"file","path":".github/workflows/ci.yaml"},
+			{"type":"file","path":".github/workflows/README.md"},
+			{"type":"file","path":".github/workflows/reusable.yaml"}
+		]`,
+		"/repos/o/a/contents/.github/workflows/call.yaml": content(`on: push
+jobs:
+  call:
+    uses: ./.github/workflows/reusable.yaml
+    with:
+      foo: bar
+`),
+		"/repos/o/a/contents/.github/workflows/ci.yaml": content(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ unknown.foo }}
+`),
+		"/repos/o/a/contents/.github/workflows/reusable.yaml": content(`on: workflow_call
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hello
+`),
+		"/repos/o/a/contents/.github/actionlint.yml":    content("paths:\n  .github/workflows/ci.yaml:\n    ignore:\n      - rule:expression\n"),
+		"/repos/o/c/contents/.github/workflows":         `[{"type":"file","path":".github/workflows/ci.yaml"}]`,
+		"/repos/o/c/contents/.github/workflows/ci.yaml": content("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"),
+		"/repos/o/c/contents/.github/actionlint.yaml":   content("{"),
+	})
+
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := l.AuditRemote(c, "o")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Repositories) != 2 {
+		t.Fatalf("only o/a and o/c should be audited: %v", r.Repositories)
+	}
+	a := r.Repositories[0]
+	if a.Name != "o/a" || a.Files != 3 || len(a.Errors) != 1 {
+		t.Fatalf("unexpected result of o/a: %+v", a)
+	}
+	e := a.Errors[0]
+	if e.Kind != "workflow-call" || !strings.Contains(e.Message, `input "foo" is not defined`) {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	if want := filepath.Join("o", "a", ".github", "workflows", "call.yaml"); e.Filepath != want {
+		t.Fatalf("file path of error should be %q but got %q", want, e.Filepath)
+	}
+	if f := r.Repositories[1].Failure; !strings.Contains(f, `could not parse config file "o/c/.github/actionlint.yaml"`) {
+		t.Fatalf("unexpected failure of o/c: %q", f)
+	}
+
+	if _, err := l.AuditRemote(c, "nobody"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatal("unexpected error:", err)
+	}
+}
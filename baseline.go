@@ -0,0 +1,130 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BaselineEntry is an error recorded in a baseline file. Line and column are not recorded so that
+// the entry keeps matching to the error after unrelated lines in the file are modified.
+//
+// When both the entry and the error have fingerprints, they are compared by the fingerprints. It
+// distinguishes errors with the same message at different lines in the same file. Otherwise, they
+// are compared by the file path, the rule name, and the message. It allows to use baseline files
+// created by older versions which did not record fingerprints.
+type BaselineEntry struct {
+	// Filepath is a slash-separated file path where the error occurred.
+	Filepath string `json:"filepath"`
+	// Kind is a rule name of the error.
+	Kind string `json:"kind"`
+	// Message is an error message.
+	Message string `json:"message"`
+	// Fingerprint is a stable identifier of the error. See Error.Fingerprint for more details. This
+	// field may be empty.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+func newBaselineEntry(err *Error, source []byte) *BaselineEntry {
+	e := &BaselineEntry{filepath.ToSlash(err.Filepath), err.Kind, err.Message, ""}
+	if len(source) > 0 {
+		e.Fingerprint = err.Fingerprint(source)
+	}
+	return e
+}
+
+func (e *BaselineEntry) match(other *BaselineEntry) bool {
+	if e.Fingerprint != "" && other.Fingerprint != "" {
+		return e.Fingerprint == other.Fingerprint
+	}
+	return e.Filepath == other.Filepath && e.Kind == other.Kind && e.Message == other.Message
+}
+
+// Baseline is a set of known errors. Errors recorded in a baseline are not reported by the linter.
+// It is useful to adopt actionlint in a large codebase gradually: existing errors are recorded in
+// a baseline and only new errors are reported.
+type Baseline struct {
+	// Entries is a list of known errors.
+	Entries []*BaselineEntry `json:"errors"`
+}
+
+// ReadBaselineFile reads the baseline file in JSON format at the given file path.
+func ReadBaselineFile(path string) (*Baseline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline file %q: %w", path, err)
+	}
+	var bl Baseline
+	if err := json.Unmarshal(b, &bl); err != nil {
+		return nil, fmt.Errorf("could not parse baseline file %q: %w", path, err)
+	}
+	return &bl, nil
+}
+
+func (bl *Baseline) index(e *BaselineEntry) int {
+	for i, x := range bl.Entries {
+		if x.match(e) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Match returns whether the given error is recorded in the baseline.
+func (bl *Baseline) Match(err *Error) bool {
+	return bl.MatchWithSource(err, nil)
+}
+
+// MatchWithSource is the same as Match but the source is a content of the file where the error
+// occurred and used to compute the fingerprint of the error. It can be nil.
+func (bl *Baseline) MatchWithSource(err *Error, source []byte) bool {
+	return bl.index(newBaselineEntry(err, source)) >= 0
+}
+
+// Add records the given error in the baseline. It returns false when the error was already
+// recorded.
+func (bl *Baseline) Add(err *Error) bool {
+	return bl.AddWithSource(err, nil)
+}
+
+// AddWithSource is the same as Add but the source is a content of the file where the error occurred
+// and used to compute the fingerprint of the error. When it is nil, the fingerprint is not recorded.
+func (bl *Baseline) AddWithSource(err *Error, source []byte) bool {
+	e := newBaselineEntry(err, source)
+	if bl.index(e) >= 0 {
+		return false
+	}
+	bl.Entries = append(bl.Entries, e)
+	return true
+}
+
+// WriteFile writes the baseline to the given file path in JSON format. The entries are sorted to
+// make the file content deterministic.
+func (bl *Baseline) WriteFile(path string) error {
+	sort.SliceStable(bl.Entries, func(i, j int) bool {
+		x, y := bl.Entries[i], bl.Entries[j]
+		if x.Filepath != y.Filepath {
+			return x.Filepath < y.Filepath
+		}
+		if x.Kind != y.Kind {
+			return x.Kind < y.Kind
+		}
+		if x.Message != y.Message {
+			return x.Message < y.Message
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+	if bl.Entries == nil {
+		bl.Entries = []*BaselineEntry{}
+	}
+	b, err := json.MarshalIndent(bl, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write baseline file %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBaselineAddMatchAndWrite(t *testing.T) {
+	e1 := &Error{Filepath: "b.yaml", Line: 10, Column: 3, Kind: "expression", Message: "foo"}
+	e2 := &Error{Filepath: "a.yaml", Line: 1, Column: 1, Kind: "syntax-check", Message: "bar"}
+
+	bl := &Baseline{}
+	if bl.Match(e1) {
+		t.Fatal("empty baseline matched", e1)
+	}
+	if !bl.Add(e1) || !bl.Add(e2) {
+		t.Fatal("errors were not added")
+	}
+	if bl.Add(e1) {
+		t.Fatal("the same error was added twice")
+	}
+
+	// Line and column are not considered
+	moved := &Error{Filepath: "b.yaml", Line: 20, Column: 5, Kind: "expression", Message: "foo"}
+	if !bl.Match(moved) {
+		t.Fatal("error at different position did not match", moved)
+	}
+	for _, e := range []*Error{
+		{Filepath: "c.yaml", Kind: "expression", Message: "foo"},
+		{Filepath: "b.yaml", Kind: "action", Message: "foo"},
+		{Filepath: "b.yaml", Kind: "expression", Message: "foo!"},
+	} {
+		if bl.Match(e) {
+			t.Error("unexpected match", e)
+		}
+	}
+
+	p := filepath.Join(t.TempDir(), "baseline.json")
+	if err := bl.WriteFile(p); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBaselineFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*BaselineEntry{
+		{"a.yaml", "syntax-check", "bar", ""},
+		{"b.yaml", "expression", "foo", ""},
+	}
+	if diff := cmp.Diff(want, read.Entries); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestBaselineMatchByFingerprint(t *testing.T) {
+	src := []byte("on: push\njobs:\n  a:\n    runs-on: foo\n  b:\n    runs-on: foo\n")
+	e1 := &Error{Filepath: "test.yaml", Line: 4, Column: 14, Kind: "runner-label", Message: "foo"}
+	e2 := &Error{Filepath: "test.yaml", Line: 6, Column: 14, Kind: "runner-label", Message: "foo"}
+
+	bl := &Baseline{}
+	if !bl.AddWithSource(e1, src) {
+		t.Fatal("error was not added")
+	}
+	if bl.Entries[0].Fingerprint != e1.Fingerprint(src) {
+		t.Fatal("fingerprint was not recorded:", bl.Entries[0])
+	}
+	if !bl.MatchWithSource(e1, src) {
+		t.Fatal("recorded error did not match")
+	}
+	// Errors on the lines with the same content are distinguished
+	if bl.MatchWithSource(e2, src) {
+		t.Fatal("error on the other line with the same content matched")
+	}
+	// The error still matches after unrelated lines are inserted
+	moved := []byte("# comment\non: push\njobs:\n  a:\n    runs-on: foo\n  b:\n    runs-on: foo\n")
+	if !bl.MatchWithSource(&Error{Filepath: "test.yaml", Line: 5, Column: 14, Kind: "runner-label", Message: "foo"}, moved) {
+		t.Fatal("recorded error did not match after inserting a line")
+	}
+	if !bl.AddWithSource(e2, src) {
+		t.Fatal("error with different fingerprint was not added")
+	}
+	if bl.AddWithSource(e2, src) {
+		t.Fatal("error was added twice")
+	}
+
+	// Entries without fingerprint match by file path, kind, and message
+	legacy := &Baseline{Entries: []*BaselineEntry{{Filepath: "test.yaml", Kind: "runner-label", Message: "foo"}}}
+	if !legacy.MatchWithSource(e2, src) {
+		t.Fatal("entry without fingerprint did not match")
+	}
+}
+
+func TestBaselineReadFileError(t *testing.T) {
+	d := t.TempDir()
+	if _, err := ReadBaselineFile(filepath.Join(d, "does-not-exist.json")); err == nil || !strings.Contains(err.Error(), "could not read baseline file") {
+		t.Fatal("unexpected error:", err)
+	}
+	p := filepath.Join(d, "broken.json")
+	if err := os.WriteFile(p, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBaselineFile(p); err == nil || !strings.Contains(err.Error(), "could not parse baseline file") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestBaselineLinterFiltersErrors(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: foo\n    steps:\n      - run: echo ${{ unknown }}\n"
+	d := t.TempDir()
+	p := filepath.Join(d, "baseline.json")
+	bl := &Baseline{}
+	bl.Add(&Error{Filepath: "test.yaml", Kind: "expression", Message: `undefined variable "unknown". available variables are "env", "github", "inputs", "job", "matrix", "needs", "runner", "secrets", "steps", "strategy", "vars"`})
+	if err := bl.WriteFile(p); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := NewLinter(&strings.Builder{}, &LinterOptions{Baseline: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Kind != "runner-label" {
+		t.Fatalf("only runner-label error should be reported: %v", errs)
+	}
+
+	if _, err := NewLinter(&strings.Builder{}, &LinterOptions{Baseline: filepath.Join(d, "missing.json")}); err == nil {
+		t.Fatal("error did not occur for missing baseline file")
+	}
+}
@@ -0,0 +1,77 @@
+package actionlint
+
+// ActionMetadataResolver resolves metadata of actions used at "uses:" of steps. LocalActionsCache
+// implements this interface for local actions like "./path/to/action".
+type ActionMetadataResolver interface {
+	// FindMetadata finds metadata of the action specified by the spec. The first return value can be
+	// nil when the action was not found. The second return value is true when the metadata was
+	// already returned by previous calls so that the same errors are not reported repeatedly.
+	FindMetadata(spec string) (*ActionMetadata, bool, error)
+}
+
+// GitRepository reads files committed to the Git repository of the project. The repository in the
+// working directory of the linter is implemented with the git command.
+type GitRepository interface {
+	// FilesAtHead returns files in the directory at HEAD commit. The directory and the returned file
+	// paths are slash-separated and relative to the repository root. It returns an error when the
+	// project is not the root of a Git repository or the repository has no commit.
+	FilesAtHead(dir string) ([]string, error)
+	// ReadFileAtHead reads the content of the file at HEAD commit. The file path is slash-separated
+	// and relative to the repository root.
+	ReadFileAtHead(path string) ([]byte, error)
+}
+
+// MatrixExpander expands a matrix at "strategy.matrix" to the combinations which GitHub runs.
+type MatrixExpander interface {
+	// ExpandMatrix returns all combinations of the matrix after applying "include" and "exclude". It
+	// returns false when the combinations cannot be known statically (e.g. ${{ }} is used) or the
+	// number of combinations is too large to enumerate.
+	ExpandMatrix(m *Matrix) ([]map[string]RawYAMLValue, bool)
+}
+
+// Capabilities is a set of services which rules can use while checking workflows. Rules should
+// request the services via Capabilities method of RuleBase instead of constructing them by
+// themselves or taking them as parameters of their constructors. The linter creates the services
+// per workflow file and sets them to rules which have SetCapabilities method. Rules implementing
+// ProjectRule receive the services of the project. Rules can be tested in isolation by setting fake
+// services. Fields are nil when the service is not available.
+type Capabilities struct {
+	// LocalActions resolves metadata of local actions in the repository.
+	LocalActions ActionMetadataResolver
+	// LocalReusableWorkflows resolves metadata of local reusable workflows in the repository.
+	LocalReusableWorkflows *LocalReusableWorkflowCache
+	// PopularActions is metadata of popular actions keyed by their specs like "actions/checkout@v4".
+	// When this field is nil, the PopularActions global variable is used.
+	PopularActions map[string]*ActionMetadata
+	// Remote is the repository on GitHub to access GitHub API. This field is nil unless the
+	// repository is given to the linter.
+	Remote *RemoteRepository
+	// RemoteReusableWorkflows resolves metadata of reusable workflows in other repositories with
+	// GitHub API. This field is nil unless the repository is given to the linter.
+	RemoteReusableWorkflows *RemoteReusableWorkflowCache
+	// Git reads files committed to the Git repository of the project. This field is nil when the
+	// checked workflow does not belong to any project.
+	Git GitRepository
+	// Matrix expands matrices to their combinations. When this field is nil, the combinations are
+	// expanded in the same way as GitHub.
+	Matrix MatrixExpander
+}
+
+// FindPopularAction finds metadata of the popular action specified by the spec.
+func (s *Capabilities) FindPopularAction(spec string) (*ActionMetadata, bool) {
+	if s == nil || s.PopularActions == nil {
+		m, ok := PopularActions[spec]
+		return m, ok
+	}
+	m, ok := s.PopularActions[spec]
+	return m, ok
+}
+
+// ExpandMatrix expands the matrix to its combinations with the Matrix service. See MatrixExpander
+// for more details.
+func (s *Capabilities) ExpandMatrix(m *Matrix) ([]map[string]RawYAMLValue, bool) {
+	if s == nil || s.Matrix == nil {
+		return defaultMatrixExpander{}.ExpandMatrix(m)
+	}
+	return s.Matrix.ExpandMatrix(m)
+}
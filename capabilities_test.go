@@ -0,0 +1,135 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+type testActionMetadataResolver struct {
+	specs []string
+}
+
+func (r *testActionMetadataResolver) FindMetadata(spec string) (*ActionMetadata, bool, error) {
+	r.specs = append(r.specs, spec)
+	return &ActionMetadata{
+		Name:   "Fake action",
+		Inputs: ActionMetadataInputs{"foo": {Name: "foo"}},
+	}, true, nil // Metadata itself is not validated when it was cached
+}
+
+func TestCapabilitiesInjectedToRule(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./my-action
+        with:
+          bar: hello
+      - uses: my/action@v1
+      - uses: actions/checkout@v4
+        with:
+          this-input-does-not-exist: true
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	local := &testActionMetadataResolver{}
+	rule := NewRuleAction()
+	rule.SetCapabilities(&Capabilities{
+		LocalActions: local,
+		PopularActions: map[string]*ActionMetadata{
+			"my/action@v1": {
+				Name:   "My action",
+				Inputs: ActionMetadataInputs{"token": {Name: "token", Required: true}},
+			},
+		},
+	})
+
+	v := NewVisitor()
+	v.AddPass(rule)
+	if err := v.Visit(w); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(local.specs) != 1 || local.specs[0] != "./my-action" {
+		t.Fatal("fake resolver was not used:", local.specs)
+	}
+
+	msgs := []string{}
+	for _, e := range rule.Errs() {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{
+		`input "bar" is not defined in action "Fake action"`,
+		`missing input "token" which is required by action "my/action@v1"`,
+		// actions/checkout@v4 is not in the injected popular actions
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("wanted %d errors but got %q", len(want), msgs)
+	}
+	for i, m := range msgs {
+		if !strings.Contains(m, want[i]) {
+			t.Errorf("error %q does not contain %q", m, want[i])
+		}
+	}
+}
+
+func TestCapabilitiesNotAvailable(t *testing.T) {
+	src := `on: push
+jobs:
+  call:
+    uses: ./.github/workflows/reusable.yaml
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./my-action
+        id: foo
+      - run: echo ${{ steps.foo.outputs.bar }}
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	// Rules can check workflows without local caches and remote repository
+	rules := []Rule{
+		NewRuleAction(),
+		NewRuleExpression(),
+		NewRuleWorkflowCall("test.yaml"),
+		NewRuleRemote(),
+	}
+	v := NewVisitor()
+	for _, r := range rules {
+		setRuleCapabilities(r, &Capabilities{})
+		v.AddPass(r)
+	}
+	if err := v.Visit(w); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rules {
+		if errs := r.Errs(); len(errs) > 0 {
+			t.Errorf("rule %q reported errors: %v", r.Name(), errs)
+		}
+	}
+
+	var c *Capabilities
+	if m, ok := c.FindPopularAction("actions/checkout@v4"); !ok || m == nil {
+		t.Error("popular actions should be found with nil capabilities")
+	}
+}
+
+func TestCapabilitiesNotSetToRuleWithoutSetter(t *testing.T) {
+	r := NewRuleAction()
+	// Wrapping the rule hides SetCapabilities method which is not a part of Rule interface
+	setRuleCapabilities(struct{ Rule }{r}, &Capabilities{})
+	if r.caps != nil {
+		t.Fatal("capabilities should not be set to the rule without SetCapabilities method")
+	}
+	setRuleCapabilities(r, &Capabilities{})
+	if r.caps == nil {
+		t.Fatal("capabilities should be set to the rule")
+	}
+}
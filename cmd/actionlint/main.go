@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	_ "time/tzdata" // Time zones at "assume-timezone" config must be available on systems without tzdata
 
 	"github.com/rhysd/actionlint"
 )
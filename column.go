@@ -0,0 +1,101 @@
+package actionlint
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// ColumnUnit is a unit to count columns of positions in a line. Editors and tools disagree on how
+// to count columns in a line containing multibyte characters. For example, the LSP and many
+// editors on Windows count UTF-16 code units, and some tools count bytes in UTF-8.
+type ColumnUnit string
+
+const (
+	// ColumnUnitRune counts columns in Unicode code points. This is the default unit and actionlint
+	// computes positions in this unit internally.
+	ColumnUnitRune ColumnUnit = "rune"
+	// ColumnUnitByte counts columns in bytes of UTF-8 encoding.
+	ColumnUnitByte ColumnUnit = "byte"
+	// ColumnUnitUTF16 counts columns in UTF-16 code units. A character outside the BMP such as emoji
+	// is counted as 2 columns.
+	ColumnUnitUTF16 ColumnUnit = "utf16"
+)
+
+// ParseColumnUnit parses the given string as ColumnUnit. An empty string is parsed as
+// ColumnUnitRune.
+func ParseColumnUnit(s string) (ColumnUnit, error) {
+	switch u := ColumnUnit(s); u {
+	case "":
+		return ColumnUnitRune, nil
+	case ColumnUnitRune, ColumnUnitByte, ColumnUnitUTF16:
+		return u, nil
+	default:
+		return ColumnUnitRune, fmt.Errorf("unknown column unit %q. it must be one of \"rune\", \"byte\", or \"utf16\"", s)
+	}
+}
+
+func (u ColumnUnit) width(r rune) int {
+	switch u {
+	case ColumnUnitByte:
+		return utf8.RuneLen(r)
+	case ColumnUnitUTF16:
+		if r >= 0x10000 {
+			return 2 // Surrogate pair
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+// FromRune converts the 1-based column in Unicode code points in the line into the column in this
+// unit. Columns beyond the end of the line are counted as 1 column per character. Zero column
+// means the column is unknown and is returned as-is.
+func (u ColumnUnit) FromRune(line string, col int) int {
+	if col <= 0 || u == ColumnUnitRune || u == "" {
+		return col
+	}
+	ret := 1
+	for _, r := range line {
+		if col <= 1 {
+			return ret
+		}
+		ret += u.width(r)
+		col--
+	}
+	return ret + col - 1
+}
+
+// ToRune converts the 1-based column in this unit in the line into the column in Unicode code
+// points. This is the inverse of FromRune. When the column points to the middle of a character,
+// the column of the character is returned.
+func (u ColumnUnit) ToRune(line string, col int) int {
+	if col <= 0 || u == ColumnUnitRune || u == "" {
+		return col
+	}
+	ret := 1
+	for _, r := range line {
+		w := u.width(r)
+		if col <= w {
+			return ret
+		}
+		col -= w
+		ret++
+	}
+	return ret + col - 1
+}
+
+// convertColumns converts the columns of the errors in the source from Unicode code points into
+// the unit. Positions of suggestions are not converted since they are applied to the source by
+// counting characters.
+func (u ColumnUnit) convertColumns(errs []*Error, lines []string) {
+	if u == ColumnUnitRune || u == "" || len(errs) == 0 {
+		return
+	}
+	for _, err := range errs {
+		if err.Line <= 0 || err.Line > len(lines) {
+			continue
+		}
+		err.Column = u.FromRune(lines[err.Line-1], err.Column)
+	}
+}
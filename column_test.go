@@ -0,0 +1,65 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColumnUnitParse(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  ColumnUnit
+	}{
+		{"", ColumnUnitRune},
+		{"rune", ColumnUnitRune},
+		{"byte", ColumnUnitByte},
+		{"utf16", ColumnUnitUTF16},
+	} {
+		have, err := ParseColumnUnit(tc.input)
+		if err != nil {
+			t.Fatalf("%q caused error: %s", tc.input, err)
+		}
+		if have != tc.want {
+			t.Errorf("wanted %q for %q but got %q", tc.want, tc.input, have)
+		}
+	}
+
+	_, err := ParseColumnUnit("utf-8")
+	if err == nil || !strings.Contains(err.Error(), `unknown column unit "utf-8"`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestColumnUnitConvert(t *testing.T) {
+	line := "a日🐶b"
+	for _, tc := range []struct {
+		unit ColumnUnit
+		cols []int // Columns of 'a', '日', '🐶', 'b', and the end of the line
+	}{
+		{ColumnUnitRune, []int{1, 2, 3, 4, 5}},
+		{ColumnUnitByte, []int{1, 2, 5, 9, 10}},
+		{ColumnUnitUTF16, []int{1, 2, 3, 5, 6}},
+	} {
+		t.Run(string(tc.unit), func(t *testing.T) {
+			for i, want := range tc.cols {
+				if have := tc.unit.FromRune(line, i+1); have != want {
+					t.Errorf("column %d was converted to %d but wanted %d", i+1, have, want)
+				}
+				if have := tc.unit.ToRune(line, want); have != i+1 {
+					t.Errorf("column %d was converted back to %d but wanted %d", want, have, i+1)
+				}
+			}
+			if have := tc.unit.FromRune(line, 0); have != 0 {
+				t.Errorf("unknown column should not be converted but got %d", have)
+			}
+		})
+	}
+
+	// Column in the middle of a character points the character
+	if have := ColumnUnitByte.ToRune(line, 3); have != 2 {
+		t.Errorf("byte column in the middle of '日' should point the character but got %d", have)
+	}
+	if have := ColumnUnitUTF16.ToRune(line, 4); have != 3 {
+		t.Errorf("UTF-16 column in the middle of surrogate pair should point the character but got %d", have)
+	}
+}
@@ -1,12 +1,26 @@
 package actionlint
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"runtime/debug"
+	"runtime/pprof"
+	"strings"
+	"syscall"
+	"time"
 )
 
 // These variables might be modified by ldflags on building release binaries by GoReleaser. Do not modify manually
@@ -56,58 +70,1157 @@ func printUsageHeader(out io.Writer) {
 
     $ actionlint -format '{{json .}}'
 
+  To run actionlint as an HTTP server which lints repository archives and
+  handles GitHub webhooks, use serve subcommand. See 'actionlint serve -h'.
+
+    $ actionlint serve -addr :8080
+
+  To lint workflows quickly from IDEs and bots, daemon subcommand keeps
+  caches in memory and serves lint requests. See 'actionlint daemon -h'.
+
+    $ actionlint daemon -listen unix:///tmp/actionlint.sock
+
+  To integrate actionlint with editors, lsp subcommand starts a language
+  server communicating via stdio. See 'actionlint lsp -h'.
+
+    $ actionlint lsp
+
+  To debug conditions at "if:" locally, eval subcommand evaluates expression
+  with mock values of contexts. See 'actionlint eval -h'.
+
+    $ actionlint eval -contexts ctx.json "github.event_name == 'push'"
+
+  To debug filters at "on:" and "if:" conditions of jobs, simulate subcommand
+  prints which jobs would run for a hypothetical event. See
+  'actionlint simulate -h'.
+
+    $ actionlint simulate -event push -ref refs/heads/main -paths src/a.go
+
+  To audit workflows across many cloned repositories with a shared config,
+  audit subcommand prints an aggregate report. See 'actionlint audit -h'.
+
+    $ actionlint audit -root /path/to/clones -format csv
+
+  To diagnose the environment for bug reports, doctor subcommand prints
+  availability of external commands, config file, datasets, and so on. See
+  'actionlint doctor -h'.
+
+    $ actionlint doctor
+
+  To review changes of a workflow, diff subcommand reports semantic changes
+  between two versions of it. See 'actionlint diff -h'.
+
+    $ actionlint diff main:.github/workflows/ci.yaml .github/workflows/ci.yaml
+
 Documents:
 
-  - List of checks: https://github.com/rhysd/actionlint/tree/%s/docs/checks.md
-  - Usage:          https://github.com/rhysd/actionlint/tree/%s/docs/usage.md
-  - Configuration:  https://github.com/rhysd/actionlint/tree/%s/docs/config.md
+  - List of checks: https://github.com/rhysd/actionlint/tree/%s/docs/checks.md
+  - Usage:          https://github.com/rhysd/actionlint/tree/%s/docs/usage.md
+  - Configuration:  https://github.com/rhysd/actionlint/tree/%s/docs/config.md
+
+Flags:
+`, b, b, b)
+}
+
+func getCommandVersion() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown" // Reaches only when actionlint package is built outside module
+	}
+
+	return info.Main.Version
+}
+
+// Command represents entire actionlint command. Given stdin/stdout/stderr are used for input/output.
+type Command struct {
+	// Stdin is a reader to read input from stdin
+	Stdin io.Reader
+	// Stdout is a writer to write output to stdout
+	Stdout io.Writer
+	// Stderr is a writer to write output to stderr
+	Stderr io.Writer
+}
+
+func (cmd *Command) runLinter(args []string, opts *LinterOptions, initConfig bool, staged bool) ([]*Error, error) {
+	// Kill running shellcheck and pyflakes processes with their child processes on Ctrl-C. They are
+	// not killed by the terminal since they are put in their own process groups.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts.Context = ctx
+
+	l, err := NewLinter(cmd.Stdout, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if initConfig {
+		return nil, l.GenerateDefaultConfig("")
+	}
+
+	if staged {
+		if len(args) > 0 {
+			return nil, errors.New("files cannot be specified with -staged option since staged files are checked")
+		}
+		return l.LintStaged("")
+	}
+
+	if len(args) == 0 {
+		return l.LintRepository("")
+	}
+
+	if len(args) == 1 && args[0] == "-" {
+		return l.LintStdin(cmd.Stdin)
+	}
+
+	return l.LintFiles(args, nil)
+}
+
+func (cmd *Command) runFormatter(args []string, fix bool) error {
+	if len(args) == 1 && args[0] == "-" {
+		b, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return fmt.Errorf("could not read stdin: %w", err)
+		}
+		f, err := FormatWorkflow(b)
+		if err != nil {
+			return err
+		}
+		_, err = cmd.Stdout.Write(f)
+		return err
+	}
+
+	if len(args) == 0 {
+		p, err := NewProjects().At(".")
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("no project was found in any parent directories of the current directory. check workflows directory is put correctly in your Git repository")
+		}
+		fs, err := findWorkflowFiles(p.WorkflowsDir())
+		if err != nil {
+			return err
+		}
+		args = fs
+	}
+
+	for _, path := range args {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q: %w", path, err)
+		}
+		f, err := FormatWorkflow(b)
+		if err != nil {
+			return fmt.Errorf("could not format %q: %w", path, err)
+		}
+		if !fix {
+			if _, err := cmd.Stdout.Write(f); err != nil {
+				return err
+			}
+			continue
+		}
+		if bytes.Equal(b, f) {
+			continue
+		}
+		if err := os.WriteFile(path, f, 0644); err != nil {
+			return fmt.Errorf("could not write formatted workflow to %q: %w", path, err)
+		}
+		fmt.Fprintf(cmd.Stdout, "Formatted %s\n", path)
+	}
+
+	return nil
+}
+
+func (cmd *Command) runRename(spec string, args []string) error {
+	s, err := parseRenameSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 && args[0] == "-" {
+		b, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return fmt.Errorf("could not read stdin: %w", err)
+		}
+		r, n, err := renameIDInWorkflow(b, s)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("%s %q was not found in the workflow", s.kind, s.from)
+		}
+		_, err = cmd.Stdout.Write(r)
+		return err
+	}
+
+	if len(args) == 0 {
+		p, err := NewProjects().At(".")
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("no project was found in any parent directories of the current directory. check workflows directory is put correctly in your Git repository")
+		}
+		fs, err := findWorkflowFiles(p.WorkflowsDir())
+		if err != nil {
+			return err
+		}
+		args = fs
+	}
+
+	// Rename IDs in all files before writing them not to leave some files partially renamed on error
+	type renamed struct {
+		path  string
+		src   []byte
+		count int
+	}
+	rs := []renamed{}
+	for _, path := range args {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q: %w", path, err)
+		}
+		r, n, err := renameIDInWorkflow(b, s)
+		if err != nil {
+			return fmt.Errorf("could not rename %s %q in %q: %w", s.kind, s.from, path, err)
+		}
+		if n > 0 {
+			rs = append(rs, renamed{path, r, n})
+		}
+	}
+	if len(rs) == 0 {
+		return fmt.Errorf("%s %q was not found in any workflow files", s.kind, s.from)
+	}
+
+	for _, r := range rs {
+		if err := os.WriteFile(r.path, r.src, 0644); err != nil {
+			return fmt.Errorf("could not write renamed workflow to %q: %w", r.path, err)
+		}
+		fmt.Fprintf(cmd.Stdout, "Renamed %s %q to %q at %d places in %s\n", s.kind, s.from, s.to, r.count, r.path)
+	}
+	return nil
+}
+
+func (cmd *Command) runReport(kind string, format string, args []string, opts *LinterOptions) error {
+	if kind != "actions" && kind != "names" {
+		return fmt.Errorf("unknown kind of report %q. it must be \"actions\" or \"names\"", kind)
+	}
+
+	ps := NewProjects()
+	if len(args) == 0 {
+		p, err := ps.At(".")
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("no project was found in any parent directories of the current directory. check workflows directory is put correctly in your Git repository")
+		}
+		fs, err := findWorkflowFiles(p.WorkflowsDir())
+		if err != nil {
+			return err
+		}
+		args = fs
+	}
+	cwd, _ := os.Getwd()
+
+	if kind == "names" {
+		rep := NewNameReport()
+		for _, path := range args {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("could not read %q: %w", path, err)
+			}
+			if cwd != "" {
+				if r, err := filepath.Rel(cwd, path); err == nil {
+					path = r
+				}
+			}
+			if err := rep.AddWorkflow(path, b); err != nil {
+				return err
+			}
+		}
+		rep.Sort()
+		return rep.Print(cmd.Stdout, format)
+	}
+
+	f, err := ParseInventoryFormat(format)
+	if err != nil {
+		return err
+	}
+
+	var client *GitHubClient
+	if opts.Remote != "" {
+		client = NewGitHubClient(opts.RemoteToken)
+	}
+	var dbg io.Writer
+	if opts.Debug {
+		dbg = cmd.Stderr
+	}
+	inv := NewInventory(client, dbg)
+	caches := NewLocalActionsCacheFactory(dbg)
+
+	for _, path := range args {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q: %w", path, err)
+		}
+		w, errs := Parse(b)
+		if w == nil {
+			return fmt.Errorf("could not parse %q: %s", path, errs[0].Message)
+		}
+		p, err := ps.At(path)
+		if err != nil {
+			return err
+		}
+		if cwd != "" {
+			if r, err := filepath.Rel(cwd, path); err == nil {
+				path = r
+			}
+		}
+		if err := inv.AddWorkflow(path, w, caches.GetCache(p)); err != nil {
+			return err
+		}
+	}
+
+	inv.Sort()
+	return inv.Print(cmd.Stdout, f)
+}
+
+// runServer runs `actionlint serve` subcommand which starts an HTTP server to lint workflows. See
+// Server document for the endpoints.
+func (cmd *Command) runServer(args []string) int {
+	var addr string
+	var appKey string
+	var opts ServerOptions
+
+	flags := flag.NewFlagSet("actionlint serve", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&addr, "addr", ":8080", "Address to listen on")
+	flags.StringVar(&opts.Linter.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
+	flags.StringVar(&opts.Linter.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.Linter.ConfigFile, "config-file", "", "File path to config file used for linting all repositories. Config files in the repositories are not used")
+	flags.Int64Var(&opts.AppID, "app-id", 0, "ID of GitHub App to access GitHub API on handling webhooks. Private key is read from -app-private-key")
+	flags.StringVar(&appKey, "app-private-key", "", "File path to private key of GitHub App in PEM format")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint serve [FLAGS]
+
+  Start an HTTP server to lint workflows as a centralized service.
+
+    POST /lint     Lint a repository archive (tar, tar.gz, or zip) in the
+                   request body and return annotations in GitHub Checks API
+                   format
+    POST /webhook  Handle "check_suite" webhook and create a check run
+    GET  /healthz  Health check
+
+  Secret of webhooks is read from $ACTIONLINT_WEBHOOK_SECRET. Clients of /lint
+  must send the token in $ACTIONLINT_SERVER_TOKEN as "Authorization: Bearer
+  {token}" header. Each endpoint is disabled when its secret or token is not
+  set, and at least one of them must be set. When GitHub App is not configured,
+  token to access GitHub API is read from $GITHUB_TOKEN or $GH_TOKEN.
+
+  Config files in linted repositories are not used. Use -config-file to
+  configure the linter.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+
+	opts.WebhookSecret = os.Getenv("ACTIONLINT_WEBHOOK_SECRET")
+	opts.LintToken = os.Getenv("ACTIONLINT_SERVER_TOKEN")
+	opts.Token = os.Getenv("GITHUB_TOKEN")
+	if opts.Token == "" {
+		opts.Token = os.Getenv("GH_TOKEN")
+	}
+	if opts.AppID != 0 {
+		b, err := os.ReadFile(appKey)
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "could not read private key of GitHub App: %s\n", err)
+			return ExitStatusInvalidCommandOption
+		}
+		opts.AppPrivateKey = b
+	}
+	opts.LogWriter = cmd.Stderr
+
+	if err := loadUserData(); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	s, err := NewServer(&opts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	fmt.Fprintf(cmd.Stderr, "Listening on %s\n", addr)
+	if err := http.ListenAndServe(addr, s); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	return ExitStatusSuccessNoProblem
+}
+
+// runDaemon runs `actionlint daemon` subcommand which starts a long-running daemon to serve lint
+// requests with warm caches. See Daemon document for the endpoints.
+func (cmd *Command) runDaemon(args []string) int {
+	var listen string
+	var ignorePats ignorePatternFlags
+	var opts DaemonOptions
+
+	flags := flag.NewFlagSet("actionlint daemon", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&listen, "listen", "tcp://127.0.0.1:7000", "Address to listen on. \"unix:///path/to/sock\" for UNIX domain socket or \"tcp://host:port\" for TCP")
+	flags.Var(&ignorePats, "ignore", "Regular expression matching to error messages you want to ignore. \"rule:name\", \"code:ALXXXX\", and \"id:message-id\" ignore all errors of the rule, the error code, or the message ID. This flag is repeatable")
+	flags.StringVar(&opts.Linter.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
+	flags.StringVar(&opts.Linter.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.Linter.ConfigFile, "config-file", "", "File path to config file used instead of config files of repositories")
+	flags.StringVar(&opts.Linter.Remote, "remote", "", "Repository on GitHub in \"owner/repo\" form to validate repository-specific references by default. Token is read from $GITHUB_TOKEN or $GH_TOKEN")
+	flags.BoolVar(&opts.Linter.RemoteLint, "remote-lint", false, "Lint reusable workflows in other repositories fetched with remote repository transitively")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint daemon [FLAGS]
+
+  Start a long-running daemon which serves lint requests. Config files and
+  responses of GitHub API are cached in memory to avoid the cost of cold start.
+
+    POST /lint     Lint a workflow file specified by JSON request body like
+                   {"path": "...", "content": "...", "working_dir": "..."}
+    POST /reload   Drop all cached data
+    GET  /healthz  Health check
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+
+	opts.Linter.IgnorePatterns = ignorePats
+	opts.Token = os.Getenv("GITHUB_TOKEN")
+	if opts.Token == "" {
+		opts.Token = os.Getenv("GH_TOKEN")
+	}
+	opts.LogWriter = cmd.Stderr
+
+	if err := loadUserData(); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	d, err := NewDaemon(&opts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	l, err := listenDaemon(listen)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	// Cancelling the base context kills external processes run for the requests in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := &http.Server{
+		Handler:     d,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	done := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		cancel()
+		srv.Shutdown(context.Background()) // This removes the socket file and waits for the requests in flight
+		close(done)
+	}()
+
+	fmt.Fprintf(cmd.Stderr, "Listening on %s\n", listen)
+	if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	<-done
+	return ExitStatusSuccessNoProblem
+}
+
+// runLSP runs `actionlint lsp` subcommand which starts a language server communicating with the
+// client via stdin and stdout.
+func (cmd *Command) runLSP(args []string) int {
+	var ignorePats ignorePatternFlags
+	var opts LSPOptions
+
+	flags := flag.NewFlagSet("actionlint lsp", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.Var(&ignorePats, "ignore", "Regular expression matching to error messages you want to ignore. \"rule:name\", \"code:ALXXXX\", and \"id:message-id\" ignore all errors of the rule, the error code, or the message ID. This flag is repeatable")
+	flags.StringVar(&opts.Linter.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
+	flags.StringVar(&opts.Linter.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.Linter.ConfigFile, "config-file", "", "File path to config file used instead of config files of repositories")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint lsp [FLAGS]
+
+  Start a language server which communicates with the client via stdin and
+  stdout using Language Server Protocol. Errors in opened workflow files are
+  published as diagnostics and quick fixes are provided as code actions.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+
+	opts.Linter.IgnorePatterns = ignorePats
+	opts.LogWriter = cmd.Stderr
+
+	if err := loadUserData(); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	s, err := NewLSPServer(&opts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := s.ServeContext(ctx, cmd.Stdin, cmd.Stdout); err != nil && err != context.Canceled {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	return ExitStatusSuccessNoProblem
+}
+
+// runEval runs `actionlint eval` subcommand which evaluates the expression with the mock values of
+// contexts and prints the result in JSON.
+func (cmd *Command) runEval(args []string) int {
+	var contexts string
+
+	flags := flag.NewFlagSet("actionlint eval", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&contexts, "contexts", "", "File path to JSON object whose keys are context names like \"github\" and values are their mock values. \"-\" reads the JSON from stdin")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint eval [FLAGS] EXPRESSION
+
+  Evaluate the expression with mock values of contexts given via -contexts
+  and print the result in JSON. The expression can be a condition at "if:"
+  like "github.event_name == 'push'" or a string containing ${{ }}. This is
+  useful for debugging conditions locally.
+
+    $ actionlint eval -contexts ctx.json "startsWith(github.ref, 'refs/tags/')"
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(cmd.Stderr, "exactly one expression must be given as argument. see 'actionlint eval -h'")
+		return ExitStatusInvalidCommandOption
+	}
+
+	ctx, err := cmd.readMockContexts(contexts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	v, err := EvalExpression(flags.Arg(0), ctx)
+	if err != nil {
+		if e, ok := err.(*ExprError); ok {
+			fmt.Fprintf(cmd.Stderr, "could not evaluate expression at column %d: %s\n", e.Column, e.Message)
+		} else {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+		}
+		return ExitStatusFailure
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(cmd.Stderr, "could not encode the result %v into JSON: %s\n", v, err)
+		return ExitStatusFailure
+	}
+	fmt.Fprintln(cmd.Stdout, string(b))
+	return ExitStatusSuccessNoProblem
+}
+
+// runSimulate runs `actionlint simulate` subcommand which prints which jobs in workflows would run
+// when the hypothetical event occurs.
+func (cmd *Command) runSimulate(args []string) int {
+	var ev simulateEvent
+	var paths string
+	var contexts string
+	var exportAct string
+	var runWith string
+
+	flags := flag.NewFlagSet("actionlint simulate", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&ev.name, "event", "push", "Name of the event which triggers workflows like \"push\" or \"pull_request\"")
+	flags.StringVar(&ev.ref, "ref", "", "Git ref of the event like \"refs/heads/main\" or \"refs/tags/v1.0.0\". For pull_request events, this is the base branch. If empty, branch and tag filters are not checked")
+	flags.StringVar(&ev.typ, "type", "", "Activity type of the event like \"opened\". If empty, \"types:\" filters are not checked")
+	flags.StringVar(&paths, "paths", "", "Comma-separated list of changed file paths like \"src/a.go,README.md\". If empty, path filters are not checked")
+	flags.StringVar(&contexts, "contexts", "", "File path to JSON object whose keys are context names like \"github\" and values are their mock values for evaluating \"if:\" conditions and matrices. \"-\" reads the JSON from stdin")
+	flags.StringVar(&exportAct, "export-act", "", "Directory to export the mock of event payload as \"event.json\". Command lines of nektos/act to run the jobs which would run are also printed")
+	flags.StringVar(&runWith, "run-with", "", "Command name or file path of nektos/act like \"act\" to run the jobs which would run locally. The mock of event payload is passed via -e option")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint simulate [FLAGS] [FILES...]
+
+  Simulate the hypothetical event and print which jobs in the workflows would
+  run. Filters at "on:", "if:" conditions of jobs, and matrix expansion are
+  evaluated. All jobs which run are assumed to succeed. When no file is given,
+  all workflow files in the current repository are simulated.
+
+    $ actionlint simulate -event push -ref refs/heads/main -paths src/a.go
+
+  To run the jobs locally with nektos/act, use -run-with flag.
+
+    $ actionlint simulate -event push -ref refs/heads/main -run-with act
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+	if paths != "" {
+		ev.paths = strings.Split(paths, ",")
+	}
+
+	ctx, err := cmd.readMockContexts(contexts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	ev.contexts = ctx
+
+	files := flags.Args()
+	if len(files) == 0 {
+		p, err := NewProjects().At(".")
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		if p == nil {
+			fmt.Fprintln(cmd.Stderr, "no project was found in any parent directories of the current directory. check workflows directory is put correctly in your Git repository")
+			return ExitStatusFailure
+		}
+		files, err = findWorkflowFiles(p.WorkflowsDir())
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+	}
+
+	jobs := [][2]string{}
+	for _, path := range files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "could not read %q: %s\n", path, err)
+			return ExitStatusFailure
+		}
+		w, errs := Parse(b)
+		if w == nil {
+			fmt.Fprintf(cmd.Stderr, "could not parse %q: %s\n", path, errs[0].Message)
+			return ExitStatusFailure
+		}
+		r := simulateWorkflow(w, &ev)
+		printSimulatedWorkflow(cmd.Stdout, path, r)
+		for _, j := range r.jobs {
+			if j.result == "success" {
+				jobs = append(jobs, [2]string{path, j.id})
+			}
+		}
+	}
+
+	if exportAct == "" && runWith == "" {
+		return ExitStatusSuccessNoProblem
+	}
+	if err := cmd.runAct(&ev, jobs, exportAct, runWith); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	return ExitStatusSuccessNoProblem
+}
+
+// runAct exports the mock of event payload to the directory and prints command lines of nektos/act
+// to run the jobs. When the act command is given, it runs the jobs one by one with the command.
+// Each element of jobs is a pair of the workflow file path and the job ID.
+func (cmd *Command) runAct(ev *simulateEvent, jobs [][2]string, dir string, act string) error {
+	b, err := json.MarshalIndent(simulateEventPayload(ev), "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode the mock of event payload into JSON: %w", err)
+	}
 
-Flags:
-`, b, b, b)
+	if dir == "" {
+		d, err := os.MkdirTemp("", "actionlint-act-")
+		if err != nil {
+			return fmt.Errorf("could not create temporary directory for the mock of event payload: %w", err)
+		}
+		defer os.RemoveAll(d)
+		dir = d
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create directory %q to export the mock of event payload: %w", dir, err)
+	}
+	payload := filepath.Join(dir, "event.json")
+	if err := os.WriteFile(payload, b, 0644); err != nil {
+		return fmt.Errorf("could not write the mock of event payload to %q: %w", payload, err)
+	}
+
+	if act == "" {
+		fmt.Fprintf(cmd.Stdout, "\nExported the mock of event payload to %s. Run the jobs with the following commands:\n", payload)
+		for _, j := range jobs {
+			args := actArgs(ev, j[0], j[1], payload)
+			for i, a := range args {
+				args[i] = shellQuote(a)
+			}
+			fmt.Fprintf(cmd.Stdout, "  act %s\n", strings.Join(args, " "))
+		}
+		return nil
+	}
+
+	exe, exeArgs, err := findExe(act)
+	if err != nil {
+		return fmt.Errorf("could not find nektos/act command %q: %w", act, err)
+	}
+	for _, j := range jobs {
+		fmt.Fprintf(cmd.Stdout, "\nRunning job %q in %s with %s\n", j[1], j[0], act)
+		c := exec.Command(exe, append(exeArgs, actArgs(ev, j[0], j[1], payload)...)...)
+		c.Stdout = cmd.Stdout
+		c.Stderr = cmd.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("job %q in %s failed with %s: %w", j[1], j[0], act, err)
+		}
+	}
+	return nil
 }
 
-func getCommandVersion() string {
-	if version != "" {
-		return version
+// runAudit runs `actionlint audit` subcommand which lints workflows in many repositories under the
+// root directory and prints an aggregate report.
+func (cmd *Command) runAudit(args []string) int {
+	var root string
+	var org string
+	var format string
+	var top int
+	var ignorePats ignorePatternFlags
+	var opts LinterOptions
+
+	flags := flag.NewFlagSet("actionlint audit", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&root, "root", "", "Directory containing cloned repositories. Repositories are searched recursively. The default is the current directory")
+	flags.StringVar(&org, "org", "", "Organization or user on GitHub whose repositories are audited via GitHub API without cloning them. Token is read from $GITHUB_TOKEN or $GH_TOKEN")
+	flags.StringVar(&format, "format", "table", "Format of the report. One of \"table\", \"csv\", or \"json\"")
+	flags.IntVar(&top, "top", 10, "Number of repositories printed as top offenders in \"table\" format. 0 prints all repositories")
+	flags.Var(&ignorePats, "ignore", "Regular expression matching to error messages you want to ignore. \"rule:name\", \"code:ALXXXX\", and \"id:message-id\" ignore all errors of the rule, the error code, or the message ID. This flag is repeatable")
+	flags.StringVar(&opts.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
+	flags.StringVar(&opts.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.ConfigFile, "config-file", "", "File path to config file shared by all repositories instead of their own config files")
+	flags.BoolVar(&opts.Verbose, "verbose", false, "Enable verbose output")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint audit [FLAGS]
+
+  Lint workflows in all repositories under the root directory and print an
+  aggregate report of numbers of errors per repository, distribution of rules,
+  and top offenders. A repository is a directory which has both .git and
+  .github/workflows. Workflows in all repositories are linted in parallel.
+
+    $ actionlint audit -root /path/to/clones -config-file org.yaml -format csv
+
+  With -org flag, repositories of the organization are listed via GitHub API
+  and only their workflow files and config files are fetched through the
+  contents API. They are linted in memory without cloning repositories.
+
+    $ GITHUB_TOKEN=xxx actionlint audit -org my-org -format csv
+
+  The exit status is 1 when some error was found or some repository could not
+  be linted.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(cmd.Stderr, "audit subcommand takes no argument. use -root flag to specify the directory. see 'actionlint audit -h'")
+		return ExitStatusInvalidCommandOption
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(cmd.Stderr, "unknown format %q of audit report. it must be one of \"table\", \"csv\", or \"json\"\n", format)
+		return ExitStatusInvalidCommandOption
+	}
+	if org != "" && root != "" {
+		fmt.Fprintln(cmd.Stderr, "-org and -root flags cannot be used at the same time")
+		return ExitStatusInvalidCommandOption
+	}
+	var client *GitHubClient
+	if org != "" {
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			token = os.Getenv("GH_TOKEN")
+		}
+		if token == "" {
+			fmt.Fprintln(cmd.Stderr, "token to access GitHub API must be set to $GITHUB_TOKEN or $GH_TOKEN environment variable with -org flag")
+			return ExitStatusInvalidCommandOption
+		}
+		client = NewGitHubClient(token)
+	}
+	if root == "" {
+		root = "."
 	}
 
-	info, ok := debug.ReadBuildInfo()
-	if !ok {
-		return "unknown" // Reaches only when actionlint package is built outside module
+	opts.IgnorePatterns = ignorePats
+	opts.LogWriter = cmd.Stderr
+
+	if err := loadUserData(); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
 	}
 
-	return info.Main.Version
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	var r *AuditReport
+	if client != nil {
+		r, err = l.AuditRemote(client, org)
+	} else {
+		r, err = l.Audit(root)
+	}
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	if err := r.Print(cmd.Stdout, format, top); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	if r.Total > 0 || r.Failures > 0 {
+		return ExitStatusSuccessProblemFound
+	}
+	return ExitStatusSuccessNoProblem
 }
 
-// Command represents entire actionlint command. Given stdin/stdout/stderr are used for input/output.
-type Command struct {
-	// Stdin is a reader to read input from stdin
-	Stdin io.Reader
-	// Stdout is a writer to write output to stdout
-	Stdout io.Writer
-	// Stderr is a writer to write output to stderr
-	Stderr io.Writer
+// runDoctor runs `actionlint doctor` subcommand which prints readiness of the environment where
+// actionlint runs.
+func (cmd *Command) runDoctor(args []string) int {
+	var opts DoctorOptions
+	var format string
+
+	flags := flag.NewFlagSet("actionlint doctor", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&opts.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
+	flags.StringVar(&opts.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.ConfigFile, "config-file", "", "File path to config file")
+	flags.StringVar(&format, "format", "text", "Format of the report. One of \"text\" or \"json\"")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint doctor [FLAGS] [DIR]
+
+  Diagnose the environment where actionlint runs and print the report. It
+  checks availability and versions of shellcheck and pyflakes, the project
+  root and the config file found from DIR (the current directory by default),
+  health of the directory of datasets downloaded by -update-data, versions of
+  datasets, and proxy and token settings for accessing GitHub API. Pass the
+  same flags as linting to diagnose the same environment. Please attach the
+  output to bug reports.
+
+    $ actionlint doctor
+
+  The exit status is 1 when some check failed with "error" status. Network is
+  never accessed.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(cmd.Stderr, "doctor subcommand takes at most one directory argument. see 'actionlint doctor -h'")
+		return ExitStatusInvalidCommandOption
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(cmd.Stderr, "unknown format %q of doctor report. it must be one of \"text\" or \"json\"\n", format)
+		return ExitStatusInvalidCommandOption
+	}
+	opts.Dir = flags.Arg(0)
+
+	r := Diagnose(&opts)
+	if err := r.Print(cmd.Stdout, format); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	if r.HasError() {
+		return ExitStatusSuccessProblemFound
+	}
+	return ExitStatusSuccessNoProblem
 }
 
-func (cmd *Command) runLinter(args []string, opts *LinterOptions, initConfig bool) ([]*Error, error) {
-	l, err := NewLinter(cmd.Stdout, opts)
+// readDiffInput reads a version of workflow given to diff subcommand. The argument is a file path or
+// "{rev}:{path}" which reads the file at the revision in the Git repository.
+func (cmd *Command) readDiffInput(arg string) ([]byte, error) {
+	b, err := os.ReadFile(arg)
+	if err == nil {
+		return b, nil
+	}
+	i := strings.Index(arg, ":")
+	if !os.IsNotExist(err) || i <= 0 || i == len(arg)-1 {
+		return nil, fmt.Errorf("could not read workflow file: %w", err)
+	}
+	g := &gitIndex{root: "."}
+	b, gerr := g.git("show", arg)
+	if gerr != nil {
+		return nil, fmt.Errorf("could not read workflow file %q in Git repository: %w", arg, gerr)
+	}
+	return b, nil
+}
+
+// loadDiffConfig loads the config file for diff subcommand. When the path is empty, the config file
+// in the repository of the current directory is loaded. It returns nil when no config file is found.
+func (cmd *Command) loadDiffConfig(path string) (*Config, error) {
+	if path != "" {
+		return ReadConfigFile(path)
+	}
+	root := findProjectRoot(".")
+	if root == "" {
+		return nil, nil
+	}
+	return loadRepoConfig(root)
+}
+
+// runDiff runs `actionlint diff` subcommand which reports semantic changes between two versions of
+// a workflow.
+func (cmd *Command) runDiff(args []string) int {
+	var format string
+	var gate bool
+	var configFile string
+
+	flags := flag.NewFlagSet("actionlint diff", flag.ContinueOnError)
+	flags.SetOutput(cmd.Stderr)
+	flags.StringVar(&format, "format", "text", "Format of the report. One of \"text\" or \"json\"")
+	flags.BoolVar(&gate, "policy-gate", false, "Report only changes which violate the policy configured at \"policy-gate\" in config file")
+	flags.StringVar(&configFile, "config-file", "", "File path to config file. By default, config file in the repository of the current directory is used")
+	flags.Usage = func() {
+		fmt.Fprintln(cmd.Stderr, `Usage: actionlint diff [FLAGS] OLD NEW
+
+  Parse two versions of a workflow and report semantic changes between them
+  for reviewing pull requests: jobs added or removed, escalations of
+  permissions of GITHUB_TOKEN, secrets newly used, third-party actions newly
+  used, and changes of triggers. Each of OLD and NEW is a file path or
+  "{rev}:{path}" which reads the file at the Git revision. The path in the
+  latter form is relative to the repository root as 'git show' does.
+
+    $ actionlint diff main:.github/workflows/ci.yaml .github/workflows/ci.yaml
+
+  With -policy-gate, only changes which introduce risks denied by the
+  "policy-gate" configuration in the config file are reported: new write
+  permissions, unpinned third-party actions, and pull_request_target event.
+  This is useful for gating merge queues.
+
+    $ actionlint diff -policy-gate main:.github/workflows/ci.yaml .github/workflows/ci.yaml
+
+  The exit status is 1 when some change is reported, otherwise 0.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitStatusSuccessNoProblem
+		}
+		return ExitStatusInvalidCommandOption
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(cmd.Stderr, "diff subcommand takes exactly two arguments of old and new workflows. see 'actionlint diff -h'")
+		return ExitStatusInvalidCommandOption
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(cmd.Stderr, "unknown format %q of workflow diff. it must be one of \"text\" or \"json\"\n", format)
+		return ExitStatusInvalidCommandOption
+	}
+
+	srcs := make([][]byte, 0, 2)
+	for _, a := range flags.Args() {
+		b, err := cmd.readDiffInput(a)
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		srcs = append(srcs, b)
+	}
+
+	d, err := DiffWorkflows(flags.Arg(0), srcs[0], flags.Arg(1), srcs[1])
 	if err != nil {
-		return nil, err
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	if gate {
+		cfg, err := cmd.loadDiffConfig(configFile)
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		var pg *PolicyGateConfig
+		if cfg != nil {
+			pg = &cfg.PolicyGate
+		}
+		d = &WorkflowDiff{Changes: d.PolicyViolations(pg)}
+	}
+	if err := d.Print(cmd.Stdout, format); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+	if len(d.Changes) > 0 {
+		return ExitStatusSuccessProblemFound
 	}
+	return ExitStatusSuccessNoProblem
+}
 
-	if initConfig {
-		return nil, l.GenerateDefaultConfig("")
+// readMockContexts reads the JSON file of mock values of contexts for evaluating expressions. "-"
+// reads the JSON from stdin. When the path is empty, it returns an empty map.
+func (cmd *Command) readMockContexts(path string) (map[string]any, error) {
+	ctx := map[string]any{}
+	if path == "" {
+		return ctx, nil
+	}
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(cmd.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read contexts from %q: %w", path, err)
+	}
+	if err := json.Unmarshal(b, &ctx); err != nil {
+		return nil, fmt.Errorf("could not parse contexts in %q as JSON object: %w", path, err)
 	}
+	return ctx, nil
+}
 
-	if len(args) == 0 {
-		return l.LintRepository("")
+func (cmd *Command) updateData() error {
+	dir, err := DefaultDataDir()
+	if err != nil {
+		return err
+	}
+	u := os.Getenv("ACTIONLINT_DATA_MANIFEST_URL")
+	if u == "" {
+		u = DefaultDataManifestURL
+	}
+	m, err := UpdateData(u, dir, nil)
+	if err != nil {
+		return err
 	}
+	fmt.Fprintf(cmd.Stdout, "Updated datasets to version %s in %s\n", m.Version, dir)
+	return nil
+}
 
-	if len(args) == 1 && args[0] == "-" {
-		return l.LintStdin(cmd.Stdin)
+// startProfile starts CPU profiling and returns a function to stop it. The function also writes
+// a heap profile. Profiles are written to "cpu.pprof" and "heap.pprof" in the directory. They can
+// be analyzed with `go tool pprof`.
+func startProfile(dir string) (func() error, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create directory for profiles: %w", err)
+	}
+	cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, fmt.Errorf("could not create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, fmt.Errorf("could not start CPU profiling: %w", err)
 	}
 
-	return l.LintFiles(args, nil)
+	stop := func() error {
+		pprof.StopCPUProfile()
+		if err := cpu.Close(); err != nil {
+			return fmt.Errorf("could not write CPU profile: %w", err)
+		}
+		heap, err := os.Create(filepath.Join(dir, "heap.pprof"))
+		if err != nil {
+			return fmt.Errorf("could not create heap profile: %w", err)
+		}
+		defer heap.Close()
+		runtime.GC() // Get up-to-date statistics
+		if err := pprof.WriteHeapProfile(heap); err != nil {
+			return fmt.Errorf("could not write heap profile: %w", err)
+		}
+		return nil
+	}
+	return stop, nil
+}
+
+// loadUserData loads datasets downloaded by -update-data if they exist. They are preferred to the
+// embedded datasets.
+func loadUserData() error {
+	dir, err := DefaultDataDir()
+	if err != nil {
+		return nil // Datasets were never downloaded
+	}
+	_, err = LoadData(dir)
+	return err
+}
+
+// colorFlag is a value of -color option. It can be used as boolean flag (-color) for backward
+// compatibility.
+type colorFlag ColorOptionKind
+
+func (c *colorFlag) String() string {
+	switch ColorOptionKind(*c) {
+	case ColorOptionKindAlways:
+		return "always"
+	case ColorOptionKindNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
+
+func (c *colorFlag) Set(v string) error {
+	switch v {
+	case "auto", "false":
+		*c = colorFlag(ColorOptionKindAuto)
+	case "always", "true":
+		*c = colorFlag(ColorOptionKindAlways)
+	case "never":
+		*c = colorFlag(ColorOptionKindNever)
+	default:
+		return fmt.Errorf("value of -color must be one of \"auto\", \"always\", or \"never\" but got %q", v)
+	}
+	return nil
+}
+
+func (c *colorFlag) IsBoolFlag() bool {
+	return true
 }
 
 type ignorePatternFlags []string
@@ -120,32 +1233,121 @@ func (i *ignorePatternFlags) Set(v string) error {
 	return nil
 }
 
+// listFlags is a value of repeatable flag which also accepts comma-separated values like
+// `-rule expression,if-cond`.
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ",")
+}
+func (l *listFlags) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
 // Main is main function of actionlint. It takes command line arguments as string slice and returns
 // exit status. The args should be entire arguments including the program name, usually given via
 // os.Args.
 func (cmd *Command) Main(args []string) int {
+	if len(args) > 1 && args[1] == "serve" {
+		return cmd.runServer(args[2:])
+	}
+	if len(args) > 1 && args[1] == "daemon" {
+		return cmd.runDaemon(args[2:])
+	}
+	if len(args) > 1 && args[1] == "lsp" {
+		return cmd.runLSP(args[2:])
+	}
+	if len(args) > 1 && args[1] == "eval" {
+		return cmd.runEval(args[2:])
+	}
+	if len(args) > 1 && args[1] == "simulate" {
+		return cmd.runSimulate(args[2:])
+	}
+	if len(args) > 1 && args[1] == "audit" {
+		return cmd.runAudit(args[2:])
+	}
+	if len(args) > 1 && args[1] == "doctor" {
+		return cmd.runDoctor(args[2:])
+	}
+	if len(args) > 1 && args[1] == "diff" {
+		return cmd.runDiff(args[2:])
+	}
+
 	var ver bool
 	var opts LinterOptions
 	var ignorePats ignorePatternFlags
+	var onlyRules listFlags
+	var onlyJobs listFlags
 	var initConfig bool
 	var noColor bool
-	var color bool
+	var color colorFlag
+	var tmpl string
+	var groupBy string
+	var format bool
+	var fix bool
+	var rename string
+	var updateData bool
+	var report string
+	var reportFormat string
+	var explain string
+	var tui bool
+	var staged bool
+	var profile string
+	var schema string
+	var future string
+	var unit string
+	var locale string
+	var categories string
 
 	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
 	flags.SetOutput(cmd.Stderr)
-	flags.Var(&ignorePats, "ignore", "Regular expression matching to error messages you want to ignore. This flag is repeatable")
+	flags.Var(&ignorePats, "ignore", "Regular expression matching to error messages you want to ignore. \"rule:name\", \"code:ALXXXX\", and \"id:message-id\" ignore all errors of the rule, the error code, or the message ID. This flag is repeatable")
 	flags.StringVar(&opts.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
-	flags.StringVar(&opts.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. If empty, pyflakes integration will be disabled")
+	flags.StringVar(&opts.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. \"ruff\" and \"flake8\" are also available. If empty, pyflakes integration will be disabled")
 	flags.BoolVar(&opts.Oneline, "oneline", false, "Use one line per one error. Useful for reading error messages from programs")
+	flags.IntVar(&opts.ContextLines, "context-lines", 0, "Number of lines printed before and after the error line in source snippets")
+	flags.StringVar(&groupBy, "group-by", "", "Aggregate errors by \"file\" or \"rule\" and print the numbers of errors instead of each error. Useful for triage on large codebases")
 	flags.StringVar(&opts.Format, "format", "", "Custom template to format error messages in Go template syntax. See the usage documentation for more details")
 	flags.StringVar(&opts.ConfigFile, "config-file", "", "File path to config file")
 	flags.BoolVar(&initConfig, "init-config", false, "Generate default config file at .github/actionlint.yaml in current project")
 	flags.BoolVar(&noColor, "no-color", false, "Disable colorful output")
-	flags.BoolVar(&color, "color", false, "Always enable colorful output. This is useful to force colorful outputs")
+	flags.Var(&color, "color", "Colorize output. One of \"auto\", \"always\", or \"never\". \"auto\" respects $NO_COLOR environment variable. -color without value is the same as \"always\"")
 	flags.BoolVar(&opts.Verbose, "verbose", false, "Enable verbose output")
 	flags.BoolVar(&opts.Debug, "debug", false, "Enable debug output (for development)")
+	flags.BoolVar(&opts.StrictInternal, "strict-internal", false, "Crash on panics in rules instead of reporting them as internal errors (for development)")
 	flags.BoolVar(&ver, "version", false, "Show version and how this binary was installed")
+	flags.StringVar(&opts.Remote, "remote", "", "Repository on GitHub in \"owner/repo\" form to validate secrets, variables, environments, branches, reusable workflows, and runner labels using GitHub API. Token is read from $GITHUB_TOKEN or $GH_TOKEN")
+	flags.BoolVar(&opts.RemoteLint, "remote-lint", false, "Lint reusable workflows in other repositories fetched with -remote transitively. Errors in them are reported with \"owner/repo/path@ref\" file paths")
+	flags.IntVar(&opts.RemoteMaxDepth, "remote-max-depth", 0, "Maximum depth of nested reusable workflows linted with -remote-lint. 0 means the default depth (10)")
 	flags.StringVar(&opts.StdinFileName, "stdin-filename", "<stdin>", "File name when reading input from stdin")
+	flags.StringVar(&tmpl, "template-mode", "", "Neutralize templating constructs before parsing workflows generated by templates. One of \"helm\", \"jinja\", or \"gotemplate\"")
+	flags.BoolVar(&format, "fmt", false, "Format workflow files in the canonical style instead of checking them. Formatted workflows are printed to stdout")
+	flags.BoolVar(&fix, "fix", false, "Apply fixes to files in place. With -fmt, workflow files are overwritten with formatted ones. Otherwise, errors of rules configured with \"fix: auto\" are fixed and not reported")
+	flags.StringVar(&rename, "rename", "", "Rename job ID or step ID in \"job:old=new\" or \"step:old=new\" form and update all references to it at \"needs:\" and in expressions. Workflow files are overwritten")
+	flags.BoolVar(&updateData, "update-data", false, "Download the latest datasets such as popular actions, runner labels, and webhook events, and use them instead of the embedded ones")
+	flags.StringVar(&schema, "workflow-schema", "", "File path to JSON schema of workflow files such as https://json.schemastore.org/github-workflow.json. Allowed keys of sections in workflow files are imported from it instead of the embedded ones")
+	flags.StringVar(&future, "future-syntax", "error", "How to treat keys which are defined in the workflow syntax but not supported by this version of actionlint yet. One of \"error\", \"warn\", or \"ignore\". \"warn\" prints them as warnings without failing")
+	flags.DurationVar(&opts.ProcessTimeout, "process-timeout", time.Minute, "Timeout of each external process like shellcheck or pyflakes. A process exceeding the timeout is killed and reported as a warning. 0 means no timeout")
+	flags.DurationVar(&opts.FileTimeout, "file-timeout", 0, "Timeout of checking each workflow file. Remaining checks for a file exceeding the timeout are skipped and reported as a warning. 0 means no timeout")
+	flags.BoolVar(&opts.AllowConfigCommands, "allow-config-commands", false, "Run external commands configured at \"script-linters\" and \"flags\" of \"shellcheck\" in config file of the repository. Enable this only for trusted repositories")
+	flags.Int64Var(&opts.MaxFileSize, "max-file-size", defaultMaxFileSize, "Maximum size of workflow file to check in bytes. Larger files are not read to avoid exhausting memory and are reported as errors. Negative value means no limit")
+	flags.Var(&onlyRules, "rule", "Name of rule to run like \"expression\". Other rules are not run. Errors found while parsing workflows are always reported. This flag is repeatable and accepts comma-separated names")
+	flags.Var(&onlyJobs, "job", "ID of job to check. Other jobs are skipped. This flag is repeatable and accepts comma-separated IDs")
+	flags.StringVar(&categories, "only-categories", "", "Comma-separated list of error categories to report such as \"security,syntax\". Errors in other categories are not reported. Categories are \"syntax\", \"expression\", \"security\", \"style\", \"portability\", and \"performance\"")
+	flags.StringVar(&unit, "column-unit", "rune", "Unit to count columns of error positions in lines containing multibyte characters. One of \"rune\", \"byte\", or \"utf16\"")
+	flags.StringVar(&locale, "locale", "en", "Language of error messages. One of \"en\", \"ja\", or \"de\". Messages without translations are output in English. Ignore patterns always match to English messages")
+	flags.StringVar(&report, "report", "", "Print a report instead of checking workflows. \"actions\" prints an inventory of actions, reusable workflows, and container images used in workflows including ones used via composite actions. \"names\" prints names of vars, secrets, and inputs referenced in expressions with their locations")
+	flags.StringVar(&reportFormat, "report-format", "json", "Format of the report printed by -report. One of \"json\", \"csv\", \"spdx\", or \"cyclonedx\" for \"actions\" report. One of \"json\", \"csv\", or \"table\" for \"names\" report")
+	flags.StringVar(&opts.Baseline, "baseline", "", "File path to baseline file in JSON format. Errors recorded in the baseline are not reported")
+	flags.BoolVar(&staged, "staged", false, "Check only workflow files staged in Git. Files are read from the Git index instead of the working tree. Useful for Git pre-commit hooks")
+	flags.BoolVar(&tui, "tui", false, "Triage errors in terminal UI. Errors can be opened in $EDITOR, fixed, and added to the baseline file interactively")
+	flags.StringVar(&profile, "profile", "", "Write CPU and heap profiles of linting to \"cpu.pprof\" and \"heap.pprof\" in the directory. Useful for evaluating performance")
+	flags.StringVar(&explain, "explain", "", "Print the explanation of the error code like \"AL1003\" with examples and exit")
 	flags.Usage = func() {
 		printUsageHeader(cmd.Stderr)
 		flags.PrintDefaults()
@@ -171,19 +1373,126 @@ func (cmd *Command) Main(args []string) int {
 		return ExitStatusSuccessNoProblem
 	}
 
+	if explain != "" {
+		s, ok := ExplainErrorCode(explain)
+		if !ok {
+			fmt.Fprintf(cmd.Stderr, "unknown error code %q. see https://github.com/rhysd/actionlint/blob/main/docs/codes.md for all error codes\n", explain)
+			return ExitStatusInvalidCommandOption
+		}
+		fmt.Fprint(cmd.Stdout, s)
+		return ExitStatusSuccessNoProblem
+	}
+
+	if updateData {
+		if err := cmd.updateData(); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if format {
+		if err := cmd.runFormatter(flags.Args(), fix); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if rename != "" {
+		if err := cmd.runRename(rename, flags.Args()); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
 	opts.IgnorePatterns = ignorePats
+	opts.Fix = fix
+	for _, r := range onlyRules {
+		if ErrorCode(r) == "" {
+			fmt.Fprintf(cmd.Stderr, "unknown rule %q at -rule option. see https://github.com/rhysd/actionlint/blob/main/docs/codes.md for all rules\n", r)
+			return ExitStatusInvalidCommandOption
+		}
+	}
+	opts.OnlyRules = onlyRules
+	opts.OnlyJobs = onlyJobs
+	opts.TemplateMode = TemplateMode(tmpl)
+	opts.FutureSyntax = FutureSyntaxMode(future)
+	opts.ColumnUnit = ColumnUnit(unit)
+	opts.Locale = Locale(locale)
+	cats, err := ParseErrorCategories(categories)
+	if err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusInvalidCommandOption
+	}
+	opts.OnlyCategories = cats
+	opts.GroupBy = ReportGroupBy(groupBy)
+	if opts.Remote != "" {
+		opts.RemoteToken = os.Getenv("GITHUB_TOKEN")
+		if opts.RemoteToken == "" {
+			opts.RemoteToken = os.Getenv("GH_TOKEN")
+		}
+	}
 	opts.LogWriter = cmd.Stderr
 
-	if color {
-		opts.Color = ColorOptionKindAlways
-	}
+	opts.Color = ColorOptionKind(color)
 	if noColor {
 		opts.Color = ColorOptionKindNever
 	}
 
-	errs, err := cmd.runLinter(flags.Args(), &opts, initConfig)
+	if err := loadUserData(); err != nil {
+		fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusFailure
+	}
+
+	if schema != "" {
+		if err := LoadWorkflowSchema(schema); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+	}
+
+	if report != "" {
+		if err := cmd.runReport(report, reportFormat, flags.Args(), &opts); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if tui {
+		errs, err := cmd.runTUI(flags.Args(), &opts)
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		if len(errs) > 0 {
+			return ExitStatusSuccessProblemFound
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if profile != "" {
+		stop, err := startProfile(profile)
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				fmt.Fprintln(cmd.Stderr, err.Error())
+			}
+		}()
+	}
+
+	errs, err := cmd.runLinter(flags.Args(), &opts, initConfig, staged)
 	if err != nil {
 		fmt.Fprintln(cmd.Stderr, err.Error())
+		var unknown *UnknownJobsError
+		if errors.As(err, &unknown) {
+			return ExitStatusInvalidCommandOption
+		}
 		return ExitStatusFailure
 	}
 	if len(errs) > 0 {
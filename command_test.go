@@ -42,3 +42,47 @@ func TestCommandMain(t *testing.T) {
 		t.Errorf("runner-label rule should be ignored by -ignore but it is included in output: %q", out)
 	}
 }
+
+func TestCommandProfile(t *testing.T) {
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+
+	dir := filepath.Join(t.TempDir(), "profile")
+	workflow := filepath.Join("testdata", "ok", "minimal.yaml")
+	status := cmd.Main([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-profile", dir, workflow})
+	if status != 0 {
+		t.Fatal("exit status should be 0 but got", status, output.String())
+	}
+
+	for _, f := range []string{"cpu.pprof", "heap.pprof"} {
+		s, err := os.Stat(filepath.Join(dir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Size() == 0 {
+			t.Errorf("profile %s is empty", f)
+		}
+	}
+}
+
+func TestCommandUnknownJob(t *testing.T) {
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+
+	workflow := filepath.Join("testdata", "ok", "minimal.yaml")
+	status := cmd.Main([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-job", "nope", workflow})
+	if status != ExitStatusInvalidCommandOption {
+		t.Fatal("exit status should be", ExitStatusInvalidCommandOption, "but got", status, output.String())
+	}
+	if out := output.String(); !strings.Contains(out, `unknown job "nope" at -job option`) {
+		t.Fatalf("output should report the unknown job: %q", out)
+	}
+}
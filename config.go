@@ -7,19 +7,108 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"gopkg.in/yaml.v3"
 )
 
-// IgnorePatterns is a list of regular expressions. These patterns are used for filtering errors by
-// matching the error messages.
-type IgnorePatterns []*regexp.Regexp
+// IgnorePattern is a pattern to ignore errors. It matches errors by a rule name with "rule:" prefix
+// like "rule:shellcheck", an error code with "code:" prefix like "code:AL1017", a message ID with
+// "id:" prefix like "id:expression/undefined-property", or a regular expression matching to error
+// messages.
+type IgnorePattern struct {
+	// Rule is a name of rule like "shellcheck". This is empty when the pattern is not "rule:".
+	Rule string
+	// Code is an error code like "AL1017". This is empty when the pattern is not "code:".
+	Code string
+	// ID is a message ID like "expression/undefined-property". This is empty when the pattern is not
+	// "id:".
+	ID string
+	// Message is a regular expression matching to error messages. This is nil when the pattern is
+	// "rule:", "code:", or "id:".
+	Message *regexp.Regexp
+}
+
+// ParseIgnorePattern parses the string as IgnorePattern. It returns an error when the rule name, the
+// error code, or the message ID is unknown or the regular expression is invalid.
+func ParseIgnorePattern(s string) (*IgnorePattern, error) {
+	if strings.HasPrefix(s, "rule:") {
+		r := strings.TrimSpace(s[len("rule:"):])
+		if ErrorCode(r) == "" {
+			ks := make([]string, 0, len(errorCodes))
+			for k := range errorCodes {
+				ks = append(ks, k)
+			}
+			return nil, fmt.Errorf("unknown rule name %q in ignore pattern %q. available rule names are %s", r, s, sortedQuotes(ks))
+		}
+		return &IgnorePattern{Rule: r}, nil
+	}
+
+	if strings.HasPrefix(s, "code:") {
+		c := strings.ToUpper(strings.TrimSpace(s[len("code:"):]))
+		for _, v := range errorCodes {
+			if v == c {
+				return &IgnorePattern{Code: c}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown error code %q in ignore pattern %q. see https://github.com/rhysd/actionlint/blob/main/docs/codes.md for all error codes", c, s)
+	}
+
+	if strings.HasPrefix(s, "id:") {
+		i := strings.TrimSpace(s[len("id:"):])
+		ids := MessageIDs()
+		for _, v := range ids {
+			if v == i {
+				return &IgnorePattern{ID: i}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown message ID %q in ignore pattern %q. available message IDs are %s", i, s, sortedQuotes(ids))
+	}
+
+	r, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression for ignore pattern %q: %s", s, err.Error())
+	}
+	return &IgnorePattern{Message: r}, nil
+}
+
+// Match returns whether the given error should be ignored by the pattern.
+func (p *IgnorePattern) Match(err *Error) bool {
+	switch {
+	case p.Rule != "":
+		return err.Kind == p.Rule
+	case p.Code != "":
+		return err.Code() == p.Code
+	case p.ID != "":
+		return err.MessageID == p.ID
+	default:
+		return p.Message.MatchString(err.Message)
+	}
+}
+
+// String returns the string representation of the pattern.
+func (p *IgnorePattern) String() string {
+	switch {
+	case p.Rule != "":
+		return "rule:" + p.Rule
+	case p.Code != "":
+		return "code:" + p.Code
+	case p.ID != "":
+		return "id:" + p.ID
+	default:
+		return p.Message.String()
+	}
+}
+
+// IgnorePatterns is a list of patterns. These patterns are used for filtering errors by matching the
+// rule names, the error codes, the message IDs, or the error messages.
+type IgnorePatterns []*IgnorePattern
 
 // Match returns whether the given error should be ignored due to the "ignore" configuration.
 func (pats IgnorePatterns) Match(err *Error) bool {
-	for _, r := range pats {
-		if r.MatchString(err.Message) {
+	for _, p := range pats {
+		if p.Match(err) {
 			return true
 		}
 	}
@@ -31,15 +120,15 @@ func (pats *IgnorePatterns) UnmarshalYAML(n *yaml.Node) error {
 	if n.Kind != yaml.SequenceNode {
 		return fmt.Errorf("yaml: \"ignore\" must be a sequence node at line:%d,col:%d", n.Line, n.Column)
 	}
-	rs := make([]*regexp.Regexp, 0, len(n.Content))
-	for _, p := range n.Content {
-		r, err := regexp.Compile(p.Value)
+	ps := make([]*IgnorePattern, 0, len(n.Content))
+	for _, c := range n.Content {
+		p, err := ParseIgnorePattern(c.Value)
 		if err != nil {
-			return fmt.Errorf("invalid regular expression %q in \"ignore\" at line%d,col:%d: %w", p.Value, n.Line, n.Column, err)
+			return fmt.Errorf("%s in \"ignore\" at line:%d,col:%d", err, c.Line, c.Column)
 		}
-		rs = append(rs, r)
+		ps = append(ps, p)
 	}
-	*pats = rs
+	*pats = ps
 	return nil
 }
 
@@ -49,6 +138,325 @@ type PathConfig struct {
 	// Ignore is a list of patterns. They are used for ignoring errors by matching to the error messages.
 	// It is similar to the "-ignore" command line option.
 	Ignore IgnorePatterns `yaml:"ignore"`
+	// Shellcheck is a configuration for the "shellcheck" rule applied to the file paths. Its flags
+	// and excluded rules are added to the ones in the "rules" mapping.
+	Shellcheck ShellcheckRuleConfig `yaml:"shellcheck"`
+}
+
+// ConfigPattern is a regular expression in the configuration file. It is compiled on parsing the
+// configuration file so that invalid patterns are reported early.
+type ConfigPattern struct {
+	*regexp.Regexp
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (pat *ConfigPattern) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("yaml: regular expression must be a string at line:%d,col:%d", n.Line, n.Column)
+	}
+	r, err := regexp.Compile(n.Value)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression %q at line:%d,col:%d: %w", n.Value, n.Line, n.Column, err)
+	}
+	pat.Regexp = r
+	return nil
+}
+
+// YAMLAnchorRuleConfig is a configuration for the "yaml-anchor" rule.
+type YAMLAnchorRuleConfig struct {
+	// Disable disables the rule. This is useful when workflow files are preprocessed by some tool
+	// which expands YAML anchors and aliases before they are pushed to GitHub.
+	Disable bool `yaml:"disable"`
+}
+
+// SetupCacheRuleConfig is a configuration for the "setup-cache" rule.
+type SetupCacheRuleConfig struct {
+	// Disable disables the rule. This is useful when caching dependencies is not desired in the
+	// repository.
+	Disable bool `yaml:"disable"`
+}
+
+// ShellcheckRuleConfig is a configuration for the "shellcheck" rule.
+type ShellcheckRuleConfig struct {
+	// Flags is a list of extra command line arguments passed to shellcheck like ["-S", "warning"].
+	Flags []string `yaml:"flags"`
+	// Exclude is a list of shellcheck rules like "SC2086" excluded in addition to the rules
+	// excluded by actionlint by default.
+	Exclude []string `yaml:"exclude"`
+}
+
+func (c *ShellcheckRuleConfig) validate(where string) error {
+	for _, f := range c.Flags {
+		if strings.HasPrefix(f, "-f") || strings.HasPrefix(f, "--format") {
+			return fmt.Errorf("flag %q cannot be set at \"flags\" in \"shellcheck\" %s since actionlint parses the output in JSON format", f, where)
+		}
+	}
+	for _, e := range c.Exclude {
+		if !reShellcheckRuleID.MatchString(e) {
+			return fmt.Errorf("invalid shellcheck rule %q at \"exclude\" in \"shellcheck\" %s. it must be in the format like \"SC2086\"", e, where)
+		}
+	}
+	return nil
+}
+
+var reShellcheckRuleID = regexp.MustCompile(`^SC\d+$`)
+
+// StyleRuleConfig is a configuration for the "style" rule. Each check is disabled by default.
+type StyleRuleConfig struct {
+	// Indentation is a width of indentation. When this value is greater than zero, indentation of
+	// nested mappings and sequences is checked.
+	Indentation int `yaml:"indentation"`
+	// Truthy enables the check for truthy values such as "yes", "no", "on", "off", which are treated
+	// as booleans in YAML 1.1 and as strings in YAML 1.2.
+	Truthy bool `yaml:"truthy"`
+	// LineLength is the maximum length of lines. When this value is greater than zero, length of
+	// each line is checked.
+	LineLength int `yaml:"line-length"`
+	// TrailingSpaces enables the check for trailing spaces at end of lines.
+	TrailingSpaces bool `yaml:"trailing-spaces"`
+	// DocumentStart is a policy for document start marker "---". "require" requires the marker at top
+	// of the workflow and "forbid" forbids the marker. Empty string means no check.
+	DocumentStart string `yaml:"document-start"`
+}
+
+// StepNameRuleConfig is a configuration for the "step-name" rule. Each check is disabled by default.
+type StepNameRuleConfig struct {
+	// Require requires "name:" in all steps.
+	Require bool `yaml:"require"`
+	// Unique requires step names to be unique within a job.
+	Unique bool `yaml:"unique"`
+	// Pattern is a regular expression which step names must match.
+	Pattern *ConfigPattern `yaml:"pattern"`
+}
+
+// RunScriptRuleConfig is a configuration for the "run-script" rule. Each check is disabled by default.
+type RunScriptRuleConfig struct {
+	// MaxLines is the maximum number of lines of a script at "run:". When this value is greater than
+	// zero, the number of lines is checked.
+	MaxLines int `yaml:"max-lines"`
+	// MaxComplexity is the maximum number of loops, conditionals, and heredocs in a script at "run:".
+	// When this value is greater than zero, the complexity is checked.
+	MaxComplexity int `yaml:"max-complexity"`
+}
+
+// RunnerLabelRuleConfig is a configuration for the "runner-label" rule.
+type RunnerLabelRuleConfig struct {
+	// Lifecycle is a strictness of the check for lifecycle of GitHub-hosted runner labels. "retired"
+	// reports labels which were already retired. "deprecated" also reports labels which are
+	// deprecated or will be retired soon. Empty string means no check.
+	Lifecycle string `yaml:"lifecycle"`
+}
+
+// RunnerToolsRuleConfig is a configuration for the "runner-tools" rule.
+type RunnerToolsRuleConfig struct {
+	// Labels is a mapping from runner labels to commands installed on the runners. Steps on
+	// self-hosted runners are checked only when one of their labels is listed here.
+	Labels map[string][]string `yaml:"labels"`
+}
+
+// EnvVarRuleConfig is a configuration for the "env-var" rule. Each check is disabled by default.
+type EnvVarRuleConfig struct {
+	// POSIXNames requires names of environment variables to be valid POSIX names which consist of
+	// alphabets, digits, and underscores.
+	POSIXNames bool `yaml:"posix-names"`
+	// Shadowing reports environment variables at "env:" which shadow variables at outer levels with
+	// different type of values.
+	Shadowing bool `yaml:"shadowing"`
+	// UndefinedReferences reports references to environment variables like `env.FOO` which are not
+	// defined at "env:" in the scope nor set via $GITHUB_ENV in previous steps.
+	UndefinedReferences bool `yaml:"undefined-references"`
+}
+
+// UnusedRuleConfig is a configuration for the "unused" rule. Each check is disabled by default.
+type UnusedRuleConfig struct {
+	// ReusableWorkflows reports local reusable workflows which are not called by any workflow
+	// triggered in the repository.
+	ReusableWorkflows bool `yaml:"reusable-workflows"`
+	// Actions reports local actions in ".github/actions" directory which are not used by any
+	// workflow triggered in the repository.
+	Actions bool `yaml:"actions"`
+}
+
+// ScheduleRuleConfig is a configuration for the "schedule" rule.
+type ScheduleRuleConfig struct {
+	// AssumeTimezone is a name of IANA time zone like "America/Los_Angeles" where the team reads
+	// schedules. Times of schedules reported by the rule are annotated with the local times in the
+	// time zone. Times in comments without time zone are assumed to be in this time zone.
+	AssumeTimezone string `yaml:"assume-timezone"`
+	// Collisions reports multiple schedules which trigger the workflow at the same minute. This is
+	// disabled by default since overlapping schedules are sometimes intentional, for example a
+	// frequent schedule and a daily schedule running different jobs.
+	Collisions bool `yaml:"collisions"`
+}
+
+// CheckoutRuleConfig is a configuration for the "checkout" rule. Each check is disabled by default.
+type CheckoutRuleConfig struct {
+	// RunScripts reports scripts at "run:" which run files in the repository like "./build.sh" before
+	// the repository is checked out.
+	RunScripts bool `yaml:"run-scripts"`
+	// LocalActions reports local actions like "./.github/actions/foo" used before the repository is
+	// checked out.
+	LocalActions bool `yaml:"local-actions"`
+	// VersionFiles reports inputs of setup actions like "node-version-file" which refer files in the
+	// repository before the repository is checked out.
+	VersionFiles bool `yaml:"version-files"`
+}
+
+// MarketplaceRuleConfig is a configuration for the "marketplace" rule. The rule is disabled by
+// default.
+type MarketplaceRuleConfig struct {
+	// Actions is a list of glob patterns of directories of actions published to GitHub Marketplace
+	// like "." or "actions/*". The patterns are relative to the repository root. Metadata files of
+	// the matched actions are checked.
+	Actions []string `yaml:"actions"`
+}
+
+// WorkflowNameRuleConfig is a configuration for the "workflow-name" rule. The rule is disabled by
+// default.
+type WorkflowNameRuleConfig struct {
+	// Unique reports workflow names which are duplicated across workflow files in the repository.
+	Unique bool `yaml:"unique"`
+}
+
+// SecretOutputRuleConfig is a configuration for the "secret-output" rule.
+type SecretOutputRuleConfig struct {
+	// JobOutputs reports job outputs containing secrets. This is disabled by default since secrets in
+	// job outputs are sometimes intentional. For example, optional secrets which may be empty are
+	// passed through outputs of reusable workflows.
+	JobOutputs bool `yaml:"job-outputs"`
+}
+
+// ExpressionRuleConfig is a configuration for the "expression" rule.
+type ExpressionRuleConfig struct {
+	// UnusedMatrixValues reports matrix values which are defined in "strategy.matrix" but never
+	// referenced as "matrix.<key>" in the job. This is disabled by default since matrix values are
+	// sometimes defined only to run the same job multiple times or to show them in job names.
+	UnusedMatrixValues bool `yaml:"unused-matrix-values"`
+}
+
+// EnvFileRuleConfig is a configuration for the "env-file" rule.
+type EnvFileRuleConfig struct {
+	// UnquotedRedirects reports environment files which are not quoted at redirections like
+	// `>> $GITHUB_OUTPUT`. This is disabled by default since the paths of the files do not contain
+	// spaces on GitHub-hosted runners.
+	UnquotedRedirects bool `yaml:"unquoted-redirects"`
+}
+
+// ServicesRuleConfig is a configuration for the "services" rule.
+type ServicesRuleConfig struct {
+	// LocalhostPorts reports ports of localhost used in "run:" scripts which are not exposed to host by
+	// any service. This is disabled by default since the ports are sometimes served by processes
+	// started in earlier steps of the job like "npm start &".
+	LocalhostPorts bool `yaml:"localhost-ports"`
+}
+
+// RepositoryDispatchConfig is a configuration for repository_dispatch event. This is for the
+// "repository-dispatch" mapping in the configuration file.
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch
+type RepositoryDispatchConfig struct {
+	// Types is a list of event types sent to the repository via the repository dispatch API. When this
+	// value is nil, types at `on.repository_dispatch.types` will not be checked.
+	Types []string `yaml:"types"`
+	// ClientPayload is a JSON schema of the client_payload sent with the event. When this value is
+	// not nil, `github.event.client_payload` is typed with the schema in workflows triggered by
+	// repository_dispatch event.
+	ClientPayload *JSONSchema `yaml:"client-payload"`
+}
+
+// PolicyGateConfig is a configuration for the policy gate of diff subcommand. This is for the
+// "policy-gate" mapping in the configuration file.
+type PolicyGateConfig struct {
+	// Deny is a list of risk categories of changes which fail the policy gate like
+	// "write-permission". When this value is nil, all categories are denied.
+	Deny []string `yaml:"deny"`
+	// AllowUnpinnedActions is a list of glob patterns of third-party actions like "my-org/*" which
+	// are allowed to be used without pinning their versions to commit SHAs.
+	AllowUnpinnedActions []string `yaml:"allow-unpinned-actions"`
+}
+
+// RulesConfig is configuration for each rule. This is for the "rules" mapping in the configuration
+// file. Keys of the mapping are rule names.
+type RulesConfig struct {
+	// YAMLAnchor is a configuration for the "yaml-anchor" rule.
+	YAMLAnchor YAMLAnchorRuleConfig `yaml:"yaml-anchor"`
+	// Style is a configuration for the "style" rule.
+	Style StyleRuleConfig `yaml:"style"`
+	// StepName is a configuration for the "step-name" rule.
+	StepName StepNameRuleConfig `yaml:"step-name"`
+	// RunScript is a configuration for the "run-script" rule.
+	RunScript RunScriptRuleConfig `yaml:"run-script"`
+	// RunnerLabel is a configuration for the "runner-label" rule.
+	RunnerLabel RunnerLabelRuleConfig `yaml:"runner-label"`
+	// RunnerTools is a configuration for the "runner-tools" rule.
+	RunnerTools RunnerToolsRuleConfig `yaml:"runner-tools"`
+	// EnvVar is a configuration for the "env-var" rule.
+	EnvVar EnvVarRuleConfig `yaml:"env-var"`
+	// Unused is a configuration for the "unused" rule.
+	Unused UnusedRuleConfig `yaml:"unused"`
+	// Schedule is a configuration for the "schedule" rule.
+	Schedule ScheduleRuleConfig `yaml:"schedule"`
+	// Checkout is a configuration for the "checkout" rule.
+	Checkout CheckoutRuleConfig `yaml:"checkout"`
+	// SetupCache is a configuration for the "setup-cache" rule.
+	SetupCache SetupCacheRuleConfig `yaml:"setup-cache"`
+	// Shellcheck is a configuration for the "shellcheck" rule.
+	Shellcheck ShellcheckRuleConfig `yaml:"shellcheck"`
+	// Marketplace is a configuration for the "marketplace" rule.
+	Marketplace MarketplaceRuleConfig `yaml:"marketplace"`
+	// WorkflowName is a configuration for the "workflow-name" rule.
+	WorkflowName WorkflowNameRuleConfig `yaml:"workflow-name"`
+	// SecretOutput is a configuration for the "secret-output" rule.
+	SecretOutput SecretOutputRuleConfig `yaml:"secret-output"`
+	// Expression is a configuration for the "expression" rule.
+	Expression ExpressionRuleConfig `yaml:"expression"`
+	// EnvFile is a configuration for the "env-file" rule.
+	EnvFile EnvFileRuleConfig `yaml:"env-file"`
+	// Services is a configuration for the "services" rule.
+	Services ServicesRuleConfig `yaml:"services"`
+	// Fix is a mapping from rule names to how errors of the rules are fixed. It is set by "fix" key
+	// in the config of each rule like `style: { fix: auto }`. "auto" means the errors are fixed
+	// silently without being reported when the linter runs with -fix.
+	Fix map[string]string `yaml:"-"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *RulesConfig) UnmarshalYAML(n *yaml.Node) error {
+	type rules RulesConfig // Avoid infinite recursion
+	if err := n.Decode((*rules)(c)); err != nil {
+		return err
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if v.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(v.Content); j += 2 {
+			if v.Content[j].Value != "fix" {
+				continue
+			}
+			f := v.Content[j+1]
+			if _, ok := errorCodes[k.Value]; !ok {
+				return fmt.Errorf("yaml: unknown rule %q for \"fix\" at line:%d,col:%d", k.Value, k.Line, k.Column)
+			}
+			if f.Kind != yaml.ScalarNode || f.Value != "auto" {
+				return fmt.Errorf("yaml: \"fix\" in %q rule config must be \"auto\" but got %q at line:%d,col:%d", k.Value, f.Value, f.Line, f.Column)
+			}
+			if c.Fix == nil {
+				c.Fix = map[string]string{}
+			}
+			c.Fix[k.Value] = f.Value
+		}
+	}
+	return nil
+}
+
+// AutoFix returns true when errors of the rule are configured to be fixed silently with
+// `fix: auto`.
+func (c *RulesConfig) AutoFix(rule string) bool {
+	return c.Fix[rule] == "auto"
 }
 
 // Config is configuration of actionlint. This struct instance is parsed from "actionlint.yaml"
@@ -64,9 +472,28 @@ type Config struct {
 	// listed here as undefined config variables.
 	// https://docs.github.com/en/actions/learn-github-actions/variables
 	ConfigVariables []string `yaml:"config-variables"`
+	// Secrets is names of secrets defined in the repository or its organization. When this value is nil,
+	// property names of `secrets` context will not be checked. Otherwise actionlint will report a name which
+	// is not listed here as undefined secrets in workflows which can access the repository secrets, and
+	// required secrets of reusable workflows called with `secrets: inherit` which are not listed here.
+	// https://docs.github.com/en/actions/security-guides/using-secrets-in-github-actions
+	Secrets []string `yaml:"secrets"`
+	// RepositoryDispatch is a "repository-dispatch" mapping in the configuration file. It configures event
+	// types and the payload of repository_dispatch event.
+	RepositoryDispatch RepositoryDispatchConfig `yaml:"repository-dispatch"`
+	// PolicyGate is a "policy-gate" mapping in the configuration file. It configures which risky
+	// changes fail `actionlint diff -policy-gate`.
+	PolicyGate PolicyGateConfig `yaml:"policy-gate"`
+	// ScriptLinters is a "script-linters" mapping in the configuration file. The keys are shell names
+	// at "shell:" like "ruby" and the values are command lines of external linters like
+	// ["ruby", "-wc"]. Scripts at "run:" run with the shells are checked by the linters. The linters
+	// read the scripts from stdin.
+	ScriptLinters map[string][]string `yaml:"script-linters"`
 	// Paths is a "paths" mapping in the configuration file. The keys are glob patterns to match file paths.
 	// And the values are corresponding configurations applied to the file paths.
 	Paths map[string]PathConfig `yaml:"paths"`
+	// Rules is a "rules" mapping in the configuration file. It configures behavior of each rule.
+	Rules RulesConfig `yaml:"rules"`
 }
 
 // PathConfigs returns a list of all PathConfig values matching to the given file path. The path must
@@ -86,18 +513,93 @@ func (cfg *Config) PathConfigs(path string) []PathConfig {
 	return ret
 }
 
+// hasCommands returns true when the config specifies external commands or their arguments which
+// actionlint runs. They are "script-linters" and "flags" of "shellcheck".
+func (cfg *Config) hasCommands() bool {
+	if cfg == nil {
+		return false
+	}
+	if len(cfg.ScriptLinters) > 0 || len(cfg.Rules.Shellcheck.Flags) > 0 {
+		return true
+	}
+	for _, c := range cfg.Paths {
+		if len(c.Shellcheck.Flags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutCommands returns a copy of the config without external commands and their arguments. A
+// config file in a repository is written by anyone who can send a pull request to the repository so
+// running the commands in it means running arbitrary commands.
+func (cfg *Config) withoutCommands() *Config {
+	c := *cfg
+	c.ScriptLinters = nil
+	c.Rules.Shellcheck.Flags = nil
+	if len(cfg.Paths) > 0 {
+		c.Paths = make(map[string]PathConfig, len(cfg.Paths))
+		for p, pc := range cfg.Paths {
+			pc.Shellcheck.Flags = nil
+			c.Paths[p] = pc
+		}
+	}
+	return &c
+}
+
 // ParseConfig parses the given bytes as an actionlint config file. When deserializing the YAML file
 // or the config validation fails, this function returns an error.
 func ParseConfig(b []byte) (*Config, error) {
 	var c Config
-	if err := yaml.Unmarshal(b, &c); err != nil {
+	if err := unmarshalYAML(b, &c); err != nil {
 		msg := strings.ReplaceAll(err.Error(), "\n", " ")
 		return nil, errors.New(msg)
 	}
-	for pat := range c.Paths {
+	for pat, pc := range c.Paths {
 		if !doublestar.ValidatePattern(pat) {
 			return nil, fmt.Errorf("invalid glob pattern %q in \"paths\"", pat)
 		}
+		if err := pc.Shellcheck.validate(fmt.Sprintf("config of path %q", pat)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Rules.Shellcheck.validate("rule config"); err != nil {
+		return nil, err
+	}
+	for sh, cmd := range c.ScriptLinters {
+		if len(cmd) == 0 || cmd[0] == "" {
+			return nil, fmt.Errorf("command for shell %q at \"script-linters\" must not be empty", sh)
+		}
+	}
+	for _, r := range c.PolicyGate.Deny {
+		if !containsString(allPolicyRisks, r) {
+			return nil, fmt.Errorf("unknown risk category %q at \"deny\" in \"policy-gate\". it must be one of %s", r, strings.Join(allPolicyRisks, ", "))
+		}
+	}
+	for _, pat := range c.PolicyGate.AllowUnpinnedActions {
+		if !doublestar.ValidatePattern(pat) {
+			return nil, fmt.Errorf("invalid glob pattern %q at \"allow-unpinned-actions\" in \"policy-gate\"", pat)
+		}
+	}
+	for _, pat := range c.Rules.Marketplace.Actions {
+		if !doublestar.ValidatePattern(pat) {
+			return nil, fmt.Errorf("invalid glob pattern %q at \"actions\" in \"marketplace\" rule config", pat)
+		}
+	}
+	switch d := c.Rules.Style.DocumentStart; d {
+	case "", "require", "forbid":
+	default:
+		return nil, fmt.Errorf("\"document-start\" in \"style\" rule config must be one of \"require\" or \"forbid\" but got %q", d)
+	}
+	if tz := c.Rules.Schedule.AssumeTimezone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q at \"assume-timezone\" in \"schedule\" rule config: %w", tz, err)
+		}
+	}
+	switch l := c.Rules.RunnerLabel.Lifecycle; l {
+	case "", "retired", "deprecated":
+	default:
+		return nil, fmt.Errorf("\"lifecycle\" in \"runner-label\" rule config must be one of \"retired\" or \"deprecated\" but got %q", l)
 	}
 	return &c, nil
 }
@@ -143,6 +645,27 @@ func writeDefaultConfigFile(path string) error {
 # Empty array means no configuration variable is allowed.
 config-variables: null
 
+# Secrets in array of strings defined in your repository or organization.
+# ` + "`null`" + ` means disabling secrets check.
+secrets: null
+
+# Configuration for repository_dispatch event. "types" is an array of event
+# types sent via the repository dispatch API. ` + "`null`" + ` means disabling event
+# types check. "client-payload" is a JSON schema of the client_payload of the
+# event. It types ` + "`github.event.client_payload`" + ` in workflows.
+repository-dispatch:
+  types: null
+#  client-payload:
+#    type: object
+#    properties:
+#      ref:
+#        type: string
+
+# External linters for scripts at "run:". The keys are shell names at "shell:"
+# and the values are command lines of the linters reading scripts from stdin.
+script-linters:
+#  ruby: [rubocop, --only, Lint, --format, emacs, --stdin, stdin.rb]
+
 # Configuration for file paths. The keys are glob patterns to match to file
 # paths relative to the repository root. The values are the configurations for
 # the file paths. Note that the path separator is always '/'.
@@ -153,6 +676,90 @@ config-variables: null
 paths:
 #  .github/workflows/**/*.yml:
 #    ignore: []
+
+# Configuration for each rule. The keys are rule names.
+rules:
+  # "yaml-anchor" rule reports YAML anchors and aliases in workflows. Set
+  # "disable: true" when your workflows are preprocessed by some tool.
+  yaml-anchor:
+    disable: false
+  # "style" rule checks coding style of workflow files. All checks are
+  # disabled by default.
+  style:
+    # Width of indentation. 0 disables the check.
+    indentation: 0
+    # Report truthy values like "yes" or "on".
+    truthy: false
+    # Maximum length of lines. 0 disables the check.
+    line-length: 0
+    # Report trailing spaces at end of lines.
+    trailing-spaces: false
+    # "require" or "forbid" document start marker "---". Empty disables the check.
+    document-start: ""
+  # "step-name" rule checks names of steps. All checks are disabled by default.
+  step-name:
+    # Report steps without "name:".
+    require: false
+    # Report step names duplicated in the same job.
+    unique: false
+    # Regular expression which step names must match. null disables the check.
+    pattern: null
+  # "run-script" rule reports long or complex scripts at "run:". All checks
+  # are disabled by default.
+  run-script:
+    # Maximum number of lines of a script. 0 disables the check.
+    max-lines: 0
+    # Maximum number of loops, conditionals, and heredocs. 0 disables the check.
+    max-complexity: 0
+  # "schedule" rule checks schedules at "on.schedule" which are evaluated in UTC.
+  schedule:
+    # IANA time zone like "America/Los_Angeles" to show local times of
+    # schedules. Empty disables the annotation.
+    assume-timezone: ""
+    # Report multiple schedules triggering the workflow at the same minute.
+    collisions: false
+  # "checkout" rule reports steps referring files in the repository before
+  # "actions/checkout". All checks are disabled by default.
+  checkout:
+    # Report scripts at "run:" running files like "./build.sh".
+    run-scripts: false
+    # Report local actions like "./.github/actions/foo".
+    local-actions: false
+    # Report inputs like "node-version-file" of setup actions.
+    version-files: false
+  # "setup-cache" rule reports setup actions not caching dependencies though
+  # lockfiles exist. Set "disable: true" when caching is not desired.
+  setup-cache:
+    disable: false
+  # "shellcheck" rule checks scripts at "run:" with shellcheck.
+  shellcheck:
+    # Extra command line arguments passed to shellcheck like ["-S", "warning"].
+    flags: []
+    # shellcheck rules excluded in addition to the default ones like [SC2086].
+    exclude: []
+  # "marketplace" rule checks actions published to GitHub Marketplace are ready to publish.
+  marketplace:
+    # Glob patterns of the action directories relative to the repository root like ["."].
+    actions: []
+  # "workflow-name" rule checks workflow names across workflow files.
+  workflow-name:
+    # Report workflow names which are duplicated in the repository.
+    unique: false
+  # "secret-output" rule checks secrets leaking through outputs of jobs and steps.
+  secret-output:
+    # Report job outputs containing secrets.
+    job-outputs: false
+  # "expression" rule checks expressions in ${{ }}.
+  expression:
+    # Report matrix values which are never referenced as "matrix.<key>" in the job.
+    unused-matrix-values: false
+  env-file:
+    # Report environment files which are not quoted at redirections like ">> $GITHUB_OUTPUT".
+    unquoted-redirects: false
+  # "services" rule checks service containers at "services:".
+  services:
+    # Report ports of localhost in "run:" scripts not exposed by any service.
+    localhost-ports: false
 `)
 	if err := os.WriteFile(path, b, 0644); err != nil {
 		return fmt.Errorf("could not write default configuration file at %q: %w", path, err)
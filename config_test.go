@@ -79,7 +79,7 @@ paths:
   foo:
     ignore: ['(foo']
 `,
-			want: `invalid regular expression "(foo" in "ignore"`,
+			want: `invalid regular expression for ignore pattern "(foo": error parsing regexp: missing closing ): ` + "`(foo`" + ` in "ignore" at line:4,col:14`,
 		},
 		{
 			in: `
@@ -88,6 +88,119 @@ paths:
 `,
 			want: `invalid glob pattern`,
 		},
+		{
+			in: `
+rules:
+  style:
+    document-start: always
+`,
+			want: `"document-start" in "style" rule config must be one of`,
+		},
+		{
+			in: `
+rules:
+  runner-label:
+    lifecycle: strict
+`,
+			want: `"lifecycle" in "runner-label" rule config must be one of`,
+		},
+		{
+			in: `
+rules:
+  step-name:
+    pattern: '(foo'
+`,
+			want: `invalid regular expression "(foo"`,
+		},
+		{
+			in: `
+repository-dispatch:
+  client-payload:
+    type: object
+    properties:
+      ref:
+        type: str
+`,
+			want: `line 7: unknown type "str" in JSON schema`,
+		},
+		{
+			in: `
+repository-dispatch:
+  client-payload:
+    type: {}
+`,
+			want: `line 4: "type" in JSON schema must be string or array of strings`,
+		},
+		{
+			in: `
+policy-gate:
+  deny: [write-permissions]
+`,
+			want: `unknown risk category "write-permissions" at "deny" in "policy-gate"`,
+		},
+		{
+			in: `
+policy-gate:
+  allow-unpinned-actions: ['foo/{bar']
+`,
+			want: `invalid glob pattern "foo/{bar" at "allow-unpinned-actions" in "policy-gate"`,
+		},
+		{
+			in: `
+rules:
+  schedule:
+    assume-timezone: Mars/Olympus_Mons
+`,
+			want: `invalid time zone "Mars/Olympus_Mons" at "assume-timezone" in "schedule" rule config`,
+		},
+		{
+			in: `
+rules:
+  marketplace:
+    actions: ['actions/[*']
+`,
+			want: `invalid glob pattern "actions/[*" at "actions" in "marketplace" rule config`,
+		},
+		{
+			in: `
+rules:
+  shellcheck:
+    flags: ['--format=tty']
+`,
+			want: `flag "--format=tty" cannot be set at "flags" in "shellcheck" rule config`,
+		},
+		{
+			in: `
+paths:
+  .github/workflows/*.yaml:
+    shellcheck:
+      exclude: [2086]
+`,
+			want: `invalid shellcheck rule "2086" at "exclude" in "shellcheck" config of path ".github/workflows/*.yaml"`,
+		},
+		{
+			in: `
+script-linters:
+  ruby: []
+`,
+			want: `command for shell "ruby" at "script-linters" must not be empty`,
+		},
+		{
+			in: `
+rules:
+  style:
+    fix: always
+`,
+			want: `"fix" in "style" rule config must be "auto" but got "always" at line:4,col:10`,
+		},
+		{
+			in: `
+rules:
+  styles:
+    fix: auto
+`,
+			want: `unknown rule "styles" for "fix" at line:3,col:3`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -103,6 +216,32 @@ paths:
 	}
 }
 
+func TestConfigParseRulesFix(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+rules:
+  style:
+    trailing-spaces: true
+    fix: auto
+  if-cond:
+    fix: auto
+  expression:
+    unused-matrix-values: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Rules.Style.TrailingSpaces || !c.Rules.Expression.UnusedMatrixValues {
+		t.Fatalf("rule configs were not parsed: %#v", c.Rules)
+	}
+	want := map[string]string{"style": "auto", "if-cond": "auto"}
+	if diff := cmp.Diff(want, c.Rules.Fix); diff != "" {
+		t.Fatal(diff)
+	}
+	if !c.Rules.AutoFix("style") || c.Rules.AutoFix("expression") {
+		t.Fatal("AutoFix returned wrong value", c.Rules.Fix)
+	}
+}
+
 func TestConfigPathConfigIgnores(t *testing.T) {
 	tests := []struct {
 		input string
@@ -272,6 +411,12 @@ func TestConfigGenerateDefaultConfigFileOK(t *testing.T) {
 	if c.ConfigVariables != nil {
 		t.Fatal(c.SelfHostedRunner.Labels)
 	}
+	if c.Secrets != nil {
+		t.Fatal(c.Secrets)
+	}
+	if c.RepositoryDispatch.Types != nil || c.RepositoryDispatch.ClientPayload != nil {
+		t.Fatal(c.RepositoryDispatch)
+	}
 	if len(c.Paths) != 0 {
 		t.Fatal(c.Paths)
 	}
@@ -288,3 +433,92 @@ func TestConfigGenerateDefaultConfigFileError(t *testing.T) {
 		t.Fatalf("unexpected error message: %q", msg)
 	}
 }
+
+func TestConfigIgnorePatternRuleAndCode(t *testing.T) {
+	err := &Error{Message: "step should have \"name:\"", Kind: "step-name"}
+	tests := []struct {
+		pat  string
+		want bool
+	}{
+		{"rule:step-name", true},
+		{"rule: step-name", true},
+		{"rule:shellcheck", false},
+		{"code:AL1021", true},
+		{"code:al1021", true},
+		{"code:AL1017", false},
+		{"AL1021", false},
+		{"should have", true},
+		{"rule:.*", false}, // Not a regular expression
+	}
+	for _, tc := range tests {
+		t.Run(tc.pat, func(t *testing.T) {
+			p, perr := ParseIgnorePattern(tc.pat)
+			if tc.pat == "rule:.*" {
+				if perr == nil || !strings.Contains(perr.Error(), `unknown rule name ".*"`) {
+					t.Fatal("unexpected error:", perr)
+				}
+				return
+			}
+			if perr != nil {
+				t.Fatal(perr)
+			}
+			if have := p.Match(err); have != tc.want {
+				t.Fatalf("wanted %v but got %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestConfigIgnorePatternMessageID(t *testing.T) {
+	err := errorfAt(&Pos{}, "expression", "property %q is not defined in object type %s", "foo", "{bar: string}")
+	tests := []struct {
+		pat  string
+		want bool
+	}{
+		{"id:expression/undefined-property", true},
+		{"id: expression/undefined-property", true},
+		{"id:expression/undefined-variable", false},
+		{"id:job-needs/undefined-job", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pat, func(t *testing.T) {
+			p, perr := ParseIgnorePattern(tc.pat)
+			if perr != nil {
+				t.Fatal(perr)
+			}
+			if have := p.Match(err); have != tc.want {
+				t.Fatalf("wanted %v but got %v", tc.want, have)
+			}
+			if s := p.String(); s != "id:"+strings.TrimSpace(tc.pat[len("id:"):]) {
+				t.Fatalf("unexpected string representation %q", s)
+			}
+		})
+	}
+}
+
+func TestConfigIgnorePatternError(t *testing.T) {
+	tests := []struct {
+		pat  string
+		want string
+	}{
+		{"rule:unknown", `unknown rule name "unknown" in ignore pattern "rule:unknown". available rule names are "action", `},
+		{"rule:", `unknown rule name "" in ignore pattern "rule:"`},
+		{"code:AL9999", `unknown error code "AL9999" in ignore pattern "code:AL9999"`},
+		{"id:expression/unknown", `unknown message ID "expression/unknown" in ignore pattern "id:expression/unknown". available message IDs are "action/deprecated-runner", `},
+		{"(foo", `invalid regular expression for ignore pattern "(foo"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pat, func(t *testing.T) {
+			_, err := ParseIgnorePattern(tc.pat)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("wanted error containing %q but got %v", tc.want, err)
+			}
+		})
+	}
+
+	var c PathConfig
+	err := yaml.Unmarshal([]byte("ignore:\n  - foo\n  - code:AL9999\n"), &c)
+	if err == nil || !strings.Contains(err.Error(), `unknown error code "AL9999"`) || !strings.Contains(err.Error(), "line:3,col:5") {
+		t.Fatal("unexpected error:", err)
+	}
+}
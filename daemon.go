@@ -0,0 +1,322 @@
+package actionlint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxDaemonRequestSize = 10 << 20
+
+// DaemonRequest is a request to lint workflows sent to "/lint" endpoint of Daemon.
+type DaemonRequest struct {
+	// Path is a path to the workflow file to lint. A relative path is resolved from WorkingDir. When
+	// this value is empty, all workflow files in the repository at WorkingDir are linted.
+	Path string `json:"path,omitempty"`
+	// Content is a content of the workflow file. When this value is nil, the file at Path is read.
+	// This is useful for linting unsaved buffers in editors.
+	Content *string `json:"content,omitempty"`
+	// WorkingDir is a working directory of the request. File paths in errors are relative to this
+	// directory. When this value is empty, the working directory of the daemon is used.
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Remote is a repository in "owner/repo" form to validate repository-specific references with
+	// GitHub API. When this value is empty, the repository given to the daemon is used.
+	Remote string `json:"remote,omitempty"`
+}
+
+// DaemonResponse is a response of "/lint" endpoint of Daemon.
+type DaemonResponse struct {
+	// Errors is a list of errors found in the workflows.
+	Errors []*ErrorTemplateFields `json:"errors"`
+}
+
+// DaemonOptions is a set of options for Daemon.
+type DaemonOptions struct {
+	// Linter is options of linters to check workflows. The config file at ConfigFile is read only once
+	// when the daemon is created or reloaded.
+	Linter LinterOptions
+	// Token is a token to access GitHub API when some repository is given with Remote field.
+	Token string
+	// LogWriter is a writer to output logs of the daemon. When this value is nil, logs are not output.
+	LogWriter io.Writer
+}
+
+// Daemon is a long-running HTTP server which lints workflows on requests. Unlike running actionlint
+// command for each file, it keeps parsed config files of projects, the config file given by
+// options, and responses of GitHub API in memory so that IDEs and bots can lint workflows without
+// the cost of cold start. It provides the following endpoints:
+//
+//   - POST /lint: Lints workflows specified by DaemonRequest in JSON and returns DaemonResponse.
+//   - POST /reload: Drops all cached data. Config files are read again on the next request.
+//   - GET /healthz: Returns 200 for health checks.
+type Daemon struct {
+	opts     DaemonOptions
+	log      io.Writer
+	mu       sync.Mutex
+	config   *Config
+	projects *Projects
+	client   *GitHubClient
+	remotes  map[string]*RemoteRepository
+}
+
+// NewDaemon creates a new Daemon instance.
+func NewDaemon(opts *DaemonOptions) (*Daemon, error) {
+	d := &Daemon{opts: *opts, log: opts.LogWriter}
+	if d.log == nil {
+		d.log = io.Discard
+	}
+	d.opts.Linter.Color = ColorOptionKindNever
+	d.opts.Linter.Format = ""
+	d.opts.Linter.GroupBy = ReportGroupByNone
+	d.opts.Linter.LogWriter = nil
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload drops all cached data and reads the config file given by the options again.
+func (d *Daemon) Reload() error {
+	var cfg *Config
+	if d.opts.Linter.ConfigFile != "" {
+		c, err := ReadConfigFile(d.opts.Linter.ConfigFile)
+		if err != nil {
+			return err
+		}
+		cfg = c
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.config = cfg
+	d.projects = NewProjects()
+	d.client = NewGitHubClient(d.opts.Token)
+	d.remotes = map[string]*RemoteRepository{}
+	return nil
+}
+
+func (d *Daemon) logf(format string, args ...interface{}) {
+	fmt.Fprintf(d.log, "[%s] %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// linter creates a Linter instance for the request. The instance shares the cached data of the daemon.
+func (d *Daemon) linter(ctx context.Context, req *DaemonRequest) (*Linter, error) {
+	opts := d.opts.Linter
+	opts.Context = ctx
+	opts.ConfigFile = "" // Already read
+	opts.Remote = ""
+	if req.WorkingDir != "" {
+		opts.WorkingDir = req.WorkingDir
+	}
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	l.defaultConfig = d.config
+	l.projects = d.projects
+
+	remote := req.Remote
+	if remote == "" {
+		remote = d.opts.Linter.Remote
+	}
+	if remote != "" {
+		r, ok := d.remotes[remote]
+		if !ok {
+			r, err = NewRemoteRepository(remote, d.client)
+			if err != nil {
+				return nil, err
+			}
+			d.remotes[remote] = r
+		}
+		l.remote = r
+		// Reusable workflows fetched by other requests must not be linted again. Their sources are
+		// cached by the GitHub client
+		l.remoteWorkflow = NewRemoteReusableWorkflowCache(d.client, l.debugWriter())
+	}
+
+	return l, nil
+}
+
+// Lint lints workflows specified by the request.
+func (d *Daemon) Lint(req *DaemonRequest) (*DaemonResponse, error) {
+	return d.LintContext(context.Background(), req)
+}
+
+// LintContext lints workflows specified by the request with the context. When the context is
+// cancelled, external processes such as shellcheck run for the request are killed and an error is
+// returned.
+func (d *Daemon) LintContext(ctx context.Context, req *DaemonRequest) (*DaemonResponse, error) {
+	l, err := d.linter(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	srcs := map[string][]byte{}
+	var errs []*Error
+	if req.Path == "" {
+		if req.Content != nil {
+			return nil, errors.New("\"path\" must be specified with \"content\"")
+		}
+		errs, err = l.LintRepository(l.cwd)
+	} else {
+		abs := req.Path
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(l.cwd, abs)
+		}
+
+		var src []byte
+		tooLarge := false
+		if req.Content != nil {
+			src = []byte(*req.Content)
+		} else {
+			src, err = l.readFile(abs)
+			tooLarge = errors.Is(err, errFileTooLarge)
+			if err != nil && !tooLarge {
+				return nil, fmt.Errorf("could not read %q: %w", req.Path, err)
+			}
+		}
+
+		p, err := l.projects.At(abs)
+		if err != nil {
+			return nil, err
+		}
+		path := abs
+		if p != nil {
+			if r, err := filepath.Rel(l.cwd, abs); err == nil {
+				path = r
+			}
+		}
+		if tooLarge {
+			errs, err = l.printWorkspaces([]workspace{*l.tooLargeWorkspace(path, p)})
+		} else {
+			srcs[path] = src
+			errs, err = l.Lint(path, src, p)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*ErrorTemplateFields, 0, len(errs))
+	for _, e := range errs {
+		src, ok := srcs[e.Filepath]
+		if !ok {
+			// Snippets of remote reusable workflows are not available
+			if p := e.Filepath; !strings.Contains(p, "@") {
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(l.cwd, p)
+				}
+				src, _ = l.readFile(p)
+			}
+			srcs[e.Filepath] = src
+		}
+		fields = append(fields, l.templateFields(e, src))
+	}
+	return &DaemonResponse{fields}, nil
+}
+
+// ServeHTTP implements http.Handler interface.
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		w.Write([]byte("ok\n"))
+	case "/lint":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+			return
+		}
+		d.handleLint(w, r)
+	case "/reload":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := d.Reload(); err != nil {
+			d.logf("could not reload: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		d.logf("reloaded")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (d *Daemon) handleLint(w http.ResponseWriter, r *http.Request) {
+	var req DaemonRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDaemonRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("could not parse request body as JSON: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	res, err := d.LintContext(r.Context(), &req)
+	if err != nil {
+		d.logf("could not lint %q: %s", req.Path, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d.logf("linted %q in %s: %d errors", req.Path, time.Since(start), len(res.Errors))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		d.logf("could not write response: %s", err)
+	}
+}
+
+// parseDaemonAddress parses the address to listen on. "unix:///path/to/sock" is a UNIX domain socket
+// and "tcp://host:port" or "host:port" is a TCP address.
+func parseDaemonAddress(addr string) (string, string, error) {
+	if strings.HasPrefix(addr, "unix://") {
+		p := strings.TrimPrefix(addr, "unix://")
+		if p == "" {
+			return "", "", fmt.Errorf("file path of socket is empty in address %q", addr)
+		}
+		return "unix", p, nil
+	}
+	a := strings.TrimPrefix(addr, "tcp://")
+	if strings.Contains(a, "://") {
+		return "", "", fmt.Errorf("scheme of address %q is not supported. it must be \"unix://\" or \"tcp://\"", addr)
+	}
+	if _, _, err := net.SplitHostPort(a); err != nil {
+		return "", "", fmt.Errorf("invalid TCP address %q: %w", addr, err)
+	}
+	return "tcp", a, nil
+}
+
+// listenDaemon listens on the address for Daemon. A stale socket file left by a previous daemon is
+// removed before listening.
+func listenDaemon(addr string) (net.Listener, error) {
+	network, a, err := parseDaemonAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if s, err := os.Stat(a); err == nil && s.Mode()&os.ModeSocket != 0 {
+			if c, err := net.Dial("unix", a); err == nil {
+				c.Close()
+				return nil, fmt.Errorf("another daemon is already listening on %q", addr)
+			}
+			os.Remove(a)
+		}
+	}
+	l, err := net.Listen(network, a)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %q: %w", addr, err)
+	}
+	return l, nil
+}
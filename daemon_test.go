@@ -0,0 +1,247 @@
+package actionlint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testDaemonProject(t *testing.T) string {
+	d := t.TempDir()
+	for _, p := range []string{".git", filepath.Join(".github", "workflows")} {
+		if err := os.MkdirAll(filepath.Join(d, p), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testStagedWrite(t, d, ".github/actionlint.yaml", "self-hosted-runner:\n  labels:\n    - my-runner\n")
+	testStagedWrite(t, d, ".github/workflows/ci.yaml", "on: push\njobs:\n  test:\n    runs-on: my-runner\n    steps:\n      - run: echo ${{ unknown }}\n")
+	return d
+}
+
+func testDaemonPost(t *testing.T, d *Daemon, path string, body string) (int, string) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func testDaemonLint(t *testing.T, d *Daemon, req *DaemonRequest) []string {
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := testDaemonPost(t, d, "/lint", string(b))
+	if status != http.StatusOK {
+		t.Fatal(status, body)
+	}
+	var res DaemonResponse
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatal(err, body)
+	}
+	ret := make([]string, 0, len(res.Errors))
+	for _, e := range res.Errors {
+		ret = append(ret, filepath.ToSlash(e.Filepath)+":"+e.Kind+":"+e.Snippet)
+	}
+	return ret
+}
+
+func TestDaemonLint(t *testing.T) {
+	proj := testDaemonProject(t)
+	d, err := NewDaemon(&DaemonOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snippet := "      - run: echo ${{ unknown }}\n                      ^~~~~~~"
+	want := []string{".github/workflows/ci.yaml:expression:" + snippet}
+
+	// Read file from disk
+	have := testDaemonLint(t, d, &DaemonRequest{Path: ".github/workflows/ci.yaml", WorkingDir: proj})
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+	// Lint entire repository
+	have = testDaemonLint(t, d, &DaemonRequest{WorkingDir: proj})
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+	// Lint unsaved content. Config file of the project is used
+	content := "on: push\njobs:\n  test:\n    runs-on: other-runner\n    steps:\n      - run: echo\n"
+	have = testDaemonLint(t, d, &DaemonRequest{Path: filepath.Join(proj, ".github", "workflows", "ci.yaml"), Content: &content, WorkingDir: proj})
+	if len(have) != 1 || !strings.HasPrefix(have[0], ".github/workflows/ci.yaml:runner-label:") {
+		t.Fatal("unexpected errors:", have)
+	}
+
+	if len(d.projects.known) != 1 {
+		t.Fatal("project should be cached:", d.projects.known)
+	}
+
+	// Config file is cached until reloading
+	testStagedWrite(t, proj, ".github/actionlint.yaml", "self-hosted-runner:\n  labels:\n    - other-runner\n")
+	have = testDaemonLint(t, d, &DaemonRequest{Path: ".github/workflows/ci.yaml", Content: &content, WorkingDir: proj})
+	if len(have) != 1 {
+		t.Fatal("cached config should be used:", have)
+	}
+	if status, body := testDaemonPost(t, d, "/reload", ""); status != http.StatusNoContent {
+		t.Fatal(status, body)
+	}
+	if len(d.projects.known) != 0 {
+		t.Fatal("cache was not dropped:", d.projects.known)
+	}
+	have = testDaemonLint(t, d, &DaemonRequest{Path: ".github/workflows/ci.yaml", Content: &content, WorkingDir: proj})
+	if len(have) != 0 {
+		t.Fatal("config should be reloaded:", have)
+	}
+}
+
+func TestDaemonConfigFile(t *testing.T) {
+	proj := testDaemonProject(t)
+	cfg := filepath.Join(t.TempDir(), "actionlint.yaml")
+	if err := os.WriteFile(cfg, []byte("self-hosted-runner:\n  labels: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDaemon(&DaemonOptions{Linter: LinterOptions{ConfigFile: cfg, IgnorePatterns: []string{"rule:expression"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := testDaemonLint(t, d, &DaemonRequest{Path: ".github/workflows/ci.yaml", WorkingDir: proj})
+	if len(have) != 1 || !strings.HasPrefix(have[0], ".github/workflows/ci.yaml:runner-label:") {
+		t.Fatal("unexpected errors:", have)
+	}
+
+	if _, err := NewDaemon(&DaemonOptions{Linter: LinterOptions{ConfigFile: filepath.Join(proj, "oops.yaml")}}); err == nil {
+		t.Fatal("error did not occur for missing config file")
+	}
+}
+
+func TestDaemonLintError(t *testing.T) {
+	proj := testDaemonProject(t)
+	d, err := NewDaemon(&DaemonOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		what string
+		body string
+		want string
+	}{
+		{"broken JSON", `{`, "could not parse request body as JSON"},
+		{"missing file", `{"path":"oops.yaml","working_dir":` + testDaemonJSON(t, proj) + `}`, `could not read "oops.yaml"`},
+		{"content without path", `{"content":"on: push"}`, `"path" must be specified with "content"`},
+		{"invalid remote", `{"path":"x.yaml","content":"on: push","remote":"foo"}`, "foo"},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			status, body := testDaemonPost(t, d, "/lint", tc.body)
+			if status != http.StatusBadRequest || !strings.Contains(body, tc.want) {
+				t.Fatalf("unexpected response %d: %q", status, body)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/lint", nil)
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatal("unexpected status:", rec.Code)
+	}
+}
+
+func testDaemonJSON(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDaemonParseAddress(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		network string
+		addr    string
+	}{
+		{"unix:///tmp/actionlint.sock", "unix", "/tmp/actionlint.sock"},
+		{"tcp://127.0.0.1:7000", "tcp", "127.0.0.1:7000"},
+		{"localhost:7000", "tcp", "localhost:7000"},
+		{":7000", "tcp", ":7000"},
+	} {
+		n, a, err := parseDaemonAddress(tc.input)
+		if err != nil {
+			t.Errorf("%q: %s", tc.input, err)
+			continue
+		}
+		if n != tc.network || a != tc.addr {
+			t.Errorf("%q: wanted %q %q but got %q %q", tc.input, tc.network, tc.addr, n, a)
+		}
+	}
+
+	for _, input := range []string{"unix://", "http://localhost:7000", "localhost"} {
+		if _, _, err := parseDaemonAddress(input); err == nil {
+			t.Errorf("error did not occur for %q", input)
+		}
+	}
+}
+
+func TestDaemonListenUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("UNIX domain socket is not tested on Windows")
+	}
+	proj := testDaemonProject(t)
+	sock := filepath.Join(t.TempDir(), "d.sock")
+
+	// Stale socket file is removed
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := listenDaemon("unix://" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDaemon(&DaemonOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: d}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if _, err := listenDaemon("unix://" + sock); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+	body := testDaemonJSON(t, &DaemonRequest{Path: ".github/workflows/ci.yaml", WorkingDir: proj})
+	res, err := c.Post("http://daemon/lint", "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var dr DaemonResponse
+	if err := json.NewDecoder(res.Body).Decode(&dr); err != nil {
+		t.Fatal(err)
+	}
+	if len(dr.Errors) != 1 || dr.Errors[0].Kind != "expression" {
+		t.Fatal("unexpected response:", dr.Errors)
+	}
+}
@@ -0,0 +1,545 @@
+package actionlint
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDataManifestURL is a URL of the manifest of datasets published for actionlint. It is used
+// by UpdateData when no other URL is specified.
+const DefaultDataManifestURL = "https://raw.githubusercontent.com/rhysd/actionlint/main/data/manifest.json"
+
+// dataManifestSchema is the latest schema version of manifest this version of actionlint can read.
+const dataManifestSchema = 1
+
+const dataManifestFile = "manifest.json"
+
+// EmbeddedDataVersion is a version of the datasets embedded in this version of actionlint. It is the
+// date when the embedded datasets were generated in YYYY-MM-DD format. LoadData ignores stored
+// datasets older than this version.
+const EmbeddedDataVersion = "2026-10-16"
+
+// maxDataSize is the maximum size of manifest or dataset file fetched by UpdateData.
+const maxDataSize = 50 * 1024 * 1024
+
+// isOlderThanEmbeddedData returns true when the version of datasets is older than the embedded
+// datasets. Versions not in YYYY-MM-DD format are not compared.
+func isOlderThanEmbeddedData(v string) bool {
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return false
+	}
+	e, err := time.Parse("2006-01-02", EmbeddedDataVersion)
+	if err != nil {
+		return false
+	}
+	return t.Before(e)
+}
+
+// DataManifestEntry is an entry of dataset listed in a manifest.
+type DataManifestEntry struct {
+	// URL is a URL of the dataset file. A relative URL is resolved from the URL of the manifest.
+	URL string `json:"url"`
+	// SHA256 is a hex-encoded SHA-256 digest of the dataset file. When it is empty, the digest is
+	// not verified.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// DataManifest is a manifest of datasets which are embedded in actionlint binary such as popular
+// actions, GitHub-hosted runner labels, and webhook events. The datasets listed in a manifest can
+// be downloaded with UpdateData to use newer data without updating actionlint itself.
+type DataManifest struct {
+	// Schema is a schema version of the manifest.
+	Schema int `json:"schema"`
+	// Version is a version of the datasets. Usually it is the date when the datasets were generated.
+	Version string `json:"version"`
+	// Datasets is a map from names of datasets to their entries. Unknown names are ignored.
+	Datasets map[string]*DataManifestEntry `json:"datasets"`
+}
+
+func parseDataManifest(b []byte) (*DataManifest, error) {
+	var m DataManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse manifest of datasets: %w", err)
+	}
+	if m.Schema <= 0 || m.Schema > dataManifestSchema {
+		return nil, fmt.Errorf("schema version %d of manifest of datasets is not supported. supported version is %d. update actionlint to use the datasets", m.Schema, dataManifestSchema)
+	}
+	return &m, nil
+}
+
+// dataset is a dataset which can be updated at runtime. parse parses the content of the dataset
+// file and returns a function to replace the embedded data with the parsed one.
+type dataset struct {
+	name  string
+	file  string
+	parse func([]byte) (func(), error)
+}
+
+var datasets = []dataset{
+	{"popular-actions", "popular-actions.jsonl", parsePopularActionsData},
+	{"runner-labels", "runner-labels.json", parseRunnerLabelsData},
+	{"webhooks", "webhooks.json", parseWebhooksData},
+	{"runner-tools", "runner-tools.json", parseRunnerToolsData},
+	{"limits", "limits.json", parseLimitsData},
+	{"workflow-schema", "github-workflow.json", parseWorkflowSchemaData},
+}
+
+// parsePopularActionsData parses JSONL data generated by "generate-popular-actions -f jsonl".
+func parsePopularActionsData(b []byte) (func(), error) {
+	actions := map[string]*ActionMetadata{}
+	outdated := map[string]struct{}{}
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		l := bytes.TrimSpace(s.Bytes())
+		if len(l) == 0 {
+			continue
+		}
+		var j struct {
+			Spec     string          `json:"spec"`
+			Meta     *ActionMetadata `json:"metadata"`
+			Outdated bool            `json:"outdated"`
+		}
+		if err := json.Unmarshal(l, &j); err != nil {
+			return nil, fmt.Errorf("could not parse popular actions data: %w", err)
+		}
+		if j.Spec == "" {
+			return nil, errors.New("spec of action is empty in popular actions data")
+		}
+		if j.Outdated {
+			outdated[j.Spec] = struct{}{}
+			continue
+		}
+		if j.Meta == nil {
+			return nil, fmt.Errorf("metadata of action %q is missing in popular actions data", j.Spec)
+		}
+		actions[j.Spec] = j.Meta
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("could not read popular actions data: %w", err)
+	}
+
+	return func() {
+		PopularActions = actions
+		OutdatedPopularActionSpecs = outdated
+	}, nil
+}
+
+// parseRunnerLabelsData parses JSON object which maps GitHub-hosted runner labels to labels
+// embedded in actionlint. The compatibility of the label is inherited from the embedded one. For
+// example, {"ubuntu-latest": "ubuntu-24.04"} means "ubuntu-latest" runs on Ubuntu 24.04. Generic
+// labels "linux", "macos", and "windows" are available for new labels not known yet. Values can be
+// objects to describe lifecycles of the labels like
+// {"compat": "ubuntu-20.04", "deprecated": "2025-02-01", "retired": "2025-04-15", "replacement": "ubuntu-latest"}.
+func parseRunnerLabelsData(b []byte) (func(), error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse runner labels data: %w", err)
+	}
+
+	labels := make([]string, 0, len(m))
+	compats := make(map[string]runnerOSCompat, len(m)+len(selfHostedRunnerPresetOSLabels))
+	for _, l := range selfHostedRunnerPresetOSLabels {
+		compats[l] = defaultRunnerOSCompats[l]
+	}
+	lifecycles := make(map[string]runnerLabelLifecycle, len(runnerLabelLifecycles))
+	for l, lc := range runnerLabelLifecycles {
+		lifecycles[l] = lc
+	}
+
+	for l, v := range m {
+		var e struct {
+			Compat      string `json:"compat"`
+			Deprecated  string `json:"deprecated"`
+			Retired     string `json:"retired"`
+			Replacement string `json:"replacement"`
+		}
+		if err := json.Unmarshal(v, &e.Compat); err != nil {
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil, fmt.Errorf("could not parse runner label %q in runner labels data: %w", l, err)
+			}
+		}
+
+		c, ok := defaultRunnerOSCompats[strings.ToLower(e.Compat)]
+		if !ok {
+			return nil, fmt.Errorf("runner label %q is based on unknown label %q in runner labels data", l, e.Compat)
+		}
+		l = strings.ToLower(l)
+		labels = append(labels, l)
+		compats[l] = c
+
+		if e.Retired != "" {
+			for _, d := range []string{e.Deprecated, e.Retired} {
+				if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+					return nil, fmt.Errorf("date %q of runner label %q must be in YYYY-MM-DD format in runner labels data", d, l)
+				}
+			}
+			lifecycles[l] = runnerLabelLifecycle{e.Deprecated, e.Retired, e.Replacement}
+		}
+	}
+	sort.Strings(labels)
+
+	return func() {
+		allGitHubHostedRunnerLabels = labels
+		defaultRunnerOSCompats = compats
+		runnerLabelLifecycles = lifecycles
+	}, nil
+}
+
+// parseWebhooksData parses JSON object which maps webhook event names to their activity types.
+func parseWebhooksData(b []byte) (func(), error) {
+	var m map[string][]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse webhooks data: %w", err)
+	}
+	if len(m) == 0 {
+		return nil, errors.New("no webhook event is defined in webhooks data")
+	}
+	for k, v := range m {
+		if v == nil {
+			m[k] = []string{}
+		}
+	}
+	return func() {
+		AllWebhookTypes = m
+	}, nil
+}
+
+// parseRunnerToolsData parses JSON object which maps commands to OS families ("linux", "macos",
+// "windows") of GitHub-hosted runner images where the commands are installed.
+func parseRunnerToolsData(b []byte) (func(), error) {
+	var m map[string][]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse runner tools data: %w", err)
+	}
+	for c, oses := range m {
+		for _, os := range oses {
+			if os != "linux" && os != "macos" && os != "windows" {
+				return nil, fmt.Errorf("unknown OS %q for command %q in runner tools data. it must be one of \"linux\", \"macos\", or \"windows\"", os, c)
+			}
+		}
+	}
+	return func() {
+		runnerImageTools = m
+	}, nil
+}
+
+// DefaultDataDir returns the directory path where datasets updated by UpdateData are stored. When
+// $ACTIONLINT_DATA_DIR environment variable is set, its value is returned. Otherwise "actionlint"
+// directory in the user cache directory is returned.
+func DefaultDataDir() (string, error) {
+	if d := os.Getenv("ACTIONLINT_DATA_DIR"); d != "" {
+		return d, nil
+	}
+	d, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find directory to store datasets. set $ACTIONLINT_DATA_DIR environment variable: %w", err)
+	}
+	return filepath.Join(d, "actionlint"), nil
+}
+
+// LoadData loads datasets stored in the directory by UpdateData and replaces the embedded datasets
+// with them. When no dataset is stored in the directory or the stored datasets are older than
+// EmbeddedDataVersion, this function does nothing and returns nil. This function is not safe to be
+// called while linting workflows.
+func LoadData(dir string) (*DataManifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, dataManifestFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read manifest of datasets: %w", err)
+	}
+	m, err := parseDataManifest(b)
+	if err != nil {
+		return nil, err
+	}
+	if isOlderThanEmbeddedData(m.Version) {
+		return nil, nil // The embedded datasets are newer than the stored ones
+	}
+
+	// Parse all datasets before applying them not to leave datasets partially updated
+	applies := make([]func(), 0, len(datasets))
+	for _, d := range datasets {
+		if _, ok := m.Datasets[d.name]; !ok {
+			continue
+		}
+		p := filepath.Join(dir, d.file)
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not read dataset %q: %w", d.name, err)
+		}
+		f, err := d.parse(b)
+		if err != nil {
+			return nil, fmt.Errorf("dataset at %q is broken. run `actionlint -update-data` again or remove the directory %q: %w", p, dir, err)
+		}
+		applies = append(applies, f)
+	}
+
+	for _, f := range applies {
+		f()
+	}
+	return m, nil
+}
+
+func fetchData(c *http.Client, u string) ([]byte, error) {
+	res, err := c.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", u, err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxDataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("could not read response from %s: %w", u, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with status %d", u, res.StatusCode)
+	}
+	if len(b) > maxDataSize {
+		return nil, fmt.Errorf("response from %s is too large. it must be smaller than %d bytes", u, maxDataSize)
+	}
+	return b, nil
+}
+
+// UpdateData downloads the manifest at the URL and the datasets listed in it, then stores them in
+// the directory. The stored datasets are loaded by LoadData. Each dataset is verified before being
+// stored so that broken datasets are never stored. When the client is nil, a default HTTP client
+// is used.
+func UpdateData(manifestURL, dir string, c *http.Client) (*DataManifest, error) {
+	if c == nil {
+		c = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL of manifest of datasets %q: %w", manifestURL, err)
+	}
+
+	mb, err := fetchData(c, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseDataManifest(mb)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, d := range datasets {
+		e, ok := m.Datasets[d.name]
+		if !ok {
+			continue
+		}
+		u, err := base.Parse(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL of dataset %q: %w", d.name, err)
+		}
+		b, err := fetchData(c, u.String())
+		if err != nil {
+			return nil, err
+		}
+		if e.SHA256 != "" {
+			h := sha256.Sum256(b)
+			if s := hex.EncodeToString(h[:]); !strings.EqualFold(s, e.SHA256) {
+				return nil, fmt.Errorf("SHA-256 digest of dataset %q does not match. wanted %s but got %s", d.name, e.SHA256, s)
+			}
+		}
+		if _, err := d.parse(b); err != nil {
+			return nil, err
+		}
+		files[d.file] = b
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create directory to store datasets: %w", err)
+	}
+	for name, b := range files {
+		if err := writeFileAtomic(filepath.Join(dir, name), b); err != nil {
+			return nil, err
+		}
+	}
+	// Write the manifest at last since LoadData reads datasets listed in the manifest
+	if err := writeFileAtomic(filepath.Join(dir, dataManifestFile), mb); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file to write %q: %w", path, err)
+	}
+	tmp := f.Name()
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("could not write %q: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("could not write %q: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("could not write %q: %w", path, err)
+	}
+	return nil
+}
+
+// parseLimitsData parses JSON object of limits of GitHub Actions like {"matrix-jobs": 256}. Omitted
+// limits keep the embedded values.
+func parseLimitsData(b []byte) (func(), error) {
+	l := githubActionsLimits
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&l); err != nil {
+		return nil, fmt.Errorf("could not parse limits data: %w", err)
+	}
+	for n, v := range map[string]int{
+		"matrix-jobs":         l.MatrixJobs,
+		"job-name-length":     l.JobNameLength,
+		"step-name-length":    l.StepNameLength,
+		"env-var-bytes":       l.EnvVarBytes,
+		"env-bytes":           l.EnvBytes,
+		"workflow-file-bytes": l.WorkflowFileBytes,
+		"expression-length":   l.ExpressionLength,
+	} {
+		if v <= 0 {
+			return nil, fmt.Errorf("limit %q must be positive but got %d in limits data", n, v)
+		}
+	}
+	return func() {
+		githubActionsLimits = l
+	}, nil
+}
+
+// EmbeddedDatasets returns the datasets embedded in actionlint as a map from file names of the
+// datasets to their contents. The contents are in the same formats as the datasets downloaded by
+// UpdateData. The workflow schema is not included since only the keys derived from it are embedded.
+func EmbeddedDatasets() (map[string][]byte, error) {
+	sets := make(map[string][]byte, len(datasets))
+
+	specs := make([]string, 0, len(PopularActions))
+	for s := range PopularActions {
+		specs = append(specs, s)
+	}
+	sort.Strings(specs)
+	outdated := make([]string, 0, len(OutdatedPopularActionSpecs))
+	for s := range OutdatedPopularActionSpecs {
+		outdated = append(outdated, s)
+	}
+	sort.Strings(outdated)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range specs {
+		if err := enc.Encode(map[string]interface{}{"spec": s, "metadata": PopularActions[s]}); err != nil {
+			return nil, fmt.Errorf("could not encode popular action %q: %w", s, err)
+		}
+	}
+	for _, s := range outdated {
+		if err := enc.Encode(map[string]interface{}{"spec": s, "outdated": true}); err != nil {
+			return nil, fmt.Errorf("could not encode outdated popular action %q: %w", s, err)
+		}
+	}
+	sets["popular-actions.jsonl"] = buf.Bytes()
+
+	labels := make(map[string]interface{}, len(allGitHubHostedRunnerLabels))
+	for _, l := range allGitHubHostedRunnerLabels {
+		if lc, ok := runnerLabelLifecycles[l]; ok {
+			labels[l] = map[string]string{
+				"compat":      l,
+				"deprecated":  lc.deprecated,
+				"retired":     lc.retired,
+				"replacement": lc.replacement,
+			}
+		} else {
+			labels[l] = l
+		}
+	}
+
+	for _, e := range []struct {
+		file string
+		v    interface{}
+	}{
+		{"runner-labels.json", labels},
+		{"webhooks.json", AllWebhookTypes},
+		{"runner-tools.json", runnerImageTools},
+		{"limits.json", githubActionsLimits},
+	} {
+		b, err := json.MarshalIndent(e.v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("could not encode dataset %q: %w", e.file, err)
+		}
+		sets[e.file] = append(b, '\n')
+	}
+
+	return sets, nil
+}
+
+// WriteDatasets writes the datasets and the manifest which lists them to the directory. The
+// datasets are a map from file names to their contents as returned from EmbeddedDatasets. Each
+// dataset is verified before being written. URLs in the manifest are relative to the manifest. The
+// written manifest is returned.
+func WriteDatasets(dir, version string, sets map[string][]byte) (*DataManifest, error) {
+	m := &DataManifest{
+		Schema:   dataManifestSchema,
+		Version:  version,
+		Datasets: make(map[string]*DataManifestEntry, len(sets)),
+	}
+	files := make(map[string]struct{}, len(sets))
+	for _, d := range datasets {
+		b, ok := sets[d.file]
+		if !ok {
+			continue
+		}
+		if _, err := d.parse(b); err != nil {
+			return nil, err
+		}
+		h := sha256.Sum256(b)
+		m.Datasets[d.name] = &DataManifestEntry{URL: d.file, SHA256: hex.EncodeToString(h[:])}
+		files[d.file] = struct{}{}
+	}
+	for f := range sets {
+		if _, ok := files[f]; !ok {
+			return nil, fmt.Errorf("unknown dataset file %q", f)
+		}
+	}
+
+	mb, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode manifest of datasets: %w", err)
+	}
+	mb = append(mb, '\n')
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create directory to write datasets: %w", err)
+	}
+	for f := range files {
+		if err := writeFileAtomic(filepath.Join(dir, f), sets[f]); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeFileAtomic(filepath.Join(dir, dataManifestFile), mb); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,374 @@
+package actionlint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testDataServer(t *testing.T, files map[string]string) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(b))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func restoreEmbeddedData(t *testing.T) {
+	actions, outdated := PopularActions, OutdatedPopularActionSpecs
+	labels, compats, lifecycles := allGitHubHostedRunnerLabels, defaultRunnerOSCompats, runnerLabelLifecycles
+	webhooks := AllWebhookTypes
+	tools := runnerImageTools
+	limits := githubActionsLimits
+	keys := AllWorkflowKeys
+	t.Cleanup(func() {
+		AllWorkflowKeys = keys
+		runnerImageTools = tools
+		githubActionsLimits = limits
+		PopularActions, OutdatedPopularActionSpecs = actions, outdated
+		allGitHubHostedRunnerLabels, defaultRunnerOSCompats, runnerLabelLifecycles = labels, compats, lifecycles
+		AllWebhookTypes = webhooks
+	})
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestDataUpdateAndLoad(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	actions := `{"spec":"foo/bar@v1","metadata":{"name":"Foo","inputs":{"x":{"name":"x","required":true}},"outputs":{}}}
+{"spec":"foo/bar@v0","outdated":true}
+`
+	labels := `{"ubuntu-26.04": "linux", "ubuntu-latest": "ubuntu-24.04", "ubuntu-22.04": {"compat": "ubuntu-22.04", "deprecated": "2027-01-01", "retired": "2027-03-01", "replacement": "ubuntu-latest"}}`
+	webhooks := `{"push": null, "issues": ["opened", "closed"]}`
+	limits := `{"matrix-jobs": 512}`
+	manifest := `{
+		"schema": 1,
+		"version": "2026-11-01",
+		"datasets": {
+			"popular-actions": {"url": "popular-actions.jsonl", "sha256": "` + sha256Hex(actions) + `"},
+			"runner-labels": {"url": "/data/runner-labels.json"},
+			"webhooks": {"url": "webhooks.json", "sha256": "` + sha256Hex(webhooks) + `"},
+			"limits": {"url": "limits.json"},
+			"unknown-dataset-in-future": {"url": "unknown.json"}
+		}
+	}`
+	s := testDataServer(t, map[string]string{
+		"/data/manifest.json":         manifest,
+		"/data/popular-actions.jsonl": actions,
+		"/data/runner-labels.json":    labels,
+		"/data/webhooks.json":         webhooks,
+		"/data/limits.json":           limits,
+	})
+
+	dir := filepath.Join(t.TempDir(), "data")
+	m, err := UpdateData(s.URL+"/data/manifest.json", dir, s.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "2026-11-01" {
+		t.Fatalf("unexpected version %q", m.Version)
+	}
+
+	// Embedded datasets are not changed until the datasets are loaded
+	if _, ok := PopularActions["foo/bar@v1"]; ok {
+		t.Fatal("datasets were applied before being loaded")
+	}
+
+	m, err = LoadData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || m.Version != "2026-11-01" {
+		t.Fatalf("unexpected manifest %v", m)
+	}
+
+	a, ok := PopularActions["foo/bar@v1"]
+	if !ok {
+		t.Fatalf("action was not loaded: %v", PopularActions)
+	}
+	if a.Name != "Foo" || !a.Inputs["x"].Required {
+		t.Errorf("unexpected action metadata %#v", a)
+	}
+	if _, ok := PopularActions["actions/checkout@v4"]; ok {
+		t.Error("embedded popular actions should be replaced")
+	}
+	if _, ok := OutdatedPopularActionSpecs["foo/bar@v0"]; !ok {
+		t.Errorf("outdated action was not loaded: %v", OutdatedPopularActionSpecs)
+	}
+
+	if strings.Join(allGitHubHostedRunnerLabels, ",") != "ubuntu-22.04,ubuntu-26.04,ubuntu-latest" {
+		t.Errorf("unexpected runner labels %v", allGitHubHostedRunnerLabels)
+	}
+	if defaultRunnerOSCompats["ubuntu-latest"] != compatUbuntu2404 {
+		t.Errorf("compatibility of ubuntu-latest was not inherited: %v", defaultRunnerOSCompats["ubuntu-latest"])
+	}
+	if defaultRunnerOSCompats["ubuntu-26.04"] != defaultRunnerOSCompats["linux"] {
+		t.Errorf("compatibility of ubuntu-26.04 was not inherited: %v", defaultRunnerOSCompats["ubuntu-26.04"])
+	}
+	if _, ok := defaultRunnerOSCompats["ubuntu-20.04"]; ok {
+		t.Error("label not in the dataset should be removed")
+	}
+	if lc := runnerLabelLifecycles["ubuntu-22.04"]; lc.retired != "2027-03-01" || lc.replacement != "ubuntu-latest" {
+		t.Errorf("lifecycle of ubuntu-22.04 was not loaded: %#v", lc)
+	}
+	if _, ok := runnerLabelLifecycles["ubuntu-20.04"]; !ok {
+		t.Error("embedded lifecycle should be kept")
+	}
+
+	if ts, ok := AllWebhookTypes["push"]; !ok || ts == nil || len(ts) != 0 {
+		t.Errorf("unexpected types for push event %#v", ts)
+	}
+	if strings.Join(AllWebhookTypes["issues"], ",") != "opened,closed" {
+		t.Errorf("unexpected types for issues event %v", AllWebhookTypes["issues"])
+	}
+
+	if githubActionsLimits.MatrixJobs != 512 {
+		t.Errorf("limit of matrix jobs was not loaded: %d", githubActionsLimits.MatrixJobs)
+	}
+	if githubActionsLimits.JobNameLength != 255 {
+		t.Errorf("omitted limit should keep the embedded value: %d", githubActionsLimits.JobNameLength)
+	}
+}
+
+func TestDataLoadNotUpdated(t *testing.T) {
+	m, err := LoadData(filepath.Join(t.TempDir(), "this-dir-does-not-exist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != nil {
+		t.Fatalf("manifest should be nil when datasets were never downloaded: %v", m)
+	}
+}
+
+func TestDataLoadOlderThanEmbedded(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"manifest.json": `{"schema":1,"version":"2024-01-01","datasets":{"webhooks":{"url":"webhooks.json"}}}`,
+		"webhooks.json": `{"push": []}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := LoadData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != nil {
+		t.Fatalf("manifest should be nil when stored datasets are older than embedded ones: %v", m)
+	}
+	if _, ok := AllWebhookTypes["issues"]; !ok {
+		t.Fatal("embedded datasets should not be replaced with older ones")
+	}
+}
+
+func TestDataLoadBrokenDataset(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"manifest.json":      `{"schema":1,"version":"v1","datasets":{"webhooks":{"url":"webhooks.json"},"runner-labels":{"url":"runner-labels.json"}}}`,
+		"webhooks.json":      `{"push": []}`,
+		"runner-labels.json": `{"foo": "unknown-label"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := LoadData(dir)
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `based on unknown label "unknown-label"`) {
+		t.Fatalf("unexpected error %q", msg)
+	}
+	if _, ok := AllWebhookTypes["issues"]; !ok {
+		t.Fatal("datasets should not be partially applied on error")
+	}
+}
+
+func TestDataUpdateError(t *testing.T) {
+	testCases := []struct {
+		what     string
+		manifest string
+		files    map[string]string
+		want     string
+	}{
+		{
+			what:     "unsupported schema",
+			manifest: `{"schema":2,"version":"v2","datasets":{}}`,
+			want:     "schema version 2 of manifest of datasets is not supported",
+		},
+		{
+			what:     "broken manifest",
+			manifest: `{"schema":`,
+			want:     "could not parse manifest of datasets",
+		},
+		{
+			what:     "digest mismatch",
+			manifest: `{"schema":1,"version":"v1","datasets":{"webhooks":{"url":"webhooks.json","sha256":"0123"}}}`,
+			files:    map[string]string{"/webhooks.json": `{"push":[]}`},
+			want:     `SHA-256 digest of dataset "webhooks" does not match`,
+		},
+		{
+			what:     "dataset not found",
+			manifest: `{"schema":1,"version":"v1","datasets":{"webhooks":{"url":"webhooks.json"}}}`,
+			want:     "failed with status 404",
+		},
+		{
+			what:     "broken dataset",
+			manifest: `{"schema":1,"version":"v1","datasets":{"popular-actions":{"url":"popular-actions.jsonl"}}}`,
+			files:    map[string]string{"/popular-actions.jsonl": `{"spec":"foo/bar@v1"}`},
+			want:     `metadata of action "foo/bar@v1" is missing`,
+		},
+		{
+			what:     "invalid limit",
+			manifest: `{"schema":1,"version":"v1","datasets":{"limits":{"url":"limits.json"}}}`,
+			files:    map[string]string{"/limits.json": `{"matrix-jobs":0}`},
+			want:     `limit "matrix-jobs" must be positive but got 0`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			files := map[string]string{"/manifest.json": tc.manifest}
+			for p, c := range tc.files {
+				files[p] = c
+			}
+			s := testDataServer(t, files)
+
+			dir := filepath.Join(t.TempDir(), "data")
+			_, err := UpdateData(s.URL+"/manifest.json", dir, s.Client())
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if msg := err.Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+			if _, err := os.Stat(dir); err == nil {
+				t.Fatal("nothing should be stored on error")
+			}
+		})
+	}
+}
+
+func TestDataUpdateTooLarge(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(w, strings.NewReader(strings.Repeat(" ", maxDataSize+1)), maxDataSize+1)
+	}))
+	t.Cleanup(s.Close)
+
+	dir := filepath.Join(t.TempDir(), "data")
+	_, err := UpdateData(s.URL+"/manifest.json", dir, s.Client())
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "is too large") {
+		t.Fatalf("unexpected error %q", msg)
+	}
+}
+
+func TestDataEmbeddedDatasetsRoundTrip(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	actions, outdated := PopularActions, OutdatedPopularActionSpecs
+	labels := append([]string{}, allGitHubHostedRunnerLabels...)
+	sort.Strings(labels)
+	compats, lifecycles := defaultRunnerOSCompats, runnerLabelLifecycles
+	webhooks := AllWebhookTypes
+	tools := runnerImageTools
+	limits := githubActionsLimits
+
+	sets, err := EmbeddedDatasets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := WriteDatasets(dir, "2099-01-01", sets); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadData(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		what      string
+		want, got interface{}
+	}{
+		{"popular actions", actions, PopularActions},
+		{"outdated popular actions", outdated, OutdatedPopularActionSpecs},
+		{"runner labels", labels, allGitHubHostedRunnerLabels},
+		{"runner OS compatibilities", compats, defaultRunnerOSCompats},
+		{"runner label lifecycles", lifecycles, runnerLabelLifecycles},
+		{"webhooks", webhooks, AllWebhookTypes},
+		{"runner tools", tools, runnerImageTools},
+		{"limits", limits, githubActionsLimits},
+	} {
+		if !reflect.DeepEqual(c.want, c.got) {
+			t.Errorf("%s are not the same after round trip", c.what)
+		}
+	}
+}
+
+func TestDataManifestMatchesEmbeddedDatasets(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("data", dataManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := parseDataManifest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != EmbeddedDataVersion {
+		t.Errorf("version of data/manifest.json %q is not the same as embedded version %q. run `go run ./scripts/generate-data-manifest`", m.Version, EmbeddedDataVersion)
+	}
+
+	sets, err := EmbeddedDatasets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range datasets {
+		b, ok := sets[d.file]
+		if !ok {
+			continue
+		}
+		e, ok := m.Datasets[d.name]
+		if !ok {
+			t.Errorf("dataset %q is not listed in data/manifest.json", d.name)
+			continue
+		}
+		h := sha256.Sum256(b)
+		if s := hex.EncodeToString(h[:]); s != e.SHA256 {
+			t.Errorf("dataset %q in data/manifest.json is outdated. run `go run ./scripts/generate-data-manifest`", d.name)
+		}
+		f, err := os.ReadFile(filepath.Join("data", e.URL))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if h := sha256.Sum256(f); hex.EncodeToString(h[:]) != e.SHA256 {
+			t.Errorf("SHA-256 digest of %q does not match data/manifest.json", e.URL)
+		}
+	}
+}
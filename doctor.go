@@ -0,0 +1,365 @@
+package actionlint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DoctorStatus is a status of a check by Diagnose.
+type DoctorStatus string
+
+const (
+	// DoctorStatusOK is the status when the check found no problem.
+	DoctorStatusOK DoctorStatus = "ok"
+	// DoctorStatusWarning is the status when actionlint works but some feature is not available.
+	DoctorStatusWarning DoctorStatus = "warning"
+	// DoctorStatusError is the status when actionlint does not work as expected.
+	DoctorStatusError DoctorStatus = "error"
+)
+
+// DoctorCheck is a result of checking one item of the environment by Diagnose.
+type DoctorCheck struct {
+	// Name is a name of the checked item like "shellcheck".
+	Name string `json:"name"`
+	// Status is a status of the check.
+	Status DoctorStatus `json:"status"`
+	// Detail is a human-readable description of the result.
+	Detail string `json:"detail"`
+}
+
+// DoctorOptions is options for Diagnose. They should be the same as the options used for linting
+// to diagnose the environment where actionlint actually runs.
+type DoctorOptions struct {
+	// Shellcheck is a command name or file path of shellcheck. Empty string means shellcheck
+	// integration is disabled.
+	Shellcheck string
+	// Pyflakes is a command name or file path of pyflakes. Empty string means pyflakes integration
+	// is disabled.
+	Pyflakes string
+	// ConfigFile is a path to the config file. When it is empty, the config file is searched from
+	// the project.
+	ConfigFile string
+	// Dir is a directory where actionlint runs. The project is searched from it. When it is empty,
+	// the current directory is used.
+	Dir string
+}
+
+// DoctorReport is a report of environment readiness created by Diagnose. It is useful for
+// attaching to bug reports.
+type DoctorReport struct {
+	// Checks is a list of the results of checks.
+	Checks []*DoctorCheck `json:"checks"`
+}
+
+// HasError returns true when some check failed with DoctorStatusError.
+func (r *DoctorReport) HasError() bool {
+	for _, c := range r.Checks {
+		if c.Status == DoctorStatusError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DoctorReport) add(name string, status DoctorStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, &DoctorCheck{name, status, fmt.Sprintf(format, args...)})
+}
+
+// Print prints the report to the writer in the format. The format is one of "text" or "json".
+func (r *DoctorReport) Print(w io.Writer, format string) error {
+	switch format {
+	case "text", "":
+		t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, c := range r.Checks {
+			fmt.Fprintf(t, "%s\t%s\t%s\n", c.Name, c.Status, c.Detail)
+		}
+		return t.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("could not encode doctor report to JSON: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q of doctor report. it must be one of \"text\" or \"json\"", format)
+	}
+}
+
+// Diagnose checks the environment where actionlint runs and returns the report. It checks
+// availability of external commands, the config file, the project, the data directory, settings
+// for accessing GitHub API, and datasets. It never accesses network.
+func Diagnose(opts *DoctorOptions) *DoctorReport {
+	r := &DoctorReport{}
+
+	r.add(
+		"actionlint",
+		DoctorStatusOK,
+		"%s %s built with %s for %s/%s",
+		getCommandVersion(),
+		installedFrom,
+		runtime.Version(),
+		runtime.GOOS,
+		runtime.GOARCH,
+	)
+
+	diagnoseExternalCommand(r, "shellcheck", opts.Shellcheck, nil, "shell scripts at \"run:\" are not checked by shellcheck")
+	var fallbacks []string
+	if opts.Pyflakes == pythonLinters[0].name {
+		for _, l := range pythonLinters[1:] {
+			fallbacks = append(fallbacks, l.name)
+		}
+	}
+	diagnoseExternalCommand(r, "pyflakes", opts.Pyflakes, fallbacks, "Python scripts at \"run:\" are not checked")
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	root := diagnoseProject(r, dir)
+	diagnoseConfig(r, opts.ConfigFile, root)
+	m := diagnoseDataDir(r)
+	diagnoseDatasets(r, m)
+	diagnoseRemote(r)
+
+	return r
+}
+
+// commandVersion runs the command with --version and returns the first line of its output.
+func commandVersion(exe string, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	c := exec.CommandContext(ctx, exe, append(args, "--version")...)
+	c.Stdout = &out
+	c.Stderr = &out
+	if err := c.Run(); err != nil {
+		return "", err
+	}
+	s := strings.TrimSpace(out.String())
+	// shellcheck prints "ShellCheck - shell script analysis tool" at the first line
+	for _, l := range strings.Split(s, "\n") {
+		if strings.HasPrefix(l, "version:") {
+			return strings.TrimSpace(strings.TrimPrefix(l, "version:")), nil
+		}
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func diagnoseExternalCommand(r *DoctorReport, name, exe string, fallbacks []string, missing string) {
+	if exe == "" {
+		r.add(name, DoctorStatusOK, "disabled by -%s= flag", name)
+		return
+	}
+	cmd := exe
+	p, args, err := findExe(cmd)
+	if err != nil {
+		for _, f := range fallbacks {
+			if p, args, err = findExe(f); err == nil {
+				cmd = f
+				break
+			}
+		}
+	}
+	if err != nil {
+		r.add(name, DoctorStatusWarning, "%q was not found. %s", exe, missing)
+		return
+	}
+	v, err := commandVersion(p, args)
+	if err != nil {
+		r.add(name, DoctorStatusError, "%q was found at %q but it could not be run: %s", cmd, p, err)
+		return
+	}
+	if cmd != exe {
+		r.add(name, DoctorStatusOK, "%q was not found. %q version %s at %q is used instead", exe, cmd, v, p)
+		return
+	}
+	r.add(name, DoctorStatusOK, "version %s at %q", v, p)
+}
+
+func diagnoseProject(r *DoctorReport, dir string) string {
+	root := findProjectRoot(dir)
+	if root == "" {
+		r.add("project", DoctorStatusWarning, "no project was found from %q. the project is a directory containing both .git and .github/workflows. workflow files must be given via arguments", absPath(dir))
+		return ""
+	}
+	r.add("project", DoctorStatusOK, "root directory is %q", root)
+	return root
+}
+
+func diagnoseConfig(r *DoctorReport, path string, root string) {
+	if path != "" {
+		if _, err := ReadConfigFile(path); err != nil {
+			r.add("config", DoctorStatusError, "could not read config file %q given via -config-file: %s", path, err)
+			return
+		}
+		r.add("config", DoctorStatusOK, "config file %q given via -config-file", path)
+		return
+	}
+	if root == "" {
+		r.add("config", DoctorStatusOK, "no config file was found. the default configuration is used")
+		return
+	}
+	for _, f := range []string{"actionlint.yaml", "actionlint.yml"} {
+		p := filepath.Join(root, ".github", f)
+		_, err := ReadConfigFile(p)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			r.add("config", DoctorStatusError, "could not parse config file %q: %s", p, err)
+		default:
+			r.add("config", DoctorStatusOK, "config file %q was found in the project", p)
+		}
+		return
+	}
+	r.add("config", DoctorStatusOK, "no config file was found in the project. the default configuration is used")
+}
+
+// diagnoseDataDir checks the directory where datasets are stored by -update-data. It returns the
+// manifest of the stored datasets when they are available.
+func diagnoseDataDir(r *DoctorReport) *DataManifest {
+	dir, err := DefaultDataDir()
+	if err != nil {
+		r.add("data-dir", DoctorStatusWarning, "%s. -update-data is not available", err)
+		return nil
+	}
+	s, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			r.add("data-dir", DoctorStatusOK, "%q does not exist. it is created by -update-data", dir)
+			return nil
+		}
+		r.add("data-dir", DoctorStatusError, "could not access %q: %s", dir, err)
+		return nil
+	}
+	if !s.IsDir() {
+		r.add("data-dir", DoctorStatusError, "%q is not a directory", dir)
+		return nil
+	}
+	writable := true
+	if f, err := os.CreateTemp(dir, "doctor-"); err != nil {
+		writable = false
+	} else {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, dataManifestFile))
+	if err != nil {
+		if !writable {
+			r.add("data-dir", DoctorStatusWarning, "%q is not writable. -update-data will fail", dir)
+		} else {
+			r.add("data-dir", DoctorStatusOK, "%q has no downloaded datasets", dir)
+		}
+		return nil
+	}
+	m, err := parseDataManifest(b)
+	if err != nil {
+		r.add("data-dir", DoctorStatusError, "%s. run `actionlint -update-data` again or remove the directory %q", err, dir)
+		return nil
+	}
+	if isOlderThanEmbeddedData(m.Version) {
+		r.add("data-dir", DoctorStatusWarning, "%q has datasets version %s older than embedded datasets version %s. they are ignored. run `actionlint -update-data` or remove the directory", dir, m.Version, EmbeddedDataVersion)
+		return nil
+	}
+	for _, d := range datasets {
+		if _, ok := m.Datasets[d.name]; !ok {
+			continue
+		}
+		p := filepath.Join(dir, d.file)
+		b, err := os.ReadFile(p)
+		if err == nil {
+			_, err = d.parse(b)
+		}
+		if err != nil {
+			r.add("data-dir", DoctorStatusError, "dataset %q at %q is broken. run `actionlint -update-data` again or remove the directory %q: %s", d.name, p, dir, err)
+			return nil
+		}
+	}
+	if !writable {
+		r.add("data-dir", DoctorStatusWarning, "%q is not writable. -update-data will fail", dir)
+	} else {
+		r.add("data-dir", DoctorStatusOK, "%q has datasets version %s", dir, m.Version)
+	}
+	return m
+}
+
+func diagnoseDatasets(r *DoctorReport, m *DataManifest) {
+	embedded := make([]string, 0, len(datasets))
+	downloaded := []string{}
+	for _, d := range datasets {
+		if m != nil {
+			if _, ok := m.Datasets[d.name]; ok {
+				downloaded = append(downloaded, d.name)
+				continue
+			}
+		}
+		embedded = append(embedded, d.name)
+	}
+
+	ls := []string{}
+	if len(downloaded) > 0 {
+		ls = append(ls, fmt.Sprintf("%s downloaded (version %s)", strings.Join(downloaded, ", "), m.Version))
+	}
+	if len(embedded) > 0 {
+		ls = append(ls, fmt.Sprintf("%s embedded in actionlint %s", strings.Join(embedded, ", "), getCommandVersion()))
+	}
+	r.add("datasets", DoctorStatusOK, "%s", strings.Join(ls, ". "))
+}
+
+func diagnoseRemote(r *DoctorReport) {
+	u := os.Getenv("ACTIONLINT_DATA_MANIFEST_URL")
+	if u == "" {
+		u = DefaultDataManifestURL
+	}
+	for _, e := range []struct {
+		name string
+		url  string
+	}{
+		{"proxy-api", githubAPIURL},
+		{"proxy-data", u},
+	} {
+		pu, err := url.Parse(e.url)
+		if err != nil {
+			r.add(e.name, DoctorStatusError, "invalid URL %q: %s", e.url, err)
+			continue
+		}
+		p, err := http.ProxyFromEnvironment(&http.Request{URL: pu})
+		switch {
+		case err != nil:
+			r.add(e.name, DoctorStatusError, "invalid proxy for %q: %s", e.url, err)
+		case p == nil:
+			r.add(e.name, DoctorStatusOK, "%q is accessed without proxy", e.url)
+		default:
+			if p.User != nil {
+				p.User = url.User("xxxxx") // Do not show the credentials in bug reports
+			}
+			r.add(e.name, DoctorStatusOK, "%q is accessed via proxy %q", e.url, p.String())
+		}
+	}
+
+	for _, n := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if os.Getenv(n) != "" {
+			r.add("token", DoctorStatusOK, "token to access GitHub API is set to $%s", n)
+			return
+		}
+	}
+	r.add("token", DoctorStatusWarning, "neither $GITHUB_TOKEN nor $GH_TOKEN is set. -remote flag and -org flag of audit subcommand are not available")
+}
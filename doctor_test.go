@@ -0,0 +1,188 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testDoctorCheck(t *testing.T, r *DoctorReport, name string, status DoctorStatus, detail string) {
+	t.Helper()
+	for _, c := range r.Checks {
+		if c.Name != name {
+			continue
+		}
+		if c.Status != status {
+			t.Errorf("wanted status %q for check %q but got %q: %s", status, name, c.Status, c.Detail)
+		}
+		if !strings.Contains(c.Detail, detail) {
+			t.Errorf("wanted %q in detail of check %q but got %q", detail, name, c.Detail)
+		}
+		return
+	}
+	t.Errorf("check %q was not found in %v", name, r.Checks)
+}
+
+func TestDoctorDiagnose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake shellcheck command is a shell script")
+	}
+
+	root := t.TempDir()
+	testEnsureDotGitDir(root)
+	if err := os.MkdirAll(filepath.Join(root, ".github", "workflows"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".github", "actionlint.yaml"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "sub")
+	if err := os.MkdirAll(sub, 0750); err != nil {
+		t.Fatal(err)
+	}
+	shellcheck := filepath.Join(root, "shellcheck")
+	script := "#!/bin/sh\necho 'ShellCheck - shell script analysis tool'\necho 'version: 0.9.0'\n"
+	if err := os.WriteFile(shellcheck, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	data := filepath.Join(root, "data")
+	if err := os.MkdirAll(data, 0750); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"schema": 1, "version": "2026-11-01", "datasets": {"webhooks": {"url": "webhooks.json"}}}`
+	if err := os.WriteFile(filepath.Join(data, dataManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "webhooks.json"), []byte(`{"push": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ACTIONLINT_DATA_DIR", data)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "secret-value")
+
+	r := Diagnose(&DoctorOptions{
+		Shellcheck: shellcheck,
+		Pyflakes:   "",
+		Dir:        sub,
+	})
+
+	testDoctorCheck(t, r, "shellcheck", DoctorStatusOK, "version 0.9.0")
+	testDoctorCheck(t, r, "pyflakes", DoctorStatusOK, "disabled")
+	testDoctorCheck(t, r, "project", DoctorStatusOK, root)
+	testDoctorCheck(t, r, "config", DoctorStatusError, "could not parse config file")
+	testDoctorCheck(t, r, "data-dir", DoctorStatusOK, "datasets version 2026-11-01")
+	testDoctorCheck(t, r, "datasets", DoctorStatusOK, "webhooks downloaded (version 2026-11-01)")
+	testDoctorCheck(t, r, "token", DoctorStatusOK, "$GH_TOKEN")
+	if !r.HasError() {
+		t.Error("report should have an error due to the broken config file")
+	}
+
+	var b bytes.Buffer
+	if err := r.Print(&b, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "secret-value") {
+		t.Fatal("token value must not be printed:", b.String())
+	}
+	var j DoctorReport
+	if err := json.Unmarshal(b.Bytes(), &j); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Checks) != len(r.Checks) {
+		t.Fatalf("wanted %d checks in JSON but got %d", len(r.Checks), len(j.Checks))
+	}
+}
+
+func TestDoctorDiagnoseMissingCommandsAndBrokenData(t *testing.T) {
+	data := t.TempDir()
+	if err := os.WriteFile(filepath.Join(data, dataManifestFile), []byte(`{"schema": 100}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ACTIONLINT_DATA_DIR", data)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	cfg := filepath.Join("testdata", "config", "ok.yml")
+	r := Diagnose(&DoctorOptions{
+		Shellcheck: "this-command-does-not-exist",
+		Pyflakes:   "this-command-does-not-exist",
+		ConfigFile: cfg,
+		Dir:        t.TempDir(),
+	})
+
+	testDoctorCheck(t, r, "shellcheck", DoctorStatusWarning, "was not found")
+	testDoctorCheck(t, r, "pyflakes", DoctorStatusWarning, "was not found")
+	testDoctorCheck(t, r, "project", DoctorStatusWarning, "no project was found")
+	testDoctorCheck(t, r, "config", DoctorStatusOK, cfg)
+	testDoctorCheck(t, r, "data-dir", DoctorStatusError, "schema version 100")
+	testDoctorCheck(t, r, "datasets", DoctorStatusOK, "popular-actions, runner-labels")
+	testDoctorCheck(t, r, "token", DoctorStatusWarning, "neither $GITHUB_TOKEN nor $GH_TOKEN")
+}
+
+func TestDoctorDiagnoseOutdatedData(t *testing.T) {
+	data := t.TempDir()
+	manifest := `{"schema": 1, "version": "2024-10-01", "datasets": {"webhooks": {"url": "webhooks.json"}}}`
+	if err := os.WriteFile(filepath.Join(data, dataManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ACTIONLINT_DATA_DIR", data)
+
+	r := Diagnose(&DoctorOptions{Dir: t.TempDir()})
+
+	testDoctorCheck(t, r, "data-dir", DoctorStatusWarning, "older than embedded datasets version "+EmbeddedDataVersion)
+	testDoctorCheck(t, r, "datasets", DoctorStatusOK, "webhooks")
+}
+
+func TestDoctorPrintText(t *testing.T) {
+	r := &DoctorReport{}
+	r.add("shellcheck", DoctorStatusOK, "version %s", "0.9.0")
+	r.add("config", DoctorStatusError, "broken")
+	var b bytes.Buffer
+	if err := r.Print(&b, "text"); err != nil {
+		t.Fatal(err)
+	}
+	want := "shellcheck  ok     version 0.9.0\nconfig      error  broken\n"
+	if b.String() != want {
+		t.Fatalf("wanted %q but got %q", want, b.String())
+	}
+	if err := r.Print(&b, "yaml"); err == nil {
+		t.Fatal("error was not returned for unknown format")
+	}
+}
+
+func TestCommandDoctor(t *testing.T) {
+	t.Setenv("ACTIONLINT_DATA_DIR", t.TempDir())
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &stdout, Stderr: &stderr}
+	status := cmd.Main([]string{"actionlint", "doctor", "-shellcheck=", "-pyflakes=", dir})
+	if status != ExitStatusSuccessNoProblem {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessNoProblem, status, stderr.String())
+	}
+	if want := "no project was found"; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	status = cmd.Main([]string{"actionlint", "doctor", "-config-file", filepath.Join("testdata", "config", "broken.yml"), dir})
+	if status != ExitStatusSuccessProblemFound {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessProblemFound, status, stderr.String())
+	}
+
+	for _, args := range [][]string{
+		{"-format", "xml"},
+		{dir, dir},
+	} {
+		stderr.Reset()
+		status := cmd.Main(append([]string{"actionlint", "doctor"}, args...))
+		if status != ExitStatusInvalidCommandOption {
+			t.Fatalf("exit status should be %d with %v but got %d: %s", ExitStatusInvalidCommandOption, args, status, stderr.String())
+		}
+	}
+}
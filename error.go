@@ -3,13 +3,18 @@ package actionlint
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-runewidth"
@@ -34,6 +39,47 @@ type Error struct {
 	Column int
 	// Kind is a string to represent kind of the error. Usually rule name which found the error.
 	Kind string
+	// Suggestions is a list of fixes which can be applied mechanically to resolve the error. This
+	// field is nil when the rule does not know how to fix the error.
+	Suggestions []*Suggestion
+	// Offset is a byte offset where the error occurred. This value is 1-based as Pos.Offset so that
+	// 0 means the offset is unknown.
+	Offset int
+	// MessageID is a stable ID of the message like "expression/undefined-property". It is assigned
+	// when the error is created from the message catalog. Unlike Message, it does not change when the
+	// wording of the message changes. This field is empty when the message is not in the catalog.
+	MessageID string
+	// format and args are the format string and its arguments which created the message. They are
+	// used to localize the message.
+	format string
+	args   []interface{}
+}
+
+// Suggestion is a machine-applicable fix of an error. It replaces the source in the range from Start
+// to End with Replacement. When Start and End are the same position, Replacement is inserted at the
+// position.
+type Suggestion struct {
+	// Message is a description of the fix.
+	Message string
+	// Start is a start position of the range to replace.
+	Start *Pos
+	// End is an end position of the range to replace. The character at this position is not included
+	// in the range.
+	End *Pos
+	// Replacement is a text to replace the range with.
+	Replacement string
+	// NewFile is a file which must be created along with the replacement. This field is nil when the
+	// fix only modifies the source.
+	NewFile *SuggestionFile
+}
+
+// SuggestionFile is a new file created by a fix. For example, a fix to extract a script at "run:"
+// creates a script file.
+type SuggestionFile struct {
+	// Path is a file path relative to the repository root. The path separator is always '/'.
+	Path string
+	// Content is the content of the file.
+	Content string
 }
 
 // Error returns summary of the error as string.
@@ -45,55 +91,275 @@ func (e *Error) String() string {
 	return e.Error()
 }
 
+// Code returns the stable error code of the error like "AL1003". It returns an empty string when
+// the kind of the error is unknown.
+func (e *Error) Code() string {
+	return ErrorCode(e.Kind)
+}
+
+// Category returns the category of the error like "security". It returns an empty string when the
+// kind of the error is unknown.
+func (e *Error) Category() ErrorCategory {
+	return ErrorCategoryOf(e.Kind)
+}
+
 func errorAt(pos *Pos, kind string, msg string) *Error {
 	return &Error{
-		Message: msg,
-		Line:    pos.Line,
-		Column:  pos.Col,
-		Kind:    kind,
+		Message:   msg,
+		Line:      pos.Line,
+		Column:    pos.Col,
+		Kind:      kind,
+		Offset:    pos.Offset,
+		MessageID: messageIDOf(msg),
+		format:    msg,
 	}
 }
 
 func errorfAt(pos *Pos, kind string, format string, args ...interface{}) *Error {
 	return &Error{
-		Message: fmt.Sprintf(format, args...),
-		Line:    pos.Line,
-		Column:  pos.Col,
-		Kind:    kind,
+		Message:   fmt.Sprintf(format, args...),
+		Line:      pos.Line,
+		Column:    pos.Col,
+		Kind:      kind,
+		Offset:    pos.Offset,
+		MessageID: messageIDOf(format),
+		format:    format,
+		args:      args,
+	}
+}
+
+// errorOfExpr creates a new error at the position from the error of expression. The message ID is
+// inherited from the error of expression.
+func errorOfExpr(pos *Pos, kind string, err *ExprError) *Error {
+	return &Error{
+		Message:   err.Message,
+		Line:      pos.Line,
+		Column:    pos.Col,
+		Kind:      kind,
+		Offset:    pos.Offset,
+		MessageID: err.MessageID,
+		format:    err.format,
+		args:      err.args,
 	}
 }
 
+// Fingerprint returns a stable identifier of the error. It is a hash of the slash-separated file
+// path, the rule name, the message, and the content of the line where the error occurred with
+// leading and trailing spaces trimmed. When the same content appears at multiple lines in the file,
+// the number of the same lines before the line is also included so that errors on the identical
+// lines have different fingerprints. Since line and column numbers are not included, the
+// fingerprint does not change when unrelated lines in the file are added or removed. When the
+// source is nil, the line content is not included.
+func (e *Error) Fingerprint(source []byte) string {
+	h := sha256.New()
+	for _, s := range []string{filepath.ToSlash(e.Filepath), e.Kind, e.Message} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	if l, n, ok := e.getLineOccurrence(source); ok {
+		h.Write([]byte(l))
+		if n > 0 {
+			fmt.Fprintf(h, "\x00%d", n)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
 // GetTemplateFields fields for formatting this error with Go template.
 func (e *Error) GetTemplateFields(source []byte) *ErrorTemplateFields {
+	return e.templateFields(source, ColumnUnitRune)
+}
+
+// templateFields returns fields for formatting this error with Go template. The column of this
+// error is counted in the given unit.
+func (e *Error) templateFields(source []byte, unit ColumnUnit) *ErrorTemplateFields {
 	snippet := ""
 	end := e.Column
+	offset := -1
+	if o, ok := (&Pos{Offset: e.Offset}).ByteOffset(); ok {
+		offset = o
+	}
 	if len(source) > 0 && e.Line > 0 {
 		if l, ok := e.getLine(source); ok {
 			snippet = l
-			if len(l) >= e.Column-1 {
-				if i := e.getIndicator(l); i != "" {
+			if offset < 0 {
+				// The error was reported at a position without its offset. Calculate it from the line
+				offset = byteOffsetAt(source, lineOffsets(source), &Pos{Line: e.Line, Col: unit.ToRune(l, e.Column)})
+			}
+			if col := unit.ToRune(l, e.Column); utf8.RuneCountInString(l) >= col-1 {
+				if i, c := getIndicator(l, col); i != "" {
 					snippet += "\n" + i
-					end = len(i) // Byte length can be used here because this line only contains ASCII
+					end = unit.FromRune(l, c)
 				}
 			}
 		}
 	}
 
+	var suggestions []*SuggestionTemplateFields
+	if len(e.Suggestions) > 0 {
+		suggestions = make([]*SuggestionTemplateFields, 0, len(e.Suggestions))
+		offsets := lineOffsets(source)
+		lines := splitLines(string(source))
+		col := func(p *Pos) int {
+			if p.Line <= 0 || p.Line > len(lines) {
+				return p.Col
+			}
+			return unit.FromRune(lines[p.Line-1], p.Col)
+		}
+		for _, s := range e.Suggestions {
+			f := &SuggestionTemplateFields{
+				Message:     s.Message,
+				Replacement: s.Replacement,
+				Line:        s.Start.Line,
+				Column:      col(s.Start),
+				EndLine:     s.End.Line,
+				EndColumn:   col(s.End),
+				Offset:      byteOffsetAt(source, offsets, s.Start),
+				EndOffset:   byteOffsetAt(source, offsets, s.End),
+			}
+			if s.NewFile != nil {
+				f.NewFilePath = s.NewFile.Path
+				f.NewFileContent = s.NewFile.Content
+			}
+			suggestions = append(suggestions, f)
+		}
+	}
+
 	return &ErrorTemplateFields{
-		Message:   e.Message,
-		Filepath:  e.Filepath,
-		Line:      e.Line,
-		Column:    e.Column,
-		Kind:      e.Kind,
-		Snippet:   snippet,
-		EndColumn: end,
+		Message:     e.Message,
+		Filepath:    filepath.ToSlash(e.Filepath),
+		Line:        e.Line,
+		Column:      e.Column,
+		Kind:        e.Kind,
+		Code:        e.Code(),
+		Category:    string(e.Category()),
+		MessageID:   e.MessageID,
+		Snippet:     snippet,
+		EndColumn:   end,
+		Offset:      offset,
+		Suggestions: suggestions,
+		Fingerprint: e.Fingerprint(source),
+	}
+}
+
+// splitLines splits the source into lines. Both "\n" and "\r\n" are accepted as line breaks so that
+// byte offsets in each line are the same on Windows.
+func splitLines(source string) []string {
+	ls := strings.Split(source, "\n")
+	for i, l := range ls {
+		ls[i] = strings.TrimSuffix(l, "\r")
 	}
+	return ls
+}
+
+// lineOffsets returns byte offsets of starts of lines in the source.
+func lineOffsets(source []byte) []int {
+	if len(source) == 0 {
+		return nil
+	}
+	ret := []int{0}
+	for i, b := range source {
+		if b == '\n' {
+			ret = append(ret, i+1)
+		}
+	}
+	return ret
+}
+
+// byteOffsetAt converts the position into a byte offset in the source. Columns are counted in
+// characters. When the position knows its offset, the offset is returned without scanning the line.
+// It returns -1 when the position is out of the source.
+func byteOffsetAt(source []byte, offsets []int, pos *Pos) int {
+	if o, ok := pos.ByteOffset(); ok {
+		if o > len(source) {
+			return -1
+		}
+		return o
+	}
+	if pos.Line <= 0 || pos.Line > len(offsets) || pos.Col <= 0 {
+		return -1
+	}
+	o := offsets[pos.Line-1]
+	for c := 1; c < pos.Col; c++ {
+		if o >= len(source) || source[o] == '\n' || source[o] == '\r' && o+1 < len(source) && source[o+1] == '\n' {
+			return -1
+		}
+		_, w := utf8.DecodeRune(source[o:])
+		o += w
+	}
+	return o
+}
+
+// Apply applies the suggestion to the given source and returns the modified source. The given
+// source is not modified. It returns an error when the range of the suggestion is out of the source.
+func (s *Suggestion) Apply(source []byte) ([]byte, error) {
+	offsets := lineOffsets(source)
+	start, end := byteOffsetAt(source, offsets, s.Start), byteOffsetAt(source, offsets, s.End)
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("range of suggestion %q is out of the source: %d:%d-%d:%d", s.Message, s.Start.Line, s.Start.Col, s.End.Line, s.End.Col)
+	}
+	ret := make([]byte, 0, len(source)-(end-start)+len(s.Replacement))
+	ret = append(ret, source[:start]...)
+	ret = append(ret, s.Replacement...)
+	ret = append(ret, source[end:]...)
+	return ret, nil
+}
+
+// applySuggestions applies the suggestions to the source at once and returns the modified source
+// with the applied suggestions. Suggestions whose ranges are out of the source or overlap with the
+// ranges of other suggestions are not applied. The given source is not modified. New files of the
+// suggestions are not created by this function.
+func applySuggestions(source []byte, ss []*Suggestion) ([]byte, []*Suggestion) {
+	type edit struct {
+		start, end int
+		s          *Suggestion
+	}
+
+	offsets := lineOffsets(source)
+	es := make([]edit, 0, len(ss))
+	for _, s := range ss {
+		start, end := byteOffsetAt(source, offsets, s.Start), byteOffsetAt(source, offsets, s.End)
+		if start < 0 || end < start {
+			continue
+		}
+		es = append(es, edit{start, end, s})
+	}
+	sort.SliceStable(es, func(i, j int) bool {
+		return es[i].start < es[j].start
+	})
+
+	ret := make([]byte, 0, len(source))
+	applied := []*Suggestion{}
+	prev := 0
+	for _, e := range es {
+		if e.start < prev {
+			continue // Overlapping with the previous edit
+		}
+		ret = append(ret, source[prev:e.start]...)
+		ret = append(ret, e.s.Replacement...)
+		prev = e.end
+		applied = append(applied, e.s)
+	}
+	ret = append(ret, source[prev:]...)
+	return ret, applied
 }
 
 // PrettyPrint prints the error with user-friendly way. It prints file name, source position, error
 // message with colorful output and source snippet with indicator. When nil is set to source, no
 // source snippet is not printed. To disable colorful output, set true to fatih/color.NoColor.
 func (e *Error) PrettyPrint(w io.Writer, source []byte) {
+	e.PrettyPrintWithContext(w, source, 0)
+}
+
+// PrettyPrintWithContext is the same as PrettyPrint but it also prints the given number of lines
+// before and after the error line as context of the source snippet.
+func (e *Error) PrettyPrintWithContext(w io.Writer, source []byte, context int) {
+	e.prettyPrint(w, source, context, ColumnUnitRune)
+}
+
+// prettyPrint prints the error with the source snippet. The column of this error is counted in the
+// given unit.
+func (e *Error) prettyPrint(w io.Writer, source []byte, context int, unit ColumnUnit) {
 	yellow.Fprint(w, e.Filepath)
 	gray.Fprint(w, ":")
 	fmt.Fprint(w, e.Line)
@@ -106,18 +372,57 @@ func (e *Error) PrettyPrint(w io.Writer, source []byte) {
 	if len(source) == 0 || e.Line <= 0 {
 		return
 	}
-	line, ok := e.getLine(source)
-	if !ok || len(line) < e.Column-1 {
+	lines := e.getLines(source, context)
+	line, ok := lines[e.Line]
+	if !ok {
+		return
+	}
+	col := unit.ToRune(line, e.Column)
+	if utf8.RuneCountInString(line) < col-1 {
 		return
 	}
+	indicator, _ := getIndicator(line, col)
 
-	lnum := fmt.Sprintf("%d | ", e.Line)
-	indent := strings.Repeat(" ", len(lnum)-2)
+	start, end := e.Line-context, e.Line+context
+	if start < 1 {
+		start = 1
+	}
+	for end > e.Line {
+		if _, ok := lines[end]; ok {
+			break
+		}
+		end--
+	}
+
+	width := len(strconv.Itoa(end))
+	indent := strings.Repeat(" ", width+1)
 	gray.Fprintf(w, "%s|\n", indent)
-	gray.Fprint(w, lnum)
-	fmt.Fprintln(w, line)
-	gray.Fprintf(w, "%s| ", indent)
-	green.Fprintln(w, e.getIndicator(line))
+	for l := start; l <= end; l++ {
+		gray.Fprintf(w, "%*d | ", width, l)
+		fmt.Fprintln(w, lines[l])
+		if l == e.Line {
+			gray.Fprintf(w, "%s| ", indent)
+			green.Fprintln(w, indicator)
+		}
+	}
+}
+
+// getLines returns the error line and the given number of lines around it. Keys of the returned
+// map are line numbers.
+func (e *Error) getLines(source []byte, context int) map[int]string {
+	ret := map[int]string{}
+	s := bufio.NewScanner(bytes.NewReader(source))
+	l := 0
+	for s.Scan() {
+		l++
+		if l > e.Line+context {
+			break
+		}
+		if l >= e.Line-context {
+			ret[l] = s.Text()
+		}
+	}
+	return ret
 }
 
 func (e *Error) getLine(source []byte) (string, bool) {
@@ -132,34 +437,67 @@ func (e *Error) getLine(source []byte) (string, bool) {
 	return "", false
 }
 
-func (e *Error) getIndicator(line string) string {
-	if e.Column <= 0 {
-		return ""
+// getLineOccurrence returns the content of the line where the error occurred with leading and
+// trailing spaces trimmed, and the number of lines with the same content before the line.
+func (e *Error) getLineOccurrence(source []byte) (string, int, bool) {
+	s := bufio.NewScanner(bytes.NewReader(source))
+	lines := []string{}
+	for s.Scan() {
+		lines = append(lines, strings.TrimSpace(s.Text()))
+		if len(lines) == e.Line {
+			l := lines[len(lines)-1]
+			n := 0
+			for _, p := range lines[:len(lines)-1] {
+				if p == l {
+					n++
+				}
+			}
+			return l, n, true
+		}
+	}
+	return "", 0, false
+}
+
+// getIndicator returns the indicator like "^~~~" to underline the token at the column in the line.
+// The column is counted in characters. The second return value is the column of the last character
+// of the underlined token.
+func getIndicator(line string, col int) (string, int) {
+	if col <= 0 {
+		return "", col
 	}
 
-	start := e.Column - 1 // Column is 1-based
+	// Byte offset of the column
+	start := 0
+	for c := 1; c < col && start < len(line); c++ {
+		_, s := utf8.DecodeRuneInString(line[start:])
+		start += s
+	}
 
 	// Count width of non-space characters after '^' for underline
-	uw := 0
-	r := strings.NewReader(line[start:])
-	for {
-		c, s, err := r.ReadRune()
-		if err != nil || s == 0 || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+	uw, n := 0, 0
+	for _, c := range line[start:] {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
 			break
 		}
 		uw += runewidth.RuneWidth(c)
+		n++
 	}
 	if uw > 0 {
 		uw-- // Decrement for place for '^'
 	}
+	end := col
+	if n > 1 {
+		end += n - 1
+	}
 
 	// Count width of spaces before '^'
 	sw := runewidth.StringWidth(line[:start])
-	return fmt.Sprintf("%s^%s", strings.Repeat(" ", sw), strings.Repeat("~", uw))
+	return fmt.Sprintf("%s^%s", strings.Repeat(" ", sw), strings.Repeat("~", uw)), end
 }
 
-// ByErrorPosition is predicate for sort.Interface. It sorts errors slice by file path, line, and
-// column.
+// ByErrorPosition is predicate for sort.Interface. It sorts errors slice by file path, line, column,
+// rule name, and message. Since all fields are compared, the order of errors is deterministic
+// regardless of the order in which rules reported them.
 type ByErrorPosition []*Error
 
 func (by ByErrorPosition) Len() int {
@@ -170,10 +508,16 @@ func (by ByErrorPosition) Less(i, j int) bool {
 	if c := strings.Compare(by[i].Filepath, by[j].Filepath); c != 0 {
 		return c < 0
 	}
-	if by[i].Line == by[j].Line {
+	if by[i].Line != by[j].Line {
+		return by[i].Line < by[j].Line
+	}
+	if by[i].Column != by[j].Column {
 		return by[i].Column < by[j].Column
 	}
-	return by[i].Line < by[j].Line
+	if by[i].Kind != by[j].Kind {
+		return by[i].Kind < by[j].Kind
+	}
+	return by[i].Message < by[j].Message
 }
 
 func (by ByErrorPosition) Swap(i, j int) {
@@ -184,7 +528,8 @@ func (by ByErrorPosition) Swap(i, j int) {
 type ErrorTemplateFields struct {
 	// Message is error message body.
 	Message string `json:"message"`
-	// Filepath is a canonical relative file path. This is empty when input was read from stdin.
+	// Filepath is a canonical relative file path. The path separator is always "/" even on Windows.
+	// This is empty when input was read from stdin.
 	// When encoding into JSON, this field may be omitted when the file path is empty.
 	Filepath string `json:"filepath,omitempty"`
 	// Line is a line number of error position.
@@ -193,12 +538,59 @@ type ErrorTemplateFields struct {
 	Column int `json:"column"`
 	// Kind is a rule name the error belongs to.
 	Kind string `json:"kind"`
+	// Code is a stable error code of the error like "AL1003". It can be explained with -explain
+	// option. When encoding into JSON, this field may be omitted when the kind of the error is unknown.
+	Code string `json:"code,omitempty"`
+	// Category is a category of the error like "security". When encoding into JSON, this field may be
+	// omitted when the kind of the error is unknown.
+	Category string `json:"category,omitempty"`
+	// MessageID is a stable ID of the message like "expression/undefined-property". It does not
+	// change when the message is localized. When encoding into JSON, this field may be omitted when
+	// the message is not in the message catalog.
+	MessageID string `json:"message_id,omitempty"`
 	// Snippet is a code snippet and indicator to indicate where the error occurred.
 	// When encoding into JSON, this field may be omitted when the snippet is empty.
 	Snippet string `json:"snippet,omitempty"`
 	// EndColumn is a column number where the error indicator (^~~~~~~) ends. When no indicator
 	// can be shown, EndColumn is equal to Column.
 	EndColumn int `json:"end_column"`
+	// Offset is a 0-based byte offset of the error position in the source. This value is -1 when the
+	// offset is unknown.
+	Offset int `json:"offset"`
+	// Suggestions is a list of machine-applicable fixes of the error.
+	// When encoding into JSON, this field may be omitted when the error has no suggestion.
+	Suggestions []*SuggestionTemplateFields `json:"suggestions,omitempty"`
+	// Fingerprint is a stable identifier of the error across runs. See Error.Fingerprint for more
+	// details.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// SuggestionTemplateFields holds all fields to format one suggestion of an error.
+type SuggestionTemplateFields struct {
+	// Message is a description of the fix.
+	Message string `json:"message"`
+	// Replacement is a text to replace the range with.
+	Replacement string `json:"replacement"`
+	// Line is a line number of the start position of the range to replace.
+	Line int `json:"line"`
+	// Column is a column number of the start position of the range to replace.
+	Column int `json:"column"`
+	// EndLine is a line number of the end position of the range to replace.
+	EndLine int `json:"end_line"`
+	// EndColumn is a column number of the end position of the range to replace. The character at
+	// this position is not included in the range.
+	EndColumn int `json:"end_column"`
+	// Offset is a byte offset of the start position of the range in the source. This value is -1
+	// when the source is not available.
+	Offset int `json:"offset"`
+	// EndOffset is a byte offset of the end position of the range in the source. This value is -1
+	// when the source is not available.
+	EndOffset int `json:"end_offset"`
+	// NewFilePath is a path of the file created by the fix. It is relative to the repository root.
+	// This value is empty when the fix does not create a file.
+	NewFilePath string `json:"new_file_path,omitempty"`
+	// NewFileContent is the content of the file created by the fix.
+	NewFileContent string `json:"new_file_content,omitempty"`
 }
 
 func unescapeBackslash(s string) string {
@@ -235,6 +627,8 @@ func toPascalCase(s string) string {
 type ruleTemplateFields struct {
 	Name        string
 	Description string
+	Code        string
+	Category    string
 }
 
 type byRuleNameField []*ruleTemplateFields
@@ -265,7 +659,8 @@ func NewErrorFormatter(format string) (*ErrorFormatter, error) {
 	}
 
 	r := map[string]*ruleTemplateFields{
-		"syntax-check": {"syntax-check", "Checks for GitHub Actions workflow syntax"},
+		"syntax-check":  {"syntax-check", "Checks for GitHub Actions workflow syntax", ErrorCode("syntax-check"), string(ErrorCategoryOf("syntax-check"))},
+		"future-syntax": {"future-syntax", "Checks for keys of GitHub Actions workflow syntax not supported by this version yet", ErrorCode("future-syntax"), string(ErrorCategoryOf("future-syntax"))},
 	}
 
 	funcs := template.FuncMap(map[string]interface{}{
@@ -326,6 +721,6 @@ func (f *ErrorFormatter) RegisterRule(r Rule) {
 
 	n := r.Name()
 	if _, ok := f.rules[n]; !ok {
-		f.rules[n] = &ruleTemplateFields{n, r.Description()}
+		f.rules[n] = &ruleTemplateFields{n, r.Description(), ErrorCode(n), string(ErrorCategoryOf(n))}
 	}
 }
@@ -0,0 +1,122 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCategory is a category of errors. Each kind of errors belongs to one category so that tools
+// like code review bots can filter errors by their categories.
+type ErrorCategory string
+
+const (
+	// ErrorCategorySyntax is a category of errors which make a workflow rejected by GitHub Actions
+	// or make it fail at runtime. For example, syntax errors, invalid usage of actions, and problems
+	// in scripts at run:.
+	ErrorCategorySyntax ErrorCategory = "syntax"
+	// ErrorCategoryExpression is a category of errors in expressions embedded with ${{ }} and
+	// conditions at if:.
+	ErrorCategoryExpression ErrorCategory = "expression"
+	// ErrorCategorySecurity is a category of errors which may leak secrets or give excessive
+	// privileges to workflows.
+	ErrorCategorySecurity ErrorCategory = "security"
+	// ErrorCategoryStyle is a category of errors which make workflows hard to read or maintain.
+	ErrorCategoryStyle ErrorCategory = "style"
+	// ErrorCategoryPortability is a category of errors which depend on runner environments such as
+	// runner labels, shells, and commands installed on runners.
+	ErrorCategoryPortability ErrorCategory = "portability"
+	// ErrorCategoryPerformance is a category of errors which make workflows slow or consume more
+	// resources than necessary.
+	ErrorCategoryPerformance ErrorCategory = "performance"
+)
+
+// AllErrorCategories is a list of all error categories.
+var AllErrorCategories = []ErrorCategory{
+	ErrorCategorySyntax,
+	ErrorCategoryExpression,
+	ErrorCategorySecurity,
+	ErrorCategoryStyle,
+	ErrorCategoryPortability,
+	ErrorCategoryPerformance,
+}
+
+// errorCategories is a mapping from kinds of errors to their categories. Add a new entry when
+// adding a new rule as well as errorCodes.
+var errorCategories = map[string]ErrorCategory{
+	"syntax-check":        ErrorCategorySyntax,
+	"expression":          ErrorCategoryExpression,
+	"action":              ErrorCategorySyntax,
+	"credentials":         ErrorCategorySecurity,
+	"deprecated-commands": ErrorCategorySecurity,
+	"env-var":             ErrorCategorySyntax,
+	"events":              ErrorCategorySyntax,
+	"glob":                ErrorCategorySyntax,
+	"id":                  ErrorCategorySyntax,
+	"if-cond":             ErrorCategoryExpression,
+	"job-needs":           ErrorCategorySyntax,
+	"matrix":              ErrorCategorySyntax,
+	"permissions":         ErrorCategorySecurity,
+	"pyflakes":            ErrorCategorySyntax,
+	"runner-label":        ErrorCategoryPortability,
+	"shell-name":          ErrorCategoryPortability,
+	"shellcheck":          ErrorCategorySyntax,
+	"workflow-call":       ErrorCategorySyntax,
+	"yaml-anchor":         ErrorCategorySyntax,
+	"style":               ErrorCategoryStyle,
+	"step-name":           ErrorCategoryStyle,
+	"run-script":          ErrorCategoryStyle,
+	"runner-tools":        ErrorCategoryPortability,
+	"failure-handling":    ErrorCategorySecurity,
+	"secret-output":       ErrorCategorySecurity,
+	"remote":              ErrorCategorySyntax,
+	"services":            ErrorCategorySyntax,
+	"workflow-name":       ErrorCategoryStyle,
+	"unused":              ErrorCategoryStyle,
+	"workflow-run":        ErrorCategorySyntax,
+	"schedule":            ErrorCategoryPerformance,
+	"limits":              ErrorCategoryPerformance,
+	"checkout":            ErrorCategorySyntax,
+	"setup-cache":         ErrorCategoryPerformance,
+	"release":             ErrorCategorySyntax,
+	"path-filter":         ErrorCategorySyntax,
+	"git-push":            ErrorCategorySyntax,
+	"future-syntax":       ErrorCategorySyntax,
+	"timeout":             ErrorCategoryPerformance,
+	"script-linter":       ErrorCategorySyntax,
+	"marketplace":         ErrorCategorySyntax,
+	"env-file":            ErrorCategorySyntax,
+}
+
+// ErrorCategoryOf returns the category of the kind of errors. The kind is a rule name like "action"
+// or "syntax-check". It returns an empty string when the kind is unknown such as errors of custom
+// rules.
+func ErrorCategoryOf(kind string) ErrorCategory {
+	return errorCategories[kind]
+}
+
+// ParseErrorCategories parses the comma-separated list of error categories like "security,syntax".
+// An empty string is parsed as an empty list.
+func ParseErrorCategories(s string) ([]ErrorCategory, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ss := strings.Split(s, ",")
+	ret := make([]ErrorCategory, 0, len(ss))
+	for _, c := range ss {
+		c := ErrorCategory(strings.TrimSpace(c))
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+func (c ErrorCategory) validate() error {
+	for _, k := range AllErrorCategories {
+		if c == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown error category %q. it must be one of \"syntax\", \"expression\", \"security\", \"style\", \"portability\", or \"performance\"", c)
+}
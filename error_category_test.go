@@ -0,0 +1,105 @@
+package actionlint
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestErrorCategoryAllKindsHaveCategories(t *testing.T) {
+	for kind := range errorCodes {
+		if ErrorCategoryOf(kind) == "" {
+			t.Errorf("category is not assigned to %q", kind)
+		}
+	}
+	for kind, c := range errorCategories {
+		if ErrorCode(kind) == "" {
+			t.Errorf("category is assigned to unknown kind %q", kind)
+		}
+		if err := c.validate(); err != nil {
+			t.Errorf("category of %q is invalid: %s", kind, err)
+		}
+		code := ErrorCode(kind)
+		re := regexp.MustCompile(`(?m)^\| \[` + code + `\]\(#` + code + `\) +\| ` + "`" + kind + "`" + ` +\| ` + "`" + string(c) + "`" + ` +\|$`)
+		if !re.MatchString(errorCodesDoc) {
+			t.Errorf("category %q of %q is not listed in docs/codes.md", c, kind)
+		}
+	}
+}
+
+func TestErrorCategoryParse(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  []ErrorCategory
+	}{
+		{"", nil},
+		{"security", []ErrorCategory{ErrorCategorySecurity}},
+		{"security,syntax", []ErrorCategory{ErrorCategorySecurity, ErrorCategorySyntax}},
+		{" style , performance ", []ErrorCategory{ErrorCategoryStyle, ErrorCategoryPerformance}},
+	} {
+		have, err := ParseErrorCategories(tc.input)
+		if err != nil {
+			t.Fatalf("%q caused error: %s", tc.input, err)
+		}
+		if diff := cmp.Diff(tc.want, have); diff != "" {
+			t.Errorf("%q was parsed unexpectedly: %s", tc.input, diff)
+		}
+	}
+
+	for _, input := range []string{"foo", "security,", "security,Syntax"} {
+		_, err := ParseErrorCategories(input)
+		if err == nil || !strings.Contains(err.Error(), "unknown error category") {
+			t.Errorf("unexpected error for %q: %v", input, err)
+		}
+	}
+}
+
+func TestErrorCategoryFilterErrors(t *testing.T) {
+	src := []byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ foo }}
+      - run: echo hi  
+    container:
+      image: alpine
+      credentials:
+        username: user
+        password: pass
+`)
+	for _, tc := range []struct {
+		categories []ErrorCategory
+		want       []string
+	}{
+		{nil, []string{"expression", "style", "credentials"}},
+		{[]ErrorCategory{ErrorCategorySecurity}, []string{"credentials"}},
+		{[]ErrorCategory{ErrorCategorySecurity, ErrorCategoryExpression}, []string{"expression", "credentials"}},
+		{[]ErrorCategory{ErrorCategoryPerformance}, []string{}},
+	} {
+		l, err := NewLinter(io.Discard, &LinterOptions{OnlyCategories: tc.categories})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.defaultConfig = &Config{Rules: RulesConfig{Style: StyleRuleConfig{TrailingSpaces: true}}}
+
+		errs, err := l.Lint("test.yaml", src, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have := []string{}
+		for _, e := range errs {
+			have = append(have, e.Kind)
+		}
+		if diff := cmp.Diff(tc.want, have); diff != "" {
+			t.Errorf("errors filtered with %v were unexpected: %s", tc.categories, diff)
+		}
+	}
+
+	if _, err := NewLinter(io.Discard, &LinterOptions{OnlyCategories: []ErrorCategory{"foo"}}); err == nil {
+		t.Fatal("unknown category should cause an error")
+	}
+}
@@ -0,0 +1,107 @@
+package actionlint
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+)
+
+//go:embed docs/codes.md
+var errorCodesDoc string
+
+// errorCodes is a mapping from kinds of errors to their error codes. Once a code is assigned to a
+// kind, it must not be changed or reused for other kinds since users track and suppress errors with
+// the codes. Add a new code at the end when adding a new rule and explain it in docs/codes.md.
+var errorCodes = map[string]string{
+	"syntax-check":        "AL1001",
+	"expression":          "AL1002",
+	"action":              "AL1003",
+	"credentials":         "AL1004",
+	"deprecated-commands": "AL1005",
+	"env-var":             "AL1006",
+	"events":              "AL1007",
+	"glob":                "AL1008",
+	"id":                  "AL1009",
+	"if-cond":             "AL1010",
+	"job-needs":           "AL1011",
+	"matrix":              "AL1012",
+	"permissions":         "AL1013",
+	"pyflakes":            "AL1014",
+	"runner-label":        "AL1015",
+	"shell-name":          "AL1016",
+	"shellcheck":          "AL1017",
+	"workflow-call":       "AL1018",
+	"yaml-anchor":         "AL1019",
+	"style":               "AL1020",
+	"step-name":           "AL1021",
+	"run-script":          "AL1022",
+	"runner-tools":        "AL1023",
+	"failure-handling":    "AL1024",
+	"secret-output":       "AL1025",
+	"remote":              "AL1026",
+	"services":            "AL1027",
+	"workflow-name":       "AL1028",
+	"unused":              "AL1029",
+	"workflow-run":        "AL1030",
+	"schedule":            "AL1031",
+	"limits":              "AL1032",
+	"checkout":            "AL1033",
+	"setup-cache":         "AL1034",
+	"release":             "AL1035",
+	"path-filter":         "AL1036",
+	"git-push":            "AL1037",
+	"future-syntax":       "AL1038",
+	"timeout":             "AL1039",
+	"script-linter":       "AL1040",
+	"marketplace":         "AL1041",
+	"env-file":            "AL1042",
+}
+
+var (
+	reErrorCodesLinkDef = regexp.MustCompile(`(?m)^\[([^\]]+)\]: (\S+)$`)
+	reErrorCodesLinkRef = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]+)\]`)
+)
+
+// ErrorCode returns the stable error code like "AL1003" of the kind of errors. The kind is a rule
+// name like "action" or "syntax-check". It returns an empty string when the kind is unknown.
+func ErrorCode(kind string) string {
+	return errorCodes[kind]
+}
+
+// ExplainErrorCode returns the explanation of the error code in Markdown format. The code is
+// case-insensitive. A kind of errors like "expression" is also accepted instead of the code. The
+// second return value is false when the code is unknown.
+func ExplainErrorCode(code string) (string, bool) {
+	code = strings.ToUpper(code)
+	if c, ok := errorCodes[strings.ToLower(code)]; ok {
+		code = c
+	}
+
+	anchor := `<a id="` + code + `"></a>`
+	i := strings.Index(errorCodesDoc, anchor)
+	if i < 0 {
+		return "", false
+	}
+	s := errorCodesDoc[i+len(anchor):]
+	if j := strings.Index(s, `<a id="`); j >= 0 {
+		s = s[:j]
+	}
+	if m := reErrorCodesLinkDef.FindStringIndex(s); m != nil {
+		s = s[:m[0]]
+	}
+
+	// Reference-style links are not available since the definitions are at the end of the document
+	urls := map[string]string{}
+	for _, m := range reErrorCodesLinkDef.FindAllStringSubmatch(errorCodesDoc, -1) {
+		urls[m[1]] = m[2]
+	}
+	s = reErrorCodesLinkRef.ReplaceAllStringFunc(s, func(l string) string {
+		m := reErrorCodesLinkRef.FindStringSubmatch(l)
+		if u, ok := urls[m[2]]; ok {
+			return m[1] + " (" + u + ")"
+		}
+		return l
+	})
+
+	return strings.TrimSpace(s) + "\n", true
+}
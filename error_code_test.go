@@ -0,0 +1,88 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodeAllRulesHaveCodes(t *testing.T) {
+	// All rules except for rules depending on external commands or GitHub API are listed in the SARIF output
+	b, err := os.ReadFile(filepath.Join("testdata", "format", "test.sarif"))
+	if err != nil {
+		panic(err)
+	}
+	var sarif struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(b, &sarif); err != nil {
+		panic(err)
+	}
+
+	for _, r := range sarif.Runs[0].Tool.Driver.Rules {
+		if ErrorCode(r.ID) == "" {
+			t.Errorf("error code is not assigned to rule %q", r.ID)
+		}
+	}
+}
+
+func TestErrorCodeUniqueAndExplained(t *testing.T) {
+	re := regexp.MustCompile(`^AL[0-9]{4}$`)
+	seen := map[string]string{}
+	for kind, code := range errorCodes {
+		if !re.MatchString(code) {
+			t.Errorf("error code %q of %q is not in the format of ALXXXX", code, kind)
+		}
+		if k, ok := seen[code]; ok {
+			t.Errorf("error code %q is assigned to both %q and %q", code, k, kind)
+		}
+		seen[code] = kind
+
+		s, ok := ExplainErrorCode(code)
+		if !ok {
+			t.Errorf("error code %q of %q is not explained in docs/codes.md", code, kind)
+			continue
+		}
+		if want := "## " + code + ": `" + kind + "`\n"; !strings.HasPrefix(s, want) {
+			t.Errorf("explanation of %q should start with %q but got %q", code, want, s)
+		}
+		if strings.Contains(s, "<a id=") || strings.Contains(s, "][") {
+			t.Errorf("explanation of %q contains other sections or unresolved links: %q", code, s)
+		}
+	}
+
+	for _, m := range regexp.MustCompile(`<a id="(AL[0-9]+)"></a>`).FindAllStringSubmatch(errorCodesDoc, -1) {
+		if _, ok := seen[m[1]]; !ok {
+			t.Errorf("error code %q is explained in docs/codes.md but not defined", m[1])
+		}
+	}
+}
+
+func TestErrorCodeExplainByKindAndCase(t *testing.T) {
+	want, ok := ExplainErrorCode("AL1002")
+	if !ok {
+		t.Fatal("AL1002 is not found")
+	}
+	for _, c := range []string{"al1002", "expression"} {
+		have, ok := ExplainErrorCode(c)
+		if !ok || have != want {
+			t.Errorf("explanation of %q is different from AL1002: %q", c, have)
+		}
+	}
+	for _, c := range []string{"", "AL9999", "unknown-rule", "AL100"} {
+		if s, ok := ExplainErrorCode(c); ok {
+			t.Errorf("%q should not be explained: %q", c, s)
+		}
+	}
+}
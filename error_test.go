@@ -29,7 +29,7 @@ func (w testErrorWriter) Write(b []byte) (int, error) {
 func TestErrorErrorAt(t *testing.T) {
 	m := "message"
 	k := "kind"
-	err := errorAt(&Pos{1, 2}, k, m)
+	err := errorAt(&Pos{Line: 1, Col: 2}, k, m)
 	if err.Message != m {
 		t.Errorf("wanted %q but got %q", m, err.Message)
 	}
@@ -50,7 +50,7 @@ func TestErrorErrorAt(t *testing.T) {
 func TestErrorErrorfAt(t *testing.T) {
 	m := "this is message"
 	k := "kind"
-	err := errorfAt(&Pos{1, 2}, k, "%s is %s", "this", "message")
+	err := errorfAt(&Pos{Line: 1, Col: 2}, k, "%s is %s", "this", "message")
 	if err.Message != m {
 		t.Errorf("wanted %q but got %q", m, err.Message)
 	}
@@ -211,7 +211,7 @@ func TestErrorPrettyPrint(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.message, func(t *testing.T) {
-			err := errorAt(&Pos{tc.line, tc.column}, "kind", tc.message)
+			err := errorAt(&Pos{Line: tc.line, Col: tc.column}, "kind", tc.message)
 			err.Filepath = "filename.txt"
 
 			var buf bytes.Buffer
@@ -303,6 +303,73 @@ func TestErrorSortErrorsByFile(t *testing.T) {
 	}
 }
 
+func TestErrorSortErrorsAtSamePosition(t *testing.T) {
+	errs := []*Error{
+		{Filepath: "a.yaml", Line: 1, Column: 1, Kind: "syntax-check", Message: "foo"},
+		{Filepath: "a.yaml", Line: 1, Column: 1, Kind: "expression", Message: "foo"},
+		{Filepath: "a.yaml", Line: 1, Column: 1, Kind: "syntax-check", Message: "bar"},
+	}
+
+	sort.Stable(ByErrorPosition(errs))
+
+	have := []string{}
+	for _, e := range errs {
+		have = append(have, e.Kind+":"+e.Message)
+	}
+	want := []string{"expression:foo", "syntax-check:bar", "syntax-check:foo"}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	src := []byte("on: push\njobs:\n  test:\n    steps:\n      - run: echo ${{ unknown }}\n")
+	e := &Error{Filepath: "test.yaml", Line: 5, Column: 23, Kind: "expression", Message: "foo"}
+	fp := e.Fingerprint(src)
+	if len(fp) != 32 {
+		t.Fatalf("unexpected fingerprint %q", fp)
+	}
+	if fp != e.GetTemplateFields(src).Fingerprint {
+		t.Fatal("fingerprint in template fields is different")
+	}
+
+	// Fingerprint does not change when unrelated lines are added or indentation changed
+	moved := []byte("# comment\non: push\njobs:\n  test:\n    steps:\n        - run: echo ${{ unknown }}\n")
+	if have := (&Error{Filepath: "test.yaml", Line: 6, Column: 25, Kind: "expression", Message: "foo"}).Fingerprint(moved); have != fp {
+		t.Errorf("fingerprint changed after moving the error: %q vs %q", fp, have)
+	}
+
+	for _, o := range []*Error{
+		{Filepath: "other.yaml", Line: 5, Column: 23, Kind: "expression", Message: "foo"},
+		{Filepath: "test.yaml", Line: 5, Column: 23, Kind: "syntax-check", Message: "foo"},
+		{Filepath: "test.yaml", Line: 5, Column: 23, Kind: "expression", Message: "bar"},
+		{Filepath: "test.yaml", Line: 4, Column: 23, Kind: "expression", Message: "foo"},
+	} {
+		if have := o.Fingerprint(src); have == fp {
+			t.Errorf("fingerprint of %v should be different from %v", o, e)
+		}
+	}
+
+	// Errors on the lines with the same content have different fingerprints
+	dup := []byte("on: push\njobs:\n  a:\n    runs-on: foo\n  b:\n    runs-on: foo\n")
+	e1 := &Error{Filepath: "test.yaml", Line: 4, Column: 14, Kind: "runner-label", Message: "foo"}
+	e2 := &Error{Filepath: "test.yaml", Line: 6, Column: 14, Kind: "runner-label", Message: "foo"}
+	fp1, fp2 := e1.Fingerprint(dup), e2.Fingerprint(dup)
+	if fp1 == fp2 {
+		t.Errorf("fingerprints of errors on the lines with the same content are the same: %q", fp1)
+	}
+	// The first occurrence has the same fingerprint as an error on a line which appears only once
+	once := []byte("on: push\njobs:\n  a:\n    runs-on: foo\n")
+	if have := e1.Fingerprint(once); have != fp1 {
+		t.Errorf("fingerprint of the first occurrence changed: %q vs %q", fp1, have)
+	}
+	// Fingerprints do not change when unrelated lines are inserted
+	moved = []byte("on: push\njobs:\n  a:\n    runs-on: foo\n    timeout-minutes: 5\n  b:\n    runs-on: foo\n")
+	if have := (&Error{Filepath: "test.yaml", Line: 7, Column: 14, Kind: "runner-label", Message: "foo"}).Fingerprint(moved); have != fp2 {
+		t.Errorf("fingerprint of the second occurrence changed after inserting a line: %q vs %q", fp2, have)
+	}
+}
+
 func TestErrorGetTemplateFieldsOK(t *testing.T) {
 	testCases := []struct {
 		message string
@@ -335,7 +402,7 @@ func TestErrorGetTemplateFieldsOK(t *testing.T) {
 
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			err := errorAt(&Pos{1, tc.column}, "kind", tc.message)
+			err := errorAt(&Pos{Line: 1, Col: tc.column}, "kind", tc.message)
 			err.Filepath = "filename.txt"
 			f := err.GetTemplateFields([]byte(tc.source))
 			if f.Message != tc.message {
@@ -365,7 +432,7 @@ func TestErrorGetTemplateFieldsOK(t *testing.T) {
 
 // Regression test for #128
 func TestErrorGetTemplateFieldsColumnIsOutOfBounds(t *testing.T) {
-	err := errorAt(&Pos{1, 9999}, "kind", "this is message")
+	err := errorAt(&Pos{Line: 1, Col: 9999}, "kind", "this is message")
 	err.Filepath = "filename.yaml"
 	f := err.GetTemplateFields([]byte("this is source"))
 	if strings.Contains(f.Snippet, "\n") {
@@ -373,6 +440,32 @@ func TestErrorGetTemplateFieldsColumnIsOutOfBounds(t *testing.T) {
 	}
 }
 
+func TestErrorGetTemplateFieldsSuggestions(t *testing.T) {
+	err := errorAt(&Pos{Line: 2, Col: 6}, "kind", "this is message")
+	err.Suggestions = []*Suggestion{
+		{Message: "replace", Start: &Pos{Line: 2, Col: 6}, End: &Pos{Line: 2, Col: 9}, Replacement: "true"},
+		{Message: "multi-byte", Start: &Pos{Line: 3, Col: 3}, End: &Pos{Line: 3, Col: 4}},
+		{Message: "out of line", Start: &Pos{Line: 1, Col: 99}, End: &Pos{Line: 5, Col: 1}},
+	}
+	f := err.GetTemplateFields([]byte("on: push\nfoo: yes\nあいう\n"))
+
+	want := []*SuggestionTemplateFields{
+		{Message: "replace", Replacement: "true", Line: 2, Column: 6, EndLine: 2, EndColumn: 9, Offset: 14, EndOffset: 17},
+		{Message: "multi-byte", Line: 3, Column: 3, EndLine: 3, EndColumn: 4, Offset: 24, EndOffset: 27},
+		{Message: "out of line", Line: 1, Column: 99, EndLine: 5, EndColumn: 1, Offset: -1, EndOffset: -1},
+	}
+	if !cmp.Equal(want, f.Suggestions) {
+		t.Fatal(cmp.Diff(want, f.Suggestions))
+	}
+
+	f = err.GetTemplateFields(nil)
+	for _, s := range f.Suggestions {
+		if s.Offset != -1 || s.EndOffset != -1 {
+			t.Fatalf("offsets should be -1 when no source is given: %#v", s)
+		}
+	}
+}
+
 func TestErrorErrorToString(t *testing.T) {
 	err := &Error{
 		Message: "this is message",
@@ -455,8 +548,8 @@ func TestErrorPrintFormattedWithTemplateFields(t *testing.T) {
 
 func TestErrorPrintFormattedErrors(t *testing.T) {
 	errs := []*Error{
-		errorAt(&Pos{1, 1}, "kind1", "error1"),
-		errorAt(&Pos{1, 0}, "kind2", "error2"),
+		errorAt(&Pos{Line: 1, Col: 1}, "kind1", "error1"),
+		errorAt(&Pos{Line: 1, Col: 0}, "kind2", "error2"),
 	}
 
 	f, err := NewErrorFormatter("{{range $ = .}}({{$.Message | printf \"%q\"}},{{$.Snippet | printf \"%q\"}}){{end}}")
@@ -681,8 +774,169 @@ func TestErrorFormatterRegisterRuleInParallel(t *testing.T) {
 		<-done
 	}
 
-	// Note: `syntax-check` rule is registered by NewErrorFormatter
-	if len(f.rules) != 101 {
+	// Note: `syntax-check` and `future-syntax` rules are registered by NewErrorFormatter
+	if len(f.rules) != 102 {
 		t.Fatalf("not all rules were registered. %d rules were registered", len(f.rules))
 	}
 }
+
+func TestErrorPrettyPrintWithContext(t *testing.T) {
+	testCases := []struct {
+		what     string
+		line     int
+		column   int
+		context  int
+		expected string
+	}{
+		{
+			what:    "no context",
+			line:    3,
+			column:  1,
+			context: 0,
+			expected: `test.yaml:3:1: message [kind]
+  |
+3 | three
+  | ^~~~~
+`,
+		},
+		{
+			what:    "context at middle of source",
+			line:    3,
+			column:  1,
+			context: 1,
+			expected: `test.yaml:3:1: message [kind]
+  |
+2 | two
+3 | three
+  | ^~~~~
+4 | four
+`,
+		},
+		{
+			what:    "context at start of source",
+			line:    1,
+			column:  1,
+			context: 2,
+			expected: `test.yaml:1:1: message [kind]
+  |
+1 | one
+  | ^~~
+2 | two
+3 | three
+`,
+		},
+		{
+			what:    "context at end of source",
+			line:    10,
+			column:  1,
+			context: 2,
+			expected: `test.yaml:10:1: message [kind]
+   |
+ 8 | eight
+ 9 | nine
+10 | ten
+   | ^~~
+`,
+		},
+	}
+
+	src := []byte("one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n")
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := &Error{
+				Message:  "message",
+				Filepath: "test.yaml",
+				Line:     tc.line,
+				Column:   tc.column,
+				Kind:     "kind",
+			}
+			err.PrettyPrintWithContext(&buf, src, tc.context)
+			if have := buf.String(); have != tc.expected {
+				t.Fatalf("wanted\n%q\nbut have\n%q", tc.expected, have)
+			}
+		})
+	}
+}
+
+func TestErrorSuggestionApply(t *testing.T) {
+	src := []byte("on: push   \njobs:\n  test:\n    if: yes\n")
+	for _, tc := range []struct {
+		s    *Suggestion
+		want string
+	}{
+		{&Suggestion{Start: &Pos{Line: 1, Col: 9}, End: &Pos{Line: 1, Col: 12}}, "on: push\njobs:\n  test:\n    if: yes\n"},
+		{&Suggestion{Start: &Pos{Line: 4, Col: 9}, End: &Pos{Line: 4, Col: 12}, Replacement: "true"}, "on: push   \njobs:\n  test:\n    if: true\n"},
+		{&Suggestion{Start: &Pos{Line: 2, Col: 1}, End: &Pos{Line: 2, Col: 1}, Replacement: "---\n"}, "on: push   \n---\njobs:\n  test:\n    if: yes\n"},
+	} {
+		have, err := tc.s.Apply(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(have) != tc.want {
+			t.Errorf("wanted %q but got %q", tc.want, have)
+		}
+	}
+	if string(src) != "on: push   \njobs:\n  test:\n    if: yes\n" {
+		t.Fatalf("source was modified: %q", src)
+	}
+
+	s := &Suggestion{Message: "out of range", Start: &Pos{Line: 10, Col: 1}, End: &Pos{Line: 10, Col: 2}}
+	if _, err := s.Apply(src); err == nil || !strings.Contains(err.Error(), "out of the source") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestErrorSuggestionApplyCRLF(t *testing.T) {
+	src := []byte("on: push   \r\njobs:\r\n  test:\r\n    if: yes\r\n")
+	for _, tc := range []struct {
+		s    *Suggestion
+		want string
+	}{
+		{&Suggestion{Start: &Pos{Line: 1, Col: 9}, End: &Pos{Line: 1, Col: 12}}, "on: push\r\njobs:\r\n  test:\r\n    if: yes\r\n"},
+		{&Suggestion{Start: &Pos{Line: 4, Col: 9}, End: &Pos{Line: 4, Col: 12}, Replacement: "true"}, "on: push   \r\njobs:\r\n  test:\r\n    if: true\r\n"},
+	} {
+		have, err := tc.s.Apply(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(have) != tc.want {
+			t.Errorf("wanted %q but got %q", tc.want, have)
+		}
+	}
+
+	// "\r" is a part of the line break. It must not be counted as a character of the line
+	s := &Suggestion{Message: "beyond line break", Start: &Pos{Line: 2, Col: 7}, End: &Pos{Line: 2, Col: 8}}
+	if _, err := s.Apply(src); err == nil || !strings.Contains(err.Error(), "out of the source") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestErrorApplySuggestions(t *testing.T) {
+	src := []byte("on: push   \njobs:\n  test:\n    if: yes\n")
+	ss := []*Suggestion{
+		{Start: &Pos{Line: 4, Col: 9}, End: &Pos{Line: 4, Col: 12}, Replacement: "true"},
+		{Start: &Pos{Line: 1, Col: 9}, End: &Pos{Line: 1, Col: 12}},
+		{Start: &Pos{Line: 4, Col: 10}, End: &Pos{Line: 4, Col: 11}, Replacement: "overlapping"},
+		{Start: &Pos{Line: 10, Col: 1}, End: &Pos{Line: 10, Col: 2}, Replacement: "out of range"},
+	}
+	have, applied := applySuggestions(src, ss)
+	if want := "on: push\njobs:\n  test:\n    if: true\n"; string(have) != want {
+		t.Errorf("wanted %q but got %q", want, have)
+	}
+	if n := len(applied); n != 2 {
+		t.Errorf("wanted 2 suggestions to be applied but got %d", n)
+	}
+	if string(src) != "on: push   \njobs:\n  test:\n    if: yes\n" {
+		t.Fatalf("source was modified: %q", src)
+	}
+}
+
+func TestErrorSplitLines(t *testing.T) {
+	want := []string{"a", "b", "", "c"}
+	for _, src := range []string{"a\nb\n\nc", "a\r\nb\r\n\r\nc", "a\r\nb\n\r\nc"} {
+		if diff := cmp.Diff(want, splitLines(src)); diff != "" {
+			t.Errorf("lines of %q mismatch: %s", src, diff)
+		}
+	}
+}
@@ -13,6 +13,13 @@ type ExprError struct {
 	Line int
 	// Column is column number position which caused the error. Note that this value is 1-based.
 	Column int
+	// MessageID is a stable ID of the message like "expression/undefined-property". This field is
+	// empty when the message is not in the message catalog.
+	MessageID string
+	// format and args are the format string and its arguments which created the message. They are
+	// used to localize the message.
+	format string
+	args   []interface{}
 }
 
 func (e *ExprError) Error() string {
@@ -0,0 +1,129 @@
+package actionlint
+
+import (
+	"strings"
+)
+
+// exprCache is a cache of expressions in a workflow file. Generated workflows often repeat the same
+// expressions like ${{ github.sha }} many times. The cache parses the same expression only once and
+// reuses the result of its semantics check while the types of the contexts referenced by the
+// expression are not updated.
+//
+// The cache is not shared across files since types of contexts like `inputs` and `secrets` depend
+// on the workflow file.
+type exprCache struct {
+	exprs map[string]*cachedExpr
+	// gens is a mapping from context names to their generations. A generation is incremented when
+	// the type of the context is updated.
+	gens map[string]int
+}
+
+// cachedExpr is an expression parsed and checked in the file.
+type cachedExpr struct {
+	node ExprNode
+	// offset is the offset of the end of the expression including the "}}" end marker.
+	offset int
+	// contexts is names of the contexts referenced in the expression.
+	contexts []string
+	checks   []*cachedExprCheck
+}
+
+// cachedExprCheck is a result of the semantics check of the expression. The result depends on the
+// check for untrusted inputs, the context availability at the workflow key, and generations of the
+// contexts referenced in the expression.
+type cachedExprCheck struct {
+	untrusted   bool
+	workflowKey string
+	gens        []int
+	ty          ExprType
+	errs        []*ExprError
+}
+
+func newExprCache() *exprCache {
+	return &exprCache{map[string]*cachedExpr{}, map[string]int{}}
+}
+
+// updated marks the types of the contexts were updated. Results of semantics checks of
+// expressions which reference the contexts are invalidated.
+func (c *exprCache) updated(contexts ...string) {
+	for _, n := range contexts {
+		c.gens[n]++
+	}
+}
+
+// parse parses the expression at the start of the source. The source is a string after "${{". It
+// returns the offset after the expression on error as well.
+func (c *exprCache) parse(src string) (*cachedExpr, int, *ExprError) {
+	// The parser stops at the first "}}" which is not in a string literal. Parsing the same prefix of
+	// a source always results in the same expression.
+	if i := strings.Index(src, "}}"); i >= 0 {
+		if e, ok := c.exprs[src[:i+2]]; ok {
+			return e, e.offset, nil
+		}
+	}
+
+	l := NewExprLexer(src)
+	n, err := NewExprParser().Parse(l)
+	if err != nil {
+		return nil, l.Offset(), err
+	}
+
+	e := &cachedExpr{node: n, offset: l.Offset()}
+	seen := map[string]struct{}{}
+	VisitExprNode(n, func(n, _ ExprNode, entering bool) {
+		if v, ok := n.(*VariableNode); ok && entering {
+			name := strings.ToLower(v.Name)
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				e.contexts = append(e.contexts, name)
+			}
+		}
+	})
+	// The expression is cached only when its end is known
+	if e.offset > 0 {
+		c.exprs[src[:e.offset]] = e
+	}
+	return e, e.offset, nil
+}
+
+// check returns the result of the semantics check of the expression. When no valid result is
+// cached, it runs the check function and caches the result. Results of object and array types are
+// not cached since callers may modify them.
+func (c *exprCache) check(e *cachedExpr, untrusted bool, workflowKey string, check func() (ExprType, []*ExprError)) (ExprType, []*ExprError) {
+	var found *cachedExprCheck
+	for _, r := range e.checks {
+		if r.untrusted == untrusted && r.workflowKey == workflowKey {
+			found = r
+			break
+		}
+	}
+	if found != nil && c.valid(e, found.gens) {
+		return found.ty, found.errs
+	}
+
+	ty, errs := check()
+	switch ty.(type) {
+	case *ObjectType, *ArrayType:
+		return ty, errs
+	}
+
+	gens := make([]int, 0, len(e.contexts))
+	for _, n := range e.contexts {
+		gens = append(gens, c.gens[n])
+	}
+	if found != nil {
+		found.gens, found.ty, found.errs = gens, ty, errs
+	} else {
+		e.checks = append(e.checks, &cachedExprCheck{untrusted, workflowKey, gens, ty, errs})
+	}
+	return ty, errs
+}
+
+func (c *exprCache) valid(e *cachedExpr, gens []int) bool {
+	for i, n := range e.contexts {
+		if c.gens[n] != gens[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprCacheParseSameExpression(t *testing.T) {
+	c := newExprCache()
+	e1, o1, err := c.parse("github.sha }} foo ${{ matrix.os }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, o2, err := c.parse("github.sha }} bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e1 != e2 || o1 != o2 || o1 != len("github.sha }}") {
+		t.Fatalf("cached expression was not reused: %v (offset %d) vs %v (offset %d)", e1, o1, e2, o2)
+	}
+	if len(e1.contexts) != 1 || e1.contexts[0] != "github" {
+		t.Fatal("unexpected contexts:", e1.contexts)
+	}
+
+	// "}}" in a string literal is not the end of the expression
+	e3, o3, err := c.parse("format('}}', github.sha) }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e3 == e1 || o3 != len("format('}}', github.sha) }}") {
+		t.Fatalf("unexpected expression %v at offset %d", e3, o3)
+	}
+
+	if _, o, err := c.parse("github. }}"); err == nil || o == 0 {
+		t.Fatalf("parse error was expected but got %v at offset %d", err, o)
+	}
+}
+
+func TestExprCacheInvalidateChecksOnContextUpdate(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ steps.a.outputs.x }} ${{ github.sha }}
+      - id: a
+        run: echo
+      - run: echo ${{ steps.a.outputs.x }} ${{ github.sha }}
+      - run: echo ${{ steps.a.outputs.x }} ${{ github.sha }}
+        if: ${{ steps.a.outcome == 'success' }}
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	r := NewRuleExpression()
+	v := NewVisitor()
+	v.AddPass(r)
+	if err := v.Visit(w); err != nil {
+		t.Fatal(err)
+	}
+	errs = r.Errs()
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	if errs[0].Line != 6 || !strings.Contains(errs[0].Message, `property "a" is not defined`) {
+		t.Fatal("unexpected error:", errs[0])
+	}
+
+	e, _, err := r.exprs.parse(" github.sha }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.checks) != 1 {
+		t.Fatal("result of the check should be cached:", e.checks)
+	}
+}
@@ -0,0 +1,579 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// exprFilteredArray is an array created by object filter like `foo.*`. Property dereference on it
+// is applied to each element.
+// https://docs.github.com/en/actions/learn-github-actions/expressions#object-filters
+type exprFilteredArray []any
+
+// ExprEvaluator evaluates syntax trees of expressions with values of contexts. Values are the same
+// as values decoded by json.Unmarshal: nil, bool, float64, string, []any, and map[string]any. The
+// semantics follows GitHub Actions. For example, values are compared loosely and property access
+// is case-insensitive.
+// https://docs.github.com/en/actions/learn-github-actions/expressions
+type ExprEvaluator struct {
+	contexts map[string]any
+}
+
+// NewExprEvaluator creates a new ExprEvaluator instance. The contexts parameter is a map from context
+// names like "github" to their values. Contexts which are not in the map are evaluated as null.
+// The "status" property of "job" context is used by status check functions like success(). When it
+// is not given, the job is considered successful.
+func NewExprEvaluator(contexts map[string]any) *ExprEvaluator {
+	cs := make(map[string]any, len(contexts))
+	for n, v := range contexts {
+		cs[strings.ToLower(n)] = v
+	}
+	return &ExprEvaluator{cs}
+}
+
+// Eval evaluates the expression syntax tree and returns its value.
+func (ev *ExprEvaluator) Eval(n ExprNode) (any, *ExprError) {
+	switch n := n.(type) {
+	case *VariableNode:
+		return ev.contexts[strings.ToLower(n.Name)], nil
+	case *NullNode:
+		return nil, nil
+	case *BoolNode:
+		return n.Value, nil
+	case *IntNode:
+		return float64(n.Value), nil
+	case *FloatNode:
+		return n.Value, nil
+	case *StringNode:
+		return n.Value, nil
+	case *ObjectDerefNode:
+		v, err := ev.Eval(n.Receiver)
+		if err != nil {
+			return nil, err
+		}
+		return evalPropertyDeref(v, n.Property), nil
+	case *ArrayDerefNode:
+		v, err := ev.Eval(n.Receiver)
+		if err != nil {
+			return nil, err
+		}
+		return evalArrayDeref(v), nil
+	case *IndexAccessNode:
+		return ev.evalIndexAccess(n)
+	case *NotOpNode:
+		v, err := ev.Eval(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return !evalTruthy(v), nil
+	case *CompareOpNode:
+		l, err := ev.Eval(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		r, err := ev.Eval(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return evalCompare(n.Kind, l, r), nil
+	case *LogicalOpNode:
+		l, err := ev.Eval(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		// && and || return one of the operands like JavaScript
+		if t := evalTruthy(l); n.Kind == LogicalOpNodeKindAnd && !t || n.Kind == LogicalOpNodeKindOr && t {
+			return l, nil
+		}
+		return ev.Eval(n.Right)
+	case *FuncCallNode:
+		return ev.evalFuncCall(n)
+	default:
+		return nil, errorfAtExpr(n, "cannot evaluate expression node %T", n)
+	}
+}
+
+func (ev *ExprEvaluator) evalIndexAccess(n *IndexAccessNode) (any, *ExprError) {
+	v, err := ev.Eval(n.Operand)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := ev.Eval(n.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	var elems []any
+	switch v := v.(type) {
+	case map[string]any:
+		if s, ok := idx.(string); ok {
+			return evalPropertyDeref(v, s), nil
+		}
+		return nil, nil
+	case []any:
+		elems = v
+	case exprFilteredArray:
+		elems = v
+	default:
+		return nil, nil
+	}
+	f, ok := idx.(float64)
+	if !ok {
+		return nil, nil
+	}
+	i := int(math.Floor(f))
+	if i < 0 || len(elems) <= i {
+		return nil, nil
+	}
+	return elems[i], nil
+}
+
+func evalPropertyDeref(v any, prop string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if p, ok := v[prop]; ok {
+			return p
+		}
+		for k, p := range v {
+			if strings.EqualFold(k, prop) {
+				return p
+			}
+		}
+		return nil
+	case exprFilteredArray:
+		ret := exprFilteredArray{}
+		for _, e := range v {
+			if o, ok := e.(map[string]any); ok {
+				if p := evalPropertyDeref(o, prop); p != nil {
+					ret = append(ret, p)
+				}
+			}
+		}
+		return ret
+	default:
+		return nil
+	}
+}
+
+func evalArrayDeref(v any) any {
+	switch v := v.(type) {
+	case []any:
+		ret := make(exprFilteredArray, len(v))
+		copy(ret, v)
+		return ret
+	case exprFilteredArray:
+		return v
+	case map[string]any:
+		ks := make([]string, 0, len(v))
+		for k := range v {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		ret := make(exprFilteredArray, 0, len(v))
+		for _, k := range ks {
+			ret = append(ret, v[k])
+		}
+		return ret
+	default:
+		return exprFilteredArray{}
+	}
+}
+
+func (ev *ExprEvaluator) evalFuncCall(n *FuncCallNode) (any, *ExprError) {
+	args := make([]any, 0, len(n.Args))
+	for _, a := range n.Args {
+		v, err := ev.Eval(a)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	// The syntax tree may not be checked by the semantics checker
+	name := strings.ToLower(n.Callee)
+	if sigs, ok := BuiltinFuncSignatures[name]; ok {
+		required := len(sigs[len(sigs)-1].Params)
+		if sigs[0].VariableLengthParams {
+			required--
+		}
+		if len(args) < required {
+			return nil, errorfAtExpr(n, "function %q takes at least %d arguments but %d arguments are given", n.Callee, required, len(args))
+		}
+	}
+
+	switch name {
+	case "contains":
+		switch s := args[0].(type) {
+		case []any:
+			return evalContains(s, args[1]), nil
+		case exprFilteredArray:
+			return evalContains(s, args[1]), nil
+		default:
+			return strings.Contains(strings.ToLower(evalString(s)), strings.ToLower(evalString(args[1]))), nil
+		}
+	case "startswith":
+		return strings.HasPrefix(strings.ToLower(evalString(args[0])), strings.ToLower(evalString(args[1]))), nil
+	case "endswith":
+		return strings.HasSuffix(strings.ToLower(evalString(args[0])), strings.ToLower(evalString(args[1]))), nil
+	case "format":
+		s, err := evalFormat(evalString(args[0]), args[1:])
+		if err != nil {
+			return nil, errorAtExpr(n, err.Error())
+		}
+		return s, nil
+	case "join":
+		sep := ","
+		if len(args) > 1 {
+			sep = evalString(args[1])
+		}
+		var elems []any
+		switch a := args[0].(type) {
+		case []any:
+			elems = a
+		case exprFilteredArray:
+			elems = a
+		default:
+			return evalString(a), nil
+		}
+		ss := make([]string, 0, len(elems))
+		for _, e := range elems {
+			ss = append(ss, evalString(e))
+		}
+		return strings.Join(ss, sep), nil
+	case "tojson":
+		b, err := json.MarshalIndent(args[0], "", "  ")
+		if err != nil {
+			return nil, errorfAtExpr(n, "could not convert value to JSON: %s", err)
+		}
+		return string(b), nil
+	case "fromjson":
+		var v any
+		if err := json.Unmarshal([]byte(evalString(args[0])), &v); err != nil {
+			return nil, errorfAtExpr(n, "could not parse JSON string %q: %s", evalString(args[0]), err)
+		}
+		return v, nil
+	case "hashfiles":
+		return nil, errorAtExpr(n, "hashFiles() cannot be evaluated since it depends on files in the workspace of the runner")
+	case "success":
+		return ev.jobStatus() == "success", nil
+	case "failure":
+		return ev.jobStatus() == "failure", nil
+	case "cancelled":
+		return ev.jobStatus() == "cancelled", nil
+	case "always":
+		return true, nil
+	default:
+		return nil, errorfAtExpr(n, "function %q cannot be evaluated", n.Callee)
+	}
+}
+
+func (ev *ExprEvaluator) jobStatus() string {
+	if s, ok := evalPropertyDeref(ev.contexts["job"], "status").(string); ok {
+		return strings.ToLower(s)
+	}
+	return "success"
+}
+
+func evalContains(elems []any, item any) bool {
+	for _, e := range elems {
+		if evalCompare(CompareOpNodeKindEq, e, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalFormat formats the string with arguments in the same way as format() function. "{0}" is
+// replaced with the first argument and "{{" and "}}" are escapes of "{" and "}".
+func evalFormat(f string, args []any) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(f); i++ {
+		c := f[i]
+		switch {
+		case c == '{' && i+1 < len(f) && f[i+1] == '{':
+			b.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(f) && f[i+1] == '}':
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(f[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("format string %q is invalid. \"{\" is not closed", f)
+			}
+			n, err := strconv.Atoi(f[i+1 : i+end])
+			if err != nil || n < 0 {
+				return "", fmt.Errorf("format string %q is invalid. %q is not a valid placeholder", f, f[i:i+end+1])
+			}
+			if n >= len(args) {
+				return "", fmt.Errorf("format string %q refers to argument {%d} but only %d arguments are given", f, n, len(args))
+			}
+			b.WriteString(evalString(args[n]))
+			i += end
+		case c == '}':
+			return "", fmt.Errorf("format string %q is invalid. \"}\" is not escaped", f)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// evalTruthy returns the value is truthy or not. false, 0, -0, "", null, and NaN are falsy.
+func evalTruthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	default:
+		return true
+	}
+}
+
+// evalString converts the value to string in the same way as GitHub Actions.
+func evalString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN"
+		case math.IsInf(v, 1):
+			return "Infinity"
+		case math.IsInf(v, -1):
+			return "-Infinity"
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case map[string]any:
+		return "Object"
+	default:
+		return "Array"
+	}
+}
+
+// evalNumber converts the value to number for loose comparison.
+func evalNumber(v any) float64 {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0
+		}
+		if strings.HasPrefix(s, "0x") {
+			if i, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+				return float64(i)
+			}
+			return math.NaN()
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		return math.NaN()
+	default:
+		return math.NaN()
+	}
+}
+
+func evalSameInstance(l, r any) bool {
+	lv, rv := reflect.ValueOf(l), reflect.ValueOf(r)
+	if lv.Kind() != rv.Kind() || lv.Len() != rv.Len() {
+		return false
+	}
+	return lv.Pointer() == rv.Pointer()
+}
+
+// evalCompare compares the two values loosely. When types of the values are different, they are
+// converted to numbers. Strings are compared case-insensitively. Arrays and objects are equal only
+// when they are the same instance.
+func evalCompare(op CompareOpNodeKind, l, r any) bool {
+	switch lv := l.(type) {
+	case string:
+		if rv, ok := r.(string); ok {
+			return evalCompareResult(op, strings.Compare(strings.ToUpper(lv), strings.ToUpper(rv)))
+		}
+	case nil:
+		if r == nil {
+			return evalCompareResult(op, 0)
+		}
+	case bool:
+		if rv, ok := r.(bool); ok {
+			return evalCompareResult(op, evalCompareNumbers(evalNumber(lv), evalNumber(rv)))
+		}
+	case []any, exprFilteredArray, map[string]any:
+		if reflect.TypeOf(l) == reflect.TypeOf(r) && evalSameInstance(l, r) {
+			return evalCompareResult(op, 0)
+		}
+		return op == CompareOpNodeKindNotEq
+	}
+	switch r.(type) {
+	case []any, exprFilteredArray, map[string]any:
+		return op == CompareOpNodeKindNotEq
+	}
+
+	ln, rn := evalNumber(l), evalNumber(r)
+	if math.IsNaN(ln) || math.IsNaN(rn) {
+		return op == CompareOpNodeKindNotEq // NaN is not equal to any value
+	}
+	return evalCompareResult(op, evalCompareNumbers(ln, rn))
+}
+
+func evalCompareNumbers(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func evalCompareResult(op CompareOpNodeKind, c int) bool {
+	switch op {
+	case CompareOpNodeKindLess:
+		return c < 0
+	case CompareOpNodeKindLessEq:
+		return c <= 0
+	case CompareOpNodeKindGreater:
+		return c > 0
+	case CompareOpNodeKindGreaterEq:
+		return c >= 0
+	case CompareOpNodeKindEq:
+		return c == 0
+	case CompareOpNodeKindNotEq:
+		return c != 0
+	default:
+		return false
+	}
+}
+
+// evalParseAndCheck parses the expression which ends with "}}" and checks its semantics with the
+// type checker. The semantics checks catch errors like unknown functions, wrong number of arguments,
+// and access to unknown properties of contexts before evaluation. Types of contexts which depend on
+// workflows like "matrix" or "steps" are loosened when they are given since the mock values may not
+// contain all properties.
+func evalParseAndCheck(src string, contexts map[string]any) (ExprNode, int, *ExprError) {
+	l := NewExprLexer(src)
+	n, err := NewExprParser().Parse(l)
+	if err != nil {
+		return nil, 0, err
+	}
+	sema := NewExprSemanticsChecker(false, nil)
+	for name := range contexts {
+		switch name {
+		case "matrix":
+			sema.UpdateMatrix(NewEmptyObjectType())
+		case "steps":
+			sema.UpdateSteps(NewEmptyObjectType())
+		case "needs":
+			sema.UpdateNeeds(NewEmptyObjectType())
+		case "inputs":
+			sema.UpdateInputs(NewEmptyObjectType())
+		case "jobs":
+			sema.UpdateJobs(NewEmptyObjectType())
+		}
+	}
+	sp := make([]string, 0, len(SpecialFunctionNames))
+	for f := range SpecialFunctionNames {
+		sp = append(sp, f)
+	}
+	sema.SetSpecialFunctionAvailability(sp)
+	if _, errs := sema.Check(n); len(errs) > 0 {
+		return nil, 0, errs[0]
+	}
+	return n, l.Offset(), nil
+}
+
+// EvalExpression evaluates the expression source with the values of contexts and returns its value.
+// The source can be an expression like "github.ref == 'refs/heads/main'" or a string containing
+// ${{ }} placeholders. When the source is a single ${{ }} placeholder, the value of the expression
+// is returned as-is. When the source contains other text, values of the placeholders are converted
+// to strings and the interpolated string is returned. The expression is checked by the same
+// semantics checker as the "expression" rule before evaluation. The values of contexts are the same
+// as ExprEvaluator.
+func EvalExpression(src string, contexts map[string]any) (any, error) {
+	ev := NewExprEvaluator(contexts)
+	src = strings.TrimSpace(src)
+	if !strings.Contains(src, "${{") {
+		n, _, err := evalParseAndCheck(src+"}}", ev.contexts)
+		if err != nil {
+			return nil, err
+		}
+		v, err := ev.Eval(n)
+		if err != nil {
+			return nil, err
+		}
+		return evalResult(v), nil
+	}
+
+	var b strings.Builder
+	var single any
+	count := 0
+	t := src
+	for {
+		i := strings.Index(t, "${{")
+		if i < 0 {
+			b.WriteString(t)
+			break
+		}
+		b.WriteString(t[:i])
+		t = t[i+len("${{"):]
+		base := len(src) - len(t)
+		n, end, err := evalParseAndCheck(t, ev.contexts)
+		if err == nil {
+			var v any
+			v, err = ev.Eval(n)
+			single = v
+		}
+		if err != nil {
+			// Make the position relative to the entire source
+			if err.Line == 1 {
+				err.Column += base
+			}
+			err.Offset += base
+			return nil, err
+		}
+		b.WriteString(evalString(single))
+		count++
+		t = t[end:]
+	}
+	if count == 1 && strings.HasPrefix(src, "${{") && strings.HasSuffix(src, "}}") {
+		return evalResult(single), nil
+	}
+	return b.String(), nil
+}
+
+// evalResult converts the evaluated value into the value which can be passed to callers.
+func evalResult(v any) any {
+	if a, ok := v.(exprFilteredArray); ok {
+		return []any(a)
+	}
+	return v
+}
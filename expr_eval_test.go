@@ -0,0 +1,202 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testEvalContexts() map[string]any {
+	var ctx map[string]any
+	src := `{
+		"github": {
+			"event_name": "push",
+			"ref": "refs/tags/v1.2.3",
+			"event": {
+				"commits": [
+					{"message": "first", "author": {"name": "foo"}},
+					{"message": "second", "author": {"name": "bar"}}
+				]
+			}
+		},
+		"Matrix": {"os": "ubuntu-latest", "node": 18},
+		"steps": {"build": {"outcome": "success", "outputs": {"ver": "1.2.3"}}},
+		"job": {"status": "failure"}
+	}`
+	if err := json.Unmarshal([]byte(src), &ctx); err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func TestEvalExpressionOK(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  any
+	}{
+		// Literals
+		{"null", nil},
+		{"true", true},
+		{"42", 42.0},
+		{"-1.5", -1.5},
+		{"0xff", 255.0},
+		{"'it''s'", "it's"},
+		// Contexts and properties
+		{"github.event_name", "push"},
+		{"GITHUB.Event_Name", "push"},
+		{"matrix.node", 18.0},
+		{"matrix['os']", "ubuntu-latest"},
+		{"github.event.commits[1].message", "second"},
+		{"github.event.commits.*.author.name", []any{"foo", "bar"}},
+		{"github.event.commits[5]", nil},
+		{"env", nil},
+		{"steps.build.outputs.ver", "1.2.3"},
+		// Operators
+		{"github.event_name == 'PUSH'", true},
+		{"matrix.node == '18'", true},
+		{"matrix.node > 16 && matrix.node < 20", true},
+		{"'' == 0", true},
+		{"null == 0", true},
+		{"null == false", true},
+		{"'abc' < 'ABD'", true},
+		{"github.event == github.event", true},
+		{"!github.event_name", false},
+		{"!''", true},
+		{"github.event_name == 'pull_request' || 'default'", "default"},
+		{"matrix.os && matrix.node", 18.0},
+		{"'' && 'foo'", ""},
+		// Functions
+		{"contains(github.ref, 'TAGS')", true},
+		{"contains(github.event.commits.*.message, 'second')", true},
+		{"contains(fromJSON('[1, 2]'), 2)", true},
+		{"startsWith(github.ref, 'refs/tags/')", true},
+		{"endsWith(github.ref, '.4')", false},
+		{"format('{0}-{1} {{0}}', matrix.os, matrix.node)", "ubuntu-latest-18 {0}"},
+		{"join(github.event.commits.*.message, ', ')", "first, second"},
+		{"toJSON(matrix.node)", "18"},
+		{"toJSON(fromJSON('{\"a\": [true]}'))", "{\n  \"a\": [\n    true\n  ]\n}"},
+		{"fromJSON('{\"a\": 1}').a", 1.0},
+		{"success()", false},
+		{"failure()", true},
+		{"cancelled()", false},
+		{"always()", true},
+		// Interpolation
+		{"${{ matrix.node }}", 18.0},
+		{"  ${{ github.event.commits.*.message }}  ", []any{"first", "second"}},
+		{"node-${{ matrix.node }} on ${{ matrix.os }}", "node-18 on ubuntu-latest"},
+		{"${{ 1 }}${{ 2 }}", "12"},
+		{"${{ null }} and ${{ true }} and ${{ 1.5 }}", " and true and 1.5"},
+		{"${{ github.event }}", testEvalContexts()["github"].(map[string]any)["event"]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			have, err := EvalExpression(tc.input, testEvalContexts())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestEvalExpressionStatusFunctionsWithoutJobStatus(t *testing.T) {
+	for input, want := range map[string]bool{
+		"success()":   true,
+		"failure()":   false,
+		"cancelled()": false,
+	} {
+		have, err := EvalExpression(input, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != want {
+			t.Errorf("%s was evaluated as %v but wanted %v", input, have, want)
+		}
+	}
+}
+
+func TestEvalExpressionError(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+		col   int
+	}{
+		{"github.event_name ==", "unexpected end of input", 21},
+		{"unknown_func(1)", "undefined function \"unknown_func\"", 1},
+		{"contains('foo')", "number of arguments is wrong", 1},
+		{"hashFiles('**/go.sum')", "hashFiles() cannot be evaluated", 1},
+		{"format('{0} {1}', 1)", "contains placeholder {1} but only 1 arguments are given", 1},
+		{"fromJSON('{')", "broken JSON string is passed to fromJSON()", 10},
+		{"foo ${{ github.event_name == }}", "unexpected", 30},
+		{"foo ${{ 1 }} ${{ hashFiles('x') }}", "hashFiles() cannot be evaluated", 18},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := EvalExpression(tc.input, testEvalContexts())
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			e, ok := err.(*ExprError)
+			if !ok {
+				t.Fatalf("error is not ExprError: %v", err)
+			}
+			if !strings.Contains(e.Message, tc.want) {
+				t.Fatalf("%q is not included in error message %q", tc.want, e.Message)
+			}
+			if e.Column != tc.col {
+				t.Fatalf("wanted column %d but got %d: %v", tc.col, e.Column, e)
+			}
+		})
+	}
+}
+
+func TestCommandEval(t *testing.T) {
+	dir := t.TempDir()
+	ctx := filepath.Join(dir, "ctx.json")
+	if err := os.WriteFile(ctx, []byte(`{"github": {"ref": "refs/heads/main"}}`), 0644); err != nil {
+		panic(err)
+	}
+
+	testCases := []struct {
+		what   string
+		args   []string
+		stdin  string
+		status int
+		want   string
+	}{
+		{"file", []string{"-contexts", ctx, "github.ref == 'refs/heads/main'"}, "", 0, "true\n"},
+		{"stdin", []string{"-contexts", "-", "${{ matrix }}"}, `{"matrix": {"os": ["linux"]}}`, 0, "{\n  \"os\": [\n    \"linux\"\n  ]\n}\n"},
+		{"no contexts", []string{"format('{0}!', github.ref)"}, "", 0, "\"!\"\n"},
+		{"no expression", []string{"-contexts", ctx}, "", ExitStatusInvalidCommandOption, "exactly one expression must be given"},
+		{"broken JSON", []string{"-contexts", "-", "true"}, "[", ExitStatusFailure, "could not parse contexts"},
+		{"missing file", []string{"-contexts", filepath.Join(dir, "missing.json"), "true"}, "", ExitStatusFailure, "could not read contexts"},
+		{"bad expression", []string{"1 +"}, "", ExitStatusFailure, "could not evaluate expression at column 3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := Command{Stdin: strings.NewReader(tc.stdin), Stdout: &stdout, Stderr: &stderr}
+			status := cmd.Main(append([]string{"actionlint", "eval"}, tc.args...))
+			if status != tc.status {
+				t.Fatalf("wanted exit status %d but got %d: %s", tc.status, status, stderr.String())
+			}
+			if tc.status == 0 {
+				if have := stdout.String(); have != tc.want {
+					t.Fatalf("wanted output %q but got %q", tc.want, have)
+				}
+			} else if !strings.Contains(stderr.String(), tc.want) {
+				t.Fatalf("%q is not included in stderr %q", tc.want, stderr.String())
+			}
+		})
+	}
+}
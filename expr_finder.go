@@ -0,0 +1,245 @@
+package actionlint
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExpressionKind is a kind of expression found in a workflow by FindAllExpressions.
+type ExpressionKind string
+
+const (
+	// ExpressionKindPlaceholder is an expression embedded in a string with ${{ }} placeholder.
+	ExpressionKindPlaceholder ExpressionKind = "placeholder"
+	// ExpressionKindCondition is a bare expression at "if:" without ${{ }}. GitHub evaluates the
+	// whole value as an expression.
+	ExpressionKindCondition ExpressionKind = "condition"
+)
+
+// FoundExpression is an expression found in a workflow by FindAllExpressions.
+type FoundExpression struct {
+	// Kind is a kind of the expression.
+	Kind ExpressionKind
+	// Raw is the expression as written in the workflow. For ExpressionKindPlaceholder, it contains
+	// ${{ and }}.
+	Raw string
+	// Source is the source of the expression without ${{ and }} and surrounding spaces.
+	Source string
+	// Pos is the start position of the expression. For ExpressionKindPlaceholder, it is the
+	// position of "${{".
+	Pos *Pos
+	// End is the position next to the end of the expression.
+	End *Pos
+}
+
+// FindAllExpressions finds all expressions in the workflow source with the same extraction rules as
+// actionlint checks them. The end of each ${{ }} is found by the expression lexer so "}}" in string
+// literals like ${{ format('}}') }} does not end the placeholder. Bare conditions at "if:" are also
+// returned. The returned expressions are sorted by their positions.
+//
+// Positions of expressions in literal block scalars (|) are precise. Positions of expressions after
+// the first line of other multi-line strings are the start position of the strings since the lines
+// are folded or escaped in their values.
+//
+// Syntax errors of the workflow are returned as the second return value. Expressions in the
+// partially parsed workflow are returned even if it has syntax errors.
+func FindAllExpressions(src []byte) ([]*FoundExpression, []*Error) {
+	w, errs := Parse(src)
+	if w == nil {
+		return nil, errs
+	}
+
+	f := &exprFinder{sourceLines: splitLines(string(src))}
+	f.walk(reflect.ValueOf(w), "")
+
+	sort.SliceStable(f.found, func(i, j int) bool {
+		return f.found[i].Pos.IsBefore(f.found[j].Pos)
+	})
+
+	// The same expression is found twice when its string is merged with YAML merge key "<<"
+	ret := make([]*FoundExpression, 0, len(f.found))
+	for _, e := range f.found {
+		if len(ret) > 0 {
+			p := ret[len(ret)-1]
+			if *p.Pos == *e.Pos && p.Raw == e.Raw {
+				continue
+			}
+		}
+		ret = append(ret, e)
+	}
+	return ret, errs
+}
+
+type exprFinder struct {
+	sourceLines
+	found []*FoundExpression
+}
+
+// walk visits all strings in the syntax tree. The field is a name of the struct field which has the
+// value.
+func (f *exprFinder) walk(v reflect.Value, field string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		switch n := v.Interface().(type) {
+		case *String:
+			f.string(n.Value, n.Pos, n.Quoted, field == "If")
+			return
+		case *RawYAMLString:
+			if p := n.Pos(); p != nil {
+				c := f.charAt(f.valueStart(p))
+				f.string(n.Value, p, c == '\'' || c == '"', false)
+			}
+			return
+		case *Pos:
+			return
+		}
+		f.walk(v.Elem(), field)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" || sf.Name == "Aliases" {
+				continue // Unexported fields and names of YAML anchors
+			}
+			f.walk(v.Field(i), sf.Name)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			f.walk(v.Index(i), field)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			f.walk(iter.Value(), field)
+		}
+	}
+}
+
+func (f *exprFinder) string(s string, pos *Pos, quoted, cond bool) {
+	if pos == nil {
+		return
+	}
+	pos = f.valueStart(pos)
+	if pos == nil {
+		return // Expressions in the value referred with YAML alias are found at its anchor
+	}
+
+	if !ContainsExpression(s) {
+		if cond && strings.TrimSpace(s) != "" {
+			f.found = append(f.found, &FoundExpression{
+				Kind:   ExpressionKindCondition,
+				Raw:    s,
+				Source: strings.TrimSpace(s),
+				Pos:    f.pos(s, pos, quoted, 0),
+				End:    f.pos(s, pos, quoted, len(s)),
+			})
+		}
+		return
+	}
+
+	offset := 0
+	for {
+		i := strings.Index(s[offset:], "${{")
+		if i < 0 {
+			return
+		}
+		start := offset + i
+		_, n, err := LexExpression(s[start+3:])
+		if err != nil {
+			// Fall back to the first "}}" when the expression has a syntax error
+			n = strings.Index(s[start+3:], "}}")
+			if n < 0 {
+				return // Unterminated ${{ is not evaluated
+			}
+			n += 2
+		}
+		end := start + 3 + n
+		raw := s[start:end]
+		f.found = append(f.found, &FoundExpression{
+			Kind:   ExpressionKindPlaceholder,
+			Raw:    raw,
+			Source: strings.TrimSpace(raw[3 : len(raw)-2]),
+			Pos:    f.pos(s, pos, quoted, start),
+			End:    f.pos(s, pos, quoted, end),
+		})
+		offset = end
+	}
+}
+
+// pos returns the position of the byte offset in the string value at the position.
+func (f *exprFinder) pos(s string, pos *Pos, quoted bool, offset int) *Pos {
+	v := s[:offset]
+	nl := strings.LastIndexByte(v, '\n')
+	block := f.charAt(pos)
+	if nl < 0 && block != '|' && block != '>' {
+		col := pos.Col
+		if quoted {
+			col++
+		}
+		return &Pos{Line: pos.Line, Col: col + utf8.RuneCountInString(v)}
+	}
+	if block != '|' {
+		return &Pos{Line: pos.Line, Col: pos.Col}
+	}
+	// Lines of literal block scalar are the same as lines in the source
+	return &Pos{Line: pos.Line + 1 + strings.Count(v, "\n"), Col: f.blockIndent(pos.Line) + 1 + utf8.RuneCountInString(v[nl+1:])}
+}
+
+// sourceLines is lines of a workflow source to look up the characters at positions of nodes.
+type sourceLines []string
+
+// valueStart returns the start position of the value at the position skipping YAML anchor like
+// "&name". It returns nil when the value is YAML alias like "*name".
+func (ls sourceLines) valueStart(pos *Pos) *Pos {
+	switch ls.charAt(pos) {
+	case '&':
+		col, anchor := 1, true
+		for _, r := range ls[pos.Line-1] {
+			if col > pos.Col {
+				if anchor && r == ' ' {
+					anchor = false
+				}
+				if !anchor && r != ' ' {
+					break
+				}
+			}
+			col++
+		}
+		return &Pos{Line: pos.Line, Col: col}
+	case '*':
+		return nil
+	default:
+		return pos
+	}
+}
+
+// charAt returns the character at the position in the source. It returns 0 when the position is
+// out of the source.
+func (ls sourceLines) charAt(pos *Pos) rune {
+	if pos == nil || pos.Line <= 0 || pos.Line > len(ls) {
+		return 0
+	}
+	col := 1
+	for _, r := range ls[pos.Line-1] {
+		if col == pos.Col {
+			return r
+		}
+		col++
+	}
+	return 0
+}
+
+// blockIndent returns the indentation of the content of the block scalar starting at the line.
+func (ls sourceLines) blockIndent(line int) int {
+	for _, l := range ls[line:] {
+		if t := strings.TrimLeft(l, " "); t != "" {
+			return len(l) - len(t)
+		}
+	}
+	return 0
+}
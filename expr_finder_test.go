@@ -0,0 +1,89 @@
+package actionlint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindAllExpressions(t *testing.T) {
+	src := `on: push
+env:
+  FOO: ${{ github.sha }}-${{ format('}}') }}
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    if: github.event_name == 'push'
+    strategy:
+      matrix:
+        os: [ubuntu-latest, '${{ fromJSON(x) }}']
+    steps:
+      - run: |
+          echo hello
+            echo ${{ secrets.TOKEN }}
+      - run: "echo '${{ env.FOO }}'"
+        if: ${{ always() }}
+      - uses: actions/checkout@${{ env.REF }}
+        with:
+          token: ${{ ! }} ${{ unterminated
+`
+	want := []*FoundExpression{
+		{ExpressionKindPlaceholder, "${{ github.sha }}", "github.sha", &Pos{Line: 3, Col: 8}, &Pos{Line: 3, Col: 25}},
+		{ExpressionKindPlaceholder, "${{ format('}}') }}", "format('}}')", &Pos{Line: 3, Col: 26}, &Pos{Line: 3, Col: 45}},
+		{ExpressionKindPlaceholder, "${{ matrix.os }}", "matrix.os", &Pos{Line: 6, Col: 14}, &Pos{Line: 6, Col: 30}},
+		{ExpressionKindCondition, "github.event_name == 'push'", "github.event_name == 'push'", &Pos{Line: 7, Col: 9}, &Pos{Line: 7, Col: 36}},
+		{ExpressionKindPlaceholder, "${{ fromJSON(x) }}", "fromJSON(x)", &Pos{Line: 10, Col: 30}, &Pos{Line: 10, Col: 48}},
+		{ExpressionKindPlaceholder, "${{ secrets.TOKEN }}", "secrets.TOKEN", &Pos{Line: 14, Col: 18}, &Pos{Line: 14, Col: 38}},
+		{ExpressionKindPlaceholder, "${{ env.FOO }}", "env.FOO", &Pos{Line: 15, Col: 21}, &Pos{Line: 15, Col: 35}},
+		{ExpressionKindPlaceholder, "${{ always() }}", "always()", &Pos{Line: 16, Col: 13}, &Pos{Line: 16, Col: 28}},
+		{ExpressionKindPlaceholder, "${{ env.REF }}", "env.REF", &Pos{Line: 17, Col: 32}, &Pos{Line: 17, Col: 46}},
+		{ExpressionKindPlaceholder, "${{ ! }}", "!", &Pos{Line: 19, Col: 18}, &Pos{Line: 19, Col: 26}},
+	}
+
+	have, errs := FindAllExpressions([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal("unexpected errors:", errs)
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestFindAllExpressionsYAMLAlias(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: &run echo ${{ github.sha }}
+      - run: *run
+`
+	have, errs := FindAllExpressions([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal("unexpected errors:", errs)
+	}
+	if len(have) != 1 {
+		t.Fatalf("expression referred with alias should be found once: %v", have)
+	}
+	if p := have[0].Pos; *p != (Pos{Line: 6, Col: 24}) {
+		t.Fatalf("position should skip the anchor: %s", p)
+	}
+}
+
+func TestFindAllExpressionsSyntaxError(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    foo: bar
+    steps:
+      - run: echo ${{ github.sha }}
+`
+	have, errs := FindAllExpressions([]byte(src))
+	if len(errs) == 0 {
+		t.Fatal("syntax error was not reported")
+	}
+	if len(have) != 1 || have[0].Source != "github.sha" {
+		t.Fatalf("expressions in the partially parsed workflow should be found: %v", have)
+	}
+}
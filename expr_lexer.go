@@ -174,19 +174,22 @@ func NewExprLexer(src string) *ExprLexer {
 	}
 	l.scan.Init(strings.NewReader(src))
 	l.scan.Error = func(_ *scanner.Scanner, m string) {
-		l.error(fmt.Sprintf("scan error while lexing expression: %s", m))
+		l.errorf("scan error while lexing expression: %s", m)
 	}
 	return l
 }
 
-func (lex *ExprLexer) error(msg string) {
+func (lex *ExprLexer) errorf(format string, args ...interface{}) {
 	if lex.lexErr == nil {
 		p := lex.scan.Pos()
 		lex.lexErr = &ExprError{
-			Message: msg,
-			Offset:  p.Offset,
-			Line:    p.Line,
-			Column:  p.Column,
+			Message:   fmt.Sprintf(format, args...),
+			Offset:    p.Offset,
+			Line:      p.Line,
+			Column:    p.Column,
+			MessageID: messageIDOf(format),
+			format:    format,
+			args:      args,
 		}
 	}
 }
@@ -243,20 +246,18 @@ func (lex *ExprLexer) unexpected(r rune, where string, expected string) *Token {
 		note = ". do you mean string literals? only single quotes are available for string delimiter"
 	}
 
-	msg := fmt.Sprintf(
+	lex.errorf(
 		"got unexpected %s while lexing %s, expecting %s%s",
 		what,
 		where,
 		expected,
 		note,
 	)
-
-	lex.error(msg)
 	return lex.eof()
 }
 
 func (lex *ExprLexer) unexpectedEOF() *Token {
-	lex.error("unexpected EOF while lexing expression")
+	lex.errorf("unexpected EOF while lexing expression")
 	return lex.eof()
 }
 
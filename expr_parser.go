@@ -8,13 +8,23 @@ import (
 
 func errorAtToken(t *Token, msg string) *ExprError {
 	return &ExprError{
-		Message: msg,
-		Offset:  t.Offset,
-		Line:    t.Line,
-		Column:  t.Column,
+		Message:   msg,
+		Offset:    t.Offset,
+		Line:      t.Line,
+		Column:    t.Column,
+		MessageID: messageIDOf(msg),
+		format:    msg,
 	}
 }
 
+func errorfAtToken(t *Token, format string, args ...interface{}) *ExprError {
+	err := errorAtToken(t, fmt.Sprintf(format, args...))
+	err.MessageID = messageIDOf(format)
+	err.format = format
+	err.args = args
+	return err
+}
+
 // ExprParser is a parser for expression syntax. To know the details, see
 // https://docs.github.com/en/actions/learn-github-actions/expressions
 type ExprParser struct {
@@ -28,16 +38,12 @@ func NewExprParser() *ExprParser {
 	return &ExprParser{}
 }
 
-func (p *ExprParser) error(msg string) {
+func (p *ExprParser) errorf(format string, args ...interface{}) {
 	if p.err == nil {
-		p.err = errorAtToken(p.cur, msg)
+		p.err = errorfAtToken(p.cur, format, args...)
 	}
 }
 
-func (p *ExprParser) errorf(format string, args ...interface{}) {
-	p.error(fmt.Sprintf(format, args...))
-}
-
 func (p *ExprParser) unexpected(where string, expected []TokenKind) {
 	if p.err != nil {
 		return
@@ -52,8 +58,7 @@ func (p *ExprParser) unexpected(where string, expected []TokenKind) {
 	} else {
 		what = fmt.Sprintf("token %q", p.cur.Kind.String())
 	}
-	msg := fmt.Sprintf("unexpected %s while parsing %s. expecting %s", what, where, qb.build())
-	p.error(msg)
+	p.errorf("unexpected %s while parsing %s. expecting %s", what, where, qb.build())
 }
 
 func (p *ExprParser) next() *Token {
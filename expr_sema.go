@@ -311,6 +311,9 @@ type ExprSemanticsChecker struct {
 	availableContexts     []string
 	availableSpecialFuncs []string
 	configVars            []string
+	events                []string
+	runnerOSes            []string
+	otherMatrixKeys       map[string][]string
 }
 
 // NewExprSemanticsChecker creates new ExprSemanticsChecker instance. When checkUntrustedInput is
@@ -332,15 +335,21 @@ func NewExprSemanticsChecker(checkUntrustedInput bool, configVars []string) *Exp
 func errorAtExpr(e ExprNode, msg string) *ExprError {
 	t := e.Token()
 	return &ExprError{
-		Message: msg,
-		Offset:  t.Offset,
-		Line:    t.Line,
-		Column:  t.Column,
+		Message:   msg,
+		Offset:    t.Offset,
+		Line:      t.Line,
+		Column:    t.Column,
+		MessageID: messageIDOf(msg),
+		format:    msg,
 	}
 }
 
 func errorfAtExpr(e ExprNode, format string, args ...interface{}) *ExprError {
-	return errorAtExpr(e, fmt.Sprintf(format, args...))
+	err := errorAtExpr(e, fmt.Sprintf(format, args...))
+	err.MessageID = messageIDOf(format)
+	err.format = format
+	err.args = args
+	return err
 }
 
 func (sema *ExprSemanticsChecker) errorf(e ExprNode, format string, args ...interface{}) {
@@ -389,6 +398,14 @@ func (sema *ExprSemanticsChecker) UpdateNeeds(ty *ObjectType) {
 	sema.vars["needs"] = ty
 }
 
+// UpdateJob updates 'job' context object to given object type. Since properties of services and
+// container depend on 'services' and 'container' sections of job configuration, the type needs to
+// be updated.
+func (sema *ExprSemanticsChecker) UpdateJob(ty *ObjectType) {
+	sema.ensureVarsCopied()
+	sema.vars["job"] = ty
+}
+
 // UpdateSecrets updates 'secrets' context object to given object type.
 func (sema *ExprSemanticsChecker) UpdateSecrets(ty *ObjectType) {
 	sema.ensureVarsCopied()
@@ -438,6 +455,14 @@ func (sema *ExprSemanticsChecker) UpdateDispatchInputs(ty *ObjectType) {
 	sema.vars["github"].(*ObjectType).Props["event"].(*ObjectType).Props["inputs"] = ty
 }
 
+// UpdateClientPayload updates 'github.event.client_payload' object to given type. The payload is
+// sent with repository_dispatch event.
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch
+func (sema *ExprSemanticsChecker) UpdateClientPayload(ty ExprType) {
+	sema.ensureGithubVarCopied()
+	sema.vars["github"].(*ObjectType).Props["event"].(*ObjectType).Props["client_payload"] = ty
+}
+
 // UpdateJobs updates 'jobs' context object to given object type.
 func (sema *ExprSemanticsChecker) UpdateJobs(ty *ObjectType) {
 	sema.ensureVarsCopied()
@@ -482,6 +507,34 @@ func (sema *ExprSemanticsChecker) checkAvailableContext(n *VariableNode) {
 	)
 }
 
+// SetWorkflowEvents sets names of events which trigger the workflow. They are used for checking
+// comparisons with github.event_name like `github.event_name == 'push'`.
+//
+// Elements of 'events' parameter must be in lower case.
+//
+// If this method is not called before checks, ExprSemanticsChecker considers any events can trigger
+// the workflow.
+func (sema *ExprSemanticsChecker) SetWorkflowEvents(events []string) {
+	sema.events = events
+}
+
+// SetRunnerOSes sets possible values of runner.os in the job like "Linux". They are used for
+// checking comparisons with runner.os like `runner.os == 'Windows'`.
+//
+// If this method is not called before checks, ExprSemanticsChecker considers the job can run on any
+// OS.
+func (sema *ExprSemanticsChecker) SetRunnerOSes(oses []string) {
+	sema.runnerOSes = oses
+}
+
+// SetMatrixKeysOfOtherJobs sets keys of matrix values defined in other jobs of the workflow. The
+// 'keys' parameter is a mapping from the keys in lower case to IDs of the jobs defining them. They
+// are used for better error messages on accessing matrix values defined in other jobs since
+// `matrix` context only contains the values of the current job.
+func (sema *ExprSemanticsChecker) SetMatrixKeysOfOtherJobs(keys map[string][]string) {
+	sema.otherMatrixKeys = keys
+}
+
 // SetSpecialFunctionAvailability sets names of available special functions while semantics checks.
 // Some functions limit where they can be used.
 // https://docs.github.com/en/actions/learn-github-actions/contexts#context-availability
@@ -562,6 +615,23 @@ func (sema *ExprSemanticsChecker) checkObjectDeref(n *ObjectDerefNode) ExprType
 			return ty.Mapped
 		}
 		if ty.IsStrict() {
+			if v, ok := n.Receiver.(*VariableNode); ok && v.Name == "matrix" {
+				if jobs, ok := sema.otherMatrixKeys[n.Property]; ok {
+					s := ""
+					if len(jobs) > 1 {
+						s = "s"
+					}
+					sema.errorf(
+						n,
+						"property %q is not defined in object type %s. it is defined in matrix of other job%s %s but \"matrix\" context only contains values in \"strategy.matrix\" of the current job",
+						n.Property,
+						ty.String(),
+						s,
+						quotes(jobs),
+					)
+					return AnyType{}
+				}
+			}
 			sema.errorf(n, "property %q is not defined in object type %s", n.Property, ty.String())
 		}
 		return AnyType{}
@@ -702,11 +772,12 @@ func (sema *ExprSemanticsChecker) checkIndexAccess(n *IndexAccessNode) ExprType
 
 	switch ty := sema.check(n.Operand).(type) {
 	case AnyType:
+		sema.checkNegativeIndex(n)
 		return AnyType{}
 	case *ArrayType:
 		switch idx.(type) {
 		case AnyType, NumberType:
-			return ty.Elem
+			return sema.checkArrayIndex(n, ty)
 		default:
 			sema.errorf(n.Index, "index access of array must be type of number but got %q", idx.String())
 			return AnyType{}
@@ -742,6 +813,102 @@ func (sema *ExprSemanticsChecker) checkIndexAccess(n *IndexAccessNode) ExprType
 	}
 }
 
+// checkArrayIndex checks the constant index of array access. When the array is a constant value
+// like fromJSON('[1, "foo"]'), the index is checked with its length and the element type is
+// precisely determined.
+func (sema *ExprSemanticsChecker) checkArrayIndex(n *IndexAccessNode, ty *ArrayType) ExprType {
+	if sema.checkNegativeIndex(n) {
+		return AnyType{}
+	}
+	lit, ok := n.Index.(*IntNode)
+	if !ok {
+		return ty.Elem
+	}
+
+	v, ok := constantJSONValue(n.Operand)
+	if !ok {
+		return ty.Elem
+	}
+	a, ok := v.([]any)
+	if !ok {
+		return ty.Elem
+	}
+	if lit.Value >= len(a) {
+		sema.errorf(n.Index, "index %d is out of bounds of array with %s. it is always evaluated to null", lit.Value, pluralize(len(a), "element"))
+		return AnyType{}
+	}
+	return typeOfJSONValue(a[lit.Value])
+}
+
+// checkNegativeIndex reports a negative constant index like foo[-1]. Unlike some languages, it
+// does not access elements from the end of array. It returns true when an error was reported.
+func (sema *ExprSemanticsChecker) checkNegativeIndex(n *IndexAccessNode) bool {
+	lit, ok := n.Index.(*IntNode)
+	if !ok || lit.Value >= 0 {
+		return false
+	}
+	sema.errorf(n.Index, "index %d of array access is negative. it does not access elements from the end of array and is always evaluated to null", lit.Value)
+	return true
+}
+
+// constantJSONValue returns the value of the expression when it is statically known. It is the
+// result of fromJSON() call with string literal argument, or a property or an element of it.
+func constantJSONValue(n ExprNode) (any, bool) {
+	switch n := n.(type) {
+	case *FuncCallNode:
+		if !strings.EqualFold(n.Callee, "fromJSON") || len(n.Args) != 1 {
+			return nil, false
+		}
+		lit, ok := n.Args[0].(*StringNode)
+		if !ok {
+			return nil, false
+		}
+		var v any
+		if err := json.Unmarshal([]byte(lit.Value), &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	case *ObjectDerefNode:
+		v, ok := constantJSONValue(n.Receiver)
+		if !ok {
+			return nil, false
+		}
+		o, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		// Property access is case-insensitive
+		for k, v := range o {
+			if strings.EqualFold(k, n.Property) {
+				return v, true
+			}
+		}
+		return nil, false
+	case *IndexAccessNode:
+		v, ok := constantJSONValue(n.Operand)
+		if !ok {
+			return nil, false
+		}
+		switch v := v.(type) {
+		case []any:
+			if i, ok := n.Index.(*IntNode); ok && 0 <= i.Value && i.Value < len(v) {
+				return v[i.Value], true
+			}
+		case map[string]any:
+			if k, ok := n.Index.(*StringNode); ok {
+				for p, v := range v {
+					if strings.EqualFold(p, k.Value) {
+						return v, true
+					}
+				}
+			}
+		}
+		return nil, false
+	default:
+		return nil, false
+	}
+}
+
 func checkFuncSignature(n *FuncCallNode, sig *FuncSignature, args []ExprType) *ExprError {
 	lp, la := len(sig.Params), len(args)
 	if sig.VariableLengthParams && (lp > la) || !sig.VariableLengthParams && lp != la {
@@ -795,6 +962,41 @@ func checkFuncSignature(n *FuncCallNode, sig *FuncSignature, args []ExprType) *E
 	return nil
 }
 
+// checkBuiltinFuncArgsMisuse detects common mistakes on arguments of built-in function calls which
+// did not match to any overloads. It returns an error describing the mistake instead of reporting
+// the type errors for each overload.
+func checkBuiltinFuncArgsMisuse(n *FuncCallNode, args []ExprType) *ExprError {
+	switch strings.ToLower(n.Callee) {
+	case "contains":
+		if len(args) != 2 {
+			return nil
+		}
+		// contains(github.ref, fromJSON('["main", "dev"]'))
+		if _, ok := args[1].(*ArrayType); ok && (StringType{}).Assignable(args[0]) {
+			if _, ok := args[0].(AnyType); !ok {
+				return errorfAtExpr(
+					n,
+					"arguments of contains() seem to be swapped. %q value is given to the first argument and %q value is given to the second argument. contains(array, item) checks whether the array contains the item",
+					args[0].String(),
+					args[1].String(),
+				)
+			}
+		}
+	case "join":
+		if len(args) != 1 && len(args) != 2 {
+			return nil
+		}
+		if !(&ArrayType{Elem: AnyType{}}).Assignable(args[0]) {
+			return errorfAtExpr(
+				n.Args[0],
+				"first argument of join() must be an array but %q value is given. join() concatenates elements of the array with separator like join(matrix.items, ', ')",
+				args[0].String(),
+			)
+		}
+	}
+	return nil
+}
+
 func (sema *ExprSemanticsChecker) checkBuiltinFuncCall(n *FuncCallNode, sig *FuncSignature) ExprType {
 	sema.checkSpecialFunctionAvailability(n)
 
@@ -833,6 +1035,17 @@ func (sema *ExprSemanticsChecker) checkBuiltinFuncCall(n *FuncCallNode, sig *Fun
 		if s, ok := err.(*json.SyntaxError); ok {
 			sema.errorf(lit, "broken JSON string is passed to fromJSON() at offset %d: %s", s.Offset, s)
 		}
+	case "tojson":
+		if v, ok := n.Args[0].(*VariableNode); ok && strings.EqualFold(v.Name, "secrets") {
+			sema.errorf(n, "toJSON(secrets) exposes all secrets of the repository. pass only the secrets which are actually used like secrets.NAME for least privilege")
+		}
+	case "hashfiles":
+		// All patterns given as variable length arguments are checked
+		for _, a := range n.Args {
+			if lit, ok := a.(*StringNode); ok && strings.Contains(lit.Value, "${{") {
+				sema.errorf(lit, "pattern %q of hashFiles() contains \"${{\". placeholders in string literals are not evaluated. build the pattern with format() like format('{0}/**/*.lock', matrix.dir)", lit.Value)
+			}
+		}
 	}
 
 	return sig.Ret
@@ -868,6 +1081,10 @@ func (sema *ExprSemanticsChecker) checkFuncCall(n *FuncCallNode) ExprType {
 	}
 
 	// All candidates failed
+	if err := checkBuiltinFuncArgsMisuse(n, tys); err != nil {
+		sema.errs = append(sema.errs, err)
+		return AnyType{}
+	}
 	sema.errs = append(sema.errs, errs...)
 
 	return AnyType{}
@@ -941,11 +1158,142 @@ func (sema *ExprSemanticsChecker) checkCompareOp(n *CompareOpNode) ExprType {
 
 	if !validateCompareOpOperands(n.Kind, l, r) {
 		sema.errorf(n, "%q value cannot be compared to %q value with %q operator", l.String(), r.String(), n.Kind.String())
+		return BoolType{}
+	}
+
+	if n.Kind == CompareOpNodeKindEq || n.Kind == CompareOpNodeKindNotEq {
+		if lit, ok := n.Right.(*StringNode); ok {
+			sema.checkCompareWithStringLiteral(n, n.Left, l, lit)
+		} else if lit, ok := n.Left.(*StringNode); ok {
+			sema.checkCompareWithStringLiteral(n, n.Right, r, lit)
+		}
 	}
 
 	return BoolType{}
 }
 
+// isContextProp returns true when the node is property access of the context like `runner.os`.
+func isContextProp(n ExprNode, ctx, prop string) bool {
+	d, ok := n.(*ObjectDerefNode)
+	if !ok || d.Property != prop {
+		return false
+	}
+	v, ok := d.Receiver.(*VariableNode)
+	return ok && strings.EqualFold(v.Name, ctx)
+}
+
+// isGitHubContextProp returns true when the node is property access of github context like
+// `github.ref`.
+func isGitHubContextProp(n ExprNode, prop string) bool {
+	return isContextProp(n, "github", prop)
+}
+
+// runnerContextValues is a mapping from properties of runner context to all values which they can
+// take.
+// https://docs.github.com/en/actions/learn-github-actions/contexts#runner-context
+var runnerContextValues = map[string][]string{
+	"os":          {"Linux", "Windows", "macOS"},
+	"arch":        {"X86", "X64", "ARM", "ARM64"},
+	"environment": {"github-hosted", "self-hosted"},
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, e := range ss {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCompareWithStringLiteral checks pitfalls on comparing some value with string literal with ==
+// or != operator.
+func (sema *ExprSemanticsChecker) checkCompareWithStringLiteral(n *CompareOpNode, operand ExprNode, ty ExprType, lit *StringNode) {
+	result := "false"
+	if n.Kind == CompareOpNodeKindNotEq {
+		result = "true"
+	}
+
+	// Number and bool values are compared with string after converting the string to number.
+	// Strings which are not numbers are converted to NaN so the comparison result is always false.
+	// https://docs.github.com/en/actions/learn-github-actions/expressions#operators
+	switch ty.(type) {
+	case NumberType, BoolType:
+		if s := strings.TrimSpace(lit.Value); s != "" {
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				sema.errorf(
+					n,
+					"%q value is compared with string %q which is not a number. the string is converted to NaN so the comparison is always %s. compare with %s value instead",
+					ty.String(),
+					lit.Value,
+					result,
+					ty.String(),
+				)
+				return
+			}
+		}
+	}
+
+	if isGitHubContextProp(operand, "ref") {
+		if v := strings.ToLower(lit.Value); v != "" && !strings.HasPrefix(v, "refs/") {
+			sema.errorf(
+				n,
+				"github.ref is compared with %q which does not start with \"refs/\". github.ref is a fully-formed ref like \"refs/heads/%s\" so the comparison is always %s. compare it with 'refs/heads/%s' or use github.ref_name instead",
+				lit.Value,
+				lit.Value,
+				result,
+				lit.Value,
+			)
+		}
+		return
+	}
+
+	for prop, vs := range runnerContextValues {
+		if !isContextProp(operand, "runner", prop) {
+			continue
+		}
+		if !containsFold(vs, lit.Value) {
+			sema.errorf(
+				n,
+				"runner.%s is compared with %q but runner.%s is one of %s. the comparison is always %s",
+				prop,
+				lit.Value,
+				prop,
+				quotes(vs),
+				result,
+			)
+			return
+		}
+		if prop == "os" && len(sema.runnerOSes) > 0 && !containsFold(sema.runnerOSes, lit.Value) {
+			sema.errorf(
+				n,
+				"runner.os is compared with %q but the job runs only on %s runners. the comparison is always %s",
+				lit.Value,
+				quotes(sema.runnerOSes),
+				result,
+			)
+		}
+		return
+	}
+
+	if isGitHubContextProp(operand, "event_name") && len(sema.events) > 0 {
+		e := strings.ToLower(lit.Value)
+		for _, ev := range sema.events {
+			if ev == e {
+				return
+			}
+		}
+		sema.errorf(
+			n,
+			"github.event_name is compared with %q but the workflow is not triggered by %q event. the comparison is always %s. events triggering this workflow are %s",
+			lit.Value,
+			lit.Value,
+			result,
+			sortedQuotes(append([]string{}, sema.events...)),
+		)
+	}
+}
+
 // checkWithNarrowing checks type of given expression with type narrowing. Type narrowing narrows
 // down the type of the expression by assuming its value. For example, `l && r` is typed as
 // `typeof(l) | typeof(r)` usually. However when the expression is assumed to be true, its type can
@@ -738,6 +738,58 @@ func TestExprSemanticsCheckOK(t *testing.T) {
 				"piyo": NullType{},
 			}),
 		},
+		{
+			what:     "toJSON() with a secret",
+			input:    "toJSON(secrets.GITHUB_TOKEN)",
+			expected: StringType{},
+		},
+		{
+			what:     "contains() with string item in array",
+			input:    "contains(fromJSON('[\"main\"]'), github.ref_name)",
+			expected: BoolType{},
+		},
+		{
+			what:     "number compared with numeric string",
+			input:    "github.retention_days == '42'",
+			expected: BoolType{},
+		},
+		{
+			what:     "number compared with empty string",
+			input:    "github.retention_days != ''",
+			expected: BoolType{},
+		},
+		{
+			what:     "github.ref compared with fully-formed ref",
+			input:    "github.ref == 'refs/heads/main' || github.ref_name == 'main'",
+			expected: BoolType{},
+		},
+		{
+			what:     "github.event_name compared when events are unknown",
+			input:    "github.event_name == 'push'",
+			expected: BoolType{},
+		},
+		{
+			what:     "runner context compared with known values in case-insensitive",
+			input:    "runner.os == 'linux' || runner.arch != 'ARM64' || runner.environment == 'GitHub-Hosted'",
+			expected: BoolType{},
+		},
+		{
+			what:     "element of constant array from fromJSON",
+			input:    `fromJSON('[1, "foo", true]')[2]`,
+			expected: BoolType{},
+		},
+		{
+			what:  "element of nested constant array from fromJSON",
+			input: `fromJSON('{"foo":[{"bar":1}, null]}').FOO[0]`,
+			expected: NewStrictObjectType(map[string]ExprType{
+				"bar": NumberType{},
+			}),
+		},
+		{
+			what:     "element of constant array at index access",
+			input:    `fromJSON('{"foo":[[1], ["a"]]}')['foo'][1][0]`,
+			expected: StringType{},
+		},
 	}
 
 	allSPFuncs := []string{}
@@ -807,6 +859,9 @@ func TestExprSemanticsCheckError(t *testing.T) {
 		availCtx   []string
 		availSP    []string
 		configVars []string
+		events     []string
+		runnerOSes []string
+		otherMat   map[string][]string
 	}{
 		{
 			what:  "undefined variable",
@@ -1271,6 +1326,128 @@ func TestExprSemanticsCheckError(t *testing.T) {
 				"broken JSON string is passed to fromJSON() at offset 12",
 			},
 		},
+		{
+			what:  "index out of bounds of constant array",
+			input: `fromJSON('[1, 2]')[2]`,
+			expected: []string{
+				"index 2 is out of bounds of array with 2 elements",
+			},
+		},
+		{
+			what:  "index out of bounds of nested constant array",
+			input: `fromJSON('{"foo":[[1], []]}').foo[1][0]`,
+			expected: []string{
+				"index 0 is out of bounds of array with 0 elements",
+			},
+		},
+		{
+			what:  "negative index of array",
+			input: `github.event.commits[-1]`,
+			expected: []string{
+				"index -1 of array access is negative",
+			},
+		},
+		{
+			what:  "arguments of contains() are swapped",
+			input: "contains(github.ref, fromJSON('[\"refs/heads/main\"]'))",
+			expected: []string{
+				`arguments of contains() seem to be swapped. "string" value is given to the first argument and "array<string>" value is given to the second argument`,
+			},
+		},
+		{
+			what:  "join() with non-array value",
+			input: "join(github.event, ', ')",
+			expected: []string{
+				`first argument of join() must be an array but "object" value is given`,
+			},
+		},
+		{
+			what:  "toJSON() with all secrets",
+			input: "toJSON(secrets)",
+			expected: []string{
+				`toJSON(secrets) exposes all secrets of the repository`,
+			},
+		},
+		{
+			what:  "placeholder in pattern of hashFiles()",
+			input: "hashFiles('**/package-lock.json', '${{ matrix.dir }}/*.lock')",
+			expected: []string{
+				`pattern "${{ matrix.dir }}/*.lock" of hashFiles() contains "${{"`,
+			},
+		},
+		{
+			what:  "number compared with non-number string",
+			input: "github.retention_days == 'latest'",
+			expected: []string{
+				`"number" value is compared with string "latest" which is not a number. the string is converted to NaN so the comparison is always false`,
+			},
+		},
+		{
+			what:  "bool compared with non-number string",
+			input: "'true' != (github.retention_days == 1)",
+			expected: []string{
+				`"bool" value is compared with string "true" which is not a number. the string is converted to NaN so the comparison is always true`,
+			},
+		},
+		{
+			what:  "github.ref compared with branch name",
+			input: "github.ref == 'main'",
+			expected: []string{
+				`github.ref is compared with "main" which does not start with "refs/"`,
+			},
+		},
+		{
+			what:  "github.ref compared with branch name on right hand side",
+			input: "'main' != github.REF",
+			expected: []string{
+				`github.ref is compared with "main" which does not start with "refs/". github.ref is a fully-formed ref like "refs/heads/main" so the comparison is always true`,
+			},
+		},
+		{
+			what:   "github.event_name compared with event not triggering workflow",
+			input:  "github.event_name == 'pull_request'",
+			events: []string{"push", "workflow_dispatch"},
+			expected: []string{
+				`github.event_name is compared with "pull_request" but the workflow is not triggered by "pull_request" event. the comparison is always false. events triggering this workflow are "push", "workflow_dispatch"`,
+			},
+		},
+		{
+			what:     "matrix value defined in other job",
+			input:    "matrix.os",
+			otherMat: map[string][]string{"os": {"test"}},
+			expected: []string{
+				`property "os" is not defined in object type {}. it is defined in matrix of other job "test" but "matrix" context only contains values in "strategy.matrix" of the current job`,
+			},
+		},
+		{
+			what:  "runner.os compared with runner label",
+			input: "runner.os == 'ubuntu'",
+			expected: []string{
+				`runner.os is compared with "ubuntu" but runner.os is one of "Linux", "Windows", "macOS". the comparison is always false`,
+			},
+		},
+		{
+			what:  "runner.arch compared with unknown architecture",
+			input: "'amd64' != runner.arch",
+			expected: []string{
+				`runner.arch is compared with "amd64" but runner.arch is one of "X86", "X64", "ARM", "ARM64". the comparison is always true`,
+			},
+		},
+		{
+			what:  "runner.environment compared with unknown environment",
+			input: "runner.environment == 'hosted'",
+			expected: []string{
+				`runner.environment is compared with "hosted" but runner.environment is one of "github-hosted", "self-hosted"`,
+			},
+		},
+		{
+			what:       "runner.os compared with OS the job does not run on",
+			input:      "runner.os == 'Windows'",
+			runnerOSes: []string{"Linux", "macOS"},
+			expected: []string{
+				`runner.os is compared with "Windows" but the job runs only on "Linux", "macOS" runners. the comparison is always false`,
+			},
+		},
 	}
 
 	allSP := []string{}
@@ -1302,6 +1479,15 @@ func TestExprSemanticsCheckError(t *testing.T) {
 			if tc.availCtx != nil {
 				c.SetContextAvailability(tc.availCtx)
 			}
+			if tc.events != nil {
+				c.SetWorkflowEvents(tc.events)
+			}
+			if tc.runnerOSes != nil {
+				c.SetRunnerOSes(tc.runnerOSes)
+			}
+			if tc.otherMat != nil {
+				c.SetMatrixKeysOfOtherJobs(tc.otherMat)
+			}
 			if tc.availSP != nil {
 				c.SetSpecialFunctionAvailability(tc.availSP)
 			} else {
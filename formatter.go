@@ -0,0 +1,331 @@
+package actionlint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Canonical orders of keys in workflow sections. Keys which are not listed here are put after the
+// listed keys keeping their original order.
+var (
+	formatWorkflowKeyOrder = []string{
+		"name",
+		"run-name",
+		"on",
+		"permissions",
+		"env",
+		"defaults",
+		"concurrency",
+		"jobs",
+	}
+	formatJobKeyOrder = []string{
+		"name",
+		"needs",
+		"if",
+		"runs-on",
+		"environment",
+		"permissions",
+		"concurrency",
+		"outputs",
+		"env",
+		"defaults",
+		"strategy",
+		"container",
+		"services",
+		"timeout-minutes",
+		"continue-on-error",
+		"uses",
+		"with",
+		"secrets",
+		"steps",
+	}
+	formatStepKeyOrder = []string{
+		"id",
+		"name",
+		"if",
+		"uses",
+		"with",
+		"run",
+		"shell",
+		"working-directory",
+		"env",
+		"continue-on-error",
+		"timeout-minutes",
+	}
+)
+
+// FormatWorkflow formats the given workflow source into the canonical style. Keys of workflow, jobs,
+// and steps are sorted in the canonical order, numbers for versions (e.g. `python-version: 3.10`) are
+// quoted not to be parsed as floats, and indentation is normalized to 2 spaces. Comments are
+// preserved. When the source cannot be parsed as YAML, this function returns an error.
+func FormatWorkflow(src []byte) ([]byte, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(src, &n); err != nil {
+		return nil, fmt.Errorf("could not parse the workflow as YAML: %w", err)
+	}
+	if n.Kind != yaml.DocumentNode || len(n.Content) == 0 || n.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("workflow must be a mapping")
+	}
+
+	w := n.Content[0]
+	// Comment at top of the file is attached to the first key. Keep it at top after sorting keys
+	head := ""
+	if len(w.Content) > 0 {
+		head = w.Content[0].HeadComment
+		w.Content[0].HeadComment = ""
+	}
+	formatSortKeys(w, formatWorkflowKeyOrder)
+	if head != "" {
+		k := w.Content[0]
+		if k.HeadComment != "" {
+			head += "\n" + k.HeadComment
+		}
+		k.HeadComment = head
+	}
+	if jobs := formatFindValue(w, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 1; i < len(jobs.Content); i += 2 {
+			job := jobs.Content[i]
+			if job.Kind != yaml.MappingNode {
+				continue
+			}
+			formatSortKeys(job, formatJobKeyOrder)
+			if steps := formatFindValue(job, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+				for _, s := range steps.Content {
+					if s.Kind == yaml.MappingNode {
+						formatSortKeys(s, formatStepKeyOrder)
+					}
+				}
+			}
+		}
+	}
+	formatQuoteVersions(w, false)
+
+	blocks := formatFindBlockScalars(w, nil)
+	if len(blocks) == 0 {
+		return formatEncode(&n)
+	}
+
+	// The YAML encoder emits block scalars with trailing spaces as double-quoted strings. Encode
+	// placeholders in literal style instead and replace them with the source text to keep the style
+	for i, b := range blocks {
+		b.node.Value = fmt.Sprintf("%s%d\n", formatBlockScalarPlaceholder, i)
+		b.node.Style = yaml.LiteralStyle
+		b.node.LineComment = "" // Line comment is a part of the header in the source text
+	}
+	tmpl, err := formatEncode(&n)
+	for _, b := range blocks {
+		b.node.Value = b.value
+		b.node.Style = b.style
+		b.node.LineComment = b.comment
+	}
+	if err != nil {
+		return nil, err
+	}
+	enc, err := formatEncode(&n)
+	if err != nil {
+		return nil, err
+	}
+
+	out, ok := formatPutBlockScalars(tmpl, src, blocks)
+	if !ok {
+		return enc, nil
+	}
+	// Ensure the block scalars put in the source text have the same values as the encoded ones
+	var want, have interface{}
+	if yaml.Unmarshal(enc, &want) != nil || yaml.Unmarshal(out, &have) != nil || !reflect.DeepEqual(want, have) {
+		return enc, nil
+	}
+	return out, nil
+}
+
+func formatEncode(n *yaml.Node) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, fmt.Errorf("could not format the workflow: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("could not format the workflow: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+const formatBlockScalarPlaceholder = "__actionlint_format_block_scalar_"
+
+// formatBlockScalar is a block scalar in the source which the YAML encoder cannot emit in block style.
+type formatBlockScalar struct {
+	node    *yaml.Node
+	value   string
+	style   yaml.Style
+	comment string
+}
+
+// formatFindBlockScalars collects literal and folded block scalars which would be emitted in other
+// styles by the YAML encoder. For example, the encoder emits a block scalar containing trailing
+// spaces as a double-quoted string.
+func formatFindBlockScalars(n *yaml.Node, found []*formatBlockScalar) []*formatBlockScalar {
+	if n.Kind == yaml.ScalarNode {
+		if n.Style != yaml.LiteralStyle && n.Style != yaml.FoldedStyle {
+			return found
+		}
+		b, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Style: n.Style, Tag: n.Tag, Value: n.Value})
+		if err == nil && len(b) > 0 && (b[0] == '|' || b[0] == '>') {
+			return found
+		}
+		return append(found, &formatBlockScalar{n, n.Value, n.Style, n.LineComment})
+	}
+	for _, c := range n.Content {
+		found = formatFindBlockScalars(c, found)
+	}
+	return found
+}
+
+// formatIndent returns the number of leading spaces of the line.
+func formatIndent(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+// formatBlockScalarSource returns the header and the lines of content of the block scalar in the
+// source, and the indentation of the content. The header is the rest of the line after the block
+// indicator "|" or ">". It returns false when the source text cannot be reused.
+func formatBlockScalarSource(lines []string, n *yaml.Node) (string, []string, int, bool) {
+	if n.Line <= 0 || n.Line > len(lines) || n.Column <= 0 {
+		return "", nil, 0, false
+	}
+	l := lines[n.Line-1]
+	if i := ColumnUnitByte.FromRune(l, n.Column) - 1; i < len(l) {
+		l = l[i:]
+	} else {
+		return "", nil, 0, false
+	}
+	h := strings.TrimRight(strings.SplitN(l, "#", 2)[0], " \t")
+	if len(h) == 0 || h[0] != '|' && h[0] != '>' || strings.ContainsAny(h, "123456789") {
+		return "", nil, 0, false // Explicit indentation indicator is not supported
+	}
+
+	indent := -1
+	end := n.Line
+	for i := n.Line; i < len(lines); i++ {
+		l := lines[i]
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if indent < 0 {
+			indent = formatIndent(l)
+		}
+		if formatIndent(l) < indent {
+			break
+		}
+		end = i + 1
+	}
+	if strings.HasSuffix(h, "+") {
+		for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+			end++
+		}
+	}
+	return strings.TrimRight(l, " \t"), lines[n.Line:end], indent, indent >= 0
+}
+
+// formatPutBlockScalars replaces the placeholders of block scalars in the formatted output with their
+// source text. Content lines are shifted to follow the indentation of the formatted output.
+func formatPutBlockScalars(out, src []byte, blocks []*formatBlockScalar) ([]byte, bool) {
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+	outs := strings.SplitAfter(string(out), "\n")
+	ret := make([]string, 0, len(outs))
+	for _, l := range outs {
+		i := strings.Index(l, formatBlockScalarPlaceholder)
+		if i < 0 {
+			ret = append(ret, l)
+			continue
+		}
+		var idx int
+		if _, err := fmt.Sscanf(l[i+len(formatBlockScalarPlaceholder):], "%d", &idx); err != nil || idx >= len(blocks) || len(ret) == 0 {
+			return nil, false
+		}
+		head, body, indent, ok := formatBlockScalarSource(lines, blocks[idx].node)
+		if !ok {
+			return nil, false
+		}
+
+		// Replace the block indicator "|" at end of the previous line with the header in the source
+		prev := strings.TrimSuffix(ret[len(ret)-1], "\n")
+		if !strings.HasSuffix(prev, "|") {
+			return nil, false
+		}
+		ret[len(ret)-1] = prev[:len(prev)-1] + head + "\n"
+
+		delta := i - indent
+		for _, c := range body {
+			if strings.TrimSpace(c) == "" && formatIndent(c) < -delta {
+				c = ""
+			} else if delta >= 0 {
+				c = strings.Repeat(" ", delta) + c
+			} else if formatIndent(c) >= -delta {
+				c = c[-delta:]
+			} else {
+				return nil, false
+			}
+			ret = append(ret, c+"\n")
+		}
+	}
+	return []byte(strings.Join(ret, "")), true
+}
+
+func formatFindValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func formatSortKeys(m *yaml.Node, order []string) {
+	sorted := make([]*yaml.Node, 0, len(m.Content))
+	used := make([]bool, len(m.Content)/2)
+	for _, k := range order {
+		for i := 0; i < len(m.Content); i += 2 {
+			if !used[i/2] && m.Content[i].Value == k {
+				sorted = append(sorted, m.Content[i], m.Content[i+1])
+				used[i/2] = true
+			}
+		}
+	}
+	for i := 0; i < len(m.Content); i += 2 {
+		if !used[i/2] {
+			sorted = append(sorted, m.Content[i], m.Content[i+1])
+		}
+	}
+	m.Content = sorted
+}
+
+// formatQuoteVersions quotes number values of keys which represent versions such as "python-version"
+// or "node-version". For example, 3.10 is parsed as float 3.1 by YAML parsers.
+func formatQuoteVersions(n *yaml.Node, version bool) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if version && n.Style == 0 && (n.Tag == "!!float" || n.Tag == "!!int") {
+			n.Style = yaml.DoubleQuotedStyle
+			n.Tag = "!!str"
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content); i += 2 {
+			k := strings.ToLower(n.Content[i].Value)
+			formatQuoteVersions(n.Content[i+1], strings.HasSuffix(k, "version") || strings.HasSuffix(k, "versions"))
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			formatQuoteVersions(c, version)
+		}
+	default:
+		for _, c := range n.Content {
+			formatQuoteVersions(c, false)
+		}
+	}
+}
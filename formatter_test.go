@@ -0,0 +1,134 @@
+package actionlint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatWorkflowCanonicalStyle(t *testing.T) {
+	src := `# CI workflow
+jobs:
+    test:   # job comment
+        steps:
+        # comment before step
+        - with:
+              python-version: 3.10
+          uses: actions/setup-python@v5
+          name: Setup
+        - run: echo hi
+        runs-on: ubuntu-latest
+        strategy:
+          matrix:
+            node-version: [18, 20.10]
+        timeout-minutes: 1.5
+on:
+  push:
+    branches: [main]
+name: CI
+`
+	want := `# CI workflow
+name: CI
+on:
+  push:
+    branches: [main]
+jobs:
+  test: # job comment
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        node-version: ["18", "20.10"]
+    timeout-minutes: 1.5
+    steps:
+      # comment before step
+      - name: Setup
+        uses: actions/setup-python@v5
+        with:
+          python-version: "3.10"
+      - run: echo hi
+`
+	b, err := FormatWorkflow([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := string(b); have != want {
+		t.Fatalf("formatted workflow is unexpected.\nwant:\n%s\nhave:\n%s", want, have)
+	}
+
+	// Formatting is idempotent
+	b2, err := FormatWorkflow(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, b2) {
+		t.Fatalf("formatting is not idempotent:\n%s", b2)
+	}
+}
+
+func TestFormatWorkflowFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "fmt", "*.yaml"))
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			src, err := os.ReadFile(f)
+			if err != nil {
+				panic(err)
+			}
+			want, err := os.ReadFile(strings.TrimSuffix(f, ".yaml") + ".out")
+			if err != nil {
+				panic(err)
+			}
+			b, err := FormatWorkflow(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Fatalf("formatted workflow is unexpected.\nwant:\n%s\nhave:\n%s", want, b)
+			}
+			b2, err := FormatWorkflow(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, b2) {
+				t.Fatalf("formatting is not idempotent:\n%s", b2)
+			}
+		})
+	}
+}
+
+func TestFormatWorkflowError(t *testing.T) {
+	for _, src := range []string{"foo: [", "- foo", ""} {
+		if _, err := FormatWorkflow([]byte(src)); err == nil {
+			t.Errorf("no error occurred for %q", src)
+		}
+	}
+}
+
+func TestCommandFormatFix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.yaml")
+	if err := os.WriteFile(path, []byte("jobs: {}\non: push\n"), 0644); err != nil {
+		panic(err)
+	}
+
+	var out bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &out, Stderr: &out}
+	if status := cmd.Main([]string{"actionlint", "-fmt", "-fix", path}); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, out.String())
+	}
+	if !strings.Contains(out.String(), "Formatted") {
+		t.Fatalf("output is unexpected: %q", out.String())
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	if have, want := string(b), "on: push\njobs: {}\n"; have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+}
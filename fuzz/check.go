@@ -17,8 +17,10 @@ func FuzzCheck(data []byte) int {
 		return 0
 	}
 
-	ac := actionlint.NewLocalActionsCache(nil, nil)
-	wc := actionlint.NewLocalReusableWorkflowCache(nil, "", nil)
+	caps := &actionlint.Capabilities{
+		LocalActions:           actionlint.NewLocalActionsCache(nil, nil),
+		LocalReusableWorkflows: actionlint.NewLocalReusableWorkflowCache(nil, "", nil),
+	}
 
 	rules := []actionlint.Rule{
 		actionlint.NewRuleMatrix(),
@@ -28,11 +30,11 @@ func FuzzCheck(data []byte) int {
 		actionlint.NewRuleEvents(),
 		actionlint.NewRuleGlob(),
 		actionlint.NewRuleJobNeeds(),
-		actionlint.NewRuleAction(ac),
+		actionlint.NewRuleAction(),
 		actionlint.NewRuleEnvVar(),
 		actionlint.NewRuleID(),
-		actionlint.NewRuleExpression(ac, wc),
-		actionlint.NewRuleWorkflowCall("test.yaml", wc),
+		actionlint.NewRuleExpression(),
+		actionlint.NewRuleWorkflowCall("test.yaml"),
 		actionlint.NewRulePermissions(),
 		actionlint.NewRuleDeprecatedCommands(),
 		actionlint.NewRuleIfCond(),
@@ -40,6 +42,11 @@ func FuzzCheck(data []byte) int {
 
 	v := actionlint.NewVisitor()
 	for _, rule := range rules {
+		if r, ok := rule.(interface {
+			SetCapabilities(*actionlint.Capabilities)
+		}); ok {
+			r.SetCapabilities(caps)
+		}
 		v.AddPass(rule)
 	}
 
@@ -0,0 +1,115 @@
+package actionlint
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Native fuzz targets. Inputs which crashed the targets in the past are put in testdata/fuzz and run
+// as regression tests by `go test`. To run a fuzzer:
+//
+//	go test -run '^$' -fuzz FuzzParse
+
+func testFuzzAddFiles(f *testing.F, pattern string) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	testFuzzAddFiles(f, filepath.Join("testdata", "ok", "*.yaml"))
+	testFuzzAddFiles(f, filepath.Join("testdata", "err", "*.yaml"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		w, errs := Parse(b)
+		if w == nil && len(errs) == 0 {
+			t.Fatal("neither workflow nor errors was returned")
+		}
+		ParseDocuments(b)
+	})
+}
+
+func FuzzParseConfig(f *testing.F) {
+	testFuzzAddFiles(f, filepath.Join("testdata", "config", "*.yml"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		c, err := ParseConfig(b)
+		if c == nil && err == nil {
+			t.Fatal("neither config nor error was returned")
+		}
+	})
+}
+
+func testFuzzAddExprs(f *testing.F) {
+	r, err := os.Open(filepath.Join("testdata", "bench", "expressions.txt"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer r.Close()
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		f.Add(s.Text() + "}}")
+	}
+	if err := s.Err(); err != nil {
+		f.Fatal(err)
+	}
+}
+
+func FuzzExprLexer(f *testing.F) {
+	testFuzzAddExprs(f)
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			return
+		}
+		l := NewExprLexer(s)
+		for i := 0; ; i++ {
+			if i > len(s)+1 {
+				t.Fatalf("lexer did not stop for %q", s)
+			}
+			tok := l.Next()
+			if l.lexErr != nil || tok.Kind == TokenKindEnd {
+				break
+			}
+		}
+	})
+}
+
+func FuzzExprParse(f *testing.F) {
+	testFuzzAddExprs(f)
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			return
+		}
+		e, err := NewExprParser().Parse(NewExprLexer(s))
+		if err != nil {
+			return
+		}
+		NewExprSemanticsChecker(true, nil).Check(e)
+	})
+}
+
+type testPanicUnmarshaler struct{}
+
+func (u *testPanicUnmarshaler) UnmarshalYAML(n *yaml.Node) error {
+	panic("oops")
+}
+
+func TestParseRecoverYAMLPanic(t *testing.T) {
+	var u testPanicUnmarshaler
+	err := unmarshalYAML([]byte("foo: bar"), &u)
+	if err == nil || !strings.Contains(err.Error(), "could not parse as YAML: oops") {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"text/scanner"
 	"unicode"
@@ -253,3 +254,47 @@ func ValidatePathGlob(pat string) []InvalidGlobPattern {
 	}
 	return validateGlob(pat, false)
 }
+
+// compileGlob converts the glob pattern of filters into a regular expression which matches to entire
+// string. The pattern should be validated in advance. '!' at the start of the pattern is not handled
+// since it is negation of the pattern in the list of filters.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet
+func compileGlob(pat string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteRune('^')
+	rs := []rune(pat)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; c {
+		case '\\':
+			if i+1 < len(rs) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(rs[i])))
+			} else {
+				b.WriteString(`\\`)
+			}
+		case '*':
+			if i+1 < len(rs) && rs[i+1] == '*' {
+				i++
+				b.WriteString(".*")
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?', '+':
+			b.WriteRune(c) // Quantifier for the preceding character
+		case '[':
+			j := i + 1
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("missing ] in glob pattern %q", pat)
+			}
+			b.WriteString(string(rs[i : j+1]))
+			i = j
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteRune('$')
+	return regexp.Compile(b.String())
+}
@@ -411,3 +411,42 @@ func TestValidateGlobQuoteCharacterInErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileGlob(t *testing.T) {
+	testCases := []struct {
+		pat   string
+		match []string
+		not   []string
+	}{
+		{"main", []string{"main"}, []string{"main2", "xmain"}},
+		{"release/*", []string{"release/v1", "release/"}, []string{"release/v1/x", "release"}},
+		{"release/**", []string{"release/v1", "release/v1/x"}, []string{"releases/v1"}},
+		{"v[0-9]+.[0-9]", []string{"v1.2", "v12.3"}, []string{"v.2", "v1x2"}},
+		{"ab?c", []string{"ac", "abc"}, []string{"abbc"}},
+		{`feat\*`, []string{"feat*"}, []string{"feature"}},
+		{"a.b", []string{"a.b"}, []string{"axb"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pat, func(t *testing.T) {
+			r, err := compileGlob(tc.pat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.match {
+				if !r.MatchString(s) {
+					t.Errorf("%q should match to %q (%s)", tc.pat, s, r)
+				}
+			}
+			for _, s := range tc.not {
+				if r.MatchString(s) {
+					t.Errorf("%q should not match to %q (%s)", tc.pat, s, r)
+				}
+			}
+		})
+	}
+
+	if _, err := compileGlob("[a-z"); err == nil {
+		t.Fatal("error was not returned for missing ]")
+	}
+}
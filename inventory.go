@@ -0,0 +1,662 @@
+package actionlint
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	reInventoryCommitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	reInventorySemver    = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(?:[-+][0-9A-Za-z.-]+)?$`)
+	reInventoryMajor     = regexp.MustCompile(`^v?[0-9]+(?:\.[0-9]+)?$`)
+)
+
+// InventoryFormat is a format to print an inventory of dependencies used by workflows.
+type InventoryFormat string
+
+const (
+	// InventoryFormatJSON prints the inventory as a JSON array.
+	InventoryFormatJSON InventoryFormat = "json"
+	// InventoryFormatCSV prints the inventory as CSV with a header row.
+	InventoryFormatCSV InventoryFormat = "csv"
+	// InventoryFormatSPDX prints the inventory as SPDX document in tag-value format. Only the fields
+	// required by "SPDX Lite" profile are printed.
+	// https://spdx.github.io/spdx-spec/v2.3/SPDX-Lite/
+	InventoryFormatSPDX InventoryFormat = "spdx"
+	// InventoryFormatCycloneDX prints the inventory as CycloneDX SBOM document in JSON format.
+	// https://cyclonedx.org/docs/1.5/json/
+	InventoryFormatCycloneDX InventoryFormat = "cyclonedx"
+)
+
+// ParseInventoryFormat parses the given string as InventoryFormat. An empty string is parsed as
+// InventoryFormatJSON.
+func ParseInventoryFormat(s string) (InventoryFormat, error) {
+	switch f := InventoryFormat(s); f {
+	case "":
+		return InventoryFormatJSON, nil
+	case InventoryFormatJSON, InventoryFormatCSV, InventoryFormatSPDX, InventoryFormatCycloneDX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q of inventory report. it must be one of \"json\", \"csv\", \"spdx\", or \"cyclonedx\"", s)
+	}
+}
+
+// Kinds of dependencies in an inventory.
+const (
+	// InventoryKindAction is an action in other repository like "actions/checkout@v4".
+	InventoryKindAction = "action"
+	// InventoryKindLocalAction is an action in the same repository like "./path/to/action".
+	InventoryKindLocalAction = "local-action"
+	// InventoryKindDockerAction is a Docker container action like "docker://alpine:3".
+	InventoryKindDockerAction = "docker-action"
+	// InventoryKindReusableWorkflow is a reusable workflow in other repository.
+	InventoryKindReusableWorkflow = "reusable-workflow"
+	// InventoryKindLocalReusableWorkflow is a reusable workflow in the same repository.
+	InventoryKindLocalReusableWorkflow = "local-reusable-workflow"
+	// InventoryKindContainerImage is a Docker image of a job container or a service container.
+	InventoryKindContainerImage = "container-image"
+)
+
+// Kinds of pins of dependencies in an inventory. They describe how strictly the version of the
+// dependency is fixed.
+const (
+	// InventoryPinSHA is a full-length commit SHA. This is the only immutable ref of actions.
+	InventoryPinSHA = "sha"
+	// InventoryPinSemver is a full semantic version tag like "v1.2.3".
+	InventoryPinSemver = "semver"
+	// InventoryPinMajor is a major or minor version tag like "v1" or "v1.2". It is usually moved to
+	// the latest release.
+	InventoryPinMajor = "major"
+	// InventoryPinRef is a branch name or other kind of tag like "main".
+	InventoryPinRef = "ref"
+	// InventoryPinDigest is a digest of Docker image.
+	InventoryPinDigest = "digest"
+	// InventoryPinTag is a tag of Docker image.
+	InventoryPinTag = "tag"
+	// InventoryPinNone means no version is specified. Local actions and local reusable workflows are
+	// always in this kind since they are in the same revision as the workflow.
+	InventoryPinNone = "none"
+)
+
+// InventoryEntry is a dependency referenced at "uses:" in workflows.
+type InventoryEntry struct {
+	// Kind is a kind of the dependency. See InventoryKind* constants.
+	Kind string `json:"kind"`
+	// Name is a name of the dependency without version like "actions/checkout" or "alpine". Names
+	// of Docker images do not contain "docker://" prefix.
+	Name string `json:"name"`
+	// Version is a version of the dependency like "v4". It is empty when no version is specified.
+	Version string `json:"version"`
+	// Pin is a kind of the version. See InventoryPin* constants.
+	Pin string `json:"pin"`
+	// License is an SPDX license identifier of the repository of the dependency. It is empty when
+	// the license is unknown.
+	License string `json:"license,omitempty"`
+	// File is a file path of the workflow which uses the dependency.
+	File string `json:"file"`
+	// Line is a line number of "uses:" in the workflow.
+	Line int `json:"line"`
+	// Column is a column number of "uses:" in the workflow.
+	Column int `json:"column"`
+	// Via is a chain of composite actions through which the dependency is used transitively. It is
+	// empty when the workflow uses the dependency directly.
+	Via []string `json:"via,omitempty"`
+}
+
+// Spec returns the reference of the dependency as written at "uses:".
+func (e *InventoryEntry) Spec() string {
+	switch {
+	case e.isImage():
+		s := e.Name
+		if e.Kind == InventoryKindDockerAction {
+			s = "docker://" + s
+		}
+		if e.Pin == InventoryPinDigest {
+			return s + "@" + e.Version
+		}
+		if e.Version != "" {
+			return s + ":" + e.Version
+		}
+		return s
+	case e.Version == "":
+		return e.Name
+	default:
+		return e.Name + "@" + e.Version
+	}
+}
+
+func (e *InventoryEntry) isImage() bool {
+	return e.Kind == InventoryKindDockerAction || e.Kind == InventoryKindContainerImage
+}
+
+// Inventory is an inventory of dependencies used by workflows such as actions, reusable workflows,
+// and container images. Composite actions are followed to collect dependencies used by them transitively. When
+// GitHub API client is given, metadata of actions in other repositories and their licenses are
+// fetched.
+type Inventory struct {
+	// Entries is a list of dependencies.
+	Entries []*InventoryEntry
+	client  *GitHubClient
+	remote  map[string]*ActionMetadata
+	created time.Time
+	dbg     io.Writer
+}
+
+// NewInventory creates a new Inventory instance. The client parameter can be nil. In the case,
+// remote actions are not followed and licenses are not fetched. The dbg parameter is a writer to
+// print debug logs. It can be nil.
+func NewInventory(client *GitHubClient, dbg io.Writer) *Inventory {
+	return &Inventory{
+		client:  client,
+		remote:  map[string]*ActionMetadata{},
+		created: time.Now().UTC(),
+		dbg:     dbg,
+	}
+}
+
+func (inv *Inventory) debug(format string, args ...interface{}) {
+	if inv.dbg == nil {
+		return
+	}
+	format = "[Inventory] " + format + "\n"
+	fmt.Fprintf(inv.dbg, format, args...)
+}
+
+// AddWorkflow adds dependencies used by the workflow to the inventory. The path parameter is a file
+// path of the workflow. The local parameter is used to find metadata of local composite actions.
+func (inv *Inventory) AddWorkflow(path string, w *Workflow, local *LocalActionsCache) error {
+	for _, j := range w.Jobs {
+		if j.Container != nil {
+			inv.addImage(j.Container.Image, path)
+		}
+		if j.Services != nil {
+			for _, s := range j.Services.Value {
+				if s.Container != nil {
+					inv.addImage(s.Container.Image, path)
+				}
+			}
+		}
+		if j.WorkflowCall != nil && j.WorkflowCall.Uses != nil && !j.WorkflowCall.Uses.ContainsExpression() {
+			if err := inv.add(j.WorkflowCall.Uses.Value, true, path, j.WorkflowCall.Uses.Pos, nil, local); err != nil {
+				return err
+			}
+		}
+		for _, s := range j.Steps {
+			e, ok := s.Exec.(*ExecAction)
+			if !ok || e.Uses == nil || e.Uses.ContainsExpression() {
+				continue
+			}
+			if err := inv.add(e.Uses.Value, false, path, e.Uses.Pos, nil, local); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (inv *Inventory) addImage(image *String, path string) {
+	if image == nil || image.ContainsExpression() || image.Value == "" {
+		return
+	}
+	e := newInventoryEntry("docker://"+image.Value, false)
+	e.Kind = InventoryKindContainerImage
+	e.File = path
+	e.Line = image.Pos.Line
+	e.Column = image.Pos.Col
+	inv.Entries = append(inv.Entries, e)
+}
+
+func (inv *Inventory) add(spec string, workflow bool, path string, pos *Pos, via []string, local *LocalActionsCache) error {
+	e := newInventoryEntry(spec, workflow)
+	e.File = path
+	e.Line = pos.Line
+	e.Column = pos.Col
+	e.Via = via
+
+	if e.Kind == InventoryKindAction || e.Kind == InventoryKindReusableWorkflow {
+		if err := inv.fetchLicense(e); err != nil {
+			return err
+		}
+	}
+	inv.Entries = append(inv.Entries, e)
+
+	if workflow {
+		return nil
+	}
+	// Prevent infinite recursion of composite actions
+	for _, v := range via {
+		if v == spec {
+			return nil
+		}
+	}
+
+	meta, err := inv.findActionMetadata(e, local)
+	if err != nil || meta == nil {
+		return err
+	}
+	via = append(via[:len(via):len(via)], spec)
+
+	// Image of Docker action on a Docker registry
+	if meta.Runs.Using == "docker" && strings.HasPrefix(meta.Runs.Image, "docker://") {
+		return inv.add(meta.Runs.Image, false, path, pos, via, local)
+	}
+	if meta.Runs.Using != "composite" {
+		return nil
+	}
+	for _, s := range meta.Runs.Steps {
+		m, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		u, ok := m["uses"].(string)
+		if !ok || ContainsExpression(u) {
+			continue
+		}
+		if err := inv.add(u, false, path, pos, via, local); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (inv *Inventory) findActionMetadata(e *InventoryEntry, local *LocalActionsCache) (*ActionMetadata, error) {
+	switch e.Kind {
+	case InventoryKindLocalAction:
+		if local == nil {
+			return nil, nil
+		}
+		m, _, err := local.FindMetadata(e.Name)
+		return m, err
+	case InventoryKindAction:
+		if inv.client == nil {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	spec := e.Spec()
+	if m, ok := inv.remote[spec]; ok {
+		return m, nil
+	}
+	inv.remote[spec] = nil
+
+	ss := strings.SplitN(e.Name, "/", 3)
+	if len(ss) < 2 {
+		return nil, nil
+	}
+	repo, dir := ss[0]+"/"+ss[1], ""
+	if len(ss) == 3 {
+		dir = ss[2] + "/"
+	}
+	for _, f := range []string{"action.yml", "action.yaml"} {
+		inv.debug("Fetching metadata of action %s from %s%s", spec, dir, f)
+		b, ok, err := inv.client.Content(repo, dir+f, e.Version)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var m ActionMetadata
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			inv.debug("Could not parse metadata of action %s: %s", spec, err)
+			return nil, nil
+		}
+		inv.remote[spec] = &m
+		return &m, nil
+	}
+	return nil, nil
+}
+
+func (inv *Inventory) fetchLicense(e *InventoryEntry) error {
+	if inv.client == nil {
+		return nil
+	}
+	ss := strings.SplitN(e.Name, "/", 3)
+	if len(ss) < 2 {
+		return nil
+	}
+	l, ok, err := inv.client.License(ss[0] + "/" + ss[1])
+	if err != nil {
+		return err
+	}
+	if ok {
+		e.License = l
+	}
+	return nil
+}
+
+// newInventoryEntry creates a new entry from the value of "uses:". The workflow parameter is true
+// when the value is at "jobs.<job_id>.uses".
+func newInventoryEntry(spec string, workflow bool) *InventoryEntry {
+	if strings.HasPrefix(spec, "./") {
+		k := InventoryKindLocalAction
+		if workflow {
+			k = InventoryKindLocalReusableWorkflow
+		}
+		return &InventoryEntry{Kind: k, Name: spec, Pin: InventoryPinNone}
+	}
+
+	if strings.HasPrefix(spec, "docker://") {
+		name, tag, digest := splitDockerImageRef(strings.TrimPrefix(spec, "docker://"))
+		e := &InventoryEntry{Kind: InventoryKindDockerAction, Name: name}
+		switch {
+		case digest != "":
+			e.Version, e.Pin = digest, InventoryPinDigest
+		case tag != "":
+			e.Version, e.Pin = tag, InventoryPinTag
+		default:
+			e.Pin = InventoryPinNone
+		}
+		return e
+	}
+
+	k := InventoryKindAction
+	if workflow {
+		k = InventoryKindReusableWorkflow
+	}
+	name, ref, _ := strings.Cut(spec, "@")
+	return &InventoryEntry{Kind: k, Name: name, Version: ref, Pin: inventoryPinOfRef(ref)}
+}
+
+func inventoryPinOfRef(ref string) string {
+	switch {
+	case ref == "":
+		return InventoryPinNone
+	case reInventoryCommitSHA.MatchString(ref):
+		return InventoryPinSHA
+	case reInventorySemver.MatchString(ref):
+		return InventoryPinSemver
+	case reInventoryMajor.MatchString(ref):
+		return InventoryPinMajor
+	default:
+		return InventoryPinRef
+	}
+}
+
+// Sort sorts the entries by file paths and positions.
+func (inv *Inventory) Sort() {
+	sort.SliceStable(inv.Entries, func(i, j int) bool {
+		l, r := inv.Entries[i], inv.Entries[j]
+		if l.File != r.File {
+			return l.File < r.File
+		}
+		if l.Line != r.Line {
+			return l.Line < r.Line
+		}
+		return l.Column < r.Column
+	})
+}
+
+// Print prints the inventory to the writer in the format.
+func (inv *Inventory) Print(w io.Writer, format InventoryFormat) error {
+	switch format {
+	case InventoryFormatJSON, "":
+		return inv.printJSON(w)
+	case InventoryFormatCSV:
+		return inv.printCSV(w)
+	case InventoryFormatSPDX:
+		return inv.printSPDX(w)
+	case InventoryFormatCycloneDX:
+		return inv.printCycloneDX(w)
+	default:
+		return fmt.Errorf("unknown format %q of inventory report", format)
+	}
+}
+
+func (inv *Inventory) printJSON(w io.Writer) error {
+	es := inv.Entries
+	if es == nil {
+		es = []*InventoryEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(es); err != nil {
+		return fmt.Errorf("could not encode inventory to JSON: %w", err)
+	}
+	return nil
+}
+
+func (inv *Inventory) printCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"kind", "name", "version", "pin", "license", "file", "line", "column", "via"})
+	for _, e := range inv.Entries {
+		c.Write([]string{
+			e.Kind,
+			e.Name,
+			e.Version,
+			e.Pin,
+			e.License,
+			e.File,
+			strconv.Itoa(e.Line),
+			strconv.Itoa(e.Column),
+			strings.Join(e.Via, " > "),
+		})
+	}
+	c.Flush()
+	if err := c.Error(); err != nil {
+		return fmt.Errorf("could not write inventory as CSV: %w", err)
+	}
+	return nil
+}
+
+// packages returns unique dependencies in the inventory. Local dependencies are omitted since they
+// are a part of the repository.
+func (inv *Inventory) packages() []*InventoryEntry {
+	seen := map[string]struct{}{}
+	ret := []*InventoryEntry{}
+	for _, e := range inv.Entries {
+		if e.Kind == InventoryKindLocalAction || e.Kind == InventoryKindLocalReusableWorkflow {
+			continue
+		}
+		s := e.purl()
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		ret = append(ret, e)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Spec() < ret[j].Spec()
+	})
+	return ret
+}
+
+// purl returns Package URL of the dependency.
+// https://github.com/package-url/purl-spec
+func (e *InventoryEntry) purl() string {
+	if e.isImage() {
+		s := "pkg:docker/" + e.Name
+		switch e.Pin {
+		case InventoryPinDigest:
+			s += "@" + strings.ReplaceAll(e.Version, ":", "%3A")
+		case InventoryPinTag:
+			s += "@" + e.Version
+		}
+		return s
+	}
+	s := "pkg:githubactions/" + e.Name
+	if e.Version != "" {
+		s += "@" + e.Version
+	}
+	return s
+}
+
+// downloadLocation returns the location to download the dependency.
+func (e *InventoryEntry) downloadLocation() string {
+	if e.isImage() {
+		return "NOASSERTION"
+	}
+	ss := strings.SplitN(e.Name, "/", 3)
+	if len(ss) < 2 {
+		return "NOASSERTION"
+	}
+	s := fmt.Sprintf("git+https://github.com/%s/%s", ss[0], ss[1])
+	if e.Version != "" {
+		s += "@" + e.Version
+	}
+	if len(ss) == 3 {
+		s += "#" + ss[2]
+	}
+	return s
+}
+
+func (inv *Inventory) printSPDX(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("SPDXVersion: SPDX-2.3\n")
+	b.WriteString("DataLicense: CC0-1.0\n")
+	b.WriteString("SPDXID: SPDXRef-DOCUMENT\n")
+	b.WriteString("DocumentName: actionlint-actions-inventory\n")
+	fmt.Fprintf(&b, "DocumentNamespace: https://github.com/rhysd/actionlint/spdx/actions-inventory-%d\n", inv.created.Unix())
+	b.WriteString("Creator: Tool: actionlint\n")
+	fmt.Fprintf(&b, "Created: %s\n", inv.created.Format("2006-01-02T15:04:05Z"))
+
+	for i, e := range inv.packages() {
+		license := e.License
+		if license == "" {
+			license = "NOASSERTION"
+		}
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "PackageName: %s\n", e.Name)
+		fmt.Fprintf(&b, "SPDXID: %s\n", id)
+		if e.Version != "" {
+			fmt.Fprintf(&b, "PackageVersion: %s\n", e.Version)
+		}
+		fmt.Fprintf(&b, "PackageDownloadLocation: %s\n", e.downloadLocation())
+		b.WriteString("FilesAnalyzed: false\n")
+		fmt.Fprintf(&b, "PackageLicenseConcluded: %s\n", license)
+		fmt.Fprintf(&b, "PackageLicenseDeclared: %s\n", license)
+		b.WriteString("PackageCopyrightText: NOASSERTION\n")
+		fmt.Fprintf(&b, "ExternalRef: PACKAGE-MANAGER purl %s\n", e.purl())
+		fmt.Fprintf(&b, "Relationship: SPDXRef-DOCUMENT DESCRIBES %s\n", id)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write inventory as SPDX: %w", err)
+	}
+	return nil
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		ID string `json:"id"`
+	} `json:"license"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXComponent struct {
+	Type               string                       `json:"type"`
+	BOMRef             string                       `json:"bom-ref"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	PURL               string                       `json:"purl"`
+	Licenses           []cycloneDXLicense           `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty          `json:"properties"`
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string `json:"bomFormat"`
+	SpecVersion  string `json:"specVersion"`
+	SerialNumber string `json:"serialNumber"`
+	Version      int    `json:"version"`
+	Metadata     struct {
+		Timestamp string `json:"timestamp"`
+		Tools     struct {
+			Components []cycloneDXComponent `json:"components"`
+		} `json:"tools"`
+	} `json:"metadata"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// newUUID generates a random UUID version 4.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("could not generate UUID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func (inv *Inventory) printCycloneDX(w io.Writer) error {
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	bom := &cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + id,
+		Version:      1,
+		Components:   []cycloneDXComponent{},
+	}
+	bom.Metadata.Timestamp = inv.created.Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cycloneDXComponent{
+		{
+			Type:       "application",
+			BOMRef:     "actionlint",
+			Name:       "actionlint",
+			Version:    getCommandVersion(),
+			PURL:       "pkg:golang/github.com/rhysd/actionlint",
+			Properties: []cycloneDXProperty{},
+		},
+	}
+
+	for _, e := range inv.packages() {
+		c := cycloneDXComponent{
+			Type:    "library",
+			BOMRef:  e.purl(),
+			Name:    e.Name,
+			Version: e.Version,
+			PURL:    e.purl(),
+			Properties: []cycloneDXProperty{
+				{"actionlint:kind", e.Kind},
+				{"actionlint:pin", e.Pin},
+			},
+		}
+		if e.isImage() {
+			c.Type = "container"
+		} else if ss := strings.SplitN(e.Name, "/", 3); len(ss) >= 2 {
+			c.ExternalReferences = []cycloneDXExternalReference{
+				{"vcs", fmt.Sprintf("https://github.com/%s/%s", ss[0], ss[1])},
+			}
+		}
+		if e.License != "" {
+			var l cycloneDXLicense
+			l.License.ID = e.License
+			c.Licenses = []cycloneDXLicense{l}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("could not encode inventory to CycloneDX: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInventoryNewEntry(t *testing.T) {
+	tests := []struct {
+		spec     string
+		workflow bool
+		want     InventoryEntry
+	}{
+		{"actions/checkout@v4", false, InventoryEntry{Kind: "action", Name: "actions/checkout", Version: "v4", Pin: "major"}},
+		{"actions/checkout@v4.1", false, InventoryEntry{Kind: "action", Name: "actions/checkout", Version: "v4.1", Pin: "major"}},
+		{"actions/checkout@v4.1.1", false, InventoryEntry{Kind: "action", Name: "actions/checkout", Version: "v4.1.1", Pin: "semver"}},
+		{"actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11", false, InventoryEntry{Kind: "action", Name: "actions/checkout", Version: "b4ffde65f46336ab88eb53be808477a3936bae11", Pin: "sha"}},
+		{"github/codeql-action/init@main", false, InventoryEntry{Kind: "action", Name: "github/codeql-action/init", Version: "main", Pin: "ref"}},
+		{"./.github/actions/setup", false, InventoryEntry{Kind: "local-action", Name: "./.github/actions/setup", Pin: "none"}},
+		{"docker://alpine:3.18", false, InventoryEntry{Kind: "docker-action", Name: "alpine", Version: "3.18", Pin: "tag"}},
+		{"docker://ghcr.io/o/i@sha256:abcd", false, InventoryEntry{Kind: "docker-action", Name: "ghcr.io/o/i", Version: "sha256:abcd", Pin: "digest"}},
+		{"docker://alpine", false, InventoryEntry{Kind: "docker-action", Name: "alpine", Pin: "none"}},
+		{"o/r/.github/workflows/ci.yml@v1", true, InventoryEntry{Kind: "reusable-workflow", Name: "o/r/.github/workflows/ci.yml", Version: "v1", Pin: "major"}},
+		{"./.github/workflows/ci.yml", true, InventoryEntry{Kind: "local-reusable-workflow", Name: "./.github/workflows/ci.yml", Pin: "none"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			have := newInventoryEntry(tc.spec, tc.workflow)
+			if !cmp.Equal(&tc.want, have) {
+				t.Fatal(cmp.Diff(&tc.want, have))
+			}
+			if s := have.Spec(); s != tc.spec {
+				t.Fatalf("wanted spec %q but got %q", tc.spec, s)
+			}
+		})
+	}
+}
+
+func testInventoryProject(t *testing.T) (*Project, string) {
+	d := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yaml": `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/setup
+      - uses: docker://alpine:3.18
+  call:
+    uses: ./.github/workflows/reusable.yaml
+  db:
+    runs-on: ubuntu-latest
+    container: node:20
+    services:
+      postgres:
+        image: postgres@sha256:abcd
+    steps:
+      - uses: ./.github/actions/docker
+`,
+		".github/actions/docker/action.yaml": `name: Docker
+description: Docker
+runs:
+  using: docker
+  image: docker://ghcr.io/o/i:1.0
+`,
+		".github/actions/setup/action.yaml": `name: Setup
+description: Setup
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v4.0.0
+    - uses: ./.github/actions/cache
+    - run: echo
+      shell: bash
+`,
+		".github/actions/cache/action.yaml": `name: Cache
+description: Cache
+runs:
+  using: composite
+  steps:
+    - uses: actions/cache@v4
+`,
+	}
+	for p, c := range files {
+		p = filepath.Join(d, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := NewProject(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, filepath.Join(p.WorkflowsDir(), "ci.yaml")
+}
+
+func TestInventoryAddWorkflowLocalCompositeActions(t *testing.T) {
+	p, path := testInventoryProject(t)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, errs := Parse(b)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	inv := NewInventory(nil, nil)
+	if err := inv.AddWorkflow("ci.yaml", w, NewLocalActionsCache(p, nil)); err != nil {
+		t.Fatal(err)
+	}
+	inv.Sort()
+
+	have := []string{}
+	for _, e := range inv.Entries {
+		have = append(have, fmt.Sprintf("%d:%d %s %s %s", e.Line, e.Column, e.Kind, e.Spec(), strings.Join(e.Via, ">")))
+	}
+	want := []string{
+		"6:15 action actions/checkout@v4 ",
+		"7:15 local-action ./.github/actions/setup ",
+		"7:15 action actions/setup-node@v4.0.0 ./.github/actions/setup",
+		"7:15 local-action ./.github/actions/cache ./.github/actions/setup",
+		"7:15 action actions/cache@v4 ./.github/actions/setup>./.github/actions/cache",
+		"8:15 docker-action docker://alpine:3.18 ",
+		"10:11 local-reusable-workflow ./.github/workflows/reusable.yaml ",
+		"13:16 container-image node:20 ",
+		"16:16 container-image postgres@sha256:abcd ",
+		"18:15 local-action ./.github/actions/docker ",
+		"18:15 docker-action docker://ghcr.io/o/i:1.0 ./.github/actions/docker",
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestInventoryRemoteActionsAndLicenses(t *testing.T) {
+	meta := base64.StdEncoding.EncodeToString([]byte(`name: Composite
+description: Composite
+runs:
+  using: composite
+  steps:
+    - uses: o/other@v2
+    - uses: o/composite@v1
+`))
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/composite/contents/action.yml?ref=v1": fmt.Sprintf(`{"type":"file","encoding":"base64","content":%q}`, meta),
+		"/repos/o/composite/license":                    `{"license":{"spdx_id":"MIT"}}`,
+		"/repos/o/other/license":                        `{"license":{"spdx_id":"NOASSERTION"}}`,
+	})
+
+	w, errs := Parse([]byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: o/composite@v1
+`))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	inv := NewInventory(c, nil)
+	if err := inv.AddWorkflow("test.yaml", w, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	have := []string{}
+	for _, e := range inv.Entries {
+		have = append(have, fmt.Sprintf("%s %q %s", e.Spec(), e.License, strings.Join(e.Via, ">")))
+	}
+	// Recursive reference to itself is not followed infinitely
+	want := []string{
+		`o/composite@v1 "MIT" `,
+		`o/other@v2 "" o/composite@v1`,
+		`o/composite@v1 "MIT" o/composite@v1`,
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestInventoryPrint(t *testing.T) {
+	inv := NewInventory(nil, nil)
+	inv.created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	inv.Entries = []*InventoryEntry{
+		{Kind: "action", Name: "actions/checkout", Version: "v4", Pin: "major", License: "MIT", File: "ci.yaml", Line: 6, Column: 15},
+		{Kind: "local-action", Name: "./setup", Pin: "none", File: "ci.yaml", Line: 7, Column: 15},
+		{Kind: "action", Name: "github/codeql-action/init", Version: "v3", Pin: "major", File: "ci.yaml", Line: 7, Column: 15, Via: []string{"./setup"}},
+		{Kind: "action", Name: "actions/checkout", Version: "v4", Pin: "major", License: "MIT", File: "release.yaml", Line: 8, Column: 15},
+		{Kind: "docker-action", Name: "alpine", Version: "sha256:abcd", Pin: "digest", File: "release.yaml", Line: 9, Column: 15},
+		{Kind: "container-image", Name: "alpine", Version: "sha256:abcd", Pin: "digest", File: "release.yaml", Line: 12, Column: 16},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := inv.Print(&b, InventoryFormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		var have []*InventoryEntry
+		if err := json.Unmarshal(b.Bytes(), &have); err != nil {
+			t.Fatal(err, b.String())
+		}
+		if !cmp.Equal(inv.Entries, have) {
+			t.Fatal(cmp.Diff(inv.Entries, have))
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := inv.Print(&b, InventoryFormatCSV); err != nil {
+			t.Fatal(err)
+		}
+		want := `kind,name,version,pin,license,file,line,column,via
+action,actions/checkout,v4,major,MIT,ci.yaml,6,15,
+local-action,./setup,,none,,ci.yaml,7,15,
+action,github/codeql-action/init,v3,major,,ci.yaml,7,15,./setup
+action,actions/checkout,v4,major,MIT,release.yaml,8,15,
+docker-action,alpine,sha256:abcd,digest,,release.yaml,9,15,
+container-image,alpine,sha256:abcd,digest,,release.yaml,12,16,
+`
+		if have := b.String(); have != want {
+			t.Fatal(cmp.Diff(want, have))
+		}
+	})
+
+	t.Run("spdx", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := inv.Print(&b, InventoryFormatSPDX); err != nil {
+			t.Fatal(err)
+		}
+		have := b.String()
+		for _, want := range []string{
+			"SPDXVersion: SPDX-2.3\n",
+			"Created: 2024-01-02T03:04:05Z\n",
+			"PackageName: actions/checkout\nSPDXID: SPDXRef-Package-1\nPackageVersion: v4\nPackageDownloadLocation: git+https://github.com/actions/checkout@v4\n",
+			"PackageLicenseDeclared: MIT\n",
+			"PackageName: alpine\nSPDXID: SPDXRef-Package-2\n",
+			"ExternalRef: PACKAGE-MANAGER purl pkg:docker/alpine@sha256%3Aabcd\n",
+			"PackageDownloadLocation: git+https://github.com/github/codeql-action@v3#init\n",
+			"ExternalRef: PACKAGE-MANAGER purl pkg:githubactions/github/codeql-action/init@v3\n",
+			"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-3\n",
+		} {
+			if !strings.Contains(have, want) {
+				t.Errorf("SPDX output does not contain %q:\n%s", want, have)
+			}
+		}
+		if strings.Contains(have, "./setup") {
+			t.Errorf("local action should not be included in SPDX output:\n%s", have)
+		}
+		if n := strings.Count(have, "PackageName:"); n != 3 {
+			t.Errorf("wanted 3 unique packages but got %d:\n%s", n, have)
+		}
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := inv.Print(&b, InventoryFormatCycloneDX); err != nil {
+			t.Fatal(err)
+		}
+		var have cycloneDXBOM
+		if err := json.Unmarshal(b.Bytes(), &have); err != nil {
+			t.Fatal(err, b.String())
+		}
+		if have.BOMFormat != "CycloneDX" || have.SpecVersion != "1.5" || have.Version != 1 {
+			t.Fatalf("unexpected header: %+v", have)
+		}
+		if !strings.HasPrefix(have.SerialNumber, "urn:uuid:") || len(have.SerialNumber) != len("urn:uuid:")+36 {
+			t.Fatalf("invalid serial number: %q", have.SerialNumber)
+		}
+		if have.Metadata.Timestamp != "2024-01-02T03:04:05Z" {
+			t.Fatalf("unexpected timestamp: %q", have.Metadata.Timestamp)
+		}
+
+		want := []cycloneDXComponent{
+			{
+				Type:               "library",
+				BOMRef:             "pkg:githubactions/actions/checkout@v4",
+				Name:               "actions/checkout",
+				Version:            "v4",
+				PURL:               "pkg:githubactions/actions/checkout@v4",
+				Licenses:           []cycloneDXLicense{{}},
+				ExternalReferences: []cycloneDXExternalReference{{"vcs", "https://github.com/actions/checkout"}},
+				Properties:         []cycloneDXProperty{{"actionlint:kind", "action"}, {"actionlint:pin", "major"}},
+			},
+			{
+				Type:       "container",
+				BOMRef:     "pkg:docker/alpine@sha256%3Aabcd",
+				Name:       "alpine",
+				Version:    "sha256:abcd",
+				PURL:       "pkg:docker/alpine@sha256%3Aabcd",
+				Properties: []cycloneDXProperty{{"actionlint:kind", "docker-action"}, {"actionlint:pin", "digest"}},
+			},
+			{
+				Type:               "library",
+				BOMRef:             "pkg:githubactions/github/codeql-action/init@v3",
+				Name:               "github/codeql-action/init",
+				Version:            "v3",
+				PURL:               "pkg:githubactions/github/codeql-action/init@v3",
+				ExternalReferences: []cycloneDXExternalReference{{"vcs", "https://github.com/github/codeql-action"}},
+				Properties:         []cycloneDXProperty{{"actionlint:kind", "action"}, {"actionlint:pin", "major"}},
+			},
+		}
+		want[0].Licenses[0].License.ID = "MIT"
+		if !cmp.Equal(want, have.Components) {
+			t.Fatal(cmp.Diff(want, have.Components))
+		}
+	})
+}
+
+func TestInventoryParseFormat(t *testing.T) {
+	for _, s := range []string{"", "json", "csv", "spdx", "cyclonedx"} {
+		if _, err := ParseInventoryFormat(s); err != nil {
+			t.Errorf("%q: %s", s, err)
+		}
+	}
+	if _, err := ParseInventoryFormat("xml"); err == nil || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -0,0 +1,135 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema is a subset of JSON schema to describe the structure of JSON values like the
+// client_payload of repository_dispatch event. Only keywords related to the types of values are
+// supported. Other keywords like "description" or "required" are ignored.
+// https://json-schema.org/understanding-json-schema/reference/type
+type JSONSchema struct {
+	// Types is a list of type names at "type" like "object" or "string". When it is empty, the value
+	// can be any type.
+	Types []string
+	// Properties is a mapping from property names to their schemas at "properties".
+	Properties map[string]*JSONSchema
+	// AdditionalProperties is a schema of properties not listed at "properties". When it is nil,
+	// additional properties can be any value.
+	AdditionalProperties *JSONSchema
+	// NoAdditionalProperties is true when "additionalProperties" is false.
+	NoAdditionalProperties bool
+	// Items is a schema of elements of array at "items".
+	Items *JSONSchema
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *JSONSchema) UnmarshalYAML(n *yaml.Node) error {
+	var raw struct {
+		Type                 yaml.Node              `yaml:"type"`
+		Properties           map[string]*JSONSchema `yaml:"properties"`
+		AdditionalProperties yaml.Node              `yaml:"additionalProperties"`
+		Items                *JSONSchema            `yaml:"items"`
+	}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch raw.Type.Kind {
+	case 0:
+		// "type" is omitted
+	case yaml.ScalarNode:
+		s.Types = []string{raw.Type.Value}
+	case yaml.SequenceNode:
+		if err := raw.Type.Decode(&s.Types); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("line %d: \"type\" in JSON schema must be string or array of strings", raw.Type.Line)
+	}
+	for _, t := range s.Types {
+		switch t {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("line %d: unknown type %q in JSON schema. available types are \"array\", \"boolean\", \"integer\", \"null\", \"number\", \"object\", \"string\"", raw.Type.Line, t)
+		}
+	}
+
+	switch raw.AdditionalProperties.Kind {
+	case 0:
+		// "additionalProperties" is omitted
+	case yaml.ScalarNode:
+		var b bool
+		if err := raw.AdditionalProperties.Decode(&b); err != nil {
+			return err
+		}
+		s.NoAdditionalProperties = !b
+	default:
+		var a JSONSchema
+		if err := raw.AdditionalProperties.Decode(&a); err != nil {
+			return err
+		}
+		s.AdditionalProperties = &a
+	}
+
+	s.Properties = raw.Properties
+	s.Items = raw.Items
+	return nil
+}
+
+// ExprType converts the JSON schema into the type of expressions. When the schema allows multiple
+// types, it returns any type.
+func (s *JSONSchema) ExprType() ExprType {
+	if s == nil {
+		return AnyType{}
+	}
+
+	ty := ""
+	for _, t := range s.Types {
+		if t == "null" {
+			continue // Null is allowed for optional values
+		}
+		if t == "integer" {
+			t = "number"
+		}
+		if ty != "" && ty != t {
+			return AnyType{}
+		}
+		ty = t
+	}
+	if ty == "" {
+		if s.Properties != nil {
+			ty = "object"
+		} else if len(s.Types) > 0 {
+			return NullType{} // Only "null" is allowed
+		}
+	}
+
+	switch ty {
+	case "object":
+		props := make(map[string]ExprType, len(s.Properties))
+		for n, p := range s.Properties {
+			props[strings.ToLower(n)] = p.ExprType() // Property access is case insensitive
+		}
+		if s.NoAdditionalProperties {
+			return NewStrictObjectType(props)
+		}
+		if s.AdditionalProperties != nil {
+			return &ObjectType{props, s.AdditionalProperties.ExprType()}
+		}
+		return NewObjectType(props)
+	case "array":
+		return &ArrayType{Elem: s.Items.ExprType()}
+	case "string":
+		return StringType{}
+	case "number":
+		return NumberType{}
+	case "boolean":
+		return BoolType{}
+	default:
+		return AnyType{}
+	}
+}
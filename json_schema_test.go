@@ -0,0 +1,93 @@
+package actionlint
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONSchemaExprType(t *testing.T) {
+	testCases := []struct {
+		what   string
+		schema string
+		want   string
+	}{
+		{
+			what:   "string",
+			schema: "type: string",
+			want:   "string",
+		},
+		{
+			what:   "integer",
+			schema: "type: integer",
+			want:   "number",
+		},
+		{
+			what:   "nullable",
+			schema: "type: [boolean, 'null']",
+			want:   "bool",
+		},
+		{
+			what:   "null",
+			schema: "type: 'null'",
+			want:   "null",
+		},
+		{
+			what:   "multiple types",
+			schema: "type: [string, number]",
+			want:   "any",
+		},
+		{
+			what:   "number and integer",
+			schema: "type: [number, integer]",
+			want:   "number",
+		},
+		{
+			what:   "no type",
+			schema: "description: anything",
+			want:   "any",
+		},
+		{
+			what:   "array",
+			schema: "type: array\nitems:\n  type: string",
+			want:   "array<string>",
+		},
+		{
+			what:   "array without items",
+			schema: "type: array",
+			want:   "array<any>",
+		},
+		{
+			what:   "strict object",
+			schema: "type: object\nadditionalProperties: false\nproperties:\n  Foo:\n    type: string\n  bar:\n    type: number",
+			want:   "{bar: number; foo: string}",
+		},
+		{
+			what:   "object without type",
+			schema: "additionalProperties: false\nproperties:\n  foo:\n    type: string",
+			want:   "{foo: string}",
+		},
+		{
+			what:   "map object",
+			schema: "type: object\nadditionalProperties:\n  type: boolean",
+			want:   "{string => bool}",
+		},
+		{
+			what:   "empty object",
+			schema: "type: object",
+			want:   "object",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var s JSONSchema
+			if err := yaml.Unmarshal([]byte(tc.schema), &s); err != nil {
+				t.Fatal(err)
+			}
+			if have := s.ExprType().String(); have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
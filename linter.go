@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,8 +11,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -59,6 +62,9 @@ type LinterOptions struct {
 	LogWriter io.Writer
 	// Color is option for colorizing error outputs. See ColorOptionKind document for each enum values.
 	Color ColorOptionKind
+	// ContextLines is the number of lines printed before and after the error line in source snippets.
+	// Zero means only the error line is printed. This option is ignored when Oneline or Format is set.
+	ContextLines int
 	// Oneline is flag if one line output is enabled. When enabling it, one error is output per one
 	// line. It is useful when reading outputs from programs.
 	Oneline bool
@@ -68,10 +74,12 @@ type LinterOptions struct {
 	Shellcheck string
 	// Pyflakes is executable for running pyflakes external command. It can be command name like "pyflakes"
 	// or file path like "/path/to/pyflakes", "path/to/pyflakes". When this value is empty, pyflakes
-	// won't run to check scripts in workflow file.
+	// won't run to check scripts in workflow file. "ruff" and "flake8" are also available instead of
+	// pyflakes. When this value is "pyflakes" and it is not found, ruff or flake8 is used if found.
 	Pyflakes string
-	// IgnorePatterns is list of regular expression to filter errors. The pattern is applied to error
-	// messages. When an error is matched, the error is ignored.
+	// IgnorePatterns is list of patterns to filter errors. A pattern is a rule name with "rule:"
+	// prefix, an error code with "code:" prefix, or a regular expression applied to error messages.
+	// When an error is matched, the error is ignored.
 	IgnorePatterns []string
 	// ConfigFile is a path to config file. Empty string means no config file path is given. In
 	// the case, actionlint will try to read config from .github/actionlint.yaml.
@@ -87,12 +95,109 @@ type LinterOptions struct {
 	WorkingDir string
 	// OnRulesCreated is a hook to add or remove the check rules. This function is called on checking
 	// every workflow files. Rules created by Linter instance are passed to the argument and the
-	// function should return the modified rules.
+	// function should return the modified rules. It is also called once per project with the rules
+	// implementing ProjectRule before checking all workflows in the project. Rules implementing
+	// ProjectRule returned from this function on checking each workflow file are ignored, and vice
+	// versa.
 	// Note that syntax errors may be reported even if this function returns nil or an empty slice.
 	OnRulesCreated func([]Rule) []Rule
+	// OnCapabilitiesCreated is a hook to replace the services which rules use while checking workflows
+	// such as resolving metadata of actions. This function is called on checking every workflow file
+	// with the services created by Linter instance and should return the modified services. It is
+	// useful to stub the services in tests. See Capabilities document for each service.
+	OnCapabilitiesCreated func(*Capabilities) *Capabilities
+	// TemplateMode is a templating language used for generating workflow files. When this value is
+	// not TemplateModeNone, templating constructs in workflow files are neutralized before parsing and
+	// each YAML document in a file is checked as a workflow. See TemplateMode document for more details.
+	TemplateMode TemplateMode
+	// GroupBy is a key to aggregate errors in output. When this value is not ReportGroupByNone,
+	// numbers of errors grouped by file or rule are printed instead of each error. This option cannot
+	// be used with Format. See Report document for more details.
+	GroupBy ReportGroupBy
+	// Remote is a repository on GitHub in "owner/repo" form. When this value is not empty, references
+	// specific to the repository such as secrets, variables, environments, branches, reusable
+	// workflows, and runner labels are validated using GitHub API.
+	Remote string
+	// RemoteToken is a token to access GitHub API for the Remote option. When this value is empty,
+	// API requests are sent without authentication.
+	RemoteToken string
+	// RemoteClient is a client of GitHub API used for the Remote option. When this value is nil, a
+	// new client is created with RemoteToken. It is useful to access GitHub API via a different base
+	// URL such as a fake server in tests. RemoteToken is ignored when this value is set.
+	RemoteClient *GitHubClient
+	// RemoteLint is flag to lint reusable workflows in other repositories which are called by the
+	// checked workflows. The called workflows are fetched transitively via GitHub API up to
+	// RemoteMaxDepth levels. This option is effective only when Remote is set.
+	RemoteLint bool
+	// RemoteMaxDepth is the maximum depth of nested reusable workflow calls to fetch and lint when
+	// RemoteLint is enabled. When this value is zero, the default depth 10 is used.
+	RemoteMaxDepth int
+	// Baseline is a path to a baseline file in JSON format. Errors recorded in the baseline file are
+	// not reported. When this value is empty, no baseline is used. See Baseline document for more
+	// details.
+	Baseline string
+	// StrictInternal is a flag to crash the process when some rule panics. By default, a panic in a
+	// rule is recovered and reported as an internal error of the rule so that other rules and files
+	// can be checked. This is useful for development.
+	StrictInternal bool
+	// FutureSyntax is a mode to treat keys which are defined in the workflow syntax but not supported
+	// by this version of actionlint yet. When this value is empty, FutureSyntaxModeError is used. Note
+	// that warnings in FutureSyntaxModeWarn are printed to LogWriter. See FutureSyntaxMode document
+	// for more details.
+	FutureSyntax FutureSyntaxMode
+	// ColumnUnit is a unit to count columns of error positions. When this value is empty,
+	// ColumnUnitRune is used. See ColumnUnit document for more details.
+	ColumnUnit ColumnUnit
+	// Locale is a language of error messages on output. When this value is empty, LocaleEnglish is
+	// used. Only the output is localized. Error values returned from the linter, ignore patterns, and
+	// baselines always use English messages. See Locale document for more details.
+	Locale Locale
+	// OnlyCategories is a list of error categories to report. Errors in other categories are
+	// filtered out. When this value is empty, errors in all categories are reported. Note that errors
+	// of custom rules have no category and are filtered out when this value is not empty.
+	OnlyCategories []ErrorCategory
+	// ProcessTimeout is a timeout of each external process run by rules such as shellcheck and
+	// pyflakes. When the process does not finish within the timeout, it is killed and a warning is
+	// printed to LogWriter instead of an error. Zero means no timeout.
+	ProcessTimeout time.Duration
+	// FileTimeout is a timeout of checking each workflow file by rules. When it is exceeded, the
+	// remaining checks for the file are skipped and a warning is printed to LogWriter. Zero means no
+	// timeout.
+	FileTimeout time.Duration
+	// AllowConfigCommands allows running external commands configured in config files of
+	// repositories. They are "script-linters" and "flags" of "shellcheck". Since anyone who can send
+	// a pull request can modify the config file, they are ignored by default. The config file given
+	// by ConfigFile is always trusted.
+	AllowConfigCommands bool
+	// MaxFileSize is the maximum size of workflow file to check in bytes. A file larger than the size
+	// is not read into memory nor parsed and an error of "limits" rule is reported instead so that
+	// a huge file such as a generated YAML file accidentally put in the workflows directory does not
+	// exhaust memory. Zero means the default size (10 MiB). A negative value means no limit.
+	MaxFileSize int64
+	// Fix is a flag to apply fixes of errors of the rules configured with `fix: auto` to the checked
+	// files in place. The fixed errors are not reported. This option is effective only for files
+	// read by LintFiles, LintFile, LintDir, and LintRepository.
+	Fix bool
+	// OnlyRules is a list of rule names to run. Other rules are not run and their errors are not
+	// reported. Errors found while parsing workflows are always reported. When this value is empty,
+	// all rules run.
+	OnlyRules []string
+	// OnlyJobs is a list of job IDs to check. Other jobs and their steps are not visited by rules.
+	// Job IDs are case-insensitive. Note that errors outside jobs such as errors at "on:" are still
+	// reported. When this value is empty, all jobs are checked. When some job ID does not match any
+	// job in the checked workflows, linting fails with UnknownJobsError.
+	OnlyJobs []string
+	// Context is a context to cancel linting. When it is cancelled, running external processes such
+	// as shellcheck and pyflakes are killed with their child processes, the remaining files are not
+	// checked, and the linting fails with an error wrapping the context's error. When this value is
+	// nil, context.Background() is used.
+	Context context.Context
 	// More options will come here
 }
 
+// defaultMaxFileSize is the default value of LinterOptions.MaxFileSize.
+const defaultMaxFileSize = 10 * 1024 * 1024
+
 // Linter is struct to lint workflow files.
 type Linter struct {
 	projects       *Projects
@@ -108,6 +213,28 @@ type Linter struct {
 	errFmt         *ErrorFormatter
 	cwd            string
 	onRulesCreated func([]Rule) []Rule
+	onCapsCreated  func(*Capabilities) *Capabilities
+	templateMode   TemplateMode
+	contextLines   int
+	groupBy        ReportGroupBy
+	remote         *RemoteRepository
+	remoteWorkflow *RemoteReusableWorkflowCache
+	remoteLint     bool
+	remoteDepth    int
+	baseline       *Baseline
+	strictInternal bool
+	futureSyntax   FutureSyntaxMode
+	columnUnit     ColumnUnit
+	locale         Locale
+	categories     map[ErrorCategory]struct{}
+	processTimeout time.Duration
+	fileTimeout    time.Duration
+	maxFileSize    int64
+	allowCfgCmds   bool
+	fix            bool
+	onlyRules      map[string]struct{}
+	onlyJobs       map[string]string // Lower-case job IDs mapped to the original ones
+	ctx            context.Context
 }
 
 // NewLinter creates a new Linter instance.
@@ -148,17 +275,25 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		cfg = c
 	}
 
-	ignore := make([]*regexp.Regexp, 0, len(opts.IgnorePatterns))
+	ignore := make(IgnorePatterns, 0, len(opts.IgnorePatterns))
 	for _, s := range opts.IgnorePatterns {
-		r, err := regexp.Compile(s)
+		p, err := ParseIgnorePattern(s)
 		if err != nil {
-			return nil, fmt.Errorf("invalid regular expression for ignore pattern %q: %s", s, err.Error())
+			return nil, err
 		}
-		ignore = append(ignore, r)
+		ignore = append(ignore, p)
+	}
+
+	groupBy, err := ParseReportGroupBy(string(opts.GroupBy))
+	if err != nil {
+		return nil, err
 	}
 
 	var formatter *ErrorFormatter
 	if opts.Format != "" {
+		if groupBy != ReportGroupByNone {
+			return nil, errors.New("grouping errors by file or rule cannot be used with custom error format")
+		}
 		f, err := NewErrorFormatter(opts.Format)
 		if err != nil {
 			return nil, err
@@ -173,6 +308,95 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		cwd = d
 	}
 
+	tmpl, err := ParseTemplateMode(string(opts.TemplateMode))
+	if err != nil {
+		return nil, err
+	}
+
+	future, err := ParseFutureSyntaxMode(string(opts.FutureSyntax))
+	if err != nil {
+		return nil, err
+	}
+
+	unit, err := ParseColumnUnit(string(opts.ColumnUnit))
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := ParseLocale(string(opts.Locale))
+	if err != nil {
+		return nil, err
+	}
+
+	var categories map[ErrorCategory]struct{}
+	if len(opts.OnlyCategories) > 0 {
+		categories = make(map[ErrorCategory]struct{}, len(opts.OnlyCategories))
+		for _, c := range opts.OnlyCategories {
+			if err := c.validate(); err != nil {
+				return nil, err
+			}
+			categories[c] = struct{}{}
+		}
+	}
+
+	var onlyRules map[string]struct{}
+	if len(opts.OnlyRules) > 0 {
+		onlyRules = make(map[string]struct{}, len(opts.OnlyRules))
+		for _, r := range opts.OnlyRules {
+			onlyRules[r] = struct{}{}
+		}
+	}
+
+	var onlyJobs map[string]string
+	if len(opts.OnlyJobs) > 0 {
+		onlyJobs = make(map[string]string, len(opts.OnlyJobs))
+		for _, j := range opts.OnlyJobs {
+			onlyJobs[strings.ToLower(j)] = j
+		}
+	}
+
+	var remote *RemoteRepository
+	var remoteWorkflow *RemoteReusableWorkflowCache
+	if opts.Remote != "" {
+		c := opts.RemoteClient
+		if c == nil {
+			c = NewGitHubClient(opts.RemoteToken)
+		}
+		r, err := NewRemoteRepository(opts.Remote, c)
+		if err != nil {
+			return nil, err
+		}
+		remote = r
+		var dbg io.Writer
+		if level >= LogLevelDebug {
+			dbg = lout
+		}
+		remoteWorkflow = NewRemoteReusableWorkflowCache(r.Client(), dbg)
+	}
+	remoteDepth := opts.RemoteMaxDepth
+	if remoteDepth <= 0 {
+		remoteDepth = 10 // https://docs.github.com/en/actions/sharing-automations/reusing-workflows#nesting-reusable-workflows
+	}
+
+	var baseline *Baseline
+	if opts.Baseline != "" {
+		b, err := ReadBaselineFile(opts.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		baseline = b
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize == 0 {
+		maxFileSize = defaultMaxFileSize
+	}
+
 	stdin := "<stdin>"
 	if opts.StdinFileName != "" {
 		stdin = opts.StdinFileName
@@ -192,6 +416,28 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		formatter,
 		cwd,
 		opts.OnRulesCreated,
+		opts.OnCapabilitiesCreated,
+		tmpl,
+		opts.ContextLines,
+		groupBy,
+		remote,
+		remoteWorkflow,
+		opts.RemoteLint,
+		remoteDepth,
+		baseline,
+		opts.StrictInternal,
+		future,
+		unit,
+		locale,
+		categories,
+		opts.ProcessTimeout,
+		opts.FileTimeout,
+		maxFileSize,
+		opts.AllowConfigCommands,
+		opts.Fix,
+		onlyRules,
+		onlyJobs,
+		ctx,
 	}
 
 	l.debug("Create a Linter instance with option %#v", opts)
@@ -280,8 +526,9 @@ func (l *Linter) LintRepository(dir string) ([]*Error, error) {
 	return l.LintDir(wd, p)
 }
 
-// LintDir lints all YAML workflow files in the given directory recursively.
-func (l *Linter) LintDir(dir string, project *Project) ([]*Error, error) {
+// findWorkflowFiles collects all YAML files in the given directory recursively. The returned file
+// paths are sorted.
+func findWorkflowFiles(dir string) ([]string, error) {
 	files := []string{}
 	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -301,11 +548,21 @@ func (l *Linter) LintDir(dir string, project *Project) ([]*Error, error) {
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no YAML file was found in %q", dir)
 	}
-	l.log("Collected", len(files), "YAML files")
 
 	// To make output deterministic, sort order of file paths
 	sort.Strings(files)
 
+	return files, nil
+}
+
+// LintDir lints all YAML workflow files in the given directory recursively.
+func (l *Linter) LintDir(dir string, project *Project) ([]*Error, error) {
+	files, err := findWorkflowFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	l.log("Collected", len(files), "YAML files")
+
 	return l.LintFiles(files, project)
 }
 
@@ -325,19 +582,13 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 
 	cwd := l.cwd
 	cpus := runtime.NumCPU()
-	proc := newConcurrentProcess(cpus)
+	proc := l.newProcess(cpus)
 	sema := semaphore.NewWeighted(int64(cpus))
 	ctx := context.Background()
 	dbg := l.debugWriter()
 	acf := NewLocalActionsCacheFactory(dbg)
 	rwcf := NewLocalReusableWorkflowCacheFactory(cwd, dbg)
 
-	type workspace struct {
-		path string
-		errs []*Error
-		src  []byte
-	}
-
 	ws := make([]workspace, 0, len(filepaths))
 	for _, p := range filepaths {
 		ws = append(ws, workspace{path: p})
@@ -363,9 +614,11 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		eg.Go(func() error {
 			// Bound concurrency on reading files to avoid "too many files to open" error (issue #3)
 			sema.Acquire(ctx, 1)
-			src, err := os.ReadFile(w.path)
+			file := w.path
+			src, err := l.readFile(file)
 			sema.Release(1)
-			if err != nil {
+			tooLarge := errors.Is(err, errFileTooLarge)
+			if err != nil && !tooLarge {
 				return fmt.Errorf("could not read %q: %w", w.path, err)
 			}
 
@@ -374,12 +627,15 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 					w.path = r // Use relative path if possible
 				}
 			}
-			errs, err := l.check(w.path, src, proj, proc, ac, rwc)
+			if tooLarge {
+				*w = *l.tooLargeWorkspace(w.path, proj)
+				return nil
+			}
+			c, err := l.checkAndFix(file, w.path, src, proj, proc, ac, rwc)
 			if err != nil {
 				return fmt.Errorf("fatal error while checking %s: %w", w.path, err)
 			}
-			w.src = src
-			w.errs = errs
+			*w = *c
 			return nil
 		})
 	}
@@ -388,6 +644,22 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		return nil, err
 	}
 
+	ws, err := l.checkProjects(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err = l.checkCompositeActions(ws, acf.all(), proc)
+	if err != nil {
+		return nil, err
+	}
+
+	rws, err := l.lintRemoteWorkflows(proc)
+	if err != nil {
+		return nil, err
+	}
+	ws = append(ws, rws...)
+
 	// Ensure that all processes finish. `proc.wait()` must be called after `eg.Wait()`.
 	// Calling `WaitGroup.Add` after `WaitGroup.Wait` can cause a race condition (specifically when
 	// increasing the group count from 0 to 1 and calling `Wait` and at the same time).
@@ -396,33 +668,12 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 	// called safely.
 	proc.wait()
 
-	total := 0
-	for i := range ws {
-		total += len(ws[i].errs)
-	}
-
-	all := make([]*Error, 0, total)
-	if l.errFmt != nil {
-		temp := make([]*ErrorTemplateFields, 0, total)
-		for i := range ws {
-			w := &ws[i]
-			for _, err := range w.errs {
-				temp = append(temp, err.GetTemplateFields(w.src))
-			}
-			all = append(all, w.errs...)
-		}
-		if err := l.errFmt.Print(l.out, temp); err != nil {
-			return nil, err
-		}
-	} else {
-		for i := range ws {
-			w := &ws[i]
-			l.printErrors(w.errs, w.src)
-			all = append(all, w.errs...)
-		}
+	all, err := l.printWorkspaces(ws)
+	if err != nil {
+		return nil, err
 	}
 
-	l.log("Found", total, "errors in", n, "files")
+	l.log("Found", len(all), "errors in", n, "files")
 
 	return all, nil
 }
@@ -438,33 +689,48 @@ func (l *Linter) LintFile(path string, project *Project) ([]*Error, error) {
 		project = p
 	}
 
-	src, err := os.ReadFile(path)
-	if err != nil {
+	src, err := l.readFile(path)
+	tooLarge := errors.Is(err, errFileTooLarge)
+	if err != nil && !tooLarge {
 		return nil, fmt.Errorf("could not read %q: %w", path, err)
 	}
 
+	file := path
 	if l.cwd != "" {
 		if r, err := filepath.Rel(l.cwd, path); err == nil {
 			path = r
 		}
 	}
+	if tooLarge {
+		return l.printWorkspaces([]workspace{*l.tooLargeWorkspace(path, project)})
+	}
 
-	proc := newConcurrentProcess(runtime.NumCPU())
+	proc := l.newProcess(runtime.NumCPU())
 	dbg := l.debugWriter()
 	localActions := NewLocalActionsCache(project, dbg)
 	localReusableWorkflows := NewLocalReusableWorkflowCache(project, l.cwd, dbg)
-	errs, err := l.check(path, src, project, proc, localActions, localReusableWorkflows)
+	w, err := l.checkAndFix(file, path, src, project, proc, localActions, localReusableWorkflows)
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	ws, err := l.checkProjects([]workspace{*w})
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	ws, err = l.checkCompositeActions(ws, []*LocalActionsCache{localActions}, proc)
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	rws, err := l.lintRemoteWorkflows(proc)
 	proc.wait()
 	if err != nil {
 		return nil, err
 	}
 
-	if l.errFmt != nil {
-		l.errFmt.PrintErrors(l.out, errs, src)
-	} else {
-		l.printErrors(errs, src)
-	}
-	return errs, err
+	return l.printWorkspaces(append(ws, rws...))
 }
 
 // LintStdin lints the content read from STDIN. The stdin parameter is a reader to read from STDIN,
@@ -472,7 +738,10 @@ func (l *Linter) LintFile(path string, project *Project) ([]*Error, error) {
 // option is empty, "<stdin>" is the default value.
 func (l *Linter) LintStdin(stdin io.Reader) ([]*Error, error) {
 	l.log("Reading the input from stdin")
-	b, err := io.ReadAll(stdin)
+	b, err := l.readSource(stdin, 0)
+	if errors.Is(err, errFileTooLarge) {
+		return l.printWorkspaces([]workspace{*l.tooLargeWorkspace(l.stdin, nil)})
+	}
 	if err != nil {
 		return nil, fmt.Errorf("could not read stdin: %w", err)
 	}
@@ -492,21 +761,243 @@ func (l *Linter) Lint(path string, content []byte, project *Project) ([]*Error,
 			project = p
 		}
 	}
-	proc := newConcurrentProcess(runtime.NumCPU())
+	proc := l.newProcess(runtime.NumCPU())
 	dbg := l.debugWriter()
 	localActions := NewLocalActionsCache(project, dbg)
 	localReusableWorkflows := NewLocalReusableWorkflowCache(project, l.cwd, dbg)
-	errs, err := l.check(path, content, project, proc, localActions, localReusableWorkflows)
+	w, err := l.check(path, content, project, proc, localActions, localReusableWorkflows)
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	ws, err := l.checkProjects([]workspace{*w})
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	ws, err = l.checkCompositeActions(ws, []*LocalActionsCache{localActions}, proc)
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+	rws, err := l.lintRemoteWorkflows(proc)
 	proc.wait()
 	if err != nil {
 		return nil, err
 	}
-	if l.errFmt != nil {
-		l.errFmt.PrintErrors(l.out, errs, content)
+
+	return l.printWorkspaces(append(ws, rws...))
+}
+
+// lintRemoteWorkflows lints reusable workflows in other repositories which were fetched while
+// checking workflows. Since linting the fetched workflows fetches more reusable workflows called by
+// them, this method repeats linting until no new workflow is fetched or the depth reaches the limit.
+func (l *Linter) lintRemoteWorkflows(proc *concurrentProcess) ([]workspace, error) {
+	if !l.remoteLint || l.remoteWorkflow == nil {
+		return nil, nil
+	}
+
+	dbg := l.debugWriter()
+	linted := map[string]struct{}{}
+	ws := []workspace{}
+	for depth := 1; depth <= l.remoteDepth; depth++ {
+		specs := []string{}
+		for _, s := range l.remoteWorkflow.Workflows() {
+			if _, ok := linted[s]; !ok {
+				specs = append(specs, s)
+			}
+		}
+		if len(specs) == 0 {
+			return ws, nil
+		}
+
+		for _, s := range specs {
+			linted[s] = struct{}{}
+			src, _ := l.remoteWorkflow.Source(s)
+			l.log("Linting remote reusable workflow", s, "at depth", depth)
+			w, err := l.check(s, src, nil, proc, newNullLocalActionsCache(dbg), newNullLocalReusableWorkflowCache(dbg))
+			if err != nil {
+				return nil, fmt.Errorf("fatal error while checking remote reusable workflow %s: %w", s, err)
+			}
+			ws = append(ws, *w)
+		}
+	}
+
+	l.log("Stopped linting remote reusable workflows since the depth reached the limit", l.remoteDepth)
+	return ws, nil
+}
+
+type workspace struct {
+	path      string
+	errs      []*Error
+	src       []byte
+	project   *Project
+	cfg       *Config
+	workflows []*Workflow
+	caps      *Capabilities // Services used for checking the workflows
+}
+
+// printWorkspaces prints errors in the workspaces and returns all of them. Errors are ordered by file
+// path, line, column, rule name, and message so that the output is deterministic regardless of the
+// order in which files were checked.
+func (l *Linter) printWorkspaces(ws []workspace) ([]*Error, error) {
+	if err := l.checkOnlyJobs(ws); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ws, func(i, j int) bool {
+		return ws[i].path < ws[j].path
+	})
+
+	total := 0
+	for i := range ws {
+		total += len(ws[i].errs)
+	}
+
+	all := make([]*Error, 0, total)
+	if l.groupBy != ReportGroupByNone {
+		for i := range ws {
+			all = append(all, ws[i].errs...)
+		}
+		NewReport(all, l.groupBy).PrintGrouped(l.out, l.groupBy)
+	} else if l.errFmt != nil {
+		temp := make([]*ErrorTemplateFields, 0, total)
+		for i := range ws {
+			w := &ws[i]
+			for _, err := range w.errs {
+				temp = append(temp, l.templateFields(err, w.src))
+			}
+			all = append(all, w.errs...)
+		}
+		if err := l.errFmt.Print(l.out, temp); err != nil {
+			return nil, err
+		}
+	} else {
+		for i := range ws {
+			w := &ws[i]
+			l.printErrors(w.errs, w.src)
+			all = append(all, w.errs...)
+		}
+	}
+	return all, nil
+}
+
+// UnknownJobsError is an error returned when some job IDs given with LinterOptions.OnlyJobs do not
+// match any job in the checked workflows.
+type UnknownJobsError struct {
+	// IDs is a list of the job IDs which do not match any job.
+	IDs []string
+	// Available is a list of IDs of all jobs in the checked workflows.
+	Available []string
+}
+
+func (e *UnknownJobsError) Error() string {
+	return fmt.Sprintf("unknown job %s at -job option. available jobs in the checked workflows are %s", quotes(e.IDs), quotes(e.Available))
+}
+
+// checkOnlyJobs checks all job IDs in LinterOptions.OnlyJobs match some job in the workflows of the
+// workspaces. The check is skipped when no workflow was parsed since jobs are unknown.
+func (l *Linter) checkOnlyJobs(ws []workspace) error {
+	if l.onlyJobs == nil {
+		return nil
+	}
+
+	parsed := false
+	seen := map[string]struct{}{}
+	available := []string{}
+	for i := range ws {
+		for _, w := range ws[i].workflows {
+			parsed = true
+			for _, j := range w.Jobs {
+				if j == nil || j.ID == nil {
+					continue
+				}
+				id := strings.ToLower(j.ID.Value)
+				if _, ok := seen[id]; !ok {
+					seen[id] = struct{}{}
+					available = append(available, j.ID.Value)
+				}
+			}
+		}
+	}
+	if !parsed {
+		return nil
+	}
+
+	unknown := []string{}
+	for id, orig := range l.onlyJobs {
+		if _, ok := seen[id]; !ok {
+			unknown = append(unknown, orig)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	sort.Strings(available)
+	return &UnknownJobsError{unknown, available}
+}
+
+// errFileTooLarge is returned when the size of the source exceeds the maximum file size.
+var errFileTooLarge = errors.New("file is too large")
+
+// readSource reads the source from the reader. It does not read more than the maximum file size into
+// memory and returns errFileTooLarge when the source is larger than the size. The size parameter is
+// the size of the source when it is known in advance. Otherwise it is 0.
+func (l *Linter) readSource(r io.Reader, size int64) ([]byte, error) {
+	if l.maxFileSize < 0 {
+		if size == 0 {
+			return io.ReadAll(r)
+		}
+	} else if size > l.maxFileSize {
+		return nil, errFileTooLarge
 	} else {
-		l.printErrors(errs, content)
+		r = io.LimitReader(r, l.maxFileSize+1)
+	}
+
+	var b bytes.Buffer
+	if size > 0 {
+		b.Grow(int(size) + bytes.MinRead) // Avoid reallocations since the size is known
+	}
+	if _, err := b.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if l.maxFileSize >= 0 && int64(b.Len()) > l.maxFileSize {
+		return nil, errFileTooLarge
+	}
+	return b.Bytes(), nil
+}
+
+// readFile reads the workflow file at the path. It returns errFileTooLarge without reading the file
+// when the file is larger than the maximum file size.
+func (l *Linter) readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var size int64
+	if s, err := f.Stat(); err == nil && s.Mode().IsRegular() {
+		size = s.Size() // The size of a file like named pipe is unknown
 	}
-	return errs, nil
+	return l.readSource(f, size)
+}
+
+// tooLargeWorkspace creates a workspace for the file which was not checked since it is larger than
+// the maximum file size. The workspace has only one error to report the file was not checked.
+func (l *Linter) tooLargeWorkspace(path string, project *Project) *workspace {
+	l.log("Skipped", path, "since its size exceeds the maximum file size", l.maxFileSize, "bytes")
+	cfg := l.config(project)
+	err := errorfAt(
+		&Pos{Line: 1, Col: 1},
+		"limits",
+		"this file was not checked since its size exceeds the maximum file size %d bytes. GitHub does not accept workflow files larger than %d bytes. change the maximum size with -max-file-size option if this file needs to be checked",
+		l.maxFileSize,
+		githubActionsLimits.WorkflowFileBytes,
+	)
+	errs := l.postprocessErrors(path, []*Error{err}, cfg, project, NewSourceFile(path, nil))
+	return &workspace{path: path, errs: errs, project: project, cfg: cfg}
 }
 
 func (l *Linter) check(
@@ -516,10 +1007,18 @@ func (l *Linter) check(
 	proc *concurrentProcess,
 	localActions *LocalActionsCache,
 	localReusableWorkflows *LocalReusableWorkflowCache,
-) ([]*Error, error) {
+) (*workspace, error) {
 	// Note: This method is called to check multiple files in parallel.
 	// It must be thread safe assuming fields of Linter are not modified while running.
 
+	if err := l.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("linting %q was cancelled: %w", path, err)
+	}
+
+	if l.maxFileSize >= 0 && int64(len(content)) > l.maxFileSize {
+		return l.tooLargeWorkspace(path, project), nil
+	}
+
 	var start time.Time
 	if l.logLevel >= LogLevelVerbose {
 		start = time.Now()
@@ -530,120 +1029,703 @@ func (l *Linter) check(
 		l.log("Using project at", project.RootDir())
 	}
 
-	var cfg *Config
-	if l.defaultConfig != nil {
-		// `-config-file` option has higher priority than repository config file
-		cfg = l.defaultConfig
-	} else if project != nil {
-		cfg = project.Config()
-	}
+	cfg := l.config(project)
 	if cfg != nil {
 		l.debug("Config: %#v", cfg)
 	} else {
 		l.debug("No config was found")
 	}
 
-	w, all := Parse(content)
+	// The source file is shared by the parser, the rules, and the post-processing of errors so that
+	// the source is split into lines and parsed as YAML only once
+	f := NewSourceFile(path, content)
+
+	var wfs []*Workflow
+	var all []*Error
+	if l.templateMode != TemplateModeNone {
+		l.log("Neutralizing templates in", path, "with template mode", l.templateMode)
+		wfs, all = ParseDocuments(NeutralizeTemplate(content, l.templateMode))
+	} else {
+		var w *Workflow
+		w, all = ParseSourceFile(f)
+		if w != nil {
+			wfs = []*Workflow{w}
+		}
+	}
 
 	if l.logLevel >= LogLevelVerbose {
 		elapsed := time.Since(start)
 		l.log("Found", len(all), "parse errors in", elapsed.Milliseconds(), "ms for", path)
 	}
 
-	if w != nil {
-		dbg := l.debugWriter()
-
-		rules := []Rule{
-			NewRuleMatrix(),
-			NewRuleCredentials(),
-			NewRuleShellName(),
-			NewRuleRunnerLabel(),
-			NewRuleEvents(),
-			NewRuleJobNeeds(),
-			NewRuleAction(localActions),
-			NewRuleEnvVar(),
-			NewRuleID(),
-			NewRuleGlob(),
-			NewRulePermissions(),
-			NewRuleWorkflowCall(path, localReusableWorkflows),
-			NewRuleExpression(localActions, localReusableWorkflows),
-			NewRuleDeprecatedCommands(),
-			NewRuleIfCond(),
-		}
-		if l.shellcheck != "" {
-			r, err := NewRuleShellcheck(l.shellcheck, proc)
-			if err == nil {
-				rules = append(rules, r)
-			} else {
-				l.log("Rule \"shellcheck\" was disabled:", err)
-			}
-		} else {
-			l.log("Rule \"shellcheck\" was disabled since shellcheck command name was empty")
-		}
-		if l.pyflakes != "" {
-			r, err := NewRulePyflakes(l.pyflakes, proc)
-			if err == nil {
-				rules = append(rules, r)
-			} else {
-				l.log("Rule \"pyflakes\" was disabled:", err)
-			}
-		} else {
-			l.log("Rule \"pyflakes\" was disabled since pyflakes command name was empty")
-		}
-		if l.onRulesCreated != nil {
-			rules = l.onRulesCreated(rules)
-		}
-
-		v := NewVisitor()
-		for _, rule := range rules {
-			v.AddPass(rule)
-		}
-		if dbg != nil {
-			v.EnableDebug(dbg)
-			for _, r := range rules {
-				r.EnableDebug(dbg)
-			}
-		}
-		if cfg != nil {
-			for _, r := range rules {
-				r.SetConfig(cfg)
-			}
-		}
+	// Style of the source is not checked in template mode since the source was modified
+	src := f
+	if l.templateMode != TemplateModeNone {
+		src = nil
+	}
 
-		if err := v.Visit(w); err != nil {
-			l.debug("Error occurred while visiting workflow syntax tree: %v", err)
+	caps := l.capabilities(project, localActions, localReusableWorkflows)
+	pathCfgs := cfg.PathConfigs(l.projectRelPath(path, project))
+	for _, w := range wfs {
+		errs, err := l.checkWorkflow(w, path, src, cfg, pathCfgs, proc, caps)
+		if err != nil {
 			return nil, err
 		}
+		all = append(all, errs...)
+	}
 
-		for _, rule := range rules {
-			errs := rule.Errs()
-			l.debug("%s found %d errors", rule.Name(), len(errs))
-			all = append(all, errs...)
+	all = l.postprocessErrors(path, all, cfg, project, f)
+
+	if l.logLevel >= LogLevelVerbose {
+		elapsed := time.Since(start)
+		l.log("Found total", len(all), "errors in", elapsed.Milliseconds(), "ms for", path)
+	}
+
+	return &workspace{
+		path:      path,
+		errs:      all,
+		src:       content,
+		project:   project,
+		cfg:       cfg,
+		workflows: wfs,
+		caps:      caps,
+	}, nil
+}
+
+// checkAndFix checks the workflow file read from the file path. When the Fix option is enabled, it
+// applies the fixes of the errors of the rules configured with `fix: auto` to the file and checks
+// the fixed content again until no fix is applicable. The errors which could not be fixed remain in
+// the returned workspace.
+func (l *Linter) checkAndFix(
+	file string,
+	path string,
+	content []byte,
+	project *Project,
+	proc *concurrentProcess,
+	localActions *LocalActionsCache,
+	localReusableWorkflows *LocalReusableWorkflowCache,
+) (*workspace, error) {
+	// Applying some fix may produce another fixable error. Limit the number of passes not to loop
+	// infinitely on fixes conflicting with each other.
+	const maxPasses = 10
+	for i := 0; ; i++ {
+		w, err := l.check(path, content, project, proc, localActions, localReusableWorkflows)
+		if err != nil || !l.fix || w.cfg == nil || i == maxPasses {
+			return w, err
 		}
 
-		if l.errFmt != nil {
-			for _, rule := range rules {
-				l.errFmt.RegisterRule(rule)
+		ss := []*Suggestion{}
+		created := map[*Suggestion]string{}
+		for _, e := range w.errs {
+			if len(e.Suggestions) == 0 || !w.cfg.Rules.AutoFix(e.Kind) {
+				continue
+			}
+			s := e.Suggestions[0]
+			if s.NewFile != nil {
+				f, err := createSuggestionFile(project, s.NewFile)
+				if err != nil {
+					return nil, err
+				}
+				if f == "" {
+					continue
+				}
+				created[s] = f
 			}
+			ss = append(ss, s)
 		}
+		fixed, applied := applySuggestions(content, ss)
+		for _, s := range applied {
+			if f, ok := created[s]; ok {
+				l.log("Created", f, "by fix of", path)
+				delete(created, s)
+			}
+		}
+		for _, f := range created {
+			os.Remove(f) // Remove files of the fixes which were not applied
+		}
+		if len(applied) == 0 {
+			return w, nil
+		}
+		if err := os.WriteFile(file, fixed, 0644); err != nil {
+			return nil, fmt.Errorf("could not write fixes to %q: %w", file, err)
+		}
+		l.log("Applied", len(applied), "fixes to", path)
+		content = fixed
 	}
+}
 
-	all = l.filterErrors(all, cfg.PathConfigs(path))
+// createSuggestionFile creates the new file of the fix in the project and returns the path of the
+// created file. Existing files are never overwritten. It returns an empty string when the file
+// cannot be created since the project is unknown or the file already exists.
+func createSuggestionFile(project *Project, f *SuggestionFile) (string, error) {
+	if project == nil {
+		return "", nil
+	}
+	r := filepath.Clean(filepath.FromSlash(f.Path))
+	if filepath.IsAbs(r) || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", nil
+	}
+	p := filepath.Join(project.RootDir(), r)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", fmt.Errorf("could not create directory for %q: %w", p, err)
+	}
+	h, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("could not create %q: %w", p, err)
+	}
+	_, err = h.WriteString(f.Content)
+	if cerr := h.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(p)
+		return "", fmt.Errorf("could not write %q: %w", p, err)
+	}
+	return p, nil
+}
 
-	for _, err := range all {
+func (l *Linter) config(project *Project) *Config {
+	if l.defaultConfig != nil {
+		// `-config-file` option has higher priority than repository config file
+		return l.defaultConfig
+	}
+	if project != nil {
+		c := project.Config()
+		if !l.allowCfgCmds && c.hasCommands() {
+			l.log("External commands at \"script-linters\" and \"flags\" of \"shellcheck\" in config file of", project.RootDir(), "are ignored. use -allow-config-commands to run them")
+			return c.withoutCommands()
+		}
+		return c
+	}
+	return nil
+}
+
+// projectRelPath returns the path relative to the root of the project. Glob patterns in "paths"
+// config are matched to the relative path. When the path is not in the project, it returns the path
+// as-is.
+func (l *Linter) projectRelPath(path string, project *Project) string {
+	if project == nil {
+		return path
+	}
+	a := path
+	if !filepath.IsAbs(a) {
+		a = filepath.Join(l.cwd, a)
+	}
+	r, err := filepath.Rel(project.RootDir(), a)
+	if err != nil || strings.HasPrefix(r, "..") {
+		return path
+	}
+	return r
+}
+
+// postprocessErrors filters the errors found in the file at the path, populates the file path to
+// them, and sorts them by their positions.
+func (l *Linter) postprocessErrors(path string, errs []*Error, cfg *Config, project *Project, src *SourceFile) []*Error {
+	errs = l.filterErrors(errs, cfg.PathConfigs(l.projectRelPath(path, project)))
+	errs = l.filterInlineIgnores(errs, src)
+
+	for _, err := range errs {
 		err.Filepath = path // Populate filename in the error
 	}
 
-	sort.Stable(ByErrorPosition(all))
+	if l.baseline != nil {
+		errs = l.filterBaseline(errs, src.Content)
+	}
 
-	if l.logLevel >= LogLevelVerbose {
-		elapsed := time.Since(start)
-		l.log("Found total", len(all), "errors in", elapsed.Milliseconds(), "ms for", path)
+	l.columnUnit.convertColumns(errs, src.sourceLines())
+
+	errs = l.filterTimeouts(errs)
+
+	if l.futureSyntax != FutureSyntaxModeError {
+		errs = l.filterFutureSyntax(errs)
+	}
+
+	if l.categories != nil {
+		errs = l.filterCategories(errs)
+	}
+
+	sort.Stable(ByErrorPosition(errs))
+	return errs
+}
+
+// checkProjects runs rules implementing ProjectRule on the workflows in the workspaces. This is the
+// second phase of linting which runs after all workflow files were parsed and checked. The
+// workspaces are grouped by their projects and the rules check all workflows in each project. Errors
+// found by the rules are added to the workspaces. When the rules report errors in files which are not
+// checked workflows (e.g. action metadata files), new workspaces for the files are appended to the
+// returned slice.
+func (l *Linter) checkProjects(ws []workspace) ([]workspace, error) {
+	groups := map[*Project][]*workspace{}
+	projs := []*Project{}
+	for i := range ws {
+		w := &ws[i]
+		if _, ok := groups[w.project]; !ok {
+			projs = append(projs, w.project)
+		}
+		groups[w.project] = append(groups[w.project], w)
+	}
+
+	extras := []workspace{}
+	for _, p := range projs {
+		e, err := l.checkProject(p, groups[p])
+		if err != nil {
+			return nil, err
+		}
+		extras = append(extras, e...)
+	}
+	return append(ws, extras...), nil
+}
+
+func (l *Linter) checkProject(project *Project, ws []*workspace) ([]workspace, error) {
+	rules := l.projectRules(project)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var caps *Capabilities
+	files := []*ProjectFile{}
+	paths := make(map[string]*workspace, len(ws))
+	for _, w := range ws {
+		if caps == nil {
+			caps = w.caps
+		}
+		paths[w.path] = w
+		for _, wf := range w.workflows {
+			files = append(files, &ProjectFile{w.path, wf})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	if project != nil {
+		l.log("Checking", len(files), "workflows in project", project.RootDir(), "with", len(rules), "project rules")
+	}
+
+	dbg := l.debugWriter()
+	cfg := l.config(project)
+	if caps == nil {
+		caps = l.capabilities(project, nil, nil)
+	}
+	var mu sync.Mutex
+	panics := []*Error{}
+	eg := errgroup.Group{}
+	for _, r := range rules {
+		setRuleCapabilities(r, caps)
+		if dbg != nil {
+			r.EnableDebug(dbg)
+		}
+		if cfg != nil {
+			r.SetConfig(cfg)
+		}
+		r := r
+		eg.Go(func() error {
+			if !l.strictInternal && len(files) > 0 {
+				defer func() {
+					if v := recover(); v != nil {
+						err := l.rulePanicError(r.Name(), &Pos{Line: 1, Col: 1}, v)
+						err.Filepath = files[0].Path
+						mu.Lock()
+						panics = append(panics, err)
+						mu.Unlock()
+					}
+				}()
+			}
+			return r.VisitProject(files)
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		l.debug("Error occurred while checking workflows in project: %v", err)
+		return nil, err
+	}
+
+	found := map[*workspace][]*Error{}
+	extras := []*workspace{}
+	for _, r := range rules {
+		errs := r.Errs()
+		l.debug("%s found %d errors in project", r.Name(), len(errs))
+		for _, err := range errs {
+			w, ok := paths[err.Filepath]
+			if !ok {
+				w = l.newExtraWorkspace(err.Filepath, project)
+				if w == nil {
+					l.debug("Error %q is ignored since file %q is not checked", err.Message, err.Filepath)
+					continue
+				}
+				paths[err.Filepath] = w
+				extras = append(extras, w)
+			}
+			found[w] = append(found[w], err)
+		}
+		if l.errFmt != nil {
+			l.errFmt.RegisterRule(r)
+		}
+	}
+	for _, err := range panics {
+		w := paths[err.Filepath]
+		found[w] = append(found[w], err)
+	}
+
+	for w, errs := range found {
+		w.errs = append(w.errs, l.postprocessErrors(w.path, errs, w.cfg, w.project, NewSourceFile(w.path, w.src))...)
+		sort.Stable(ByErrorPosition(w.errs))
+	}
+
+	ret := make([]workspace, 0, len(extras))
+	for _, w := range extras {
+		ret = append(ret, *w)
+	}
+	return ret, nil
+}
+
+// checkCompositeActions checks steps of local composite actions used by the checked workflows with
+// the rules which check steps of workflows. Each action is checked only once even if multiple
+// workflows use it. Errors in the action metadata files are added to their workspaces. Workspaces
+// are appended to the returned slice when the files are not in the given workspaces yet.
+func (l *Linter) checkCompositeActions(ws []workspace, caches []*LocalActionsCache, proc *concurrentProcess) ([]workspace, error) {
+	paths := make(map[string]int, len(ws))
+	for i := range ws {
+		paths[ws[i].path] = i
+	}
+
+	for _, c := range caches {
+		for _, meta := range c.compositeActions() {
+			src, err := c.readFile(meta.Path())
+			if err != nil {
+				l.debug("Could not read composite action metadata %q: %v", meta.Path(), err)
+				continue
+			}
+			w, errs := parseCompositeAction(src)
+			if w == nil {
+				continue
+			}
+			f := NewSourceFile(meta.Path(), src)
+
+			path := meta.Path()
+			if l.cwd != "" {
+				if r, err := filepath.Rel(l.cwd, path); err == nil {
+					path = r
+				}
+			}
+			l.log("Checking steps of composite action", path)
+
+			cfg := l.config(c.proj)
+			pathCfgs := cfg.PathConfigs(l.projectRelPath(path, c.proj))
+			rules := []Rule{
+				NewRuleExpression(),
+				NewRuleCredentials(),
+				NewRuleDeprecatedCommands(),
+			}
+			var lines sourceLines
+			if l.shellcheck != "" {
+				lines = f.sourceLines()
+			}
+			if r := l.newRuleShellcheck(pathCfgs, lines, proc); r != nil {
+				rules = append(rules, r)
+			}
+
+			found, err := l.visitWorkflow(w, path, rules, cfg, l.capabilities(c.proj, c, nil))
+			if err != nil {
+				return nil, fmt.Errorf("fatal error while checking composite action %s: %w", path, err)
+			}
+			errs = l.postprocessErrors(path, append(errs, found...), cfg, c.proj, f)
+
+			if i, ok := paths[path]; ok {
+				ws[i].errs = append(ws[i].errs, errs...)
+				sort.Stable(ByErrorPosition(ws[i].errs))
+				continue
+			}
+			paths[path] = len(ws)
+			ws = append(ws, workspace{path: path, errs: errs, src: src, project: c.proj, cfg: cfg})
+		}
+	}
+
+	return ws, nil
+}
+
+// newExtraWorkspace creates a workspace for the file in the project which is not a checked workflow
+// file but where some project rule reported an error. For example, an action metadata file. It
+// returns nil when the file is not in the project or cannot be read.
+func (l *Linter) newExtraWorkspace(path string, project *Project) *workspace {
+	if project == nil {
+		return nil
+	}
+	p := path
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(l.cwd, p)
+	}
+	if !project.Knows(p) {
+		return nil
+	}
+	src, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+	return &workspace{path: path, src: src, project: project, cfg: l.config(project)}
+}
+
+// capabilities creates services which rules can use. Local actions and local reusable workflows are
+// not available when the caches are nil. The Git repository is not available when the project is nil.
+func (l *Linter) capabilities(project *Project, localActions *LocalActionsCache, localReusableWorkflows *LocalReusableWorkflowCache) *Capabilities {
+	s := &Capabilities{
+		LocalReusableWorkflows:  localReusableWorkflows,
+		PopularActions:          PopularActions,
+		Remote:                  l.remote,
+		RemoteReusableWorkflows: l.remoteWorkflow,
+		Matrix:                  defaultMatrixExpander{},
+	}
+	if localActions != nil {
+		s.LocalActions = localActions // Avoid typed nil in the interface field
+	}
+	if project != nil {
+		s.Git = &gitIndex{project.RootDir()}
+	}
+	if l.onCapsCreated != nil {
+		s = l.onCapsCreated(s)
+	}
+	return s
+}
+
+// projectRules creates rules which run in the second phase of linting. The project parameter is the
+// project which the checked workflows belong to. It can be nil.
+func (l *Linter) projectRules(project *Project) []ProjectRule {
+	rules := []Rule{
+		NewRuleWorkflowName(),
+		NewRuleUnused(project, l.cwd),
+		NewRuleWorkflowRun(project, l.cwd),
+		NewRuleSetupCache(project),
+		NewRuleRelease(),
+		NewRuleGitPush(),
+		NewRuleMarketplace(project, l.cwd),
+	}
+	if l.onRulesCreated != nil {
+		rules = l.onRulesCreated(rules)
+	}
+
+	ret := make([]ProjectRule, 0, len(rules))
+	for _, r := range rules {
+		if !l.ruleEnabled(r) {
+			continue
+		}
+		if p, ok := r.(ProjectRule); ok {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+func (l *Linter) checkWorkflow(
+	w *Workflow,
+	path string,
+	src *SourceFile,
+	cfg *Config,
+	pathCfgs []PathConfig,
+	proc *concurrentProcess,
+	caps *Capabilities,
+) ([]*Error, error) {
+	rules := []Rule{
+		NewRuleMatrix(),
+		NewRuleCredentials(),
+		NewRuleShellName(),
+		NewRuleRunnerLabel(),
+		NewRuleEvents(),
+		NewRuleJobNeeds(),
+		NewRuleAction(),
+		NewRuleEnvVar(),
+		NewRuleID(),
+		NewRuleGlob(),
+		NewRulePermissions(),
+		NewRuleWorkflowCall(path),
+		NewRuleExpression(),
+		NewRuleDeprecatedCommands(),
+		NewRuleIfCond(),
+		NewRuleYAMLAnchor(),
+		NewRuleStyle(src),
+		NewRuleSchedule(src),
+		NewRuleLimits(src),
+		NewRuleCheckout(),
+		NewRulePathFilter(),
+		NewRuleStepName(),
+		NewRuleRunScript(src),
+		NewRuleRunnerTools(),
+		NewRuleFailureHandling(),
+		NewRuleSecretOutput(),
+		NewRuleServices(),
+		NewRuleEnvFile(src),
+	}
+	// Lines of the source to report errors of external commands at precise positions in scripts
+	var lines sourceLines
+	if src != nil && (l.shellcheck != "" || l.pyflakes != "" || cfg != nil && len(cfg.ScriptLinters) > 0) {
+		lines = src.sourceLines()
+	}
+	if r := l.newRuleShellcheck(pathCfgs, lines, proc); r != nil {
+		rules = append(rules, r)
+	}
+	if l.pyflakes != "" {
+		r, err := NewRulePyflakes(l.pyflakes, proc)
+		if err != nil && l.pyflakes == "pyflakes" {
+			// Fall back to the alternative linters when pyflakes is not installed
+			for _, p := range pythonLinters[1:] {
+				if f, e := NewRulePyflakes(p.name, proc); e == nil {
+					l.log("Rule \"pyflakes\" uses", p.name, "since pyflakes was not found:", err)
+					r, err = f, nil
+					break
+				}
+			}
+		}
+		if err == nil {
+			r.lines = lines
+			rules = append(rules, r)
+		} else {
+			l.log("Rule \"pyflakes\" was disabled:", err)
+		}
+	} else {
+		l.log("Rule \"pyflakes\" was disabled since pyflakes command name was empty")
+	}
+	if cfg != nil && len(cfg.ScriptLinters) > 0 {
+		r, err := NewRuleScriptLinter(cfg.ScriptLinters, proc)
+		if err == nil {
+			r.lines = lines
+			rules = append(rules, r)
+		} else {
+			l.log("Rule \"script-linter\" was disabled:", err)
+		}
+	}
+	if l.remote != nil {
+		rules = append(rules, NewRuleRemote())
+	}
+	if l.onRulesCreated != nil {
+		rules = l.onRulesCreated(rules)
+		// Rules implementing ProjectRule run in the second phase
+		filtered := make([]Rule, 0, len(rules))
+		for _, r := range rules {
+			if _, ok := r.(ProjectRule); !ok {
+				filtered = append(filtered, r)
+			}
+		}
+		rules = filtered
+	}
+	if l.onlyRules != nil {
+		filtered := make([]Rule, 0, len(rules))
+		for _, r := range rules {
+			if l.ruleEnabled(r) {
+				filtered = append(filtered, r)
+			}
+		}
+		rules = filtered
+	}
+
+	return l.visitWorkflow(w, path, rules, cfg, caps)
+}
+
+// ruleEnabled returns true when the rule is selected by OnlyRules option.
+func (l *Linter) ruleEnabled(r Rule) bool {
+	if l.onlyRules == nil {
+		return true
+	}
+	_, ok := l.onlyRules[r.Name()]
+	return ok
+}
+
+// newRuleShellcheck creates the "shellcheck" rule for the file. It returns nil when the rule is
+// disabled.
+func (l *Linter) newRuleShellcheck(pathCfgs []PathConfig, lines sourceLines, proc *concurrentProcess) *RuleShellcheck {
+	if l.shellcheck == "" {
+		l.log("Rule \"shellcheck\" was disabled since shellcheck command name was empty")
+		return nil
+	}
+	r, err := NewRuleShellcheck(l.shellcheck, proc)
+	if err != nil {
+		l.log("Rule \"shellcheck\" was disabled:", err)
+		return nil
+	}
+	r.paths = pathCfgs
+	r.lines = lines
+	return r
+}
+
+// visitWorkflow runs the rules on the workflow syntax tree and returns errors found by them.
+func (l *Linter) visitWorkflow(w *Workflow, path string, rules []Rule, cfg *Config, caps *Capabilities) ([]*Error, error) {
+	dbg := l.debugWriter()
+	v := NewVisitor()
+	for _, rule := range rules {
+		v.AddPass(rule)
+	}
+	if dbg != nil {
+		v.EnableDebug(dbg)
+		for _, r := range rules {
+			r.EnableDebug(dbg)
+		}
+	}
+	for _, r := range rules {
+		setRuleCapabilities(r, caps)
+	}
+	if cfg != nil {
+		for _, r := range rules {
+			r.SetConfig(cfg)
+		}
+	}
+
+	all := []*Error{}
+	if !l.strictInternal {
+		v.OnPanic(func(p Pass, pos *Pos, v interface{}) {
+			all = append(all, l.rulePanicError(p.(Rule).Name(), pos, v))
+		})
+	}
+
+	if l.fileTimeout > 0 {
+		v.SetDeadline(time.Now().Add(l.fileTimeout))
+	}
+
+	if l.onlyJobs != nil {
+		v.SetJobFilter(func(n *Job) bool {
+			_, ok := l.onlyJobs[strings.ToLower(n.ID.Value)]
+			return ok
+		})
+	}
+
+	if err := v.Visit(w); err != nil {
+		if err != context.DeadlineExceeded {
+			l.debug("Error occurred while visiting workflow syntax tree: %v", err)
+			return nil, err
+		}
+		l.debug("Checking workflow %q was aborted due to timeout %s", path, l.fileTimeout)
+		all = append(all, errorfAt(&Pos{Line: 1, Col: 1}, "timeout", "checking this file did not finish within %s. the remaining checks were skipped. increase the timeout with -file-timeout option", l.fileTimeout))
+	}
+
+	for _, rule := range rules {
+		errs := rule.Errs()
+		l.debug("%s found %d errors", rule.Name(), len(errs))
+		all = append(all, errs...)
+	}
+
+	if l.errFmt != nil {
+		for _, rule := range rules {
+			l.errFmt.RegisterRule(rule)
+		}
 	}
 
 	return all, nil
 }
 
+// newProcess creates a new concurrentProcess instance to run external processes of rules in parallel.
+func (l *Linter) newProcess(par int) *concurrentProcess {
+	p := newConcurrentProcess(par)
+	p.ctx = l.ctx
+	p.timeout = l.processTimeout
+	return p
+}
+
+// rulePanicError creates an error to report the panic in the rule. It is reported as an error of the
+// rule instead of crashing the process so that other rules and files can be checked.
+func (l *Linter) rulePanicError(rule string, pos *Pos, v interface{}) *Error {
+	l.debug("Rule %q panicked: %v\n%s", rule, v, debug.Stack())
+	return errorfAt(pos, rule, "internal error in rule %q: %v. this is a bug of actionlint. please report it at https://github.com/rhysd/actionlint/issues with the workflow", rule, v)
+}
+
 func (l *Linter) filterErrors(errs []*Error, cfgs []PathConfig) []*Error {
 	if len(l.ignorePats) == 0 && len(cfgs) == 0 {
 		return errs
@@ -670,11 +1752,136 @@ Loop:
 	return filtered
 }
 
+var reInlineIgnore = regexp.MustCompile(`#\s*actionlint-ignore:\s*(\S.*?)\s*$`)
+
+// parseInlineIgnores parses "# actionlint-ignore: {pattern}" comments in the source. The pattern is
+// the same as -ignore option. A comment at end of line ignores errors at the line and a comment in
+// its own line ignores errors at the next line. It returns patterns for each line number. Invalid
+// patterns are returned as errors.
+func parseInlineIgnores(src *SourceFile) (map[int]IgnorePatterns, []*Error) {
+	if !bytes.Contains(src.Content, []byte("actionlint-ignore:")) {
+		return nil, nil
+	}
+	ret := map[int]IgnorePatterns{}
+	var errs []*Error
+	for i, l := range src.Lines() {
+		m := reInlineIgnore.FindStringSubmatchIndex(l)
+		if m == nil {
+			continue
+		}
+		p, err := ParseIgnorePattern(l[m[2]:m[3]])
+		if err != nil {
+			errs = append(errs, errorfAt(&Pos{Line: i + 1, Col: m[2] + 1}, "syntax-check", "invalid pattern in \"actionlint-ignore\" comment: %s", err))
+			continue
+		}
+		line := i + 1 // Comment at end of line ignores errors at the line
+		if strings.TrimSpace(l[:m[0]]) == "" {
+			line++ // Comment in its own line ignores errors at the next line
+		}
+		ret[line] = append(ret[line], p)
+	}
+	return ret, errs
+}
+
+func (l *Linter) filterInlineIgnores(errs []*Error, src *SourceFile) []*Error {
+	pats, invalid := parseInlineIgnores(src)
+	if len(pats) == 0 {
+		return append(errs, invalid...)
+	}
+	filtered := make([]*Error, 0, len(errs)+len(invalid))
+	for _, err := range errs {
+		if pats[err.Line].Match(err) {
+			l.debug("Error %q is ignored due to \"actionlint-ignore\" comment", err.Message)
+			continue
+		}
+		filtered = append(filtered, err)
+	}
+	return append(filtered, invalid...)
+}
+
+func (l *Linter) filterBaseline(errs []*Error, src []byte) []*Error {
+	filtered := make([]*Error, 0, len(errs))
+	for _, err := range errs {
+		if l.baseline.MatchWithSource(err, src) {
+			l.debug("Error %q is ignored since it is recorded in the baseline", err.Message)
+			continue
+		}
+		filtered = append(filtered, err)
+	}
+	if len(filtered) != len(errs) {
+		l.log("Filtered", len(errs)-len(filtered), "error(s) recorded in the baseline")
+	}
+	return filtered
+}
+
+// filterCategories removes errors whose categories are not specified with -only-categories option.
+func (l *Linter) filterCategories(errs []*Error) []*Error {
+	filtered := make([]*Error, 0, len(errs))
+	for _, err := range errs {
+		if _, ok := l.categories[err.Category()]; ok {
+			filtered = append(filtered, err)
+		}
+	}
+	if len(filtered) != len(errs) {
+		l.log("Filtered", len(errs)-len(filtered), "error(s) due to \"-only-categories\" command line option")
+	}
+	return filtered
+}
+
+// filterTimeouts removes the errors of "timeout" kind and prints them as warnings to the log output.
+// A timeout should not fail the check since it is not a problem of the workflow.
+func (l *Linter) filterTimeouts(errs []*Error) []*Error {
+	filtered := make([]*Error, 0, len(errs))
+	for _, err := range errs {
+		if err.Kind != "timeout" {
+			filtered = append(filtered, err)
+			continue
+		}
+		l.warn(err)
+	}
+	return filtered
+}
+
+// warn prints the error as a warning to the log output.
+func (l *Linter) warn(err *Error) {
+	fmt.Fprintf(l.logOut, "warning: %s:%d:%d: %s [%s]\n", err.Filepath, err.Line, err.Column, err.LocalizedMessage(l.locale), err.Kind)
+}
+
+// filterFutureSyntax removes errors of keys in future workflow syntax. They are printed as warnings
+// to the log output in FutureSyntaxModeWarn.
+func (l *Linter) filterFutureSyntax(errs []*Error) []*Error {
+	filtered := make([]*Error, 0, len(errs))
+	for _, err := range errs {
+		if err.Kind != "future-syntax" {
+			filtered = append(filtered, err)
+			continue
+		}
+		if l.futureSyntax == FutureSyntaxModeWarn {
+			l.warn(err)
+		} else {
+			l.debug("Error %q is ignored due to -future-syntax=ignore", err.Message)
+		}
+	}
+	return filtered
+}
+
 func (l *Linter) printErrors(errs []*Error, src []byte) {
 	if l.oneline {
 		src = nil
 	}
 	for _, err := range errs {
-		err.PrettyPrint(l.out, src)
+		if msg := err.LocalizedMessage(l.locale); msg != err.Message {
+			e := *err
+			e.Message = msg
+			err = &e
+		}
+		err.prettyPrint(l.out, src, l.contextLines, l.columnUnit)
 	}
 }
+
+// templateFields returns the fields to format the error with the error message in the locale.
+func (l *Linter) templateFields(err *Error, src []byte) *ErrorTemplateFields {
+	f := err.templateFields(src, l.columnUnit)
+	f.Message = err.LocalizedMessage(l.locale)
+	return f
+}
@@ -1,8 +1,10 @@
 package actionlint
 
 import (
+	"archive/zip"
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,7 +15,9 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sys/execabs"
@@ -212,6 +216,590 @@ func TestLinterLintError(t *testing.T) {
 	}
 }
 
+// Positions and snippets of errors must not change when the workflow file uses CRLF line breaks,
+// which is common on Windows.
+func TestLinterLintErrorCRLF(t *testing.T) {
+	lines := func(errs []*Error, src []byte) []string {
+		ret := make([]string, 0, len(errs))
+		for _, err := range errs {
+			f := err.GetTemplateFields(src)
+			ret = append(ret, fmt.Sprintf("%d:%d-%d: %s [%s]\n%s", f.Line, f.Column, f.EndColumn, f.Message, f.Kind, f.Snippet))
+		}
+		return ret
+	}
+
+	for _, subdir := range []string{"examples", "err"} {
+		dir, infiles, err := testFindAllWorkflowsInDir(subdir)
+		if err != nil {
+			panic(err)
+		}
+		proj := &Project{root: dir}
+
+		for _, infile := range infiles {
+			testName := strings.TrimSuffix(filepath.Base(infile), filepath.Ext(infile))
+			t.Run(subdir+"/"+testName, func(t *testing.T) {
+				lf, err := os.ReadFile(infile)
+				if err != nil {
+					panic(err)
+				}
+				crlf := bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+
+				l, err := NewLinter(io.Discard, &LinterOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				l.defaultConfig = &Config{}
+
+				want, err := l.Lint("test.yaml", lf, proj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				have, err := l.Lint("test.yaml", crlf, proj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(lines(want, lf), lines(have, crlf)); diff != "" {
+					t.Fatal(diff)
+				}
+			})
+		}
+	}
+}
+
+func TestLinterLintBrokenJobsCRLF(t *testing.T) {
+	src := "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n\n    steps:\n      - run: echo ${{ github.foo }}\n\n  b:\n    runs-on: ubuntu-latest\n    steps:\n      - run: [\n"
+	src = strings.ReplaceAll(src, "\n", "\r\n")
+
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("wanted 2 errors but got %d: %v", len(errs), errs)
+	}
+	if errs[0].Line != 7 || errs[0].Column != 23 || errs[0].Kind != "expression" {
+		t.Errorf("error in the intact job was not reported correctly: %v", errs[0])
+	}
+	if errs[1].Line != 12 || errs[1].Kind != "syntax-check" {
+		t.Errorf("syntax error was not reported correctly: %v", errs[1])
+	}
+}
+
+func TestLinterInlineIgnoreCRLF(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      # actionlint-ignore: rule:expression\n      - run: echo ${{ github.foo }}\n      - run: echo ${{ github.bar }} # actionlint-ignore: rule:expression\n      - run: echo ${{ github.baz }}\n"
+	src = strings.ReplaceAll(src, "\n", "\r\n")
+
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Line != 9 || errs[0].Column != 23 {
+		t.Fatalf("only the error at line 9 should be reported but got %v", errs)
+	}
+}
+
+func TestLinterColumnUnit(t *testing.T) {
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo '日本語🐶' ${{ foo }}\n")
+	for _, tc := range []struct {
+		unit  ColumnUnit
+		col   int
+		end   int
+		print string
+	}{
+		{ColumnUnitRune, 30, 32, "test.yaml:6:30:"},
+		{ColumnUnitByte, 39, 41, "test.yaml:6:39:"},
+		{ColumnUnitUTF16, 31, 33, "test.yaml:6:31:"},
+	} {
+		t.Run(string(tc.unit), func(t *testing.T) {
+			var b strings.Builder
+			l, err := NewLinter(&b, &LinterOptions{ColumnUnit: tc.unit})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{}
+
+			errs, err := l.Lint("test.yaml", src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d: %v", len(errs), errs)
+			}
+			f := errs[0].templateFields(src, tc.unit)
+			if f.Column != tc.col || f.EndColumn != tc.end {
+				t.Errorf("wanted columns %d-%d but got %d-%d", tc.col, tc.end, f.Column, f.EndColumn)
+			}
+
+			out := b.String()
+			if !strings.HasPrefix(out, tc.print) {
+				t.Errorf("output should start with %q: %q", tc.print, out)
+			}
+			// Indicator is put at the same place regardless of the unit
+			if !strings.Contains(out, "\n  |                                  ^~~\n") {
+				t.Errorf("indicator is not put under the expression: %q", out)
+			}
+		})
+	}
+
+	if _, err := NewLinter(io.Discard, &LinterOptions{ColumnUnit: "foo"}); err == nil || !strings.Contains(err.Error(), "unknown column unit") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestLinterProcessTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake shellcheck script does not work on Windows")
+	}
+	if _, err := execabs.LookPath("sleep"); err != nil {
+		t.Skip("sleep command is necessary to run this test:", err)
+	}
+
+	exe := filepath.Join(t.TempDir(), "shellcheck")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\nexec sleep 10\n"), 0755); err != nil {
+		panic(err)
+	}
+
+	var log strings.Builder
+	l, err := NewLinter(io.Discard, &LinterOptions{
+		Shellcheck:     exe,
+		ProcessTimeout: 100 * time.Millisecond,
+		LogWriter:      &log,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n")
+	start := time.Now()
+	errs, err := l.Lint("test.yaml", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Fatal("timeout should not be reported as error:", errs)
+	}
+	if sec := time.Since(start).Seconds(); sec >= 5 {
+		t.Fatalf("shellcheck process was not killed on timeout. it took %v seconds", sec)
+	}
+	out := log.String()
+	for _, want := range []string{"warning: test.yaml:6:9: ", "did not finish within 100ms and was killed", `the check by "shellcheck" rule was skipped`, "[timeout]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output does not contain %q: %q", want, out)
+		}
+	}
+}
+
+func TestLinterCancelKillsProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake shellcheck script does not work on Windows")
+	}
+	if _, err := execabs.LookPath("sleep"); err != nil {
+		t.Skip("sleep command is necessary to run this test:", err)
+	}
+
+	// The fake shellcheck spawns a child process which would be orphaned unless the process group is killed
+	exe := filepath.Join(t.TempDir(), "shellcheck")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\nsleep 10 &\nsleep 10\n"), 0755); err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l, err := NewLinter(io.Discard, &LinterOptions{
+		Shellcheck: exe,
+		Context:    ctx,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n")
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = l.Lint("test.yaml", src, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("cancellation error was expected but got", err)
+	}
+	if sec := time.Since(start).Seconds(); sec >= 5 {
+		t.Fatalf("shellcheck process was not killed on cancellation. it took %v seconds", sec)
+	}
+
+	if _, err := l.Lint("test.yaml", src, nil); !errors.Is(err, context.Canceled) {
+		t.Fatal("linting should fail after cancellation but got", err)
+	}
+}
+
+func TestLinterMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.yaml")
+	large := filepath.Join(dir, "large.yaml")
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+	if err := os.WriteFile(small, []byte(src), 0644); err != nil {
+		panic(err)
+	}
+	// The large file has an error which would be reported if it was checked
+	big := src + strings.Repeat("      - run: echo ${{ foo }}\n", 10)
+	if err := os.WriteFile(large, []byte(big), 0644); err != nil {
+		panic(err)
+	}
+
+	newLinter := func(max int64) *Linter {
+		l, err := NewLinter(io.Discard, &LinterOptions{MaxFileSize: max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.defaultConfig = &Config{}
+		return l
+	}
+
+	check := func(what string, errs []*Error, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(what, err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("%s: wanted one error but got %v", what, errs)
+		}
+		e := errs[0]
+		if e.Kind != "limits" || e.Line != 1 || e.Column != 1 || !strings.Contains(e.Message, "was not checked since its size exceeds the maximum file size 128 bytes") {
+			t.Errorf("%s: unexpected error: %s", what, e)
+		}
+	}
+
+	l := newLinter(128)
+	errs, err := l.LintFile(large, nil)
+	check("LintFile", errs, err)
+	errs, err = l.LintFiles([]string{small, large}, nil)
+	check("LintFiles", errs, err)
+	errs, err = l.LintStdin(strings.NewReader(big))
+	check("LintStdin", errs, err)
+	errs, err = l.Lint("test.yaml", []byte(big), nil)
+	check("Lint", errs, err)
+
+	errs, err = l.LintFile(small, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Fatal("file within the maximum size should be checked:", errs)
+	}
+
+	for _, max := range []int64{0, -1} {
+		errs, err := newLinter(max).LintFile(large, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) == 0 || errs[0].Kind != "expression" {
+			t.Errorf("file should be checked with max size %d but got %v", max, errs)
+		}
+	}
+}
+
+func TestLinterShellcheckConfigAndDirectives(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake shellcheck script does not work on Windows")
+	}
+
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args.txt")
+	script := filepath.Join(dir, "script.sh")
+	exe := filepath.Join(dir, "shellcheck")
+	fake := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %q\ncat > %q\necho '[{\"line\":4,\"column\":6,\"level\":\"info\",\"code\":2086,\"message\":\"Double quote to prevent globbing and word splitting.\"}]'\n", args, script)
+	if err := os.WriteFile(exe, []byte(fake), 0755); err != nil {
+		panic(err)
+	}
+
+	l, err := NewLinter(io.Discard, &LinterOptions{Shellcheck: exe})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{
+		Rules: RulesConfig{
+			Shellcheck: ShellcheckRuleConfig{Flags: []string{"-S", "warning"}, Exclude: []string{"SC2086"}},
+		},
+		Paths: map[string]PathConfig{
+			"test.yaml":  {Shellcheck: ShellcheckRuleConfig{Flags: []string{"-o", "all"}}},
+			"other.yaml": {Shellcheck: ShellcheckRuleConfig{Flags: []string{"-o", "none"}}},
+		},
+	}
+
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: |\n          # shellcheck disable=SC2046\n          echo $(hi)\n          echo $FOO\n")
+	errs, err := l.Lint("test.yaml", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(b), " -e SC1091,SC2194,SC2050,SC2154,SC2157,SC2043,SC2086 -S warning -o all -\n"; !strings.HasSuffix(have, want) {
+		t.Errorf("wanted arguments to end with %q but got %q", want, have)
+	}
+
+	b, err = os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(b), "# shellcheck disable=SC2046\nset -eo pipefail\necho $(hi)\necho $FOO\n\n"; have != want {
+		t.Errorf("wanted script %q but got %q", want, have)
+	}
+
+	if len(errs) != 1 {
+		t.Fatal("wanted one error but got", errs)
+	}
+	if want := "SC2086:info:3:6:"; !strings.Contains(errs[0].Message, want) {
+		t.Errorf("line in the script is not mapped correctly. wanted %q in %q", want, errs[0].Message)
+	}
+	if errs[0].Line != 9 || errs[0].Column != 16 {
+		t.Errorf("error is not reported at the position in the script: %d:%d", errs[0].Line, errs[0].Column)
+	}
+}
+
+func TestLinterFileTimeout(t *testing.T) {
+	var log strings.Builder
+	l, err := NewLinter(io.Discard, &LinterOptions{
+		FileTimeout: time.Nanosecond,
+		LogWriter:   &log,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ foo }}\n")
+	errs, err := l.Lint("test.yaml", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Fatal("checks after timeout should be skipped:", errs)
+	}
+	want := "warning: test.yaml:1:1: checking this file did not finish within 1ns. the remaining checks were skipped. increase the timeout with -file-timeout option [timeout]\n"
+	if have := log.String(); have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+}
+
+func TestLinterFixAuto(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "actionlint.yaml")
+	if err := os.WriteFile(cfg, []byte("rules:\n  style:\n    trailing-spaces: true\n    fix: auto\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := "on: push   \njobs:\n  test:\n    runs-on: ubuntu-latest\n    if: ${{ github.event_name }} == 'push'\n    steps:\n      - run: echo hi  \n"
+	paths := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")}
+
+	for _, fix := range []bool{false, true} {
+		for _, p := range paths {
+			if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		l, err := NewLinter(io.Discard, &LinterOptions{ConfigFile: cfg, Fix: fix})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, ps := range [][]string{paths[:1], paths} {
+			errs, err := l.LintFiles(ps, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kinds := []string{}
+			for _, e := range errs {
+				kinds = append(kinds, e.Kind)
+			}
+			want := []string{"style", "if-cond", "style"}
+			if fix {
+				// "if-cond" rule is not configured with `fix: auto`
+				want = []string{"if-cond"}
+			}
+			if len(ps) > 1 {
+				want = append(want, want...)
+			}
+			if diff := cmp.Diff(want, kinds); diff != "" {
+				t.Errorf("errors mismatch with fix=%v on %d files: %s", fix, len(ps), diff)
+			}
+		}
+
+		for _, p := range paths {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := src
+			if fix {
+				want = "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    if: ${{ github.event_name }} == 'push'\n    steps:\n      - run: echo hi\n"
+			}
+			if string(b) != want {
+				t.Errorf("wanted content of %q with fix=%v to be %q but got %q", p, fix, want, b)
+			}
+		}
+	}
+}
+
+func TestLinterFixAutoExtractScript(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - id: build\n        run: |\n          make ${{ github.ref_name }}\n          make test\n          make install\n"
+	fixed := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - id: build\n        run: bash ./.github/scripts/test-build.sh\n        env:\n          REF_NAME: ${{ github.ref_name }}\n"
+	script := "#!/usr/bin/env bash\n\nmake ${REF_NAME}\nmake test\nmake install\n"
+
+	for _, exists := range []bool{false, true} {
+		t.Run(fmt.Sprintf("exists=%v", exists), func(t *testing.T) {
+			root := t.TempDir()
+			for _, d := range []string{".git", filepath.Join(".github", "workflows")} {
+				if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := "rules:\n  run-script:\n    max-lines: 2\n    fix: auto\n"
+			if err := os.WriteFile(filepath.Join(root, ".github", "actionlint.yaml"), []byte(cfg), 0644); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(root, ".github", "workflows", "test.yaml")
+			if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dst := filepath.Join(root, ".github", "scripts", "test-build.sh")
+			if exists {
+				if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(dst, []byte("existing"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			l, err := NewLinter(io.Discard, &LinterOptions{Fix: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs, err := l.LintFiles([]string{path}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantSrc, wantScript, wantErrs := fixed, script, 0
+			if exists {
+				// The existing file is not overwritten and the error remains
+				wantSrc, wantScript, wantErrs = src, "existing", 1
+			}
+			if len(errs) != wantErrs {
+				t.Fatalf("wanted %d errors but got %v", wantErrs, errs)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != wantSrc {
+				t.Errorf("wanted workflow %q but got %q", wantSrc, b)
+			}
+			b, err = os.ReadFile(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != wantScript {
+				t.Errorf("wanted script %q but got %q", wantScript, b)
+			}
+		})
+	}
+}
+
+func TestLinterOnlyRulesAndJobs(t *testing.T) {
+	src := []byte(`on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ foo.bar }}
+  test:
+    needs: build
+    runs-on: ubuntu-lates
+    steps:
+      - run: echo ${{ matrix.x }}
+  deploy:
+    needs: [test, nope]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.test.outputs.foo }}
+`)
+
+	testCases := []struct {
+		what  string
+		rules []string
+		jobs  []string
+		want  []string
+		err   string
+	}{
+		{
+			what: "no filter",
+			want: []string{"6:23:expression", "9:14:runner-label", "11:23:expression", "12:3:job-needs", "16:23:expression"},
+		},
+		{
+			what:  "rules",
+			rules: []string{"runner-label", "job-needs"},
+			want:  []string{"9:14:runner-label", "12:3:job-needs"},
+		},
+		{
+			what: "jobs",
+			jobs: []string{"TEST", "deploy"},
+			want: []string{"9:14:runner-label", "11:23:expression", "12:3:job-needs", "16:23:expression"},
+		},
+		{
+			what: "jobs without depended job",
+			jobs: []string{"test"},
+			want: []string{"9:14:runner-label", "11:23:expression"},
+		},
+		{
+			what:  "rules and jobs",
+			rules: []string{"expression"},
+			jobs:  []string{"build", "deploy"},
+			want:  []string{"6:23:expression", "16:23:expression"},
+		},
+		{
+			what: "unknown job",
+			jobs: []string{"build", "Unknown", "nope"},
+			err:  `unknown job "Unknown", "nope" at -job option. available jobs in the checked workflows are "build", "deploy", "test"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			l, err := NewLinter(io.Discard, &LinterOptions{OnlyRules: tc.rules, OnlyJobs: tc.jobs})
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs, err := l.Lint("test.yaml", src, nil)
+			if tc.err != "" {
+				var unknown *UnknownJobsError
+				if !errors.As(err, &unknown) || err.Error() != tc.err {
+					t.Fatalf("wanted error %q but got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			have := []string{}
+			for _, e := range errs {
+				have = append(have, fmt.Sprintf("%d:%d:%s", e.Line, e.Column, e.Kind))
+			}
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
 func TestLinterLintAllErrorWorkflowsAtOnce(t *testing.T) {
 	shellcheck, err := execabs.LookPath("shellcheck")
 	if err != nil {
@@ -307,6 +895,50 @@ func TestLintFindProjectFromPath(t *testing.T) {
 	}
 }
 
+// testNewProjectLinter creates a linter to lint the project in testdata/projects. The working
+// directory is set to the project directory and actionlint.yaml in the directory is used as config.
+func testNewProjectLinter(t *testing.T, repo string) *Linter {
+	t.Helper()
+	opts := LinterOptions{
+		WorkingDir: repo,
+	}
+	cfg := filepath.Join(repo, "actionlint.yaml")
+	if _, err := os.Stat(cfg); err == nil {
+		opts.ConfigFile = cfg
+	}
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// testProjectWorkflowsDir returns the directory of workflows in the project in testdata/projects.
+// Projects which check files in the layout of actual repositories put workflows in .github/workflows
+// instead of workflows.
+func testProjectWorkflowsDir(repo string) string {
+	d := filepath.Join(repo, "workflows")
+	if _, err := os.Stat(d); err == nil {
+		return d
+	}
+	return filepath.Join(repo, ".github", "workflows")
+}
+
+// testLintProjectFiles lints the workflow files in the project in testdata/projects. It is useful
+// to lint only some files in the project. The file paths are relative to the project directory.
+func testLintProjectFiles(t *testing.T, repo string, files ...string) []*Error {
+	t.Helper()
+	ps := make([]string, 0, len(files))
+	for _, f := range files {
+		ps = append(ps, filepath.Join(repo, filepath.FromSlash(f)))
+	}
+	errs, err := testNewProjectLinter(t, repo).LintFiles(ps, &Project{root: repo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return errs
+}
+
 func TestLinterLintProject(t *testing.T) {
 	root := filepath.Join("testdata", "projects")
 	entries, err := os.ReadDir(root)
@@ -324,20 +956,7 @@ func TestLinterLintProject(t *testing.T) {
 			repo := filepath.Join(root, name)
 			t.Log("Linting project at", repo)
 
-			opts := LinterOptions{
-				WorkingDir: repo,
-			}
-			cfg := filepath.Join(repo, "actionlint.yaml")
-			if _, err := os.Stat(cfg); err == nil {
-				opts.ConfigFile = cfg
-			}
-			linter, err := NewLinter(io.Discard, &opts)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			proj := &Project{root: repo}
-			errs, err := linter.LintDir(filepath.Join(repo, "workflows"), proj)
+			errs, err := testNewProjectLinter(t, repo).LintDir(testProjectWorkflowsDir(repo), &Project{root: repo})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -438,12 +1057,6 @@ func TestLinterFormatErrorMessageInSARIF(t *testing.T) {
 	}
 
 	out := b.String()
-	// Fix path separators on Windows
-	if runtime.GOOS == "windows" {
-		slash := filepath.ToSlash(file)
-		escaped := strings.ReplaceAll(file, `\`, `\\`)
-		out = strings.ReplaceAll(out, escaped, slash)
-	}
 
 	var have interface{}
 	if err := json.Unmarshal([]byte(out), &have); err != nil {
@@ -641,6 +1254,155 @@ func TestLinterRemoveRuleOnRulesCreatedHook(t *testing.T) {
 	}
 }
 
+type customProjectRuleForTest struct {
+	RuleBase
+	files []string
+	steps int
+}
+
+func (r *customProjectRuleForTest) VisitStep(n *Step) error {
+	r.steps++
+	return nil
+}
+
+func (r *customProjectRuleForTest) VisitProject(files []*ProjectFile) error {
+	for _, f := range files {
+		r.files = append(r.files, f.Path)
+	}
+	last := files[len(files)-1]
+	r.FileErrorf(last.Path, &Pos{Line: 1, Col: 1}, "%d files in project", len(files))
+	r.FileErrorf("unknown.yaml", &Pos{Line: 1, Col: 1}, "this error is not reported")
+	return nil
+}
+
+func TestLinterAddProjectRuleOnRulesCreatedHook(t *testing.T) {
+	var calls int32
+	r := &customProjectRuleForTest{RuleBase: NewRuleBase("this-is-test", "")}
+	o := &LinterOptions{
+		OnRulesCreated: func(rules []Rule) []Rule {
+			atomic.AddInt32(&calls, 1) // Called in parallel
+			return append(rules, r)
+		},
+	}
+	l, err := NewLinter(io.Discard, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+	l.defaultConfig.Rules.WorkflowName.Unique = true
+
+	d := filepath.Join("testdata", "projects", "duplicate_workflow_name", "workflows")
+	fs := []string{
+		filepath.Join(d, "test.yaml"),
+		filepath.Join(d, "ci.yaml"),
+		filepath.Join(d, "release.yaml"),
+	}
+	errs, err := l.LintFiles(fs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Called once for each file in the first phase and once for the project in the second phase
+	if calls != 4 {
+		t.Fatal("hook should be called 4 times but got", calls)
+	}
+	if r.steps != 0 {
+		t.Fatal("project rule should not visit each workflow but visited steps:", r.steps)
+	}
+	want := []string{fs[1], fs[2], fs[0]}
+	if diff := cmp.Diff(want, r.files); diff != "" {
+		t.Fatal(diff)
+	}
+
+	msgs := []string{}
+	for _, e := range errs {
+		msgs = append(msgs, fmt.Sprintf("%s:%d:%d: %s [%s]", e.Filepath, e.Line, e.Column, e.Message, e.Kind))
+	}
+	wantMsgs := []string{
+		fs[0] + `:1:1: 3 files in project [this-is-test]`,
+		fs[0] + `:1:7: workflow name "CI" is duplicated. it is also used in "` + fs[1] + `". workflows with the same name are hard to distinguish in GitHub UI and required status checks [workflow-name]`,
+	}
+	if diff := cmp.Diff(wantMsgs, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+type panicRuleForTest struct {
+	RuleBase
+	steps int
+}
+
+func (r *panicRuleForTest) VisitStep(n *Step) error {
+	r.steps++
+	panic("oops")
+}
+
+type panicProjectRuleForTest struct {
+	RuleBase
+}
+
+func (r *panicProjectRuleForTest) VisitProject(files []*ProjectFile) error {
+	panic("oops")
+}
+
+func TestLinterRecoverPanicInRule(t *testing.T) {
+	r := &panicRuleForTest{RuleBase: NewRuleBase("panic-rule", "")}
+	o := &LinterOptions{
+		OnRulesCreated: func(rules []Rule) []Rule {
+			return append(rules, r, &panicProjectRuleForTest{NewRuleBase("panic-project-rule", "")})
+		},
+	}
+	l, err := NewLinter(io.Discard, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n      - run: echo\n"
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.steps != 1 {
+		t.Fatal("rule should not be called after panic but visited steps", r.steps, "times")
+	}
+
+	msgs := []string{}
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	want := []string{
+		`test.yaml:1:1: internal error in rule "panic-project-rule": oops. this is a bug of actionlint. please report it at https://github.com/rhysd/actionlint/issues with the workflow [panic-project-rule]`,
+		`test.yaml:6:9: internal error in rule "panic-rule": oops. this is a bug of actionlint. please report it at https://github.com/rhysd/actionlint/issues with the workflow [panic-rule]`,
+		`test.yaml:6:23: undefined variable "unknown". available variables are "env", "github", "inputs", "job", "matrix", "needs", "runner", "secrets", "steps", "strategy", "vars" [expression]`,
+	}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLinterStrictInternalPanic(t *testing.T) {
+	o := &LinterOptions{
+		StrictInternal: true,
+		OnRulesCreated: func(rules []Rule) []Rule {
+			return append(rules, &panicRuleForTest{RuleBase: NewRuleBase("panic-rule", "")})
+		},
+	}
+	l, err := NewLinter(io.Discard, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	defer func() {
+		if v := recover(); v != "oops" {
+			t.Fatal("unexpected panic:", v)
+		}
+	}()
+	l.Lint("test.yaml", []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"), nil)
+	t.Fatal("panic did not occur")
+}
+
 func TestLinterGenerateDefaultConfigAlreadyExists(t *testing.T) {
 	l, err := NewLinter(io.Discard, &LinterOptions{})
 	if err != nil {
@@ -884,6 +1646,77 @@ func BenchmarkExamplesLintFiles(b *testing.B) {
 	}
 }
 
+// testReadRealWorldDataset reads all workflow files in testdata/realworld/dataset.zip. The dataset
+// contains about 1500 workflow files collected from popular repositories on GitHub.
+func testReadRealWorldDataset(b *testing.B) map[string][]byte {
+	r, err := zip.OpenReader(filepath.Join("testdata", "realworld", "dataset.zip"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer r.Close()
+
+	ret := make(map[string][]byte, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			b.Fatal(err)
+		}
+		bs, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+		ret[f.Name] = bs
+	}
+	return ret
+}
+
+func BenchmarkRealWorldDataset(b *testing.B) {
+	files := testReadRealWorldDataset(b)
+	wfs := make([]*Workflow, 0, len(files))
+	for _, src := range files {
+		if w, _ := Parse(src); w != nil {
+			wfs = append(wfs, w)
+		}
+	}
+
+	b.Run("Parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, src := range files {
+				Parse(src)
+			}
+		}
+	})
+
+	b.Run("Expression", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, w := range wfs {
+				v := NewVisitor()
+				v.AddPass(NewRuleExpression())
+				if err := v.Visit(w); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Lint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l, err := NewLinter(io.Discard, &LinterOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			l.defaultConfig = &Config{}
+			for name, src := range files {
+				// Workflows in the dataset may contain errors
+				if _, err := l.Lint(name, src, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
 func BenchmarkLintRepository(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		opts := LinterOptions{}
@@ -900,3 +1733,63 @@ func BenchmarkLintRepository(b *testing.B) {
 		}
 	}
 }
+
+func TestLinterLintRemoteReusableWorkflows(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/contents/.github/workflows/a.yml?ref=v1": `{"type":"file","encoding":"base64","content":"b246IHdvcmtmbG93X2NhbGwKam9iczoKICBjYWxsOgogICAgdXNlczogby9yLy5naXRodWIvd29ya2Zsb3dzL2IueW1sQHYxCg=="}`,
+		"/repos/o/r/contents/.github/workflows/b.yml?ref=v1": `{"type":"file","encoding":"base64","content":"b246IHdvcmtmbG93X2NhbGwKam9iczoKICB0ZXN0OgogICAgcnVucy1vbjogdWJ1bnR1LWxhdGVzdAogICAgc3RlcHM6CiAgICAgIC0gcnVuOiBlY2hvICR7eyB1bmtub3duLmZvbyB9fQo="}`,
+	})
+	src := []byte("on: push\njobs:\n  call:\n    uses: o/r/.github/workflows/a.yml@v1\n")
+
+	testCases := []struct {
+		depth int
+		want  []string
+	}{
+		{
+			depth: 0,
+			want: []string{
+				`o/r/.github/workflows/b.yml@v1:6:23: undefined variable "unknown"`,
+			},
+		},
+		{
+			depth: 1,
+			want:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("depth=%d", tc.depth), func(t *testing.T) {
+			opts := &LinterOptions{
+				Remote:         "o/r",
+				RemoteClient:   c,
+				RemoteLint:     true,
+				RemoteMaxDepth: tc.depth,
+				Shellcheck:     "",
+				Pyflakes:       "",
+			}
+			l, err := NewLinter(io.Discard, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{}
+
+			errs, err := l.Lint("test.yaml", src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			have := make([]string, 0, len(errs))
+			for _, err := range errs {
+				have = append(have, err.Error())
+			}
+			if len(have) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(have), have)
+			}
+			for i, h := range have {
+				if !strings.Contains(h, tc.want[i]) {
+					t.Errorf("error %q does not contain %q", h, tc.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Package lintest provides helpers to test Go programs embedding actionlint.
+
+Lint runs the linter on a workflow given as a string in a temporary repository. Files in the
+repository such as config file, local actions, and reusable workflows can be put with Options.
+Services used by rules such as metadata of actions and GitHub API can be stubbed with Options
+as well so that tests don't depend on the network or the actual popular actions data.
+
+	errs := lintest.Lint(t, src, &lintest.Options{
+		PopularActions: lintest.Actions{
+			"my-org/my-action@v1": {Name: "My action"},
+		},
+	})
+	lintest.Assert(t, errs,
+		lintest.Want{Kind: "expression", Line: 9, Column: 23, Message: `property "foo" is not defined`},
+	)
+
+The same as the actionlint package, this package does not follow semantic versioning.
+*/
+package lintest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+)
+
+// WorkflowPath is a slash-separated relative path of the workflow file linted by Lint in the
+// temporary repository. The Filepath field of the errors returned from Lint is this path.
+const WorkflowPath = ".github/workflows/test.yaml"
+
+// Actions is a stub of metadata of actions keyed by specs like "owner/repo@v1" or
+// "./path/to/action". It implements actionlint.ActionMetadataResolver interface.
+type Actions map[string]*actionlint.ActionMetadata
+
+// FindMetadata returns the metadata of the action specified by the spec. It returns nil when the
+// action is not in the map.
+func (a Actions) FindMetadata(spec string) (*actionlint.ActionMetadata, bool, error) {
+	return a[spec], false, nil
+}
+
+// Options is options to run the linter with Lint function.
+type Options struct {
+	// Linter is options of the linter. OnCapabilitiesCreated is overwritten when PopularActions or
+	// LocalActions is set, and RemoteClient is overwritten when GitHubAPI is set. WorkingDir is always
+	// set to the temporary repository.
+	Linter actionlint.LinterOptions
+	// Config is the content of actionlint.yaml config file put in the temporary repository. When this
+	// value is empty, no config file is put.
+	Config string
+	// Files is contents of files keyed by slash-separated relative paths like
+	// ".github/actions/my-action/action.yml" put in the temporary repository.
+	Files map[string]string
+	// PopularActions replaces the metadata of popular actions. When this value is nil, the actual
+	// data of popular actions bundled in actionlint is used.
+	PopularActions Actions
+	// LocalActions replaces the metadata of local actions like "./path/to/action". When this value
+	// is nil, local actions are read from Files.
+	LocalActions Actions
+	// GitHubAPI is responses of the fake GitHub API server used for Linter.Remote option. See
+	// GitHubServer function for the format. When this value is nil, the actual GitHub API is used.
+	GitHubAPI map[string]string
+}
+
+// NewProject creates a temporary repository with the files keyed by slash-separated relative paths
+// and returns the path to the repository. The repository is removed after the test.
+func NewProject(t testing.TB, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for p, c := range files {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// GitHubServer starts a fake GitHub API server and returns a client to access it. The routes are
+// response bodies keyed by the paths of requests like "/repos/owner/repo/actions/secrets". A key
+// can have a query like "/repos/owner/repo/contents/action.yml?ref=v1", which is preferred to the
+// key without the query. When no route matches, the server returns 404. The server is closed after
+// the test.
+func GitHubServer(t testing.TB, routes map[string]string) *actionlint.GitHubClient {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []string{r.URL.Path}
+		if q := r.URL.RawQuery; q != "" {
+			keys = append([]string{r.URL.Path + "?" + q}, keys...)
+		}
+		for _, k := range keys {
+			if b, ok := routes[k]; ok {
+				w.Write([]byte(b))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(s.Close)
+	return actionlint.NewGitHubClientWithBaseURL(s.URL, "")
+}
+
+// Lint runs the linter on the workflow source and returns the errors sorted by their positions.
+// The workflow is put at WorkflowPath in a temporary repository created with the options. The opts
+// parameter can be nil. It fails the test when the linter cannot run.
+func Lint(t testing.TB, src string, opts *Options) []*actionlint.Error {
+	t.Helper()
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	files := map[string]string{WorkflowPath: src}
+	for p, c := range opts.Files {
+		files[p] = c
+	}
+	if opts.Config != "" {
+		files[".github/actionlint.yaml"] = opts.Config
+	}
+	root := NewProject(t, files)
+
+	o := opts.Linter
+	o.WorkingDir = root
+	if opts.PopularActions != nil || opts.LocalActions != nil {
+		o.OnCapabilitiesCreated = func(c *actionlint.Capabilities) *actionlint.Capabilities {
+			if opts.PopularActions != nil {
+				c.PopularActions = opts.PopularActions
+			}
+			if opts.LocalActions != nil {
+				c.LocalActions = opts.LocalActions
+			}
+			return c
+		}
+	}
+	if opts.GitHubAPI != nil {
+		o.RemoteClient = GitHubServer(t, opts.GitHubAPI)
+	}
+
+	l, err := actionlint.NewLinter(io.Discard, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.LintFile(filepath.Join(root, filepath.FromSlash(WorkflowPath)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range errs {
+		err.Filepath = filepath.ToSlash(err.Filepath)
+	}
+	sort.Stable(actionlint.ByErrorPosition(errs))
+	return errs
+}
+
+// Want is an expected error. Fields with zero values match any error.
+type Want struct {
+	// Kind is the name of the rule which reports the error like "expression".
+	Kind string
+	// Line is the line number of the error position (1-based).
+	Line int
+	// Column is the column number of the error position (1-based).
+	Column int
+	// Message is a substring of the error message.
+	Message string
+}
+
+// Match returns true when the error is expected by the want.
+func (w *Want) Match(err *actionlint.Error) bool {
+	return (w.Kind == "" || w.Kind == err.Kind) &&
+		(w.Line == 0 || w.Line == err.Line) &&
+		(w.Column == 0 || w.Column == err.Column) &&
+		strings.Contains(err.Message, w.Message)
+}
+
+func (w *Want) String() string {
+	ss := []string{}
+	if w.Line != 0 {
+		ss = append(ss, fmt.Sprintf("line %d", w.Line))
+	}
+	if w.Column != 0 {
+		ss = append(ss, fmt.Sprintf("column %d", w.Column))
+	}
+	if w.Kind != "" {
+		ss = append(ss, fmt.Sprintf("rule %q", w.Kind))
+	}
+	if w.Message != "" {
+		ss = append(ss, fmt.Sprintf("message containing %q", w.Message))
+	}
+	if len(ss) == 0 {
+		return "any error"
+	}
+	return strings.Join(ss, ", ")
+}
+
+// match reports the wants which don't match to any error as test failures and returns which errors
+// were matched.
+func match(t testing.TB, errs []*actionlint.Error, wants []Want) []bool {
+	t.Helper()
+	matched := make([]bool, len(errs))
+Wants:
+	for i := range wants {
+		w := &wants[i]
+		for j, err := range errs {
+			if !matched[j] && w.Match(err) {
+				matched[j] = true
+				continue Wants
+			}
+		}
+		t.Errorf("expected error was not reported: %s", w)
+	}
+	return matched
+}
+
+// Assert checks that the errors are exactly the expected ones. Each want matches one error in any
+// order. It reports the missing and the unexpected errors as test failures. Assert with no want
+// checks that no error is reported.
+func Assert(t testing.TB, errs []*actionlint.Error, wants ...Want) {
+	t.Helper()
+	for i, m := range match(t, errs, wants) {
+		if !m {
+			t.Errorf("unexpected error was reported: %s", errs[i])
+		}
+	}
+}
+
+// AssertContains checks that the errors contain the expected ones. Other errors are ignored.
+func AssertContains(t testing.TB, errs []*actionlint.Error, wants ...Want) {
+	t.Helper()
+	match(t, errs, wants)
+}
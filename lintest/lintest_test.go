@@ -0,0 +1,130 @@
+package lintest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+)
+
+// testRecorder records test failures instead of failing the test.
+type testRecorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *testRecorder) Helper() {}
+
+func (r *testRecorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, format)
+}
+
+func TestLintAndAssert(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ matrix.foo }}
+`
+	errs := Lint(t, src, nil)
+	Assert(t, errs, Want{Kind: "expression", Line: 6, Column: 23, Message: `property "foo" is not defined`})
+	AssertContains(t, errs, Want{Kind: "expression"})
+	if errs[0].Filepath != WorkflowPath {
+		t.Errorf("wanted file path %q but got %q", WorkflowPath, errs[0].Filepath)
+	}
+
+	for _, wants := range [][]Want{
+		{},
+		{{Kind: "expression", Line: 7}},
+		{{Kind: "expression"}, {Kind: "expression"}},
+	} {
+		r := &testRecorder{TB: t}
+		Assert(r, errs, wants...)
+		if len(r.errs) == 0 {
+			t.Errorf("Assert did not fail with %v", wants)
+		}
+	}
+
+	r := &testRecorder{TB: t}
+	AssertContains(r, errs, Want{Message: "this message is not reported"})
+	if len(r.errs) != 1 || !strings.Contains(r.errs[0], "was not reported") {
+		t.Errorf("AssertContains did not fail as expected: %v", r.errs)
+	}
+}
+
+func TestLintWithFilesAndConfig(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: my-runner
+    steps:
+      - uses: ./.github/actions/my-action
+        with:
+          foo: bar
+`
+	action := `name: My action
+description: test
+inputs:
+  bar:
+    description: test
+runs:
+  using: node20
+  main: index.js
+`
+	errs := Lint(t, src, &Options{
+		Config: "self-hosted-runner:\n  labels: [my-runner]\n",
+		Files: map[string]string{
+			".github/actions/my-action/action.yml": action,
+			".github/actions/my-action/index.js":   "",
+		},
+	})
+	Assert(t, errs, Want{Kind: "action", Line: 8, Message: `input "foo" is not defined`})
+}
+
+func TestLintWithStubActions(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: my-org/my-action@v1
+        with:
+          foo: bar
+      - uses: ./my-local-action
+        with:
+          bar: ${{ steps.x.outputs.y }}
+`
+	meta := &actionlint.ActionMetadata{
+		Name:        "My action",
+		Description: "test",
+		Inputs:      actionlint.ActionMetadataInputs{"bar": {Name: "bar"}},
+		Runs:        actionlint.ActionMetadataRuns{Using: "docker", Image: "docker://alpine:3"},
+	}
+	errs := Lint(t, src, &Options{
+		PopularActions: Actions{"my-org/my-action@v1": meta},
+		LocalActions:   Actions{"./my-local-action": meta},
+	})
+	Assert(t, errs,
+		Want{Kind: "action", Line: 8, Message: `input "foo" is not defined in action "my-org/my-action@v1"`},
+		Want{Kind: "expression", Line: 11, Message: `property "x" is not defined`},
+	)
+}
+
+func TestLintWithFakeGitHubAPI(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ secrets.KNOWN }} ${{ secrets.UNKNOWN }}
+`
+	errs := Lint(t, src, &Options{
+		Linter: actionlint.LinterOptions{Remote: "o/r"},
+		GitHubAPI: map[string]string{
+			"/repos/o/r/actions/secrets":              `{"total_count":1,"secrets":[{"name":"KNOWN"}]}`,
+			"/repos/o/r/actions/organization-secrets": `{"total_count":0,"secrets":[]}`,
+		},
+	})
+	Assert(t, errs, Want{Kind: "remote", Line: 6, Message: `"UNKNOWN" is not defined in repository "o/r"`})
+}
@@ -0,0 +1,1087 @@
+package actionlint
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LSPOptions is a set of options for LSPServer.
+type LSPOptions struct {
+	// Linter is options of linters to check workflows. The config file at ConfigFile is read only once
+	// when the server is created.
+	Linter LinterOptions
+	// LogWriter is a writer to output logs of the server. When this value is nil, logs are not output.
+	// Note that stdout must not be used since it is used for the communication with the client.
+	LogWriter io.Writer
+}
+
+// LSPServer is a language server which speaks Language Server Protocol over stdio. It lints workflow
+// documents opened in the client and publishes the errors as diagnostics. It also provides the
+// following code actions for each error:
+//
+//   - Suggestions of the error as quick fixes
+//   - Suppressing the error with "# actionlint-ignore:" inline comment
+//   - Adding an ignore pattern for the error to "paths" in .github/actionlint.yaml
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
+type LSPServer struct {
+	opts     LSPOptions
+	log      io.Writer
+	out      io.Writer
+	config   *Config
+	projects *Projects
+	docs     map[string]*lspDocument
+	shutdown bool
+	ctx      context.Context
+}
+
+// NewLSPServer creates a new LSPServer instance.
+func NewLSPServer(opts *LSPOptions) (*LSPServer, error) {
+	s := &LSPServer{
+		opts:     *opts,
+		log:      opts.LogWriter,
+		projects: NewProjects(),
+		docs:     map[string]*lspDocument{},
+		ctx:      context.Background(),
+	}
+	if s.log == nil {
+		s.log = io.Discard
+	}
+	s.opts.Linter.Color = ColorOptionKindNever
+	s.opts.Linter.Format = ""
+	s.opts.Linter.GroupBy = ReportGroupByNone
+	s.opts.Linter.ColumnUnit = ColumnUnitRune // Converted into UTF-16 code units on sending to client
+	s.opts.Linter.LogWriter = nil
+	s.opts.Linter.Remote = ""
+	if s.opts.Linter.ConfigFile != "" {
+		c, err := ReadConfigFile(s.opts.Linter.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		s.config = c
+	}
+	return s, nil
+}
+
+func (s *LSPServer) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.log, "[%s] %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// Serve reads requests and notifications from the client via the reader and writes responses and
+// notifications to the writer until "exit" notification is received or the reader reaches EOF.
+// Messages are handled sequentially.
+func (s *LSPServer) Serve(in io.Reader, out io.Writer) error {
+	return s.ServeContext(context.Background(), in, out)
+}
+
+// ServeContext is the same as Serve but it returns the context's error when the context is
+// cancelled. External processes such as shellcheck running for linting documents are killed on the
+// cancellation. Messages are read in another goroutine so that the cancellation is not blocked by
+// reading the next message.
+func (s *LSPServer) ServeContext(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.ctx = ctx
+	s.out = out
+
+	type read struct {
+		msg *lspMessage
+		err error
+	}
+	reads := make(chan read)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		r := bufio.NewReader(in)
+		for {
+			msg, err := readLSPMessage(r)
+			select {
+			case reads <- read{msg, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var msg *lspMessage
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-reads:
+			msg, err = r.msg, r.err
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if msg.ID == nil {
+			if msg.Method == "exit" {
+				s.logf("Exit")
+				return nil
+			}
+			if err := s.handleNotification(msg.Method, msg.Params); err != nil {
+				s.logf("Could not handle notification %q: %s", msg.Method, err)
+			}
+			continue
+		}
+
+		var res interface{}
+		var rerr *lspResponseError
+		if s.shutdown {
+			rerr = &lspResponseError{Code: lspErrorCodeInvalidRequest, Message: "server was already shut down"}
+		} else {
+			res, rerr = s.handleRequest(msg.Method, msg.Params)
+		}
+		if err := s.respond(msg.ID, res, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *LSPServer) handleRequest(method string, params json.RawMessage) (interface{}, *lspResponseError) {
+	switch method {
+	case "initialize":
+		s.logf("Initialize")
+		return &lspInitializeResult{
+			Capabilities: lspServerCapabilities{
+				TextDocumentSync: lspTextDocumentSyncOptions{
+					OpenClose: true,
+					Change:    lspTextDocumentSyncKindFull,
+					Save:      true,
+				},
+				CodeActionProvider: lspCodeActionOptions{
+					CodeActionKinds: []string{lspCodeActionKindQuickFix},
+				},
+				HoverProvider: true,
+				CompletionProvider: lspCompletionOptions{
+					TriggerCharacters: []string{"."},
+				},
+				DefinitionProvider:     true,
+				ReferencesProvider:     true,
+				DocumentSymbolProvider: true,
+				RenameProvider: lspRenameOptions{
+					PrepareProvider: true,
+				},
+				SemanticTokensProvider: lspSemanticTokensOptions{
+					Legend: lspSemanticTokensLegend{
+						TokenTypes:     lspSemanticTokenTypes,
+						TokenModifiers: []string{},
+					},
+					Full: true,
+				},
+			},
+			ServerInfo: lspServerInfo{
+				Name:    "actionlint",
+				Version: getCommandVersion(),
+			},
+		}, nil
+	case "shutdown":
+		s.logf("Shutdown")
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/hover":
+		var p lspTextDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		if h := s.hover(&p); h != nil {
+			return h, nil
+		}
+		return nil, nil
+	case "textDocument/completion":
+		var p lspTextDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.complete(&p), nil
+	case "textDocument/definition":
+		var p lspTextDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.definition(&p), nil
+	case "textDocument/references":
+		var p lspReferenceParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.references(&p), nil
+	case "textDocument/documentSymbol":
+		var p lspDocumentSymbolParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.documentSymbols(&p), nil
+	case "textDocument/semanticTokens/full":
+		var p lspSemanticTokensParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.semanticTokens(&p), nil
+	case "textDocument/prepareRename":
+		var p lspTextDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		if r := s.prepareRename(&p); r != nil {
+			return r, nil
+		}
+		return nil, nil
+	case "textDocument/rename":
+		var p lspRenameParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		e, err := s.rename(&p)
+		if err != nil {
+			return nil, &lspResponseError{Code: lspErrorCodeRequestFailed, Message: err.Error()}
+		}
+		return e, nil
+	case "textDocument/codeAction":
+		var p lspCodeActionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, lspInvalidParams(err)
+		}
+		return s.codeActions(&p), nil
+	default:
+		return nil, &lspResponseError{
+			Code:    lspErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("method %q is not supported", method),
+		}
+	}
+}
+
+func (s *LSPServer) handleNotification(method string, params json.RawMessage) error {
+	switch method {
+	case "textDocument/didOpen":
+		var p lspDidOpenTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		return s.update(p.TextDocument.URI, p.TextDocument.Version, []byte(p.TextDocument.Text))
+	case "textDocument/didChange":
+		var p lspDidChangeTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		if len(p.ContentChanges) == 0 {
+			return nil
+		}
+		// Only full synchronization is supported. The last change has the entire content
+		text := p.ContentChanges[len(p.ContentChanges)-1].Text
+		return s.update(p.TextDocument.URI, p.TextDocument.Version, []byte(text))
+	case "textDocument/didSave":
+		var p lspDidSaveTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		if isLSPConfigFile(p.TextDocument.URI) {
+			return s.reloadProjects()
+		}
+		return nil
+	case "textDocument/didClose":
+		var p lspDidCloseTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		if _, ok := s.docs[p.TextDocument.URI]; !ok {
+			return nil
+		}
+		delete(s.docs, p.TextDocument.URI)
+		// Clear the diagnostics of the closed document
+		return s.notify("textDocument/publishDiagnostics", &lspPublishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []*lspDiagnostic{},
+		})
+	case "workspace/didChangeWatchedFiles":
+		var p lspDidChangeWatchedFilesParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		for _, c := range p.Changes {
+			if isLSPConfigFile(c.URI) {
+				return s.reloadProjects()
+			}
+		}
+		return nil
+	default:
+		// Notifications not supported by the server must be ignored. This includes "initialized" and
+		// "$/cancelRequest".
+		return nil
+	}
+}
+
+func (s *LSPServer) update(uri string, version int, text []byte) error {
+	if isLSPConfigFile(uri) {
+		return nil // Config files are not workflows
+	}
+	path, err := pathFromLSPURI(uri)
+	if err != nil {
+		return err
+	}
+	d := &lspDocument{uri: uri, path: path, version: version, text: text}
+	if w, _ := Parse(text); w != nil {
+		d.workflow = w
+	} else if prev, ok := s.docs[uri]; ok {
+		d.workflow = prev.workflow // Keep the previous one for hover and completion while editing
+	}
+	s.docs[uri] = d
+	return s.publish(d)
+}
+
+// reloadProjects drops cached projects since their config files were modified, then lints all
+// opened documents again.
+func (s *LSPServer) reloadProjects() error {
+	s.logf("Reload config files")
+	s.projects = NewProjects()
+	for _, d := range s.docs {
+		if err := s.publish(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LSPServer) publish(d *lspDocument) error {
+	diags := []*lspDiagnostic{}
+	if err := s.lint(d); err != nil {
+		s.logf("Could not lint %s: %s", d.path, err)
+		// Show the error in the client since it is usually caused by a broken config file
+		d.errs = nil
+		diags = append(diags, &lspDiagnostic{
+			Severity: lspDiagnosticSeverityError,
+			Source:   "actionlint",
+			Message:  err.Error(),
+		})
+	}
+	for _, e := range d.errs {
+		diags = append(diags, lspDiagnosticOf(e, d.text))
+	}
+	s.logf("Publish %d diagnostics for %s", len(diags), d.path)
+	return s.notify("textDocument/publishDiagnostics", &lspPublishDiagnosticsParams{
+		URI:         d.uri,
+		Version:     d.version,
+		Diagnostics: diags,
+	})
+}
+
+func (s *LSPServer) lint(d *lspDocument) error {
+	p, err := s.projects.At(d.path)
+	if err != nil {
+		return err
+	}
+
+	opts := s.opts.Linter
+	opts.ConfigFile = "" // Already read
+	opts.Context = s.ctx
+	path := d.path
+	if p != nil {
+		// Make the path relative to the repository root so that it matches to the keys of "paths" in
+		// the config file
+		if r, err := filepath.Rel(p.RootDir(), d.path); err == nil {
+			opts.WorkingDir = p.RootDir()
+			path = r
+		}
+	}
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		return err
+	}
+	l.defaultConfig = s.config
+	l.projects = s.projects
+
+	errs, err := l.Lint(path, d.text, p)
+	if err != nil {
+		return err
+	}
+
+	d.project = p
+	d.config = l.config(p)
+	d.relPath = path
+	d.errs = nil
+	for _, e := range errs {
+		if e.Filepath == path {
+			d.errs = append(d.errs, e)
+		}
+	}
+	return nil
+}
+
+func (s *LSPServer) codeActions(params *lspCodeActionParams) []*lspCodeAction {
+	actions := []*lspCodeAction{}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return actions
+	}
+
+	for _, e := range d.errs {
+		line := e.Line - 1
+		if line < params.Range.Start.Line || params.Range.End.Line < line {
+			continue
+		}
+		diags := []*lspDiagnostic{lspDiagnosticOf(e, d.text)}
+		lines := d.lines()
+
+		for i, sg := range e.Suggestions {
+			if sg.NewFile != nil {
+				continue // Fixes creating files are only applied by -fix
+			}
+			actions = append(actions, &lspCodeAction{
+				Title:       sg.Message,
+				Kind:        lspCodeActionKindQuickFix,
+				Diagnostics: diags,
+				IsPreferred: i == 0,
+				Edit: &lspWorkspaceEdit{
+					Changes: map[string][]*lspTextEdit{
+						d.uri: {{
+							Range: lspRange{
+								Start: lspPositionIn(lines, sg.Start.Line, sg.Start.Col),
+								End:   lspPositionIn(lines, sg.End.Line, sg.End.Col),
+							},
+							NewText: sg.Replacement,
+						}},
+					},
+				},
+			})
+		}
+
+		pat := inlineIgnorePatternOf(e)
+		if e.Line > 0 {
+			actions = append(actions, &lspCodeAction{
+				Title:       fmt.Sprintf("Suppress with inline comment \"# actionlint-ignore: %s\"", pat),
+				Kind:        lspCodeActionKindQuickFix,
+				Diagnostics: diags,
+				Edit: &lspWorkspaceEdit{
+					Changes: map[string][]*lspTextEdit{
+						d.uri: {{
+							Range:   lspRange{Start: lspPosition{Line: line}, End: lspPosition{Line: line}},
+							NewText: lineIndentAt(d.text, e.Line) + "# actionlint-ignore: " + pat + "\n",
+						}},
+					},
+				},
+			})
+		}
+
+		if a := s.configIgnoreAction(d, pat); a != nil {
+			a.Diagnostics = diags
+			actions = append(actions, a)
+		}
+	}
+
+	return actions
+}
+
+// configIgnoreAction creates a code action to add the ignore pattern to "paths" section of the
+// config file in the repository. It returns nil when the action cannot be provided.
+func (s *LSPServer) configIgnoreAction(d *lspDocument, pat string) *lspCodeAction {
+	if d.project == nil || s.config != nil {
+		return nil // Config file in the repository is not used
+	}
+	key := filepath.ToSlash(d.relPath)
+	if strings.HasPrefix(key, "../") {
+		return nil
+	}
+	if c := d.project.Config(); c != nil {
+		if _, ok := c.Paths[key]; ok {
+			return nil // Adding the same key would break the config file
+		}
+	}
+
+	var path string
+	var src []byte
+	for _, n := range []string{"actionlint.yaml", "actionlint.yml"} {
+		p := filepath.Join(d.project.RootDir(), ".github", n)
+		if b, err := os.ReadFile(p); err == nil {
+			path, src = p, b
+			break
+		}
+	}
+
+	title := fmt.Sprintf("Add \"%s\" to ignore patterns of %s in actionlint.yaml", pat, key)
+
+	if path == "" {
+		path = filepath.Join(d.project.RootDir(), ".github", "actionlint.yaml")
+		uri := lspURIFromPath(path)
+		return &lspCodeAction{
+			Title: title,
+			Kind:  lspCodeActionKindQuickFix,
+			Edit: &lspWorkspaceEdit{
+				DocumentChanges: []interface{}{
+					&lspCreateFile{Kind: "create", URI: uri},
+					&lspTextDocumentEdit{
+						TextDocument: lspOptionalVersionedTextDocumentIdentifier{URI: uri},
+						Edits: []*lspTextEdit{{
+							NewText: "paths:\n" + configIgnoreEntry(key, pat, "  "),
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	lines := splitLines(string(src))
+	var edit *lspTextEdit
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "paths:") {
+			continue
+		}
+		if rest := strings.TrimSpace(l[len("paths:"):]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil // Flow style mapping like `paths: {}` is not supported
+		}
+		indent := "  "
+		for _, l := range lines[i+1:] {
+			t := strings.TrimLeft(l, " ")
+			if t == "" || strings.HasPrefix(t, "#") {
+				continue
+			}
+			if len(t) < len(l) {
+				indent = l[:len(l)-len(t)]
+			}
+			break
+		}
+		pos := lspPosition{Line: i + 1}
+		edit = &lspTextEdit{Range: lspRange{Start: pos, End: pos}, NewText: configIgnoreEntry(key, pat, indent)}
+		break
+	}
+	if edit == nil {
+		// Append new "paths" section at the end of the file
+		last := len(lines) - 1
+		pos := lspPosition{Line: last, Character: lspUTF16Len(lines[last])}
+		text := "paths:\n" + configIgnoreEntry(key, pat, "  ")
+		if lines[last] != "" {
+			text = "\n" + text
+		}
+		edit = &lspTextEdit{Range: lspRange{Start: pos, End: pos}, NewText: text}
+	}
+
+	return &lspCodeAction{
+		Title: title,
+		Kind:  lspCodeActionKindQuickFix,
+		Edit: &lspWorkspaceEdit{
+			Changes: map[string][]*lspTextEdit{lspURIFromPath(path): {edit}},
+		},
+	}
+}
+
+// configIgnoreEntry builds an entry of "paths" mapping in the config file. The indent is a unit of
+// indentation used in the mapping.
+func configIgnoreEntry(key, pat, indent string) string {
+	var b strings.Builder
+	b.WriteString(indent + strconv.Quote(key) + ":\n")
+	b.WriteString(strings.Repeat(indent, 2) + "ignore:\n")
+	b.WriteString(strings.Repeat(indent, 3) + "- " + strconv.Quote(pat) + "\n")
+	return b.String()
+}
+
+// inlineIgnorePatternOf returns the ignore pattern which matches to the error. The error code is
+// preferred since it is more specific than the rule name.
+func inlineIgnorePatternOf(e *Error) string {
+	if c := e.Code(); c != "" {
+		return "code:" + c
+	}
+	return "rule:" + e.Kind
+}
+
+func lineIndentAt(src []byte, line int) string {
+	l, ok := lineAt(src, line)
+	if !ok {
+		return ""
+	}
+	return l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+}
+
+func lineAt(src []byte, line int) (string, bool) {
+	s := string(src)
+	for i := 1; i < line; i++ {
+		j := strings.IndexByte(s, '\n')
+		if j < 0 {
+			return "", false
+		}
+		s = s[j+1:]
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, "\r"), true
+}
+
+func isLSPConfigFile(uri string) bool {
+	b := uri[strings.LastIndexByte(uri, '/')+1:]
+	return (b == "actionlint.yaml" || b == "actionlint.yml") && strings.HasSuffix(uri[:len(uri)-len(b)], "/.github/")
+}
+
+func pathFromLSPURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid document URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("document URI %q is not a file URI", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func lspURIFromPath(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows path like C:/path/to/file
+	}
+	u := url.URL{Scheme: "file", Path: p}
+	return u.String()
+}
+
+func lspPositionOf(line, col int) lspPosition {
+	p := lspPosition{Line: line - 1, Character: col - 1}
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Character < 0 {
+		p.Character = 0
+	}
+	return p
+}
+
+// lspUTF16Len returns the length of the string in UTF-16 code units. Characters in LSP are counted
+// in UTF-16 code units.
+func lspUTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += ColumnUnitUTF16.width(r)
+	}
+	return n
+}
+
+// lspByteOffset converts the character of a position counted in UTF-16 code units into the 0-based
+// byte offset in the line.
+func lspByteOffset(line string, char int) int {
+	return ColumnUnitByte.FromRune(line, ColumnUnitUTF16.ToRune(line, char+1)) - 1
+}
+
+// lspCharOf converts the 0-based byte offset in the line into the character of a position counted in
+// UTF-16 code units. This is the inverse of lspByteOffset.
+func lspCharOf(line string, offset int) int {
+	return ColumnUnitUTF16.FromRune(line, ColumnUnitByte.ToRune(line, offset+1)) - 1
+}
+
+// lspPositionIn returns the position of the 1-based line and column in Unicode code points. The
+// character of the position is counted in UTF-16 code units as required by the LSP specification.
+func lspPositionIn(lines []string, line, col int) lspPosition {
+	if 0 < line && line <= len(lines) {
+		col = ColumnUnitUTF16.FromRune(lines[line-1], col)
+	}
+	return lspPositionOf(line, col)
+}
+
+func lspDiagnosticOf(e *Error, src []byte) *lspDiagnostic {
+	f := e.GetTemplateFields(src)
+	lines := splitLines(string(src))
+	start := lspPositionIn(lines, e.Line, e.Column)
+	end := lspPositionIn(lines, e.Line, f.EndColumn+1) // EndColumn is inclusive
+	if end.Character <= start.Character {
+		end.Character = start.Character + 1
+	}
+	return &lspDiagnostic{
+		Range:    lspRange{Start: start, End: end},
+		Severity: lspDiagnosticSeverityError,
+		Code:     f.Code,
+		Source:   "actionlint",
+		Message:  fmt.Sprintf("%s [%s]", e.Message, e.Kind),
+	}
+}
+
+type lspDocument struct {
+	uri      string
+	path     string
+	relPath  string
+	version  int
+	text     []byte
+	project  *Project
+	config   *Config
+	errs     []*Error
+	workflow *Workflow // The last workflow which could be parsed
+}
+
+func (d *lspDocument) lines() []string {
+	return splitLines(string(d.text))
+}
+
+// JSON-RPC messages
+
+type lspMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type lspResponse struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Result  json.RawMessage   `json:"result,omitempty"`
+	Error   *lspResponseError `json:"error,omitempty"`
+}
+
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+const (
+	lspErrorCodeInvalidRequest = -32600
+	lspErrorCodeMethodNotFound = -32601
+	lspErrorCodeInvalidParams  = -32602
+	lspErrorCodeRequestFailed  = -32803
+)
+
+type lspResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func lspInvalidParams(err error) *lspResponseError {
+	return &lspResponseError{Code: lspErrorCodeInvalidParams, Message: err.Error()}
+}
+
+// maxLSPMessageSize is the maximum size of LSP message body in bytes. A message may contain the
+// whole content of a workflow file so some room for the other fields is added to the maximum file
+// size. It prevents allocating a huge buffer for a broken Content-Length header.
+const maxLSPMessageSize = defaultMaxFileSize + 1024*1024
+
+func readLSPMessage(r *bufio.Reader) (*lspMessage, error) {
+	length := -1
+	for {
+		l, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && l != "" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		l = strings.TrimRight(l, "\r\n")
+		if l == "" {
+			break
+		}
+		if i := strings.IndexByte(l, ':'); i >= 0 && strings.EqualFold(l[:i], "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(l[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length header %q: %w", l, err)
+			}
+			if n < 0 || n > maxLSPMessageSize {
+				return nil, fmt.Errorf("Content-Length %d is out of range. it must be between 0 and %d", n, maxLSPMessageSize)
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("Content-Length header is missing in LSP message")
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("could not read LSP message body: %w", err)
+	}
+	var m lspMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse LSP message as JSON: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *LSPServer) write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode LSP message: %w", err)
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(b), b); err != nil {
+		return fmt.Errorf("could not write LSP message: %w", err)
+	}
+	return nil
+}
+
+func (s *LSPServer) respond(id json.RawMessage, result interface{}, rerr *lspResponseError) error {
+	res := &lspResponse{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("could not encode result of LSP request: %w", err)
+		}
+		res.Result = b // `null` is also a valid result
+	}
+	return s.write(res)
+}
+
+func (s *LSPServer) notify(method string, params interface{}) error {
+	return s.write(&lspNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// LSP structures. Only fields used by actionlint are defined.
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspOptionalVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
+}
+
+type lspTextDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type lspVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+type lspTextDocumentSyncKind int
+
+const lspTextDocumentSyncKindFull lspTextDocumentSyncKind = 1
+
+type lspTextDocumentSyncOptions struct {
+	OpenClose bool                    `json:"openClose"`
+	Change    lspTextDocumentSyncKind `json:"change"`
+	Save      bool                    `json:"save"`
+}
+
+const lspCodeActionKindQuickFix = "quickfix"
+
+type lspCodeActionOptions struct {
+	CodeActionKinds []string `json:"codeActionKinds"`
+}
+
+type lspRenameOptions struct {
+	PrepareProvider bool `json:"prepareProvider"`
+}
+
+type lspSemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+type lspSemanticTokensOptions struct {
+	Legend lspSemanticTokensLegend `json:"legend"`
+	Full   bool                    `json:"full"`
+}
+
+type lspCompletionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters"`
+}
+
+type lspServerCapabilities struct {
+	TextDocumentSync       lspTextDocumentSyncOptions `json:"textDocumentSync"`
+	CodeActionProvider     lspCodeActionOptions       `json:"codeActionProvider"`
+	HoverProvider          bool                       `json:"hoverProvider"`
+	CompletionProvider     lspCompletionOptions       `json:"completionProvider"`
+	DefinitionProvider     bool                       `json:"definitionProvider"`
+	ReferencesProvider     bool                       `json:"referencesProvider"`
+	DocumentSymbolProvider bool                       `json:"documentSymbolProvider"`
+	RenameProvider         lspRenameOptions           `json:"renameProvider"`
+	SemanticTokensProvider lspSemanticTokensOptions   `json:"semanticTokensProvider"`
+}
+
+type lspServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type lspInitializeResult struct {
+	Capabilities lspServerCapabilities `json:"capabilities"`
+	ServerInfo   lspServerInfo         `json:"serverInfo"`
+}
+
+type lspDidOpenTextDocumentParams struct {
+	TextDocument lspTextDocumentItem `json:"textDocument"`
+}
+
+type lspTextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type lspDidChangeTextDocumentParams struct {
+	TextDocument   lspVersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []lspTextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type lspDidSaveTextDocumentParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspDidCloseTextDocumentParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspFileEvent struct {
+	URI string `json:"uri"`
+}
+
+type lspDidChangeWatchedFilesParams struct {
+	Changes []lspFileEvent `json:"changes"`
+}
+
+const lspDiagnosticSeverityError = 1
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string           `json:"uri"`
+	Version     int              `json:"version,omitempty"`
+	Diagnostics []*lspDiagnostic `json:"diagnostics"`
+}
+
+type lspCodeActionContext struct {
+	Diagnostics []*lspDiagnostic `json:"diagnostics"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Range        lspRange                  `json:"range"`
+	Context      lspCodeActionContext      `json:"context"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspTextDocumentEdit struct {
+	TextDocument lspOptionalVersionedTextDocumentIdentifier `json:"textDocument"`
+	Edits        []*lspTextEdit                             `json:"edits"`
+}
+
+type lspCreateFile struct {
+	Kind string `json:"kind"`
+	URI  string `json:"uri"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes         map[string][]*lspTextEdit `json:"changes,omitempty"`
+	DocumentChanges []interface{}             `json:"documentChanges,omitempty"`
+}
+
+type lspCodeAction struct {
+	Title       string            `json:"title"`
+	Kind        string            `json:"kind"`
+	Diagnostics []*lspDiagnostic  `json:"diagnostics,omitempty"`
+	IsPreferred bool              `json:"isPreferred,omitempty"`
+	Edit        *lspWorkspaceEdit `json:"edit,omitempty"`
+}
+
+type lspTextDocumentPositionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Position     lspPosition               `json:"position"`
+}
+
+type lspReferenceContext struct {
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+type lspReferenceParams struct {
+	lspTextDocumentPositionParams
+	Context lspReferenceContext `json:"context"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspRenameParams struct {
+	lspTextDocumentPositionParams
+	NewName string `json:"newName"`
+}
+
+type lspSemanticTokensParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspSemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+type lspDocumentSymbolParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspSymbolKind int
+
+const (
+	lspSymbolKindClass    lspSymbolKind = 5
+	lspSymbolKindField    lspSymbolKind = 8
+	lspSymbolKindFunction lspSymbolKind = 12
+	lspSymbolKindEvent    lspSymbolKind = 24
+)
+
+type lspDocumentSymbol struct {
+	Name           string               `json:"name"`
+	Detail         string               `json:"detail,omitempty"`
+	Kind           lspSymbolKind        `json:"kind"`
+	Range          lspRange             `json:"range"`
+	SelectionRange lspRange             `json:"selectionRange"`
+	Children       []*lspDocumentSymbol `json:"children,omitempty"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    *lspRange        `json:"range,omitempty"`
+}
+
+const (
+	lspCompletionItemKindFunction  = 3
+	lspCompletionItemKindField     = 5
+	lspCompletionItemKindVariable  = 6
+	lspCompletionItemKindProperty  = 10
+	lspCompletionItemKindReference = 18
+)
+
+type lspCompletionItem struct {
+	Label      string       `json:"label"`
+	Kind       int          `json:"kind,omitempty"`
+	Detail     string       `json:"detail,omitempty"`
+	InsertText string       `json:"insertText,omitempty"`
+	TextEdit   *lspTextEdit `json:"textEdit,omitempty"`
+}
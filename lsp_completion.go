@@ -0,0 +1,192 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+)
+
+// complete returns completion items at the position. Contexts, their properties, and functions are
+// completed in expressions. Job IDs are completed in "needs:" and input names of actions are
+// completed under "with:".
+func (s *LSPServer) complete(params *lspTextDocumentPositionParams) []*lspCompletionItem {
+	items := []*lspCompletionItem{}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return items
+	}
+	lines := d.lines()
+	line := params.Position.Line
+	if line >= len(lines) {
+		return items
+	}
+	l := lines[line]
+	char := lspByteOffset(l, params.Position.Character)
+	if char > len(l) {
+		char = len(l)
+	}
+	path, onKey := lspYAMLPathAt(lines, line, char)
+
+	if src, off, ok := lspExprAt(l, char); ok {
+		return d.completeExpr(l, src, off, line, char)
+	}
+	if src, off, ok := lspIfCondAt(l, line, char, path, onKey); ok {
+		return d.completeExpr(l, src, off, line, char)
+	}
+	if len(path) == 0 {
+		return items
+	}
+
+	ks := lspPathKeys(path)
+	last := path[len(path)-1]
+	if len(ks) >= 3 && ks[0] == "jobs" && ks[len(ks)-1] == "needs" && !onKey {
+		for _, r := range findYAMLJobRanges(lines) {
+			if r.id != ks[1] {
+				items = append(items, &lspCompletionItem{
+					Label:  r.id,
+					Kind:   lspCompletionItemKindReference,
+					Detail: "job",
+				})
+			}
+		}
+		return items
+	}
+
+	// Input names are completed at a new line under "with:" or at the key under "with:"
+	inWith := last.key == "with" && last.line != line && !onKey
+	if !inWith && onKey && len(path) >= 2 && path[len(path)-2].key == "with" {
+		inWith = true
+		path = path[:len(path)-1]
+	}
+	if inWith && len(ks) >= 2 && ks[0] == "jobs" {
+		var uses string
+		if item := lspStepItemAt(path); item != nil {
+			uses, _ = lspItemValue(lines, item, "uses")
+		} else if len(path) == 3 {
+			// "with:" of job which calls reusable workflow. Find "uses:" in the children of the job
+			j := path[1]
+			uses, _ = lspItemValue(lines, &lspYAMLPathElem{"", j.line + 1, j.col}, "uses")
+		}
+		if uses != "" {
+			items = append(items, d.completeInputs(uses)...)
+		}
+	}
+
+	return items
+}
+
+// completeExpr returns completion items of the expression at the byte offset char in the line l.
+func (d *lspDocument) completeExpr(l, src string, offset, line, char int) []*lspCompletionItem {
+	parents, word, end := lspExprChainAt(src, char-offset)
+	end += offset
+	edit := func(label string) *lspTextEdit {
+		// Replace the entire identifier at the cursor
+		return &lspTextEdit{
+			Range:   lspRange{Start: lspPosition{line, lspCharOf(l, end-len(word))}, End: lspPosition{line, lspCharOf(l, end)}},
+			NewText: label,
+		}
+	}
+
+	items := []*lspCompletionItem{}
+	scope := lspExprScopeAt(d.workflow, d.project, d.config, &Pos{Line: line + 1, Col: ColumnUnitByte.ToRune(l, char+1)})
+	if len(parents) == 0 {
+		for _, n := range sortedTypeNames(scope.vars) {
+			if scope.contextAvailable(n) {
+				items = append(items, &lspCompletionItem{
+					Label:    n,
+					Kind:     lspCompletionItemKindVariable,
+					Detail:   "context",
+					TextEdit: edit(n),
+				})
+			}
+		}
+		fs := make([]string, 0, len(scope.funcs))
+		for n := range scope.funcs {
+			fs = append(fs, n)
+		}
+		sort.Strings(fs)
+		for _, n := range fs {
+			if scope.funcAvailable(n) {
+				sig := scope.funcs[n][0]
+				items = append(items, &lspCompletionItem{
+					Label:    sig.Name,
+					Kind:     lspCompletionItemKindFunction,
+					Detail:   sig.String(),
+					TextEdit: edit(sig.Name),
+				})
+			}
+		}
+		return items
+	}
+
+	o, ok := scope.resolve(parents).(*ObjectType)
+	if !ok {
+		return items
+	}
+	for _, n := range sortedTypeNames(o.Props) {
+		items = append(items, &lspCompletionItem{
+			Label:    n,
+			Kind:     lspCompletionItemKindProperty,
+			Detail:   o.Props[n].String(),
+			TextEdit: edit(n),
+		})
+	}
+	return items
+}
+
+// completeInputs returns completion items of input names of the action or the reusable workflow
+// specified at "uses:".
+func (d *lspDocument) completeInputs(uses string) []*lspCompletionItem {
+	type input struct {
+		name     string
+		required bool
+	}
+	var inputs []input
+	if strings.HasPrefix(uses, "./") && d.project != nil {
+		if strings.Contains(uses, "/.github/workflows/") {
+			m, err := NewLocalReusableWorkflowCache(d.project, d.project.RootDir(), nil).FindMetadata(uses)
+			if err != nil || m == nil {
+				return nil
+			}
+			for _, i := range m.Inputs {
+				inputs = append(inputs, input{i.Name, i.Required})
+			}
+		} else {
+			m, _, err := NewLocalActionsCache(d.project, nil).FindMetadata(uses)
+			if err != nil || m == nil {
+				return nil
+			}
+			for _, i := range m.Inputs {
+				inputs = append(inputs, input{i.Name, i.Required})
+			}
+		}
+	} else if m, ok := PopularActions[uses]; ok {
+		for _, i := range m.Inputs {
+			inputs = append(inputs, input{i.Name, i.Required})
+		}
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].name < inputs[j].name })
+
+	items := make([]*lspCompletionItem, 0, len(inputs))
+	for _, i := range inputs {
+		detail := "optional input"
+		if i.required {
+			detail = "required input"
+		}
+		items = append(items, &lspCompletionItem{
+			Label:      i.name,
+			Kind:       lspCompletionItemKindField,
+			Detail:     detail,
+			InsertText: i.name + ": ",
+		})
+	}
+	return items
+}
+
+func sortedTypeNames(m map[string]ExprType) []string {
+	ns := make([]string, 0, len(m))
+	for n := range m {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+	return ns
+}
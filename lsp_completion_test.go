@@ -0,0 +1,233 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testLSPCompletionLabels(t *testing.T, s *LSPServer, uri, text string, pos map[string]interface{}) ([]string, []*lspCompletionItem) {
+	t.Helper()
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, text), testLSPPositionRequest(1, "textDocument/completion", uri, pos))
+	if m := msgs[len(msgs)-1]; m.Error != nil {
+		t.Fatal(m.Error)
+	}
+	var items []*lspCompletionItem
+	if err := json.Unmarshal(msgs[len(msgs)-1].Result, &items); err != nil {
+		t.Fatal(err)
+	}
+	labels := make([]string, 0, len(items))
+	for _, i := range items {
+		labels = append(labels, i.Label)
+	}
+	return labels, items
+}
+
+func TestLSPServerCompletion(t *testing.T) {
+	proj, uri := testLSPProject(t, "")
+	testStagedWrite(t, proj, ".github/actions/my-action/action.yml", "name: My action\ndescription: test\ninputs:\n  foo:\n    required: true\n  bar:\n    description: bar\nruns:\n  using: node20\n  main: index.js\n")
+	testStagedWrite(t, proj, ".github/workflows/reusable.yaml", "on:\n  workflow_call:\n    inputs:\n      name:\n        type: string\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n")
+
+	local := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/my-action
+        with:
+          
+  call:
+    uses: ./.github/workflows/reusable.yaml
+    with:
+      
+`
+
+	testCases := []struct {
+		what    string
+		text    string
+		marker  string
+		offset  int
+		want    []string
+		exclude []string
+	}{
+		{
+			what:   "steps context",
+			text:   testLSPWorkflow,
+			marker: "steps.co.outputs.commit",
+			offset: len("steps."),
+			want:   []string{"co", "get"},
+		},
+		{
+			what:   "outputs of action",
+			text:   testLSPWorkflow,
+			marker: "steps.co.outputs.commit",
+			offset: len("steps.co.outputs."),
+			want:   []string{"commit", "ref"},
+		},
+		{
+			what:   "needs context",
+			text:   testLSPWorkflow,
+			marker: "needs.build.outputs",
+			offset: len("needs."),
+			want:   []string{"build"},
+		},
+		{
+			what:   "job outputs",
+			text:   testLSPWorkflow,
+			marker: "needs.build.outputs.ver",
+			offset: len("needs.build.outputs."),
+			want:   []string{"ver"},
+		},
+		{
+			what:    "contexts and functions",
+			text:    testLSPWorkflow,
+			marker:  "github.ref",
+			offset:  0,
+			want:    []string{"github", "inputs", "secrets", "vars", "contains", "startsWith"},
+			exclude: []string{"steps", "env", "success", "hashFiles"},
+		},
+		{
+			what:    "if condition without ${{ }}",
+			text:    testLSPWorkflow,
+			marker:  "success()",
+			offset:  0,
+			want:    []string{"steps", "success", "always", "hashFiles"},
+			exclude: []string{"secrets"},
+		},
+		{
+			what:   "needs",
+			text:   testLSPWorkflow,
+			marker: "[build]",
+			offset: 1,
+			want:   []string{"build"},
+		},
+		{
+			what:   "inputs of popular action",
+			text:   testLSPWorkflow,
+			marker: "fetch-depth",
+			offset: 1,
+			want:   []string{"clean", "fetch-depth", "fetch-tags", "filter", "github-server-url", "lfs", "path", "persist-credentials", "ref", "repository", "set-safe-directory", "show-progress", "sparse-checkout", "sparse-checkout-cone-mode", "ssh-key", "ssh-known-hosts", "ssh-strict", "ssh-user", "submodules", "token"},
+		},
+		{
+			what:   "inputs of local action",
+			text:   local,
+			marker: "with:\n          \n",
+			offset: len("with:\n          "),
+			want:   []string{"bar", "foo"},
+		},
+		{
+			what:   "inputs of local reusable workflow",
+			text:   local,
+			marker: "with:\n      \n",
+			offset: len("with:\n      "),
+			want:   []string{"name"},
+		},
+		{
+			what:   "nothing",
+			text:   testLSPWorkflow,
+			marker: "ubuntu-latest",
+			offset: 1,
+			want:   []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := NewLSPServer(&LSPOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos := testLSPPositionOf(t, tc.text, tc.marker, tc.offset)
+			labels, _ := testLSPCompletionLabels(t, s, uri, tc.text, pos)
+			if len(tc.exclude) == 0 && len(tc.want) == len(labels) {
+				if diff := cmp.Diff(tc.want, labels); diff != "" {
+					t.Fatal(diff)
+				}
+				return
+			}
+			have := map[string]struct{}{}
+			for _, l := range labels {
+				have[l] = struct{}{}
+			}
+			for _, w := range tc.want {
+				if _, ok := have[w]; !ok {
+					t.Errorf("%q is not included in %q", w, labels)
+				}
+			}
+			for _, e := range tc.exclude {
+				if _, ok := have[e]; ok {
+					t.Errorf("%q should not be included in %q", e, labels)
+				}
+			}
+			if len(tc.exclude) == 0 {
+				t.Errorf("wanted %q but got %q", tc.want, labels)
+			}
+		})
+	}
+}
+
+func TestLSPServerCompletionItems(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, items := testLSPCompletionLabels(t, s, uri, testLSPWorkflow, testLSPPositionOf(t, testLSPWorkflow, "ref }}", 2))
+	want := &lspCompletionItem{
+		Label:  "ref",
+		Kind:   lspCompletionItemKindProperty,
+		Detail: "string",
+		TextEdit: &lspTextEdit{
+			Range:   lspRange{Start: lspPosition{19, 22}, End: lspPosition{19, 25}},
+			NewText: "ref",
+		},
+	}
+	var found *lspCompletionItem
+	for _, i := range items {
+		if i.Label == "ref" {
+			found = i
+		}
+	}
+	if diff := cmp.Diff(want, found); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Completion works while the workflow is broken by editing
+	broken := strings.Replace(testLSPWorkflow, "steps.co.outputs.commit }}", "steps.", 1)
+	labels, _ := testLSPCompletionLabels(t, s, uri, broken, testLSPPositionOf(t, broken, "steps.\n", len("steps.")))
+	if diff := cmp.Diff([]string{"co", "get"}, labels); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Input of action at the key
+	_, items = testLSPCompletionLabels(t, s, uri, testLSPWorkflow, testLSPPositionOf(t, testLSPWorkflow, "fetch-depth", 5))
+	for _, i := range items {
+		if i.Label == "fetch-depth" && i.InsertText != "fetch-depth: " {
+			t.Fatalf("unexpected insert text %q", i.InsertText)
+		}
+	}
+}
+
+func TestLSPServerCompletionMultibyte(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"🚀 日本語 ${{ github.re }}\"\n"
+	pos := testLSPPositionOf(t, src, "re }}", 2)
+	_, items := testLSPCompletionLabels(t, s, uri, src, pos)
+	end := pos["character"].(int)
+	want := &lspTextEdit{Range: lspRange{Start: lspPosition{5, end - len("re")}, End: lspPosition{5, end}}, NewText: "ref"}
+	for _, i := range items {
+		if i.Label == "ref" {
+			if diff := cmp.Diff(want, i.TextEdit); diff != "" {
+				t.Fatal(diff)
+			}
+			return
+		}
+	}
+	t.Fatal("\"ref\" is not completed:", items)
+}
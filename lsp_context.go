@@ -0,0 +1,372 @@
+package actionlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lspYAMLPathElem is an element of the path from the root of a YAML document to some position. It
+// is a key of mapping or an item of sequence. The key is empty when it is an item of sequence.
+type lspYAMLPathElem struct {
+	key  string
+	line int // 0-based
+	col  int // 0-based
+}
+
+// Matches to a line which has mapping key like "  - foo: ...". The 2nd group is markers of sequence
+// items and the 3rd group is the key.
+var reLSPYAMLKeyLine = regexp.MustCompile(`^( *)((?:- +)*)([^\s#'"{}\[\],&*!|>%@` + "`" + `-][^#:]*?|"[^"]*"|'[^']*') *:(?: |$)`)
+
+// Matches to a line which only has markers of sequence items like "  - foo".
+var reLSPYAMLItemLine = regexp.MustCompile(`^( *)((?:-(?: +|$))+)`)
+
+func lspYAMLLineElems(l string, line int) []*lspYAMLPathElem {
+	var markers string
+	var offset int
+	var key *lspYAMLPathElem
+	if m := reLSPYAMLKeyLine.FindStringSubmatchIndex(l); m != nil {
+		markers, offset = l[m[4]:m[5]], m[4]
+		k := l[m[6]:m[7]]
+		if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') {
+			k = k[1 : len(k)-1]
+		}
+		key = &lspYAMLPathElem{k, line, m[6]}
+	} else if m := reLSPYAMLItemLine.FindStringSubmatchIndex(l); m != nil {
+		markers, offset = l[m[4]:m[5]], m[4]
+	}
+
+	ret := []*lspYAMLPathElem{}
+	for i, c := range markers {
+		if c == '-' {
+			ret = append(ret, &lspYAMLPathElem{"", line, offset + i})
+		}
+	}
+	if key != nil {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+// lspYAMLPathAt returns the path from the root of the YAML source to the position by scanning
+// indentation of the lines. Since it does not parse the source, it works with broken sources while
+// editing. Flow style mappings and sequences are not supported. The second return value is true
+// when the position is on the key of the last element.
+func lspYAMLPathAt(lines []string, line, char int) ([]*lspYAMLPathElem, bool) {
+	if line >= len(lines) {
+		return nil, false
+	}
+
+	rev := []*lspYAMLPathElem{}
+	onKey := false
+	l := lines[line]
+	threshold := char
+	if t := strings.TrimLeft(l, " "); t != "" && len(l)-len(t) < threshold {
+		threshold = len(l) - len(t)
+	}
+	es := lspYAMLLineElems(l, line)
+	for i := len(es) - 1; i >= 0; i-- {
+		e := es[i]
+		if e.col > char {
+			continue
+		}
+		if e.key != "" {
+			onKey = char <= e.col+len(e.key)
+		}
+		rev = append(rev, e)
+		threshold = e.col
+	}
+
+	for i := line - 1; i >= 0 && threshold > 0; i-- {
+		l := lines[i]
+		t := strings.TrimLeft(l, " ")
+		if t == "" || t[0] == '#' || len(l)-len(t) >= threshold {
+			continue
+		}
+		es := lspYAMLLineElems(l, i)
+		for j := len(es) - 1; j >= 0; j-- {
+			if e := es[j]; e.col < threshold {
+				rev = append(rev, e)
+				threshold = e.col
+			}
+		}
+	}
+
+	ret := make([]*lspYAMLPathElem, 0, len(rev))
+	for i := len(rev) - 1; i >= 0; i-- {
+		ret = append(ret, rev[i])
+	}
+	return ret, onKey
+}
+
+// lspPathKeys returns keys in the path. Items of sequences are omitted.
+func lspPathKeys(path []*lspYAMLPathElem) []string {
+	ks := make([]string, 0, len(path))
+	for _, e := range path {
+		if e.key != "" {
+			ks = append(ks, e.key)
+		}
+	}
+	return ks
+}
+
+// Children of these keys are IDs defined by users.
+var lspWorkflowKeyPlaceholders = map[string]string{
+	"env":      "<env_id>",
+	"inputs":   "<inputs_id>",
+	"outputs":  "<output_id>",
+	"secrets":  "<secrets_id>",
+	"services": "<service_id>",
+	"with":     "<with_id>",
+}
+
+// lspWorkflowKeyOf converts the path to the workflow key like "jobs.<job_id>.steps.if". The keys
+// are used by WorkflowKeyAvailability.
+func lspWorkflowKeyOf(path []*lspYAMLPathElem) string {
+	ks := lspPathKeys(path)
+	for i := range ks {
+		if i == 1 && ks[0] == "jobs" {
+			ks[i] = "<job_id>"
+			continue
+		}
+		if i > 0 {
+			if p, ok := lspWorkflowKeyPlaceholders[ks[i-1]]; ok {
+				ks[i] = p
+			}
+		}
+	}
+	return strings.Join(ks, ".")
+}
+
+// lspKeyAvailability returns contexts and special functions available at the workflow key. When the
+// key is not found, the availability of the nearest parent key is returned. The last return value
+// is the workflow key which was found.
+func lspKeyAvailability(key string) ([]string, []string, string) {
+	for key != "" {
+		if ctx, sp := WorkflowKeyAvailability(key); ctx != nil {
+			return ctx, sp, key
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return nil, nil, ""
+}
+
+// lspExprAt finds the ${{ }} expression at the position in the line. It returns the source of the
+// expression and the offset of the source in the line.
+func lspExprAt(line string, char int) (string, int, bool) {
+	if char > len(line) {
+		char = len(line)
+	}
+	start := strings.LastIndex(line[:char], "${{")
+	if start < 0 || strings.Contains(line[start:char], "}}") {
+		return "", 0, false
+	}
+	start += 3
+	end := strings.Index(line[start:], "}}")
+	if end < 0 {
+		end = len(line) // Expression is not closed yet while editing
+	} else {
+		end += start
+	}
+	return line[start:end], start, true
+}
+
+// lspIfCondAt returns the source of the condition at "if:" which is not enclosed in ${{ }} and the
+// offset of the source in the line.
+func lspIfCondAt(line string, lnum, char int, path []*lspYAMLPathElem, onKey bool) (string, int, bool) {
+	if onKey || len(path) == 0 || strings.Contains(line, "${{") {
+		return "", 0, false
+	}
+	k := path[len(path)-1]
+	if k.key != "if" || k.line != lnum {
+		return "", 0, false
+	}
+	start := k.col + len("if:")
+	if start > len(line) || start > char {
+		return "", 0, false
+	}
+	return line[start:], start, true
+}
+
+func isLSPExprIdentChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// lspExprChainAt returns the chain of property accesses like ["github", "event"] before the offset
+// in the expression source and the identifier at the offset. For example, when the source is
+// "github.event.act" and the offset is at the end, it returns ["github", "event"] and "act". The
+// last return value is the end offset of the identifier.
+func lspExprChainAt(src string, offset int) ([]string, string, int) {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	start := offset
+	for start > 0 && (isLSPExprIdentChar(src[start-1]) || src[start-1] == '.') {
+		start--
+	}
+	end := offset
+	for end < len(src) && isLSPExprIdentChar(src[end]) {
+		end++
+	}
+	ss := strings.Split(src[start:end], ".")
+	return ss[:len(ss)-1], ss[len(ss)-1], end
+}
+
+// lspExprScope is a set of contexts and functions available in the expression.
+type lspExprScope struct {
+	key      string
+	vars     map[string]ExprType
+	funcs    map[string][]*FuncSignature
+	contexts []string // Empty means all contexts are available
+	special  []string // Special functions available in the expression
+}
+
+// lspExprScopeAt returns the scope of the expression at the position. Types of contextual contexts
+// like "steps" or "matrix" are resolved with the same logic as "expression" rule.
+func lspExprScopeAt(w *Workflow, proj *Project, cfg *Config, pos *Pos) *lspExprScope {
+	var ret *lspExprScope
+	if w != nil {
+		var actions *LocalActionsCache
+		var workflows *LocalReusableWorkflowCache
+		if proj != nil {
+			actions = NewLocalActionsCache(proj, nil)
+			workflows = NewLocalReusableWorkflowCache(proj, proj.RootDir(), nil)
+		} else {
+			actions = newNullLocalActionsCache(nil)
+			workflows = newNullLocalReusableWorkflowCache(nil)
+		}
+		r := NewRuleExpression()
+		r.SetCapabilities(&Capabilities{LocalActions: actions, LocalReusableWorkflows: workflows})
+		r.SetConfig(cfg)
+
+		var at *Pos
+		r.onExprScope = func(p *Pos, key string, sema *ExprSemanticsChecker) {
+			if pos.IsBefore(p) || at != nil && p.IsBefore(at) {
+				return
+			}
+			at = p
+			// Types are copied since they are modified while visiting the rest of the workflow
+			vars := make(map[string]ExprType, len(sema.vars))
+			for n, t := range sema.vars {
+				vars[n] = t.DeepCopy()
+			}
+			ret = &lspExprScope{
+				key:      key,
+				vars:     vars,
+				funcs:    sema.funcs,
+				contexts: sema.availableContexts,
+				special:  sema.availableSpecialFuncs,
+			}
+		}
+
+		v := NewVisitor()
+		v.AddPass(r)
+		v.Visit(w) // Errors are not needed
+	}
+
+	if ret == nil {
+		ret = &lspExprScope{vars: BuiltinGlobalVariableTypes, funcs: BuiltinFuncSignatures}
+	}
+	return ret
+}
+
+// resolve returns the type of the property access chain like ["github", "event"].
+func (s *lspExprScope) resolve(chain []string) ExprType {
+	if len(chain) == 0 {
+		return nil
+	}
+	ty, ok := s.vars[strings.ToLower(chain[0])]
+	if !ok {
+		return nil
+	}
+	for _, p := range chain[1:] {
+		o, ok := ty.(*ObjectType)
+		if !ok {
+			return nil
+		}
+		if t, ok := o.Props[strings.ToLower(p)]; ok {
+			ty = t
+		} else if o.Mapped != nil {
+			ty = o.Mapped
+		} else {
+			return nil
+		}
+	}
+	return ty
+}
+
+func (s *lspExprScope) contextAvailable(name string) bool {
+	if len(s.contexts) == 0 {
+		return true
+	}
+	for _, c := range s.contexts {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// funcAvailable returns true when the function is available in the scope. Special functions like
+// success() are only available at specific workflow keys.
+func (s *lspExprScope) funcAvailable(name string) bool {
+	if _, ok := SpecialFunctionNames[name]; !ok {
+		return true
+	}
+	for _, f := range s.special {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lspStepItemAt returns the item of "steps" sequence which contains the last element of the path.
+func lspStepItemAt(path []*lspYAMLPathElem) *lspYAMLPathElem {
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].key == "" && path[i-1].key == "steps" {
+			return path[i]
+		}
+	}
+	return nil
+}
+
+// lspItemValue finds the value of the key in the sequence item. The item is at the line and the
+// column. It returns false when the key is not found.
+func lspItemValue(lines []string, item *lspYAMLPathElem, key string) (string, bool) {
+	keyCol := -1
+	for i := item.line; i < len(lines); i++ {
+		l := lines[i]
+		t := strings.TrimLeft(l, " ")
+		if t == "" || t[0] == '#' {
+			continue
+		}
+		if i > item.line && len(l)-len(t) <= item.col {
+			break // Next item or parent
+		}
+		for _, e := range lspYAMLLineElems(l, i) {
+			if e.key == "" {
+				continue
+			}
+			if keyCol < 0 {
+				keyCol = e.col
+			}
+			if e.col != keyCol || e.key != key {
+				continue
+			}
+			v := l[e.col:]
+			v = strings.TrimSpace(v[strings.IndexByte(v, ':')+1:])
+			if j := strings.Index(v, " #"); j >= 0 {
+				v = strings.TrimSpace(v[:j])
+			}
+			if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+				v = v[1 : len(v)-1]
+			}
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,87 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLSPYAMLPathAt(t *testing.T) {
+	src := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+
+      - run: |
+          echo hello
+        "if": ${{ always() }}
+`
+	lines := strings.Split(src, "\n")
+
+	testCases := []struct {
+		line  int
+		char  int
+		path  string
+		key   string
+		onKey bool
+	}{
+		{0, 0, "on", "on", true},
+		{0, 5, "on", "on", false},
+		{3, 6, "jobs.build.runs-on", "jobs.<job_id>.runs-on", true},
+		{3, 14, "jobs.build.runs-on", "jobs.<job_id>.runs-on", false},
+		{5, 6, "jobs.build.steps.-", "jobs.<job_id>.steps", false},
+		{5, 9, "jobs.build.steps.-.uses", "jobs.<job_id>.steps.uses", true},
+		{7, 12, "jobs.build.steps.-.with.fetch-depth", "jobs.<job_id>.steps.with.<with_id>", true},
+		{8, 10, "jobs.build.steps.-.with", "jobs.<job_id>.steps.with", false},
+		{8, 8, "jobs.build.steps.-", "jobs.<job_id>.steps", false},
+		{10, 12, "jobs.build.steps.-.run", "jobs.<job_id>.steps.run", false},
+		{11, 9, "jobs.build.steps.-.if", "jobs.<job_id>.steps.if", true},
+		{12, 0, "", "", false},
+	}
+
+	for _, tc := range testCases {
+		path, onKey := lspYAMLPathAt(lines, tc.line, tc.char)
+		ss := make([]string, 0, len(path))
+		for _, e := range path {
+			if e.key == "" {
+				ss = append(ss, "-")
+			} else {
+				ss = append(ss, e.key)
+			}
+		}
+		if have := strings.Join(ss, "."); have != tc.path {
+			t.Errorf("path at %d:%d should be %q but got %q", tc.line, tc.char, tc.path, have)
+		}
+		if have := lspWorkflowKeyOf(path); have != tc.key {
+			t.Errorf("workflow key at %d:%d should be %q but got %q", tc.line, tc.char, tc.key, have)
+		}
+		if onKey != tc.onKey {
+			t.Errorf("position %d:%d should be on key=%v", tc.line, tc.char, tc.onKey)
+		}
+	}
+}
+
+func TestLSPExprChainAt(t *testing.T) {
+	testCases := []struct {
+		src    string
+		offset int
+		chain  string
+		word   string
+	}{
+		{" github.event.act ", 17, "github.event", "act"},
+		{" github.event.action ", 10, "github", "event"},
+		{" contains(github.ref, 'x') ", 4, "", "contains"},
+		{" steps. ", 7, "steps", ""},
+		{" ", 1, "", ""},
+		{"!success()", 3, "", "success"},
+	}
+	for _, tc := range testCases {
+		chain, word, _ := lspExprChainAt(tc.src, tc.offset)
+		if have := strings.Join(chain, "."); have != tc.chain || word != tc.word {
+			t.Errorf("wanted %q and %q at %d of %q but got %q and %q", tc.chain, tc.word, tc.offset, tc.src, have, word)
+		}
+	}
+}
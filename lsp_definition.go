@@ -0,0 +1,423 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type lspRefKind int
+
+const (
+	lspRefKindJob lspRefKind = iota
+	lspRefKindStep
+)
+
+func (k lspRefKind) String() string {
+	if k == lspRefKindStep {
+		return "step"
+	}
+	return "job"
+}
+
+// lspRef is a declaration of job ID or step ID, or a reference to it. References are the items of
+// "needs:" and property accesses like "needs.build" or "steps.test" in expressions.
+type lspRef struct {
+	kind lspRefKind
+	id   string
+	job  string // ID of the job which contains the step. Empty for jobs
+	line int    // 0-based
+	col  int    // 0-based byte offset in the line
+	decl bool
+}
+
+func (r *lspRef) contains(line, char int) bool {
+	return r.line == line && r.col <= char && char <= r.col+len(r.id)
+}
+
+func (r *lspRef) sameTarget(o *lspRef) bool {
+	return r.kind == o.kind && strings.EqualFold(r.id, o.id) && strings.EqualFold(r.job, o.job)
+}
+
+// lspRange returns the range of the reference in the lines. The characters of the range are counted
+// in UTF-16 code units as required by the LSP specification.
+func (r *lspRef) lspRange(lines []string) lspRange {
+	c := r.col
+	if r.line < len(lines) {
+		c = lspCharOf(lines[r.line], c)
+	}
+	return lspRange{Start: lspPosition{r.line, c}, End: lspPosition{r.line, c + lspUTF16Len(r.id)}}
+}
+
+// Matches to property accesses to job IDs or step IDs like "needs.build" or "steps.test".
+var reLSPExprRef = regexp.MustCompile(`(?i)(needs|steps)\.([a-z_][a-z0-9_-]*)`)
+
+// lspStringRange returns the range of the string value in the source lines. Quotes are not included.
+// The characters of the range are counted in UTF-16 code units.
+func lspStringRange(lines []string, s *String) lspRange {
+	p := lspPositionIn(lines, s.Pos.Line, s.Pos.Col)
+	if s.Quoted {
+		p.Character++
+	}
+	return lspRange{Start: p, End: lspPosition{p.Line, p.Character + lspUTF16Len(s.Value)}}
+}
+
+// lspRefOf returns the reference at the string value. Columns of positions in the syntax tree are
+// counted in Unicode code points so they are converted into byte offsets in the line.
+func lspRefOf(kind lspRefKind, s *String, job string, decl bool, lines []string) *lspRef {
+	p := lspPositionOf(s.Pos.Line, s.Pos.Col)
+	if p.Line < len(lines) {
+		p.Character = ColumnUnitByte.FromRune(lines[p.Line], p.Character+1) - 1
+	}
+	if s.Quoted {
+		p.Character++
+	}
+	return &lspRef{kind, s.Value, job, p.Line, p.Character, decl}
+}
+
+// lspJobAt returns the job which contains the line. It returns nil when the line is not in jobs.
+func lspJobAt(w *Workflow, line int) *Job {
+	var ret *Job
+	for _, j := range w.Jobs {
+		if j.ID == nil || j.ID.Pos == nil {
+			continue
+		}
+		l := j.ID.Pos.Line - 1
+		if l <= line && (ret == nil || ret.ID.Pos.Line-1 < l) {
+			ret = j
+		}
+	}
+	return ret
+}
+
+// lspWorkflowRefs collects declarations of job IDs and step IDs and all references to them in the
+// workflow. Declarations and "needs:" items are collected from the syntax tree and references in
+// expressions are collected from the source lines.
+func lspWorkflowRefs(w *Workflow, lines []string) []*lspRef {
+	refs := []*lspRef{}
+	if w == nil {
+		return refs
+	}
+
+	for _, j := range w.Jobs {
+		if j.ID == nil || j.ID.Pos == nil {
+			continue
+		}
+		refs = append(refs, lspRefOf(lspRefKindJob, j.ID, "", true, lines))
+		for _, n := range j.Needs {
+			if n.Pos != nil {
+				refs = append(refs, lspRefOf(lspRefKindJob, n, "", false, lines))
+			}
+		}
+		for _, s := range j.Steps {
+			if s.ID != nil && s.ID.Pos != nil && !ContainsExpression(s.ID.Value) {
+				refs = append(refs, lspRefOf(lspRefKindStep, s.ID, j.ID.Value, true, lines))
+			}
+		}
+	}
+
+	for i, l := range lines {
+		for _, m := range reLSPExprRef.FindAllStringSubmatchIndex(l, -1) {
+			if s := m[0]; s > 0 && (isLSPExprIdentChar(l[s-1]) || l[s-1] == '.') {
+				continue // Property access like "github.event.steps"
+			}
+			if !lspInExpr(lines, i, m[0]) {
+				continue
+			}
+			r := &lspRef{id: l[m[4]:m[5]], line: i, col: m[4]}
+			if strings.EqualFold(l[m[2]:m[3]], "steps") {
+				j := lspJobAt(w, i)
+				if j == nil {
+					continue
+				}
+				r.kind = lspRefKindStep
+				r.job = j.ID.Value
+			}
+			refs = append(refs, r)
+		}
+	}
+
+	return refs
+}
+
+// lspInExpr returns true when the position is in ${{ }} or in the condition at "if:".
+func lspInExpr(lines []string, line, char int) bool {
+	l := lines[line]
+	if _, _, ok := lspExprAt(l, char); ok {
+		return true
+	}
+	path, onKey := lspYAMLPathAt(lines, line, char)
+	_, _, ok := lspIfCondAt(l, line, char, path, onKey)
+	return ok
+}
+
+// references returns the locations of the references to the job ID or the step ID at the position.
+func (s *LSPServer) references(params *lspReferenceParams) []*lspLocation {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	lines := d.lines()
+	refs := lspWorkflowRefs(d.workflow, lines)
+	target := lspRefAt(refs, lines, params.Position)
+	if target == nil {
+		return nil
+	}
+	locs := []*lspLocation{}
+	for _, r := range refs {
+		if r.sameTarget(target) && (!r.decl || params.Context.IncludeDeclaration) {
+			locs = append(locs, &lspLocation{URI: d.uri, Range: r.lspRange(lines)})
+		}
+	}
+	sort.Slice(locs, func(i, j int) bool {
+		a, b := locs[i].Range.Start, locs[j].Range.Start
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	})
+	return locs
+}
+
+// lspRefAt returns the reference at the position. The character of the position is counted in UTF-16
+// code units.
+func lspRefAt(refs []*lspRef, lines []string, pos lspPosition) *lspRef {
+	if pos.Line >= len(lines) {
+		return nil
+	}
+	line, char := pos.Line, lspByteOffset(lines[pos.Line], pos.Character)
+	for _, r := range refs {
+		if r.contains(line, char) {
+			return r
+		}
+	}
+	return nil
+}
+
+// definition returns the locations of the definition of the symbol at the position. It resolves job
+// IDs at "needs:" and in expressions, step IDs in expressions, outputs of jobs, inputs of the
+// workflow, local actions and reusable workflows at "uses:", and inputs of them at "with:".
+func (s *LSPServer) definition(params *lspTextDocumentPositionParams) []*lspLocation {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok || d.workflow == nil {
+		return nil
+	}
+	lines := d.lines()
+	line := params.Position.Line
+	if line >= len(lines) {
+		return nil
+	}
+	char := lspByteOffset(lines[line], params.Position.Character)
+	w := d.workflow
+
+	refs := lspWorkflowRefs(w, lines)
+	if r := lspRefAt(refs, lines, params.Position); r != nil {
+		locs := []*lspLocation{}
+		for _, t := range refs {
+			if t.decl && t.sameTarget(r) {
+				locs = append(locs, &lspLocation{URI: d.uri, Range: t.lspRange(lines)})
+			}
+		}
+		return locs
+	}
+
+	if lspInExpr(lines, line, char) {
+		if loc := d.definitionInExpr(lines, line, char); loc != nil {
+			return []*lspLocation{loc}
+		}
+		return nil
+	}
+
+	if loc := d.definitionOfUses(lines, line, char); loc != nil {
+		return []*lspLocation{loc}
+	}
+	return nil
+}
+
+func (d *lspDocument) definitionInExpr(lines []string, line, char int) *lspLocation {
+	w := d.workflow
+	l := lines[line]
+	parents, word, _ := lspExprChainAt(l, char)
+	if len(parents) == 0 {
+		return nil
+	}
+	switch strings.ToLower(parents[0]) {
+	case "needs":
+		// needs.<job_id>.outputs.<output_id>
+		if len(parents) != 3 || !strings.EqualFold(parents[2], "outputs") {
+			return nil
+		}
+		j, ok := w.Jobs[strings.ToLower(parents[1])]
+		if !ok {
+			return nil
+		}
+		if o, ok := j.Outputs[strings.ToLower(word)]; ok && o.Name != nil && o.Name.Pos != nil {
+			return &lspLocation{URI: d.uri, Range: lspStringRange(lines, o.Name)}
+		}
+	case "steps":
+		// steps.<step_id>.outputs.<output_id> jumps to the step
+		j := lspJobAt(w, line)
+		if len(parents) < 2 || j == nil {
+			return nil
+		}
+		for _, s := range j.Steps {
+			if s.ID != nil && s.ID.Pos != nil && strings.EqualFold(s.ID.Value, parents[1]) {
+				return &lspLocation{URI: d.uri, Range: lspStringRange(lines, s.ID)}
+			}
+		}
+	case "inputs":
+		if len(parents) != 1 {
+			return nil
+		}
+		if n := lspWorkflowInputName(w, word); n != nil {
+			return &lspLocation{URI: d.uri, Range: lspStringRange(lines, n)}
+		}
+	}
+	return nil
+}
+
+// lspWorkflowInputName returns the name of the input declared at "workflow_call" or
+// "workflow_dispatch" event.
+func lspWorkflowInputName(w *Workflow, name string) *String {
+	for _, e := range w.On {
+		switch e := e.(type) {
+		case *WorkflowCallEvent:
+			for _, i := range e.Inputs {
+				if i.Name != nil && i.Name.Pos != nil && strings.EqualFold(i.Name.Value, name) {
+					return i.Name
+				}
+			}
+		case *WorkflowDispatchEvent:
+			for _, i := range e.Inputs {
+				if i.Name != nil && i.Name.Pos != nil && strings.EqualFold(i.Name.Value, name) {
+					return i.Name
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// definitionOfUses resolves the local action or the local reusable workflow at "uses:", and inputs
+// of them at "with:". The char parameter is a byte offset in the line.
+func (d *lspDocument) definitionOfUses(lines []string, line, char int) *lspLocation {
+	if d.project == nil {
+		return nil
+	}
+	on := func(s *String) bool {
+		if s == nil || s.Pos == nil {
+			return false
+		}
+		return lspRefOf(lspRefKindJob, s, "", false, lines).contains(line, char)
+	}
+
+	for _, j := range d.workflow.Jobs {
+		if c := j.WorkflowCall; c != nil && c.Uses != nil && strings.HasPrefix(c.Uses.Value, "./") {
+			p := filepath.Join(d.project.RootDir(), filepath.FromSlash(c.Uses.Value))
+			if on(c.Uses) {
+				return lspFileLocation(p)
+			}
+			for _, i := range c.Inputs {
+				if on(i.Name) {
+					return lspWorkflowCallInputLocation(p, i.Name.Value)
+				}
+			}
+		}
+		for _, s := range j.Steps {
+			e, ok := s.Exec.(*ExecAction)
+			if !ok || e.Uses == nil || !strings.HasPrefix(e.Uses.Value, "./") {
+				continue
+			}
+			var hit *String
+			if on(e.Uses) {
+				hit = e.Uses
+			} else {
+				for _, i := range e.Inputs {
+					if on(i.Name) {
+						hit = i.Name
+						break
+					}
+				}
+			}
+			if hit == nil {
+				continue
+			}
+			m, _, err := NewLocalActionsCache(d.project, nil).FindMetadata(e.Uses.Value)
+			if err != nil || m == nil {
+				return nil
+			}
+			p := filepath.Join(m.dir, m.file)
+			if hit == e.Uses {
+				return lspFileLocation(p)
+			}
+			return lspActionInputLocation(p, hit.Value)
+		}
+	}
+	return nil
+}
+
+func lspFileLocation(path string) *lspLocation {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return &lspLocation{URI: lspURIFromPath(path)}
+}
+
+// lspWorkflowCallInputLocation returns the location of the input declared at "workflow_call" event
+// in the reusable workflow file.
+func lspWorkflowCallInputLocation(path, name string) *lspLocation {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	w, _ := Parse(b)
+	if w == nil {
+		return nil
+	}
+	for _, e := range w.On {
+		if e, ok := e.(*WorkflowCallEvent); ok {
+			for _, i := range e.Inputs {
+				if i.Name != nil && i.Name.Pos != nil && strings.EqualFold(i.Name.Value, name) {
+					return &lspLocation{URI: lspURIFromPath(path), Range: lspStringRange(splitLines(string(b)), i.Name)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lspActionInputLocation returns the location of the input declared in the action metadata file.
+func lspActionInputLocation(path, name string) *lspLocation {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil || len(n.Content) == 0 {
+		return nil
+	}
+	inputs := lspYAMLMappingValue(n.Content[0], "inputs")
+	if inputs == nil || inputs.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(inputs.Content); i += 2 {
+		k := inputs.Content[i]
+		if strings.EqualFold(k.Value, name) {
+			return &lspLocation{URI: lspURIFromPath(path), Range: lspStringRange(splitLines(string(b)), newString(k))}
+		}
+	}
+	return nil
+}
+
+func lspYAMLMappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
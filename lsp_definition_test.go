@@ -0,0 +1,229 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testLSPDefinitionWorkflow = `on:
+  workflow_call:
+    inputs:
+      name:
+        type: string
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      ver: ${{ steps.get.outputs.ver }}
+    steps:
+      - uses: ./.github/actions/my-action
+        with:
+          foo: x
+      - id: get
+        run: echo "ver=${{ inputs.name }}" >> "$GITHUB_OUTPUT"
+  test:
+    needs: [build]
+    if: needs.build.result == 'success'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.build.outputs.ver }}
+  call:
+    needs: build
+    uses: ./.github/workflows/reusable.yaml
+    with:
+      name: foo
+`
+
+func testLSPLocations(t *testing.T, m *testLSPMessage) []*lspLocation {
+	t.Helper()
+	if m.Error != nil {
+		t.Fatal(m.Error)
+	}
+	var locs []*lspLocation
+	if err := json.Unmarshal(m.Result, &locs); err != nil {
+		t.Fatal(err, string(m.Result))
+	}
+	return locs
+}
+
+func testLSPLocationOf(t *testing.T, uri, text, marker string, length int) *lspLocation {
+	t.Helper()
+	p := testLSPPositionOf(t, text, marker, 0)
+	l, c := p["line"].(int), p["character"].(int)
+	return &lspLocation{URI: uri, Range: lspRange{Start: lspPosition{l, c}, End: lspPosition{l, c + length}}}
+}
+
+func TestLSPServerDefinition(t *testing.T) {
+	proj, uri := testLSPProject(t, "")
+	action := "name: My action\ndescription: test\ninputs:\n  foo:\n    required: true\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n"
+	testStagedWrite(t, proj, ".github/actions/my-action/action.yml", action)
+	reusable := "on:\n  workflow_call:\n    inputs:\n      name:\n        type: string\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	testStagedWrite(t, proj, ".github/workflows/reusable.yaml", reusable)
+	actionURI := lspURIFromPath(filepath.Join(proj, ".github", "actions", "my-action", "action.yml"))
+	reusableURI := lspURIFromPath(filepath.Join(proj, ".github", "workflows", "reusable.yaml"))
+
+	src := testLSPDefinitionWorkflow
+	buildJob := testLSPLocationOf(t, uri, src, "build:\n", len("build"))
+	getStep := testLSPLocationOf(t, uri, src, "get\n", len("get"))
+
+	testCases := []struct {
+		what   string
+		marker string
+		offset int
+		want   *lspLocation
+	}{
+		{"job in needs sequence", "[build]", 1, buildJob},
+		{"job in needs string", "needs: build", len("needs: bu"), buildJob},
+		{"job in if condition", "needs.build.result", len("needs."), buildJob},
+		{"job declaration", "build:\n", 0, buildJob},
+		{"job output", "needs.build.outputs.ver", len("needs.build.outputs.v"), testLSPLocationOf(t, uri, src, "ver: ${{", len("ver"))},
+		{"step ID", "steps.get.outputs", len("steps.g"), getStep},
+		{"step output", "steps.get.outputs.ver", len("steps.get.outputs.ver"), getStep},
+		{"workflow input", "inputs.name", len("inputs.n"), testLSPLocationOf(t, uri, src, "name:\n", len("name"))},
+		{"local action", "./.github/actions/my-action", 3, &lspLocation{URI: actionURI}},
+		{"input of local action", "foo: x", 1, testLSPLocationOf(t, actionURI, action, "foo:", len("foo"))},
+		{"reusable workflow", "./.github/workflows/reusable.yaml", 3, &lspLocation{URI: reusableURI}},
+		{"input of reusable workflow", "name: foo", 1, testLSPLocationOf(t, reusableURI, reusable, "name:", len("name"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := NewLSPServer(&LSPOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos := testLSPPositionOf(t, src, tc.marker, tc.offset)
+			msgs := testLSPServe(t, s, testLSPDidOpen(uri, src), testLSPPositionRequest(1, "textDocument/definition", uri, pos))
+			if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+				t.Fatal("workflow should have no error:", ds[0].Message)
+			}
+			have := testLSPLocations(t, msgs[1])
+			if diff := cmp.Diff([]*lspLocation{tc.want}, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestLSPServerDefinitionMultibyte(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	src := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - { name: "🚀 日本語", id: get, run: echo "ver=1" >> "$GITHUB_OUTPUT" }
+      - run: echo "🚀 日本語 ${{ steps.get.outputs.ver }}"
+`
+	decl := testLSPLocationOf(t, uri, src, "get,", len("get"))
+	if c := decl.Range.Start.Character; c != len(`      - { name: "🚀 日本語", id: `)-8 {
+		t.Fatal("character should be counted in UTF-16 code units:", c)
+	}
+
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPPositionRequest(1, "textDocument/definition", uri, testLSPPositionOf(t, src, "steps.get", len("steps.g"))),
+		testLSPRequest(2, "textDocument/references", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     testLSPPositionOf(t, src, "get,", 1),
+			"context":      map[string]interface{}{"includeDeclaration": true},
+		}),
+	)
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+		t.Fatal("workflow should have no error:", ds[0].Message)
+	}
+	if diff := cmp.Diff([]*lspLocation{decl}, testLSPLocations(t, msgs[1])); diff != "" {
+		t.Fatal(diff)
+	}
+	want := []*lspLocation{decl, testLSPLocationOf(t, uri, src, "get.outputs", len("get"))}
+	if diff := cmp.Diff(want, testLSPLocations(t, msgs[2])); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLSPServerDefinitionNotFound(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := testLSPDefinitionWorkflow
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPPositionRequest(1, "textDocument/definition", uri, testLSPPositionOf(t, src, "ubuntu-latest", 1)),
+		testLSPPositionRequest(2, "textDocument/definition", uri, testLSPPositionOf(t, src, "./.github/actions", 3)),
+		testLSPPositionRequest(3, "textDocument/definition", uri, map[string]interface{}{"line": 100, "character": 0}),
+		testLSPPositionRequest(4, "textDocument/definition", "file:///unknown.yaml", map[string]interface{}{"line": 0, "character": 0}),
+	)
+	for _, m := range msgs[1:] {
+		if string(m.Result) != "null" {
+			t.Errorf("no location should be returned for request %s: %s", m.ID, m.Result)
+		}
+	}
+}
+
+func TestLSPServerReferences(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	src := testLSPDefinitionWorkflow
+	loc := func(marker string, offset, length int) *lspLocation {
+		p := testLSPPositionOf(t, src, marker, offset)
+		l, c := p["line"].(int), p["character"].(int)
+		return &lspLocation{URI: uri, Range: lspRange{Start: lspPosition{l, c}, End: lspPosition{l, c + length}}}
+	}
+	build := []*lspLocation{
+		loc("build:\n", 0, 5),
+		loc("[build]", 1, 5),
+		loc("needs.build.result", len("needs."), 5),
+		loc("needs.build.outputs", len("needs."), 5),
+		loc("needs: build", len("needs: "), 5),
+	}
+
+	testCases := []struct {
+		what   string
+		marker string
+		offset int
+		decl   bool
+		want   []*lspLocation
+	}{
+		{"job declaration", "build:\n", 2, true, build},
+		{"job reference", "needs.build.outputs", len("needs.b"), true, build},
+		{"job without declaration", "[build]", 1, false, build[1:]},
+		{
+			"step",
+			"steps.get",
+			len("steps.g"),
+			true,
+			[]*lspLocation{loc("steps.get", len("steps."), 3), loc("get\n", 0, 3)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := NewLSPServer(&LSPOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := testLSPRequest(1, "textDocument/references", map[string]interface{}{
+				"textDocument": map[string]interface{}{"uri": uri},
+				"position":     testLSPPositionOf(t, src, tc.marker, tc.offset),
+				"context":      map[string]interface{}{"includeDeclaration": tc.decl},
+			})
+			msgs := testLSPServe(t, s, testLSPDidOpen(uri, src), req)
+			have := testLSPLocations(t, msgs[1])
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
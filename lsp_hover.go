@@ -0,0 +1,169 @@
+package actionlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// hover returns the document at the position. The document is made from the types of contexts,
+// the signatures of functions, the availability of contexts at workflow keys, and explanations of
+// errors at the position. It returns nil when nothing can be shown.
+func (s *LSPServer) hover(params *lspTextDocumentPositionParams) *lspHover {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	lines := d.lines()
+	line := params.Position.Line
+	if line >= len(lines) {
+		return nil
+	}
+	l := lines[line]
+	char := lspByteOffset(l, params.Position.Character)
+	path, onKey := lspYAMLPathAt(lines, line, char)
+
+	var sections []string
+	var rng *lspRange
+	if src, off, ok := lspExprAt(l, char); ok {
+		sections, rng = d.hoverExpr(l, src, off, line, char)
+	} else if src, off, ok := lspIfCondAt(l, line, char, path, onKey); ok {
+		sections, rng = d.hoverExpr(l, src, off, line, char)
+	} else if onKey {
+		k := path[len(path)-1]
+		if md := hoverWorkflowKey(path); md != "" {
+			sections = append(sections, md)
+			rng = &lspRange{Start: lspPosition{line, lspCharOf(l, k.col)}, End: lspPosition{line, lspCharOf(l, k.col+len(k.key))}}
+		}
+	}
+
+	// Explain errors at the position. Ranges of diagnostics are counted in UTF-16 code units
+	char = params.Position.Character
+	seen := map[string]struct{}{}
+	for _, e := range d.errs {
+		r := lspDiagnosticOf(e, d.text).Range
+		if r.Start.Line != line || char < r.Start.Character || r.End.Character < char {
+			continue
+		}
+		c := e.Code()
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		seen[c] = struct{}{}
+		if md, ok := ExplainErrorCode(c); ok {
+			sections = append(sections, strings.TrimSpace(md))
+		}
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+	return &lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: strings.Join(sections, "\n\n---\n\n")},
+		Range:    rng,
+	}
+}
+
+// hoverExpr returns the document of the expression at the byte offset char in the line l.
+func (d *lspDocument) hoverExpr(l, src string, offset, line, char int) ([]string, *lspRange) {
+	parents, word, end := lspExprChainAt(src, char-offset)
+	if word == "" {
+		return nil, nil
+	}
+	start := offset + end - len(word)
+	rng := &lspRange{Start: lspPosition{line, lspCharOf(l, start)}, End: lspPosition{line, lspCharOf(l, start+len(word))}}
+
+	if len(parents) == 0 && strings.HasPrefix(strings.TrimLeft(src[end:], " "), "(") {
+		if md := hoverFunction(word); md != "" {
+			return []string{md}, rng
+		}
+		return nil, nil
+	}
+
+	chain := append(parents, word)
+	scope := lspExprScopeAt(d.workflow, d.project, d.config, &Pos{Line: line + 1, Col: ColumnUnitByte.ToRune(l, char+1)})
+	ty := scope.resolve(chain)
+	if ty == nil {
+		return nil, nil
+	}
+	md := fmt.Sprintf("```\n%s: %s\n```", strings.Join(chain, "."), lspTypeString(ty))
+	if len(chain) == 1 {
+		n := strings.ToLower(word)
+		if _, ok := BuiltinGlobalVariableTypes[n]; ok {
+			md += fmt.Sprintf("\n\n[Document](https://docs.github.com/en/actions/learn-github-actions/contexts#%s-context)", n)
+		}
+	}
+	return []string{md}, rng
+}
+
+func hoverFunction(name string) string {
+	n := strings.ToLower(name)
+	sigs, ok := BuiltinFuncSignatures[n]
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("```\n")
+	for _, sig := range sigs {
+		b.WriteString(sig.String())
+		b.WriteByte('\n')
+	}
+	b.WriteString("```")
+	if keys, ok := SpecialFunctionNames[n]; ok {
+		b.WriteString("\n\nThis function is only available at ")
+		lspWriteCodeList(&b, keys)
+	}
+	fmt.Fprintf(&b, "\n\n[Document](https://docs.github.com/en/actions/learn-github-actions/expressions#%s)", n)
+	return b.String()
+}
+
+func hoverWorkflowKey(path []*lspYAMLPathElem) string {
+	ctx, sp, key := lspKeyAvailability(lspWorkflowKeyOf(path))
+	if ctx == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "**`%s`**\n\nAvailable contexts: ", key)
+	lspWriteCodeList(&b, ctx)
+	if len(sp) > 0 {
+		fs := make([]string, 0, len(sp))
+		for _, f := range sp {
+			fs = append(fs, BuiltinFuncSignatures[f][0].Name+"()")
+		}
+		b.WriteString("\n\nAvailable special functions: ")
+		lspWriteCodeList(&b, fs)
+	}
+	return b.String()
+}
+
+func lspWriteCodeList(b *strings.Builder, ss []string) {
+	for i, s := range ss {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('`')
+		b.WriteString(s)
+		b.WriteByte('`')
+	}
+}
+
+// lspTypeString returns the string representation of the type. Properties of an object are put in
+// separate lines for readability.
+func lspTypeString(ty ExprType) string {
+	o, ok := ty.(*ObjectType)
+	if !ok || !o.IsStrict() || len(o.Props) == 0 {
+		return ty.String()
+	}
+	ps := make([]string, 0, len(o.Props))
+	for p := range o.Props {
+		ps = append(ps, p)
+	}
+	sort.Strings(ps)
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, p := range ps {
+		fmt.Fprintf(&b, "  %s: %s;\n", p, o.Props[p].String())
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testLSPWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      ver: ${{ steps.get.outputs.ver }}
+    steps:
+      - uses: actions/checkout@v4
+        id: co
+        with:
+          fetch-depth: 0
+      - id: get
+        run: echo "ver=1" >> "$GITHUB_OUTPUT"
+      - run: echo ${{ steps.co.outputs.commit }}
+        if: success()
+  test:
+    needs: [build]
+    runs-on: ubuntu-latest
+    env:
+      REF: ${{ github.ref }}
+    steps:
+      - run: echo ${{ needs.build.outputs.ver }}
+`
+
+// testLSPPositionOf returns the position of the marker in the text. The offset is added to the
+// character of the position.
+func testLSPPositionOf(t *testing.T, text, marker string, offset int) map[string]interface{} {
+	t.Helper()
+	i := strings.Index(text, marker)
+	if i < 0 {
+		t.Fatalf("marker %q is not found", marker)
+	}
+	i += offset
+	line := strings.Count(text[:i], "\n")
+	char := lspUTF16Len(text[strings.LastIndexByte(text[:i], '\n')+1 : i])
+	return map[string]interface{}{"line": line, "character": char}
+}
+
+func testLSPPositionRequest(id int, method, uri string, pos map[string]interface{}) string {
+	return testLSPRequest(id, method, map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     pos,
+	})
+}
+
+func TestLSPServerHover(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	testCases := []struct {
+		what   string
+		marker string
+		offset int
+		want   []string
+	}{
+		{
+			what:   "steps context",
+			marker: "steps.co.outputs.commit",
+			offset: 1,
+			want:   []string{"```\nsteps: {\n  co: {", "  get: {"},
+		},
+		{
+			what:   "output of action",
+			marker: "commit }}",
+			offset: 2,
+			want:   []string{"```\nsteps.co.outputs.commit: string\n```"},
+		},
+		{
+			what:   "needs context",
+			marker: "build.outputs.ver",
+			offset: len("build.outputs.v"),
+			want:   []string{"```\nneeds.build.outputs.ver: string\n```"},
+		},
+		{
+			what:   "github context",
+			marker: "github.ref",
+			offset: 0,
+			want:   []string{"github: {\n  action: string;", "[Document](https://docs.github.com/en/actions/learn-github-actions/contexts#github-context)"},
+		},
+		{
+			what:   "function in if condition",
+			marker: "success()",
+			offset: 3,
+			want:   []string{"```\nsuccess() -> bool\n```", "This function is only available at `jobs.<job_id>.if`, `jobs.<job_id>.steps.if`"},
+		},
+		{
+			what:   "workflow key",
+			marker: "runs-on: ubuntu-latest\n    outputs",
+			offset: 2,
+			want:   []string{"**`jobs.<job_id>.runs-on`**\n\nAvailable contexts: `github`, `inputs`, `matrix`, `needs`, `strategy`, `vars`"},
+		},
+		{
+			what:   "child key of workflow key",
+			marker: "fetch-depth",
+			offset: 0,
+			want:   []string{"**`jobs.<job_id>.steps.with`**", "Available special functions: `hashFiles()`"},
+		},
+		{
+			what:   "if key",
+			marker: "if: success",
+			offset: 1,
+			want:   []string{"**`jobs.<job_id>.steps.if`**", "Available special functions: `always()`, `cancelled()`, `failure()`, `hashFiles()`, `success()`"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := NewLSPServer(&LSPOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos := testLSPPositionOf(t, testLSPWorkflow, tc.marker, tc.offset)
+			msgs := testLSPServe(t, s, testLSPDidOpen(uri, testLSPWorkflow), testLSPPositionRequest(1, "textDocument/hover", uri, pos))
+			if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+				t.Fatal("workflow should have no error:", ds)
+			}
+			var h lspHover
+			if err := json.Unmarshal(msgs[1].Result, &h); err != nil {
+				t.Fatal(err, string(msgs[1].Result))
+			}
+			if h.Contents.Kind != "markdown" {
+				t.Fatal("unexpected kind of hover content:", h.Contents.Kind)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(h.Contents.Value, w) {
+					t.Errorf("%q is not included in hover content %q", w, h.Contents.Value)
+				}
+			}
+			if h.Range == nil || h.Range.Start.Line != pos["line"] {
+				t.Error("unexpected range:", h.Range)
+			}
+		})
+	}
+}
+
+func TestLSPServerHoverNothing(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, testLSPWorkflow),
+		testLSPPositionRequest(1, "textDocument/hover", uri, testLSPPositionOf(t, testLSPWorkflow, "ubuntu-latest", 1)),
+		testLSPPositionRequest(2, "textDocument/hover", uri, testLSPPositionOf(t, testLSPWorkflow, "steps:", 1)),
+		testLSPPositionRequest(3, "textDocument/hover", uri, map[string]interface{}{"line": 100, "character": 0}),
+		testLSPPositionRequest(4, "textDocument/hover", "file:///unknown.yaml", map[string]interface{}{"line": 0, "character": 0}),
+	)
+	for _, m := range msgs[1:] {
+		if string(m.Result) != "null" {
+			t.Errorf("no hover content should be returned for request %s: %s", m.ID, m.Result)
+		}
+	}
+}
+
+func TestLSPServerHoverError(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n"
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPPositionRequest(1, "textDocument/hover", uri, testLSPPositionOf(t, src, "unknown", 2)),
+	)
+	var h lspHover
+	if err := json.Unmarshal(msgs[1].Result, &h); err != nil {
+		t.Fatal(err, string(msgs[1].Result))
+	}
+	if !strings.Contains(h.Contents.Value, "## AL1002: `expression`") {
+		t.Fatal("error explanation is not included:", h.Contents.Value)
+	}
+}
+
+func TestLSPServerHoverMultibyte(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"🚀 日本語 ${{ github.ref }}\"\n"
+	pos := testLSPPositionOf(t, src, "ref }}", 1)
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, src), testLSPPositionRequest(1, "textDocument/hover", uri, pos))
+	var h lspHover
+	if err := json.Unmarshal(msgs[1].Result, &h); err != nil {
+		t.Fatal(err, string(msgs[1].Result))
+	}
+	if !strings.Contains(h.Contents.Value, "```\ngithub.ref: string\n```") {
+		t.Fatal("unexpected hover content:", h.Contents.Value)
+	}
+	start := pos["character"].(int) - 1
+	want := &lspRange{Start: lspPosition{5, start}, End: lspPosition{5, start + len("ref")}}
+	if h.Range == nil || *h.Range != *want {
+		t.Fatalf("wanted range %v but got %v", want, h.Range)
+	}
+}
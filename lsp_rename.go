@@ -0,0 +1,56 @@
+package actionlint
+
+import (
+	"errors"
+	"fmt"
+)
+
+// prepareRename returns the range of the job ID or the step ID at the position which can be renamed.
+func (s *LSPServer) prepareRename(params *lspTextDocumentPositionParams) *lspRange {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	lines := d.lines()
+	r := lspRefAt(lspWorkflowRefs(d.workflow, lines), lines, params.Position)
+	if r == nil {
+		return nil
+	}
+	rng := r.lspRange(lines)
+	return &rng
+}
+
+// rename renames the job ID or the step ID at the position and updates all references to it in the
+// workflow.
+func (s *LSPServer) rename(params *lspRenameParams) (*lspWorkspaceEdit, error) {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, fmt.Errorf("document %q is not opened", params.TextDocument.URI)
+	}
+	lines := d.lines()
+	refs := lspWorkflowRefs(d.workflow, lines)
+	target := lspRefAt(refs, lines, params.Position)
+	if target == nil {
+		return nil, errors.New("no job ID or step ID at the position")
+	}
+	declared := false
+	for _, r := range refs {
+		if r.decl && r.sameTarget(target) {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return nil, fmt.Errorf("%s %q is not defined", target.kind, target.id)
+	}
+
+	rs, err := renameRefs(refs, target, params.NewName)
+	if err != nil {
+		return nil, err
+	}
+	edits := make([]*lspTextEdit, 0, len(rs))
+	for _, r := range rs {
+		edits = append(edits, &lspTextEdit{Range: r.lspRange(lines), NewText: params.NewName})
+	}
+	return &lspWorkspaceEdit{Changes: map[string][]*lspTextEdit{d.uri: edits}}, nil
+}
@@ -0,0 +1,91 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLSPServerRename(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	src := testRenameWorkflow
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rename := func(id int, marker string, offset int, name string) string {
+		return testLSPRequest(id, "textDocument/rename", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     testLSPPositionOf(t, src, marker, offset),
+			"newName":      name,
+		})
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPPositionRequest(1, "textDocument/prepareRename", uri, testLSPPositionOf(t, src, "needs.build.outputs", len("needs.bu"))),
+		testLSPPositionRequest(2, "textDocument/prepareRename", uri, testLSPPositionOf(t, src, "ubuntu-latest", 1)),
+		rename(3, "needs: build", len("needs: b"), "compile"),
+		rename(4, "steps.get.outcome", len("steps.g"), "version"),
+		rename(5, "needs: build", len("needs: b"), "lint"),
+		rename(6, "needs: build", len("needs: b"), "1st"),
+		rename(7, "ubuntu-latest", 1, "foo"),
+	)
+
+	var r lspRange
+	if err := json.Unmarshal(msgs[1].Result, &r); err != nil {
+		t.Fatal(err, string(msgs[1].Result))
+	}
+	p := testLSPPositionOf(t, src, "needs.build.outputs", len("needs."))
+	if want := (lspRange{Start: lspPosition{p["line"].(int), p["character"].(int)}, End: lspPosition{p["line"].(int), p["character"].(int) + 5}}); r != want {
+		t.Errorf("wanted range %v but got %v", want, r)
+	}
+	if string(msgs[2].Result) != "null" {
+		t.Errorf("nothing should be renamed at runs-on: %s", msgs[2].Result)
+	}
+
+	for _, tc := range []struct {
+		msg  *testLSPMessage
+		from string
+		to   string
+		want int
+	}{
+		{msgs[3], "build", "compile", 5},
+		{msgs[4], "get", "version", 5}, // Only references in the "build" job are renamed
+	} {
+		if tc.msg.Error != nil {
+			t.Fatal(tc.msg.Error)
+		}
+		var e lspWorkspaceEdit
+		if err := json.Unmarshal(tc.msg.Result, &e); err != nil {
+			t.Fatal(err, string(tc.msg.Result))
+		}
+		edits := e.Changes[uri]
+		if len(edits) != tc.want {
+			t.Errorf("wanted %d edits to rename %q but got %d: %v", tc.want, tc.from, len(edits), edits)
+		}
+		ls := strings.Split(src, "\n")
+		for _, e := range edits {
+			l := ls[e.Range.Start.Line]
+			if have := l[e.Range.Start.Character:e.Range.End.Character]; !strings.EqualFold(have, tc.from) || e.NewText != tc.to {
+				t.Errorf("unexpected edit %q -> %q at line %d", have, e.NewText, e.Range.Start.Line+1)
+			}
+		}
+	}
+
+	for i, want := range []string{
+		`job ID "lint" is already used`,
+		`invalid job ID "1st"`,
+		"no job ID or step ID at the position",
+	} {
+		m := msgs[5+i]
+		if m.Error == nil {
+			t.Errorf("error should occur for request %s: %s", m.ID, m.Result)
+			continue
+		}
+		if m.Error.Code != lspErrorCodeRequestFailed || !strings.Contains(m.Error.Message, want) {
+			t.Errorf("%q is not included in error %v", want, m.Error)
+		}
+	}
+}
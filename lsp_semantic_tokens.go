@@ -0,0 +1,134 @@
+package actionlint
+
+import (
+	"strings"
+)
+
+// Token types of semantic tokens. The order must be the same as lspSemanticTokenTypes.
+const (
+	lspSemanticTokenVariable = iota
+	lspSemanticTokenProperty
+	lspSemanticTokenFunction
+	lspSemanticTokenString
+	lspSemanticTokenNumber
+	lspSemanticTokenKeyword
+	lspSemanticTokenOperator
+)
+
+var lspSemanticTokenTypes = []string{
+	"variable",
+	"property",
+	"function",
+	"string",
+	"number",
+	"keyword",
+	"operator",
+}
+
+type lspSemanticToken struct {
+	line   int
+	char   int
+	length int
+	kind   int
+}
+
+// semanticTokens returns semantic tokens of expressions in ${{ }} and conditions at "if:" in the
+// document. Tokens are classified with the token stream of the expression lexer.
+func (s *LSPServer) semanticTokens(params *lspSemanticTokensParams) *lspSemanticTokens {
+	ret := &lspSemanticTokens{Data: []int{}}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return ret
+	}
+	lines := d.lines()
+
+	ts := []*lspSemanticToken{}
+	for i, l := range lines {
+		if strings.Contains(l, "${{") {
+			for off := 0; ; {
+				j := strings.Index(l[off:], "${{")
+				if j < 0 {
+					break
+				}
+				start := off + j + len("${{")
+				var end int
+				ts, end = lspExprSemanticTokens(ts, l[start:], i, start)
+				off = start + end
+			}
+			continue
+		}
+		if !strings.Contains(l, "if:") {
+			continue
+		}
+		path, onKey := lspYAMLPathAt(lines, i, len(l))
+		if src, off, ok := lspIfCondAt(l, i, len(l), path, onKey); ok {
+			ts, _ = lspExprSemanticTokens(ts, src+"}}", i, off)
+		}
+	}
+
+	// Encode tokens in relative positions. See the specification of "textDocument/semanticTokens"
+	prevLine, prevChar := 0, 0
+	for _, t := range ts {
+		if t.line != prevLine {
+			prevChar = 0
+		}
+		ret.Data = append(ret.Data, t.line-prevLine, t.char-prevChar, t.length, t.kind, 0)
+		prevLine, prevChar = t.line, t.char
+	}
+	return ret
+}
+
+// lspExprSemanticTokens appends semantic tokens in the expression source which ends with "}}". The
+// source is at the offset in the line. Tokens lexed before an error are appended even if the
+// expression is broken. It returns the offset of the end of the expression in the source.
+func lspExprSemanticTokens(ts []*lspSemanticToken, src string, line, offset int) ([]*lspSemanticToken, int) {
+	lex := NewExprLexer(src)
+	var prev *Token
+	var pending *Token // Identifier which is a function or a context depending on the next token
+	flush := func(kind int) {
+		if pending != nil {
+			ts = append(ts, &lspSemanticToken{line, offset + pending.Offset, len(pending.Value), kind})
+			pending = nil
+		}
+	}
+
+	for {
+		t := lex.Next()
+		if lex.Err() != nil {
+			flush(lspSemanticTokenVariable)
+			return ts, lex.Offset()
+		}
+		if pending != nil {
+			if t.Kind == TokenKindLeftParen {
+				flush(lspSemanticTokenFunction)
+			} else {
+				flush(lspSemanticTokenVariable)
+			}
+		}
+
+		kind := -1
+		switch t.Kind {
+		case TokenKindEnd:
+			return ts, lex.Offset()
+		case TokenKindIdent:
+			switch {
+			case prev != nil && prev.Kind == TokenKindDot:
+				kind = lspSemanticTokenProperty
+			case t.Value == "true" || t.Value == "false" || t.Value == "null":
+				kind = lspSemanticTokenKeyword
+			default:
+				pending = t
+			}
+		case TokenKindString:
+			kind = lspSemanticTokenString
+		case TokenKindInt, TokenKindFloat:
+			kind = lspSemanticTokenNumber
+		case TokenKindNot, TokenKindLess, TokenKindLessEq, TokenKindGreater, TokenKindGreaterEq, TokenKindEq, TokenKindNotEq, TokenKindAnd, TokenKindOr:
+			kind = lspSemanticTokenOperator
+		}
+		if kind >= 0 {
+			ts = append(ts, &lspSemanticToken{line, offset + t.Offset, len(t.Value), kind})
+		}
+		prev = t
+	}
+}
@@ -0,0 +1,88 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLSPServerSemanticTokens(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	src := `on: push
+jobs:
+  test:
+    if: github.event_name == 'push' && !cancelled()
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+    steps:
+      - run: echo ${{ format('{0}', 1.5) }} ${{ true }}
+        if: ${{ steps.foo.outputs.bar != null }}
+      - run: echo "${{ github.`
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPRequest(1, "textDocument/semanticTokens/full", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+		}),
+	)
+	if msgs[1].Error != nil {
+		t.Fatal(msgs[1].Error)
+	}
+	var toks lspSemanticTokens
+	if err := json.Unmarshal(msgs[1].Result, &toks); err != nil {
+		t.Fatal(err, string(msgs[1].Result))
+	}
+	if len(toks.Data)%5 != 0 {
+		t.Fatal("length of data must be multiple of 5:", toks.Data)
+	}
+
+	// Decode relative positions into "line:char:text:type"
+	lines := strings.Split(src, "\n")
+	have := []string{}
+	line, char := 0, 0
+	for i := 0; i < len(toks.Data); i += 5 {
+		if toks.Data[i] > 0 {
+			char = 0
+		}
+		line += toks.Data[i]
+		char += toks.Data[i+1]
+		text := lines[line][char : char+toks.Data[i+2]]
+		have = append(have, fmt.Sprintf("%d:%d:%s:%s", line, char, text, lspSemanticTokenTypes[toks.Data[i+3]]))
+	}
+
+	want := []string{
+		"3:8:github:variable",
+		"3:15:event_name:property",
+		"3:26:==:operator",
+		"3:29:'push':string",
+		"3:36:&&:operator",
+		"3:39:!:operator",
+		"3:40:cancelled:function",
+		"4:17:matrix:variable",
+		"4:24:os:property",
+		"9:22:format:function",
+		"9:29:'{0}':string",
+		"9:36:1.5:number",
+		"9:48:true:keyword",
+		"10:16:steps:variable",
+		"10:22:foo:property",
+		"10:26:outputs:property",
+		"10:34:bar:property",
+		"10:38:!=:operator",
+		"10:41:null:keyword",
+		"11:23:github:variable",
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,197 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+)
+
+// documentSymbols returns the outline of the workflow. Jobs are the top-level symbols and steps are
+// their children. Inputs of "workflow_call" event and inputs passed to reusable workflows are also
+// included.
+func (s *LSPServer) documentSymbols(params *lspDocumentSymbolParams) []*lspDocumentSymbol {
+	syms := []*lspDocumentSymbol{}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok || d.workflow == nil {
+		return syms
+	}
+	w := d.workflow
+	lines := d.lines()
+
+	for _, e := range w.On {
+		if e, ok := e.(*WorkflowCallEvent); ok && e.Pos != nil && len(e.Inputs) > 0 {
+			sel := lspPositionIn(lines, e.Pos.Line, e.Pos.Col)
+			sym := &lspDocumentSymbol{
+				Name:           e.EventName(),
+				Kind:           lspSymbolKindEvent,
+				SelectionRange: lspRange{Start: sel, End: lspPosition{sel.Line, sel.Character + len(e.EventName())}},
+			}
+			for _, i := range e.Inputs {
+				if i.Name != nil && i.Name.Pos != nil {
+					sym.Children = append(sym.Children, lspInputSymbol(lines, i.Name, lspInputTypeName(i.Type)))
+				}
+			}
+			sym.Range = lspRange{Start: sel, End: lspSymbolsEnd(sym.Children)}
+			syms = append(syms, sym)
+		}
+	}
+
+	ends := map[string]int{}
+	for _, r := range findYAMLJobRanges(lines) {
+		ends[strings.ToLower(r.id)] = r.end
+	}
+	for _, j := range w.Jobs {
+		if j.ID == nil || j.ID.Pos == nil {
+			continue
+		}
+		sel := lspStringRange(lines, j.ID)
+		end, ok := ends[strings.ToLower(j.ID.Value)]
+		if !ok {
+			end = sel.Start.Line + 1
+		}
+		sym := &lspDocumentSymbol{
+			Name:           j.ID.Value,
+			Kind:           lspSymbolKindClass,
+			Range:          lspRange{Start: sel.Start, End: lspBlockEnd(lines, sel.Start.Line, end)},
+			SelectionRange: sel,
+		}
+		if j.Name != nil {
+			sym.Detail = j.Name.Value
+		}
+
+		for i, st := range j.Steps {
+			if st.Pos == nil {
+				continue
+			}
+			e := end
+			if i+1 < len(j.Steps) && j.Steps[i+1].Pos != nil {
+				e = j.Steps[i+1].Pos.Line - 1
+			}
+			sym.Children = append(sym.Children, lspStepSymbol(st, lines, e))
+		}
+
+		if c := j.WorkflowCall; c != nil {
+			ns := make([]string, 0, len(c.Inputs))
+			for n := range c.Inputs {
+				ns = append(ns, n)
+			}
+			sort.Strings(ns)
+			for _, n := range ns {
+				if i := c.Inputs[n]; i.Name != nil && i.Name.Pos != nil {
+					sym.Children = append(sym.Children, lspInputSymbol(lines, i.Name, "input"))
+				}
+			}
+		}
+
+		syms = append(syms, sym)
+	}
+
+	sort.Slice(syms, func(i, j int) bool {
+		a, b := syms[i].Range.Start, syms[j].Range.Start
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	})
+	return syms
+}
+
+func lspInputSymbol(lines []string, name *String, detail string) *lspDocumentSymbol {
+	r := lspStringRange(lines, name)
+	return &lspDocumentSymbol{
+		Name:           name.Value,
+		Detail:         detail,
+		Kind:           lspSymbolKindField,
+		Range:          r,
+		SelectionRange: r,
+	}
+}
+
+func lspInputTypeName(t WorkflowCallEventInputType) string {
+	switch t {
+	case WorkflowCallEventInputTypeBoolean:
+		return "boolean"
+	case WorkflowCallEventInputTypeNumber:
+		return "number"
+	case WorkflowCallEventInputTypeString:
+		return "string"
+	default:
+		return "input"
+	}
+}
+
+// lspStepSymbol returns the symbol of the step. The step is named with "name:", "id:", "uses:", or
+// the first line of "run:" in this order. The end is the 0-based index of the line next to the
+// step.
+func lspStepSymbol(s *Step, lines []string, end int) *lspDocumentSymbol {
+	start := lspPositionIn(lines, s.Pos.Line, s.Pos.Col)
+	sym := &lspDocumentSymbol{
+		Kind:           lspSymbolKindFunction,
+		Range:          lspRange{Start: start, End: lspBlockEnd(lines, start.Line, end)},
+		SelectionRange: lspRange{Start: start, End: start},
+	}
+
+	var label *String
+	switch {
+	case s.Name != nil:
+		label = s.Name
+		if s.ID != nil {
+			sym.Detail = s.ID.Value
+		}
+	case s.ID != nil:
+		label = s.ID
+	}
+	switch e := s.Exec.(type) {
+	case *ExecAction:
+		if label == nil {
+			label = e.Uses
+		} else if e.Uses != nil && sym.Detail == "" {
+			sym.Detail = e.Uses.Value
+		}
+	case *ExecRun:
+		if label == nil && e.Run != nil {
+			label = e.Run
+		}
+	}
+
+	if label == nil || label.Pos == nil {
+		sym.Name = "(step)"
+		return sym
+	}
+	sym.Name = strings.TrimSpace(label.Value)
+	if i := strings.IndexByte(sym.Name, '\n'); i >= 0 {
+		// Multi-line script at "run:". Its position is not the position of the first line
+		sym.Name = sym.Name[:i]
+	} else {
+		sym.SelectionRange = lspStringRange(lines, label)
+	}
+	if sym.Name == "" {
+		sym.Name = "(step)"
+	}
+	return sym
+}
+
+// lspBlockEnd returns the end position of the block which starts at the start line and ends before
+// the end line. Trailing empty lines and comments are not included in the block.
+func lspBlockEnd(lines []string, start, end int) lspPosition {
+	if end > len(lines) {
+		end = len(lines)
+	}
+	last := start
+	for i := start; i < end; i++ {
+		if t := strings.TrimSpace(lines[i]); t != "" && !strings.HasPrefix(t, "#") {
+			last = i
+		}
+	}
+	c := 0
+	if last < len(lines) {
+		c = lspUTF16Len(lines[last])
+	}
+	return lspPosition{last, c}
+}
+
+func lspSymbolsEnd(syms []*lspDocumentSymbol) lspPosition {
+	var p lspPosition
+	for _, s := range syms {
+		if e := s.Range.End; p.Line < e.Line || p.Line == e.Line && p.Character < e.Character {
+			p = e
+		}
+	}
+	return p
+}
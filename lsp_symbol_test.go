@@ -0,0 +1,101 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testLSPSymbolTree(syms []*lspDocumentSymbol, indent string, out []string) []string {
+	for _, s := range syms {
+		out = append(out, fmt.Sprintf(
+			"%s%s (%s) kind=%d range=%d:%d-%d:%d sel=%d:%d-%d:%d",
+			indent,
+			s.Name,
+			s.Detail,
+			s.Kind,
+			s.Range.Start.Line,
+			s.Range.Start.Character,
+			s.Range.End.Line,
+			s.Range.End.Character,
+			s.SelectionRange.Start.Line,
+			s.SelectionRange.Start.Character,
+			s.SelectionRange.End.Line,
+			s.SelectionRange.End.Character,
+		))
+		out = testLSPSymbolTree(s.Children, indent+"  ", out)
+	}
+	return out
+}
+
+func TestLSPServerDocumentSymbol(t *testing.T) {
+	_, uri := testLSPProject(t, "")
+	src := `on:
+  workflow_call:
+    inputs:
+      name:
+        type: string
+      dry-run:
+        type: boolean
+jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+
+      - name: Get version
+        id: get
+        run: echo "ver=1" >> "$GITHUB_OUTPUT"
+      # comment
+      - run: |
+          make
+          make test
+
+  call:
+    needs: build
+    uses: ./.github/workflows/reusable.yaml
+    with:
+      name: ${{ inputs.name }}
+`
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPDidOpen(uri, src),
+		testLSPRequest(1, "textDocument/documentSymbol", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+		}),
+		testLSPRequest(2, "textDocument/documentSymbol", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": "file:///unknown.yaml"},
+		}),
+	)
+
+	var syms []*lspDocumentSymbol
+	if err := json.Unmarshal(msgs[1].Result, &syms); err != nil {
+		t.Fatal(err, string(msgs[1].Result))
+	}
+	want := []string{
+		"workflow_call () kind=24 range=1:2-5:13 sel=1:2-1:15",
+		"  name (string) kind=8 range=3:6-3:10 sel=3:6-3:10",
+		"  dry-run (boolean) kind=8 range=5:6-5:13 sel=5:6-5:13",
+		"build (Build) kind=5 range=8:2-20:19 sel=8:2-8:7",
+		"  actions/checkout@v4 () kind=12 range=12:8-12:33 sel=12:14-12:33",
+		"  Get version (get) kind=12 range=14:8-16:45 sel=14:14-14:25",
+		"  make () kind=12 range=18:8-20:19 sel=18:8-18:8",
+		"call () kind=5 range=22:2-26:30 sel=22:2-22:6",
+		"  name (input) kind=8 range=26:6-26:10 sel=26:6-26:10",
+	}
+	if diff := cmp.Diff(want, testLSPSymbolTree(syms, "", nil)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if string(msgs[2].Result) != "[]" {
+		t.Fatal("no symbol should be returned for unknown document:", string(msgs[2].Result))
+	}
+}
@@ -0,0 +1,500 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testLSPMessage struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params json.RawMessage   `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  *lspResponseError `json:"error"`
+}
+
+func testLSPFrame(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+func testLSPRequest(id int, method string, params interface{}) string {
+	return testLSPFrame(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+}
+
+func testLSPNotification(method string, params interface{}) string {
+	return testLSPFrame(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// testLSPServe sends the messages to the server and returns messages sent from the server.
+func testLSPServe(t *testing.T, s *LSPServer, msgs ...string) []*testLSPMessage {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(strings.NewReader(strings.Join(msgs, "")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	o := out.String()
+	ret := []*testLSPMessage{}
+	for o != "" {
+		i := strings.Index(o, "\r\n\r\n")
+		if i < 0 {
+			t.Fatalf("header is broken: %q", o)
+		}
+		var n int
+		if _, err := fmt.Sscanf(o[:i], "Content-Length: %d", &n); err != nil {
+			t.Fatal(err, o)
+		}
+		o = o[i+4:]
+		var m testLSPMessage
+		if err := json.Unmarshal([]byte(o[:n]), &m); err != nil {
+			t.Fatal(err, o[:n])
+		}
+		ret = append(ret, &m)
+		o = o[n:]
+	}
+	return ret
+}
+
+func testLSPDiagnostics(t *testing.T, m *testLSPMessage) []*lspDiagnostic {
+	t.Helper()
+	if m.Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("diagnostics should be published but got %q method", m.Method)
+	}
+	var p lspPublishDiagnosticsParams
+	if err := json.Unmarshal(m.Params, &p); err != nil {
+		t.Fatal(err)
+	}
+	return p.Diagnostics
+}
+
+func testLSPCodeActions(t *testing.T, m *testLSPMessage) []*lspCodeAction {
+	t.Helper()
+	if m.Error != nil {
+		t.Fatal("code action request failed:", m.Error)
+	}
+	var as []*lspCodeAction
+	if err := json.Unmarshal(m.Result, &as); err != nil {
+		t.Fatal(err)
+	}
+	return as
+}
+
+// testLSPApplyEdits applies text edits to the text. Edits must not overlap.
+func testLSPApplyEdits(t *testing.T, text string, edits []*lspTextEdit) string {
+	t.Helper()
+	offset := func(p lspPosition) int {
+		o := 0
+		for i := 0; i < p.Line; i++ {
+			j := strings.IndexByte(text[o:], '\n')
+			if j < 0 {
+				t.Fatal("line is out of range:", p)
+			}
+			o += j + 1
+		}
+		l := text[o:]
+		if i := strings.IndexByte(l, '\n'); i >= 0 {
+			l = l[:i]
+		}
+		return o + lspByteOffset(l, p.Character)
+	}
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		text = text[:offset(e.Range.Start)] + e.NewText + text[offset(e.Range.End):]
+	}
+	return text
+}
+
+func testLSPProject(t *testing.T, config string) (string, string) {
+	d := t.TempDir()
+	for _, p := range []string{".git", filepath.Join(".github", "workflows")} {
+		if err := os.MkdirAll(filepath.Join(d, p), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if config != "" {
+		testStagedWrite(t, d, ".github/actionlint.yaml", config)
+	}
+	return d, lspURIFromPath(filepath.Join(d, ".github", "workflows", "ci.yaml"))
+}
+
+func testLSPDidOpen(uri, text string) string {
+	return testLSPNotification("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "yaml", "version": 1, "text": text},
+	})
+}
+
+func testLSPCodeActionRequest(id int, uri string, line int) string {
+	return testLSPRequest(id, "textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"range": map[string]interface{}{
+			"start": map[string]interface{}{"line": line, "character": 0},
+			"end":   map[string]interface{}{"line": line, "character": 0},
+		},
+		"context": map[string]interface{}{"diagnostics": []interface{}{}},
+	})
+}
+
+func TestLSPServerMessages(t *testing.T) {
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(
+		t,
+		s,
+		testLSPRequest(1, "initialize", map[string]interface{}{}),
+		testLSPNotification("initialized", map[string]interface{}{}),
+		testLSPRequest(2, "textDocument/unknown", map[string]interface{}{}),
+		testLSPNotification("$/cancelRequest", map[string]interface{}{"id": 2}),
+		testLSPRequest(3, "shutdown", nil),
+		testLSPRequest(4, "shutdown", nil),
+		testLSPNotification("exit", nil),
+		testLSPRequest(5, "initialize", map[string]interface{}{}), // Not handled after exit
+	)
+	if len(msgs) != 4 {
+		t.Fatalf("wanted 4 responses but got %d", len(msgs))
+	}
+	for i, m := range msgs {
+		if want := fmt.Sprint(i + 1); string(m.ID) != want {
+			t.Errorf("ID of response %d should be %s but got %s", i, want, m.ID)
+		}
+	}
+
+	var res lspInitializeResult
+	if err := json.Unmarshal(msgs[0].Result, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ServerInfo.Name != "actionlint" || !res.Capabilities.TextDocumentSync.OpenClose {
+		t.Fatal("unexpected initialize result:", res)
+	}
+	if e := msgs[1].Error; e == nil || e.Code != lspErrorCodeMethodNotFound {
+		t.Fatal("unknown method should cause an error:", e)
+	}
+	if string(msgs[2].Result) != "null" || msgs[2].Error != nil {
+		t.Fatalf("shutdown should return null but got %s (%v)", msgs[2].Result, msgs[2].Error)
+	}
+	if e := msgs[3].Error; e == nil || e.Code != lspErrorCodeInvalidRequest {
+		t.Fatal("request after shutdown should cause an error:", e)
+	}
+}
+
+func TestLSPServerBrokenMessage(t *testing.T) {
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []string{
+		"Content-Type: application/json\r\n\r\n{}",
+		"Content-Length: foo\r\n\r\n{}",
+		"Content-Length: 10\r\n\r\n{}",
+		"Content-Length: 2\r\n\r\n[]",
+		"Content-Length: -1\r\n\r\n{}",
+		fmt.Sprintf("Content-Length: %d\r\n\r\n{}", maxLSPMessageSize+1),
+	} {
+		var out strings.Builder
+		if err := s.Serve(strings.NewReader(in), &out); err == nil {
+			t.Errorf("error did not occur for %q", in)
+		}
+	}
+}
+
+func TestLSPServerDiagnosticsAndCodeActions(t *testing.T) {
+	proj, uri := testLSPProject(t, "rules:\n  style:\n    trailing-spaces: true\n")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi  \n"
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, text), testLSPCodeActionRequest(1, uri, 5), testLSPCodeActionRequest(2, uri, 0))
+	if len(msgs) != 3 {
+		t.Fatalf("wanted 3 messages but got %d", len(msgs))
+	}
+
+	diag := &lspDiagnostic{
+		Range:    lspRange{Start: lspPosition{5, 20}, End: lspPosition{5, 21}},
+		Severity: lspDiagnosticSeverityError,
+		Code:     "AL1020",
+		Source:   "actionlint",
+		Message:  "trailing spaces at end of line [style]",
+	}
+	if diff := cmp.Diff([]*lspDiagnostic{diag}, testLSPDiagnostics(t, msgs[0])); diff != "" {
+		t.Fatal(diff)
+	}
+
+	configURI := lspURIFromPath(filepath.Join(proj, ".github", "actionlint.yaml"))
+	want := []*lspCodeAction{
+		{
+			Title:       "remove trailing spaces",
+			Kind:        "quickfix",
+			Diagnostics: []*lspDiagnostic{diag},
+			IsPreferred: true,
+			Edit: &lspWorkspaceEdit{
+				Changes: map[string][]*lspTextEdit{
+					uri: {{Range: lspRange{Start: lspPosition{5, 20}, End: lspPosition{5, 22}}}},
+				},
+			},
+		},
+		{
+			Title:       `Suppress with inline comment "# actionlint-ignore: code:AL1020"`,
+			Kind:        "quickfix",
+			Diagnostics: []*lspDiagnostic{diag},
+			Edit: &lspWorkspaceEdit{
+				Changes: map[string][]*lspTextEdit{
+					uri: {{Range: lspRange{Start: lspPosition{5, 0}, End: lspPosition{5, 0}}, NewText: "      # actionlint-ignore: code:AL1020\n"}},
+				},
+			},
+		},
+		{
+			Title:       `Add "code:AL1020" to ignore patterns of .github/workflows/ci.yaml in actionlint.yaml`,
+			Kind:        "quickfix",
+			Diagnostics: []*lspDiagnostic{diag},
+			Edit: &lspWorkspaceEdit{
+				Changes: map[string][]*lspTextEdit{
+					configURI: {{
+						Range:   lspRange{Start: lspPosition{3, 0}, End: lspPosition{3, 0}},
+						NewText: "paths:\n  \".github/workflows/ci.yaml\":\n    ignore:\n      - \"code:AL1020\"\n",
+					}},
+				},
+			},
+		},
+	}
+	actions := testLSPCodeActions(t, msgs[1])
+	if diff := cmp.Diff(want, actions); diff != "" {
+		t.Fatal(diff)
+	}
+	if as := testLSPCodeActions(t, msgs[2]); len(as) != 0 {
+		t.Fatal("no code action should be provided at line without error:", as)
+	}
+
+	// Apply the inline comment
+	fixed := testLSPApplyEdits(t, text, actions[1].Edit.Changes[uri])
+	msgs = testLSPServe(t, s, testLSPNotification("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []interface{}{map[string]interface{}{"text": fixed}},
+	}))
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+		t.Fatal("error should be suppressed by inline comment:", ds)
+	}
+
+	// Add the ignore pattern to the config file
+	msgs = testLSPServe(t, s, testLSPDidOpen(uri, text))
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 1 {
+		t.Fatal("error should be reported again:", ds)
+	}
+	config := testLSPApplyEdits(t, "rules:\n  style:\n    trailing-spaces: true\n", actions[2].Edit.Changes[configURI])
+	testStagedWrite(t, proj, ".github/actionlint.yaml", config)
+	msgs = testLSPServe(t, s, testLSPNotification("textDocument/didSave", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": configURI},
+	}))
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+		t.Fatal("error should be ignored by config file:", ds)
+	}
+
+	// The same path cannot be added to the config file twice
+	msgs = testLSPServe(t, s, testLSPCodeActionRequest(1, uri, 5))
+	if as := testLSPCodeActions(t, msgs[0]); len(as) != 0 {
+		t.Fatal("no error should remain:", as)
+	}
+
+	// Diagnostics are cleared when the document is closed
+	msgs = testLSPServe(t, s, testLSPNotification("textDocument/didClose", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}))
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 0 {
+		t.Fatal("diagnostics should be cleared:", ds)
+	}
+}
+
+func TestLSPServerDiagnosticsInUTF16(t *testing.T) {
+	_, uri := testLSPProject(t, "rules:\n  style:\n    trailing-spaces: true\n")
+	// -column-unit option is ignored since the LSP requires UTF-16 code units
+	s, err := NewLSPServer(&LSPOptions{Linter: LinterOptions{ColumnUnit: ColumnUnitByte}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo 🐶日本  \n"
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, text), testLSPCodeActionRequest(1, uri, 5))
+	if len(msgs) != 2 {
+		t.Fatalf("wanted 2 messages but got %d", len(msgs))
+	}
+
+	want := lspRange{Start: lspPosition{5, 22}, End: lspPosition{5, 23}}
+	ds := testLSPDiagnostics(t, msgs[0])
+	if len(ds) != 1 {
+		t.Fatal("wanted 1 diagnostic but got", ds)
+	}
+	if diff := cmp.Diff(want, ds[0].Range); diff != "" {
+		t.Fatal(diff)
+	}
+
+	as := testLSPCodeActions(t, msgs[1])
+	if len(as) == 0 {
+		t.Fatal("no code action was returned")
+	}
+	want.End.Character++
+	if diff := cmp.Diff(want, as[0].Edit.Changes[uri][0].Range); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLSPServerConfigIgnoreCodeAction(t *testing.T) {
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n"
+	testCases := []struct {
+		what   string
+		config string
+		want   string
+	}{
+		{
+			what:   "existing paths",
+			config: "paths:\n    \"**/*.yml\":\n        ignore:\n            - foo\n",
+			want:   "paths:\n    \".github/workflows/ci.yaml\":\n        ignore:\n            - \"code:AL1002\"\n    \"**/*.yml\":\n        ignore:\n            - foo\n",
+		},
+		{
+			what:   "no newline at end of file",
+			config: "self-hosted-runner:\n  labels: [foo]",
+			want:   "self-hosted-runner:\n  labels: [foo]\npaths:\n  \".github/workflows/ci.yaml\":\n    ignore:\n      - \"code:AL1002\"\n",
+		},
+		{
+			what:   "multibyte characters at last line",
+			config: "self-hosted-runner:\n  labels: [日本語-runner]",
+			want:   "self-hosted-runner:\n  labels: [日本語-runner]\npaths:\n  \".github/workflows/ci.yaml\":\n    ignore:\n      - \"code:AL1002\"\n",
+		},
+		{
+			what:   "path already exists",
+			config: "paths:\n  .github/workflows/ci.yaml:\n    ignore: [foo]\n",
+		},
+		{
+			what:   "flow style paths",
+			config: "paths: {}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			proj, uri := testLSPProject(t, tc.config)
+			s, err := NewLSPServer(&LSPOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs := testLSPServe(t, s, testLSPDidOpen(uri, workflow), testLSPCodeActionRequest(1, uri, 5))
+			if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 1 {
+				t.Fatal("unexpected diagnostics:", ds)
+			}
+			actions := testLSPCodeActions(t, msgs[1])
+
+			configURI := lspURIFromPath(filepath.Join(proj, ".github", "actionlint.yaml"))
+			var edits []*lspTextEdit
+			for _, a := range actions {
+				if es, ok := a.Edit.Changes[configURI]; ok {
+					edits = es
+				}
+			}
+			if tc.want == "" {
+				if edits != nil {
+					t.Fatal("code action should not be provided:", edits)
+				}
+				return
+			}
+			if edits == nil {
+				t.Fatal("code action was not found:", actions)
+			}
+			have := testLSPApplyEdits(t, tc.config, edits)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+			if _, err := ParseConfig([]byte(have)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLSPServerCreateConfigCodeAction(t *testing.T) {
+	proj, uri := testLSPProject(t, "")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n"
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, workflow), testLSPCodeActionRequest(1, uri, 5))
+	actions := testLSPCodeActions(t, msgs[1])
+	if len(actions) != 2 {
+		t.Fatal("unexpected code actions:", actions)
+	}
+
+	configURI := lspURIFromPath(filepath.Join(proj, ".github", "actionlint.yaml"))
+	text := "paths:\n  \".github/workflows/ci.yaml\":\n    ignore:\n      - \"code:AL1002\"\n"
+	want := &lspWorkspaceEdit{
+		DocumentChanges: []interface{}{
+			&lspCreateFile{Kind: "create", URI: configURI},
+			&lspTextDocumentEdit{
+				TextDocument: lspOptionalVersionedTextDocumentIdentifier{URI: configURI},
+				Edits:        []*lspTextEdit{{NewText: text}},
+			},
+		},
+	}
+	// Compare JSON values since elements of DocumentChanges are decoded as maps
+	var v1, v2 interface{}
+	for _, x := range []struct {
+		edit *lspWorkspaceEdit
+		dest *interface{}
+	}{{want, &v1}, {actions[1].Edit, &v2}} {
+		b, err := json.Marshal(x.edit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, x.dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(v1, v2); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLSPServerConfigFileOption(t *testing.T) {
+	proj, uri := testLSPProject(t, "")
+	testStagedWrite(t, proj, "config.yaml", "self-hosted-runner:\n  labels: [my-runner]\n")
+	s, err := NewLSPServer(&LSPOptions{Linter: LinterOptions{ConfigFile: filepath.Join(proj, "config.yaml")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow := "on: push\njobs:\n  test:\n    runs-on: my-runner\n    steps:\n      - run: echo ${{ unknown }}\n"
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, workflow), testLSPCodeActionRequest(1, uri, 5))
+	if ds := testLSPDiagnostics(t, msgs[0]); len(ds) != 1 {
+		t.Fatal("unexpected diagnostics:", ds)
+	}
+	// Editing config file in the repository is not offered since it is not used
+	if as := testLSPCodeActions(t, msgs[1]); len(as) != 1 {
+		t.Fatal("unexpected code actions:", as)
+	}
+
+	if _, err := NewLSPServer(&LSPOptions{Linter: LinterOptions{ConfigFile: filepath.Join(proj, "oops.yaml")}}); err == nil {
+		t.Fatal("error did not occur")
+	}
+}
+
+func TestLSPServerBrokenConfigFile(t *testing.T) {
+	_, uri := testLSPProject(t, "paths: [\n")
+	s, err := NewLSPServer(&LSPOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := testLSPServe(t, s, testLSPDidOpen(uri, "on: push\n"))
+	ds := testLSPDiagnostics(t, msgs[0])
+	if len(ds) != 1 || !strings.Contains(ds[0].Message, "could not parse config file") {
+		t.Fatal("unexpected diagnostics:", ds)
+	}
+}
@@ -0,0 +1,589 @@
+package actionlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Locale is a language of error messages. Error messages are always generated in English and
+// translated into the locale on output. Messages which are not in the message catalog are output
+// in English.
+type Locale string
+
+const (
+	// LocaleEnglish outputs error messages in English. This is the default locale.
+	LocaleEnglish Locale = "en"
+	// LocaleJapanese outputs error messages in Japanese.
+	LocaleJapanese Locale = "ja"
+	// LocaleGerman outputs error messages in German.
+	LocaleGerman Locale = "de"
+)
+
+// ParseLocale parses the given string as Locale. A region and an encoding such as "ja_JP.UTF-8" or
+// "de-DE" are ignored. An empty string is parsed as LocaleEnglish.
+func ParseLocale(s string) (Locale, error) {
+	l := strings.ToLower(s)
+	if i := strings.IndexAny(l, "_-."); i >= 0 {
+		l = l[:i]
+	}
+	switch l := Locale(l); l {
+	case "":
+		return LocaleEnglish, nil
+	case LocaleEnglish, LocaleJapanese, LocaleGerman:
+		return l, nil
+	default:
+		return LocaleEnglish, fmt.Errorf("unknown locale %q. it must be one of \"en\", \"ja\", or \"de\"", s)
+	}
+}
+
+// messageCatalogEntry is a message in the message catalog. The format is the format string of the
+// English message passed to Errorf and the translations are format strings of the message in other
+// locales. The translations take the same arguments as the format so they refer to the arguments
+// with explicit indexes like %[2]q when the word order is different.
+type messageCatalogEntry struct {
+	id           string
+	format       string
+	translations map[Locale]string
+}
+
+// messageCatalog is a mapping from kinds of errors to their messages. Each message has a stable ID
+// like "expression/undefined-property" which does not change even if the wording of the message
+// changes. Once an ID is assigned to a message, it must not be changed or reused for other messages
+// since users suppress errors with the IDs. Variants of the same message such as the message with
+// and without a suggestion share the same ID. The ID is assigned to an error when it is created from
+// the format so the format here must be the same as the one passed to Errorf. Messages which are
+// created from errors of other packages (e.g. failing to read action metadata) have no ID.
+var messageCatalog = map[string][]*messageCatalogEntry{
+	"action": {
+		{id: "action/docker-action-on-non-linux", format: "Docker container action %q cannot run on runner %q. Docker container actions are only supported on Linux runners"},
+		{
+			id:     "action/runner-too-old",
+			format: "the runner of %q action is too old to run on GitHub Actions. update the action's version to fix this issue",
+			translations: map[Locale]string{
+				LocaleJapanese: "%[1]q アクションのランナーは古すぎるため GitHub Actions で実行できません。アクションのバージョンを更新してください",
+				LocaleGerman:   "der Runner der Aktion %[1]q ist zu alt, um auf GitHub Actions zu laufen. aktualisieren Sie die Version der Aktion, um dieses Problem zu beheben",
+			},
+		},
+		{id: "action/invalid-format", format: "specifying action %q in invalid format because %s"},
+		{id: "action/invalid-format", format: `specifying action %q in invalid format because %s. available formats are "{owner}/{repo}@{ref}" or "{owner}/{repo}/{path}@{ref}"`},
+		{id: "action/missing-runs-key", format: `%q is required in "runs" section because %q is a %s action. the action is defined at %q`},
+		{id: "action/disallowed-runs-key", format: `%q is not allowed in "runs" section because %q is a %s action. the action is defined at %q`},
+		{id: "action/missing-runs-file", format: `file %q does not exist in %q. it is specified at %q key in "runs" section in %q action`},
+		{id: "action/invalid-runs-image", format: `reference of Docker image %q at "image" key in "runs" section in %q action is invalid. it must be in the format of "[registry/]name[:tag][@digest]" and the name must be in lower case. the action is defined at %q`},
+		{id: "action/invalid-dockerfile-name", format: `the local file %q referenced from "image" key must be named "Dockerfile" in %q action. the action is defined at %q`},
+		{id: "action/undefined-runs-input", format: `input %q referenced at %q in "runs" section is not defined in %q action. available inputs are %s. the action is defined at %q`},
+		{id: "action/invalid-runs-condition", format: `condition %q at %q in "runs" section in %q action is invalid: %s. the action is defined at %q`},
+		{id: "action/missing-pre", format: `"pre" is required when "pre-if" is specified in "runs" section in %q action. the action is defined at %q`},
+		{id: "action/missing-post", format: `"post" is required when "post-if" is specified in "runs" section in %q action. the action is defined at %q`},
+		{id: "action/missing-runs-using", format: `"runs.using" is missing in local action %q defined at %q`},
+		{id: "action/deprecated-runner", format: `runner %q at runs.using in %q action defined at %q is deprecated and no longer supported by GitHub Actions. use "node20" or "node24" instead. see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs-for-javascript-actions`},
+		{id: "action/invalid-runner", format: `invalid runner name %q at runs.using in %q action defined at %q. valid runners are "composite", "docker", "node20", and "node24". see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs`},
+		{id: "action/empty-docker-tag", format: "tag of Docker action should not be empty: %q"},
+		{id: "action/invalid-docker-image", format: `reference of Docker image %q is invalid. it must be in the format of "[registry/]name[:tag][@digest]" and the name must be in lower case`},
+		{id: "action/docker-image-tag-omitted", format: `tag of Docker image %q is omitted so "latest" tag is used. the image may be changed unexpectedly. pin the image with a specific version tag or digest like "docker://%s:1.2.3"`},
+		{id: "action/docker-image-latest-tag", format: `Docker image %q uses "latest" tag. the image may be changed unexpectedly. pin the image with a specific version tag or digest like "docker://%s:1.2.3"`},
+		{id: "action/missing-name", format: "name is required in action metadata %q"},
+		{id: "action/missing-description", format: "description is required in metadata of %q action at %q"},
+		{id: "action/invalid-branding-icon", format: "incorrect icon name %q at branding.icon in metadata of %q action at %q. see the official document to know the exhaustive list of supported icons: https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#brandingicon"},
+		{id: "action/invalid-branding-color", format: "incorrect color %q at branding.icon in metadata of %q action at %q. see the official document to know the exhaustive list of supported colors: https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#brandingcolor"},
+		{
+			id:     "action/undefined-input",
+			format: "input %q is not defined in action %s. available inputs are %s",
+			translations: map[Locale]string{
+				LocaleJapanese: "入力 %[1]q はアクション %[2]s に定義されていません。利用可能な入力は %[3]s です",
+				LocaleGerman:   "die Eingabe %[1]q ist in der Aktion %[2]s nicht definiert. verfügbare Eingaben sind %[3]s",
+			},
+		},
+		{id: "action/missing-required-input", format: "missing input %q which is required by action %s. all required inputs are %s"},
+	},
+	"checkout": {
+		{id: "checkout/script-before-checkout", format: `script at "run:" refers to %q in the repository but the repository is not checked out yet in this job. add "actions/checkout" step before this step`},
+		{id: "checkout/local-action-before-checkout", format: `local action %q is used but the repository is not checked out yet in this job. the action cannot be found until "actions/checkout" step runs`},
+		{id: "checkout/input-before-checkout", format: `input %q of action %q refers to file %q in the repository but the repository is not checked out yet in this job. add "actions/checkout" step before this step`},
+	},
+	"credentials": {
+		{id: "credentials/hardcoded-password", format: `"password" section in %s should be specified via secrets. do not put password value directly`},
+	},
+	"deprecated-commands": {
+		{
+			id:     "deprecated-commands/deprecated-command",
+			format: "workflow command %q was deprecated. use `%s` instead: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions",
+			translations: map[Locale]string{
+				LocaleJapanese: "ワークフローコマンド %[1]q は非推奨です。代わりに `%[2]s` を使用してください: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions",
+				LocaleGerman:   "der Workflow-Befehl %[1]q ist veraltet. verwenden Sie stattdessen `%[2]s`: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions",
+			},
+		},
+	},
+	"env-file": {
+		{id: "env-file/unquoted-env-file", format: `$%s is not quoted. quote it like "$%s" to prevent word splitting and globbing of the file path`},
+		{id: "env-file/empty-name", format: `name is empty at line %q written to $%s. the line must be in the "{name}={value}" format`},
+		{id: "env-file/empty-name-or-delimiter", format: `name or delimiter is empty at line %q written to $%s. the line must be in the "{name}<<{delimiter}" format`},
+		{id: "env-file/newline-in-value", format: `value of %q written to $%s contains newline. use the "{name}<<{delimiter}" format to write multi-line value. see https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#multiline-strings`},
+		{id: "env-file/invalid-line-format", format: `line %q written to $%s is not in the "{name}={value}" format nor the "{name}<<{delimiter}" format. the step will fail with "Invalid format" error at runtime`},
+		{id: "env-file/delimiter-not-after-value", format: `delimiter %q of multi-line value at line %q written to $%s is not written after the value. the step will fail with "Matching delimiter not found" error at runtime%s`},
+	},
+	"env-var": {
+		{id: "env-var/invalid-name", format: "environment variable name %q is invalid. '&', '=' and spaces should not be contained"},
+		{id: "env-var/non-posix-name", format: "environment variable name %q is not a valid POSIX name. the name should consist of alphabets, digits, and underscores and should not start with a digit. shells cannot refer such variables"},
+		{id: "env-var/reserved-name", format: `environment variable %q is set by GitHub Actions by default and cannot be overridden. names with "GITHUB_" and "RUNNER_" prefixes are reserved. use other name`},
+		{id: "env-var/shadowed-variable", format: "environment variable %q shadows the variable defined at %s level with different type of value. %s value %q is overridden with %s value %q"},
+		{id: "env-var/undefined-variable", format: `environment variable %q is not defined in this scope. env context only contains variables defined at "env:" of the workflow, the job, and the step, and variables set via $GITHUB_ENV in previous steps`},
+		{id: "env-var/set-in-same-step", format: `environment variable %q is set via $GITHUB_ENV at line %d of "run:" in the same step. env context is evaluated before the step runs so the variable is not available yet`},
+		{id: "env-var/read-in-same-step", format: "environment variable %q is set via $GITHUB_ENV at line %d but it is read at line %d in the same step. variables set via $GITHUB_ENV are available only in subsequent steps. assign the value to a shell variable as well"},
+	},
+	"events": {
+		{id: "events/invalid-cron", format: "invalid CRON format %q in schedule event: %s"},
+		{id: "events/schedule-too-frequent", format: "scheduled job runs too frequently. it runs once per %g seconds. the shortest interval is once every 5 minutes"},
+		{id: "events/unavailable-filter", format: "%q filter is not available for %s event. it is only for %s %s"},
+		{id: "events/conflicting-filters", format: "both %q and %q filters cannot be used for the same event %q. note: use '!' to negate patterns"},
+		{id: "events/unknown-event", format: "unknown Webhook event %q. see https://docs.github.com/en/actions/learn-github-actions/events-that-trigger-workflows#webhook-events for list of all Webhook event names"},
+		{id: "events/missing-workflows", format: `no workflow is configured for "workflow_run" event`},
+		{id: "events/unavailable-workflows", format: `"workflows" cannot be configured for %q event. it is only for workflow_run event`},
+		{id: "events/refs-prefix-in-branch-filter", format: `%q filter of workflow_run event is matched to the head branch name of the triggering workflow run such as "main". the pattern %q starting with "refs/" never matches. remove the "refs/heads/" prefix`},
+		{id: "events/unavailable-types", format: `"types" cannot be specified for %q Webhook event`},
+		{id: "events/invalid-activity-type", format: "invalid activity type %q for %q Webhook event. available types are %s"},
+		{id: "events/invalid-activity-type", format: "invalid activity type %q for %q Webhook event. available types are %s. did you mean %q?"},
+		{id: "events/unknown-repository-dispatch-type", format: `unknown type %q for "repository_dispatch" event. available types configured at "repository-dispatch" in actionlint.yaml are %s. did you mean %q?`},
+		{id: "events/unknown-repository-dispatch-type", format: `unknown type %q for "repository_dispatch" event. available types configured at "repository-dispatch" in actionlint.yaml are %s`},
+		{id: "events/invalid-workflow-call-number-default", format: "input of workflow_call event %q is typed as number but its default value %q cannot be parsed as a float number: %s"},
+		{id: "events/invalid-workflow-call-boolean-default", format: "input of workflow_call event %q is typed as boolean. its default value must be true or false but got %q"},
+		{id: "events/default-of-required-input", format: "input %q of workflow_call event has the default value %q, but it is also required. if an input is marked as required, its default value will never be used"},
+		{id: "events/quoted-default", format: "default value %q of %q input is quoted but the input is typed as %s. the quoted value is a string in YAML. remove the quotes"},
+		{id: "events/missing-choice-options", format: `input type of %q is "choice" but "options" is not set`},
+		{id: "events/duplicated-option", format: "option %q is duplicated in options of %q input"},
+		{id: "events/default-not-in-options", format: "default value %q of %q input is not included in its options %q"},
+		{id: "events/options-without-choice", format: `"options" can not be set to %q input because its input type is not "choice"`},
+		{id: "events/invalid-workflow-dispatch-number-default", format: `type of %q input is "number" but its default value %q cannot be parsed as a float number: %s`},
+		{id: "events/invalid-workflow-dispatch-boolean-default", format: `type of %q input is "boolean". its default value %q must be "true" or "false"`},
+		{id: "events/too-many-workflow-dispatch-inputs", format: `maximum number of inputs for "workflow_dispatch" event is 10 but %d inputs are provided. see https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#providing-inputs`},
+	},
+	"expression": {
+		{id: "expression/unevaluable-node", format: "cannot evaluate expression node %T"},
+		{id: "expression/too-few-arguments-to-evaluate", format: "function %q takes at least %d arguments but %d arguments are given"},
+		{id: "expression/json-conversion-failure", format: "could not convert value to JSON: %s"},
+		{id: "expression/json-parse-failure", format: "could not parse JSON string %q: %s"},
+		{id: "expression/unevaluable-hash-files", format: "hashFiles() cannot be evaluated since it depends on files in the workspace of the runner"},
+		{id: "expression/unevaluable-function", format: "function %q cannot be evaluated"},
+		{
+			id:     "expression/untrusted-input",
+			format: "%q is potentially untrusted. avoid using it directly in inline scripts. instead, pass it through an environment variable. see https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions for more details",
+			translations: map[Locale]string{
+				LocaleJapanese: "%[1]q は信頼できない可能性があります。インラインスクリプトで直接使用せず、環境変数を経由して渡してください。詳細は https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions を参照してください",
+				LocaleGerman:   "%[1]q ist möglicherweise nicht vertrauenswürdig. verwenden Sie es nicht direkt in Inline-Skripten, sondern übergeben Sie es über eine Umgebungsvariable. weitere Details finden Sie unter https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions",
+			},
+		},
+		{id: "expression/untrusted-object-filter", format: "object filter extracts potentially untrusted properties %s. avoid using the value directly in inline scripts. instead, pass the value through an environment variable. see https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions for more details"},
+		{id: "expression/scan-error", format: "scan error while lexing expression: %s"},
+		{id: "expression/unexpected-character", format: "got unexpected %s while lexing %s, expecting %s%s"},
+		{id: "expression/unexpected-eof", format: "unexpected EOF while lexing expression"},
+		{id: "expression/unexpected-token", format: "unexpected %s while parsing %s. expecting %s"},
+		{id: "expression/invalid-integer-literal", format: "parsing invalid integer literal %q: %s"},
+		{id: "expression/invalid-float-literal", format: "parsing invalid float literal %q: %s"},
+		{id: "expression/remaining-tokens", format: "parser did not reach end of input after parsing the expression. %d remaining token(s) in the input: %s"},
+		{id: "expression/unavailable-context", format: "context %q is not allowed here. available %s %s. see https://docs.github.com/en/actions/learn-github-actions/contexts#context-availability for more details"},
+		{id: "expression/unavailable-function", format: "calling function %q is not allowed here. %q is only available in %s. see https://docs.github.com/en/actions/learn-github-actions/contexts#context-availability for more details"},
+		{
+			id:     "expression/undefined-variable",
+			format: "undefined variable %q. available variables are %s",
+			translations: map[Locale]string{
+				LocaleJapanese: "未定義の変数 %[1]q です。利用可能な変数は %[2]s です",
+				LocaleGerman:   "undefinierte Variable %[1]q. verfügbare Variablen sind %[2]s",
+			},
+		},
+		{
+			id:     "expression/undefined-matrix-property",
+			format: `property %q is not defined in object type %s. it is defined in matrix of other job%s %s but "matrix" context only contains values in "strategy.matrix" of the current job`,
+			translations: map[Locale]string{
+				LocaleJapanese: `プロパティ %[1]q はオブジェクト型 %[2]s に定義されていません。他のジョブ %[4]s の matrix に定義されていますが、"matrix" コンテキストには現在のジョブの "strategy.matrix" の値のみが含まれます`,
+				LocaleGerman:   `die Eigenschaft %[1]q ist im Objekttyp %[2]s nicht definiert. sie ist in der Matrix anderer Jobs %[4]s definiert, aber der Kontext "matrix" enthält nur Werte aus "strategy.matrix" des aktuellen Jobs`,
+			},
+		},
+		{
+			id:     "expression/undefined-property",
+			format: "property %q is not defined in object type %s",
+			translations: map[Locale]string{
+				LocaleJapanese: "プロパティ %[1]q はオブジェクト型 %[2]s に定義されていません",
+				LocaleGerman:   "die Eigenschaft %[1]q ist im Objekttyp %[2]s nicht definiert",
+			},
+		},
+		{id: "expression/invalid-dereference-receiver", format: "receiver of object dereference %q must be type of object but got %q"},
+		{
+			id:     "expression/undefined-element-property",
+			format: "property %q is not defined in object type %s as element of filtered array",
+			translations: map[Locale]string{
+				LocaleJapanese: "プロパティ %[1]q はフィルタされた配列の要素のオブジェクト型 %[2]s に定義されていません",
+				LocaleGerman:   "die Eigenschaft %[1]q ist im Objekttyp %[2]s als Element des gefilterten Arrays nicht definiert",
+			},
+		},
+		{id: "expression/invalid-filter-property", format: "property filtered by %q at object filtering must be type of object but got %q"},
+		{id: "expression/reserved-config-variable-prefix", format: "configuration variable name %q must not start with the GITHUB_ prefix (case insensitive). note: see the convention at https://docs.github.com/en/actions/learn-github-actions/variables#naming-conventions-for-configuration-variables"},
+		{id: "expression/invalid-config-variable-name", format: "configuration variable name %q can only contain alphabets, decimal numbers, and '_'. note: see the convention at https://docs.github.com/en/actions/learn-github-actions/variables#naming-conventions-for-configuration-variables"},
+		{id: "expression/no-config-variable", format: "no configuration variable is allowed since the variables list is empty in actionlint.yaml. you may forget adding the variable %q to the list"},
+		{id: "expression/undefined-config-variable", format: "undefined configuration variable %q. defined configuration variables in actionlint.yaml are %s"},
+		{id: "expression/invalid-object-filter-element", format: "elements of object at receiver of object filtering `.*` must be type of object but got %q. the type of receiver was %q"},
+		{id: "expression/object-filter-without-object-element", format: "object type %q cannot be filtered by object filtering `.*` since it has no object element"},
+		{id: "expression/invalid-object-filter-receiver", format: "receiver of object filtering `.*` must be type of array or object but got %q"},
+		{id: "expression/invalid-array-index", format: "index access of array must be type of number but got %q"},
+		{id: "expression/invalid-property-access", format: "property access of object must be type of string but got %q"},
+		{id: "expression/invalid-index-operand", format: "index access operand must be type of object or array but got %q"},
+		{id: "expression/index-out-of-bounds", format: "index %d is out of bounds of array with %s. it is always evaluated to null"},
+		{id: "expression/negative-index", format: "index %d of array access is negative. it does not access elements from the end of array and is always evaluated to null"},
+		{id: "expression/wrong-number-of-arguments", format: "number of arguments is wrong. function %q takes %s%d parameters but %d arguments are given"},
+		{id: "expression/unassignable-argument", format: "%s argument of function call is not assignable. %q cannot be assigned to %q. called function type is %q"},
+		{id: "expression/swapped-contains-arguments", format: "arguments of contains() seem to be swapped. %q value is given to the first argument and %q value is given to the second argument. contains(array, item) checks whether the array contains the item"},
+		{id: "expression/invalid-join-argument", format: "first argument of join() must be an array but %q value is given. join() concatenates elements of the array with separator like join(matrix.items, ', ')"},
+		{id: "expression/unused-format-argument", format: "format string %q does not contain placeholder {%d}. remove argument which is unused in the format string"},
+		{id: "expression/missing-format-argument", format: "format string %q contains placeholder {%d} but only %d arguments are given to format"},
+		{id: "expression/broken-json", format: "broken JSON string is passed to fromJSON() at offset %d: %s"},
+		{id: "expression/to-json-secrets", format: "toJSON(secrets) exposes all secrets of the repository. pass only the secrets which are actually used like secrets.NAME for least privilege"},
+		{id: "expression/placeholder-in-hash-files-pattern", format: `pattern %q of hashFiles() contains "${{". placeholders in string literals are not evaluated. build the pattern with format() like format('{0}/**/*.lock', matrix.dir)`},
+		{id: "expression/undefined-function", format: "undefined function %q. available functions are %s"},
+		{id: "expression/invalid-not-operand", format: `type of operand of ! operator %q is not assignable to type "bool"`},
+		{id: "expression/incomparable-values", format: "%q value cannot be compared to %q value with %q operator"},
+		{id: "expression/comparison-with-nan", format: "%q value is compared with string %q which is not a number. the string is converted to NaN so the comparison is always %s. compare with %s value instead"},
+		{id: "expression/github-ref-without-refs-prefix", format: `github.ref is compared with %q which does not start with "refs/". github.ref is a fully-formed ref like "refs/heads/%s" so the comparison is always %s. compare it with 'refs/heads/%s' or use github.ref_name instead`},
+		{id: "expression/unknown-runner-value", format: "runner.%s is compared with %q but runner.%s is one of %s. the comparison is always %s"},
+		{id: "expression/unreachable-runner-os", format: "runner.os is compared with %q but the job runs only on %s runners. the comparison is always %s"},
+		{id: "expression/untriggered-event-name", format: "github.event_name is compared with %q but the workflow is not triggered by %q event. the comparison is always %s. events triggering this workflow are %s"},
+		{id: "expression/invalid-bool-input", format: "type of input %q must be bool but found type %s"},
+		{id: "expression/invalid-number-input", format: "type of input %q must be number but found type %s"},
+		{id: "expression/invalid-runs-on-type", format: `type of expression at "runs-on" must be string or array but found type %q`},
+		{id: "expression/multiple-expressions", format: "one ${{ }} expression should be included in %q value but got %d expressions"},
+		{id: "expression/invalid-object-type", format: "type of expression at %q must be object but found type %s"},
+		{id: "expression/invalid-array-type", format: "type of expression at %q must be array but found type %s"},
+		{id: "expression/invalid-number-type", format: "type of expression at %q must be number but found type %s"},
+		{id: "expression/invalid-reusable-workflow-input-type", format: "input %q is typed as %s by reusable workflow %q. %s value cannot be assigned"},
+		{id: "expression/invalid-if-condition-type", format: `"if" condition should be type "bool" but got type %q`},
+		{id: "expression/object-in-template", format: "object, array, and null values should not be evaluated in template with ${{ }} but evaluating the value of type %s"},
+		{id: "expression/invalid-bool-type", format: "type of expression must be bool but found type %s"},
+		{id: "expression/nested-expression", format: `"${{" is nested in ${{ }} expression. ${{ }} cannot be nested. remove the inner "${{"`},
+		{id: "expression/nested-expression", format: `"${{" is nested in ${{ }} expression. ${{ }} cannot be nested. remove the inner "${{" and "}}" around %q`},
+		{id: "expression/unevaluated-expression", format: `${{ }} cannot be used at "%s:" since GitHub does not evaluate expressions there. %q must be specified without ${{ }}`},
+		{id: "expression/unused-matrix-value", format: `matrix value %q is defined but never referenced as "matrix.%s" in this job. remove it if it is not necessary`},
+	},
+	"failure-handling": {
+		{id: "failure-handling/always-in-job-condition", format: `job %q runs even when the workflow run is cancelled because "if:" condition %q uses always(), but the job %s. use "if: success() || failure()" or "if: ${{ !cancelled() }}" instead not to run it on cancellation`},
+		{id: "failure-handling/always-in-step-condition", format: `step runs even when the workflow run is cancelled because "if:" condition %q uses always(), but the step %s. use "if: success() || failure()" or "if: ${{ !cancelled() }}" instead not to run it on cancellation`},
+		{id: "failure-handling/output-of-continue-on-error", format: `output %q of job %q may be empty because %s has "continue-on-error: true". check the output is not empty or make the failure stop this job`},
+	},
+	"future-syntax": {
+		{id: "future-syntax/unsupported-key", format: "key %q for %q section is defined in the workflow syntax but not supported by this version of actionlint yet. update actionlint to check it"},
+	},
+	"git-push": {
+		{id: "git-push/tags-by-github-token", format: `tags pushed by %s with GITHUB_TOKEN do not trigger workflow %q on "push" event since events caused by GITHUB_TOKEN do not create new workflow runs. pass a personal access token or a GitHub App token to %s`},
+		{id: "git-push/infinite-loop", format: `%s pushes commits with a token other than GITHUB_TOKEN at %s and this workflow is triggered by "push" event at line:%d,col:%d. the pushed commits trigger this workflow again and may cause an infinite loop. add "paths-ignore:" filter, check "github.actor" at "if:", or include "[skip ci]" in the commit message`},
+	},
+	"glob": {
+		{id: "glob/invalid-pattern", format: "%s. note: filter pattern syntax is explained at https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet"},
+	},
+	"id": {
+		{id: "id/duplicated-step-id", format: "step ID %q duplicates. previously defined at %s. step ID must be unique within a job. note that step ID is case insensitive"},
+		{id: "id/invalid-id", format: "invalid %s ID %q. %s ID must start with a letter or _ and contain only alphanumeric characters, -, or _"},
+	},
+	"if-cond": {
+		{id: "if-cond/always-true-condition", format: "if: condition %q is always evaluated to true because extra characters are around ${{ }}"},
+	},
+	"job-needs": {
+		{id: "job-needs/duplicated-needs", format: `job ID %q duplicates in "needs" section. note that job ID is case insensitive`},
+		{id: "job-needs/duplicated-job-id", format: "job ID %q duplicates. previously defined at %s. note that job ID is case insensitive"},
+		{
+			id:     "job-needs/undefined-job",
+			format: "job %q needs job %q which does not exist in this workflow",
+			translations: map[Locale]string{
+				LocaleJapanese: "ジョブ %[1]q は、このワークフローに存在しないジョブ %[2]q に依存しています",
+				LocaleGerman:   "der Job %[1]q benötigt den Job %[2]q, der in diesem Workflow nicht existiert",
+			},
+		},
+		{id: "job-needs/cyclic-dependencies", format: `cyclic dependencies in "needs" job configurations are detected. detected cycle is %s`},
+	},
+	"limits": {
+		{id: "limits/file-too-large-to-check", format: "this file was not checked since its size exceeds the maximum file size %d bytes. GitHub does not accept workflow files larger than %d bytes. change the maximum size with -max-file-size option if this file needs to be checked"},
+		{id: "limits/file-too-large", format: "size of this workflow file is %d bytes. GitHub does not accept workflow files larger than %d bytes"},
+		{id: "limits/name-too-long", format: "%s name is %d characters long. GitHub allows %s names up to %d characters"},
+		{id: "limits/env-var-too-large", format: "size of environment variable %q is %d bytes. processes cannot be run with an environment variable larger than %d bytes"},
+		{id: "limits/env-section-too-large", format: `total size of environment variables in this "env" section is %d bytes. it exceeds the limit %d bytes`},
+		{id: "limits/matrix-too-large", format: "matrix of job %q generates %d jobs. GitHub allows a matrix to generate up to %d jobs per workflow run"},
+		{id: "limits/expression-too-long", format: `expression is %d characters long. GitHub allows expressions up to %d characters and rejects the workflow with "Exceeded max expression length" error`},
+		{id: "limits/input-too-large", format: "size of input %q is %d bytes. the input is passed to the action as an environment variable but processes cannot be run with an environment variable larger than %d bytes"},
+	},
+	"marketplace": {
+		{id: "marketplace/missing-name", format: `"name" is required in metadata of action %q published to GitHub Marketplace`},
+		{id: "marketplace/duplicated-name", format: "name %q of action %q is already used by action %q. names of actions published to GitHub Marketplace must be unique"},
+		{id: "marketplace/missing-description", format: `"description" is required in metadata of action %q published to GitHub Marketplace`},
+		{id: "marketplace/unsupported-icon", format: `icon %q at "branding.icon" of action %q is not supported by GitHub Marketplace. see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#brandingicon for supported icons`},
+		{id: "marketplace/unsupported-color", format: `color %q at "branding.color" of action %q is not supported by GitHub Marketplace. supported colors are %s`},
+		{id: "marketplace/missing-readme", format: "README file does not exist in directory of action %q. GitHub Marketplace shows the README as the page of the action"},
+	},
+	"matrix": {
+		{id: "matrix/undefined-continue-on-error-value", format: `matrix value %q referenced at "continue-on-error" is not defined in some matrix combinations such as %s. it is evaluated to null, which is falsy, in the combinations. define the value in all combinations like "%s: [false]"`},
+		{id: "matrix/duplicated-value", format: "duplicate value %s is found in matrix %q. the same value is at %s"},
+		{id: "matrix/exclude-without-matrix", format: `"exclude" section exists but no matrix variation exists`},
+		{id: "matrix/undefined-exclude-key", format: `%q in "exclude" section does not exist in matrix. available matrix configurations are %s`},
+		{id: "matrix/unmatched-exclude-value", format: `value %s in "exclude" does not match in matrix %q combinations. possible values are %s`},
+	},
+	"path-filter": {
+		{id: "path-filter/unmatched-directory", format: `directory %q used in this workflow is not matched by any pattern at "paths" filter of %q event at line:%d,col:%d (%s). changes in the directory do not trigger this workflow. this may be a copy-paste mistake`},
+	},
+	"permissions": {
+		{id: "permissions/invalid-all-scopes-value", format: `%q is invalid for permission for all the scopes. available values are "read-all" and "write-all"`},
+		{id: "permissions/unknown-scope", format: "unknown permission scope %q. all available permission scopes are %s"},
+		{id: "permissions/invalid-scope-value", format: `%q is invalid for permission of scope %q. available values are "read", "write" or "none"`},
+	},
+	"pyflakes": {
+		{id: "pyflakes/issue", format: "%s reported issue in this script: %s"},
+	},
+	"release": {
+		{id: "release/release-by-github-token", format: `release is updated by %s with GITHUB_TOKEN but it does not trigger workflow %q on "release" event since events caused by GITHUB_TOKEN do not create new workflow runs. pass a personal access token or a GitHub App token to %s`},
+		{id: "release/missing-contents-write", format: `%s requires "contents: write" permission to create or update a release but the permission of "contents" scope is %q in this job. add "contents: write" to "permissions:"`},
+		{id: "release/release-from-branch", format: `%s creates a release from the pushed ref since %q input is omitted, but the workflow is triggered by pushing branches at line:%d,col:%d. filter the "push" event by tags like "tags: [v*]" or check the ref with "startsWith(github.ref, 'refs/tags/')" at "if:"`},
+	},
+	"remote": {
+		{id: "remote/unmatched-branch", format: "branch filter %q in %q does not match to any branch in repository %q"},
+		{id: "remote/unknown-environment", format: "environment %q is not found in repository %q. available environments are %s"},
+		{id: "remote/unknown-runner-group", format: "runner group %q is not found in organization %q. available runner groups are %s"},
+		{id: "remote/unregistered-runner-label", format: "runner label %q is not registered to any self-hosted runner in repository %q or its organization"},
+		{id: "remote/reusable-workflow-not-found", format: "reusable workflow %q is not found. file %q does not exist at ref %q in repository %q or the repository is not accessible"},
+		{id: "remote/undefined-in-environment", format: "%s %q is not defined in repository %q, its organization, or environment %q"},
+		{id: "remote/undefined", format: "%s %q is not defined in repository %q or its organization"},
+	},
+	"run-script": {
+		{id: "run-script/too-many-lines", format: "script has %d lines, which exceeds the maximum %d lines. consider extracting it into a script file like %q or a composite action"},
+		{id: "run-script/too-complex", format: "script contains %d loops, conditionals, and heredocs, which exceeds the maximum complexity %d. consider extracting it into a script file like %q or a composite action"},
+	},
+	"runner-label": {
+		{id: "runner-label/invalid-label-pattern", format: "label pattern %q is an invalid glob. kindly check list of labels in actionlint.yaml config file: %v"},
+		{
+			id:     "runner-label/unknown-label",
+			format: "label %q is unknown. available labels are %s. if it is a custom label for self-hosted runner, set list of labels in actionlint.yaml config file",
+			translations: map[Locale]string{
+				LocaleJapanese: "ラベル %[1]q は不明です。利用可能なラベルは %[2]s です。セルフホストランナーのカスタムラベルの場合は、actionlint.yaml 設定ファイルにラベルのリストを設定してください",
+				LocaleGerman:   "das Label %[1]q ist unbekannt. verfügbare Labels sind %[2]s. wenn es ein benutzerdefiniertes Label für einen selbst gehosteten Runner ist, legen Sie die Liste der Labels in der Konfigurationsdatei actionlint.yaml fest",
+			},
+		},
+		{id: "runner-label/retired-label", format: "label %q was retired on %s and jobs with the label no longer run. use newer label like %q"},
+		{id: "runner-label/retiring-label", format: "label %q %s retired on %s. migrate to newer label like %q before the retirement"},
+		{id: "runner-label/conflicting-labels", format: "label %q conflicts with label %q defined at %s. note: to run your job on each workers, use matrix"},
+	},
+	"runner-tools": {
+		{id: "runner-tools/command-not-installed", format: `command %q is not installed on the image of runner %q. install it in previous steps or use another runner. if it is installed on your self-hosted runner, add it to "labels" of "runner-tools" rule in actionlint.yaml`},
+	},
+	"schedule": {
+		{id: "schedule/local-time", format: "schedule %q runs%s but %q mentions local time. schedules of GitHub Actions are always evaluated in UTC. convert the time to UTC"},
+		{id: "schedule/local-time-with-offset", format: "schedule %q runs%s but %q implies it is written in local time %02d:%02d %s. schedules of GitHub Actions are always evaluated in UTC. the time in UTC is %s"},
+		{id: "schedule/daylight-saving-time", format: "schedule %q runs%s but %q implies local time in %s which observes daylight saving time. schedules of GitHub Actions are always evaluated in UTC so the local time of the run shifts by one hour when daylight saving time starts or ends"},
+		{id: "schedule/collision", format: "schedule %q collides with schedule %q at line %d. both trigger the workflow at the same minute (e.g. %s UTC%s) so the workflow runs twice"},
+	},
+	"script-linter": {
+		{id: "script-linter/issue", format: "%s reported issue in this script: %s: %s"},
+	},
+	"secret-output": {
+		{id: "secret-output/secret-in-job-output", format: "output %q of job %q contains secrets. job outputs containing secrets are redacted and not passed to downstream jobs. use the secret in the downstream jobs directly via secrets context"},
+		{id: "secret-output/secret-in-step-output", format: `%s is written to step outputs at line %q. step outputs are visible to other steps and jobs and values transformed from secrets are not masked. pass the secret via "env:" to each step which needs it instead`},
+	},
+	"services": {
+		{id: "services/duplicated-host-port", format: "host port %s of %q service is already mapped by %q service. services in the same job cannot share the same port of host"},
+		{id: "services/unexposed-port", format: `%q is used in "run:" script but port %s is not exposed to host by any service. map the port with "ports:" of the service like "%s:%s"`},
+		{id: "services/unclosed-quote-in-options", format: `quotes are not closed in "options:" of %q service: %q`},
+		{id: "services/missing-option-value", format: `value of %q option is missing in "options:" of %q service`},
+		{id: "services/empty-health-cmd", format: `command of "--health-cmd" option should not be empty in "options:" of %q service`},
+		{id: "services/invalid-duration", format: `value %q of %q option is not a valid duration in "options:" of %q service. it should be a duration like "10s" or "1m30s"`},
+		{id: "services/invalid-health-retries", format: `value %q of "--health-retries" option is not a valid number of retries in "options:" of %q service. it should be a non-negative integer`},
+		{id: "services/unknown-health-option", format: `unknown health check option %q in "options:" of %q service. available options are "--health-cmd", "--health-interval", "--health-retries", "--health-start-interval", "--health-start-period", "--health-timeout"`},
+		{id: "services/conflicting-no-healthcheck", format: `"--no-healthcheck" option conflicts with other health check options in "options:" of %q service`},
+	},
+	"setup-cache": {
+		{id: "setup-cache/dependencies-not-cached", format: `%q does not cache dependencies though lockfile %q exists in the repository. enable the built-in cache with "cache: %s" input or add "actions/cache" step to make the job faster`},
+		{id: "setup-cache/missing-cache-dependency-path", format: `file %q at "cache-dependency-path" input of %q does not exist in the repository. the cache cannot be keyed by the lockfile`},
+	},
+	"shell-name": {
+		{id: "shell-name/invalid-name", format: "shell name %q is invalid%s. available names are %s"},
+	},
+	"shellcheck": {
+		{id: "shellcheck/issue", format: "shellcheck reported issue in this script: SC%d:%s:%d:%d: %s"},
+	},
+	"step-name": {
+		{id: "step-name/missing-name", format: `step should have "name:" to make workflow logs readable`},
+		{id: "step-name/duplicated-name", format: "step name %q is duplicated in the job. previously defined at %s"},
+		{id: "step-name/unmatched-name", format: `step name %q does not match to the pattern /%s/ configured in "step-name" rule`},
+	},
+	"style": {
+		{id: "style/line-too-long", format: "line is too long. %d characters exceed the maximum length %d"},
+		{id: "style/trailing-spaces", format: "trailing spaces at end of line"},
+		{id: "style/missing-document-start", format: `document start marker "---" is missing at top of the workflow`},
+		{id: "style/disallowed-document-start", format: `document start marker "---" is not allowed`},
+		{id: "style/truthy-value", format: `truthy value %q should be "true" or "false". YAML 1.1 parsers treat it as boolean but YAML 1.2 parsers treat it as string. quote it if it is intended as string`},
+		{id: "style/mapping-indentation", format: "indentation of this mapping should be %d but found %d"},
+		{id: "style/sequence-indentation", format: "indentation of this sequence should be %d but found %d"},
+	},
+	"syntax-check": {
+		{id: "syntax-check/invalid-ignore-pattern", format: `invalid pattern in "actionlint-ignore" comment: %s`},
+		{id: "syntax-check/expected-key", format: "expected %q key for %q section but got %q"},
+		{
+			id:     "syntax-check/unexpected-key",
+			format: "unexpected key %q for %q section. expected one of %v",
+			translations: map[Locale]string{
+				LocaleJapanese: "%[2]q セクションに予期しないキー %[1]q があります。%[3]v のいずれかが必要です",
+				LocaleGerman:   "unerwarteter Schlüssel %[1]q im Abschnitt %[2]q. erwartet wird einer von %[3]v",
+			},
+		},
+		{id: "syntax-check/unexpected-key-in-section", format: "unexpected key %q for %q section"},
+		{id: "syntax-check/unavailable-filter", format: "%q filter is not available for %q event. available keys are %s"},
+		{id: "syntax-check/empty-section", format: "%q section should not be empty"},
+		{id: "syntax-check/expected-sequence", format: "%q section must be sequence node but got %s node with %q tag"},
+		{id: "syntax-check/expected-string", format: "expected scalar node for string value but found %s node with %q tag"},
+		{
+			id:     "syntax-check/empty-string",
+			format: "string should not be empty",
+			translations: map[Locale]string{
+				LocaleJapanese: "文字列を空にすることはできません",
+				LocaleGerman:   "die Zeichenkette darf nicht leer sein",
+			},
+		},
+		{id: "syntax-check/expected-expression", format: "expecting a single ${{...}} expression or %s, but found plain text node"},
+		{id: "syntax-check/expected-bool", format: "expected bool value but found %s node with %q tag"},
+		{id: "syntax-check/expected-integer", format: "expected scalar node for integer value but found %s node with %q tag"},
+		{id: "syntax-check/invalid-integer", format: "invalid integer value: %q: %s"},
+		{id: "syntax-check/expected-float", format: "expected scalar node for float value but found %s node with %q tag"},
+		{id: "syntax-check/invalid-float", format: "invalid float value: %q: %s"},
+		{
+			id:     "syntax-check/expected-mapping",
+			format: "%s is %s node but mapping node is expected",
+			translations: map[Locale]string{
+				LocaleJapanese: "%[1]s は %[2]s ノードですが、マッピングノードが必要です",
+				LocaleGerman:   "%[1]s ist ein %[2]s-Knoten, aber ein Mapping-Knoten wird erwartet",
+			},
+		},
+		{id: "syntax-check/empty-mapping", format: "%s should not be empty. please remove this section if it's unnecessary"},
+		{id: "syntax-check/duplicated-key", format: "key %q is duplicated in %s. previously defined at %s%s"},
+		{id: "syntax-check/invalid-schedule", format: `element of "schedule" section must be mapping and must contain one key "cron"`},
+		{id: "syntax-check/invalid-workflow-dispatch-input-type", format: `input type of workflow_dispatch event must be one of "string", "number", "boolean", "choice", "environment" but got %q`},
+		{id: "syntax-check/invalid-workflow-call-input-type", format: `invalid value %q for input type of workflow_call event. it must be one of "boolean", "number", or "string"`},
+		{id: "syntax-check/missing-workflow-call-input-type", format: `"type" is missing at %q input of workflow_call event`},
+		{id: "syntax-check/missing-workflow-call-output-value", format: `"value" is missing at %q output of workflow_call event`},
+		{id: "syntax-check/schedule-not-mapping", format: "schedule event must be configured with mapping"},
+		{id: "syntax-check/event-in-sequence", format: `%q event should not be listed in sequence. Use mapping for "on" section and configure the event as values of the mapping`},
+		{id: "syntax-check/invalid-on-section", format: `"on" section value is expected to be mapping or sequence but found %s node`},
+		{id: "syntax-check/missing-defaults-run", format: `"defaults" section should have "run" section`},
+		{id: "syntax-check/missing-concurrency-group", format: `group name is missing in "concurrency" section`},
+		{id: "syntax-check/missing-environment-name", format: `name is missing in "environment" section`},
+		{id: "syntax-check/unexpected-matrix-value", format: "unexpected %s node on parsing value in matrix row"},
+		{id: "syntax-check/invalid-max-parallel", format: `value at "max-parallel" must be greater than zero: %v`},
+		{id: "syntax-check/incomplete-credentials", format: `both "username" and "password" must be specified in "credentials" section`},
+		{id: "syntax-check/invalid-timeout-minutes", format: `value at "timeout-minutes" must be greater than zero: %v`},
+		{id: "syntax-check/action-key-in-run-step", format: `this step is for running shell command since it contains at least one of "run", "shell" keys, but also contains %q key which is used for running action`},
+		{id: "syntax-check/run-key-in-action-step", format: `this step is for running action since it contains at least one of "uses", "with" keys, but also contains %q key which is used for running shell command`},
+		{id: "syntax-check/missing-uses", format: `"uses" is required to run action in step`},
+		{id: "syntax-check/working-directory-with-uses", format: `"working-directory" is not available with "uses". it is only available with "run"`},
+		{id: "syntax-check/missing-run", format: `"run" is required to run script in step`},
+		{id: "syntax-check/missing-run-or-uses", format: `step must run script with "run" section or run action with "uses" section`},
+		{id: "syntax-check/invalid-secrets", format: `expected mapping node for secrets or "inherit" string node but found %q node`},
+		{id: "syntax-check/unavailable-key-in-workflow-call", format: `when a reusable workflow is called with "uses", %q is not available. only following keys are allowed: "name", "uses", "with", "secrets", "needs", "if", and "permissions" in job %q`},
+		{id: "syntax-check/missing-steps", format: `"steps" section is missing in job %q`},
+		{id: "syntax-check/missing-runs-on", format: `"runs-on" section is missing in job %q`},
+		{id: "syntax-check/workflow-call-key-without-uses", format: `%q is only available for a reusable workflow call with "uses" but "uses" is not found in job %q`},
+		{id: "syntax-check/unknown-anchor", format: "unknown anchor %q is referenced by alias"},
+		{id: "syntax-check/recursive-alias", format: "alias %q recursively refers to its own anchor"},
+		{id: "syntax-check/too-many-expanded-nodes", format: "too many nodes are expanded by aliases. expanding alias %q exceeds the limit of %d nodes. nested aliases are not expanded anymore"},
+		{id: "syntax-check/invalid-merge-key-value", format: `value of merge key "<<" must be mapping or sequence of mappings but got %s node`},
+		{id: "syntax-check/duplicated-mapping-key", format: "key %q is duplicated in mapping. previously defined at %s"},
+		{id: "syntax-check/empty-workflow", format: "workflow is empty"},
+		{id: "syntax-check/missing-on", format: `"on" section is missing in workflow`},
+		{id: "syntax-check/missing-jobs", format: `"jobs" section is missing in workflow`},
+		{id: "syntax-check/invalid-yaml", format: "could not parse as YAML: %s"},
+	},
+	"timeout": {
+		{id: "timeout/file-timeout", format: "checking this file did not finish within %s. the remaining checks were skipped. increase the timeout with -file-timeout option"},
+		{id: "timeout/process-timeout", format: "%s while checking this script. the check by %q rule was skipped. increase the timeout with -process-timeout option"},
+	},
+	"unused": {
+		{id: "unused/unused-reusable-workflow", format: "reusable workflow %q is not called by any workflow triggered in this repository. remove it if it is no longer used"},
+		{id: "unused/unused-local-action", format: "local action %q is not used by any workflow triggered in this repository. remove it if it is no longer used"},
+	},
+	"workflow-call": {
+		{id: "workflow-call/invalid-format", format: `reusable workflow call %q at "uses" is not following the format "owner/repo/path/to/workflow.yml@ref" nor "./path/to/workflow.yml". see https://docs.github.com/en/actions/learn-github-actions/reusing-workflows for more details`},
+		{id: "workflow-call/recursive-call", format: "reusable workflow is called recursively: %s. GitHub does not allow reusable workflows to call themselves"},
+		{id: "workflow-call/too-deeply-nested", format: "reusable workflow call %q nests workflows more than %d levels: %s. GitHub allows connecting up to %d levels of workflows including the caller workflow"},
+		{id: "workflow-call/too-many-unique-calls", format: "this workflow calls %d unique reusable workflows including nested calls at this job. GitHub allows one workflow file to call up to %d unique reusable workflows"},
+		{id: "workflow-call/missing-required-input", format: "input %q is required by %q reusable workflow"},
+		{id: "workflow-call/undefined-input", format: "input %q is not defined in %q reusable workflow. %s"},
+		{id: "workflow-call/missing-required-secret", format: "secret %q is required by %q reusable workflow"},
+		{id: "workflow-call/undefined-secret", format: "secret %q is not defined in %q reusable workflow. %s"},
+		{id: "workflow-call/secret-not-inherited", format: `secret %q is required by %q reusable workflow but it is not inherited with "secrets: inherit" since it is not defined in %s`},
+	},
+	"workflow-name": {
+		{
+			id:     "workflow-name/duplicated",
+			format: "workflow name %q is duplicated. it is also used in %q. workflows with the same name are hard to distinguish in GitHub UI and required status checks",
+			translations: map[Locale]string{
+				LocaleJapanese: "ワークフロー名 %[1]q が重複しています。%[2]q でも使用されています。同じ名前のワークフローは GitHub の UI や必須ステータスチェックで区別しにくくなります",
+				LocaleGerman:   "der Workflow-Name %[1]q ist doppelt vorhanden. er wird auch in %[2]q verwendet. Workflows mit demselben Namen sind in der GitHub-Oberfläche und bei erforderlichen Statusprüfungen schwer zu unterscheiden",
+			},
+		},
+	},
+	"workflow-run": {
+		{id: "workflow-run/triggered-only-by-workflow-call", format: "workflow %q referenced by workflow_run event is triggered only by workflow_call event. reusable workflow runs as a part of its caller workflow so it never triggers workflow_run event"},
+		{id: "workflow-run/renamed-workflow", format: `workflow %q referenced by workflow_run event is renamed to %q in %q. update the name in "workflows" filter`},
+		{id: "workflow-run/removed-workflow", format: "workflow %q referenced by workflow_run event is not found in this repository. it was defined in %q at HEAD but the file was removed or moved"},
+		{id: "workflow-run/unknown-workflow", format: `workflow %q referenced by workflow_run event is not found in this repository. "workflows" filter must be the name of workflow at "name:" or the workflow file path when "name:" is omitted`},
+	},
+	"yaml-anchor": {
+		{id: "yaml-anchor/alias", format: `YAML alias %q is used. GitHub Actions may reject workflows using YAML anchors and aliases. disable this rule with "yaml-anchor" configuration when the workflow is preprocessed by some tool`},
+	},
+}
+
+// messageCatalogIndex is a mapping from formats of messages to the entries of the message catalog.
+var messageCatalogIndex = map[string]*messageCatalogEntry{}
+
+func init() {
+	for _, es := range messageCatalog {
+		for _, e := range es {
+			messageCatalogIndex[e.format] = e
+		}
+	}
+}
+
+// messageIDOf returns the message ID of the format of the message. It returns an empty string when
+// the format is not in the message catalog.
+func messageIDOf(format string) string {
+	if e, ok := messageCatalogIndex[format]; ok {
+		return e.id
+	}
+	return ""
+}
+
+// LocalizedMessage returns the message of the error translated into the locale. It returns the
+// English message as-is when the locale is English or the message is not in the message catalog.
+func (e *Error) LocalizedMessage(locale Locale) string {
+	if locale == LocaleEnglish || locale == "" {
+		return e.Message
+	}
+	m, ok := messageCatalogIndex[e.format]
+	if !ok {
+		return e.Message
+	}
+	t, ok := m.translations[locale]
+	if !ok {
+		return e.Message
+	}
+	if fmt.Sprintf(e.format, e.args...) != e.Message {
+		return e.Message // The message was modified after the error was created
+	}
+	return fmt.Sprintf(t, e.args...)
+}
+
+// MessageIDs returns all message IDs in the message catalog in sorted order.
+func MessageIDs() []string {
+	seen := map[string]struct{}{}
+	ids := []string{}
+	for _, es := range messageCatalog {
+		for _, e := range es {
+			if _, ok := seen[e.id]; !ok {
+				seen[e.id] = struct{}{}
+				ids = append(ids, e.id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,455 @@
+package actionlint
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessageCatalogParseLocale(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Locale
+	}{
+		{"", LocaleEnglish},
+		{"en", LocaleEnglish},
+		{"ja", LocaleJapanese},
+		{"ja_JP.UTF-8", LocaleJapanese},
+		{"de-DE", LocaleGerman},
+		{"DE", LocaleGerman},
+		{"en_US.UTF-8", LocaleEnglish},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			have, err := ParseLocale(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+
+	_, err := ParseLocale("fr_FR")
+	if err == nil || !strings.Contains(err.Error(), `unknown locale "fr_FR"`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestMessageCatalogLocalizedMessage(t *testing.T) {
+	pos := &Pos{Line: 1, Col: 1}
+	tests := []struct {
+		what string
+		err  *Error
+		id   string
+		ja   string
+		de   string
+	}{
+		{
+			what: "undefined property",
+			err:  errorOfExpr(pos, "expression", errorfAtExpr(&VariableNode{Name: "foo", tok: &Token{}}, "property %q is not defined in object type %s", "foo", "{bar: string}")),
+			id:   "expression/undefined-property",
+			ja:   `プロパティ "foo" はオブジェクト型 {bar: string} に定義されていません`,
+			de:   `die Eigenschaft "foo" ist im Objekttyp {bar: string} nicht definiert`,
+		},
+		{
+			what: "argument not in translation",
+			err: errorfAt(
+				pos,
+				"expression",
+				"property %q is not defined in object type %s. it is defined in matrix of other job%s %s but \"matrix\" context only contains values in \"strategy.matrix\" of the current job",
+				"foo",
+				"{}",
+				"s",
+				`"a", "b"`,
+			),
+			id: "expression/undefined-matrix-property",
+			ja: `プロパティ "foo" はオブジェクト型 {} に定義されていません。他のジョブ "a", "b" の matrix に定義されていますが、"matrix" コンテキストには現在のジョブの "strategy.matrix" の値のみが含まれます`,
+			de: `die Eigenschaft "foo" ist im Objekttyp {} nicht definiert. sie ist in der Matrix anderer Jobs "a", "b" definiert, aber der Kontext "matrix" enthält nur Werte aus "strategy.matrix" des aktuellen Jobs`,
+		},
+		{
+			what: "undefined job",
+			err:  errorfAt(pos, "job-needs", "job %q needs job %q which does not exist in this workflow", "a", "b"),
+			id:   "job-needs/undefined-job",
+			ja:   `ジョブ "a" は、このワークフローに存在しないジョブ "b" に依存しています`,
+			de:   `der Job "a" benötigt den Job "b", der in diesem Workflow nicht existiert`,
+		},
+		{
+			what: "different word order",
+			err:  errorfAt(pos, "syntax-check", "unexpected key %q for %q section. expected one of %v", "foo", "job", `"bar", "baz"`),
+			id:   "syntax-check/unexpected-key",
+			ja:   `"job" セクションに予期しないキー "foo" があります。"bar", "baz" のいずれかが必要です`,
+			de:   `unerwarteter Schlüssel "foo" im Abschnitt "job". erwartet wird einer von "bar", "baz"`,
+		},
+		{
+			what: "no argument",
+			err:  errorAt(pos, "syntax-check", "string should not be empty"),
+			id:   "syntax-check/empty-string",
+			ja:   `文字列を空にすることはできません`,
+			de:   `die Zeichenkette darf nicht leer sein`,
+		},
+		{
+			what: "no translation",
+			err:  errorfAt(pos, "job-needs", "job ID %q duplicates in \"needs\" section. note that job ID is case insensitive", "a"),
+			id:   "job-needs/duplicated-needs",
+		},
+		{
+			what: "not in catalog",
+			err:  errorAt(pos, "expression", "message not in catalog"),
+		},
+		{
+			what: "message without format",
+			err:  errorAt(pos, "job-needs", `job "a" needs job "b" which does not exist in this workflow`),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			if tc.err.MessageID != tc.id {
+				t.Errorf("wanted message ID %q but got %q", tc.id, tc.err.MessageID)
+			}
+			for _, l := range []struct {
+				locale Locale
+				want   string
+			}{
+				{LocaleEnglish, tc.err.Message},
+				{LocaleJapanese, tc.ja},
+				{LocaleGerman, tc.de},
+			} {
+				want := l.want
+				if want == "" {
+					want = tc.err.Message
+				}
+				if have := tc.err.LocalizedMessage(l.locale); have != want {
+					t.Errorf("wanted %q in locale %q but got %q", want, l.locale, have)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageCatalogModifiedMessageNotLocalized(t *testing.T) {
+	err := errorfAt(&Pos{}, "job-needs", "job %q needs job %q which does not exist in this workflow", "a", "b")
+	err.Message += " (modified)"
+	if have := err.LocalizedMessage(LocaleJapanese); have != err.Message {
+		t.Fatalf("modified message should not be localized but got %q", have)
+	}
+}
+
+var reTestMessageCatalogVerb = regexp.MustCompile(`%(\[(\d+)\])?[+#0-9.]*([a-zA-Z%])`)
+
+// testMessageCatalogVerbs returns the set of pairs of argument index and verb in the format like
+// "2q". The value of each pair is true when the argument is a suffix of a word like "job%s" for
+// plural forms. Translations can omit such arguments since plural forms depend on languages.
+func testMessageCatalogVerbs(format string) map[string]bool {
+	ret := map[string]bool{}
+	i := 0
+	for _, m := range reTestMessageCatalogVerb.FindAllStringSubmatchIndex(format, -1) {
+		verb := format[m[6]:m[7]]
+		if verb == "%" {
+			continue
+		}
+		if m[4] >= 0 {
+			i, _ = strconv.Atoi(format[m[4]:m[5]])
+		} else {
+			i++
+		}
+		suffix := verb == "s" && m[0] > 0 && unicode.IsLetter(rune(format[m[0]-1]))
+		ret[fmt.Sprintf("%d%s", i, verb)] = suffix
+	}
+	return ret
+}
+
+func TestMessageCatalogEntries(t *testing.T) {
+	formats := map[string]struct{}{}
+	for k, es := range messageCatalog {
+		if ErrorCode(k) == "" {
+			t.Errorf("kind %q in message catalog is unknown", k)
+		}
+		for _, e := range es {
+			if _, ok := formats[e.format]; ok {
+				t.Errorf("format of message %q is duplicated: %q", e.id, e.format)
+			}
+			formats[e.format] = struct{}{}
+			if !strings.HasPrefix(e.id, k+"/") {
+				t.Errorf("message ID %q must start with its kind %q", e.id, k)
+			}
+			if len(e.translations) == 0 {
+				continue
+			}
+			for l := range e.translations {
+				if l != LocaleJapanese && l != LocaleGerman {
+					t.Errorf("message %q has translation for unknown locale %q", e.id, l)
+				}
+			}
+			verbs := testMessageCatalogVerbs(e.format)
+			for _, l := range []Locale{LocaleJapanese, LocaleGerman} {
+				tr, ok := e.translations[l]
+				if !ok {
+					t.Errorf("message %q has no translation for locale %q", e.id, l)
+					continue
+				}
+				trVerbs := testMessageCatalogVerbs(tr)
+				for v := range trVerbs {
+					if _, ok := verbs[v]; !ok {
+						t.Errorf("translation of message %q for locale %q uses argument %q which is not in the format %q: %q", e.id, l, v, e.format, tr)
+					}
+				}
+				for v, suffix := range verbs {
+					if _, ok := trVerbs[v]; !ok && !suffix {
+						t.Errorf("translation of message %q for locale %q does not use argument %q in the format %q: %q", e.id, l, v, e.format, tr)
+					}
+				}
+			}
+		}
+	}
+}
+
+// testMessageCatalogFormatArg returns the argument of the message or its format at the call which
+// creates an error. It returns nil when the call does not create an error.
+func testMessageCatalogFormatArg(file string, c *ast.CallExpr) ast.Expr {
+	idx := -1
+	switch f := c.Fun.(type) {
+	case *ast.Ident:
+		switch f.Name {
+		case "errorAt", "errorfAt":
+			idx = 2
+		case "errorAtToken", "errorfAtToken", "errorAtExpr", "errorfAtExpr":
+			idx = 1
+		}
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok && x.Name == "fmt" {
+			return nil
+		}
+		switch f.Sel.Name {
+		case "Errorf", "errorAt", "errorfAt":
+			idx = 1
+		case "FileErrorf":
+			idx = 2
+		case "Error":
+			if len(c.Args) == 2 {
+				idx = 1
+			}
+		case "error", "errorf":
+			idx = 1
+			if file == "expr_lexer.go" || file == "expr_parser.go" {
+				idx = 0
+			}
+		}
+	}
+	if idx < 0 || idx >= len(c.Args) {
+		return nil
+	}
+	return c.Args[idx]
+}
+
+// All messages in sources must be in the message catalog and all messages in the message catalog
+// must be used in sources.
+func TestMessageCatalogCoversMessagesInSources(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		panic(err)
+	}
+
+	used := map[string]struct{}{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := goparser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			c, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			l, ok := testMessageCatalogFormatArg(file, c).(*ast.BasicLit)
+			if !ok || l.Kind != token.STRING {
+				return true // Message is created dynamically
+			}
+			s, err := strconv.Unquote(l.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(s, "internal error in rule ") {
+				return true // Kind of this error is not fixed
+			}
+			used[s] = struct{}{}
+			if messageIDOf(s) == "" {
+				t.Errorf("message at %s is not in the message catalog: %q", fset.Position(l.Pos()), s)
+			}
+			return true
+		})
+	}
+
+	for _, es := range messageCatalog {
+		for _, e := range es {
+			if _, ok := used[e.format]; !ok {
+				t.Errorf("message %q in the message catalog is not used in any source: %q", e.id, e.format)
+			}
+		}
+	}
+}
+
+// Errors reported for the test data must have message IDs matching their kinds. Messages which have
+// translations must be reported at least once so that outdated formats in the catalog are detected.
+func TestMessageCatalogTestData(t *testing.T) {
+	errs := []*Error{}
+	for _, subdir := range []string{"examples", "err"} {
+		dir, infiles, err := testFindAllWorkflowsInDir(subdir)
+		if err != nil {
+			panic(err)
+		}
+		proj := &Project{root: dir}
+		for _, infile := range infiles {
+			b, err := os.ReadFile(infile)
+			if err != nil {
+				panic(err)
+			}
+			l, err := NewLinter(io.Discard, &LinterOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{}
+			es, err := l.Lint("test.yaml", b, proj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs = append(errs, es...)
+		}
+	}
+
+	root := filepath.Join("testdata", "projects")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		repo := filepath.Join(root, e.Name())
+		es, err := testNewProjectLinter(t, repo).LintDir(testProjectWorkflowsDir(repo), &Project{root: repo})
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs = append(errs, es...)
+	}
+
+	reported := map[string]struct{}{}
+	for _, err := range errs {
+		if err.MessageID == "" {
+			continue
+		}
+		if !strings.HasPrefix(err.MessageID, err.Kind+"/") {
+			t.Errorf("message ID %q does not match to kind %q of error %q", err.MessageID, err.Kind, err.Message)
+		}
+		reported[err.format] = struct{}{}
+		for _, l := range []Locale{LocaleJapanese, LocaleGerman} {
+			if msg := err.LocalizedMessage(l); strings.Contains(msg, "%!") {
+				t.Errorf("message %q is broken in locale %q: %q", err.Message, l, msg)
+			}
+		}
+	}
+
+	for _, es := range messageCatalog {
+		for _, e := range es {
+			if len(e.translations) == 0 {
+				continue
+			}
+			if _, ok := reported[e.format]; !ok {
+				t.Errorf("message %q was not reported for test data. the format may be outdated: %q", e.id, e.format)
+			}
+		}
+	}
+}
+
+func TestMessageCatalogLinterOutput(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    needs: [unknown]
+    steps:
+      - run: echo ${{ matrix.foo }}
+`
+	var b bytes.Buffer
+	l, err := NewLinter(&b, &LinterOptions{Locale: "ja_JP.UTF-8", Oneline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatal("two errors were expected:", errs)
+	}
+	// Errors returned from the linter are not localized
+	if want := `job "test" needs job "unknown" which does not exist in this workflow`; errs[0].Message != want {
+		t.Errorf("wanted message %q but got %q", want, errs[0].Message)
+	}
+	have := b.String()
+	for _, want := range []string{
+		`test.yaml:3:3: ジョブ "test" は、このワークフローに存在しないジョブ "unknown" に依存しています [job-needs]`,
+		`test.yaml:7:23: プロパティ "foo" はオブジェクト型 {} に定義されていません [expression]`,
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("output does not contain %q: %q", want, have)
+		}
+	}
+
+	_, err = NewLinter(&b, &LinterOptions{Locale: "fr"})
+	if err == nil || !strings.Contains(err.Error(), `unknown locale "fr"`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+// Translated messages are listed in docs/usage.md since the other messages fall back to English.
+func TestMessageCatalogTranslatedMessagesInDocs(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("docs", "usage.md"))
+	if err != nil {
+		panic(err)
+	}
+	doc := string(b)
+	i := strings.Index(doc, "The following messages are\ntranslated into all the locales:")
+	if i < 0 {
+		t.Fatal("list of translated messages is not found in docs/usage.md")
+	}
+	listed := []string{}
+	for _, l := range strings.Split(doc[i:], "\n")[3:] {
+		if !strings.HasPrefix(l, "- `") {
+			break
+		}
+		listed = append(listed, strings.Trim(l[2:], "`"))
+	}
+
+	translated := []string{}
+	for _, id := range MessageIDs() {
+		for _, e := range messageCatalog[id[:strings.IndexByte(id, '/')]] {
+			if e.id == id && len(e.translations) > 0 {
+				translated = append(translated, id)
+				break
+			}
+		}
+	}
+
+	if diff := cmp.Diff(translated, listed); diff != "" {
+		t.Fatalf("translated messages listed in docs/usage.md are outdated: %s", diff)
+	}
+}
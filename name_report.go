@@ -0,0 +1,249 @@
+package actionlint
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nameReportContexts is a list of contexts whose property names are collected by NameReport.
+var nameReportContexts = []string{"inputs", "secrets", "vars"}
+
+// NameReportEntry is a reference to a property of "vars", "secrets", or "inputs" context in
+// expressions like `vars.IMAGE_NAME`.
+type NameReportEntry struct {
+	// Context is a name of the context. One of "vars", "secrets", or "inputs".
+	Context string `json:"context"`
+	// Name is a name of the property as written in the expression.
+	Name string `json:"name"`
+	// File is a file path of the workflow which references the name.
+	File string `json:"file"`
+	// Line is a line number of the reference in the workflow.
+	Line int `json:"line"`
+	// Column is a column number of the reference in the workflow.
+	Column int `json:"column"`
+}
+
+// NameReport is a report of names of variables, secrets, and inputs referenced in expressions of
+// workflows. It helps administrators to reconcile repository settings with usage in workflows.
+type NameReport struct {
+	// Entries is a list of all references.
+	Entries []*NameReportEntry
+}
+
+// NewNameReport creates a new empty NameReport instance.
+func NewNameReport() *NameReport {
+	return &NameReport{}
+}
+
+// AddWorkflow adds references in the workflow source to the report. The path parameter is a file
+// path of the workflow. Expressions in ${{ }} and conditions at "if:" are scanned.
+func (r *NameReport) AddWorkflow(path string, src []byte) error {
+	var n yaml.Node
+	if err := unmarshalYAML(src, &n); err != nil {
+		return fmt.Errorf("could not parse %q: %w", path, err)
+	}
+	lines := splitLines(string(src))
+	r.addNode(path, &n, false, lines)
+	return nil
+}
+
+func (r *NameReport) addNode(path string, n *yaml.Node, cond bool, lines []string) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			r.addNode(path, c, false, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			r.addNode(path, n.Content[i+1], n.Content[i].Value == "if", lines)
+		}
+	case yaml.ScalarNode:
+		if n.ShortTag() != "!!str" {
+			return
+		}
+		if cond && !ContainsExpression(n.Value) {
+			r.addExpr(path, n, n.Value+"}}", 0, lines)
+			return
+		}
+		for off := 0; ; {
+			i := strings.Index(n.Value[off:], "${{")
+			if i < 0 {
+				return
+			}
+			start := off + i + len("${{")
+			end := r.addExpr(path, n, n.Value[start:], start, lines)
+			if end == 0 {
+				return
+			}
+			off = start + end
+		}
+	}
+}
+
+// addExpr adds references in the expression source which ends with "}}". The offset parameter is
+// the offset of the source in the scalar node. It returns the offset of the end of the expression in
+// the source. 0 is returned when lexing the expression failed.
+func (r *NameReport) addExpr(path string, n *yaml.Node, src string, offset int, lines []string) int {
+	ts := []*Token{}
+	l := NewExprLexer(src)
+	for {
+		t := l.Next()
+		if l.Err() != nil {
+			return 0
+		}
+		if t.Kind == TokenKindEnd {
+			break
+		}
+		ts = append(ts, t)
+	}
+
+	for i, t := range ts {
+		if t.Kind != TokenKindIdent || i+2 >= len(ts) || (i > 0 && ts[i-1].Kind == TokenKindDot) {
+			continue
+		}
+		ctx := strings.ToLower(t.Value)
+		if !containsString(nameReportContexts, ctx) {
+			continue
+		}
+		var name string
+		switch p := ts[i+2]; ts[i+1].Kind {
+		case TokenKindDot:
+			if p.Kind == TokenKindIdent {
+				name = p.Value
+			}
+		case TokenKindLeftBracket:
+			if p.Kind == TokenKindString {
+				name = strings.ReplaceAll(p.Value[1:len(p.Value)-1], "''", "'")
+			}
+		}
+		if name == "" {
+			continue
+		}
+		line, col := nameReportPos(n, offset+t.Offset, lines)
+		r.Entries = append(r.Entries, &NameReportEntry{ctx, name, path, line, col})
+	}
+
+	return l.Offset()
+}
+
+// nameReportPos converts the offset in the value of the scalar node into the line and column in the
+// source. When the position cannot be calculated, the position of the node is returned.
+func nameReportPos(n *yaml.Node, offset int, lines []string) (int, int) {
+	v := n.Value[:offset]
+	switch n.Style {
+	case yaml.LiteralStyle:
+		// Content of the block scalar starts from the next line of "|". Since indentation is not
+		// included in the value, search the line in the source.
+		line := n.Line + 1 + strings.Count(v, "\n")
+		start := strings.LastIndexByte(v, '\n') + 1
+		seg := n.Value[start:]
+		if i := strings.IndexByte(seg, '\n'); i >= 0 {
+			seg = seg[:i]
+		}
+		if line-1 < len(lines) {
+			if i := strings.Index(lines[line-1], seg); i >= 0 {
+				return line, i + offset - start + 1
+			}
+		}
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		if !strings.Contains(v, "\n") {
+			return n.Line, n.Column + offset + 1 // +1 for the opening quote
+		}
+	case 0:
+		if !strings.Contains(v, "\n") {
+			return n.Line, n.Column + offset
+		}
+	}
+	return n.Line, n.Column
+}
+
+// Sort sorts the entries by file paths and positions.
+func (r *NameReport) Sort() {
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		a, b := r.Entries[i], r.Entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Print prints the report to the writer in the format. The format is one of "json", "csv", or
+// "table". "table" format aggregates references by names. Names are case-insensitive.
+func (r *NameReport) Print(w io.Writer, format string) error {
+	switch format {
+	case "json", "":
+		es := r.Entries
+		if es == nil {
+			es = []*NameReportEntry{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(es); err != nil {
+			return fmt.Errorf("could not encode report of names to JSON: %w", err)
+		}
+		return nil
+	case "csv":
+		c := csv.NewWriter(w)
+		c.Write([]string{"context", "name", "file", "line", "column"})
+		for _, e := range r.Entries {
+			c.Write([]string{e.Context, e.Name, e.File, strconv.Itoa(e.Line), strconv.Itoa(e.Column)})
+		}
+		c.Flush()
+		if err := c.Error(); err != nil {
+			return fmt.Errorf("could not write report of names as CSV: %w", err)
+		}
+		return nil
+	case "table":
+		return r.printTable(w)
+	default:
+		return fmt.Errorf("unknown format %q of report of names. it must be one of \"json\", \"csv\", or \"table\"", format)
+	}
+}
+
+func (r *NameReport) printTable(w io.Writer) error {
+	type row struct {
+		ctx  string
+		name string
+		locs []string
+	}
+	rows := []*row{}
+	idx := map[string]*row{}
+	for _, e := range r.Entries {
+		k := e.Context + "." + strings.ToLower(e.Name)
+		ro, ok := idx[k]
+		if !ok {
+			ro = &row{ctx: e.Context, name: e.Name}
+			idx[k] = ro
+			rows = append(rows, ro)
+		}
+		ro.locs = append(ro.locs, fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column))
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].ctx != rows[j].ctx {
+			return rows[i].ctx < rows[j].ctx
+		}
+		return strings.ToLower(rows[i].name) < strings.ToLower(rows[j].name)
+	})
+
+	t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(t, "CONTEXT\tNAME\tREFERENCES\tLOCATIONS")
+	for _, ro := range rows {
+		fmt.Fprintf(t, "%s\t%s\t%d\t%s\n", ro.ctx, ro.name, len(ro.locs), strings.Join(ro.locs, ", "))
+	}
+	if err := t.Flush(); err != nil {
+		return fmt.Errorf("could not write report of names as table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package actionlint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testNameReportWorkflow = `on:
+  workflow_call:
+    inputs:
+      version:
+        type: string
+jobs:
+  test:
+    if: vars.ENABLED == 'true' && github.event.inputs.skip != 'true'
+    runs-on: ${{ vars.RUNNER }}
+    env:
+      TOKEN: "${{ secrets.NPM_TOKEN }}"
+      PAIR: ${{ inputs.version }}-${{ secrets['Other-Secret'] }}
+    steps:
+      - run: |
+          echo ${{ inputs.version }}
+            echo ${{ vars.runner }}
+      - if: ${{ secrets.npm_token != '' }}
+        run: echo 'vars.NOT_EXPR'
+      - run: echo ${{ vars }} ${{ format('{0}', vars.A) }}
+`
+
+func TestNameReportAddWorkflow(t *testing.T) {
+	r := NewNameReport()
+	if err := r.AddWorkflow("ci.yaml", []byte(testNameReportWorkflow)); err != nil {
+		t.Fatal(err)
+	}
+	r.Sort()
+
+	want := []*NameReportEntry{
+		{"vars", "ENABLED", "ci.yaml", 8, 9},
+		{"vars", "RUNNER", "ci.yaml", 9, 18},
+		{"secrets", "NPM_TOKEN", "ci.yaml", 11, 19},
+		{"inputs", "version", "ci.yaml", 12, 17},
+		{"secrets", "Other-Secret", "ci.yaml", 12, 39},
+		{"inputs", "version", "ci.yaml", 15, 20},
+		{"vars", "runner", "ci.yaml", 16, 22},
+		{"secrets", "npm_token", "ci.yaml", 17, 17},
+		{"vars", "A", "ci.yaml", 19, 49},
+	}
+	if diff := cmp.Diff(want, r.Entries); diff != "" {
+		t.Fatal(diff)
+	}
+
+	lines := strings.Split(testNameReportWorkflow, "\n")
+	for _, e := range r.Entries {
+		l := lines[e.Line-1]
+		if !strings.HasPrefix(l[e.Column-1:], e.Context) {
+			t.Errorf("position %d:%d of %s.%s is wrong: %q", e.Line, e.Column, e.Context, e.Name, l)
+		}
+	}
+}
+
+func TestNameReportPrint(t *testing.T) {
+	r := NewNameReport()
+	r.Entries = []*NameReportEntry{
+		{"vars", "IMAGE", "a.yaml", 1, 10},
+		{"secrets", "TOKEN", "a.yaml", 2, 10},
+		{"vars", "image", "b.yaml", 3, 5},
+	}
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "table",
+			want: `CONTEXT  NAME   REFERENCES  LOCATIONS
+secrets  TOKEN  1           a.yaml:2:10
+vars     IMAGE  2           a.yaml:1:10, b.yaml:3:5
+`,
+		},
+		{
+			format: "csv",
+			want: `context,name,file,line,column
+vars,IMAGE,a.yaml,1,10
+secrets,TOKEN,a.yaml,2,10
+vars,image,b.yaml,3,5
+`,
+		},
+		{
+			format: "json",
+			want: `[
+  {
+    "context": "vars",
+    "name": "IMAGE",
+    "file": "a.yaml",
+    "line": 1,
+    "column": 10
+  },
+  {
+    "context": "secrets",
+    "name": "TOKEN",
+    "file": "a.yaml",
+    "line": 2,
+    "column": 10
+  },
+  {
+    "context": "vars",
+    "name": "image",
+    "file": "b.yaml",
+    "line": 3,
+    "column": 5
+  }
+]
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := r.Print(&b, tc.format); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	var b bytes.Buffer
+	if err := NewNameReport().Print(&b, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "[]\n" {
+		t.Fatalf("empty report should be printed as empty array: %q", b.String())
+	}
+
+	err := r.Print(&b, "spdx")
+	if err == nil || !strings.Contains(err.Error(), `unknown format "spdx" of report of names`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestCommandReportNames(t *testing.T) {
+	dir := t.TempDir()
+	ci := filepath.Join(dir, "ci.yaml")
+	if err := os.WriteFile(ci, []byte(testNameReportWorkflow), 0644); err != nil {
+		panic(err)
+	}
+
+	var out bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &out, Stderr: &out}
+	if status := cmd.Main([]string{"actionlint", "-report", "names", "-report-format", "table", ci}); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, out.String())
+	}
+	for _, want := range []string{
+		"inputs   version       2",
+		"secrets  NPM_TOKEN     2",
+		"vars     RUNNER        2",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%q is not included in output %q", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if status := cmd.Main([]string{"actionlint", "-report", "unknown", ci}); status == 0 {
+		t.Fatal("exit status should not be 0", out.String())
+	}
+	if want := `it must be "actions" or "names"`; !strings.Contains(out.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, out.String())
+	}
+}
@@ -1,7 +1,9 @@
 package actionlint
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"regexp"
 	"strconv"
@@ -29,7 +31,7 @@ func nodeKindName(k yaml.Kind) string {
 }
 
 func posAt(n *yaml.Node) *Pos {
-	return &Pos{n.Line, n.Column}
+	return &Pos{Line: n.Line, Col: n.Column}
 }
 
 func isNull(n *yaml.Node) bool {
@@ -50,37 +52,81 @@ type workflowKeyVal struct {
 
 type parser struct {
 	errors []*Error
+	// aliases is a list of YAML aliases found while resolving them. Value is the anchor name and Pos
+	// is the position where the alias is used.
+	aliases []*String
+	// expanding is a set of anchored nodes which are being expanded. This is used for detecting
+	// recursive aliases.
+	expanding map[*yaml.Node]struct{}
+	// expanded is the number of nodes copied by expanding aliases and aliasBudget is the limit of it.
+	// aliasBudget is zero until resolving aliases starts.
+	expanded    int
+	aliasBudget int
+	// mappings is a set of mapping nodes which were already checked by parseMapping. Duplicate keys
+	// in other mapping nodes are checked by checkDuplicateKeys.
+	mappings map[*yaml.Node]struct{}
+	// src is the source file being parsed. It is used for calculating byte offsets of positions. It
+	// is nil when the source is not available.
+	src *SourceFile
+}
+
+// posAt returns the position of the node with its byte offset in the source.
+func (p *parser) posAt(n *yaml.Node) *Pos {
+	if p.src == nil {
+		return posAt(n)
+	}
+	return p.src.pos(n.Line, n.Column)
+}
+
+func (p *parser) newString(n *yaml.Node) *String {
+	quoted := n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+	return &String{n.Value, quoted, p.posAt(n)}
 }
 
 func (p *parser) error(n *yaml.Node, m string) {
-	p.errors = append(p.errors, &Error{m, "", n.Line, n.Column, "syntax-check"})
+	p.errorAt(p.posAt(n), m)
 }
 
 func (p *parser) errorAt(pos *Pos, m string) {
-	p.errors = append(p.errors, &Error{m, "", pos.Line, pos.Col, "syntax-check"})
+	p.errors = append(p.errors, errorAt(pos, "syntax-check", m))
 }
 
 func (p *parser) errorfAt(pos *Pos, format string, args ...interface{}) {
-	m := fmt.Sprintf(format, args...)
-	p.errorAt(pos, m)
+	p.errors = append(p.errors, errorfAt(pos, "syntax-check", format, args...))
 }
 
 func (p *parser) errorf(n *yaml.Node, format string, args ...interface{}) {
-	m := fmt.Sprintf(format, args...)
-	p.error(n, m)
+	p.errorfAt(p.posAt(n), format, args...)
 }
 
 func (p *parser) unexpectedKey(s *String, sec string, expected []string) {
+	if isKnownWorkflowKey(sec, s.Value) {
+		// The key is defined in the workflow schema but this version of actionlint does not know it
+		// yet. How to treat it is controlled by -future-syntax option
+		p.errors = append(p.errors, errorfAt(s.Pos, "future-syntax", "key %q for %q section is defined in the workflow syntax but not supported by this version of actionlint yet. update actionlint to check it", s.Value, sec))
+		return
+	}
+
 	l := len(expected)
-	var m string
 	if l == 1 {
-		m = fmt.Sprintf("expected %q key for %q section but got %q", expected[0], sec, s.Value)
+		p.errorfAt(s.Pos, "expected %q key for %q section but got %q", expected[0], sec, s.Value)
 	} else if l > 1 {
-		m = fmt.Sprintf("unexpected key %q for %q section. expected one of %v", s.Value, sec, sortedQuotes(expected))
+		p.errorfAt(s.Pos, "unexpected key %q for %q section. expected one of %v", s.Value, sec, sortedQuotes(expected))
 	} else {
-		m = fmt.Sprintf("unexpected key %q for %q section", s.Value, sec)
+		p.errorfAt(s.Pos, "unexpected key %q for %q section", s.Value, sec)
+	}
+}
+
+// unexpectedEventKey reports an unexpected key in the configuration of non-Webhook event such as
+// "workflow_dispatch". When the key is a filter of Webhook events like "branches", it reports the
+// filter is not available for the event since the unexpected key error would be confusing.
+func (p *parser) unexpectedEventKey(s *String, event string, expected []string) {
+	switch s.Value {
+	case "types", "branches", "branches-ignore", "tags", "tags-ignore", "paths", "paths-ignore", "workflows":
+		p.errorfAt(s.Pos, "%q filter is not available for %q event. available keys are %s", s.Value, event, sortedQuotes(expected))
+	default:
+		p.unexpectedKey(s, event, expected)
 	}
-	p.errorAt(s.Pos, m)
 }
 
 func (p *parser) checkNotEmpty(sec string, len int, n *yaml.Node) bool {
@@ -122,7 +168,7 @@ func (p *parser) parseExpression(n *yaml.Node, expecting string) *String {
 		p.missingExpression(n, expecting)
 		return nil
 	}
-	return newString(n)
+	return p.newString(n)
 }
 
 func (p *parser) mayParseExpression(n *yaml.Node) *String {
@@ -132,14 +178,14 @@ func (p *parser) mayParseExpression(n *yaml.Node) *String {
 	if !isExprAssigned(n.Value) {
 		return nil
 	}
-	return newString(n)
+	return p.newString(n)
 }
 
 func (p *parser) parseString(n *yaml.Node, allowEmpty bool) *String {
 	if !p.checkString(n, allowEmpty) {
-		return &String{"", false, posAt(n)}
+		return &String{"", false, p.posAt(n)}
 	}
-	return newString(n)
+	return p.newString(n)
 }
 
 func (p *parser) parseStringSequence(sec string, n *yaml.Node, allowEmpty bool, allowElemEmpty bool) []*String {
@@ -179,13 +225,13 @@ func (p *parser) parseBool(n *yaml.Node) *Bool {
 		e := p.parseExpression(n, "boolean literal \"true\" or \"false\"")
 		return &Bool{
 			Expression: e,
-			Pos:        posAt(n),
+			Pos:        p.posAt(n),
 		}
 	}
 
 	return &Bool{
 		Value: n.Value == "true",
-		Pos:   posAt(n),
+		Pos:   p.posAt(n),
 	}
 }
 
@@ -202,7 +248,7 @@ func (p *parser) parseInt(n *yaml.Node) *Int {
 		}
 		return &Int{
 			Expression: e,
-			Pos:        posAt(n),
+			Pos:        p.posAt(n),
 		}
 	}
 
@@ -214,7 +260,7 @@ func (p *parser) parseInt(n *yaml.Node) *Int {
 
 	return &Int{
 		Value: i,
-		Pos:   posAt(n),
+		Pos:   p.posAt(n),
 	}
 }
 
@@ -231,7 +277,7 @@ func (p *parser) parseFloat(n *yaml.Node) *Float {
 		}
 		return &Float{
 			Expression: e,
-			Pos:        posAt(n),
+			Pos:        p.posAt(n),
 		}
 	}
 
@@ -243,7 +289,7 @@ func (p *parser) parseFloat(n *yaml.Node) *Float {
 
 	return &Float{
 		Value: f,
-		Pos:   posAt(n),
+		Pos:   p.posAt(n),
 	}
 }
 
@@ -260,6 +306,11 @@ func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty, caseSensiti
 		return nil
 	}
 
+	if p.mappings == nil {
+		p.mappings = map[*yaml.Node]struct{}{}
+	}
+	p.mappings[n] = struct{}{}
+
 	l := len(n.Content) / 2
 	keys := make(map[string]*Pos, l)
 	m := make([]workflowKeyVal, 0, l)
@@ -310,12 +361,21 @@ func (p *parser) parseScheduleEvent(pos *Pos, n *yaml.Node) *ScheduledEvent {
 	cron := make([]*String, 0, len(n.Content))
 	for _, c := range n.Content {
 		m := p.parseMapping("element of \"schedule\" section", c, false, true)
-		if len(m) != 1 || m[0].id != "cron" {
-			p.error(c, "element of \"schedule\" section must be mapping and must contain one key \"cron\"")
+		var v *yaml.Node
+		for _, kv := range m {
+			if kv.id == "cron" {
+				v = kv.val
+			} else {
+				p.unexpectedEventKey(kv.key, "schedule", []string{"cron"})
+			}
+		}
+		if v == nil {
+			if len(m) == 0 {
+				p.error(c, "element of \"schedule\" section must be mapping and must contain one key \"cron\"")
+			}
 			continue
 		}
-		s := p.parseString(m[0].val, false)
-		if s != nil {
+		if s := p.parseString(v, false); s != nil {
 			cron = append(cron, s)
 		}
 	}
@@ -329,7 +389,7 @@ func (p *parser) parseWorkflowDispatchEvent(pos *Pos, n *yaml.Node) *WorkflowDis
 
 	for _, kv := range p.parseSectionMapping("workflow_dispatch", n, true, true) {
 		if kv.id != "inputs" {
-			p.unexpectedKey(kv.key, "workflow_dispatch", []string{"inputs"})
+			p.unexpectedEventKey(kv.key, "workflow_dispatch", []string{"inputs"})
 			continue
 		}
 
@@ -400,7 +460,7 @@ func (p *parser) parseRepositoryDispatchEvent(pos *Pos, n *yaml.Node) *Repositor
 		if kv.id == "types" {
 			ret.Types = p.parseStringOrStringSequence("types", kv.val, false, false)
 		} else {
-			p.unexpectedKey(kv.key, "repository_dispatch", []string{"types"})
+			p.unexpectedEventKey(kv.key, "repository_dispatch", []string{"types"})
 		}
 	}
 
@@ -553,7 +613,7 @@ func (p *parser) parseWorkflowCallEvent(pos *Pos, n *yaml.Node) *WorkflowCallEve
 				ret.Outputs[kv.id] = output
 			}
 		default:
-			p.unexpectedKey(kv.key, "workflow_call", []string{"inputs", "secrets", "outputs"})
+			p.unexpectedEventKey(kv.key, "workflow_call", []string{"inputs", "secrets", "outputs"})
 		}
 	}
 
@@ -566,18 +626,18 @@ func (p *parser) parseEvents(pos *Pos, n *yaml.Node) []Event {
 		switch n.Value {
 		case "workflow_dispatch":
 			return []Event{
-				&WorkflowDispatchEvent{Pos: posAt(n)},
+				&WorkflowDispatchEvent{Pos: p.posAt(n)},
 			}
 		case "repository_dispatch":
 			return []Event{
-				&RepositoryDispatchEvent{Pos: posAt(n)},
+				&RepositoryDispatchEvent{Pos: p.posAt(n)},
 			}
 		case "schedule":
 			p.errorAt(pos, "schedule event must be configured with mapping")
 			return []Event{}
 		case "workflow_call":
 			return []Event{
-				&WorkflowCallEvent{Pos: posAt(n)},
+				&WorkflowCallEvent{Pos: p.posAt(n)},
 			}
 		default:
 			h := p.parseString(n, false)
@@ -587,7 +647,7 @@ func (p *parser) parseEvents(pos *Pos, n *yaml.Node) []Event {
 			return []Event{
 				&WebhookEvent{
 					Hook: h,
-					Pos:  posAt(n),
+					Pos:  p.posAt(n),
 				},
 			}
 		}
@@ -625,11 +685,11 @@ func (p *parser) parseEvents(pos *Pos, n *yaml.Node) []Event {
 				case "schedule", "repository_dispatch":
 					p.errorf(c, "%q event should not be listed in sequence. Use mapping for \"on\" section and configure the event as values of the mapping", s.Value)
 				case "workflow_dispatch":
-					ret = append(ret, &WorkflowDispatchEvent{Pos: posAt(c)})
+					ret = append(ret, &WorkflowDispatchEvent{Pos: p.posAt(c)})
 				case "workflow_call":
-					ret = append(ret, &WorkflowCallEvent{Pos: posAt(c)})
+					ret = append(ret, &WorkflowCallEvent{Pos: p.posAt(c)})
 				default:
-					ret = append(ret, &WebhookEvent{Hook: s, Pos: posAt(c)})
+					ret = append(ret, &WebhookEvent{Hook: s, Pos: p.posAt(c)})
 				}
 			}
 		}
@@ -784,7 +844,7 @@ func (p *parser) parseOutputs(n *yaml.Node) map[string]*Output {
 func (p *parser) parseRawYAMLValue(n *yaml.Node) RawYAMLValue {
 	switch n.Kind {
 	case yaml.ScalarNode:
-		return &RawYAMLString{n.Value, posAt(n)}
+		return &RawYAMLString{n.Value, p.posAt(n)}
 	case yaml.SequenceNode:
 		vs := make([]RawYAMLValue, 0, len(n.Content))
 		for _, c := range n.Content {
@@ -792,7 +852,7 @@ func (p *parser) parseRawYAMLValue(n *yaml.Node) RawYAMLValue {
 				vs = append(vs, v)
 			}
 		}
-		return &RawYAMLArray{vs, posAt(n)}
+		return &RawYAMLArray{vs, p.posAt(n)}
 	case yaml.MappingNode:
 		parsed := p.parseMapping("matrix row value", n, true, false)
 		m := make(map[string]RawYAMLValue, len(parsed))
@@ -801,7 +861,7 @@ func (p *parser) parseRawYAMLValue(n *yaml.Node) RawYAMLValue {
 				m[kv.id] = v
 			}
 		}
-		return &RawYAMLObject{m, posAt(n)}
+		return &RawYAMLObject{m, p.posAt(n)}
 	default:
 		p.errorf(n, "unexpected %s node on parsing value in matrix row", nodeKindName(n.Kind))
 		return nil
@@ -847,7 +907,7 @@ func (p *parser) parseMatrix(pos *Pos, n *yaml.Node) *Matrix {
 	if n.Kind == yaml.ScalarNode {
 		return &Matrix{
 			Expression: p.parseExpression(n, "matrix"),
-			Pos:        posAt(n),
+			Pos:        p.posAt(n),
 		}
 	}
 
@@ -862,6 +922,7 @@ func (p *parser) parseMatrix(pos *Pos, n *yaml.Node) *Matrix {
 		default:
 			if kv.val.Kind == yaml.ScalarNode {
 				ret.Rows[kv.id] = &MatrixRow{
+					Name:       kv.key,
 					Expression: p.parseExpression(kv.val, "array value for matrix variations"),
 				}
 				continue
@@ -971,7 +1032,7 @@ func (p *parser) parseContainer(sec string, pos *Pos, n *yaml.Node) *Container {
 }
 
 func (p *parser) parseServices(n *yaml.Node) *Services {
-	ret := &Services{Pos: posAt(n)}
+	ret := &Services{Pos: p.posAt(n)}
 	if e := p.mayParseExpression(n); e != nil {
 		ret.Expression = e
 	} else {
@@ -999,7 +1060,7 @@ func (p *parser) parseTimeoutMinutes(n *yaml.Node) *Float {
 
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_idsteps
 func (p *parser) parseStep(n *yaml.Node) *Step {
-	ret := &Step{Pos: posAt(n)}
+	ret := &Step{Pos: p.posAt(n)}
 	var workDir *String
 
 	for _, kv := range p.parseMapping("element of \"steps\" section", n, false, true) {
@@ -1028,6 +1089,9 @@ func (p *parser) parseStep(n *yaml.Node) *Step {
 			}
 			if kv.id == "uses" {
 				exec.Uses = p.parseString(kv.val, false)
+				if !exec.Uses.ContainsExpression() {
+					exec.Spec, _ = ParseActionSpec(exec.Uses.Value) // Invalid specification is reported by "action" rule
+				}
 			} else {
 				// kv.key == "with"
 				with := p.parseSectionMapping("with", kv.val, false, false)
@@ -1324,6 +1388,182 @@ func (p *parser) parseJobs(n *yaml.Node) map[string]*Job {
 	return ret
 }
 
+func isMergeKey(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && (n.Tag == "!!merge" || n.Tag == "" && n.Value == "<<" && n.Style == 0)
+}
+
+// Nodes copied by expanding aliases are limited so that nested aliases like "billion laughs" attack
+// don't exhaust memory. The limit is relative to the number of nodes in the YAML tree in the same way
+// as yaml.v3 limits the ratio of aliases on decoding into Go values. Note that yaml.v3 does not check
+// it on decoding into yaml.Node.
+const (
+	minAliasExpansion   = 10000
+	aliasExpansionRatio = 10
+)
+
+func countYAMLNodes(n *yaml.Node) int {
+	c := 1
+	for _, e := range n.Content {
+		c += countYAMLNodes(e)
+	}
+	return c
+}
+
+// copyNodeAt makes a deep copy of the given node. Positions of all the copied nodes are replaced with
+// the given position so that errors in the copied nodes are reported at the position of the alias.
+func (p *parser) copyNodeAt(n *yaml.Node, pos *yaml.Node) *yaml.Node {
+	p.expanded++
+	c := *n
+	c.Line = pos.Line
+	c.Column = pos.Column
+	c.Anchor = ""
+	c.HeadComment = ""
+	c.LineComment = ""
+	c.FootComment = ""
+	if len(n.Content) > 0 {
+		c.Content = make([]*yaml.Node, 0, len(n.Content))
+		for _, e := range n.Content {
+			c.Content = append(c.Content, p.copyNodeAt(e, pos))
+		}
+	}
+	return &c
+}
+
+// resolveAliases expands YAML aliases (*foo) and merge keys (<<: *foo) in the given node. The aliases
+// are replaced with the copies of their anchored nodes. The copied nodes have the position of the
+// alias usage. This function modifies the given node in place and returns the resolved node.
+func (p *parser) resolveAliases(n *yaml.Node) *yaml.Node {
+	if p.aliasBudget == 0 {
+		p.aliasBudget = aliasExpansionRatio * countYAMLNodes(n)
+		if p.aliasBudget < minAliasExpansion {
+			p.aliasBudget = minAliasExpansion
+		}
+	}
+
+	if n.Kind == yaml.AliasNode {
+		p.aliases = append(p.aliases, &String{n.Value, false, p.posAt(n)})
+		a := n.Alias
+		if a == nil {
+			p.errorf(n, "unknown anchor %q is referenced by alias", n.Value)
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+		}
+		if _, ok := p.expanding[a]; ok {
+			p.errorf(n, "alias %q recursively refers to its own anchor", n.Value)
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+		}
+		r := p.resolveAliases(a)
+		if p.expanded > p.aliasBudget {
+			// The error was already reported
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+		}
+		c := p.copyNodeAt(r, n)
+		if p.expanded > p.aliasBudget {
+			p.errorf(n, "too many nodes are expanded by aliases. expanding alias %q exceeds the limit of %d nodes. nested aliases are not expanded anymore", n.Value, p.aliasBudget)
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+		}
+		return c
+	}
+
+	if n.Anchor != "" {
+		if p.expanding == nil {
+			p.expanding = map[*yaml.Node]struct{}{}
+		}
+		p.expanding[n] = struct{}{}
+		defer delete(p.expanding, n)
+	}
+
+	for i, c := range n.Content {
+		n.Content[i] = p.resolveAliases(c)
+	}
+
+	if n.Kind == yaml.MappingNode {
+		p.resolveMergeKeys(n)
+	}
+
+	return n
+}
+
+// resolveMergeKeys merges mappings specified with merge keys (<<:) into the given mapping node. Keys
+// explicitly defined in the mapping take precedence over the merged keys. When a sequence of mappings
+// is merged, earlier mappings take precedence over later ones.
+// https://yaml.org/type/merge.html
+func (p *parser) resolveMergeKeys(n *yaml.Node) {
+	found := false
+	for i := 0; i < len(n.Content); i += 2 {
+		if isMergeKey(n.Content[i]) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	defined := map[string]struct{}{}
+	for i := 0; i < len(n.Content); i += 2 {
+		if k := n.Content[i]; !isMergeKey(k) {
+			defined[k.Value] = struct{}{}
+		}
+	}
+
+	merged := make([]*yaml.Node, 0, len(n.Content))
+	merge := func(m *yaml.Node) {
+		if m.Kind != yaml.MappingNode {
+			p.errorf(m, "value of merge key \"<<\" must be mapping or sequence of mappings but got %s node", nodeKindName(m.Kind))
+			return
+		}
+		for i := 0; i < len(m.Content); i += 2 {
+			k := m.Content[i]
+			if _, ok := defined[k.Value]; ok {
+				continue
+			}
+			defined[k.Value] = struct{}{}
+			merged = append(merged, k, m.Content[i+1])
+		}
+	}
+
+	for i := 0; i < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if !isMergeKey(k) {
+			merged = append(merged, k, v)
+			continue
+		}
+		if v.Kind == yaml.SequenceNode {
+			for _, m := range v.Content {
+				merge(m)
+			}
+		} else {
+			merge(v)
+		}
+	}
+
+	n.Content = merged
+}
+
+// checkDuplicateKeys checks duplicate keys in all mapping nodes in the given tree which were not
+// checked by parseMapping. For example, mappings in sections which caused some other errors or values
+// of unknown keys are not parsed by the parser. yaml.v3 silently accepts duplicate keys when decoding
+// into yaml.Node so they need to be checked here.
+func (p *parser) checkDuplicateKeys(n *yaml.Node) {
+	if _, ok := p.mappings[n]; !ok && n.Kind == yaml.MappingNode {
+		keys := make(map[string]*yaml.Node, len(n.Content)/2)
+		for i := 0; i < len(n.Content); i += 2 {
+			k := n.Content[i]
+			if k.Kind != yaml.ScalarNode {
+				continue
+			}
+			if prev, ok := keys[k.Value]; ok {
+				p.errorf(k, "key %q is duplicated in mapping. previously defined at %s", k.Value, p.posAt(prev).String())
+				continue
+			}
+			keys[k.Value] = k
+		}
+	}
+	for _, c := range n.Content {
+		p.checkDuplicateKeys(c)
+	}
+}
+
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions
 func (p *parser) parse(n *yaml.Node) *Workflow {
 	w := &Workflow{}
@@ -1373,6 +1613,8 @@ func (p *parser) parse(n *yaml.Node) *Workflow {
 		}
 	}
 
+	p.checkDuplicateKeys(n)
+
 	if w.On == nil {
 		p.error(n, "\"on\" section is missing in workflow")
 	}
@@ -1390,6 +1632,25 @@ func (p *parser) parse(n *yaml.Node) *Workflow {
 // 	}
 // }
 
+// recoverYAMLPanic converts a panic while decoding YAML into an error. go-yaml may panic on some
+// malformed inputs. Applications embedding actionlint should not crash on such inputs. This function
+// must be called with defer.
+func recoverYAMLPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("could not parse as YAML: %v", r)
+	}
+}
+
+func decodeYAML(d *yaml.Decoder, n *yaml.Node) (err error) {
+	defer recoverYAMLPanic(&err)
+	return d.Decode(n)
+}
+
+func unmarshalYAML(b []byte, v interface{}) (err error) {
+	defer recoverYAMLPanic(&err)
+	return yaml.Unmarshal(b, v)
+}
+
 func handleYAMLError(err error) []*Error {
 	re := regexp.MustCompile(`\bline (\d+):`)
 
@@ -1398,8 +1659,7 @@ func handleYAMLError(err error) []*Error {
 		if ss := re.FindStringSubmatch(msg); len(ss) > 1 {
 			l, _ = strconv.Atoi(ss[1])
 		}
-		msg = fmt.Sprintf("could not parse as YAML: %s", msg)
-		return &Error{msg, "", l, 0, "syntax-check"}
+		return errorfAt(&Pos{Line: l}, "syntax-check", "could not parse as YAML: %s", msg)
 	}
 
 	if te, ok := err.(*yaml.TypeError); ok {
@@ -1413,21 +1673,280 @@ func handleYAMLError(err error) []*Error {
 	return []*Error{yamlErr(err.Error())}
 }
 
+// ParseDocuments parses given source which may contain multiple YAML documents separated with `---`
+// into workflow syntax trees. Each document is parsed as one workflow. This is useful for checking
+// workflow files generated by some templating tools. Errors detected while parsing all the documents
+// are returned.
+func ParseDocuments(b []byte) ([]*Workflow, []*Error) {
+	f := NewSourceFile("", b) // Lines in all documents are counted from the beginning of the source
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	ws := []*Workflow{}
+	errs := []*Error{}
+	for {
+		var n yaml.Node
+		if err := decodeYAML(d, &n); err != nil {
+			if err == io.EOF {
+				break
+			}
+			errs = append(errs, handleYAMLError(err)...)
+			break
+		}
+		p := &parser{src: f}
+		w := p.parse(p.resolveAliases(&n))
+		w.Aliases = p.aliases
+		ws = append(ws, w)
+		errs = append(errs, p.errors...)
+	}
+	if len(ws) == 0 && len(errs) == 0 {
+		// Empty source. Report the same error as Parse
+		w, es := Parse(b)
+		return []*Workflow{w}, es
+	}
+	return ws, errs
+}
+
 // Parse parses given source as byte sequence into workflow syntax tree. It returns all errors
 // detected while parsing the input. It means that detecting one error does not stop parsing. Even
 // if one or more errors are detected, parser will try to continue parsing and finding more errors.
 func Parse(b []byte) (*Workflow, []*Error) {
 	var n yaml.Node
 
-	if err := yaml.Unmarshal(b, &n); err != nil {
-		return nil, handleYAMLError(err)
+	if err := unmarshalYAML(b, &n); err != nil {
+		return parseBrokenJobs(b, err)
 	}
 
+	return parseNode(&n, NewSourceFile("", b))
+}
+
+// ParseSourceFile parses the source file into workflow syntax tree as Parse does. The YAML node tree
+// retained by the source file is reused so that the source is not parsed as YAML again by rules
+// checking the raw YAML.
+func ParseSourceFile(f *SourceFile) (*Workflow, []*Error) {
+	n, err := f.Node()
+	if err != nil {
+		return parseBrokenJobs(f.Content, err)
+	}
+	if hasYAMLAliases(n) {
+		// Resolving aliases modifies the tree in place. Parse another tree not to break the shared one
+		n = &yaml.Node{}
+		if err := unmarshalYAML(f.Content, n); err != nil {
+			return parseBrokenJobs(f.Content, err)
+		}
+	}
+	return parseNode(n, f)
+}
+
+func parseNode(n *yaml.Node, src *SourceFile) (*Workflow, []*Error) {
 	// Uncomment for checking YAML tree
-	// dumpYAML(&n, 0)
+	// dumpYAML(n, 0)
 
-	p := &parser{}
-	w := p.parse(&n)
+	p := &parser{src: src}
+	w := p.parse(p.resolveAliases(n))
+	w.Aliases = p.aliases
 
 	return w, p.errors
 }
+
+// parseCompositeAction parses "steps" in "runs" section of the composite action metadata file and
+// converts the action into a workflow which has one job running the steps so that rules for
+// workflows can check the steps. Inputs of the action are converted into string inputs of
+// "workflow_call" event to type `inputs` context. It returns nil when the metadata has no steps.
+// Other sections of the metadata are not checked since the "action" rule checks them.
+func parseCompositeAction(b []byte) (*Workflow, []*Error) {
+	var n yaml.Node
+	if err := unmarshalYAML(b, &n); err != nil {
+		return nil, nil // The "action" rule reports the error while reading the metadata
+	}
+
+	p := &parser{src: NewSourceFile("", b)}
+	root := p.resolveAliases(&n)
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	call := &WorkflowCallEvent{Pos: p.posAt(root.Content[0])}
+	job := &Job{ID: &String{Value: "composite", Pos: p.posAt(root.Content[0])}, Pos: p.posAt(root.Content[0])}
+	m := root.Content[0].Content
+	for i := 0; i+1 < len(m); i += 2 {
+		k, v := m[i], m[i+1]
+		switch k.Value {
+		case "inputs":
+			if v.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				name := p.newString(v.Content[j])
+				call.Inputs = append(call.Inputs, &WorkflowCallEventInput{
+					Name: name,
+					Type: WorkflowCallEventInputTypeString, // Inputs of actions are always strings
+					ID:   strings.ToLower(name.Value),
+				})
+			}
+		case "runs":
+			if v.Kind != yaml.MappingNode {
+				continue
+			}
+			job.Pos = p.posAt(k)
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				if v.Content[j].Value == "steps" {
+					job.Steps = p.parseSteps(v.Content[j+1])
+				}
+			}
+		}
+	}
+
+	if len(job.Steps) == 0 {
+		return nil, nil
+	}
+
+	w := &Workflow{
+		On:      []Event{call},
+		Jobs:    map[string]*Job{"composite": job},
+		Aliases: p.aliases,
+	}
+	return w, p.errors
+}
+
+// yamlJobRange is a range of lines of a job in "jobs:" section.
+type yamlJobRange struct {
+	id    string
+	pos   *Pos
+	start int // 0-based index of the line of the job ID
+	end   int // 0-based index of the line next to the last line of the job
+}
+
+// findYAMLJobRanges finds ranges of jobs in "jobs:" section by indentation of the lines. It returns
+// nil when "jobs:" section is not written in block style.
+func findYAMLJobRanges(lines []string) []*yamlJobRange {
+	start := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "jobs:") {
+			if r := strings.TrimSpace(l[len("jobs:"):]); r == "" || strings.HasPrefix(r, "#") {
+				start = i + 1
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	ret := []*yamlJobRange{}
+	indent := -1
+	end := len(lines)
+	for i := start; i < len(lines); i++ {
+		l := lines[i]
+		t := strings.TrimLeft(l, " ")
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		d := len(l) - len(t)
+		if d == 0 {
+			end = i // Next top-level key
+			break
+		}
+		if indent < 0 {
+			indent = d
+		}
+		if d != indent || strings.HasPrefix(t, "-") {
+			continue
+		}
+		if len(ret) > 0 {
+			ret[len(ret)-1].end = i
+		}
+		id := t
+		if c := strings.IndexByte(t, ':'); c >= 0 {
+			id = t[:c]
+		}
+		id = strings.Trim(strings.TrimSpace(id), `"'`)
+		ret = append(ret, &yamlJobRange{id, &Pos{Line: i + 1, Col: d + 1}, i, len(lines)})
+	}
+	if len(ret) > 0 {
+		ret[len(ret)-1].end = end
+	}
+	return ret
+}
+
+// parseBrokenJobs tries to recover from the YAML syntax error by removing jobs which contain syntax
+// errors from the source. When the rest of the source can be parsed, it returns the workflow where
+// the broken jobs are replaced with placeholders whose Broken fields are true. Rules can still check
+// the intact jobs. This is useful for editors where a workflow file is often broken while editing.
+// When it cannot recover from the error, it returns nil and the syntax error.
+func parseBrokenJobs(b []byte, err error) (*Workflow, []*Error) {
+	errs := handleYAMLError(err)
+	lines := splitLines(string(b))
+	jobs := findYAMLJobRanges(lines)
+	if len(jobs) < 2 {
+		return nil, errs
+	}
+
+	removed := make([]bool, len(jobs))
+	remove := func(i int) string {
+		ls := make([]string, len(lines))
+		copy(ls, lines)
+		for j, r := range removed {
+			if r || j == i {
+				for k := jobs[j].start; k < jobs[j].end; k++ {
+					ls[k] = "" // Keep line numbers of other jobs
+				}
+			}
+		}
+		return strings.Join(ls, "\n")
+	}
+
+	found := errs
+	for c := 1; c < len(jobs); c++ { // At least one job must be intact
+		// Find the job which contains the error
+		broken := -1
+		line := found[0].Line - 1
+		for i, j := range jobs {
+			if !removed[i] && j.start <= line && line < j.end {
+				broken = i
+				break
+			}
+		}
+		if broken < 0 {
+			// Some errors are reported at the line of the parent node. Find the job whose removal
+			// fixes the error
+			for i := range jobs {
+				var n yaml.Node
+				if !removed[i] && unmarshalYAML([]byte(remove(i)), &n) == nil {
+					broken = i
+					break
+				}
+			}
+			if broken >= 0 {
+				for _, e := range found {
+					e.Line, e.Column = jobs[broken].pos.Line, jobs[broken].pos.Col // Report at the broken job
+				}
+			}
+		}
+		if broken < 0 {
+			return nil, errs[:1]
+		}
+		removed[broken] = true
+
+		var n yaml.Node
+		if err := unmarshalYAML([]byte(remove(-1)), &n); err != nil {
+			found = handleYAMLError(err)
+			errs = append(errs, found...)
+			continue
+		}
+
+		p := &parser{}
+		w := p.parse(p.resolveAliases(&n))
+		w.Aliases = p.aliases
+		if w.Jobs == nil {
+			w.Jobs = map[string]*Job{}
+		}
+		for i, j := range jobs {
+			if removed[i] {
+				id := &String{Value: j.id, Pos: j.pos}
+				w.Jobs[strings.ToLower(j.id)] = &Job{ID: id, Pos: j.pos, Broken: true}
+			}
+		}
+		return w, append(errs, p.errors...)
+	}
+
+	return nil, errs[:1]
+}
@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
@@ -22,8 +23,12 @@ type Pass interface {
 
 // Visitor visits syntax tree from root in depth-first order
 type Visitor struct {
-	passes []Pass
-	dbg    io.Writer
+	passes   []Pass
+	dbg      io.Writer
+	onPanic  func(p Pass, pos *Pos, v interface{})
+	panicked []bool
+	deadline time.Time
+	jobs     func(n *Job) bool
 }
 
 // NewVisitor creates Visitor instance
@@ -42,6 +47,48 @@ func (v *Visitor) EnableDebug(w io.Writer) {
 	v.dbg = w
 }
 
+// OnPanic sets a callback called when some pass panics while visiting a syntax tree. The panic is
+// recovered and the pass is not called anymore on the tree so that other passes can continue. The
+// callback receives the pass, the position of the node being visited, and the value passed to
+// panic(). When no callback is set, panics are not recovered.
+func (v *Visitor) OnPanic(f func(p Pass, pos *Pos, v interface{})) {
+	v.onPanic = f
+}
+
+// SetDeadline sets the deadline of visiting a syntax tree. When the deadline is exceeded, Visit stops
+// visiting the rest of the tree and returns context.DeadlineExceeded. The deadline is checked before
+// visiting each job and step. Zero time means no deadline.
+func (v *Visitor) SetDeadline(t time.Time) {
+	v.deadline = t
+}
+
+// SetJobFilter sets a predicate to select jobs to visit. Jobs for which the predicate returns false
+// are skipped with their steps. Note that passes can still access the skipped jobs through the
+// Workflow node. nil means all jobs are visited.
+func (v *Visitor) SetJobFilter(f func(n *Job) bool) {
+	v.jobs = f
+}
+
+func (v *Visitor) expired() bool {
+	return !v.deadline.IsZero() && time.Now().After(v.deadline)
+}
+
+func (v *Visitor) call(i int, pos *Pos, f func(p Pass) error) (err error) {
+	if v.onPanic == nil {
+		return f(v.passes[i])
+	}
+	if v.panicked[i] {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			v.panicked[i] = true
+			v.onPanic(v.passes[i], pos, r)
+		}
+	}()
+	return f(v.passes[i])
+}
+
 func (v *Visitor) reportElapsedTime(what string, start time.Time) {
 	fmt.Fprintf(v.dbg, "[Visitor] %s took %vms\n", what, time.Since(start).Milliseconds())
 }
@@ -52,9 +99,13 @@ func (v *Visitor) Visit(n *Workflow) error {
 	if v.dbg != nil {
 		t = time.Now()
 	}
+	if v.onPanic != nil {
+		v.panicked = make([]bool, len(v.passes))
+	}
 
-	for _, p := range v.passes {
-		if err := p.VisitWorkflowPre(n); err != nil {
+	pos := &Pos{Line: 1, Col: 1}
+	for i := range v.passes {
+		if err := v.call(i, pos, func(p Pass) error { return p.VisitWorkflowPre(n) }); err != nil {
 			return err
 		}
 	}
@@ -65,6 +116,12 @@ func (v *Visitor) Visit(n *Workflow) error {
 	}
 
 	for _, j := range n.Jobs {
+		if v.expired() {
+			return context.DeadlineExceeded
+		}
+		if v.jobs != nil && !v.jobs(j) {
+			continue
+		}
 		if err := v.visitJob(j); err != nil {
 			return err
 		}
@@ -75,8 +132,12 @@ func (v *Visitor) Visit(n *Workflow) error {
 		t = time.Now()
 	}
 
-	for _, p := range v.passes {
-		if err := p.VisitWorkflowPost(n); err != nil {
+	if v.expired() {
+		return context.DeadlineExceeded
+	}
+
+	for i := range v.passes {
+		if err := v.call(i, pos, func(p Pass) error { return p.VisitWorkflowPost(n) }); err != nil {
 			return err
 		}
 	}
@@ -94,8 +155,8 @@ func (v *Visitor) visitJob(n *Job) error {
 		t = time.Now()
 	}
 
-	for _, p := range v.passes {
-		if err := p.VisitJobPre(n); err != nil {
+	for i := range v.passes {
+		if err := v.call(i, n.Pos, func(p Pass) error { return p.VisitJobPre(n) }); err != nil {
 			return err
 		}
 	}
@@ -106,6 +167,9 @@ func (v *Visitor) visitJob(n *Job) error {
 	}
 
 	for _, s := range n.Steps {
+		if v.expired() {
+			return context.DeadlineExceeded
+		}
 		if err := v.visitStep(s); err != nil {
 			return err
 		}
@@ -116,8 +180,8 @@ func (v *Visitor) visitJob(n *Job) error {
 		t = time.Now()
 	}
 
-	for _, p := range v.passes {
-		if err := p.VisitJobPost(n); err != nil {
+	for i := range v.passes {
+		if err := v.call(i, n.Pos, func(p Pass) error { return p.VisitJobPost(n) }); err != nil {
 			return err
 		}
 	}
@@ -135,8 +199,8 @@ func (v *Visitor) visitStep(n *Step) error {
 		t = time.Now()
 	}
 
-	for _, p := range v.passes {
-		if err := p.VisitStep(n); err != nil {
+	for i := range v.passes {
+		if err := v.call(i, n.Pos, func(p Pass) error { return p.VisitStep(n) }); err != nil {
 			return err
 		}
 	}
@@ -1,11 +1,13 @@
 package actionlint
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/mattn/go-shellwords"
 	"golang.org/x/sync/errgroup"
@@ -13,49 +15,88 @@ import (
 	"golang.org/x/sys/execabs"
 )
 
+// processTimeoutError is an error returned when the process did not finish within the timeout. The
+// process was killed.
+type processTimeoutError struct {
+	cmd     string
+	timeout time.Duration
+}
+
+func (e *processTimeoutError) Error() string {
+	return fmt.Sprintf("%s did not finish within %s and was killed", e.cmd, e.timeout)
+}
+
 // cmdExecution represents a single command line execution.
 type cmdExecution struct {
 	cmd           string
 	args          []string
 	stdin         string
 	combineOutput bool
+	timeout       time.Duration
 }
 
-func (e *cmdExecution) run() ([]byte, error) {
-	cmd := exec.Command(e.cmd, e.args...)
-	cmd.Stderr = nil
-
-	p, err := cmd.StdinPipe()
-	if err != nil {
-		return nil, fmt.Errorf("could not make stdin pipe for %s process: %w", e.cmd, err)
+// run runs the command and returns its stdout. When the ctx is cancelled or the timeout is exceeded,
+// the process is killed with its child processes. Killing the child processes is important since
+// some commands like shellcheck wrapped by shell scripts spawn other processes and they would be
+// orphaned otherwise.
+func (e *cmdExecution) run(parent context.Context) ([]byte, error) {
+	if err := parent.Err(); err != nil {
+		return nil, fmt.Errorf("%s was not run: %w", e.cmd, err)
 	}
-	if _, err := io.WriteString(p, e.stdin); err != nil {
-		p.Close()
-		return nil, fmt.Errorf("could not write to stdin of %s process: %w", e.cmd, err)
+
+	ctx := parent
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
 	}
-	p.Close()
+	cmd := exec.Command(e.cmd, e.args...)
+	cmd.Stdin = strings.NewReader(e.stdin)
+	setProcessGroup(cmd)
 
-	var stdout []byte
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	if e.combineOutput {
-		stdout, err = cmd.CombinedOutput()
+		cmd.Stderr = &stdout
 	} else {
-		stdout, err = cmd.Output()
+		cmd.Stderr = &stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
 
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd.Process)
+		case <-done:
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+
 	if err != nil {
+		if err := parent.Err(); err != nil {
+			return nil, fmt.Errorf("%s was killed: %w", e.cmd, err)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &processTimeoutError{e.cmd, e.timeout}
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			code := exitErr.ExitCode()
 
-			stderr := exitErr.Stderr
+			stderr := stderr.Bytes()
 			if e.combineOutput {
-				stderr = stdout
+				stderr = stdout.Bytes()
 			}
 
 			if code < 0 {
 				return nil, fmt.Errorf("%s was terminated. stderr: %q", e.cmd, stderr)
 			}
 
-			if len(stdout) == 0 {
+			if stdout.Len() == 0 {
 				return nil, fmt.Errorf("%s exited with status %d but stdout was empty. stderr: %q", e.cmd, code, stderr)
 			}
 
@@ -65,7 +106,7 @@ func (e *cmdExecution) run() ([]byte, error) {
 		}
 	}
 
-	return stdout, nil
+	return stdout.Bytes(), nil
 }
 
 // concurrentProcess is a manager to run process concurrently. Since running process consumes OS
@@ -74,9 +115,13 @@ func (e *cmdExecution) run() ([]byte, error) {
 // cause the error "pipe: too many files to open". To avoid it, this type manages how many processes
 // are run at once.
 type concurrentProcess struct {
+	// ctx is the context to cancel the running processes. When it is cancelled, the running
+	// processes are killed and no more process is started.
 	ctx  context.Context
 	sema *semaphore.Weighted
 	wg   sync.WaitGroup
+	// timeout is the timeout of each process. Zero means no timeout.
+	timeout time.Duration
 }
 
 // newConcurrentProcess creates a new ConcurrentProcess instance. The `par` argument represents how
@@ -96,7 +141,7 @@ func (proc *concurrentProcess) run(eg *errgroup.Group, exec *cmdExecution, callb
 		if err := proc.sema.Acquire(proc.ctx, 1); err != nil {
 			return fmt.Errorf("could not acquire semaphore to run %q: %w", exec.cmd, err)
 		}
-		stdout, err := exec.run()
+		stdout, err := exec.run(proc.ctx)
 		proc.sema.Release(1)
 		return callback(stdout, err)
 	})
@@ -162,7 +207,7 @@ func (cmd *externalCommand) run(args []string, stdin string, callback func([]byt
 		allArgs = append(allArgs, args...)
 		args = allArgs
 	}
-	exec := &cmdExecution{cmd.exe, args, stdin, cmd.combineOutput}
+	exec := &cmdExecution{cmd.exe, args, stdin, cmd.combineOutput, cmd.proc.timeout}
 	cmd.proc.run(&cmd.eg, exec, callback)
 }
 
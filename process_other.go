@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package actionlint
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on this platform since process groups are not available.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the process. Note that its child processes are not killed on this platform.
+func killProcessGroup(p *os.Process) {
+	p.Kill()
+}
@@ -1,6 +1,8 @@
 package actionlint
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
@@ -343,3 +345,77 @@ func TestProcessCommandExitStatusNonZero(t *testing.T) {
 		t.Fatalf("Unexpected error happened: %q", msg)
 	}
 }
+
+func TestProcessTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this test is flaky on Windows")
+	}
+
+	p := newConcurrentProcess(1)
+	p.timeout = 100 * time.Millisecond
+	sleep := testSkipIfNoCommand(t, p, "sleep")
+
+	start := time.Now()
+	var timeout *processTimeoutError
+	sleep.run([]string{"10"}, "", func(b []byte, err error) error {
+		if !errors.As(err, &timeout) {
+			t.Errorf("timeout error was expected but got %v", err)
+		}
+		return nil
+	})
+	if err := sleep.wait(); err != nil {
+		t.Fatal(err)
+	}
+	p.wait()
+
+	if sec := time.Since(start).Seconds(); sec >= 5 {
+		t.Fatalf("process was not killed on timeout. it took %v seconds", sec)
+	}
+	if timeout != nil && !strings.Contains(timeout.Error(), "did not finish within 100ms and was killed") {
+		t.Fatalf("unexpected error message: %q", timeout.Error())
+	}
+}
+
+func TestProcessCancelKillsChildProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("child processes are not killed on Windows")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := newConcurrentProcess(1)
+	p.ctx = ctx
+	// The background sleep process keeps stdout open so the command does not finish until the child
+	// process is also killed
+	sh := testSkipIfNoCommand(t, p, "sh")
+
+	start := time.Now()
+	var cmdErr error
+	sh.run([]string{"-c", "sleep 10 & sleep 10"}, "", func(b []byte, err error) error {
+		cmdErr = err
+		return nil
+	})
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := sh.wait(); err != nil {
+		t.Fatal(err)
+	}
+	p.wait()
+
+	if sec := time.Since(start).Seconds(); sec >= 5 {
+		t.Fatalf("processes were not killed on cancellation. it took %v seconds", sec)
+	}
+	if !errors.Is(cmdErr, context.Canceled) {
+		t.Fatalf("cancellation error was expected but got %v", cmdErr)
+	}
+
+	// No process is started after the cancellation
+	sh.run([]string{"-c", "exit 0"}, "", func(b []byte, err error) error {
+		cmdErr = err
+		return nil
+	})
+	sh.wait()
+	p.wait()
+	if !errors.Is(cmdErr, context.Canceled) {
+		t.Fatalf("cancellation error was expected but got %v", cmdErr)
+	}
+}
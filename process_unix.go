@@ -0,0 +1,24 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package actionlint
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessGroup makes the process the leader of a new process group so that the process and its
+// child processes can be killed at once with killProcessGroup.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group led by the process started with setProcessGroup.
+func killProcessGroup(p *os.Process) {
+	if err := unix.Kill(-p.Pid, unix.SIGKILL); err != nil {
+		p.Kill() // Fall back to killing only the process
+	}
+}
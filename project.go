@@ -3,7 +3,9 @@ package actionlint
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // Project represents one GitHub project. One Git repository corresponds to one project.
@@ -22,17 +24,28 @@ func absPath(path string) string {
 // findProject creates new Project instance by finding a project which the given path belongs to.
 // A project must be a Git repository and have ".github/workflows" directory.
 func findProject(path string) (*Project, error) {
+	d := findProjectRoot(path)
+	if d == "" {
+		return nil, nil
+	}
+	return NewProject(d)
+}
+
+// findProjectRoot finds the root directory of the project which the path belongs to. The root
+// directory contains both .github/workflows and .git. When it is not found, this function returns
+// an empty string.
+func findProjectRoot(path string) string {
 	d := absPath(path)
 	for {
 		if s, err := os.Stat(filepath.Join(d, ".github", "workflows")); err == nil && s.IsDir() {
 			if _, err := os.Stat(filepath.Join(d, ".git")); err == nil { // Note: .git may be a file
-				return NewProject(d)
+				return d
 			}
 		}
 
 		p := filepath.Dir(d)
 		if p == d {
-			return nil, nil
+			return ""
 		}
 		d = p
 	}
@@ -62,8 +75,26 @@ func (p *Project) WorkflowsDir() string {
 // Knows returns true when the project knows the given file. When a file is included in the
 // project's directory, the project knows the file.
 func (p *Project) Knows(path string) bool {
-	// TODO: strings.HasPrefix is not perfect to check file path
-	return strings.HasPrefix(absPath(path), p.root)
+	a := absPath(path)
+	r := absPath(p.root) // The root may be relative when the instance was created with NewProject
+	if samePath(a, r) {
+		return true
+	}
+	// Check the separator not to confuse sibling directories like "repo" and "repo-ui"
+	if !strings.HasSuffix(r, string(filepath.Separator)) {
+		r += string(filepath.Separator)
+	}
+	return len(a) >= len(r) && samePath(a[:len(r)], r)
+}
+
+// samePath returns true when the two file paths are the same. File paths are case-insensitive on
+// Windows. For example, editors on Windows may send a path with a lower-case drive letter like
+// "c:\path\to\repo" while the path returned from filepath.Abs starts with "C:".
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
 }
 
 // Config returns config object of the GitHub project repository. The config file was read from
@@ -75,8 +106,9 @@ func (p *Project) Config() *Config {
 }
 
 // Projects represents set of projects. It caches Project instances which was created previously
-// and reuses them.
+// and reuses them. This struct is safe to be used from multiple goroutines.
 type Projects struct {
+	mu    sync.Mutex
 	known []*Project
 }
 
@@ -88,6 +120,9 @@ func NewProjects() *Projects {
 // At returns the Project instance which the path belongs to. It returns nil if no project is found
 // from the path.
 func (ps *Projects) At(path string) (*Project, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
 	for _, p := range ps.known {
 		if p.Knows(path) {
 			return p, nil
@@ -149,3 +149,44 @@ func TestProjectsLoadingBrokenProjectConfig(t *testing.T) {
 		t.Fatalf("wanted error %q but have error %q", want, msg)
 	}
 }
+
+func TestProjectsDistinguishSiblingDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, n := range []string{"repo", "repo-ui"} {
+		d := filepath.Join(root, n)
+		if err := os.MkdirAll(filepath.Join(d, ".github", "workflows"), 0750); err != nil {
+			panic(err)
+		}
+		testEnsureDotGitDir(d)
+	}
+
+	ps := NewProjects()
+	for _, n := range []string{"repo", "repo-ui"} {
+		want := filepath.Join(root, n)
+		p, err := ps.At(filepath.Join(want, ".github", "workflows", "ci.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || p.RootDir() != want {
+			t.Fatalf("project of %q should be at %q but got %v", n, want, p)
+		}
+	}
+}
+
+func TestProjectKnowsPathWithRelativeRoot(t *testing.T) {
+	p, err := NewProject(filepath.Join("testdata", "find_project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join("testdata", "find_project", ".github", "workflows", "test.yaml"),
+		absPath(filepath.Join("testdata", "find_project", "README.md")),
+	} {
+		if !p.Knows(path) {
+			t.Errorf("project at %q should know %q", p.RootDir(), path)
+		}
+	}
+	if p.Knows(filepath.Join("testdata", "find_project_ui")) {
+		t.Errorf("project at %q should not know sibling directory", p.RootDir())
+	}
+}
@@ -0,0 +1,56 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectsKnowsPathWithDifferentCase(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".github", "workflows"), 0750); err != nil {
+		panic(err)
+	}
+	testEnsureDotGitDir(root)
+
+	ps := NewProjects()
+	p, err := ps.At(filepath.Join(root, ".github", "workflows", "ci.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil {
+		t.Fatal("project was not found")
+	}
+
+	// Editors may send a path with a lower-case drive letter like "c:\path\to\file"
+	for _, path := range []string{
+		strings.ToLower(root),
+		strings.ToUpper(root),
+		strings.ToLower(filepath.Join(root, ".github", "workflows", "ci.yaml")),
+	} {
+		if !p.Knows(path) {
+			t.Errorf("project at %q should know %q", root, path)
+		}
+	}
+	if p.Knows(strings.ToLower(root) + "-ui") {
+		t.Errorf("project at %q should not know sibling directory", root)
+	}
+}
+
+func TestErrorTemplateFieldsFilepathSlash(t *testing.T) {
+	err := &Error{Message: "msg", Filepath: `.github\workflows\ci.yaml`, Line: 1, Column: 1, Kind: "syntax-check"}
+	if f := err.GetTemplateFields(nil); f.Filepath != ".github/workflows/ci.yaml" {
+		t.Fatalf("path separator should be \"/\" but got %q", f.Filepath)
+	}
+}
+
+func TestLinterPathConfigsBackslash(t *testing.T) {
+	cfg, err := ParseConfig([]byte("paths:\n  .github/workflows/**/*.yaml:\n    ignore: [foo]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs := cfg.PathConfigs(`.github\workflows\sub\ci.yaml`); len(cs) != 1 {
+		t.Fatalf("path with backslashes should match the glob pattern but got %v", cs)
+	}
+}
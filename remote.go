@@ -0,0 +1,531 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+// maxGitHubResponseSize is the maximum size of a response body of GitHub API. The largest response
+// is an archive of repository downloaded by Tarball method.
+const maxGitHubResponseSize = 100 << 20
+
+// GitHubClient is a small client of GitHub REST API used for checking repository-specific references.
+// Responses are cached so that the same endpoint is requested only once while linting many workflow
+// files. This struct is safe to be used from multiple goroutines.
+type GitHubClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	mu      sync.Mutex
+	cache   map[string]*githubResponse
+}
+
+type githubResponse struct {
+	once   sync.Once
+	status int
+	body   []byte
+	err    error
+}
+
+// NewGitHubClient creates a new GitHubClient instance. The token is sent as bearer token of API
+// requests. When it is empty, API requests are sent without authentication.
+func NewGitHubClient(token string) *GitHubClient {
+	return NewGitHubClientWithBaseURL(githubAPIURL, token)
+}
+
+// NewGitHubClientWithBaseURL creates a new GitHubClient instance which sends API requests to the
+// base URL like "https://github.example.com/api/v3" instead of https://api.github.com. It is useful
+// for GitHub Enterprise Server or a fake server in tests.
+func NewGitHubClientWithBaseURL(baseURL, token string) *GitHubClient {
+	return &GitHubClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		cache:   map[string]*githubResponse{},
+	}
+}
+
+func (c *GitHubClient) get(path string) (int, []byte, error) {
+	c.mu.Lock()
+	r, ok := c.cache[path]
+	if !ok {
+		r = &githubResponse{}
+		c.cache[path] = r
+	}
+	c.mu.Unlock()
+
+	r.once.Do(func() {
+		r.status, r.body, r.err = c.request(http.MethodGet, path, nil)
+	})
+	return r.status, r.body, r.err
+}
+
+// request sends the request to the path. The body is encoded into JSON when it is not nil. Unlike
+// get method, the response is not cached.
+func (c *GitHubClient) request(method, path string, body interface{}) (int, []byte, error) {
+	u := c.baseURL + path
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("could not encode request body to %s: %w", u, err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not create request to %s: %w", u, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not send request to %s: %w", u, err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxGitHubResponseSize+1))
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not read response from %s: %w", u, err)
+	}
+	if len(b) > maxGitHubResponseSize {
+		return 0, nil, fmt.Errorf("response from %s is larger than %d bytes", u, maxGitHubResponseSize)
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusForbidden, http.StatusNotFound:
+		return res.StatusCode, b, nil
+	case http.StatusUnauthorized:
+		return 0, nil, fmt.Errorf("request to %s was not authorized. check the token to access GitHub API is valid", u)
+	default:
+		return 0, nil, fmt.Errorf("request to %s failed with status %d: %s", u, res.StatusCode, strings.TrimSpace(string(b)))
+	}
+}
+
+// names fetches the paginated list of objects at the path and collects their "name" fields. The key
+// is a key of the list in the response object. When the key is empty, the response is a list. The
+// second return value is false when the resource is not accessible (e.g. permission is not
+// sufficient).
+func (c *GitHubClient) names(path, key string) (map[string]struct{}, bool, error) {
+	ret := map[string]struct{}{}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	for page := 1; ; page++ {
+		status, body, err := c.get(fmt.Sprintf("%s%sper_page=100&page=%d", path, sep, page))
+		if err != nil {
+			return nil, false, err
+		}
+		if status != http.StatusOK {
+			return nil, false, nil
+		}
+
+		var items []struct {
+			Name string `json:"name"`
+		}
+		if key == "" {
+			err = json.Unmarshal(body, &items)
+		} else {
+			var o map[string]json.RawMessage
+			err = json.Unmarshal(body, &o)
+			if err == nil && o[key] != nil {
+				err = json.Unmarshal(o[key], &items)
+			}
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("could not parse response from %s: %w", path, err)
+		}
+
+		for _, i := range items {
+			ret[i.Name] = struct{}{}
+		}
+		if len(items) < 100 {
+			return ret, true, nil
+		}
+	}
+}
+
+// runnerLabels fetches labels of all self-hosted runners at the path.
+func (c *GitHubClient) runnerLabels(path string) (map[string]struct{}, bool, error) {
+	ret := map[string]struct{}{}
+	for page := 1; ; page++ {
+		status, body, err := c.get(fmt.Sprintf("%s?per_page=100&page=%d", path, page))
+		if err != nil {
+			return nil, false, err
+		}
+		if status != http.StatusOK {
+			return nil, false, nil
+		}
+
+		var res struct {
+			Runners []struct {
+				Labels []struct {
+					Name string `json:"name"`
+				} `json:"labels"`
+			} `json:"runners"`
+		}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil, false, fmt.Errorf("could not parse response from %s: %w", path, err)
+		}
+
+		for _, r := range res.Runners {
+			for _, l := range r.Labels {
+				ret[strings.ToLower(l.Name)] = struct{}{}
+			}
+		}
+		if len(res.Runners) < 100 {
+			return ret, true, nil
+		}
+	}
+}
+
+// Content fetches the file at the path in the repository at the ref. The repo parameter is
+// "owner/repo" form. The second return value is false when the file does not exist.
+func (c *GitHubClient) Content(repo, path, ref string) ([]byte, bool, error) {
+	p := contentsPath(repo, path, ref)
+	status, body, err := c.get(p)
+	if err != nil {
+		return nil, false, err
+	}
+	// Directory content is returned as an array
+	if status != http.StatusOK || bytes.HasPrefix(bytes.TrimSpace(body), []byte{'['}) {
+		return nil, false, nil
+	}
+
+	var res struct {
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, false, fmt.Errorf("could not parse response from %s: %w", p, err)
+	}
+	if res.Type != "file" {
+		return nil, false, nil
+	}
+	if res.Encoding != "base64" {
+		return []byte(res.Content), true, nil
+	}
+	// Content is encoded in base64 with line breaks
+	b, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(res.Content, "\n", ""))
+	if err != nil {
+		return nil, false, fmt.Errorf("could not decode content of %s: %w", p, err)
+	}
+	return b, true, nil
+}
+
+// Directory fetches paths of files in the directory of the repository at the ref. The repo parameter
+// is "owner/repo" form. Files in subdirectories are not included. The returned paths are relative to
+// the repository root and sorted. The second return value is false when the directory does not
+// exist.
+func (c *GitHubClient) Directory(repo, path, ref string) ([]string, bool, error) {
+	p := contentsPath(repo, path, ref)
+	status, body, err := c.get(p)
+	if err != nil {
+		return nil, false, err
+	}
+	// File content is returned as an object
+	if status != http.StatusOK || !bytes.HasPrefix(bytes.TrimSpace(body), []byte{'['}) {
+		return nil, false, nil
+	}
+
+	var items []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, false, fmt.Errorf("could not parse response from %s: %w", p, err)
+	}
+	ret := []string{}
+	for _, i := range items {
+		if i.Type == "file" {
+			ret = append(ret, i.Path)
+		}
+	}
+	sort.Strings(ret)
+	return ret, true, nil
+}
+
+func contentsPath(repo, path, ref string) string {
+	p := fmt.Sprintf("/repos/%s/contents/%s", repo, strings.TrimPrefix(path, "/"))
+	if ref != "" {
+		p += "?ref=" + url.QueryEscape(ref)
+	}
+	return p
+}
+
+// Repositories fetches names of all repositories owned by the organization or the user in
+// "owner/repo" form. Archived repositories are not included since their workflows never run. The
+// returned names are sorted.
+func (c *GitHubClient) Repositories(owner string) ([]string, error) {
+	ret := []string{}
+	path := fmt.Sprintf("/orgs/%s/repos", url.PathEscape(owner))
+	for page := 1; ; page++ {
+		p := fmt.Sprintf("%s?per_page=100&page=%d", path, page)
+		status, body, err := c.get(p)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusNotFound && page == 1 && strings.HasPrefix(path, "/orgs/") {
+			// The owner is not an organization. Try a user
+			path = fmt.Sprintf("/users/%s/repos", url.PathEscape(owner))
+			page = 0
+			continue
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("could not list repositories of %q: status %d", owner, status)
+		}
+
+		var repos []struct {
+			FullName string `json:"full_name"`
+			Archived bool   `json:"archived"`
+		}
+		if err := json.Unmarshal(body, &repos); err != nil {
+			return nil, fmt.Errorf("could not parse response from %s: %w", p, err)
+		}
+		for _, r := range repos {
+			if !r.Archived {
+				ret = append(ret, r.FullName)
+			}
+		}
+		if len(repos) < 100 {
+			sort.Strings(ret)
+			return ret, nil
+		}
+	}
+}
+
+// License fetches the SPDX license identifier of the repository like "MIT". The repo parameter is
+// "owner/repo" form. The second return value is false when the license is not detected.
+func (c *GitHubClient) License(repo string) (string, bool, error) {
+	p := fmt.Sprintf("/repos/%s/license", repo)
+	status, body, err := c.get(p)
+	if err != nil {
+		return "", false, err
+	}
+	if status != http.StatusOK {
+		return "", false, nil
+	}
+
+	var res struct {
+		License struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", false, fmt.Errorf("could not parse response from %s: %w", p, err)
+	}
+	id := res.License.SPDXID
+	if id == "" || id == "NOASSERTION" {
+		return "", false, nil
+	}
+	return id, true, nil
+}
+
+// Tarball downloads the archive of the repository at the ref in tar.gz format. The repo parameter
+// is "owner/repo" form. Unlike other methods, the response is not cached.
+func (c *GitHubClient) Tarball(repo, ref string) ([]byte, error) {
+	p := fmt.Sprintf("/repos/%s/tarball/%s", repo, url.PathEscape(ref))
+	status, body, err := c.request(http.MethodGet, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("could not download archive of %s at %s: status %d", repo, ref, status)
+	}
+	return body, nil
+}
+
+// CreateCheckRun creates a completed check run for the commit of the repository with the output.
+// When the output has more annotations than GitHub API accepts at once, the rest of annotations are
+// sent by updating the check run. The repo parameter is "owner/repo" form. It returns the ID of
+// the created check run.
+func (c *GitHubClient) CreateCheckRun(repo, sha, conclusion string, output *CheckRunOutput) (int64, error) {
+	const limit = 50 // https://docs.github.com/en/rest/checks/runs#create-a-check-run
+	annots := output.Annotations
+	first := annots
+	if len(first) > limit {
+		first = first[:limit]
+	}
+
+	p := fmt.Sprintf("/repos/%s/check-runs", repo)
+	o := *output
+	o.Annotations = first
+	status, body, err := c.request(http.MethodPost, p, map[string]interface{}{
+		"name":       "actionlint",
+		"head_sha":   sha,
+		"status":     "completed",
+		"conclusion": conclusion,
+		"output":     &o,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if status != http.StatusCreated {
+		return 0, fmt.Errorf("could not create check run for %s at %s: status %d: %s", repo, sha, status, strings.TrimSpace(string(body)))
+	}
+	var res struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return 0, fmt.Errorf("could not parse response from %s: %w", p, err)
+	}
+
+	for i := limit; i < len(annots); i += limit {
+		j := i + limit
+		if j > len(annots) {
+			j = len(annots)
+		}
+		o.Annotations = annots[i:j]
+		p := fmt.Sprintf("/repos/%s/check-runs/%d", repo, res.ID)
+		status, body, err := c.request(http.MethodPatch, p, map[string]interface{}{"output": &o})
+		if err != nil {
+			return 0, err
+		}
+		if status != http.StatusOK {
+			return 0, fmt.Errorf("could not add annotations to check run %d of %s: status %d: %s", res.ID, repo, status, strings.TrimSpace(string(body)))
+		}
+	}
+
+	return res.ID, nil
+}
+
+// RemoteRepository is a repository on GitHub to validate repository-specific references in workflows
+// such as secrets, configuration variables, environments, branches, and self-hosted runner labels.
+type RemoteRepository struct {
+	// Owner is an owner of the repository.
+	Owner string
+	// Name is a name of the repository.
+	Name   string
+	client *GitHubClient
+}
+
+// NewRemoteRepository creates a new RemoteRepository instance. The slug parameter is "owner/repo"
+// form.
+func NewRemoteRepository(slug string, client *GitHubClient) (*RemoteRepository, error) {
+	ss := strings.Split(slug, "/")
+	if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+		return nil, fmt.Errorf("remote repository must be in \"owner/repo\" form but got %q", slug)
+	}
+	return &RemoteRepository{ss[0], ss[1], client}, nil
+}
+
+// Client returns the GitHub API client used by this repository.
+func (r *RemoteRepository) Client() *GitHubClient {
+	return r.client
+}
+
+// String returns "owner/repo" form of the repository.
+func (r *RemoteRepository) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+func (r *RemoteRepository) path(p string) string {
+	return fmt.Sprintf("/repos/%s/%s%s", r.Owner, r.Name, p)
+}
+
+func mergeRemoteNames(sets ...map[string]struct{}) map[string]struct{} {
+	ret := map[string]struct{}{}
+	for _, s := range sets {
+		for n := range s {
+			ret[strings.ToUpper(n)] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// Secrets returns names of secrets available in the repository including organization secrets.
+// Names are in upper case. The second return value is false when the secrets cannot be listed due to
+// lack of permission.
+func (r *RemoteRepository) Secrets() (map[string]struct{}, bool, error) {
+	repo, ok, err := r.client.names(r.path("/actions/secrets"), "secrets")
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	org, _, err := r.client.names(r.path("/actions/organization-secrets"), "secrets")
+	if err != nil {
+		return nil, false, err
+	}
+	return mergeRemoteNames(repo, org), true, nil
+}
+
+// Variables returns names of configuration variables available in the repository including
+// organization variables. Names are in upper case. The second return value is false when the
+// variables cannot be listed due to lack of permission.
+func (r *RemoteRepository) Variables() (map[string]struct{}, bool, error) {
+	repo, ok, err := r.client.names(r.path("/actions/variables"), "variables")
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	org, _, err := r.client.names(r.path("/actions/organization-variables"), "variables")
+	if err != nil {
+		return nil, false, err
+	}
+	return mergeRemoteNames(repo, org), true, nil
+}
+
+// Environments returns names of deployment environments in the repository.
+func (r *RemoteRepository) Environments() (map[string]struct{}, bool, error) {
+	return r.client.names(r.path("/environments"), "environments")
+}
+
+// EnvironmentSecrets returns names of secrets in the environment. Names are in upper case.
+func (r *RemoteRepository) EnvironmentSecrets(env string) (map[string]struct{}, bool, error) {
+	s, ok, err := r.client.names(r.path("/environments/"+url.PathEscape(env)+"/secrets"), "secrets")
+	return mergeRemoteNames(s), ok, err
+}
+
+// EnvironmentVariables returns names of configuration variables in the environment. Names are in
+// upper case.
+func (r *RemoteRepository) EnvironmentVariables(env string) (map[string]struct{}, bool, error) {
+	s, ok, err := r.client.names(r.path("/environments/"+url.PathEscape(env)+"/variables"), "variables")
+	return mergeRemoteNames(s), ok, err
+}
+
+// Branches returns names of branches in the repository.
+func (r *RemoteRepository) Branches() (map[string]struct{}, bool, error) {
+	return r.client.names(r.path("/branches"), "")
+}
+
+// RunnerLabels returns labels of self-hosted runners registered in the repository and its
+// organization. Labels are in lower case. Organization runners are included only when the token has
+// permission to list them.
+func (r *RemoteRepository) RunnerLabels() (map[string]struct{}, bool, error) {
+	repo, ok, err := r.client.runnerLabels(r.path("/actions/runners"))
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	org, _, err := r.client.runnerLabels(fmt.Sprintf("/orgs/%s/actions/runners", r.Owner))
+	if err != nil {
+		return nil, false, err
+	}
+	for l := range org {
+		repo[l] = struct{}{}
+	}
+	return repo, true, nil
+}
+
+// RunnerGroups returns names of runner groups in the organization of the repository.
+func (r *RemoteRepository) RunnerGroups() (map[string]struct{}, bool, error) {
+	return r.client.names(fmt.Sprintf("/orgs/%s/actions/runner-groups", r.Owner), "runner_groups")
+}
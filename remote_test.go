@@ -0,0 +1,265 @@
+package actionlint
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testGitHubServer serves the responses keyed by "path?page=N" or "path". Unknown paths return 404.
+func testGitHubServer(t *testing.T, routes map[string]string) (*GitHubClient, *int32) {
+	var count int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		keys := []string{r.URL.Path}
+		if p := r.URL.Query().Get("page"); p != "" {
+			keys = append([]string{fmt.Sprintf("%s?page=%s", r.URL.Path, p)}, keys...)
+		}
+		if ref := r.URL.Query().Get("ref"); ref != "" {
+			keys = append([]string{fmt.Sprintf("%s?ref=%s", r.URL.Path, ref)}, keys...)
+		}
+		for _, k := range keys {
+			if b, ok := routes[k]; ok {
+				if b == "403" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(b))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(s.Close)
+
+	c := NewGitHubClientWithBaseURL(s.URL+"/", "test-token")
+	return c, &count
+}
+
+func TestRemoteRepositoryNames(t *testing.T) {
+	c, count := testGitHubServer(t, map[string]string{
+		"/repos/o/r/actions/secrets":                 `{"total_count":1,"secrets":[{"name":"repo_secret"}]}`,
+		"/repos/o/r/actions/organization-secrets":    `{"total_count":1,"secrets":[{"name":"ORG_SECRET"}]}`,
+		"/repos/o/r/actions/variables":               `{"total_count":1,"variables":[{"name":"REPO_VAR"}]}`,
+		"/repos/o/r/actions/organization-variables":  "403",
+		"/repos/o/r/environments":                    `{"total_count":1,"environments":[{"name":"production"}]}`,
+		"/repos/o/r/environments/production/secrets": `{"total_count":1,"secrets":[{"name":"DEPLOY_KEY"}]}`,
+		"/repos/o/r/actions/runners":                 `{"total_count":1,"runners":[{"labels":[{"name":"self-hosted"},{"name":"GPU"}]}]}`,
+		"/orgs/o/actions/runners":                    "403",
+	})
+	r, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		what  string
+		fetch func() (map[string]struct{}, bool, error)
+		want  []string
+	}{
+		{"secrets", r.Secrets, []string{"ORG_SECRET", "REPO_SECRET"}},
+		{"variables", r.Variables, []string{"REPO_VAR"}},
+		{"environments", r.Environments, []string{"production"}},
+		{"environment secrets", func() (map[string]struct{}, bool, error) { return r.EnvironmentSecrets("production") }, []string{"DEPLOY_KEY"}},
+		{"runner labels", r.RunnerLabels, []string{"gpu", "self-hosted"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			m, ok, err := tc.fetch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("resource was not available")
+			}
+			have := remoteNamesToSlice(m)
+			sort.Strings(have)
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+
+	// Responses are cached
+	before := atomic.LoadInt32(count)
+	if _, _, err := r.Secrets(); err != nil {
+		t.Fatal(err)
+	}
+	if after := atomic.LoadInt32(count); before != after {
+		t.Fatalf("API was requested again: %d -> %d", before, after)
+	}
+}
+
+func TestRemoteRepositoryPagination(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"name":"branch-%d"}`, i)
+	}
+	b.WriteString("]")
+
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/branches?page=1": b.String(),
+		"/repos/o/r/branches?page=2": `[{"name":"main"}]`,
+	})
+	r, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok, err := r.Branches()
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if len(m) != 101 {
+		t.Fatalf("wanted 101 branches but got %d", len(m))
+	}
+	if _, ok := m["main"]; !ok {
+		t.Fatal("branch on second page was not fetched")
+	}
+}
+
+func TestRemoteRepositoryNotAvailable(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/actions/secrets": "403",
+	})
+	r, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := r.Secrets(); ok || err != nil {
+		t.Fatal("secrets should not be available", ok, err)
+	}
+	if _, ok, err := r.Environments(); ok || err != nil {
+		t.Fatal("environments should not be available", ok, err)
+	}
+}
+
+func TestRemoteRepositoryUnauthorized(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{})
+	c.token = "wrong-token"
+	r, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = r.Branches()
+	if err == nil || !strings.Contains(err.Error(), "was not authorized") {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestRemoteGitHubClientContent(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("on: workflow_call\njobs: {}\n"))
+	content = content[:10] + "\n" + content[10:]
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/contents/.github/workflows/ci.yml?ref=v1": fmt.Sprintf(`{"type":"file","encoding":"base64","content":%q}`, content),
+		"/repos/o/r/contents/.github/workflows?ref=v1":        `[]`,
+	})
+
+	b, ok, err := c.Content("o/r", ".github/workflows/ci.yml", "v1")
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if string(b) != "on: workflow_call\njobs: {}\n" {
+		t.Fatalf("unexpected content: %q", b)
+	}
+
+	for _, ref := range []string{"v2", "v1"} {
+		path := ".github/workflows/ci.yml"
+		if ref == "v1" {
+			path = ".github/workflows" // directory
+		}
+		if _, ok, err := c.Content("o/r", path, ref); ok || err != nil {
+			t.Fatal(path, ref, ok, err)
+		}
+	}
+}
+
+func TestRemoteNewRemoteRepositoryError(t *testing.T) {
+	for _, s := range []string{"", "owner", "owner/", "/repo", "a/b/c"} {
+		if _, err := NewRemoteRepository(s, NewGitHubClient("")); err == nil {
+			t.Errorf("error was not returned for %q", s)
+		}
+	}
+}
+
+func TestRemoteGitHubClientDirectory(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/contents/.github/workflows": `[
+			{"type":"file","path":".github/workflows/test.yaml"},
+			{"type":"dir","path":".github/workflows/sub"},
+			{"type":"file","path":".github/workflows/ci.yml"}
+		]`,
+		"/repos/o/r/contents/README.md": `{"type":"file","encoding":"base64","content":""}`,
+	})
+
+	fs, ok, err := c.Directory("o/r", ".github/workflows", "")
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if diff := cmp.Diff([]string{".github/workflows/ci.yml", ".github/workflows/test.yaml"}, fs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	for _, p := range []string{"README.md", "missing"} {
+		if _, ok, err := c.Directory("o/r", p, ""); ok || err != nil {
+			t.Fatal(p, ok, err)
+		}
+	}
+}
+
+func TestRemoteGitHubClientRepositories(t *testing.T) {
+	page1 := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		page1 = append(page1, fmt.Sprintf(`{"full_name":"org/repo%03d","archived":%v}`, i, i%50 == 0))
+	}
+	c, _ := testGitHubServer(t, map[string]string{
+		"/orgs/org/repos?page=1": "[" + strings.Join(page1, ",") + "]",
+		"/orgs/org/repos?page=2": `[{"full_name":"org/last","archived":false}]`,
+		"/users/user/repos":      `[{"full_name":"user/b"},{"full_name":"user/a"}]`,
+		"/orgs/forbidden/repos":  "403",
+		"/users/forbidden/repos": "403",
+		"/orgs/broken/repos":     "[",
+	})
+
+	rs, err := c.Repositories("org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 99 || rs[0] != "org/last" || rs[1] != "org/repo001" {
+		t.Fatalf("unexpected repositories (%d): %v", len(rs), rs)
+	}
+
+	rs, err = c.Repositories("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"user/a", "user/b"}, rs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	for owner, want := range map[string]string{
+		"forbidden": "status 403",
+		"broken":    "could not parse response",
+		"nobody":    "status 404",
+	} {
+		_, err := c.Repositories(owner)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q is not included in error for %q: %v", want, owner, err)
+		}
+	}
+}
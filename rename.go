@@ -0,0 +1,120 @@
+package actionlint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// renameSpec is a spec of renaming job ID or step ID given by -rename option like "job:old=new".
+type renameSpec struct {
+	kind lspRefKind
+	from string
+	to   string
+}
+
+func parseRenameSpec(s string) (*renameSpec, error) {
+	k, ids, ok := strings.Cut(s, ":")
+	from, to, ok2 := strings.Cut(ids, "=")
+	if !ok || !ok2 || from == "" {
+		return nil, fmt.Errorf("invalid value %q for -rename. it must be in \"job:old=new\" or \"step:old=new\" form", s)
+	}
+	ret := &renameSpec{from: from, to: to}
+	switch k {
+	case "job":
+		ret.kind = lspRefKindJob
+	case "step":
+		ret.kind = lspRefKindStep
+	default:
+		return nil, fmt.Errorf("kind of ID to rename must be \"job\" or \"step\" but got %q", k)
+	}
+	if err := validateNewID(ret.kind, to); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func validateNewID(kind lspRefKind, id string) error {
+	if !jobIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid %s ID %q. %s ID must start with a letter or _ and contain only alphanumeric characters, -, or _", kind, id, kind)
+	}
+	return nil
+}
+
+// renameRefs returns all references to the target which should be renamed to the new ID. It returns
+// an error when the new ID conflicts with an existing ID.
+func renameRefs(refs []*lspRef, target *lspRef, to string) ([]*lspRef, error) {
+	kind := target.kind
+	if err := validateNewID(kind, to); err != nil {
+		return nil, err
+	}
+	ret := []*lspRef{}
+	for _, r := range refs {
+		if r.sameTarget(target) {
+			ret = append(ret, r)
+			continue
+		}
+		if r.decl && r.kind == target.kind && strings.EqualFold(r.job, target.job) && strings.EqualFold(r.id, to) {
+			return nil, fmt.Errorf("%s ID %q is already used at line %d. note that %s ID is case insensitive", kind, r.id, r.line+1, kind)
+		}
+	}
+	return ret, nil
+}
+
+// applyRenames replaces the IDs at the references with the new ID in the source lines.
+func applyRenames(lines []string, refs []*lspRef, to string) string {
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		return a.line > b.line || a.line == b.line && a.col > b.col // Replace from the end not to shift columns
+	})
+	ls := make([]string, len(lines))
+	copy(ls, lines)
+	for _, r := range refs {
+		l := ls[r.line]
+		ls[r.line] = l[:r.col] + to + l[r.col+len(r.id):]
+	}
+	return strings.Join(ls, "\n")
+}
+
+// renameIDInWorkflow renames the job ID or the step ID in the workflow source and updates all
+// references to it. Step IDs are renamed in all jobs which declare them. It returns the number of
+// renamed references. When the ID is not declared in the workflow, it returns zero.
+func renameIDInWorkflow(src []byte, spec *renameSpec) ([]byte, int, error) {
+	w, errs := Parse(src)
+	if w == nil {
+		if len(errs) > 0 {
+			return nil, 0, errors.New(errs[0].Message)
+		}
+		return nil, 0, errors.New("could not parse workflow")
+	}
+
+	text := string(src)
+	crlf := strings.Contains(text, "\r\n")
+	if crlf {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	lines := strings.Split(text, "\n")
+	refs := lspWorkflowRefs(w, lines)
+
+	renames := []*lspRef{}
+	for _, r := range refs {
+		if !r.decl || r.kind != spec.kind || !strings.EqualFold(r.id, spec.from) {
+			continue
+		}
+		rs, err := renameRefs(refs, r, spec.to)
+		if err != nil {
+			return nil, 0, err
+		}
+		renames = append(renames, rs...)
+	}
+	if len(renames) == 0 {
+		return src, 0, nil
+	}
+
+	out := applyRenames(lines, renames, spec.to)
+	if crlf {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	return []byte(out), len(renames), nil
+}
@@ -0,0 +1,280 @@
+package actionlint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRenameWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      ver: ${{ steps.get.outputs.ver }}
+    steps:
+      - id: get
+        run: echo "ver=1" >> "$GITHUB_OUTPUT"
+      - run: echo ${{ steps.get.outputs.ver }} ${{ steps.Get.conclusion }}
+        if: steps.get.outcome == 'success'
+  test:
+    needs: [build, "lint"]
+    if: needs.build.result == 'success' && needs.lint.result == 'success'
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo ${{ needs.build.outputs.ver }} ${{ github.event.needs.build }}
+  lint:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "needs.build is not an expression"
+`
+
+func TestRenameIDInWorkflow(t *testing.T) {
+	testCases := []struct {
+		what  string
+		spec  string
+		want  string
+		count int
+	}{
+		{
+			what: "job",
+			spec: "job:build=compile",
+			want: strings.NewReplacer(
+				"  build:", "  compile:",
+				"[build,", "[compile,",
+				"needs.build.result", "needs.compile.result",
+				"needs.build.outputs", "needs.compile.outputs",
+				"needs: build", "needs: compile",
+			).Replace(testRenameWorkflow),
+			count: 5,
+		},
+		{
+			what: "quoted job",
+			spec: "job:lint=check",
+			want: strings.NewReplacer(
+				`"lint"`, `"check"`,
+				"needs.lint", "needs.check",
+				"  lint:", "  check:",
+			).Replace(testRenameWorkflow),
+			count: 3,
+		},
+		{
+			what: "step in all jobs",
+			spec: "step:get=version",
+			want: strings.NewReplacer(
+				"steps.get", "steps.version",
+				"steps.Get", "steps.version",
+				"id: get", "id: version",
+			).Replace(testRenameWorkflow),
+			count: 6,
+		},
+		{
+			what:  "not found",
+			spec:  "job:unknown=foo",
+			want:  testRenameWorkflow,
+			count: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := parseRenameSpec(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, n, err := renameIDInWorkflow([]byte(testRenameWorkflow), s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tc.count {
+				t.Errorf("wanted %d renames but got %d", tc.count, n)
+			}
+			if have := string(b); have != tc.want {
+				t.Fatalf("wanted:\n%s\nbut got:\n%s", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRenameIDInWorkflowMultibyte(t *testing.T) {
+	src := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - { name: 日本語, id: foo, run: echo hi }
+      - run: echo "日本語 ${{ steps.foo.outputs.x }}"
+  テスト: { needs: build, runs-on: ubuntu-latest, steps: [{ run: echo }] }
+`
+	want := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - { name: 日本語, id: bar, run: echo hi }
+      - run: echo "日本語 ${{ steps.bar.outputs.x }}"
+  テスト: { needs: build, runs-on: ubuntu-latest, steps: [{ run: echo }] }
+`
+	s, err := parseRenameSpec("step:foo=bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, n, err := renameIDInWorkflow([]byte(src), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatal("unexpected number of renames:", n)
+	}
+	if have := string(b); have != want {
+		t.Fatalf("wanted:\n%s\nbut got:\n%s", want, have)
+	}
+
+	s, err = parseRenameSpec("job:build=compile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, n, err = renameIDInWorkflow([]byte(src), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || !strings.Contains(string(b), "  テスト: { needs: compile, runs-on") {
+		t.Fatalf("job was not renamed correctly (%d renames):\n%s", n, b)
+	}
+}
+
+func TestRenameIDInWorkflowCRLF(t *testing.T) {
+	src := strings.ReplaceAll(testRenameWorkflow, "\n", "\r\n")
+	s, err := parseRenameSpec("job:build=compile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, n, err := renameIDInWorkflow([]byte(src), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatal("unexpected number of renames:", n)
+	}
+	if strings.Count(string(b), "\r\n") != strings.Count(src, "\r\n") {
+		t.Fatalf("CRLF is not preserved: %q", b)
+	}
+}
+
+func TestRenameIDInWorkflowError(t *testing.T) {
+	testCases := []struct {
+		what string
+		spec string
+		src  string
+		want string
+	}{
+		{
+			what: "conflict with other job",
+			spec: "job:build=Lint",
+			src:  testRenameWorkflow,
+			want: `job ID "lint" is already used at line 19`,
+		},
+		{
+			what: "conflict with other step",
+			spec: "step:get=foo",
+			src:  "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - id: get\n        run: echo\n      - id: foo\n        run: echo\n",
+			want: `step ID "foo" is already used at line 8`,
+		},
+		{
+			what: "broken workflow",
+			spec: "job:build=compile",
+			src:  "on: push\njobs: [",
+			want: "could not parse",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			s, err := parseRenameSpec(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = renameIDInWorkflow([]byte(tc.src), s)
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("%q is not included in error message %q", tc.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseRenameSpecError(t *testing.T) {
+	testCases := []struct {
+		spec string
+		want string
+	}{
+		{"build=compile", "it must be in \"job:old=new\""},
+		{"job:build", "it must be in \"job:old=new\""},
+		{"job:=compile", "it must be in \"job:old=new\""},
+		{"matrix:os=platform", "kind of ID to rename must be \"job\" or \"step\""},
+		{"job:build=", "invalid job ID \"\""},
+		{"step:get=1st", "invalid step ID \"1st\""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := parseRenameSpec(tc.spec)
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("%q is not included in error message %q", tc.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestCommandRename(t *testing.T) {
+	dir := t.TempDir()
+	ci := filepath.Join(dir, "ci.yaml")
+	other := filepath.Join(dir, "other.yaml")
+	otherSrc := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	for p, s := range map[string]string{ci: testRenameWorkflow, other: otherSrc} {
+		if err := os.WriteFile(p, []byte(s), 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	var out bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &out, Stderr: &out}
+	if status := cmd.Main([]string{"actionlint", "-rename", "job:build=compile", ci, other}); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, out.String())
+	}
+	if want := `Renamed job "build" to "compile" at 5 places in ` + ci; !strings.Contains(out.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, out.String())
+	}
+
+	b, err := os.ReadFile(ci)
+	if err != nil {
+		panic(err)
+	}
+	if !strings.Contains(string(b), "needs: compile") || strings.Contains(string(b), "needs: build") {
+		t.Fatalf("job was not renamed:\n%s", b)
+	}
+	b, err = os.ReadFile(other)
+	if err != nil {
+		panic(err)
+	}
+	if string(b) != otherSrc {
+		t.Fatalf("file which does not have the job was modified:\n%s", b)
+	}
+
+	out.Reset()
+	if status := cmd.Main([]string{"actionlint", "-rename", "job:build=compile", ci, other}); status != ExitStatusFailure {
+		t.Fatal("exit status should be", ExitStatusFailure, "but got", status, out.String())
+	}
+	if want := `job "build" was not found in any workflow files`; !strings.Contains(out.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, out.String())
+	}
+}
@@ -0,0 +1,167 @@
+package actionlint
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ReportGroupBy is a key to group errors in a report.
+type ReportGroupBy string
+
+const (
+	// ReportGroupByNone does not group errors. Each error is printed separately.
+	ReportGroupByNone ReportGroupBy = ""
+	// ReportGroupByFile groups errors by file paths.
+	ReportGroupByFile ReportGroupBy = "file"
+	// ReportGroupByRule groups errors by rule names (kinds of errors).
+	ReportGroupByRule ReportGroupBy = "rule"
+)
+
+// ParseReportGroupBy parses the given string as ReportGroupBy. An empty string is parsed as
+// ReportGroupByNone.
+func ParseReportGroupBy(s string) (ReportGroupBy, error) {
+	switch g := ReportGroupBy(s); g {
+	case ReportGroupByNone, ReportGroupByFile, ReportGroupByRule:
+		return g, nil
+	default:
+		return ReportGroupByNone, fmt.Errorf("unknown group %q to aggregate errors. it must be one of \"file\" or \"rule\"", s)
+	}
+}
+
+// ReportCount is a number of errors for some key in a report group.
+type ReportCount struct {
+	// Key is a file path or a rule name.
+	Key string
+	// Count is a number of errors for the key.
+	Count int
+}
+
+// ReportGroup is a group of errors aggregated by a file path or a rule name.
+type ReportGroup struct {
+	// Key is a file path or a rule name of the group.
+	Key string
+	// Errors is a list of errors in the group.
+	Errors []*Error
+	// Breakdown is numbers of errors in the group broken down by the other key. When the group is
+	// grouped by file, the keys are rule names. When the group is grouped by rule, the keys are file
+	// paths. The counts are sorted in descending order.
+	Breakdown []ReportCount
+}
+
+// Report is an aggregation of errors detected by the linter. It is useful to triage many errors
+// in a large codebase.
+type Report struct {
+	// Total is a total number of errors.
+	Total int
+	// Files is a number of files which have at least one error.
+	Files int
+	// Rules is a number of rules which reported at least one error.
+	Rules int
+	// Groups is a list of groups sorted by the numbers of errors in descending order.
+	Groups []*ReportGroup
+}
+
+// NewReport aggregates the given errors into a report. The errors are grouped by the given key.
+// When ReportGroupByNone is given, the errors are grouped by rule.
+func NewReport(errs []*Error, by ReportGroupBy) *Report {
+	files := map[string]struct{}{}
+	rules := map[string]struct{}{}
+	groups := map[string]*ReportGroup{}
+	for _, err := range errs {
+		files[err.Filepath] = struct{}{}
+		rules[err.Kind] = struct{}{}
+
+		k := err.Kind
+		if by == ReportGroupByFile {
+			k = err.Filepath
+		}
+		g, ok := groups[k]
+		if !ok {
+			g = &ReportGroup{Key: k}
+			groups[k] = g
+		}
+		g.Errors = append(g.Errors, err)
+	}
+
+	gs := make([]*ReportGroup, 0, len(groups))
+	for _, g := range groups {
+		counts := map[string]int{}
+		for _, err := range g.Errors {
+			k := err.Filepath
+			if by == ReportGroupByFile {
+				k = err.Kind
+			}
+			counts[k]++
+		}
+		g.Breakdown = sortReportCounts(counts)
+		gs = append(gs, g)
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		if len(gs[i].Errors) != len(gs[j].Errors) {
+			return len(gs[i].Errors) > len(gs[j].Errors)
+		}
+		return gs[i].Key < gs[j].Key
+	})
+
+	return &Report{
+		Total:  len(errs),
+		Files:  len(files),
+		Rules:  len(rules),
+		Groups: gs,
+	}
+}
+
+func sortReportCounts(m map[string]int) []ReportCount {
+	cs := make([]ReportCount, 0, len(m))
+	for k, c := range m {
+		cs = append(cs, ReportCount{k, c})
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Count != cs[j].Count {
+			return cs[i].Count > cs[j].Count
+		}
+		return cs[i].Key < cs[j].Key
+	})
+	return cs
+}
+
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// PrintGrouped prints the report in text grouped by the given key. Each group is printed with its
+// number of errors followed by the breakdown. For example,
+//
+//	runner-label: 37 occurrences across 12 files
+//	  .github/workflows/ci.yaml: 10
+//	  ...
+func (r *Report) PrintGrouped(w io.Writer, by ReportGroupBy) {
+	for _, g := range r.Groups {
+		if by == ReportGroupByFile {
+			yellow.Fprint(w, g.Key)
+			gray.Fprint(w, ": ")
+			fmt.Fprintf(w, "%s by %s\n", pluralize(len(g.Errors), "error"), pluralize(len(g.Breakdown), "rule"))
+		} else {
+			bold.Fprint(w, g.Key)
+			gray.Fprint(w, ": ")
+			fmt.Fprintf(w, "%s across %s\n", pluralize(len(g.Errors), "occurrence"), pluralize(len(g.Breakdown), "file"))
+		}
+		for _, c := range g.Breakdown {
+			fmt.Fprint(w, "  ")
+			if by == ReportGroupByFile {
+				bold.Fprint(w, c.Key)
+			} else {
+				yellow.Fprint(w, c.Key)
+			}
+			gray.Fprint(w, ": ")
+			fmt.Fprintln(w, c.Count)
+		}
+	}
+	if r.Total > 0 {
+		fmt.Fprintf(w, "\n%s in %s by %s\n", pluralize(r.Total, "error"), pluralize(r.Files, "file"), pluralize(r.Rules, "rule"))
+	}
+}
@@ -0,0 +1,133 @@
+package actionlint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testReportErrors() []*Error {
+	return []*Error{
+		{Filepath: "a.yaml", Line: 1, Column: 1, Kind: "runner-label", Message: "m"},
+		{Filepath: "a.yaml", Line: 2, Column: 1, Kind: "runner-label", Message: "m"},
+		{Filepath: "b.yaml", Line: 1, Column: 1, Kind: "runner-label", Message: "m"},
+		{Filepath: "b.yaml", Line: 3, Column: 1, Kind: "expression", Message: "m"},
+		{Filepath: "c.yaml", Line: 1, Column: 1, Kind: "syntax-check", Message: "m"},
+	}
+}
+
+func TestReportNewReport(t *testing.T) {
+	testCases := []struct {
+		by     ReportGroupBy
+		groups []string
+		counts [][]ReportCount
+	}{
+		{
+			by:     ReportGroupByRule,
+			groups: []string{"runner-label", "expression", "syntax-check"},
+			counts: [][]ReportCount{
+				{{"a.yaml", 2}, {"b.yaml", 1}},
+				{{"b.yaml", 1}},
+				{{"c.yaml", 1}},
+			},
+		},
+		{
+			by:     ReportGroupByFile,
+			groups: []string{"a.yaml", "b.yaml", "c.yaml"},
+			counts: [][]ReportCount{
+				{{"runner-label", 2}},
+				{{"expression", 1}, {"runner-label", 1}},
+				{{"syntax-check", 1}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.by), func(t *testing.T) {
+			r := NewReport(testReportErrors(), tc.by)
+			if r.Total != 5 || r.Files != 3 || r.Rules != 3 {
+				t.Fatalf("wrong totals: %#v", r)
+			}
+			groups := make([]string, 0, len(r.Groups))
+			counts := make([][]ReportCount, 0, len(r.Groups))
+			for _, g := range r.Groups {
+				groups = append(groups, g.Key)
+				counts = append(counts, g.Breakdown)
+			}
+			if !cmp.Equal(tc.groups, groups) {
+				t.Fatal(cmp.Diff(tc.groups, groups))
+			}
+			if !cmp.Equal(tc.counts, counts) {
+				t.Fatal(cmp.Diff(tc.counts, counts))
+			}
+		})
+	}
+}
+
+func TestReportPrintGrouped(t *testing.T) {
+	testCases := []struct {
+		by   ReportGroupBy
+		want []string
+	}{
+		{
+			by: ReportGroupByRule,
+			want: []string{
+				"runner-label: 3 occurrences across 2 files",
+				"  a.yaml: 2",
+				"  b.yaml: 1",
+				"expression: 1 occurrence across 1 file",
+				"  b.yaml: 1",
+				"syntax-check: 1 occurrence across 1 file",
+				"  c.yaml: 1",
+				"",
+				"5 errors in 3 files by 3 rules",
+			},
+		},
+		{
+			by: ReportGroupByFile,
+			want: []string{
+				"a.yaml: 2 errors by 1 rule",
+				"  runner-label: 2",
+				"b.yaml: 2 errors by 2 rules",
+				"  expression: 1",
+				"  runner-label: 1",
+				"c.yaml: 1 error by 1 rule",
+				"  syntax-check: 1",
+				"",
+				"5 errors in 3 files by 3 rules",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.by), func(t *testing.T) {
+			var b bytes.Buffer
+			NewReport(testReportErrors(), tc.by).PrintGrouped(&b, tc.by)
+			have := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestReportPrintGroupedNoError(t *testing.T) {
+	var b bytes.Buffer
+	NewReport(nil, ReportGroupByRule).PrintGrouped(&b, ReportGroupByRule)
+	if b.Len() != 0 {
+		t.Fatalf("nothing should be printed but got %q", b.String())
+	}
+}
+
+func TestReportParseReportGroupBy(t *testing.T) {
+	for _, s := range []string{"", "file", "rule"} {
+		if _, err := ParseReportGroupBy(s); err != nil {
+			t.Errorf("%q should be parsed but got error: %s", s, err)
+		}
+	}
+	if _, err := ParseReportGroupBy("job"); err == nil {
+		t.Fatal("error was not returned for unknown group")
+	}
+}
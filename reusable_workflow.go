@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -170,8 +171,10 @@ type LocalReusableWorkflowCache struct {
 	mu    sync.RWMutex
 	proj  *Project // maybe nil
 	cache map[string]*ReusableWorkflowMetadata
+	calls map[string][]string // Reusable workflows called by each reusable workflow
 	cwd   string
 	dbg   io.Writer
+	read  func(string) ([]byte, error) // os.ReadFile when nil
 }
 
 func (c *LocalReusableWorkflowCache) debug(format string, args ...interface{}) {
@@ -182,6 +185,13 @@ func (c *LocalReusableWorkflowCache) debug(format string, args ...interface{}) {
 	fmt.Fprintf(c.dbg, format, args...)
 }
 
+func (c *LocalReusableWorkflowCache) readFile(path string) ([]byte, error) {
+	if c.read != nil {
+		return c.read(path)
+	}
+	return os.ReadFile(path)
+}
+
 func (c *LocalReusableWorkflowCache) readCache(key string) (*ReusableWorkflowMetadata, bool) {
 	c.mu.RLock()
 	m, ok := c.cache[key]
@@ -215,7 +225,7 @@ func (c *LocalReusableWorkflowCache) FindMetadata(spec string) (*ReusableWorkflo
 	}
 
 	file := filepath.Join(c.proj.RootDir(), filepath.FromSlash(spec))
-	src, err := os.ReadFile(file)
+	src, err := c.readFile(file)
 	if err != nil {
 		c.writeCache(spec, nil) // Remember the workflow file was not found
 		return nil, fmt.Errorf("could not read reusable workflow file for %q: %w", spec, err)
@@ -233,6 +243,31 @@ func (c *LocalReusableWorkflowCache) FindMetadata(spec string) (*ReusableWorkflo
 	return m, nil
 }
 
+// findCalls returns specs at "uses:" of jobs in the local reusable workflow located by the 'spec'
+// argument. It returns nil when the workflow cannot be read. Calling this method is thread-safe.
+func (c *LocalReusableWorkflowCache) findCalls(spec string) []string {
+	if c.proj == nil || !strings.HasPrefix(spec, "./") || ContainsExpression(spec) {
+		return nil
+	}
+
+	c.mu.RLock()
+	calls, ok := c.calls[spec]
+	c.mu.RUnlock()
+	if ok {
+		return calls
+	}
+
+	src, err := c.readFile(filepath.Join(c.proj.RootDir(), filepath.FromSlash(spec)))
+	if err == nil {
+		calls = parseReusableWorkflowCalls(src)
+	}
+
+	c.mu.Lock()
+	c.calls[spec] = calls
+	c.mu.Unlock()
+	return calls
+}
+
 func (c *LocalReusableWorkflowCache) convWorkflowPathToSpec(p string) (string, bool) {
 	if c.proj == nil {
 		return "", false
@@ -320,6 +355,38 @@ func (c *LocalReusableWorkflowCache) WriteWorkflowCallEvent(wpath string, event
 	c.debug("Workflow call metadata from workflow %s: %v", wpath, m)
 }
 
+// parseReusableWorkflowCalls parses specs at "uses:" of jobs in the workflow source. Specs containing
+// ${{ }} placeholders are omitted since they cannot be resolved statically. The returned specs are
+// sorted.
+func parseReusableWorkflowCalls(src []byte) []string {
+	type workflow struct {
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+
+	var w workflow
+	if err := yaml.Unmarshal(src, &w); err != nil || w.Jobs.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	calls := []string{}
+	for i := 1; i < len(w.Jobs.Content); i += 2 {
+		j := w.Jobs.Content[i]
+		if j.Kind != yaml.MappingNode {
+			continue
+		}
+		for k := 0; k+1 < len(j.Content); k += 2 {
+			if j.Content[k].Value == "uses" {
+				if u := j.Content[k+1].Value; u != "" && !ContainsExpression(u) {
+					calls = append(calls, u)
+				}
+				break
+			}
+		}
+	}
+	sort.Strings(calls)
+	return calls
+}
+
 func parseReusableWorkflowMetadata(src []byte) (*ReusableWorkflowMetadata, error) {
 	type workflow struct {
 		On yaml.Node `yaml:"on"`
@@ -374,6 +441,7 @@ func NewLocalReusableWorkflowCache(proj *Project, cwd string, dbg io.Writer) *Lo
 	return &LocalReusableWorkflowCache{
 		proj:  proj,
 		cache: map[string]*ReusableWorkflowMetadata{},
+		calls: map[string][]string{},
 		cwd:   cwd,
 		dbg:   dbg,
 	}
@@ -412,3 +480,158 @@ func (f *LocalReusableWorkflowCacheFactory) GetCache(p *Project) *LocalReusableW
 	f.caches[r] = c
 	return c
 }
+
+type remoteReusableWorkflow struct {
+	src  []byte
+	meta *ReusableWorkflowMetadata // nil when the workflow is invalid
+	// False when the workflow was fetched only for tracking nested calls. Such workflows are not
+	// returned from Workflows method until their metadata is requested.
+	requested bool
+}
+
+// RemoteReusableWorkflowCache is a cache for reusable workflows in other repositories on GitHub. The
+// workflow files are fetched via GitHub API only once. Unlike LocalReusableWorkflowCache, this cache
+// can be shared across multiple projects. Calling methods of this struct is thread-safe.
+type RemoteReusableWorkflowCache struct {
+	mu     sync.RWMutex
+	client *GitHubClient
+	cache  map[string]*remoteReusableWorkflow // nil value means the workflow was not found
+	dbg    io.Writer
+}
+
+// NewRemoteReusableWorkflowCache creates a new RemoteReusableWorkflowCache instance. The client
+// parameter is used for fetching the workflow files.
+func NewRemoteReusableWorkflowCache(client *GitHubClient, dbg io.Writer) *RemoteReusableWorkflowCache {
+	return &RemoteReusableWorkflowCache{
+		client: client,
+		cache:  map[string]*remoteReusableWorkflow{},
+		dbg:    dbg,
+	}
+}
+
+func (c *RemoteReusableWorkflowCache) debug(format string, args ...interface{}) {
+	if c.dbg == nil {
+		return
+	}
+	format = "[RemoteReusableWorkflowCache] " + format + "\n"
+	fmt.Fprintf(c.dbg, format, args...)
+}
+
+// FindMetadata fetches/parses a reusable workflow metadata located by the 'spec' argument in
+// "owner/repo/path/to/workflow.yml@ref" format. The second return value is false when the workflow
+// file does not exist or the repository is not accessible. When the workflow file exists but it is
+// not a valid reusable workflow, this method returns nil metadata with true. The error is returned
+// only when fetching the workflow file failed.
+func (c *RemoteReusableWorkflowCache) FindMetadata(spec string) (*ReusableWorkflowMetadata, bool, error) {
+	w, err := c.fetch(spec)
+	if err != nil || w == nil {
+		return nil, false, err
+	}
+	c.mu.Lock()
+	w.requested = true
+	c.mu.Unlock()
+	return w.meta, true, nil
+}
+
+// fetch returns the reusable workflow located by the spec. It fetches the workflow file when it is
+// not cached yet. It returns nil when the workflow was not found.
+func (c *RemoteReusableWorkflowCache) fetch(spec string) (*remoteReusableWorkflow, error) {
+	c.mu.RLock()
+	w, ok := c.cache[spec]
+	c.mu.RUnlock()
+	if ok {
+		c.debug("Cache hit for %s", spec)
+		return w, nil
+	}
+
+	s, ref, ok := strings.Cut(spec, "@")
+	if !ok {
+		return nil, nil
+	}
+	ss := strings.SplitN(s, "/", 3)
+	if len(ss) != 3 {
+		return nil, nil
+	}
+
+	src, found, err := c.client.Content(ss[0]+"/"+ss[1], ss[2], ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		c.debug("Reusable workflow %s was not found", spec)
+		c.mu.Lock()
+		c.cache[spec] = nil
+		c.mu.Unlock()
+		return nil, nil
+	}
+
+	w = &remoteReusableWorkflow{src: src}
+	if m, err := parseReusableWorkflowMetadata(src); err == nil {
+		w.meta = m
+	} else {
+		c.debug("Reusable workflow %s is invalid: %v", spec, err)
+	}
+
+	c.mu.Lock()
+	c.cache[spec] = w
+	c.mu.Unlock()
+
+	c.debug("New reusable workflow metadata at %s: %v", spec, w.meta)
+	return w, nil
+}
+
+// findCalls returns specs at "uses:" of jobs in the reusable workflow located by the 'spec' argument
+// in "owner/repo/path/to/workflow.yml@ref" format. The workflow is fetched when it has not been
+// fetched yet. Local reusable workflow calls like "./path/to/workflow.yml" in the workflow are
+// resolved to the same repository and ref. It returns nil when the workflow was not found.
+func (c *RemoteReusableWorkflowCache) findCalls(spec string) ([]string, error) {
+	w, err := c.fetch(spec)
+	if err != nil || w == nil {
+		return nil, err
+	}
+
+	calls := parseReusableWorkflowCalls(w.src)
+	for i, u := range calls {
+		calls[i] = resolveRemoteWorkflowCall(u, spec)
+	}
+	return calls, nil
+}
+
+// resolveRemoteWorkflowCall resolves the local reusable workflow call like "./path/to/workflow.yml"
+// in the reusable workflow located by 'caller' in "owner/repo/path/to/workflow.yml@ref" format. The
+// call is resolved to the workflow in the same repository and ref. Other calls are returned as-is.
+func resolveRemoteWorkflowCall(spec, caller string) string {
+	if !isWorkflowCallUsesLocalFormat(spec) || !isWorkflowCallUsesRepoFormat(caller) {
+		return spec
+	}
+	s, ref, _ := strings.Cut(caller, "@")
+	ss := strings.SplitN(s, "/", 3)
+	return fmt.Sprintf("%s/%s/%s@%s", ss[0], ss[1], strings.TrimPrefix(spec, "./"), ref)
+}
+
+// Workflows returns specs of all reusable workflows whose metadata were requested so far in sorted
+// order. Workflows which were not found are not included.
+func (c *RemoteReusableWorkflowCache) Workflows() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ss := make([]string, 0, len(c.cache))
+	for s, w := range c.cache {
+		if w != nil && w.requested {
+			ss = append(ss, s)
+		}
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// Source returns the source of the fetched reusable workflow. The second return value is false when
+// the workflow has not been fetched yet or it was not found.
+func (c *RemoteReusableWorkflowCache) Source(spec string) ([]byte, bool) {
+	c.mu.RLock()
+	w := c.cache[spec]
+	c.mu.RUnlock()
+	if w == nil {
+		return nil, false
+	}
+	return w.src, true
+}
@@ -13,6 +13,7 @@ type RuleBase struct {
 	errs   []*Error
 	dbg    io.Writer
 	config *Config
+	caps   *Capabilities
 }
 
 // NewRuleBase creates a new RuleBase instance. It should be embedded to your own
@@ -53,6 +54,32 @@ func (r *RuleBase) Errorf(pos *Pos, format string, args ...interface{}) {
 	r.errs = append(r.errs, err)
 }
 
+// FileErrorf reports a new error in the file at the path with the source position and the
+// formatted error message. This method is used by rules implementing ProjectRule which check many
+// files at once.
+func (r *RuleBase) FileErrorf(path string, pos *Pos, format string, args ...interface{}) {
+	err := errorfAt(pos, r.name, format, args...)
+	err.Filepath = path
+	r.errs = append(r.errs, err)
+}
+
+// reportTimeout reports that the check by the external process at the position was skipped since
+// the process did not finish within the timeout. It is reported as a warning of "timeout" kind
+// instead of an error of the rule.
+func (r *RuleBase) reportTimeout(pos *Pos, err *processTimeoutError) {
+	r.errs = append(r.errs, errorfAt(pos, "timeout", "%s while checking this script. the check by %q rule was skipped. increase the timeout with -process-timeout option", err, r.name))
+}
+
+// Suggest attaches the machine-applicable fix to the last error reported by the rule. When no error
+// was reported yet, this method does nothing.
+func (r *RuleBase) Suggest(s *Suggestion) {
+	if len(r.errs) == 0 {
+		return
+	}
+	err := r.errs[len(r.errs)-1]
+	err.Suggestions = append(err.Suggestions, s)
+}
+
 // Debug prints debug log to the output. The output is specified by the argument of EnableDebug method.
 // By default, no output is set so debug log is not printed.
 func (r *RuleBase) Debug(format string, args ...interface{}) {
@@ -96,6 +123,21 @@ func (r *RuleBase) Config() *Config {
 	return r.config
 }
 
+// SetCapabilities populates services which the rule can use while checking workflows. See
+// Capabilities document for more details.
+func (r *RuleBase) SetCapabilities(s *Capabilities) {
+	r.caps = s
+}
+
+// Capabilities returns the services set by SetCapabilities. When no services were set to this rule,
+// this method returns an empty instance where no service is available.
+func (r *RuleBase) Capabilities() *Capabilities {
+	if r.caps == nil {
+		return &Capabilities{}
+	}
+	return r.caps
+}
+
 // Rule is an interface which all rule structs must meet.
 type Rule interface {
 	Pass
@@ -106,3 +148,33 @@ type Rule interface {
 	SetConfig(cfg *Config)
 	Config() *Config
 }
+
+// setRuleCapabilities populates the services to the rule when the rule can use them. Using the
+// services is optional for rules so SetCapabilities method is not a part of Rule interface. Rules
+// embedding RuleBase implement the method.
+func setRuleCapabilities(r Rule, s *Capabilities) {
+	if r, ok := r.(interface{ SetCapabilities(*Capabilities) }); ok {
+		r.SetCapabilities(s)
+	}
+}
+
+// ProjectFile is a workflow file passed to rules implementing ProjectRule.
+type ProjectFile struct {
+	// Path is a file path of the workflow file. It is the same as Filepath field of errors.
+	Path string
+	// Workflow is a syntax tree of the workflow.
+	Workflow *Workflow
+}
+
+// ProjectRule is an interface for rules which check all workflow files in a project at once. The
+// linter checks workflows in two phases. In the first phase, each workflow file is parsed and
+// checked by rules in parallel. In the second phase, rules implementing this interface check all
+// parsed workflows in the same project to find problems across files. Rules in the second phase
+// also run in parallel. Methods of Pass interface are not called for the rules. Errors must be
+// reported with FileErrorf method of RuleBase so that the file paths are set.
+type ProjectRule interface {
+	Rule
+	// VisitProject is called with all workflow files in the project which were parsed successfully.
+	// The files are sorted by their paths.
+	VisitProject(files []*ProjectFile) error
+}
@@ -3,9 +3,9 @@ package actionlint
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -295,24 +295,80 @@ func isImageOnDockerRegistry(image string) bool {
 		strings.HasPrefix(image, "docker.io/")
 }
 
+// Reference of Docker image like "ghcr.io/owner/image:tag@sha256:...".
+// https://github.com/distribution/reference/blob/main/regexp.go
+var reDockerImageRef = regexp.MustCompile(
+	`^(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` + // Registry
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` + // Name
+		`(?::[\w][\w.-]{0,127})?` + // Tag
+		`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9A-Fa-f]{32,})?$`, // Digest
+)
+
+var reDockerActionInputRef = regexp.MustCompile(`\$\{\{[^}]*\binputs\.([A-Za-z_][A-Za-z0-9_-]*)`)
+
+// splitDockerImageRef splits the reference of Docker image into name, tag, and digest.
+func splitDockerImageRef(ref string) (string, string, string) {
+	name, digest, _ := strings.Cut(ref, "@")
+	tag := ""
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
 // RuleAction is a rule to check running action in steps of jobs.
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_idstepsuses
 type RuleAction struct {
 	RuleBase
-	cache *LocalActionsCache
+	// runner is a label of runs-on: of the current job when the job runs on Windows or macOS
+	runner *String
 }
 
-// NewRuleAction creates new RuleAction instance.
-func NewRuleAction(cache *LocalActionsCache) *RuleAction {
+// NewRuleAction creates new RuleAction instance. Metadata of actions is resolved with LocalActions
+// and PopularActions of the capabilities set by SetCapabilities.
+func NewRuleAction() *RuleAction {
 	return &RuleAction{
 		RuleBase: RuleBase{
 			name: "action",
 			desc: "Checks for popular actions released on GitHub, local actions, and action calls at \"uses:\"",
 		},
-		cache: cache,
 	}
 }
 
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleAction) VisitJobPre(n *Job) error {
+	rule.runner = nil
+	if n.RunsOn == nil {
+		return nil
+	}
+	for _, l := range n.RunsOn.Labels {
+		if l.ContainsExpression() {
+			continue
+		}
+		if c, ok := defaultRunnerOSCompats[strings.ToLower(l.Value)]; ok {
+			if os := runnerOSFamily(c); os == "windows" || os == "macos" {
+				rule.runner = l
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// checkDockerActionRunner checks the Docker container action runs on Linux runner.
+// https://docs.github.com/en/actions/creating-actions/about-custom-actions#docker-container-actions
+func (rule *RuleAction) checkDockerActionRunner(exec *ExecAction) {
+	if rule.runner == nil {
+		return
+	}
+	rule.Errorf(
+		exec.Uses.Pos,
+		"Docker container action %q cannot run on runner %q. Docker container actions are only supported on Linux runners",
+		exec.Uses.Value,
+		rule.runner.Value,
+	)
+}
+
 // VisitStep is callback when visiting Step node.
 func (rule *RuleAction) VisitStep(n *Step) error {
 	e, ok := n.Exec.(*ExecAction)
@@ -325,53 +381,29 @@ func (rule *RuleAction) VisitStep(n *Step) error {
 		return nil
 	}
 
-	spec := e.Uses.Value
-
-	if strings.HasPrefix(spec, "./") {
-		// Relative to repository root
-		rule.checkLocalAction(spec, e)
-		return nil
+	spec := e.Spec
+	if spec == nil {
+		s, err := ParseActionSpec(e.Uses.Value)
+		if err != nil {
+			rule.invalidActionFormat(e.Uses.Pos, e.Uses.Value, err.Error())
+			return nil
+		}
+		spec = s
 	}
 
-	if strings.HasPrefix(spec, "docker://") {
-		rule.checkDockerAction(spec, e)
-		return nil
+	switch spec.Kind {
+	case ActionSpecKindLocal:
+		rule.checkLocalAction(spec.Path, e)
+	case ActionSpecKindDocker:
+		rule.checkDockerAction(spec.Image, e)
+	default:
+		rule.checkRepoAction(e.Uses.Value, e)
 	}
-
-	rule.checkRepoAction(spec, e)
 	return nil
 }
 
-// Parse {owner}/{repo}@{ref} or {owner}/{repo}/{path}@{ref}
 func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
-	s := spec
-	idx := strings.IndexRune(s, '@')
-	if idx == -1 {
-		rule.invalidActionFormat(exec.Uses.Pos, spec, "ref is missing")
-		return
-	}
-	ref := s[idx+1:]
-	s = s[:idx] // remove {ref}
-
-	idx = strings.IndexRune(s, '/')
-	if idx == -1 {
-		rule.invalidActionFormat(exec.Uses.Pos, spec, "owner is missing")
-		return
-	}
-
-	owner := s[:idx]
-	s = s[idx+1:] // eat {owner}
-
-	repo := s
-	if idx := strings.IndexRune(s, '/'); idx >= 0 {
-		repo = s[:idx]
-	}
-
-	if owner == "" || repo == "" || ref == "" {
-		rule.invalidActionFormat(exec.Uses.Pos, spec, "owner and repo and ref should not be empty")
-	}
-
-	meta, ok := PopularActions[spec]
+	meta, ok := rule.Capabilities().FindPopularAction(spec)
 	if !ok {
 		if _, ok := OutdatedPopularActionSpecs[spec]; ok {
 			rule.Errorf(exec.Uses.Pos, "the runner of %q action is too old to run on GitHub Actions. update the action's version to fix this issue", spec)
@@ -391,6 +423,10 @@ func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
 }
 
 func (rule *RuleAction) invalidActionFormat(pos *Pos, spec string, why string) {
+	if strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "docker://") {
+		rule.Errorf(pos, "specifying action %q in invalid format because %s", spec, why)
+		return
+	}
 	rule.Errorf(pos, "specifying action %q in invalid format because %s. available formats are \"{owner}/{repo}@{ref}\" or \"{owner}/{repo}/{path}@{ref}\"", spec, why)
 }
 
@@ -434,7 +470,11 @@ func (rule *RuleAction) checkRunsFileExists(file, dir, prop, name string, pos *P
 func (rule *RuleAction) checkLocalDockerActionRuns(r *ActionMetadataRuns, dir, name string, pos *Pos) {
 	if r.Image == "" {
 		rule.missingRunsProp(pos, "image", "Docker", name, dir)
-	} else if !isImageOnDockerRegistry(r.Image) {
+	} else if isImageOnDockerRegistry(r.Image) {
+		if ref := strings.TrimPrefix(r.Image, "docker://"); !reDockerImageRef.MatchString(ref) {
+			rule.Errorf(pos, `reference of Docker image %q at "image" key in "runs" section in %q action is invalid. it must be in the format of "[registry/]name[:tag][@digest]" and the name must be in lower case. the action is defined at %q`, ref, name, dir)
+		}
+	} else {
 		rule.checkRunsFileExists(r.Image, dir, "image", name, pos)
 		if filepath.Base(filepath.FromSlash(r.Image)) != "Dockerfile" {
 			rule.Errorf(pos, `the local file %q referenced from "image" key must be named "Dockerfile" in %q action. the action is defined at %q`, r.Image, name, dir)
@@ -446,6 +486,42 @@ func (rule *RuleAction) checkLocalDockerActionRuns(r *ActionMetadataRuns, dir, n
 	rule.checkInvalidRunsProps(pos, r, "Docker", name, dir, []string{"main", "pre", "pre-if", "post", "post-if", "steps"})
 }
 
+// checkLocalDockerActionInputs checks inputs referenced at "args" and "env" of Docker action are
+// defined in "inputs" section.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runsargs
+func (rule *RuleAction) checkLocalDockerActionInputs(meta *ActionMetadata, pos *Pos) {
+	check := func(prop string, v any) {
+		s, ok := v.(string)
+		if !ok {
+			return
+		}
+		for _, m := range reDockerActionInputRef.FindAllStringSubmatch(s, -1) {
+			if _, ok := meta.Inputs[strings.ToLower(m[1])]; ok {
+				continue
+			}
+			ns := make([]string, 0, len(meta.Inputs))
+			for _, i := range meta.Inputs {
+				ns = append(ns, i.Name)
+			}
+			rule.Errorf(
+				pos,
+				"input %q referenced at %q in \"runs\" section is not defined in %q action. available inputs are %s. the action is defined at %q",
+				m[1],
+				prop,
+				meta.Name,
+				sortedQuotes(ns),
+				meta.Dir(),
+			)
+		}
+	}
+	for _, a := range meta.Runs.Args {
+		check("args", a)
+	}
+	for k, v := range meta.Runs.Env {
+		check("env."+k, v)
+	}
+}
+
 // https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs-for-composite-actions
 func (rule *RuleAction) checkLocalCompositeActionRuns(r *ActionMetadataRuns, dir, name string, pos *Pos) {
 	if r.Steps == nil {
@@ -454,6 +530,43 @@ func (rule *RuleAction) checkLocalCompositeActionRuns(r *ActionMetadataRuns, dir
 	rule.checkInvalidRunsProps(pos, r, "Composite", name, dir, []string{"main", "pre", "pre-if", "post", "post-if", "image", "pre-entrypoint", "entrypoint", "post-entrypoint", "args", "env"})
 }
 
+// checkRunsCondition checks the condition at "pre-if" or "post-if" in "runs" section of JavaScript
+// action. The condition is evaluated in the same way as "if:" of steps so the same contexts and
+// special functions are available. Errors are reported at "uses:" of the step since positions in
+// action metadata are not available.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runspre-if
+func (rule *RuleAction) checkRunsCondition(cond, prop, dir, name string, pos *Pos) {
+	src := strings.TrimSpace(cond)
+	if src == "" {
+		return
+	}
+	if strings.HasPrefix(src, "${{") && strings.HasSuffix(src, "}}") {
+		src = strings.TrimSpace(src[3 : len(src)-2])
+	}
+
+	report := func(msg string) {
+		rule.Errorf(pos, `condition %q at %q in "runs" section in %q action is invalid: %s. the action is defined at %q`, cond, prop, name, msg, dir)
+	}
+
+	expr, err := NewExprParser().Parse(NewExprLexer(src + "}}")) // }} is necessary since lexer lexes it as end of tokens
+	if err != nil {
+		report(err.Message)
+		return
+	}
+
+	c := NewExprSemanticsChecker(false, nil)
+	ctx, sp := WorkflowKeyAvailability("jobs.<job_id>.steps.if")
+	c.SetContextAvailability(ctx)
+	c.SetSpecialFunctionAvailability(sp)
+	ty, errs := c.Check(expr)
+	for _, err := range errs {
+		report(err.Message)
+	}
+	if len(errs) == 0 && !(BoolType{}).Assignable(ty) {
+		report(fmt.Sprintf("condition should be type \"bool\" but got type %q", ty.String()))
+	}
+}
+
 // https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs-for-javascript-actions
 func (rule *RuleAction) checkLocalJavaScriptActionRuns(r *ActionMetadataRuns, dir, name string, pos *Pos) {
 	if r.Main == "" {
@@ -466,11 +579,13 @@ func (rule *RuleAction) checkLocalJavaScriptActionRuns(r *ActionMetadataRuns, di
 	if r.Pre == "" && r.PreIf != "" {
 		rule.Errorf(pos, `"pre" is required when "pre-if" is specified in "runs" section in %q action. the action is defined at %q`, name, dir)
 	}
+	rule.checkRunsCondition(r.PreIf, "pre-if", dir, name, pos)
 
 	rule.checkRunsFileExists(r.Post, dir, "post", name, pos)
 	if r.Post == "" && r.PostIf != "" {
 		rule.Errorf(pos, `"post" is required when "post-if" is specified in "runs" section in %q action. the action is defined at %q`, name, dir)
 	}
+	rule.checkRunsCondition(r.PostIf, "post-if", dir, name, pos)
 
 	rule.checkInvalidRunsProps(pos, r, "JavaScript", name, dir, []string{"steps", "image", "pre-entrypoint", "entrypoint", "post-entrypoint", "args", "env"})
 }
@@ -482,12 +597,18 @@ func (rule *RuleAction) checkLocalActionRuns(meta *ActionMetadata, pos *Pos) {
 		rule.Errorf(pos, `"runs.using" is missing in local action %q defined at %q`, meta.Name, meta.Dir())
 	case "docker":
 		rule.checkLocalDockerActionRuns(r, meta.Dir(), meta.Name, pos)
+		rule.checkLocalDockerActionInputs(meta, pos)
 	case "composite":
 		rule.checkLocalCompositeActionRuns(r, meta.Dir(), meta.Name, pos)
-	case "node20":
+	case "node20", "node24":
+		rule.checkLocalJavaScriptActionRuns(r, meta.Dir(), meta.Name, pos)
+	case "node12", "node16":
+		// These runners were removed from GitHub Actions. Actions using them are forced to run on newer Node.js
+		// https://github.blog/changelog/2024-09-25-end-of-life-for-actions-node16/
+		rule.Errorf(pos, `runner %q at runs.using in %q action defined at %q is deprecated and no longer supported by GitHub Actions. use "node20" or "node24" instead. see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs-for-javascript-actions`, r.Using, meta.Name, meta.Dir())
 		rule.checkLocalJavaScriptActionRuns(r, meta.Dir(), meta.Name, pos)
 	default:
-		rule.Errorf(pos, `invalid runner name %q at runs.using in %q action defined at %q. valid runners are "composite", "docker", and "node20". see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs`, r.Using, meta.Name, meta.Dir())
+		rule.Errorf(pos, `invalid runner name %q at runs.using in %q action defined at %q. valid runners are "composite", "docker", "node20", and "node24". see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs`, r.Using, meta.Name, meta.Dir())
 
 		// Probably invalid version of Node.js runner. Assume it is JavaScript action to find as many errors as possible
 		if strings.HasPrefix(r.Using, "node") {
@@ -497,30 +618,42 @@ func (rule *RuleAction) checkLocalActionRuns(meta *ActionMetadata, pos *Pos) {
 }
 
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#example-using-the-github-packages-container-registry
-func (rule *RuleAction) checkDockerAction(uri string, exec *ExecAction) {
-	tag := ""
-	tagExists := false
-	if idx := strings.IndexRune(uri[len("docker://"):], ':'); idx != -1 {
-		idx += len("docker://")
-		if idx < len(uri) {
-			tag = uri[idx+1:]
-			uri = uri[:idx]
-			tagExists = true
-		}
+func (rule *RuleAction) checkDockerAction(ref string, exec *ExecAction) {
+	rule.checkDockerActionRunner(exec)
+
+	if strings.HasSuffix(ref, ":") {
+		rule.Errorf(exec.Uses.Pos, "tag of Docker action should not be empty: %q", "docker://"+strings.TrimSuffix(ref, ":"))
+		return
 	}
 
-	if _, err := url.Parse(uri); err != nil {
+	if !reDockerImageRef.MatchString(ref) {
 		rule.Errorf(
 			exec.Uses.Pos,
-			"URI for Docker container %q is invalid: %s (tag=%s)",
-			uri,
-			err.Error(),
-			tag,
+			"reference of Docker image %q is invalid. it must be in the format of \"[registry/]name[:tag][@digest]\" and the name must be in lower case",
+			ref,
 		)
+		return
 	}
 
-	if tagExists && tag == "" {
-		rule.Errorf(exec.Uses.Pos, "tag of Docker action should not be empty: %q", uri)
+	name, tag, digest := splitDockerImageRef(ref)
+	if digest != "" {
+		return
+	}
+	switch tag {
+	case "":
+		rule.Errorf(
+			exec.Uses.Pos,
+			"tag of Docker image %q is omitted so \"latest\" tag is used. the image may be changed unexpectedly. pin the image with a specific version tag or digest like \"docker://%s:1.2.3\"",
+			ref,
+			name,
+		)
+	case "latest":
+		rule.Errorf(
+			exec.Uses.Pos,
+			"Docker image %q uses \"latest\" tag. the image may be changed unexpectedly. pin the image with a specific version tag or digest like \"docker://%s:1.2.3\"",
+			ref,
+			name,
+		)
 	}
 }
 
@@ -559,7 +692,12 @@ func (rule *RuleAction) checkLocalActionMetadata(meta *ActionMetadata, action *E
 
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#example-using-action-in-the-same-repository-as-the-workflow
 func (rule *RuleAction) checkLocalAction(spec string, action *ExecAction) {
-	meta, cached, err := rule.cache.FindMetadata(spec)
+	c := rule.Capabilities().LocalActions
+	if c == nil {
+		rule.Debug("Skip checking local action %q since LocalActions of the capabilities is not available", spec)
+		return
+	}
+	meta, cached, err := c.FindMetadata(spec)
 	if err != nil {
 		rule.Error(action.Uses.Pos, err.Error())
 		return
@@ -572,6 +710,9 @@ func (rule *RuleAction) checkLocalAction(spec string, action *ExecAction) {
 		rule.Debug("Checking metadata of %s action %q at %q", meta.Runs, meta.Name, spec)
 		rule.checkLocalActionMetadata(meta, action)
 	}
+	if meta.Runs.Using == "docker" {
+		rule.checkDockerActionRunner(action)
+	}
 
 	rule.checkAction(meta, action, func(m *ActionMetadata) string {
 		return fmt.Sprintf("%q defined at %q", m.Name, spec)
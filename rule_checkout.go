@@ -0,0 +1,117 @@
+package actionlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Command referring a file in the repository with relative path like "./build.sh" or "bash ./build.sh".
+var reCheckoutRunRepoFile = regexp.MustCompile(`(?m)^\s*(?:(?:bash|sh|zsh|pwsh|source|\.|python3?|node|ruby|perl)\s+)?(\./[\w./-]+)`)
+
+// Commands which may put files in the workspace without actions/checkout.
+var reCheckoutRunPopulate = regexp.MustCompile(`\b(?:git\s+(?:clone|init|fetch|pull|checkout|worktree)|gh\s+repo\s+clone|curl|wget|tar|unzip|mkdir|cp|mv|touch|tee|rsync)\b|>\s*\./`)
+
+// RuleCheckout is a rule to check steps refer files in the repository after the repository is
+// checked out by actions/checkout in the job. Beginners often forget that the workspace is empty
+// until the repository is checked out. This rule is heuristic. Steps after a step which may put
+// files in the workspace (e.g. downloading artifacts) are not checked. Each check is enabled by the
+// "checkout" configuration in the "rules" section of the configuration file.
+type RuleCheckout struct {
+	RuleBase
+}
+
+// NewRuleCheckout creates a new RuleCheckout instance.
+func NewRuleCheckout() *RuleCheckout {
+	return &RuleCheckout{
+		RuleBase: RuleBase{
+			name: "checkout",
+			desc: "Checks for steps referring files in the repository before the repository is checked out",
+		},
+	}
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleCheckout) VisitJobPre(n *Job) error {
+	if rule.config == nil {
+		return nil
+	}
+	c := &rule.config.Rules.Checkout
+	if !c.RunScripts && !c.LocalActions && !c.VersionFiles {
+		return nil
+	}
+
+	for _, s := range n.Steps {
+		if !rule.checkStep(s, c) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// checkStep checks the step does not refer files in the repository. It returns false when the
+// workspace may be populated by the step so that the following steps should not be checked.
+func (rule *RuleCheckout) checkStep(s *Step, c *CheckoutRuleConfig) bool {
+	switch e := s.Exec.(type) {
+	case *ExecRun:
+		if e.Run == nil || e.Run.ContainsExpression() {
+			return false
+		}
+		if reCheckoutRunPopulate.MatchString(e.Run.Value) {
+			return false
+		}
+		if m := reCheckoutRunRepoFile.FindStringSubmatch(e.Run.Value); m != nil && c.RunScripts {
+			rule.Errorf(
+				e.Run.Pos,
+				"script at \"run:\" refers to %q in the repository but the repository is not checked out yet in this job. add \"actions/checkout\" step before this step",
+				m[1],
+			)
+		}
+		return true
+	case *ExecAction:
+		spec := e.Spec
+		if spec == nil {
+			return false // ${{ }} or invalid format
+		}
+
+		switch spec.Kind {
+		case ActionSpecKindLocal:
+			if !c.LocalActions {
+				return true
+			}
+			rule.Errorf(
+				e.Uses.Pos,
+				"local action %q is used but the repository is not checked out yet in this job. the action cannot be found until \"actions/checkout\" step runs",
+				e.Uses.Value,
+			)
+			return true
+		case ActionSpecKindDocker:
+			return true
+		}
+
+		if strings.Contains(strings.ToLower(spec.Repo), "checkout") {
+			return false // actions/checkout or a wrapper of it
+		}
+		if !strings.HasPrefix(strings.ToLower(spec.Repo), "setup-") {
+			return false // Unknown action may put files in the workspace (e.g. actions/download-artifact)
+		}
+
+		if !c.VersionFiles {
+			return true
+		}
+		for n, i := range e.Inputs {
+			if !strings.HasSuffix(n, "-version-file") || i.Value == nil || i.Value.Value == "" || i.Value.ContainsExpression() {
+				continue
+			}
+			rule.Errorf(
+				i.Name.Pos,
+				"input %q of action %q refers to file %q in the repository but the repository is not checked out yet in this job. add \"actions/checkout\" step before this step",
+				i.Name.Value,
+				e.Uses.Value,
+				i.Value.Value,
+			)
+		}
+		return true
+	default:
+		return false
+	}
+}
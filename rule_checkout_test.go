@@ -0,0 +1,97 @@
+package actionlint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleCheckoutConfig(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: bash ./build.sh
+      - uses: ./.github/actions/foo
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - uses: actions/checkout@v4
+      - run: ./test.sh
+`
+	all := []string{
+		`script at "run:" refers to "./build.sh" in the repository but the repository is not checked out yet in this job. add "actions/checkout" step before this step`,
+		`local action "./.github/actions/foo" is used but the repository is not checked out yet in this job. the action cannot be found until "actions/checkout" step runs`,
+		`input "go-version-file" of action "actions/setup-go@v5" refers to file "go.mod" in the repository but the repository is not checked out yet in this job. add "actions/checkout" step before this step`,
+	}
+
+	testCases := []struct {
+		what string
+		cfg  string
+		want []string
+	}{
+		{"disabled", "", []string{}},
+		{"all", "run-scripts: true\n    local-actions: true\n    version-files: true", all},
+		{"run-scripts", "run-scripts: true", all[:1]},
+		{"local-actions", "local-actions: true", all[1:2]},
+		{"version-files", "version-files: true", all[2:]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte("rules:\n  checkout:\n    " + tc.cfg + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs := []string{}
+			for _, e := range testCheckRule(t, NewRuleCheckout(), cfg, src) {
+				msgs = append(msgs, e.Message)
+			}
+			if diff := cmp.Diff(tc.want, msgs); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	if errs := testCheckRule(t, NewRuleCheckout(), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected without config but got", errs)
+	}
+}
+
+func TestRuleCheckoutStopChecking(t *testing.T) {
+	cfg, err := ParseConfig([]byte("rules:\n  checkout:\n    run-scripts: true\n    local-actions: true\n    version-files: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		what string
+		step string
+	}{
+		{"checkout wrapper", "- uses: my-org/checkout-with-lfs@v1"},
+		{"unknown action", "- uses: actions/download-artifact@v4"},
+		{"git clone", "- run: git clone https://github.com/owner/repo ."},
+		{"download", "- run: curl -LO https://example.com/build.sh"},
+		{"redirect", "- run: echo 'echo hi' > ./build.sh"},
+		{"expression in run", "- run: echo ${{ github.sha }}"},
+		{"expression in uses", "- uses: ${{ matrix.action }}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      ` + tc.step + `
+      - run: ./build.sh
+      - uses: ./.github/actions/foo
+`
+			if errs := testCheckRule(t, NewRuleCheckout(), cfg, src); len(errs) != 0 {
+				t.Fatal("no error was expected but got", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package actionlint
+
+import (
+	"path"
+	"strings"
+)
+
+// envFileFormats is a mapping from the names of environment files to whether their lines must be
+// in the "{name}={value}" or "{name}<<{delimiter}" format.
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#environment-files
+var envFileFormats = map[string]bool{
+	"GITHUB_ENV":          true,
+	"GITHUB_OUTPUT":       true,
+	"GITHUB_STATE":        true,
+	"GITHUB_PATH":         false,
+	"GITHUB_STEP_SUMMARY": false,
+}
+
+// envFileWrite is a write to an environment file like `echo "foo=bar" >> "$GITHUB_OUTPUT"` in a
+// script.
+type envFileWrite struct {
+	file string
+	// content is the content written to the file. Its dynamic parts are surrounded by the markers
+	// of shell words.
+	content string
+	// known is false when the content cannot be known statically.
+	known  bool
+	offset int
+}
+
+// RuleEnvFile is a rule to check writes to environment files like $GITHUB_OUTPUT and $GITHUB_ENV in
+// scripts at "run:". Lines written to the files must be in the "{name}={value}" format or the
+// "{name}<<{delimiter}" format for multi-line values. Otherwise the step fails or the outputs are
+// broken at runtime.
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#environment-files
+type RuleEnvFile struct {
+	RuleBase
+	lines         sourceLines
+	workflowShell string
+	jobShell      string
+	runnerShell   string
+}
+
+// NewRuleEnvFile creates a new RuleEnvFile instance. The src parameter is the source file of the
+// workflow to report errors at precise positions in scripts. It can be nil.
+func NewRuleEnvFile(src *SourceFile) *RuleEnvFile {
+	var lines sourceLines
+	if src != nil {
+		lines = src.sourceLines()
+	}
+	return &RuleEnvFile{
+		RuleBase: RuleBase{
+			name: "env-file",
+			desc: "Checks for writes to environment files like $GITHUB_OUTPUT and $GITHUB_ENV in \"run:\"",
+		},
+		lines: lines,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleEnvFile) VisitWorkflowPre(n *Workflow) error {
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.workflowShell = n.Defaults.Run.Shell.Value
+	}
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleEnvFile) VisitJobPre(n *Job) error {
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.jobShell = n.Defaults.Run.Shell.Value
+	}
+	if n.RunsOn != nil {
+		for _, label := range n.RunsOn.Labels {
+			l := strings.ToLower(label.Value)
+			if l == "windows" || strings.HasPrefix(l, "windows-") {
+				rule.runnerShell = "pwsh"
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// VisitJobPost is callback when visiting Job node after visiting its children.
+func (rule *RuleEnvFile) VisitJobPost(n *Job) error {
+	rule.jobShell = ""
+	rule.runnerShell = ""
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleEnvFile) VisitStep(n *Step) error {
+	run, ok := n.Exec.(*ExecRun)
+	if !ok || run.Run == nil || !rule.isPOSIXShell(run) {
+		return nil
+	}
+
+	src := run.Run.Value
+	if !strings.Contains(src, "GITHUB_") {
+		return nil
+	}
+	loc := newScriptLocation(rule.lines, run.Run, src, run.RunPos)
+
+	ws := rule.collectWrites(parseShellScript(src), loc)
+	checked := map[string]struct{}{}
+	for _, w := range ws {
+		if _, ok := checked[w.file]; ok || !envFileFormats[w.file] {
+			continue
+		}
+		checked[w.file] = struct{}{}
+		rule.checkFormat(w.file, ws, loc)
+	}
+
+	return nil
+}
+
+func (rule *RuleEnvFile) isPOSIXShell(run *ExecRun) bool {
+	shell := "bash"
+	switch {
+	case run.Shell != nil:
+		shell = run.Shell.Value
+	case rule.jobShell != "":
+		shell = rule.jobShell
+	case rule.workflowShell != "":
+		shell = rule.workflowShell
+	case rule.runnerShell != "":
+		shell = rule.runnerShell
+	}
+	if i := strings.IndexAny(shell, " \t"); i >= 0 {
+		shell = shell[:i]
+	}
+	switch path.Base(shell) {
+	case "bash", "sh":
+		return true
+	default:
+		return false
+	}
+}
+
+// envFileOf returns the name of environment file when the word is a reference to it like
+// "$GITHUB_OUTPUT".
+func envFileOf(w *shellWord) (string, bool) {
+	v := w.value
+	if len(v) < 3 || v[0] != shellDynamicStart || v[len(v)-1] != shellDynamicEnd || v[1] != '$' {
+		return "", false
+	}
+	n := v[2 : len(v)-1]
+	if _, ok := envFileFormats[n]; !ok {
+		return "", false
+	}
+	return n, true
+}
+
+// collectWrites collects writes to environment files in the statements in order.
+func (rule *RuleEnvFile) collectWrites(stmts []*shellStatement, loc *scriptLocation) []*envFileWrite {
+	ws := []*envFileWrite{}
+
+	type output struct {
+		content string
+		known   bool
+		offset  int
+	}
+	// Outputs of commands in group commands like `{ echo foo; echo bar; } >> "$GITHUB_OUTPUT"`
+	groups := [][]output{}
+
+	for _, s := range stmts {
+		for i, c := range s.cmds {
+			files := rule.redirectedFiles(c, loc)
+			// `echo foo | tee -a "$GITHUB_OUTPUT"`
+			if c.name() == "tee" {
+				for _, a := range c.words[1:] {
+					if f, ok := envFileOf(a); ok {
+						rule.checkQuoted(f, a, loc)
+						files = append(files, f)
+					}
+				}
+				if len(files) > 0 {
+					content, known := "", false
+					if i == 1 {
+						content, known = s.cmds[0].output()
+					}
+					for _, f := range files {
+						ws = append(ws, &envFileWrite{f, content, known, c.offset})
+					}
+				}
+				continue
+			}
+
+			if c.closeGroup && len(groups) > 0 {
+				outs := groups[len(groups)-1]
+				groups = groups[:len(groups)-1]
+				for _, f := range files {
+					for _, o := range outs {
+						ws = append(ws, &envFileWrite{f, o.content, o.known, o.offset})
+					}
+				}
+				if len(files) == 0 && len(groups) > 0 {
+					groups[len(groups)-1] = append(groups[len(groups)-1], outs...)
+				}
+				continue
+			}
+			if c.openGroup {
+				groups = append(groups, []output{})
+			}
+
+			if len(c.words) == 0 && !c.hasHeredoc {
+				continue
+			}
+			content, known := c.output()
+			if i < len(s.cmds)-1 {
+				continue // Output is piped to the next command
+			}
+			if len(files) > 0 {
+				for _, f := range files {
+					ws = append(ws, &envFileWrite{f, content, known, c.offset})
+				}
+				continue
+			}
+			if len(groups) > 0 && !rule.hasStdoutRedirect(c) {
+				g := len(groups) - 1
+				groups[g] = append(groups[g], output{content, known, c.offset})
+			}
+		}
+	}
+
+	return ws
+}
+
+// redirectedFiles returns the names of environment files which the stdout of the command is
+// redirected to. It also checks the redirections are quoted.
+func (rule *RuleEnvFile) redirectedFiles(c *shellCommand, loc *scriptLocation) []string {
+	var fs []string
+	for _, r := range c.redirects {
+		switch r.op {
+		case ">", ">>", ">|", "&>", "&>>":
+		default:
+			continue
+		}
+		if r.fd != "" && r.fd != "1" {
+			continue
+		}
+		f, ok := envFileOf(r.target)
+		if !ok {
+			continue
+		}
+		rule.checkQuoted(f, r.target, loc)
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func (rule *RuleEnvFile) hasStdoutRedirect(c *shellCommand) bool {
+	for _, r := range c.redirects {
+		switch r.op {
+		case ">", ">>", ">|", "&>", "&>>", ">&":
+			if r.fd == "" || r.fd == "1" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (rule *RuleEnvFile) checkQuoted(file string, w *shellWord, loc *scriptLocation) {
+	if w.quoted || rule.config == nil || !rule.config.Rules.EnvFile.UnquotedRedirects {
+		return
+	}
+	rule.Errorf(
+		loc.atOffset(w.offset),
+		"$%s is not quoted. quote it like \"$%s\" to prevent word splitting and globbing of the file path",
+		file,
+		file,
+	)
+}
+
+// checkFormat checks the lines written to the environment file are in the "{name}={value}" format
+// or the "{name}<<{delimiter}" format. The lines are checked as the runner parses the file.
+// https://github.com/actions/runner/blob/main/src/Runner.Worker/FileCommandManager.cs
+func (rule *RuleEnvFile) checkFormat(file string, ws []*envFileWrite, loc *scriptLocation) {
+	// Delimiters may be written in multiple branches of `if` statement. Remember them not to report
+	// the second one as invalid line.
+	delims := map[string]struct{}{}
+
+	var (
+		partial   string        // Line which is not terminated with newline yet
+		delim     string        // Delimiter of the current multi-line value
+		open      *envFileWrite // Write which started the current multi-line value
+		openLine  string
+		inValue   bool
+		lastValue string        // Name of the last "{name}={value}" line
+		lastWrite *envFileWrite // Write of the last "{name}={value}" line
+	)
+
+	for _, w := range ws {
+		if w.file != file {
+			continue
+		}
+		if !w.known {
+			if inValue {
+				partial = ""
+				continue // Content of the multi-line value
+			}
+			return // Cannot check the remaining lines
+		}
+
+		ls := strings.Split(partial+w.content, "\n")
+		partial = ls[len(ls)-1]
+		for _, l := range ls[:len(ls)-1] {
+			if inValue {
+				if l == delim {
+					inValue = false
+				}
+				continue
+			}
+			if l == "" {
+				continue // Empty lines are ignored by the runner
+			}
+
+			eq, hd := indexShellStatic(l, "="), indexShellStatic(l, "<<")
+			switch {
+			case eq >= 0 && (hd < 0 || eq < hd):
+				if eq == 0 {
+					rule.Errorf(loc.atOffset(w.offset), "name is empty at line %q written to $%s. the line must be in the \"{name}={value}\" format", displayShellValue(l), file)
+					return
+				}
+				lastValue, lastWrite = l[:eq], w
+			case hd >= 0:
+				n, d := l[:hd], l[hd+2:]
+				if n == "" || d == "" {
+					rule.Errorf(loc.atOffset(w.offset), "name or delimiter is empty at line %q written to $%s. the line must be in the \"{name}<<{delimiter}\" format", displayShellValue(l), file)
+					return
+				}
+				delim, open, openLine, inValue = d, w, l, true
+				delims[d] = struct{}{}
+				lastWrite = nil
+			default:
+				if isShellDynamic(l) {
+					return // The line may be in the correct format at runtime
+				}
+				if _, ok := delims[l]; ok {
+					continue
+				}
+				if lastWrite == w {
+					rule.Errorf(
+						loc.atOffset(w.offset),
+						"value of %q written to $%s contains newline. use the \"{name}<<{delimiter}\" format to write multi-line value. see https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#multiline-strings",
+						displayShellValue(lastValue),
+						file,
+					)
+				} else {
+					rule.Errorf(
+						loc.atOffset(w.offset),
+						"line %q written to $%s is not in the \"{name}={value}\" format nor the \"{name}<<{delimiter}\" format. the step will fail with \"Invalid format\" error at runtime",
+						displayShellValue(l),
+						file,
+					)
+				}
+				return
+			}
+		}
+	}
+
+	if !inValue {
+		return
+	}
+	note := ""
+	for _, w := range ws {
+		if w.file == file && w.known && w != open {
+			for _, l := range strings.Split(w.content, "\n") {
+				if l != delim && strings.TrimSpace(l) == strings.TrimSpace(delim) {
+					note = ". note that the delimiter must exactly match the line including whitespaces"
+					break
+				}
+			}
+		}
+	}
+	rule.Errorf(
+		loc.atOffset(open.offset),
+		"delimiter %q of multi-line value at line %q written to $%s is not written after the value. the step will fail with \"Matching delimiter not found\" error at runtime%s",
+		displayShellValue(delim),
+		displayShellValue(openLine),
+		file,
+		note,
+	)
+}
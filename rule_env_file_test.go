@@ -0,0 +1,180 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleEnvFileFormat(t *testing.T) {
+	testCases := []struct {
+		what  string
+		run   string
+		shell string
+		want  []string
+	}{
+		{
+			what: "single line value",
+			run:  `echo "foo=bar" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "dynamic line",
+			run:  "echo \"$LINE\" >> \"$GITHUB_ENV\"\necho \"foo\" >> \"$GITHUB_ENV\"",
+		},
+		{
+			what: "dynamic name",
+			run:  `echo "${NAME}=${{ inputs.value }}" >> "${GITHUB_OUTPUT}"`,
+		},
+		{
+			what: "multi-line value in group",
+			run:  "{\n  echo 'body<<EOF'\n  cat body.txt\n  echo EOF\n} >> \"$GITHUB_OUTPUT\"",
+		},
+		{
+			what: "multi-line value in one line group",
+			run:  `{ echo "body<<$D"; cat body.txt; echo "${D}"; } >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "here document",
+			run:  "cat <<-EOS >> \"$GITHUB_ENV\"\n\tA=1\n\tB<<X\n\t$B\n\tX\n\tEOS\necho C=3 >> \"$GITHUB_ENV\"",
+		},
+		{
+			what: "tee",
+			run:  `printf '%s\n' a=1 b=2 | tee -a "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "partial line",
+			run:  "echo -n 'foo=' >> \"$GITHUB_OUTPUT\"\necho bar >> \"$GITHUB_OUTPUT\"",
+		},
+		{
+			what: "delimiter in branches",
+			run:  "echo 'v<<EOF' >> \"$GITHUB_OUTPUT\"\nif true; then\n  echo EOF >> \"$GITHUB_OUTPUT\"\nelse\n  echo EOF >> \"$GITHUB_OUTPUT\"\nfi",
+		},
+		{
+			what: "unknown output",
+			run:  "jq -r '.[]' x.json >> \"$GITHUB_OUTPUT\"\necho foo >> \"$GITHUB_OUTPUT\"",
+		},
+		{
+			what: "path and summary",
+			run:  "echo \"$HOME/bin\" >> \"$GITHUB_PATH\"\necho '# Summary' >> \"$GITHUB_STEP_SUMMARY\"",
+		},
+		{
+			what:  "pwsh",
+			run:   `echo "foo" >> $env:GITHUB_OUTPUT`,
+			shell: "pwsh",
+		},
+		{
+			what: "invalid format",
+			run:  `echo "${{ github.sha }}=sha" >> "$GITHUB_ENV" && echo "sha" >> "$GITHUB_ENV"`,
+			want: []string{`line "sha" written to $GITHUB_ENV is not in the "{name}={value}" format`},
+		},
+		{
+			what: "multi-line value without delimiter",
+			run:  `echo -e "body=first\nsecond" >> "$GITHUB_OUTPUT"`,
+			want: []string{`value of "body" written to $GITHUB_OUTPUT contains newline`},
+		},
+		{
+			what: "multi-line quoted value without delimiter",
+			run:  "echo \"body=first\nsecond\" >> \"$GITHUB_OUTPUT\"",
+			want: []string{`value of "body" written to $GITHUB_OUTPUT contains newline`},
+		},
+		{
+			what: "empty name",
+			run:  `echo "=foo" >> "$GITHUB_OUTPUT"`,
+			want: []string{`name is empty at line "=foo"`},
+		},
+		{
+			what: "empty delimiter",
+			run:  `echo "body<<" >> "$GITHUB_OUTPUT"`,
+			want: []string{`name or delimiter is empty at line "body<<"`},
+		},
+		{
+			what: "delimiter not written",
+			run:  "echo \"body<<EOF\" >> \"$GITHUB_OUTPUT\"\ncat body.txt >> \"$GITHUB_OUTPUT\"",
+			want: []string{`delimiter "EOF" of multi-line value at line "body<<EOF" written to $GITHUB_OUTPUT is not written`},
+		},
+		{
+			what: "delimiter with spaces",
+			run:  "{\n  echo \"body << EOF\"\n  cat body.txt\n  echo \"EOF\"\n} >> \"$GITHUB_OUTPUT\"",
+			want: []string{`delimiter " EOF" of multi-line value`, "must exactly match the line including whitespaces"},
+		},
+		{
+			what: "dynamic delimiter not written",
+			run:  "{\n  echo \"body<<$EOF\"\n  cat body.txt\n} >> \"$GITHUB_OUTPUT\"\necho \"$EOF\"",
+			want: []string{`delimiter "$EOF" of multi-line value`},
+		},
+		{
+			what: "each file is checked",
+			run:  "echo 'a=1' >> \"$GITHUB_OUTPUT\"\necho 'a' >> \"$GITHUB_ENV\"\necho 'b' >> \"$GITHUB_STATE\"",
+			want: []string{`line "a" written to $GITHUB_ENV`, `line "b" written to $GITHUB_STATE`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var sh *String
+			if tc.shell != "" {
+				sh = &String{Value: tc.shell, Pos: &Pos{}}
+			}
+			s := &Step{Exec: &ExecRun{Run: &String{Value: tc.run, Pos: &Pos{}}, Shell: sh, RunPos: &Pos{}}}
+
+			r := NewRuleEnvFile(nil)
+			r.SetConfig(&Config{})
+			if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.VisitJobPre(&Job{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.VisitStep(s); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := r.Errs()
+			if len(tc.want) == 0 {
+				if len(errs) > 0 {
+					t.Fatal("wanted no error but got", errs)
+				}
+				return
+			}
+			msgs := make([]string, 0, len(errs))
+			for _, e := range errs {
+				msgs = append(msgs, e.Message)
+			}
+			all := strings.Join(msgs, "\n")
+			for _, w := range tc.want {
+				if !strings.Contains(all, w) {
+					t.Errorf("%q is not included in error messages: %q", w, msgs)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEnvFileUnquotedRedirects(t *testing.T) {
+	run := "echo foo=1 >> $GITHUB_OUTPUT\necho bar=2 >> \"$GITHUB_OUTPUT\"\necho baz=3 | tee -a ${GITHUB_ENV}\necho 'hi' >>$GITHUB_STEP_SUMMARY"
+	for _, enabled := range []bool{true, false} {
+		s := &Step{Exec: &ExecRun{Run: &String{Value: run, Pos: &Pos{}}, RunPos: &Pos{}}}
+		r := NewRuleEnvFile(nil)
+		cfg := &Config{}
+		cfg.Rules.EnvFile.UnquotedRedirects = enabled
+		r.SetConfig(cfg)
+		if err := r.VisitStep(s); err != nil {
+			t.Fatal(err)
+		}
+		errs := r.Errs()
+		if !enabled {
+			if len(errs) > 0 {
+				t.Fatal("wanted no error when the option is disabled but got", errs)
+			}
+			continue
+		}
+		want := []string{"$GITHUB_OUTPUT is not quoted", "$GITHUB_ENV is not quoted", "$GITHUB_STEP_SUMMARY is not quoted"}
+		if len(errs) != len(want) {
+			t.Fatalf("wanted %d errors but got %v", len(want), errs)
+		}
+		for i, e := range errs {
+			if !strings.HasPrefix(e.Message, want[i]) {
+				t.Errorf("wanted message starting with %q but got %q", want[i], e.Message)
+			}
+		}
+	}
+}
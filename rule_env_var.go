@@ -1,10 +1,105 @@
 package actionlint
 
-import "strings"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reEnvVarPOSIXName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	reEnvVarGitHubEnvName = regexp.MustCompile(`(?:^|[\s"'(])([A-Za-z_][A-Za-z0-9_]*)(?:=|<<)`)
+)
+
+// Default environment variables set by GitHub Actions which cannot be overridden. Note that only
+// variables with "GITHUB_" and "RUNNER_" prefixes are listed since they are reserved.
+// https://docs.github.com/en/actions/writing-workflows/choosing-what-your-workflow-does/store-information-in-variables#default-environment-variables
+var reservedEnvVarNames = map[string]struct{}{
+	"GITHUB_ACTION":              {},
+	"GITHUB_ACTION_PATH":         {},
+	"GITHUB_ACTION_REPOSITORY":   {},
+	"GITHUB_ACTIONS":             {},
+	"GITHUB_ACTOR":               {},
+	"GITHUB_ACTOR_ID":            {},
+	"GITHUB_API_URL":             {},
+	"GITHUB_BASE_REF":            {},
+	"GITHUB_ENV":                 {},
+	"GITHUB_EVENT_NAME":          {},
+	"GITHUB_EVENT_PATH":          {},
+	"GITHUB_GRAPHQL_URL":         {},
+	"GITHUB_HEAD_REF":            {},
+	"GITHUB_JOB":                 {},
+	"GITHUB_OUTPUT":              {},
+	"GITHUB_PATH":                {},
+	"GITHUB_REF":                 {},
+	"GITHUB_REF_NAME":            {},
+	"GITHUB_REF_PROTECTED":       {},
+	"GITHUB_REF_TYPE":            {},
+	"GITHUB_REPOSITORY":          {},
+	"GITHUB_REPOSITORY_ID":       {},
+	"GITHUB_REPOSITORY_OWNER":    {},
+	"GITHUB_REPOSITORY_OWNER_ID": {},
+	"GITHUB_RETENTION_DAYS":      {},
+	"GITHUB_RUN_ATTEMPT":         {},
+	"GITHUB_RUN_ID":              {},
+	"GITHUB_RUN_NUMBER":          {},
+	"GITHUB_SERVER_URL":          {},
+	"GITHUB_SHA":                 {},
+	"GITHUB_STATE":               {},
+	"GITHUB_STEP_SUMMARY":        {},
+	"GITHUB_TRIGGERING_ACTOR":    {},
+	"GITHUB_WORKFLOW":            {},
+	"GITHUB_WORKFLOW_REF":        {},
+	"GITHUB_WORKFLOW_SHA":        {},
+	"GITHUB_WORKSPACE":           {},
+	"RUNNER_ARCH":                {},
+	"RUNNER_ENVIRONMENT":         {},
+	"RUNNER_NAME":                {},
+	"RUNNER_OS":                  {},
+	"RUNNER_TEMP":                {},
+	"RUNNER_TOOL_CACHE":          {},
+}
+
+// Actions which are known not to set environment variables via $GITHUB_ENV.
+var actionsNotSettingEnv = []string{
+	"actions/checkout@",
+	"actions/cache@",
+	"actions/upload-artifact@",
+	"actions/download-artifact@",
+}
+
+// envVarScope is a set of environment variables defined at some level of "env:". Keys are names in
+// lower case and values are the definitions.
+type envVarScope struct {
+	vars map[string]*EnvVar
+	// dynamic is true when the variables are defined by an expression like `env: ${{ ... }}`.
+	dynamic bool
+}
+
+func newEnvVarScope(env *Env) *envVarScope {
+	s := &envVarScope{vars: map[string]*EnvVar{}}
+	if env == nil {
+		return s
+	}
+	if env.Expression != nil {
+		s.dynamic = true
+		return s
+	}
+	for k, v := range env.Vars {
+		s.vars[strings.ToLower(k)] = v
+	}
+	return s
+}
 
 // RuleEnvVar is a rule checker to check environment variables setup.
 type RuleEnvVar struct {
 	RuleBase
+	workflow *envVarScope
+	job      *envVarScope
+	// Names of environment variables in lower case set via $GITHUB_ENV in previous steps
+	exported map[string]struct{}
+	// unknown is true when environment variables in env context cannot be known statically
+	unknown bool
 }
 
 // NewRuleEnvVar creates new RuleEnvVar instance.
@@ -20,12 +115,66 @@ func NewRuleEnvVar() *RuleEnvVar {
 // VisitStep is callback when visiting Step node.
 func (rule *RuleEnvVar) VisitStep(n *Step) error {
 	rule.checkEnv(n.Env)
+	rule.checkReservedNames(n.Env)
+
+	step := newEnvVarScope(n.Env)
+	rule.checkShadowing(step, rule.job, "job")
+	rule.checkShadowing(step, rule.workflow, "workflow")
+
+	ss := []*String{n.Name, n.If}
+	switch e := n.Exec.(type) {
+	case *ExecRun:
+		ss = append(ss, e.Run, e.Shell, e.WorkingDirectory)
+	case *ExecAction:
+		for _, i := range e.Inputs {
+			ss = append(ss, i.Value)
+		}
+		ss = append(ss, e.Entrypoint, e.Args)
+	}
+	ss = appendEnvStrings(ss, n.Env)
+
+	var ws []githubEnvWrite
+	exported := true
+	if e, ok := n.Exec.(*ExecRun); ok && e.Run != nil {
+		ws, exported = parseGitHubEnvWrites(e.Run.Value)
+		rule.checkReadsInSameStep(e.Run, ws, step)
+		rule.checkEnvReferencesInSameStep(ss, ws, step)
+	}
+
+	if rule.config != nil && rule.config.Rules.EnvVar.UndefinedReferences && rule.job != nil && !rule.unknown && !step.dynamic {
+		rule.checkEnvReferences(ss, ws, step)
+	}
+
+	// Environment variables set in this step are available in later steps
+	switch e := n.Exec.(type) {
+	case *ExecRun:
+		if rule.exported != nil {
+			for _, w := range ws {
+				rule.exported[strings.ToLower(w.name)] = struct{}{}
+			}
+		}
+		if !exported {
+			rule.unknown = true
+		}
+	case *ExecAction:
+		if !rule.unknown && e.Uses != nil {
+			rule.unknown = true
+			for _, a := range actionsNotSettingEnv {
+				if strings.HasPrefix(e.Uses.Value, a) {
+					rule.unknown = false
+					break
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
 // VisitJobPre is callback when visiting Job node before visiting its children.
 func (rule *RuleEnvVar) VisitJobPre(n *Job) error {
 	rule.checkEnv(n.Env)
+	rule.checkReservedNames(n.Env)
 	if n.Container != nil {
 		rule.checkEnv(n.Container.Env)
 	}
@@ -34,12 +183,19 @@ func (rule *RuleEnvVar) VisitJobPre(n *Job) error {
 			rule.checkEnv(s.Container.Env)
 		}
 	}
+
+	rule.job = newEnvVarScope(n.Env)
+	rule.checkShadowing(rule.job, rule.workflow, "workflow")
+	rule.exported = map[string]struct{}{}
+	rule.unknown = rule.workflow == nil || rule.workflow.dynamic || rule.job.dynamic
 	return nil
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleEnvVar) VisitWorkflowPre(n *Workflow) error {
 	rule.checkEnv(n.Env)
+	rule.checkReservedNames(n.Env)
+	rule.workflow = newEnvVarScope(n.Env)
 	return nil
 }
 
@@ -57,6 +213,229 @@ func (rule *RuleEnvVar) checkEnv(env *Env) {
 				"environment variable name %q is invalid. '&', '=' and spaces should not be contained",
 				v.Name.Value,
 			)
+			continue
+		}
+		if rule.config != nil && rule.config.Rules.EnvVar.POSIXNames && !reEnvVarPOSIXName.MatchString(v.Name.Value) {
+			rule.Errorf(
+				v.Name.Pos,
+				"environment variable name %q is not a valid POSIX name. the name should consist of alphabets, digits, and underscores and should not start with a digit. shells cannot refer such variables",
+				v.Name.Value,
+			)
+		}
+	}
+}
+
+// checkReservedNames checks names of environment variables at "env:" of workflow, job, and step.
+// Note that "env:" of containers is not checked since it is for processes in the containers.
+func (rule *RuleEnvVar) checkReservedNames(env *Env) {
+	if env == nil || env.Expression != nil {
+		return
+	}
+	for _, v := range env.Vars {
+		if _, ok := reservedEnvVarNames[strings.ToUpper(v.Name.Value)]; ok {
+			rule.Errorf(
+				v.Name.Pos,
+				"environment variable %q is set by GitHub Actions by default and cannot be overridden. names with \"GITHUB_\" and \"RUNNER_\" prefixes are reserved. use other name",
+				v.Name.Value,
+			)
+		}
+	}
+}
+
+// literalEnvValueType returns the type of the literal value of environment variable. All values
+// of environment variables are strings, but numbers and booleans in YAML are converted to strings.
+func literalEnvValueType(v *String) (string, bool) {
+	if v == nil || v.ContainsExpression() {
+		return "", false
+	}
+	if v.Quoted {
+		return "string", true
+	}
+	switch v.Value {
+	case "true", "false":
+		return "boolean", true
+	}
+	if _, err := strconv.ParseFloat(v.Value, 64); err == nil {
+		return "number", true
+	}
+	return "string", true
+}
+
+func (rule *RuleEnvVar) checkShadowing(inner, outer *envVarScope, where string) {
+	if rule.config == nil || !rule.config.Rules.EnvVar.Shadowing || outer == nil {
+		return
+	}
+	for k, i := range inner.vars {
+		o, ok := outer.vars[k]
+		if !ok {
+			continue
+		}
+		it, ok := literalEnvValueType(i.Value)
+		if !ok {
+			continue
+		}
+		ot, ok := literalEnvValueType(o.Value)
+		if !ok || it == ot {
+			continue
+		}
+		rule.Errorf(
+			i.Name.Pos,
+			"environment variable %q shadows the variable defined at %s level with different type of value. %s value %q is overridden with %s value %q",
+			i.Name.Value,
+			where,
+			ot,
+			o.Value.Value,
+			it,
+			i.Value.Value,
+		)
+	}
+}
+
+func (rule *RuleEnvVar) isDefined(name string, step *envVarScope) bool {
+	name = strings.ToLower(name)
+	for _, s := range []*envVarScope{step, rule.job, rule.workflow} {
+		if s == nil {
+			continue
+		}
+		if _, ok := s.vars[name]; ok {
+			return true
+		}
+	}
+	_, ok := rule.exported[name]
+	return ok
+}
+
+func (rule *RuleEnvVar) checkEnvReferences(ss []*String, ws []githubEnvWrite, step *envVarScope) {
+	for _, s := range ss {
+		if s == nil || !s.ContainsExpression() {
+			continue
+		}
+		visitExprNodesInString(s, func(n ExprNode, pos *Pos) {
+			name, ok := envContextReference(n)
+			if !ok || rule.isDefined(name, step) {
+				return
+			}
+			for _, w := range ws {
+				if strings.EqualFold(w.name, name) {
+					return // Reported by checkEnvReferencesInSameStep
+				}
+			}
+			rule.Errorf(
+				pos,
+				"environment variable %q is not defined in this scope. env context only contains variables defined at \"env:\" of the workflow, the job, and the step, and variables set via $GITHUB_ENV in previous steps",
+				name,
+			)
+		})
+	}
+}
+
+// envContextReference returns the name of environment variable when the node is a reference to
+// env context like `env.FOO` or `env['FOO']`.
+func envContextReference(n ExprNode) (string, bool) {
+	var recv ExprNode
+	var name string
+	switch n := n.(type) {
+	case *ObjectDerefNode:
+		recv, name = n.Receiver, n.Property
+	case *IndexAccessNode:
+		i, ok := n.Index.(*StringNode)
+		if !ok {
+			return "", false
+		}
+		recv, name = n.Operand, i.Value
+	default:
+		return "", false
+	}
+	if v, ok := recv.(*VariableNode); !ok || v.Name != "env" {
+		return "", false
+	}
+	return name, true
+}
+
+// githubEnvWrite is a write of an environment variable via $GITHUB_ENV in a script.
+type githubEnvWrite struct {
+	name string
+	// line is a 0-based line index of the write in the script.
+	line int
+}
+
+// parseGitHubEnvWrites parses writes of environment variables via $GITHUB_ENV in the script like
+// `echo "FOO=bar" >> "$GITHUB_ENV"`. The second return value is false when some variable names
+// cannot be known statically like `cat vars.txt >> "$GITHUB_ENV"`.
+func parseGitHubEnvWrites(script string) ([]githubEnvWrite, bool) {
+	var ws []githubEnvWrite
+	for i, line := range strings.Split(script, "\n") {
+		if !strings.Contains(line, "GITHUB_ENV") {
+			continue
+		}
+		ms := reEnvVarGitHubEnvName.FindAllStringSubmatch(line, -1)
+		if len(ms) == 0 {
+			return ws, false
+		}
+		for _, m := range ms {
+			ws = append(ws, githubEnvWrite{m[1], i})
+		}
+	}
+	return ws, true
+}
+
+// checkEnvReferencesInSameStep checks environment variables set via $GITHUB_ENV are referenced via
+// env context in the same step. ${{ }} placeholders are evaluated before the step runs.
+func (rule *RuleEnvVar) checkEnvReferencesInSameStep(ss []*String, ws []githubEnvWrite, step *envVarScope) {
+	if len(ws) == 0 {
+		return
+	}
+	for _, s := range ss {
+		if s == nil || !s.ContainsExpression() {
+			continue
+		}
+		visitExprNodesInString(s, func(n ExprNode, pos *Pos) {
+			name, ok := envContextReference(n)
+			if !ok || rule.isDefined(name, step) {
+				return
+			}
+			for _, w := range ws {
+				if strings.EqualFold(w.name, name) {
+					rule.Errorf(
+						pos,
+						"environment variable %q is set via $GITHUB_ENV at line %d of \"run:\" in the same step. env context is evaluated before the step runs so the variable is not available yet",
+						w.name,
+						w.line+1,
+					)
+					return
+				}
+			}
+		})
+	}
+}
+
+// checkReadsInSameStep checks environment variables set via $GITHUB_ENV are read in the same step.
+// Such variables are only available in subsequent steps.
+func (rule *RuleEnvVar) checkReadsInSameStep(run *String, ws []githubEnvWrite, step *envVarScope) {
+	lines := strings.Split(run.Value, "\n")
+	reported := map[string]struct{}{}
+	for _, w := range ws {
+		if _, ok := reported[w.name]; ok || rule.isDefined(w.name, step) {
+			continue
+		}
+		re := regexp.MustCompile(`(?:^|[^\\])\$\{?` + w.name + `\b|^\s*(?:export\s+|local\s+)?` + w.name + `=`)
+		for i := w.line + 1; i < len(lines); i++ {
+			m := re.FindString(lines[i])
+			if m == "" {
+				continue
+			}
+			if strings.HasSuffix(m, "=") {
+				break // Assigned to shell variable
+			}
+			rule.Errorf(
+				run.Pos,
+				"environment variable %q is set via $GITHUB_ENV at line %d but it is read at line %d in the same step. variables set via $GITHUB_ENV are available only in subsequent steps. assign the value to a shell variable as well",
+				w.name,
+				w.line+1,
+				i+1,
+			)
+			reported[w.name] = struct{}{}
+			break
 		}
 	}
 }
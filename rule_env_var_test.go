@@ -1,6 +1,9 @@
 package actionlint
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func testValidateEnvVarName(t *testing.T, name string) []*Error {
 	t.Helper()
@@ -76,3 +79,78 @@ func TestRuleEnvVarSkipEdgeCaseEnv(t *testing.T) {
 	}
 
 }
+
+func TestRuleEnvVarConfigurableChecks(t *testing.T) {
+	src := `on: push
+env:
+  DEBUG: false
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      DEBUG: verbose
+      node-version: 20
+    steps:
+      - run: echo "$DEBUG"
+        env:
+          DEBUG: 'true'
+          RETRY: 3
+      - run: echo "VERSION=1.2.3" >> "$GITHUB_ENV"
+      - run: echo '${{ env.TOKEN }} ${{ env.VERSION }} ${{ env.RETRY }}'
+      - run: echo '${{ env['undefined'] }}'
+      - uses: actions/setup-node@v4
+      - run: echo '${{ env.NODE_PATH }}'
+`
+	tests := []struct {
+		what string
+		cfg  string
+		want []string
+	}{
+		{
+			what: "default",
+			cfg:  "",
+		},
+		{
+			what: "posix-names",
+			cfg:  "rules:\n  env-var:\n    posix-names: true",
+			want: []string{
+				`10:7: environment variable name "node-version" is not a valid POSIX name`,
+			},
+		},
+		{
+			what: "shadowing",
+			cfg:  "rules:\n  env-var:\n    shadowing: true",
+			want: []string{
+				`9:7: environment variable "DEBUG" shadows the variable defined at workflow level with different type of value. boolean value "false" is overridden with string value "verbose"`,
+				`14:11: environment variable "DEBUG" shadows the variable defined at workflow level with different type of value. boolean value "false" is overridden with string value "true"`,
+			},
+		},
+		{
+			what: "undefined-references",
+			cfg:  "rules:\n  env-var:\n    undefined-references: true",
+			want: []string{
+				`17:60: environment variable "retry" is not defined in this scope`,
+				`18:24: environment variable "undefined" is not defined in this scope`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tc.cfg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := testCheckRule(t, NewRuleEnvVar(), cfg, src)
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -43,7 +44,7 @@ func (rule *RuleEvents) checkEvent(event Event) {
 	case *WorkflowDispatchEvent:
 		rule.checkWorkflowDispatchEvent(e)
 	case *RepositoryDispatchEvent:
-		// Nothing to do
+		rule.checkRepositoryDispatchEvent(e)
 	case *WorkflowCallEvent:
 		rule.checkWorkflowCallEvent(e)
 	case *WebhookEvent:
@@ -125,6 +126,8 @@ func (rule *RuleEvents) checkWebhookEvent(event *WebhookEvent) {
 		if len(event.Workflows) == 0 {
 			rule.Error(event.Pos, "no workflow is configured for \"workflow_run\" event")
 		}
+		rule.checkWorkflowRunBranches(event.Branches)
+		rule.checkWorkflowRunBranches(event.BranchesIgnore)
 	} else {
 		if len(event.Workflows) != 0 {
 			rule.Errorf(event.Pos, "\"workflows\" cannot be configured for %q event. it is only for workflow_run event", hook)
@@ -157,6 +160,25 @@ func (rule *RuleEvents) checkWebhookEvent(event *WebhookEvent) {
 	)
 }
 
+// checkWorkflowRunBranches checks "branches" and "branches-ignore" filters of workflow_run event.
+// The filters are matched to the head branch name of the triggering workflow run such as "main".
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#limiting-your-workflow-to-run-based-on-branches
+func (rule *RuleEvents) checkWorkflowRunBranches(filter *WebhookEventFilter) {
+	if filter.IsEmpty() {
+		return
+	}
+	for _, v := range filter.Values {
+		if strings.HasPrefix(v.Value, "refs/") {
+			rule.Errorf(
+				v.Pos,
+				"%q filter of workflow_run event is matched to the head branch name of the triggering workflow run such as \"main\". the pattern %q starting with \"refs/\" never matches. remove the \"refs/heads/\" prefix",
+				filter.Name.Value,
+				v.Value,
+			)
+		}
+	}
+}
+
 func (rule *RuleEvents) checkTypes(hook *String, types []*String, expected []string) {
 	if len(expected) == 0 && len(types) > 0 {
 		rule.Errorf(hook.Pos, "\"types\" cannot be specified for %q Webhook event", hook.Value)
@@ -171,7 +193,12 @@ func (rule *RuleEvents) checkTypes(hook *String, types []*String, expected []str
 				break
 			}
 		}
-		if !valid {
+		if valid {
+			continue
+		}
+
+		similar := findSimilarActivityType(ty.Value, expected)
+		if similar == "" {
 			rule.Errorf(
 				ty.Pos,
 				"invalid activity type %q for %q Webhook event. available types are %s",
@@ -179,7 +206,107 @@ func (rule *RuleEvents) checkTypes(hook *String, types []*String, expected []str
 				hook.Value,
 				sortedQuotes(expected),
 			)
+			continue
+		}
+
+		rule.Errorf(
+			ty.Pos,
+			"invalid activity type %q for %q Webhook event. available types are %s. did you mean %q?",
+			ty.Value,
+			hook.Value,
+			sortedQuotes(expected),
+			similar,
+		)
+		if !strings.ContainsAny(ty.Value, "\\'\"\n") {
+			start := ty.Pos
+			if ty.Quoted {
+				start = start.advance("'") // Skip the opening quote
+			}
+			rule.Suggest(&Suggestion{
+				Message:     fmt.Sprintf("replace %q with activity type %q", ty.Value, similar),
+				Start:       start,
+				End:         start.advance(ty.Value),
+				Replacement: similar,
+			})
+		}
+	}
+}
+
+// findSimilarActivityType finds the activity type which is the most similar to the given invalid
+// type from the candidates. Empty string is returned when no similar type is found. This is useful
+// to detect typos like "oppened".
+func findSimilarActivityType(ty string, candidates []string) string {
+	ty = strings.ToLower(ty)
+	found := ""
+	best := len(ty)/3 + 1 // Allow one edit per three characters
+	for _, c := range candidates {
+		if d := editDistance(ty, c); d < best {
+			found, best = c, d
+		}
+	}
+	return found
+}
+
+// editDistance calculates the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	r, s := []rune(a), []rune(b)
+	prev := make([]int, len(s)+1)
+	cur := make([]int, len(s)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r); i++ {
+		cur[0] = i
+		for j := 1; j <= len(s); j++ {
+			cost := 1
+			if r[i-1] == s[j-1] {
+				cost = 0
+			}
+			d := prev[j] + 1
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < d {
+				d = prev[j-1] + cost
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(s)]
+}
+
+// checkRepositoryDispatchEvent checks the types of repository_dispatch event are listed in the
+// configuration file since they are arbitrary strings sent via the API.
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch
+func (rule *RuleEvents) checkRepositoryDispatchEvent(event *RepositoryDispatchEvent) {
+	cfg := rule.Config()
+	if cfg == nil || cfg.RepositoryDispatch.Types == nil {
+		return
+	}
+	known := cfg.RepositoryDispatch.Types
+
+	for _, ty := range event.Types {
+		if ty.ContainsExpression() || containsString(known, ty.Value) {
+			continue
+		}
+		available := sortedQuotes(append([]string{}, known...)) // Copy since the config is shared across goroutines
+		if similar := findSimilarActivityType(ty.Value, known); similar != "" {
+			rule.Errorf(
+				ty.Pos,
+				"unknown type %q for \"repository_dispatch\" event. available types configured at \"repository-dispatch\" in actionlint.yaml are %s. did you mean %q?",
+				ty.Value,
+				available,
+				similar,
+			)
+			continue
 		}
+		rule.Errorf(
+			ty.Pos,
+			"unknown type %q for \"repository_dispatch\" event. available types configured at \"repository-dispatch\" in actionlint.yaml are %s",
+			ty.Value,
+			available,
+		)
 	}
 }
 
@@ -202,6 +329,7 @@ func (rule *RuleEvents) checkWorkflowCallEvent(event *WorkflowCallEvent) {
 						err,
 					)
 				}
+				rule.checkQuotedInputDefault(i.Default, i.Name.Value, "number")
 			case WorkflowCallEventInputTypeBoolean:
 				if d := strings.ToLower(i.Default.Value); d != "true" && d != "false" {
 					rule.Errorf(
@@ -211,6 +339,7 @@ func (rule *RuleEvents) checkWorkflowCallEvent(event *WorkflowCallEvent) {
 						i.Default.Value,
 					)
 				}
+				rule.checkQuotedInputDefault(i.Default, i.Name.Value, "boolean")
 			}
 		}
 		if i.IsRequired() {
@@ -224,6 +353,35 @@ func (rule *RuleEvents) checkWorkflowCallEvent(event *WorkflowCallEvent) {
 	}
 }
 
+// checkQuotedInputDefault checks the default value of the boolean or number input is not quoted. A
+// quoted value is a string in YAML so it does not match to the input type. The 'ty' parameter is
+// "boolean" or "number".
+func (rule *RuleEvents) checkQuotedInputDefault(def *String, name, ty string) {
+	if !def.Quoted || def.ContainsExpression() {
+		return
+	}
+	if ty == "boolean" {
+		if d := strings.ToLower(def.Value); d != "true" && d != "false" {
+			return
+		}
+	} else if _, err := strconv.ParseFloat(def.Value, 64); err != nil {
+		return
+	}
+	rule.Errorf(
+		def.Pos,
+		"default value %q of %q input is quoted but the input is typed as %s. the quoted value is a string in YAML. remove the quotes",
+		def.Value,
+		name,
+		ty,
+	)
+	rule.Suggest(&Suggestion{
+		Message:     "remove the quotes",
+		Start:       def.Pos,
+		End:         &Pos{Line: def.Pos.Line, Col: def.Pos.Col + len(def.Value) + 2},
+		Replacement: def.Value,
+	})
+}
+
 // https://github.blog/changelog/2021-11-10-github-actions-input-types-for-manual-workflows/
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#onworkflow_dispatchinputs
 func (rule *RuleEvents) checkWorkflowDispatchEvent(event *WorkflowDispatchEvent) {
@@ -248,6 +406,21 @@ func (rule *RuleEvents) checkWorkflowDispatchEvent(event *WorkflowDispatchEvent)
 				}
 				if _, ok := seen[i.Default.Value]; !ok {
 					rule.Errorf(i.Default.Pos, "default value %q of %q input is not included in its options %q", i.Default.Value, n, b.build())
+					for _, o := range i.Options {
+						if strings.EqualFold(o.Value, i.Default.Value) && !strings.ContainsAny(i.Default.Value, "\\'\"\n") {
+							start := i.Default.Pos
+							if i.Default.Quoted {
+								start = start.advance("'") // Skip the opening quote
+							}
+							rule.Suggest(&Suggestion{
+								Message:     fmt.Sprintf("replace %q with option %q", i.Default.Value, o.Value),
+								Start:       start,
+								End:         start.advance(i.Default.Value),
+								Replacement: o.Value,
+							})
+							break
+						}
+					}
 				}
 			}
 		} else {
@@ -268,10 +441,12 @@ func (rule *RuleEvents) checkWorkflowDispatchEvent(event *WorkflowDispatchEvent)
 							err,
 						)
 					}
+					rule.checkQuotedInputDefault(i.Default, i.Name.Value, "number")
 				case WorkflowDispatchEventInputTypeBoolean:
 					if d := strings.ToLower(i.Default.Value); d != "true" && d != "false" {
 						rule.Errorf(i.Default.Pos, "type of %q input is \"boolean\". its default value %q must be \"true\" or \"false\"", n, i.Default.Value)
 					}
+					rule.checkQuotedInputDefault(i.Default, i.Name.Value, "boolean")
 				}
 			}
 		}
@@ -0,0 +1,102 @@
+package actionlint
+
+import (
+	"testing"
+)
+
+func TestRuleEventsSuggestFixesOfInputDefaults(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "quoted boolean",
+			input: "type: boolean\n        default: 'true'",
+			want:  "type: boolean\n        default: true",
+		},
+		{
+			what:  "quoted number",
+			input: "type: number\n        default: \"-1.5\"",
+			want:  "type: number\n        default: -1.5",
+		},
+		{
+			what:  "choice in different case",
+			input: "type: choice\n        options: [debug, info]\n        default: 'Info'",
+			want:  "type: choice\n        options: [debug, info]\n        default: 'info'",
+		},
+		{
+			what:  "unquoted choice in different case",
+			input: "type: choice\n        options: [debug, info]\n        default: DEBUG",
+			want:  "type: choice\n        options: [debug, info]\n        default: debug",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on:\n  workflow_dispatch:\n    inputs:\n      foo:\n        " + tc.input + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			errs := testCheckRule(t, NewRuleEvents(), nil, src)
+			if len(errs) != 1 || len(errs[0].Suggestions) != 1 {
+				t.Fatalf("one error with one suggestion was expected but got %v", errs)
+			}
+			b, err := errs[0].Suggestions[0].Apply([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "on:\n  workflow_dispatch:\n    inputs:\n      foo:\n        " + tc.want + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			if string(b) != want {
+				t.Fatalf("wanted %q but got %q", want, string(b))
+			}
+		})
+	}
+}
+
+func TestRuleEventsSuggestFixesOfActivityTypeTypos(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "typo",
+			input: "types: [oppened]",
+			want:  "types: [opened]",
+		},
+		{
+			what:  "quoted typo",
+			input: "types: ['synchronized', closed]",
+			want:  "types: ['synchronize', closed]",
+		},
+		{
+			what:  "different case",
+			input: "types:\n      - Labeled",
+			want:  "types:\n      - labeled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on:\n  pull_request:\n    " + tc.input + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			errs := testCheckRule(t, NewRuleEvents(), nil, src)
+			if len(errs) != 1 || len(errs[0].Suggestions) != 1 {
+				t.Fatalf("one error with one suggestion was expected but got %v", errs)
+			}
+			b, err := errs[0].Suggestions[0].Apply([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "on:\n  pull_request:\n    " + tc.want + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			if string(b) != want {
+				t.Fatalf("wanted %q but got %q", want, string(b))
+			}
+		})
+	}
+}
+
+func TestRuleEventsNoSuggestionForUnrelatedActivityType(t *testing.T) {
+	for _, ty := range []string{"foo", "merged", "a"} {
+		if s := findSimilarActivityType(ty, AllWebhookTypes["pull_request"]); s != "" {
+			t.Errorf("no similar type should be found for %q but got %q", ty, s)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package actionlint
 
 import (
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 //go:generate go run ./scripts/generate-availability ./availability.go
@@ -22,17 +24,33 @@ type RuleExpression struct {
 	matrixTy         *ObjectType
 	stepsTy          *ObjectType
 	needsTy          *ObjectType
+	jobTy            *ObjectType
 	secretsTy        *ObjectType
 	inputsTy         *ObjectType
 	dispatchInputsTy *ObjectType
+	clientPayloadTy  ExprType
 	jobsTy           *ObjectType
-	workflow         *Workflow
-	localActions     *LocalActionsCache
-	localWorkflows   *LocalReusableWorkflowCache
+	events           []string
+	runnerOSes       []string
+	// matrixRefs is a set of matrix keys referenced in the current job. It is nil when the keys of
+	// the matrix are not known statically.
+	matrixRefs      map[string]struct{}
+	matrixRefAll    bool // true when the entire matrix is referenced like toJSON(matrix)
+	otherMatrixKeys map[string][]string
+	workflow        *Workflow
+	// exprs is a cache of expressions in the workflow. When a type of context is updated, the cache
+	// must be notified with exprCache.updated.
+	exprs *exprCache
+	// onExprScope is called with a semantics checker set up for each expression before checking it.
+	// The position is where the expression starts. This is used by the language server to know
+	// contexts available at the cursor.
+	onExprScope func(pos *Pos, workflowKey string, sema *ExprSemanticsChecker)
 }
 
-// NewRuleExpression creates new RuleExpression instance.
-func NewRuleExpression(actionsCache *LocalActionsCache, workflowCache *LocalReusableWorkflowCache) *RuleExpression {
+// NewRuleExpression creates new RuleExpression instance. Outputs of local actions and local reusable
+// workflows are resolved with LocalActions and LocalReusableWorkflows of the capabilities set by
+// SetCapabilities.
+func NewRuleExpression() *RuleExpression {
 	return &RuleExpression{
 		RuleBase: RuleBase{
 			name: "expression",
@@ -41,20 +59,32 @@ func NewRuleExpression(actionsCache *LocalActionsCache, workflowCache *LocalReus
 		matrixTy:         nil,
 		stepsTy:          nil,
 		needsTy:          nil,
+		jobTy:            nil,
 		secretsTy:        nil,
 		inputsTy:         nil,
 		dispatchInputsTy: nil,
+		clientPayloadTy:  nil,
 		jobsTy:           nil,
 		workflow:         nil,
-		localActions:     actionsCache,
-		localWorkflows:   workflowCache,
+		exprs:            newExprCache(),
 	}
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
+	rule.exprs = newExprCache()
 	rule.checkString(n.Name, "")
 
+	rule.events = make([]string, 0, len(n.On))
+	for _, e := range n.On {
+		if _, ok := e.(*WorkflowCallEvent); ok {
+			rule.events = nil // github.event_name in reusable workflow is the event name of caller workflow
+			break
+		}
+		rule.events = append(rule.events, strings.ToLower(e.EventName()))
+	}
+	rule.exprs.updated("github")
+
 	for _, e := range n.On {
 		switch e := e.(type) {
 		case *WebhookEvent:
@@ -90,8 +120,13 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 				ity.Props[id] = ty
 			}
 			rule.dispatchInputsTy = ity
+			rule.exprs.updated("inputs", "github")
 		case *RepositoryDispatchEvent:
 			rule.checkStrings(e.Types, "")
+			if rule.config != nil && rule.config.RepositoryDispatch.ClientPayload != nil {
+				rule.clientPayloadTy = rule.config.RepositoryDispatch.ClientPayload.ExprType()
+				rule.exprs.updated("github")
+			}
 		case *WorkflowCallEvent:
 			ity := NewEmptyStrictObjectType()
 
@@ -103,6 +138,7 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 			//       type: string
 			//       default: ${{ inputs.input1 }}
 			rule.inputsTy = ity
+			rule.exprs.updated("inputs")
 
 			for _, i := range e.Inputs {
 				rule.checkString(i.Description, "")
@@ -141,6 +177,7 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 					ty = AnyType{}
 				}
 				ity.Props[i.ID] = ty
+				rule.exprs.updated("inputs")
 			}
 
 			// When no secret is passed, secrets may be inherited from a caller of the workflow.
@@ -153,6 +190,7 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 					rule.checkString(s.Description, "")
 				}
 				rule.secretsTy = sty
+				rule.exprs.updated("secrets")
 			}
 
 			for _, o := range e.Outputs {
@@ -162,6 +200,17 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 		}
 	}
 
+	// When the workflow can access the repository secrets directly or by `secrets: inherit` and the
+	// secrets are listed in the configuration, `secrets` context is typed strictly with them.
+	if rule.secretsTy == nil && rule.config != nil && rule.config.Secrets != nil {
+		sty := NewEmptyStrictObjectType()
+		for _, s := range rule.config.Secrets {
+			sty.Props[strings.ToLower(s)] = StringType{}
+		}
+		rule.secretsTy = sty
+		rule.exprs.updated("secrets")
+	}
+
 	rule.checkString(n.RunName, "run-name")
 	rule.checkEnv(n.Env, "env")
 
@@ -186,6 +235,7 @@ func (rule *RuleExpression) VisitJobPre(n *Job) error {
 	// Type of needs must be resolved before resolving type of matrix because `needs` context can
 	// be used in matrix configuration.
 	rule.needsTy = rule.calcNeedsType(n)
+	rule.exprs.updated("needs")
 
 	// Set matrix type at start of VisitJobPre() because matrix values are available in
 	// jobs.<job_id> section. For example:
@@ -198,7 +248,14 @@ func (rule *RuleExpression) VisitJobPre(n *Job) error {
 	if n.Strategy != nil && n.Strategy.Matrix != nil {
 		// Check and guess type of the matrix
 		rule.matrixTy = rule.checkMatrix(n.Strategy.Matrix)
+		if cfg := rule.Config(); cfg != nil && cfg.Rules.Expression.UnusedMatrixValues && n.Strategy.Matrix.Expression == nil {
+			rule.matrixRefs = map[string]struct{}{}
+		}
 	}
+	rule.jobTy = rule.calcJobType(n)
+	rule.runnerOSes = runnerOSesOfJob(n)
+	rule.otherMatrixKeys = rule.matrixKeysOfOtherJobs(n)
+	rule.exprs.updated("matrix", "job", "runner")
 
 	rule.checkString(n.Name, "jobs.<job_id>.name")
 	rule.checkStrings(n.Needs, "")
@@ -248,6 +305,7 @@ func (rule *RuleExpression) VisitJobPre(n *Job) error {
 	rule.checkWorkflowCall(n.WorkflowCall)
 
 	rule.stepsTy = NewEmptyStrictObjectType()
+	rule.exprs.updated("steps")
 
 	return nil
 }
@@ -263,9 +321,19 @@ func (rule *RuleExpression) VisitJobPost(n *Job) error {
 		rule.checkString(output.Value, "jobs.<job_id>.outputs.<output_id>")
 	}
 
+	if rule.matrixRefs != nil && !rule.matrixRefAll {
+		rule.checkUnusedMatrixKeys(n.Strategy.Matrix)
+	}
+
 	rule.matrixTy = nil
 	rule.stepsTy = nil
 	rule.needsTy = nil
+	rule.jobTy = nil
+	rule.runnerOSes = nil
+	rule.matrixRefs = nil
+	rule.matrixRefAll = false
+	rule.otherMatrixKeys = nil
+	rule.exprs.updated("matrix", "steps", "needs", "job", "runner")
 
 	return nil
 }
@@ -283,6 +351,7 @@ func (rule *RuleExpression) VisitStep(n *Step) error {
 		rule.checkString(e.WorkingDirectory, "jobs.<job_id>.steps.working-directory")
 	case *ExecAction:
 		rule.checkString(e.Uses, "")
+		rule.checkNotEvaluated(e.Uses, "uses")
 		for n, i := range e.Inputs {
 			if e.Uses != nil && strings.HasPrefix(e.Uses.Value, "actions/github-script@") && n == "script" {
 				rule.checkScriptString(i.Value, "jobs.<job_id>.steps.with")
@@ -311,6 +380,7 @@ func (rule *RuleExpression) VisitStep(n *Step) error {
 			"conclusion": StringType{},
 			"outcome":    StringType{},
 		})
+		rule.exprs.updated("steps")
 	}
 
 	return nil
@@ -323,7 +393,11 @@ func (rule *RuleExpression) getActionOutputsType(spec *String) *ObjectType {
 	}
 
 	if strings.HasPrefix(spec.Value, "./") {
-		meta, _, err := rule.localActions.FindMetadata(spec.Value)
+		c := rule.Capabilities().LocalActions
+		if c == nil {
+			return NewMapObjectType(StringType{})
+		}
+		meta, _, err := c.FindMetadata(spec.Value)
 		if err != nil {
 			rule.Error(spec.Pos, err.Error())
 			return NewMapObjectType(StringType{})
@@ -343,7 +417,7 @@ func (rule *RuleExpression) getActionOutputsType(spec *String) *ObjectType {
 
 	// When the action run at this step is a popular action, we know what outputs are set by it.
 	// Set the output names to `steps.{step_id}.outputs.{name}`.
-	if meta, ok := PopularActions[spec.Value]; ok {
+	if meta, ok := rule.Capabilities().FindPopularAction(spec.Value); ok {
 		return typeOfActionOutputs(meta)
 	}
 
@@ -351,11 +425,12 @@ func (rule *RuleExpression) getActionOutputsType(spec *String) *ObjectType {
 }
 
 func (rule *RuleExpression) getWorkflowCallOutputsType(call *WorkflowCall) *ObjectType {
-	if call.Uses == nil {
+	c := rule.Capabilities().LocalReusableWorkflows
+	if call.Uses == nil || c == nil {
 		return NewMapObjectType(StringType{})
 	}
 
-	m, err := rule.localWorkflows.FindMetadata(call.Uses.Value)
+	m, err := c.FindMetadata(call.Uses.Value)
 	if err != nil {
 		rule.Error(call.Uses.Pos, err.Error())
 		return NewMapObjectType(StringType{})
@@ -513,10 +588,15 @@ func (rule *RuleExpression) checkWorkflowCall(c *WorkflowCall) {
 	}
 
 	rule.checkString(c.Uses, "")
+	rule.checkNotEvaluated(c.Uses, "uses")
 
-	m, err := rule.localWorkflows.FindMetadata(c.Uses.Value)
-	if err != nil {
-		rule.Error(c.Uses.Pos, err.Error())
+	var m *ReusableWorkflowMetadata
+	if cache := rule.Capabilities().LocalReusableWorkflows; cache != nil {
+		md, err := cache.FindMetadata(c.Uses.Value)
+		if err != nil {
+			rule.Error(c.Uses.Pos, err.Error())
+		}
+		m = md
 	}
 
 	for n, i := range c.Inputs {
@@ -623,16 +703,7 @@ func (rule *RuleExpression) checkIfCondition(str *String, workflowKey string) {
 		}
 	} else {
 		src := str.Value + "}}" // }} is necessary since lexer lexes it as end of tokens
-		line, col := str.Pos.Line, str.Pos.Col
-
-		p := NewExprParser()
-		expr, err := p.Parse(NewExprLexer(src))
-		if err != nil {
-			rule.exprError(err, line, col)
-			return
-		}
-
-		if ty, ok := rule.checkSemanticsOfExprNode(expr, line, col, false, workflowKey); ok {
+		if ty, _, ok := rule.checkSemantics(src, str.Pos.Line, str.Pos.Col, false, workflowKey); ok {
 			condTy = ty
 		}
 	}
@@ -717,6 +788,8 @@ func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrus
 	if quoted {
 		col++ // when the string is quoted like 'foo' or "foo", column should be incremented
 	}
+	fixable := canSuggestFixInString(s, quoted)
+	src := s
 	offset := 0
 	ts := []typedExpr{}
 	for {
@@ -728,7 +801,12 @@ func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrus
 		start := idx + 3 // 3 means removing "${{"
 		s = s[start:]
 		offset += start
-		col := col + offset
+		col := col + utf8.RuneCountInString(src[:offset]) // Columns are counted in characters
+
+		if inner, innerEnd := findNestedPlaceholder(s); inner >= 0 {
+			rule.nestedPlaceholderError(s, inner, innerEnd, line, col, fixable)
+			return nil, false
+		}
 
 		ty, offsetAfter, ok := rule.checkSemantics(s, line, col, checkUntrusted, workflowKey)
 		if !ok {
@@ -737,7 +815,7 @@ func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrus
 		if ty == nil || offsetAfter == 0 {
 			return nil, true
 		}
-		ts = append(ts, typedExpr{ty, Pos{line, col - 3}})
+		ts = append(ts, typedExpr{ty, Pos{Line: line, Col: col - 3}})
 
 		s = s[offsetAfter:]
 		offset += offsetAfter
@@ -746,12 +824,107 @@ func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrus
 	return ts, true
 }
 
+// nestedPlaceholderError reports "${{" nested in the expression. The src is a source after the
+// outer "${{" and inner and innerEnd are the offsets of the nested "${{" and the end of its "}}".
+func (rule *RuleExpression) nestedPlaceholderError(src string, inner, innerEnd, line, col int, fixable bool) {
+	pos := &Pos{Line: line, Col: col + utf8.RuneCountInString(src[:inner])}
+	if innerEnd < 0 {
+		rule.Error(pos, "\"${{\" is nested in ${{ }} expression. ${{ }} cannot be nested. remove the inner \"${{\"")
+		return
+	}
+
+	e := strings.TrimSpace(src[inner+3 : innerEnd-2])
+	rule.Errorf(
+		pos,
+		"\"${{\" is nested in ${{ }} expression. ${{ }} cannot be nested. remove the inner \"${{\" and \"}}\" around %q",
+		e,
+	)
+	if fixable && !strings.Contains(e, "${{") {
+		rule.Suggest(&Suggestion{
+			Message:     "remove the nested \"${{\" and \"}}\"",
+			Start:       pos,
+			End:         &Pos{Line: line, Col: col + utf8.RuneCountInString(src[:innerEnd])},
+			Replacement: e,
+		})
+	}
+}
+
+// checkNotEvaluated checks ${{ }} is not used in the value where GitHub does not evaluate
+// expressions such as "uses:". When all the expressions are literals, the fix to embed them is
+// suggested. The value which consists only of ${{ }} or contains spaces outside ${{ }} is not
+// checked since it does not look like a reference to an action or a reusable workflow in the first
+// place. Other checks for the value are skipped in the case as well.
+func (rule *RuleExpression) checkNotEvaluated(s *String, key string) {
+	if s == nil || !s.ContainsExpression() {
+		return
+	}
+	if t := stripPlaceholders(s.Value); t == "" || strings.ContainsAny(t, " \t\n") {
+		return
+	}
+
+	var fix strings.Builder
+	literals := true
+	rest := s.Value
+	start, end := -1, -1
+	offset := 0
+	for literals {
+		idx := strings.Index(rest, "${{")
+		if idx == -1 {
+			break
+		}
+		fix.WriteString(rest[:idx])
+		if start < 0 {
+			start = offset + idx
+		}
+		src := rest[idx+3:]
+		l := NewExprLexer(src)
+		expr, err := NewExprParser().Parse(l)
+		if err != nil || l.Offset() == 0 {
+			literals = false
+			break
+		}
+		switch n := expr.(type) {
+		case *StringNode:
+			fix.WriteString(n.Value)
+		case *IntNode, *FloatNode, *BoolNode:
+			fix.WriteString(n.Token().Value)
+		default:
+			literals = false
+		}
+		consumed := idx + 3 + l.Offset()
+		rest = rest[consumed:]
+		offset += consumed
+		end = offset
+	}
+
+	rule.Errorf(
+		s.Pos,
+		"${{ }} cannot be used at \"%s:\" since GitHub does not evaluate expressions there. %q must be specified without ${{ }}",
+		key,
+		s.Value,
+	)
+	if literals && start >= 0 && canSuggestFixInString(s.Value, s.Quoted) {
+		col := s.Pos.Col
+		if s.Quoted {
+			col++
+		}
+		// The fix replaces the range from the start of the first ${{ to the end of the last }}
+		r := fix.String()[start:]
+		rule.Suggest(&Suggestion{
+			Message:     "embed the literal values directly",
+			Start:       &Pos{Line: s.Pos.Line, Col: col + utf8.RuneCountInString(s.Value[:start])},
+			End:         &Pos{Line: s.Pos.Line, Col: col + utf8.RuneCountInString(s.Value[:end])},
+			Replacement: r,
+		})
+	}
+}
+
 func (rule *RuleExpression) exprError(err *ExprError, lineBase, colBase int) {
 	pos := convertExprLineColToPos(err.Line, err.Column, lineBase, colBase)
-	rule.Error(pos, err.Message)
+	rule.errs = append(rule.errs, errorOfExpr(pos, rule.name, err))
 }
 
-func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col int, checkUntrusted bool, workflowKey string) (ExprType, bool) {
+func (rule *RuleExpression) newSemanticsChecker(checkUntrusted bool, workflowKey string) *ExprSemanticsChecker {
 	var v []string
 	if rule.config != nil {
 		v = rule.config.ConfigVariables
@@ -766,6 +939,9 @@ func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col in
 	if rule.needsTy != nil {
 		c.UpdateNeeds(rule.needsTy)
 	}
+	if rule.jobTy != nil {
+		c.UpdateJob(rule.jobTy)
+	}
 	if rule.secretsTy != nil {
 		c.UpdateSecrets(rule.secretsTy)
 	}
@@ -775,9 +951,21 @@ func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col in
 	if rule.dispatchInputsTy != nil {
 		c.UpdateDispatchInputs(rule.dispatchInputsTy)
 	}
+	if rule.clientPayloadTy != nil {
+		c.UpdateClientPayload(rule.clientPayloadTy)
+	}
 	if rule.jobsTy != nil {
 		c.UpdateJobs(rule.jobsTy)
 	}
+	if rule.events != nil {
+		c.SetWorkflowEvents(rule.events)
+	}
+	if rule.runnerOSes != nil {
+		c.SetRunnerOSes(rule.runnerOSes)
+	}
+	if rule.otherMatrixKeys != nil {
+		c.SetMatrixKeysOfOtherJobs(rule.otherMatrixKeys)
+	}
 	if workflowKey != "" {
 		ctx, sp := WorkflowKeyAvailability(workflowKey)
 		if len(ctx) == 0 {
@@ -786,25 +974,35 @@ func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col in
 		c.SetContextAvailability(ctx)
 		c.SetSpecialFunctionAvailability(sp)
 	}
+	return c
+}
 
-	ty, errs := c.Check(expr)
-	for _, err := range errs {
-		rule.exprError(err, line, col)
+func (rule *RuleExpression) notifyExprScope(line, col int, workflowKey string) {
+	if rule.onExprScope != nil {
+		rule.onExprScope(&Pos{Line: line, Col: col}, workflowKey, rule.newSemanticsChecker(false, workflowKey))
 	}
-
-	return ty, len(errs) == 0
 }
 
+// checkSemantics parses the expression at the start of the source and checks its semantics. The
+// source is a string after "${{". Results of parsing and checking the same expression are reused
+// through the cache.
 func (rule *RuleExpression) checkSemantics(src string, line, col int, checkUntrusted bool, workflowKey string) (ExprType, int, bool) {
-	l := NewExprLexer(src)
-	p := NewExprParser()
-	expr, err := p.Parse(l)
+	rule.notifyExprScope(line, col, workflowKey)
+	expr, offset, err := rule.exprs.parse(src)
 	if err != nil {
 		rule.exprError(err, line, col)
-		return nil, l.Offset(), false
+		rule.matrixRefAll = true // Matrix values referenced in the broken expression are unknown
+		return nil, offset, false
 	}
-	t, ok := rule.checkSemanticsOfExprNode(expr, line, col, checkUntrusted, workflowKey)
-	return t, l.Offset(), ok
+
+	rule.collectMatrixRefs(expr.node)
+	ty, errs := rule.exprs.check(expr, checkUntrusted, workflowKey, func() (ExprType, []*ExprError) {
+		return rule.newSemanticsChecker(checkUntrusted, workflowKey).Check(expr.node)
+	})
+	for _, err := range errs {
+		rule.exprError(err, line, col)
+	}
+	return ty, offset, len(errs) == 0
 }
 
 func (rule *RuleExpression) calcNeedsType(job *Job) *ObjectType {
@@ -814,6 +1012,45 @@ func (rule *RuleExpression) calcNeedsType(job *Job) *ObjectType {
 	return o
 }
 
+// calcJobType calculates type of `job` context of the job. Properties of `job.services` are IDs
+// of services defined in the job and `job.container` is only available when the job runs in a
+// container.
+func (rule *RuleExpression) calcJobType(job *Job) *ObjectType {
+	// https://docs.github.com/en/actions/learn-github-actions/contexts#job-context
+	o := NewStrictObjectType(map[string]ExprType{
+		"status": StringType{},
+	})
+
+	if job.Container != nil {
+		o.Props["container"] = NewStrictObjectType(map[string]ExprType{
+			"id":      StringType{},
+			"network": StringType{},
+		})
+	}
+
+	newServiceTy := func() ExprType {
+		return NewStrictObjectType(map[string]ExprType{
+			"id":      StringType{},
+			"network": StringType{},
+			"ports":   NewMapObjectType(StringType{}),
+		})
+	}
+	switch {
+	case job.Services == nil:
+		o.Props["services"] = NewEmptyStrictObjectType()
+	case job.Services.Expression != nil:
+		o.Props["services"] = NewMapObjectType(newServiceTy()) // Service IDs are unknown
+	default:
+		ss := NewEmptyStrictObjectType()
+		for id := range job.Services.Value {
+			ss.Props[id] = newServiceTy()
+		}
+		o.Props["services"] = ss
+	}
+
+	return o
+}
+
 func (rule *RuleExpression) populateDependantNeedsTypes(out *ObjectType, job *Job, root *Job) {
 	for _, id := range job.Needs {
 		i := strings.ToLower(id.Value) // ID is case insensitive
@@ -830,7 +1067,9 @@ func (rule *RuleExpression) populateDependantNeedsTypes(out *ObjectType, job *Jo
 		}
 
 		var outputs *ObjectType
-		if j.WorkflowCall == nil {
+		if j.Broken {
+			outputs = NewEmptyObjectType() // Outputs of the broken job are unknown
+		} else if j.WorkflowCall == nil {
 			outputs = NewEmptyStrictObjectType()
 			for name := range j.Outputs {
 				outputs.Props[name] = StringType{}
@@ -848,6 +1087,95 @@ func (rule *RuleExpression) populateDependantNeedsTypes(out *ObjectType, job *Jo
 	}
 }
 
+// collectMatrixRefs collects keys of matrix values referenced in the expression.
+func (rule *RuleExpression) collectMatrixRefs(expr ExprNode) {
+	if rule.matrixRefs == nil || rule.matrixRefAll {
+		return
+	}
+	VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+		if v, ok := n.(*VariableNode); !ok || !entering || v.Name != "matrix" {
+			return
+		}
+		switch p := p.(type) {
+		case *ObjectDerefNode:
+			rule.matrixRefs[p.Property] = struct{}{}
+			return
+		case *IndexAccessNode:
+			if p.Operand == n {
+				if s, ok := p.Index.(*StringNode); ok {
+					rule.matrixRefs[strings.ToLower(s.Value)] = struct{}{}
+					return
+				}
+			}
+		}
+		rule.matrixRefAll = true // e.g. toJSON(matrix), matrix[inputs.key]
+	})
+}
+
+// checkUnusedMatrixKeys reports matrix values which are defined but never referenced in the job.
+func (rule *RuleExpression) checkUnusedMatrixKeys(m *Matrix) {
+	keys := staticMatrixKeys(m)
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		if _, ok := rule.matrixRefs[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		key := keys[k]
+		rule.Errorf(
+			key.Pos,
+			"matrix value %q is defined but never referenced as \"matrix.%s\" in this job. remove it if it is not necessary",
+			key.Value,
+			key.Value,
+		)
+	}
+}
+
+// matrixKeysOfOtherJobs returns a mapping from keys of matrix values to IDs of jobs other than the
+// given job defining them.
+func (rule *RuleExpression) matrixKeysOfOtherJobs(job *Job) map[string][]string {
+	if rule.workflow == nil {
+		return nil
+	}
+	ret := map[string][]string{}
+	for _, j := range rule.workflow.Jobs {
+		if j == job || j.ID == nil || j.Strategy == nil || j.Strategy.Matrix == nil {
+			continue
+		}
+		for k := range staticMatrixKeys(j.Strategy.Matrix) {
+			ret[k] = append(ret[k], j.ID.Value)
+		}
+	}
+	for _, ids := range ret {
+		sort.Strings(ids)
+	}
+	return ret
+}
+
+// staticMatrixKeys returns keys of matrix values defined in the matrix statically. The keys of the
+// returned map are in lower case and the values are the keys in the original case.
+func staticMatrixKeys(m *Matrix) map[string]*String {
+	ret := map[string]*String{}
+	if m.Expression != nil {
+		return ret
+	}
+	for k, r := range m.Rows {
+		ret[k] = r.Name
+	}
+	if m.Include != nil {
+		for _, c := range m.Include.Combinations {
+			for k, a := range c.Assigns {
+				if _, ok := ret[k]; !ok {
+					ret[k] = a.Key
+				}
+			}
+		}
+	}
+	return ret
+}
+
 func (rule *RuleExpression) checkMatrixExpression(expr *String) *ObjectType {
 	ty := rule.checkObjectExpression(expr, "matrix", "jobs.<job_id>.strategy")
 	if ty == nil {
@@ -999,6 +1327,7 @@ func (rule *RuleExpression) checkWorkflowCallOutputs(outputs map[string]*Workflo
 		})
 	}
 	rule.jobsTy = NewStrictObjectType(props)
+	rule.exprs.updated("jobs")
 
 	for _, o := range outputs {
 		rule.checkString(o.Value, "on.workflow_call.outputs.<output_id>.value")
@@ -1061,6 +1390,112 @@ func convertExprLineColToPos(line, col, lineBase, colBase int) *Pos {
 	}
 }
 
+// canSuggestFixInString returns whether the offsets in the string value can be mapped to the
+// columns in the source. It is not possible when the value contains newlines or when the quoted
+// value may contain escaped characters.
+func canSuggestFixInString(s string, quoted bool) bool {
+	if strings.Contains(s, "\n") {
+		return false
+	}
+	return !quoted || !strings.ContainsAny(s, "'\"\\")
+}
+
+// stripPlaceholders removes all ${{ }} placeholders from the string. An unclosed placeholder is
+// removed until the end of the string.
+func stripPlaceholders(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${{")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		j := strings.Index(s[i:], "}}")
+		if j < 0 {
+			return b.String()
+		}
+		s = s[i+j+len("}}"):]
+	}
+}
+
+// findNestedPlaceholder finds "${{" nested in the expression at the head of src, which is a source
+// after the opening "${{". It returns the offsets of the nested "${{" and the end of its "}}". -1 is
+// returned for the offsets which are not found.
+func findNestedPlaceholder(src string) (int, int) {
+	inner, innerEnd := -1, -1
+	for i := 0; i < len(src); i++ {
+		switch {
+		case src[i] == '\'':
+			// Skip string literal. '' is an escaped single quote in the literal
+			for i++; i < len(src); i++ {
+				if src[i] == '\'' {
+					if i+1 < len(src) && src[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case strings.HasPrefix(src[i:], "${{"):
+			if inner < 0 {
+				inner = i
+			}
+			i += 2
+		case strings.HasPrefix(src[i:], "}}"):
+			if inner < 0 {
+				return -1, -1 // Reached the end of the outer expression
+			}
+			if innerEnd >= 0 {
+				return inner, innerEnd
+			}
+			innerEnd = i + 2
+			i++
+		}
+	}
+	return inner, innerEnd
+}
+
+// visitExprNodesInString parses all ${{ }} placeholders in the string and calls the function for
+// each node of the parsed expressions with its position in the source. Placeholders with syntax
+// errors are ignored since they are reported by the "expression" rule.
+func visitExprNodesInString(s *String, f func(n ExprNode, pos *Pos)) {
+	line, col := s.Pos.Line, s.Pos.Col
+	if s.Quoted {
+		col++
+	}
+	src := s.Value
+	offset := 0
+	for {
+		idx := strings.Index(src, "${{")
+		if idx == -1 {
+			return
+		}
+		start := idx + 3
+		src = src[start:]
+		offset += start
+
+		l := NewExprLexer(src)
+		expr, err := NewExprParser().Parse(l)
+		if err != nil {
+			return
+		}
+
+		VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+			if entering {
+				t := n.Token()
+				f(n, convertExprLineColToPos(t.Line, t.Column, line, col+utf8.RuneCountInString(s.Value[:offset])))
+			}
+		})
+
+		if l.Offset() == 0 {
+			return
+		}
+		src = src[l.Offset():]
+		offset += l.Offset()
+	}
+}
+
 func typeOfActionOutputs(meta *ActionMetadata) *ObjectType {
 	// Some action sets outputs dynamically. Such outputs are not defined in action.yml. actionlint
 	// cannot check such outputs statically so it allows any props (#18)
@@ -0,0 +1,67 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleExpressionSuggestFixesOfInterpolation(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "nested placeholder",
+			input: "- run: echo ${{ format('{0}', ${{ github.sha }}) }}",
+			want:  "- run: echo ${{ format('{0}', github.sha) }}",
+		},
+		{
+			what:  "nested placeholder after string literal containing }}",
+			input: "- run: echo ${{ format('}}{0}', ${{github.sha}}) }}",
+			want:  "- run: echo ${{ format('}}{0}', github.sha) }}",
+		},
+		{
+			what:  "literal at uses",
+			input: "- uses: actions/checkout@${{ 'v4' }}",
+			want:  "- uses: actions/checkout@v4",
+		},
+		{
+			what:  "multiple literals at uses",
+			input: "- uses: ${{ 'actions' }}/checkout@v${{ 4 }}",
+			want:  "- uses: actions/checkout@v4",
+		},
+		{
+			what:  "non-literal at uses",
+			input: "- uses: actions/checkout@${{ github.sha }}",
+			want:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      " + tc.input + "\n"
+			errs := testCheckRule(t, NewRuleExpression(), nil, src)
+			if len(errs) != 1 {
+				t.Fatal("one error was expected but got", errs)
+			}
+			if tc.want == "" {
+				if len(errs[0].Suggestions) != 0 {
+					t.Fatal("no fix was expected but got", errs[0].Suggestions)
+				}
+				return
+			}
+			if len(errs[0].Suggestions) != 1 {
+				t.Fatal("one fix was expected but got", errs[0].Suggestions)
+			}
+			b, err := errs[0].Suggestions[0].Apply([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Replace(src, tc.input, tc.want, 1)
+			if string(b) != want {
+				t.Fatalf("wanted %q but got %q", want, string(b))
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package actionlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	reFailureHandlingSecrets     = regexp.MustCompile(`\bsecrets\s*[.\[]`)
+	reFailureHandlingDeploy      = regexp.MustCompile(`(?i)\bdeploy`)
+	reFailureHandlingStatus      = regexp.MustCompile(`\bjob\.status\b|\bneeds\.[A-Za-z0-9_*-]+\.result\b|\bsteps\.[A-Za-z0-9_-]+\.(?:outcome|conclusion)\b`)
+	reFailureHandlingStepOutput  = regexp.MustCompile(`\bsteps\.([A-Za-z_][A-Za-z0-9_-]*)\.outputs\b`)
+	reFailureHandlingNeedsOutput = regexp.MustCompile(`\bneeds\.([A-Za-z_][A-Za-z0-9_-]*)\.outputs\.([A-Za-z_][A-Za-z0-9_-]*)`)
+)
+
+// RuleFailureHandling is a rule to check how jobs and steps handle failures and cancellations. It
+// checks jobs and steps which run even when the workflow run is cancelled due to always() at their
+// "if:" conditions though they use secrets or deploy something. It also checks outputs of jobs
+// which may be empty because of "continue-on-error: true" are used by downstream jobs.
+type RuleFailureHandling struct {
+	RuleBase
+	// Outputs of jobs which may be empty on failure. Keys are job IDs in lower case and values are
+	// maps from output names in lower case to the descriptions of failing jobs or steps.
+	fallible map[string]map[string]string
+}
+
+// NewRuleFailureHandling creates a new RuleFailureHandling instance.
+func NewRuleFailureHandling() *RuleFailureHandling {
+	return &RuleFailureHandling{
+		RuleBase: RuleBase{
+			name: "failure-handling",
+			desc: "Checks for always() at \"if:\" conditions running jobs or steps on cancellation and outputs which may be empty due to \"continue-on-error:\"",
+		},
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleFailureHandling) VisitWorkflowPre(n *Workflow) error {
+	rule.fallible = map[string]map[string]string{}
+
+	for id, j := range n.Jobs {
+		var failing string
+		if isContinueOnError(j.ContinueOnError) {
+			failing = fmt.Sprintf("job %q", j.ID.Value)
+		}
+
+		steps := map[string]string{}
+		for _, s := range j.Steps {
+			if s.ID != nil && isContinueOnError(s.ContinueOnError) {
+				steps[strings.ToLower(s.ID.Value)] = fmt.Sprintf("step %q", s.ID.Value)
+			}
+		}
+
+		outputs := map[string]string{}
+		for name, o := range j.Outputs {
+			if o.Value == nil {
+				continue
+			}
+			if failing != "" {
+				outputs[name] = failing
+				continue
+			}
+			for _, m := range reFailureHandlingStepOutput.FindAllStringSubmatch(o.Value.Value, -1) {
+				if s, ok := steps[strings.ToLower(m[1])]; ok {
+					outputs[name] = fmt.Sprintf("%s of job %q", s, j.ID.Value)
+					break
+				}
+			}
+		}
+		if len(outputs) > 0 {
+			rule.fallible[id] = outputs
+		}
+	}
+
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleFailureHandling) VisitJobPre(n *Job) error {
+	ss := []*String{n.Name, n.If}
+	if n.Environment != nil {
+		ss = append(ss, n.Environment.Name, n.Environment.URL)
+	}
+	for _, o := range n.Outputs {
+		ss = append(ss, o.Value)
+	}
+	ss = appendEnvStrings(ss, n.Env)
+	if c := n.WorkflowCall; c != nil {
+		for _, i := range c.Inputs {
+			ss = append(ss, i.Value)
+		}
+		for _, s := range c.Secrets {
+			ss = append(ss, s.Value)
+		}
+	}
+	for _, s := range n.Steps {
+		ss = append(ss, failureHandlingStepStrings(s)...)
+	}
+
+	rule.checkFallibleOutputs(n, ss)
+
+	if !isAlwaysCond(n.If) || reportsStatus(ss) {
+		return nil
+	}
+	var what string
+	switch {
+	case n.Environment != nil:
+		what = fmt.Sprintf("deploys to environment %q", n.Environment.Name.Value)
+	case n.WorkflowCall != nil && (n.WorkflowCall.InheritSecrets || len(n.WorkflowCall.Secrets) > 0):
+		what = "passes secrets to the reusable workflow"
+	case containsSecrets(ss):
+		what = "uses secrets"
+	case reFailureHandlingDeploy.MatchString(n.ID.Value):
+		what = "looks like it deploys something"
+	default:
+		return nil
+	}
+	rule.Errorf(
+		n.If.Pos,
+		"job %q runs even when the workflow run is cancelled because \"if:\" condition %q uses always(), but the job %s. use \"if: success() || failure()\" or \"if: ${{ !cancelled() }}\" instead not to run it on cancellation",
+		n.ID.Value,
+		n.If.Value,
+		what,
+	)
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleFailureHandling) VisitStep(n *Step) error {
+	if !isAlwaysCond(n.If) {
+		return nil
+	}
+	ss := failureHandlingStepStrings(n)
+	if reportsStatus(ss) {
+		return nil
+	}
+	var what string
+	switch {
+	case containsSecrets(ss):
+		what = "uses secrets"
+	case failureHandlingStepDeploys(n):
+		what = "looks like it deploys something"
+	default:
+		return nil
+	}
+	rule.Errorf(
+		n.If.Pos,
+		"step runs even when the workflow run is cancelled because \"if:\" condition %q uses always(), but the step %s. use \"if: success() || failure()\" or \"if: ${{ !cancelled() }}\" instead not to run it on cancellation",
+		n.If.Value,
+		what,
+	)
+	return nil
+}
+
+func (rule *RuleFailureHandling) checkFallibleOutputs(n *Job, ss []*String) {
+	reported := map[string]struct{}{}
+	for _, s := range ss {
+		if s == nil || !s.ContainsExpression() {
+			continue
+		}
+		for _, m := range reFailureHandlingNeedsOutput.FindAllStringSubmatch(s.Value, -1) {
+			id, name := strings.ToLower(m[1]), strings.ToLower(m[2])
+			failing, ok := rule.fallible[id][name]
+			if !ok {
+				continue
+			}
+			if _, ok := reported[id+"."+name]; ok {
+				continue
+			}
+			reported[id+"."+name] = struct{}{}
+			rule.Errorf(
+				s.Pos,
+				"output %q of job %q may be empty because %s has \"continue-on-error: true\". check the output is not empty or make the failure stop this job",
+				m[2],
+				m[1],
+				failing,
+			)
+		}
+	}
+}
+
+func failureHandlingStepStrings(n *Step) []*String {
+	ss := []*String{n.Name, n.If}
+	switch e := n.Exec.(type) {
+	case *ExecRun:
+		ss = append(ss, e.Run)
+	case *ExecAction:
+		for _, i := range e.Inputs {
+			ss = append(ss, i.Value)
+		}
+		ss = append(ss, e.Args)
+	}
+	return appendEnvStrings(ss, n.Env)
+}
+
+func failureHandlingStepDeploys(n *Step) bool {
+	for _, s := range []*String{n.ID, n.Name} {
+		if s != nil && reFailureHandlingDeploy.MatchString(s.Value) {
+			return true
+		}
+	}
+	if e, ok := n.Exec.(*ExecAction); ok && e.Uses != nil {
+		return reFailureHandlingDeploy.MatchString(e.Uses.Value)
+	}
+	return false
+}
+
+func containsSecrets(ss []*String) bool {
+	for _, s := range ss {
+		if s != nil && s.ContainsExpression() && reFailureHandlingSecrets.MatchString(s.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportsStatus returns true when the strings refer the results of jobs or steps. Jobs or steps
+// which report the results like notifications are intended to run even on cancellation.
+func reportsStatus(ss []*String) bool {
+	for _, s := range ss {
+		if s != nil && s.ContainsExpression() && reFailureHandlingStatus.MatchString(s.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func isContinueOnError(b *Bool) bool {
+	return b != nil && b.Expression == nil && b.Value
+}
+
+// isAlwaysCond returns true when the "if:" condition calls always() without checking cancelled().
+// Such condition is true even when the workflow run is cancelled.
+func isAlwaysCond(n *String) bool {
+	if n == nil {
+		return false
+	}
+	src := strings.TrimSpace(n.Value)
+	if strings.HasPrefix(src, "${{") && strings.HasSuffix(src, "}}") {
+		src = src[3 : len(src)-2]
+	}
+	expr, err := NewExprParser().Parse(NewExprLexer(src + "}}"))
+	if err != nil {
+		return false
+	}
+	always, cancelled := false, false
+	VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+		if c, ok := n.(*FuncCallNode); ok && entering {
+			switch strings.ToLower(c.Callee) {
+			case "always":
+				always = true
+			case "cancelled":
+				cancelled = true
+			}
+		}
+	})
+	return always && !cancelled
+}
@@ -0,0 +1,31 @@
+package actionlint
+
+import (
+	"testing"
+)
+
+func TestRuleFailureHandlingAlwaysCond(t *testing.T) {
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"always()", true},
+		{"${{ always() }}", true},
+		{"ALWAYS()", true},
+		{"always() && github.event_name == 'push'", true},
+		{"${{ always() && !cancelled() }}", false},
+		{"!cancelled()", false},
+		{"success() || failure()", false},
+		{"github.ref == 'always()'", false},
+		{"always(", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.cond, func(t *testing.T) {
+			have := isAlwaysCond(&String{Value: tc.cond, Pos: &Pos{}})
+			if have != tc.want {
+				t.Fatalf("wanted %v but got %v for condition %q", tc.want, have, tc.cond)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package actionlint
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reGitPushCommand = regexp.MustCompile(`\bgit\s+push\b([^\n;&|]*)`)
+	reGitTagCommand  = regexp.MustCompile(`\bgit\s+tag\s`)
+	// Remote URL with a token like "https://x-access-token:${{ secrets.PAT }}@github.com/..."
+	reGitPushTokenURL = regexp.MustCompile(`https://[^\s'"]*(\$\{\{[^}]*\}\})@github\.com`)
+	// Commit messages which skip workflow runs
+	// https://docs.github.com/en/actions/managing-workflow-runs/skipping-workflow-runs
+	reGitPushSkipCI = regexp.MustCompile(`(?i)\[(?:skip ci|ci skip|no ci|skip actions|actions skip)\]|skip-checks:\s*true`)
+	// Conditions which prevent the workflow from running for the pushed commits
+	reGitPushGuardCondition = regexp.MustCompile(`github\.(?:actor|triggering_actor|event\.pusher|event\.head_commit|event\.sender)|skip ci|ci skip`)
+)
+
+// gitPushAction is an action to push commits or tags.
+type gitPushAction struct {
+	// tokenInput is the name of input for the token. Empty means the credentials persisted by
+	// actions/checkout are used.
+	tokenInput string
+	// tagInputs is the names of inputs to push tags.
+	tagInputs []string
+	// messageInput is the name of input for the commit message.
+	messageInput string
+}
+
+// gitPushActions is the table of popular actions to push commits. Keys are "{owner}/{repo}" in
+// lower case.
+var gitPushActions = map[string]*gitPushAction{
+	"stefanzweifel/git-auto-commit-action": {tagInputs: []string{"tagging_message", "tag_name"}, messageInput: "commit_message"},
+	"endbug/add-and-commit":                {tagInputs: []string{"tag"}, messageInput: "message"},
+	"ad-m/github-push-action":              {tokenInput: "github_token", tagInputs: []string{"tags"}},
+}
+
+// gitPushStep is a step to push commits or tags to the repository.
+type gitPushStep struct {
+	pos  *Pos
+	what string
+	// token is the token used to push. nil means the token is unknown.
+	token *String
+	// defaultToken is true when the default token, which is GITHUB_TOKEN, is used to push.
+	defaultToken bool
+	// tokenAt is where the token is passed like "\"token\" input of actions/checkout".
+	tokenAt string
+	tags    bool
+	commits bool
+	// skipCI is true when the commit message skips workflow runs like "[skip ci]".
+	skipCI bool
+	// guarded is true when "if:" of the step prevents the infinite loop like checking github.actor.
+	guarded bool
+}
+
+// RuleGitPush is a rule to check workflows pushing commits or tags to the repository. Pushes with
+// GITHUB_TOKEN do not trigger workflows on "push" event, and pushes with a personal access token
+// from a workflow triggered by "push" event may trigger the workflow again infinitely. This rule
+// checks all workflow files in the project at once to know the workflows triggered by the pushes.
+type RuleGitPush struct {
+	RuleBase
+}
+
+// NewRuleGitPush creates a new RuleGitPush instance.
+func NewRuleGitPush() *RuleGitPush {
+	return &RuleGitPush{
+		RuleBase: RuleBase{
+			name: "git-push",
+			desc: "Checks tokens and triggers of workflows pushing commits or tags to the repository",
+		},
+	}
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleGitPush) VisitProject(files []*ProjectFile) error {
+	var tagged *ProjectFile // Workflow triggered by pushing tags
+	for _, f := range files {
+		if tagged == nil && isTriggeredByPushingTags(f.Workflow) {
+			tagged = f
+		}
+	}
+
+	for _, f := range files {
+		w := f.Workflow
+		for _, j := range w.Jobs {
+			for _, s := range rule.pushSteps(j) {
+				if s.tags && tagged != nil && (s.defaultToken || isGitHubToken(s.token)) {
+					rule.FileErrorf(
+						f.Path,
+						s.pos,
+						"tags pushed by %s with GITHUB_TOKEN do not trigger workflow %q on \"push\" event since events caused by GITHUB_TOKEN do not create new workflow runs. pass a personal access token or a GitHub App token to %s",
+						s.what,
+						tagged.Path,
+						s.tokenAt,
+					)
+				}
+				if s.commits && s.token != nil && !isGitHubToken(s.token) && !s.skipCI && !s.guarded {
+					rule.checkInfiniteLoop(f.Path, w, j, s)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *RuleGitPush) checkInfiniteLoop(path string, w *Workflow, j *Job, s *gitPushStep) {
+	if j.If != nil && reGitPushGuardCondition.MatchString(j.If.Value) {
+		return
+	}
+	for _, e := range w.On {
+		p, ok := e.(*WebhookEvent)
+		if !ok || p.Hook.Value != "push" {
+			continue
+		}
+		if !p.Paths.IsEmpty() || !p.PathsIgnore.IsEmpty() {
+			continue
+		}
+		// When only tag filters are defined, pushing branches does not trigger the workflow
+		if p.Branches.IsEmpty() && p.BranchesIgnore.IsEmpty() && !(p.Tags.IsEmpty() && p.TagsIgnore.IsEmpty()) {
+			continue
+		}
+		rule.FileErrorf(
+			path,
+			s.pos,
+			"%s pushes commits with a token other than GITHUB_TOKEN at %s and this workflow is triggered by \"push\" event at line:%d,col:%d. the pushed commits trigger this workflow again and may cause an infinite loop. add \"paths-ignore:\" filter, check \"github.actor\" at \"if:\", or include \"[skip ci]\" in the commit message",
+			s.what,
+			s.tokenAt,
+			p.Pos.Line,
+			p.Pos.Col,
+		)
+		return
+	}
+}
+
+// pushSteps returns steps pushing commits or tags in the job.
+func (rule *RuleGitPush) pushSteps(j *Job) []*gitPushStep {
+	ret := []*gitPushStep{}
+	var checkout *ExecAction // The last actions/checkout step
+	for _, s := range j.Steps {
+		guarded := s.If != nil && reGitPushGuardCondition.MatchString(s.If.Value)
+		switch e := s.Exec.(type) {
+		case *ExecAction:
+			if e.Spec == nil || e.Spec.Kind != ActionSpecKindRepository {
+				continue
+			}
+			n := strings.ToLower(e.Spec.Owner + "/" + e.Spec.Repo)
+			if n == "actions/checkout" {
+				checkout = e
+				continue
+			}
+			a, ok := gitPushActions[n]
+			if !ok {
+				continue
+			}
+			p := &gitPushStep{pos: e.Uses.Pos, what: strconv.Quote(e.Uses.Value), commits: true, guarded: guarded}
+			for _, t := range a.tagInputs {
+				if i, ok := e.Inputs[t]; ok && i.Value != nil && i.Value.Value != "" && i.Value.Value != "false" {
+					p.tags = true
+				}
+			}
+			if a.messageInput != "" {
+				if i, ok := e.Inputs[a.messageInput]; ok && i.Value != nil {
+					p.skipCI = reGitPushSkipCI.MatchString(i.Value.Value)
+				}
+			}
+			if a.tokenInput != "" {
+				p.tokenAt = strconv.Quote(a.tokenInput) + " input"
+				if i, ok := e.Inputs[a.tokenInput]; ok {
+					p.token = i.Value
+				}
+			} else {
+				setCheckoutToken(p, checkout)
+			}
+			ret = append(ret, p)
+		case *ExecRun:
+			if e.Run == nil {
+				continue
+			}
+			script := e.Run.Value
+			ms := reGitPushCommand.FindAllStringSubmatch(script, -1)
+			if len(ms) == 0 {
+				continue
+			}
+			p := &gitPushStep{
+				pos:     e.Run.Pos,
+				what:    "\"git push\" command",
+				skipCI:  reGitPushSkipCI.MatchString(script),
+				guarded: guarded,
+			}
+			for _, m := range ms {
+				if isGitPushingTags(m[1], script) {
+					p.tags = true
+				} else {
+					p.commits = true
+				}
+			}
+			if m := reGitPushTokenURL.FindStringSubmatch(script); m != nil {
+				p.token = &String{Value: m[1], Pos: e.Run.Pos}
+				p.tokenAt = "the remote URL"
+			} else {
+				setCheckoutToken(p, checkout)
+			}
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// setCheckoutToken sets the token persisted by actions/checkout to the step.
+func setCheckoutToken(p *gitPushStep, checkout *ExecAction) {
+	if checkout == nil {
+		return // The token is unknown
+	}
+	if i, ok := checkout.Inputs["persist-credentials"]; ok && i.Value != nil && i.Value.Value == "false" {
+		return
+	}
+	p.tokenAt = "\"token\" input of actions/checkout"
+	if i, ok := checkout.Inputs["token"]; ok {
+		p.token = i.Value
+	} else {
+		p.defaultToken = true
+	}
+}
+
+// isGitPushingTags returns true when the arguments of "git push" push tags.
+func isGitPushingTags(args, script string) bool {
+	if strings.Contains(args, "--tags") || strings.Contains(args, "--follow-tags") || strings.Contains(args, "refs/tags/") {
+		return true
+	}
+	if !reGitTagCommand.MatchString(script) {
+		return false
+	}
+	// "git tag v1.0.0 && git push origin v1.0.0"
+	n := 0
+	for _, a := range strings.Fields(args) {
+		if !strings.HasPrefix(a, "-") {
+			n++
+		}
+	}
+	return n >= 2
+}
+
+// isTriggeredByPushingTags returns true when the workflow is triggered by pushing tags matched by
+// "tags:" filter.
+func isTriggeredByPushingTags(w *Workflow) bool {
+	for _, e := range w.On {
+		p, ok := e.(*WebhookEvent)
+		if !ok || p.Hook.Value != "push" {
+			continue
+		}
+		// Workflows without tag filters are usually not intended to run on pushing tags
+		if !p.Tags.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
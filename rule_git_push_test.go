@@ -0,0 +1,31 @@
+package actionlint
+
+import "testing"
+
+func TestRuleGitPushIsPushingTags(t *testing.T) {
+	testCases := []struct {
+		script string
+		want   bool
+	}{
+		{"git push", false},
+		{"git push origin main", false},
+		{"git push --tags", true},
+		{"git push --follow-tags origin main", true},
+		{"git push origin refs/tags/v1.0.0", true},
+		{"git tag v1.0.0\ngit push origin v1.0.0", true},
+		{"git tag v1.0.0\ngit push", false},
+		{"git tag -a v1.0.0 -m 'v1.0.0'\ngit push -u origin v1.0.0", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.script, func(t *testing.T) {
+			m := reGitPushCommand.FindStringSubmatch(tc.script)
+			if m == nil {
+				t.Fatal("git push command was not found")
+			}
+			if have := isGitPushingTags(m[1], tc.script); have != tc.want {
+				t.Fatalf("wanted %v but got %v", tc.want, have)
+			}
+		})
+	}
+}
@@ -58,13 +58,13 @@ func (rule *RuleGlob) checkFilePathGlobs(filter *WebhookEventFilter) {
 func (rule *RuleGlob) globErrors(errs []InvalidGlobPattern, pos *Pos, quoted bool) {
 	for i := range errs {
 		err := &errs[i]
-		p := *pos
+		p := &Pos{Line: pos.Line, Col: pos.Col}
 		if quoted {
 			p.Col++
 		}
 		if err.Column != 0 {
 			p.Col += err.Column - 1
 		}
-		rule.Errorf(&p, "%s. note: filter pattern syntax is explained at https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet", err.Message)
+		rule.Errorf(p, "%s. note: filter pattern syntax is explained at https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet", err.Message)
 	}
 }
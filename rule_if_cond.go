@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
 // RuleIfCond is a rule to check if: conditions.
@@ -47,4 +48,59 @@ func (rule *RuleIfCond) checkIfCond(n *String) {
 		"if: condition %q is always evaluated to true because extra characters are around ${{ }}",
 		n.Value,
 	)
+	rule.suggestUnwrap(n)
+}
+
+// suggestUnwrap suggests the fix to remove all ${{ }} in the condition so that the entire condition
+// is evaluated as one expression. For example, `${{ a }} == 'x'` is fixed to `a == 'x'`.
+func (rule *RuleIfCond) suggestUnwrap(n *String) {
+	var b strings.Builder
+	src := n.Value
+	start, end := -1, 0
+	for {
+		idx := strings.Index(src[end:], "${{")
+		if idx == -1 {
+			break
+		}
+		idx += end
+		l := NewExprLexer(src[idx+3:])
+		e, err := NewExprParser().Parse(l)
+		if err != nil || l.Offset() == 0 {
+			return
+		}
+		if start < 0 {
+			start = idx
+		} else {
+			b.WriteString(src[end:idx])
+		}
+		inner := strings.TrimSpace(src[idx+3 : idx+3+l.Offset()-2])
+		switch e.(type) {
+		case *CompareOpNode, *LogicalOpNode:
+			b.WriteString("(" + inner + ")") // Keep the precedence of the operators
+		default:
+			b.WriteString(inner)
+		}
+		end = idx + 3 + l.Offset()
+	}
+	if start < 0 || !canSuggestFixInString(src[:end], n.Quoted) {
+		return
+	}
+
+	// Check the fixed condition is a valid expression
+	fixed := src[:start] + b.String() + src[end:]
+	l := NewExprLexer(fixed + "}}")
+	if _, err := NewExprParser().Parse(l); err != nil || l.Offset() != len(fixed)+2 {
+		return
+	}
+
+	col := n.Pos.Col
+	if n.Quoted {
+		col++
+	}
+	rule.Suggest(&Suggestion{
+		Message:     "remove ${{ }} to evaluate the entire condition as one expression",
+		Start:       &Pos{Line: n.Pos.Line, Col: col + utf8.RuneCountInString(src[:start])},
+		End:         &Pos{Line: n.Pos.Line, Col: col + utf8.RuneCountInString(src[:end])},
+		Replacement: b.String(),
+	})
 }
@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -64,3 +65,54 @@ func TestRuleIfCond(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleIfCondSuggestUnwrap(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{
+			input: "${{ github.ref }} == 'refs/heads/main'",
+			want:  "github.ref == 'refs/heads/main'",
+		},
+		{
+			input: "${{ github.event_name == 'push' }} && ${{ github.ref_name == 'main' || false }}",
+			want:  "(github.event_name == 'push') && (github.ref_name == 'main' || false)",
+		},
+		{
+			input: `"!${{ github.event.forced }}"`,
+			want:  `"!github.event.forced"`,
+		},
+		{
+			input: "${{ github.actor }} is not a bot",
+			want:  "", // The fixed condition is not a valid expression
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - if: " + tc.input + "\n        run: echo\n"
+			errs := testCheckRule(t, NewRuleIfCond(), nil, src)
+			if len(errs) != 1 {
+				t.Fatal("one error was expected but got", errs)
+			}
+			if tc.want == "" {
+				if len(errs[0].Suggestions) != 0 {
+					t.Fatal("no fix was expected but got", errs[0].Suggestions)
+				}
+				return
+			}
+			if len(errs[0].Suggestions) != 1 {
+				t.Fatal("one fix was expected but got", errs[0].Suggestions)
+			}
+			b, err := errs[0].Suggestions[0].Apply([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Replace(src, tc.input, tc.want, 1)
+			if string(b) != want {
+				t.Fatalf("wanted %q but got %q", want, string(b))
+			}
+		})
+	}
+}
@@ -94,13 +94,16 @@ func (rule *RuleJobNeeds) VisitWorkflowPost(n *Workflow) error {
 	for id, node := range rule.nodes {
 		node.resolved = make([]*jobNode, 0, len(node.needs))
 		for _, dep := range node.needs {
-			n, ok := rule.nodes[dep]
+			d, ok := rule.nodes[dep]
 			if !ok {
+				if _, ok := n.Jobs[dep]; ok {
+					continue // The job exists but was skipped by the job filter of visitor
+				}
 				rule.Errorf(node.pos, "job %q needs job %q which does not exist in this workflow", id, dep)
 				valid = false
 				continue
 			}
-			node.resolved = append(node.resolved, n)
+			node.resolved = append(node.resolved, d)
 		}
 	}
 	if !valid {
@@ -121,21 +124,19 @@ func (rule *RuleJobNeeds) VisitWorkflowPost(n *Workflow) error {
 			}
 		}
 
-		var msg strings.Builder
-		msg.WriteString("cyclic dependencies in \"needs\" job configurations are detected. detected cycle is ")
-
-		msg.WriteString(strconv.Quote(start.id))
+		var cycle strings.Builder
+		cycle.WriteString(strconv.Quote(start.id))
 		from, to := start, edges[start]
 		for {
-			msg.WriteString(" -> ")
-			msg.WriteString(strconv.Quote(to.id))
+			cycle.WriteString(" -> ")
+			cycle.WriteString(strconv.Quote(to.id))
 			from, to = to, edges[to]
 			if from == start {
 				break
 			}
 		}
 
-		rule.Error(start.pos, msg.String())
+		rule.Errorf(start.pos, "cyclic dependencies in \"needs\" job configurations are detected. detected cycle is %s", cycle.String())
 	}
 
 	return nil
@@ -0,0 +1,260 @@
+package actionlint
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// actionsLimits is a table of limits of GitHub Actions. Workflows exceeding the limits fail at
+// runtime. The table can be updated with "limits" dataset without updating actionlint.
+type actionsLimits struct {
+	// MatrixJobs is the maximum number of jobs generated by a matrix per workflow run.
+	// https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs
+	MatrixJobs int `json:"matrix-jobs"`
+	// JobNameLength is the maximum number of characters of job names at "name:".
+	JobNameLength int `json:"job-name-length"`
+	// StepNameLength is the maximum number of characters of step names at "name:".
+	StepNameLength int `json:"step-name-length"`
+	// EnvVarBytes is the maximum size of one environment variable ("NAME=value") in bytes. Processes
+	// cannot be spawned with a larger environment variable on Linux (MAX_ARG_STRLEN).
+	EnvVarBytes int `json:"env-var-bytes"`
+	// EnvBytes is the maximum total size of environment variables at one "env:" section in bytes.
+	EnvBytes int `json:"env-bytes"`
+	// WorkflowFileBytes is the maximum size of workflow file in bytes.
+	WorkflowFileBytes int `json:"workflow-file-bytes"`
+	// ExpressionLength is the maximum number of characters of one expression. GitHub rejects the
+	// workflow with "Exceeded max expression length" error when it is exceeded.
+	ExpressionLength int `json:"expression-length"`
+}
+
+var githubActionsLimits = actionsLimits{
+	MatrixJobs:        256,
+	JobNameLength:     255,
+	StepNameLength:    255,
+	EnvVarBytes:       128 * 1024,
+	EnvBytes:          256 * 1024,
+	WorkflowFileBytes: 512 * 1024,
+	ExpressionLength:  21000,
+}
+
+// RuleLimits is a rule to check workflows do not exceed limits of GitHub Actions. For example, the
+// number of jobs generated by a matrix, the length of job names, the size of environment variables,
+// the length of expressions, and the size of workflow file. Such workflows fail at runtime.
+type RuleLimits struct {
+	RuleBase
+	src    *SourceFile
+	limits *actionsLimits
+}
+
+// NewRuleLimits creates a new RuleLimits instance. The src parameter is the source file of the
+// workflow. When it is nil, the file size is not checked.
+func NewRuleLimits(src *SourceFile) *RuleLimits {
+	l := githubActionsLimits // Copy not to be affected by updating the dataset while checking
+	return &RuleLimits{
+		RuleBase: RuleBase{
+			name: "limits",
+			desc: "Checks workflows do not exceed limits of GitHub Actions such as the number of matrix jobs",
+		},
+		src:    src,
+		limits: &l,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleLimits) VisitWorkflowPre(n *Workflow) error {
+	if l := rule.limits.WorkflowFileBytes; rule.src != nil && len(rule.src.Content) > l {
+		rule.Errorf(
+			&Pos{Line: 1, Col: 1},
+			"size of this workflow file is %d bytes. GitHub does not accept workflow files larger than %d bytes",
+			len(rule.src.Content),
+			l,
+		)
+	}
+	rule.checkEnv(n.Env)
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleLimits) VisitJobPre(n *Job) error {
+	if n.Name != nil {
+		rule.checkName(n.Name, "job", rule.limits.JobNameLength)
+	}
+	rule.checkEnv(n.Env)
+	rule.checkIf(n.If)
+	if n.Strategy != nil && n.Strategy.Matrix != nil {
+		rule.checkMatrix(n.ID, n.Strategy.Matrix)
+	}
+	if n.WorkflowCall != nil {
+		for _, i := range n.WorkflowCall.Inputs {
+			rule.checkExprLength(i.Value)
+		}
+	}
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleLimits) VisitStep(n *Step) error {
+	if n.Name != nil {
+		rule.checkName(n.Name, "step", rule.limits.StepNameLength)
+	}
+	rule.checkEnv(n.Env)
+	rule.checkIf(n.If)
+	if e, ok := n.Exec.(*ExecAction); ok {
+		rule.checkActionInputs(e.Inputs)
+	}
+	return nil
+}
+
+func (rule *RuleLimits) checkName(name *String, what string, max int) {
+	// The length of name containing ${{ }} is known only at runtime
+	if name.ContainsExpression() {
+		return
+	}
+	if l := utf8.RuneCountInString(name.Value); l > max {
+		rule.Errorf(
+			name.Pos,
+			"%s name is %d characters long. GitHub allows %s names up to %d characters",
+			what,
+			l,
+			what,
+			max,
+		)
+	}
+}
+
+func (rule *RuleLimits) checkEnv(env *Env) {
+	if env == nil || env.Expression != nil {
+		return
+	}
+
+	total := 0
+	var first *Pos
+	for _, v := range env.Vars {
+		if v.Value == nil {
+			continue
+		}
+		rule.checkExprLength(v.Value)
+		// Note: Values containing ${{ }} may be longer at runtime
+		s := len(v.Name.Value) + 1 + len(v.Value.Value) // NAME=value
+		if s > rule.limits.EnvVarBytes {
+			rule.Errorf(
+				v.Name.Pos,
+				"size of environment variable %q is %d bytes. processes cannot be run with an environment variable larger than %d bytes",
+				v.Name.Value,
+				s,
+				rule.limits.EnvVarBytes,
+			)
+		}
+		total += s
+		if first == nil || v.Name.Pos.IsBefore(first) {
+			first = v.Name.Pos
+		}
+	}
+
+	if total > rule.limits.EnvBytes {
+		rule.Errorf(
+			first,
+			"total size of environment variables in this \"env\" section is %d bytes. it exceeds the limit %d bytes",
+			total,
+			rule.limits.EnvBytes,
+		)
+	}
+}
+
+// maxEnumeratedMatrixCombinations is the maximum number of combinations of matrix enumerated to count
+// the jobs. When a matrix has more combinations, the number is calculated without enumerating them.
+const maxEnumeratedMatrixCombinations = 65536
+
+func (rule *RuleLimits) checkMatrix(id *String, m *Matrix) {
+	jobs := 0
+	if combs, ok := rule.Capabilities().ExpandMatrix(m); ok {
+		jobs = len(combs)
+	} else if m.Expression == nil && (m.Exclude == nil || len(m.Exclude.Combinations) == 0) {
+		// Counting the jobs is too expensive. Calculate the number without enumerating combinations
+		jobs = 1
+		for _, r := range m.Rows {
+			if r.Expression != nil {
+				return
+			}
+			jobs *= len(r.Values)
+		}
+	}
+
+	if jobs > rule.limits.MatrixJobs {
+		rule.Errorf(
+			m.Pos,
+			"matrix of job %q generates %d jobs. GitHub allows a matrix to generate up to %d jobs per workflow run",
+			id.Value,
+			jobs,
+			rule.limits.MatrixJobs,
+		)
+	}
+}
+
+func (rule *RuleLimits) checkIf(cond *String) {
+	if cond == nil {
+		return
+	}
+	if cond.ContainsExpression() {
+		rule.checkExprLength(cond)
+		return
+	}
+	// The entire value of "if:" without ${{ }} is an expression
+	if l := utf8.RuneCountInString(cond.Value); l > rule.limits.ExpressionLength {
+		rule.exprLengthError(cond.Pos, l)
+	}
+}
+
+// checkExprLength checks the length of each ${{ }} expression in the string value.
+func (rule *RuleLimits) checkExprLength(s *String) {
+	if s == nil {
+		return
+	}
+	v := s.Value
+	for {
+		i := strings.Index(v, "${{")
+		if i < 0 {
+			return
+		}
+		v = v[i+3:]
+		_, offset, err := LexExpression(v)
+		if err != nil {
+			return // Broken expression is reported by "expression" rule
+		}
+		if l := utf8.RuneCountInString(strings.TrimSpace(v[:offset-2])); l > rule.limits.ExpressionLength {
+			rule.exprLengthError(s.Pos, l)
+		}
+		v = v[offset:]
+	}
+}
+
+func (rule *RuleLimits) exprLengthError(pos *Pos, l int) {
+	rule.Errorf(
+		pos,
+		"expression is %d characters long. GitHub allows expressions up to %d characters and rejects the workflow with \"Exceeded max expression length\" error",
+		l,
+		rule.limits.ExpressionLength,
+	)
+}
+
+// checkActionInputs checks sizes of inputs at "with:" of the action step. Inputs are passed to the
+// action as environment variables like INPUT_NAME so they are limited as well as "env:".
+func (rule *RuleLimits) checkActionInputs(inputs map[string]*Input) {
+	for _, i := range inputs {
+		if i.Value == nil {
+			continue
+		}
+		rule.checkExprLength(i.Value)
+		// Note: Values containing ${{ }} may be longer at runtime
+		s := len("INPUT_") + len(i.Name.Value) + 1 + len(i.Value.Value) // INPUT_NAME=value
+		if s > rule.limits.EnvVarBytes {
+			rule.Errorf(
+				i.Value.Pos,
+				"size of input %q is %d bytes. the input is passed to the action as an environment variable but processes cannot be run with an environment variable larger than %d bytes",
+				i.Name.Value,
+				s,
+				rule.limits.EnvVarBytes,
+			)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package actionlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRuleLimitsEnvSize(t *testing.T) {
+	restoreEmbeddedData(t)
+	githubActionsLimits.WorkflowFileBytes = 1024 * 1024
+
+	large := strings.Repeat("a", 128*1024)
+	src := `on: push
+env:
+  LARGE: ` + large + `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      A: ` + large[:100*1024] + `
+      B: ` + large[:100*1024] + `
+      C: ` + large[:100*1024] + `
+    steps:
+      - run: echo
+        env:
+          OK: ` + large[:100*1024] + `
+`
+	errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src)
+	want := []string{
+		`size of environment variable "LARGE" is 131078 bytes. processes cannot be run with an environment variable larger than 131072 bytes`,
+		`total size of environment variables in this "env" section is 307206 bytes. it exceeds the limit 262144 bytes`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("wanted %d errors but got %v", len(want), errs)
+	}
+	for i, e := range errs {
+		if e.Message != want[i] {
+			t.Errorf("wanted %q but got %q", want[i], e.Message)
+		}
+	}
+	if errs[1].Line != 8 {
+		t.Errorf("total size error should be reported at the first variable but got line %d", errs[1].Line)
+	}
+}
+
+func TestRuleLimitsWorkflowFileSize(t *testing.T) {
+	restoreEmbeddedData(t)
+	githubActionsLimits.WorkflowFileBytes = 100
+
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	if errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected but got", errs)
+	}
+
+	src += "      # " + strings.Repeat("a", 100) + "\n"
+	errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src)
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	want := fmt.Sprintf("size of this workflow file is %d bytes. GitHub does not accept workflow files larger than 100 bytes", len(src))
+	if errs[0].Message != want || errs[0].Line != 1 || errs[0].Column != 1 {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+}
+
+func TestRuleLimitsMatrixExcludeObject(t *testing.T) {
+	restoreEmbeddedData(t)
+	githubActionsLimits.MatrixJobs = 3
+
+	// Exclude matches an object partially. 2 * 2 - 2 = 2 jobs
+	src := `on: push
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [linux, windows]
+        target:
+          - { arch: x64, debug: true }
+          - { arch: arm64, debug: false }
+        exclude:
+          - target: { arch: arm64 }
+        include:
+          - os: linux
+            target: { arch: x64, debug: true }
+            extra: 1
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	if errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected but got", errs)
+	}
+}
+
+func TestRuleLimitsExpressionLength(t *testing.T) {
+	restoreEmbeddedData(t)
+	githubActionsLimits.ExpressionLength = 30
+
+	long := "format('{0}', '" + strings.Repeat("x", 20) + "')" // 37 characters
+	short := "github.sha"
+	src := `on: push
+env:
+  A: ${{ ` + short + ` }} ${{ ` + long + ` }}
+jobs:
+  test:
+    if: ${{ ` + long + ` }}
+    runs-on: ubuntu-latest
+    steps:
+      - if: ` + long + `
+        run: echo
+      - if: ` + short + `
+        uses: actions/checkout@v4
+        with:
+          ref: ${{ ` + long + ` }}
+          path: ${{ ` + short + ` }}
+  call:
+    uses: ./.github/workflows/reusable.yaml
+    with:
+      foo: ${{ ` + long + ` }}
+`
+	errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src)
+	sort.Stable(ByErrorPosition(errs))
+	lines := []int{3, 6, 9, 14, 19}
+	if len(errs) != len(lines) {
+		t.Fatalf("wanted %d errors but got %v", len(lines), errs)
+	}
+	for i, e := range errs {
+		if e.Line != lines[i] {
+			t.Errorf("wanted error at line %d but got %v", lines[i], e)
+		}
+		want := "expression is 37 characters long. GitHub allows expressions up to 30 characters"
+		if !strings.HasPrefix(e.Message, want) {
+			t.Errorf("wanted message starting with %q but got %q", want, e.Message)
+		}
+	}
+}
+
+func TestRuleLimitsActionInputSize(t *testing.T) {
+	restoreEmbeddedData(t)
+	githubActionsLimits.EnvVarBytes = 20
+
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: main
+          path: ` + strings.Repeat("x", 20) + `
+      - run: echo
+`
+	errs := testCheckRule(t, NewRuleLimits(NewSourceFile("test.yaml", []byte(src))), nil, src)
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	want := `size of input "path" is 31 bytes. the input is passed to the action as an environment variable but processes cannot be run with an environment variable larger than 20 bytes`
+	if errs[0].Message != want || errs[0].Line != 9 {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+}
+
+type testMatrixExpander int
+
+func (n testMatrixExpander) ExpandMatrix(m *Matrix) ([]map[string]RawYAMLValue, bool) {
+	return make([]map[string]RawYAMLValue, int(n)), true
+}
+
+func TestRuleLimitsMatrixExpanderCapability(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [linux, windows]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	r := NewRuleLimits(NewSourceFile("test.yaml", []byte(src)))
+	r.SetCapabilities(&Capabilities{Matrix: testMatrixExpander(300)})
+	errs := testCheckRule(t, r, nil, src)
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	want := `matrix of job "test" generates 300 jobs. GitHub allows a matrix to generate up to 256 jobs per workflow run`
+	if errs[0].Message != want {
+		t.Fatalf("wanted %q but got %q", want, errs[0].Message)
+	}
+}
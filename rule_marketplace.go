@@ -0,0 +1,205 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleMarketplace is a rule to check actions published to GitHub Marketplace from the repository
+// are ready to publish. Actions in the directories matching to "actions" in the rule config are
+// checked. Names and descriptions are required, branding must be one of the supported icons and
+// colors, names must be unique in the repository, and README must exist in the action directory.
+// The rule is disabled by default.
+// https://docs.github.com/en/actions/creating-actions/publishing-actions-in-github-marketplace
+type RuleMarketplace struct {
+	RuleBase
+	project *Project
+	cwd     string
+}
+
+// NewRuleMarketplace creates a new RuleMarketplace instance. The project parameter is the project
+// which the checked workflows belong to. The cwd parameter is a working directory which file paths
+// of the reported errors are relative to.
+func NewRuleMarketplace(project *Project, cwd string) *RuleMarketplace {
+	return &RuleMarketplace{
+		RuleBase: RuleBase{
+			name: "marketplace",
+			desc: "Checks actions published to GitHub Marketplace from the repository are ready to publish",
+		},
+		project: project,
+		cwd:     cwd,
+	}
+}
+
+// marketplaceAction is an action checked by the "marketplace" rule.
+type marketplaceAction struct {
+	dir  string // Slash-separated path relative to the repository root
+	path string // Path of the metadata file reported in errors
+	meta *ActionMetadata
+	node *yaml.Node // Mapping node at top of the metadata file
+}
+
+// spec returns the path of the action directory as "uses:" of local action like "./actions/foo".
+func (a *marketplaceAction) spec() string {
+	if a.dir == "." {
+		return "."
+	}
+	return "./" + a.dir
+}
+
+// pos returns the position of the key in the metadata file. Nested keys are separated with ".".
+// When the key is not found, the position of its nearest parent or the head of the file is returned.
+func (a *marketplaceAction) pos(key string) *Pos {
+	pos := &Pos{Line: 1, Col: 1}
+	m := a.node
+	for _, k := range strings.Split(key, ".") {
+		if m == nil || m.Kind != yaml.MappingNode {
+			return pos
+		}
+		var v *yaml.Node
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if c := m.Content[i]; c.Value == k {
+				pos = &Pos{Line: c.Line, Col: c.Column}
+				v = m.Content[i+1]
+				break
+			}
+		}
+		if v == nil {
+			return pos
+		}
+		m = v
+	}
+	return pos
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleMarketplace) VisitProject(files []*ProjectFile) error {
+	cfg := rule.Config()
+	if cfg == nil || rule.project == nil || len(cfg.Rules.Marketplace.Actions) == 0 {
+		return nil
+	}
+
+	names := map[string]*marketplaceAction{}
+	for _, a := range rule.findActions(cfg.Rules.Marketplace.Actions) {
+		rule.checkAction(a, names)
+	}
+	return nil
+}
+
+func (rule *RuleMarketplace) checkAction(a *marketplaceAction, names map[string]*marketplaceAction) {
+	m := a.meta
+	if m.Name == "" {
+		rule.FileErrorf(a.path, a.pos("name"), "\"name\" is required in metadata of action %q published to GitHub Marketplace", a.spec())
+	} else if prev, ok := names[strings.ToLower(m.Name)]; ok {
+		rule.FileErrorf(a.path, a.pos("name"), "name %q of action %q is already used by action %q. names of actions published to GitHub Marketplace must be unique", m.Name, a.spec(), prev.spec())
+	} else {
+		names[strings.ToLower(m.Name)] = a
+	}
+
+	if strings.TrimSpace(m.Description) == "" {
+		rule.FileErrorf(a.path, a.pos("description"), "\"description\" is required in metadata of action %q published to GitHub Marketplace", a.spec())
+	}
+
+	if i := m.Branding.Icon; i != "" {
+		if _, ok := BrandingIcons[strings.ToLower(i)]; !ok {
+			rule.FileErrorf(a.path, a.pos("branding.icon"), "icon %q at \"branding.icon\" of action %q is not supported by GitHub Marketplace. see https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#brandingicon for supported icons", i, a.spec())
+		}
+	}
+	if c := m.Branding.Color; c != "" {
+		if _, ok := BrandingColors[strings.ToLower(c)]; !ok {
+			ns := make([]string, 0, len(BrandingColors))
+			for n := range BrandingColors {
+				ns = append(ns, n)
+			}
+			rule.FileErrorf(a.path, a.pos("branding.color"), "color %q at \"branding.color\" of action %q is not supported by GitHub Marketplace. supported colors are %s", c, a.spec(), sortedQuotes(ns))
+		}
+	}
+
+	if !rule.hasReadme(a.dir) {
+		rule.FileErrorf(a.path, a.pos("name"), "README file does not exist in directory of action %q. GitHub Marketplace shows the README as the page of the action", a.spec())
+	}
+}
+
+// findActions finds actions in the directories matching to the glob patterns. The actions are
+// sorted by their directories.
+func (rule *RuleMarketplace) findActions(patterns []string) []*marketplaceAction {
+	root := rule.project.RootDir()
+	fsys := os.DirFS(root)
+	dirs := map[string]struct{}{}
+	for _, pat := range patterns {
+		ms, err := doublestar.Glob(fsys, strings.TrimPrefix(pat, "./"))
+		if err != nil {
+			rule.Debug("Could not find action directories matching to %q: %s", pat, err)
+			continue
+		}
+		for _, d := range ms {
+			dirs[d] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(dirs))
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+
+	ret := make([]*marketplaceAction, 0, len(sorted))
+	for _, d := range sorted {
+		if a := rule.readAction(d); a != nil {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+func (rule *RuleMarketplace) readAction(dir string) *marketplaceAction {
+	for _, f := range []string{"action.yml", "action.yaml"} {
+		p := filepath.Join(rule.project.RootDir(), filepath.FromSlash(dir), f)
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		var n yaml.Node
+		var meta ActionMetadata
+		if err := yaml.Unmarshal(b, &n); err != nil || len(n.Content) == 0 || n.Content[0].Kind != yaml.MappingNode {
+			rule.Debug("Skip checking action metadata %q since it could not be parsed", p)
+			return nil
+		}
+		if err := n.Decode(&meta); err != nil {
+			rule.Debug("Skip checking action metadata %q since it could not be parsed: %s", p, err)
+			return nil
+		}
+
+		if r, err := filepath.Rel(rule.cwd, p); err == nil {
+			p = r
+		}
+		return &marketplaceAction{dir: dir, path: p, meta: &meta, node: n.Content[0]}
+	}
+	rule.Debug("Directory %q matches to \"actions\" in \"marketplace\" rule config but action metadata file is not found", dir)
+	return nil
+}
+
+// hasReadme returns true when README file like "README.md" exists in the directory. The file name is
+// case-insensitive.
+func (rule *RuleMarketplace) hasReadme(dir string) bool {
+	entries, err := os.ReadDir(filepath.Join(rule.project.RootDir(), filepath.FromSlash(dir)))
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		n := strings.ToLower(e.Name())
+		if n == "readme" || strings.HasPrefix(n, "readme.") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,16 @@
 package actionlint
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // RuleMatrix is a rule checker to check 'matrix' field of job.
 type RuleMatrix struct {
 	RuleBase
+	// combs is combinations of the matrix of the current job. It is nil when the job has no matrix or
+	// the combinations cannot be known statically.
+	combs []map[string]RawYAMLValue
 }
 
 // NewRuleMatrix creates new RuleMatrix instance.
@@ -19,11 +25,16 @@ func NewRuleMatrix() *RuleMatrix {
 
 // VisitJobPre is callback when visiting Job node before visiting its children.
 func (rule *RuleMatrix) VisitJobPre(n *Job) error {
+	rule.combs = nil
 	if n.Strategy == nil || n.Strategy.Matrix == nil || n.Strategy.Matrix.Expression != nil {
 		return nil
 	}
 
 	m := n.Strategy.Matrix
+	if cs, ok := rule.Capabilities().ExpandMatrix(m); ok {
+		rule.combs = cs
+	}
+	rule.checkContinueOnError(n.ContinueOnError)
 
 	for _, row := range m.Rows {
 		rule.checkDuplicateInRow(row)
@@ -43,6 +54,91 @@ func (rule *RuleMatrix) VisitJobPre(n *Job) error {
 	return nil
 }
 
+// VisitStep is callback when visiting Step node.
+func (rule *RuleMatrix) VisitStep(n *Step) error {
+	rule.checkContinueOnError(n.ContinueOnError)
+	return nil
+}
+
+// checkContinueOnError checks matrix values referenced at "continue-on-error" are defined in all
+// combinations. For example, when `experimental: true` is only added to some combinations by
+// "include", `matrix.experimental` is null in other combinations. It is falsy so it might not be
+// intended.
+func (rule *RuleMatrix) checkContinueOnError(b *Bool) {
+	if b == nil || b.Expression == nil || len(rule.combs) == 0 {
+		return
+	}
+
+	seen := map[string]struct{}{}
+	visitExprNodesInString(b.Expression, func(n ExprNode, pos *Pos) {
+		d, ok := n.(*ObjectDerefNode)
+		if !ok {
+			return
+		}
+		if v, ok := d.Receiver.(*VariableNode); !ok || v.Name != "matrix" {
+			return
+		}
+		k := d.Property
+		if _, ok := seen[k]; ok {
+			return
+		}
+		seen[k] = struct{}{}
+
+		var missing map[string]RawYAMLValue
+		name := ""
+		for _, c := range rule.combs {
+			if n, ok := findMatrixKey(c, k); ok {
+				name = n
+			} else if missing == nil {
+				missing = c
+			}
+		}
+		if name == "" || missing == nil {
+			return // Undefined property is reported by "expression" rule
+		}
+
+		rule.Errorf(
+			pos,
+			"matrix value %q referenced at \"continue-on-error\" is not defined in some matrix combinations such as %s. it is evaluated to null, which is falsy, in the combinations. define the value in all combinations like \"%s: [false]\"",
+			name,
+			matrixCombinationString(missing),
+			name,
+		)
+	})
+}
+
+// findMatrixKey finds the key in the matrix combination case-insensitively and returns the key
+// with its original case.
+func findMatrixKey(c map[string]RawYAMLValue, key string) (string, bool) {
+	for k := range c {
+		if strings.EqualFold(k, key) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func matrixCombinationString(c map[string]RawYAMLValue) string {
+	ks := make([]string, 0, len(c))
+	for k := range c {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+
+	var b strings.Builder
+	b.WriteRune('{')
+	for i, k := range ks {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteString(": ")
+		b.WriteString(c[k].String())
+	}
+	b.WriteRune('}')
+	return b.String()
+}
+
 func (rule *RuleMatrix) checkDuplicateInRow(row *MatrixRow) {
 	if row.Values == nil {
 		return // Give up when ${{ }} is specified
@@ -134,6 +230,130 @@ func isYAMLValueSubset(v, sub RawYAMLValue) bool {
 	}
 }
 
+// defaultMatrixExpander is the default implementation of MatrixExpander. It enumerates up to
+// maxEnumeratedMatrixCombinations combinations.
+type defaultMatrixExpander struct{}
+
+// ExpandMatrix expands the matrix into combinations of matrix values in the same way as GitHub.
+func (defaultMatrixExpander) ExpandMatrix(m *Matrix) ([]map[string]RawYAMLValue, bool) {
+	return expandMatrixCombinations(m, maxEnumeratedMatrixCombinations)
+}
+
+// expandMatrixCombinations expands the matrix into combinations of matrix values in the same way as
+// GitHub. Combinations matching to "exclude" are removed, then each combination at "include" is
+// merged into the existing combinations whose original values match to it. When it cannot be merged
+// into any combination, it is added as a new combination. It returns false when the matrix contains
+// ${{ }} or the number of combinations exceeds the max.
+// https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs#expanding-or-adding-matrix-configurations
+func expandMatrixCombinations(m *Matrix, max int) ([]map[string]RawYAMLValue, bool) {
+	if m.Expression != nil || (m.Include != nil && m.Include.ContainsExpression()) || (m.Exclude != nil && m.Exclude.ContainsExpression()) {
+		return nil, false
+	}
+
+	names := make([]string, 0, len(m.Rows))
+	product := 1
+	for n, r := range m.Rows {
+		if r.Expression != nil {
+			return nil, false
+		}
+		names = append(names, n)
+		product *= len(r.Values)
+		if product > max {
+			return nil, false
+		}
+	}
+	sort.Strings(names)
+
+	var combs []map[string]RawYAMLValue
+	if len(names) > 0 {
+		combs = []map[string]RawYAMLValue{{}}
+	}
+	for _, n := range names {
+		next := make([]map[string]RawYAMLValue, 0, len(combs)*len(m.Rows[n].Values))
+		for _, c := range combs {
+			for _, v := range m.Rows[n].Values {
+				d := make(map[string]RawYAMLValue, len(c)+1)
+				for k, v := range c {
+					d[k] = v
+				}
+				d[n] = v
+				next = append(next, d)
+			}
+		}
+		combs = next
+	}
+
+	if m.Exclude != nil {
+		kept := combs[:0]
+	Combinations:
+		for _, c := range combs {
+			for _, e := range m.Exclude.Combinations {
+				matched := true
+				for k, a := range e.Assigns {
+					if v, ok := c[k]; !ok || !isYAMLValueSubset(v, a.Value) {
+						matched = false
+						break
+					}
+				}
+				if matched {
+					continue Combinations
+				}
+			}
+			kept = append(kept, c)
+		}
+		combs = kept
+	}
+
+	if m.Include != nil {
+		// Values in "include" are merged into the combinations only when they do not overwrite the
+		// original values of the combinations. Values added by previous "include" combinations can be
+		// overwritten. Keep the original values separately not to be affected by the merged values.
+		origs := make([]map[string]RawYAMLValue, len(combs))
+		for i, c := range combs {
+			o := make(map[string]RawYAMLValue, len(c))
+			for k, v := range c {
+				o[k] = v
+			}
+			origs[i] = o
+		}
+		for _, i := range m.Include.Combinations {
+			merged := false
+			for j, o := range origs {
+				if !canMergeMatrixInclude(o, i) {
+					continue
+				}
+				for k, a := range i.Assigns {
+					combs[j][k] = a.Value
+				}
+				merged = true
+			}
+			if !merged {
+				c := make(map[string]RawYAMLValue, len(i.Assigns))
+				for k, a := range i.Assigns {
+					c[k] = a.Value
+				}
+				combs = append(combs, c)
+				if len(combs) > max {
+					return nil, false
+				}
+			}
+		}
+	}
+
+	return combs, true
+}
+
+// canMergeMatrixInclude returns true when the combination at "include" does not overwrite any of the
+// original values of the matrix combination.
+func canMergeMatrixInclude(orig map[string]RawYAMLValue, include *MatrixCombination) bool {
+	for k, a := range include.Assigns {
+		if v, ok := orig[k]; ok && !v.Equals(a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
 func (rule *RuleMatrix) checkExclude(m *Matrix) {
 	if m.Exclude == nil || len(m.Exclude.Combinations) == 0 || (m.Include != nil && m.Include.ContainsExpression()) {
 		return
@@ -0,0 +1,267 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleMatrixExpandCombinations(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix string
+		max    int
+		want   []string // nil means the combinations cannot be enumerated
+	}{
+		{
+			what: "rows only",
+			matrix: `
+        os: [linux, macos]
+        node: [18, 20]`,
+			want: []string{
+				`{node: "18", os: "linux"}`,
+				`{node: "18", os: "macos"}`,
+				`{node: "20", os: "linux"}`,
+				`{node: "20", os: "macos"}`,
+			},
+		},
+		{
+			// Example in https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs#example-expanding-configurations
+			what: "include merged into original combinations or appended",
+			matrix: `
+        fruit: [apple, pear]
+        animal: [cat, dog]
+        include:
+          - color: green
+          - color: pink
+            animal: cat
+          - fruit: apple
+            shape: circle
+          - fruit: banana
+          - fruit: banana
+            animal: cat`,
+			want: []string{
+				`{animal: "cat", color: "pink", fruit: "apple", shape: "circle"}`,
+				`{animal: "cat", color: "pink", fruit: "pear"}`,
+				`{animal: "cat", fruit: "banana"}`,
+				`{animal: "dog", color: "green", fruit: "apple", shape: "circle"}`,
+				`{animal: "dog", color: "green", fruit: "pear"}`,
+				`{fruit: "banana"}`,
+			},
+		},
+		{
+			what: "include does not overwrite original values",
+			matrix: `
+        os: [linux]
+        include:
+          - os: windows
+            shell: pwsh
+          - os: linux
+            shell: bash`,
+			want: []string{
+				`{os: "linux", shell: "bash"}`,
+				`{os: "windows", shell: "pwsh"}`,
+			},
+		},
+		{
+			what: "include is not merged into combinations appended by include",
+			matrix: `
+        include:
+          - os: linux
+          - os: linux
+            shell: bash`,
+			want: []string{
+				`{os: "linux", shell: "bash"}`,
+				`{os: "linux"}`,
+			},
+		},
+		{
+			what: "exclude",
+			matrix: `
+        os: [linux, macos]
+        target:
+          - { arch: x64, debug: true }
+          - { arch: arm64, debug: false }
+        exclude:
+          - target: { arch: arm64 }
+          - os: macos
+            target: { debug: true }`,
+			want: []string{
+				`{os: "linux", target: {"arch": "x64", "debug": "true"}}`,
+			},
+		},
+		{
+			what: "include is applied after exclude and does not merge into other original values",
+			matrix: `
+        os: [linux, macos]
+        exclude:
+          - os: macos
+        include:
+          - os: macos
+            experimental: true`,
+			want: []string{
+				`{experimental: "true", os: "macos"}`,
+				`{os: "linux"}`,
+			},
+		},
+		{
+			what: "rows exceed max",
+			matrix: `
+        a: [1, 2, 3]
+        b: [1, 2]`,
+			max: 5,
+		},
+		{
+			what: "include exceeds max",
+			matrix: `
+        a: [1, 2]
+        include:
+          - a: 3
+          - a: 4`,
+			max: 3,
+		},
+		{
+			what: "expression at row",
+			matrix: `
+        a: ${{ fromJSON(inputs.a) }}`,
+		},
+		{
+			what: "expression at include",
+			matrix: `
+        a: [1, 2]
+        include: ${{ fromJSON(inputs.include) }}`,
+		},
+		{
+			what: "all combinations are excluded",
+			matrix: `
+        os: [linux]
+        exclude:
+          - os: linux`,
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    strategy:\n      matrix:" + tc.matrix + "\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			max := tc.max
+			if max == 0 {
+				max = maxEnumeratedMatrixCombinations
+			}
+
+			combs, ok := expandMatrixCombinations(w.Jobs["test"].Strategy.Matrix, max)
+			if tc.want == nil {
+				if ok {
+					t.Fatal("combinations should not be enumerated but got", combs)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("combinations could not be enumerated")
+			}
+			have := make([]string, 0, len(combs))
+			for _, c := range combs {
+				have = append(have, matrixCombinationString(c))
+			}
+			sort.Strings(have)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRuleMatrixContinueOnError(t *testing.T) {
+	testCases := []struct {
+		what string
+		job  string
+		want []string
+	}{
+		{
+			what: "value defined only by include",
+			job: `
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        os: [linux, macos]
+        include:
+          - os: linux
+            experimental: true`,
+			want: []string{`matrix value "experimental" referenced at "continue-on-error" is not defined in some matrix combinations such as {os: "macos"}`},
+		},
+		{
+			what: "value defined in all combinations",
+			job: `
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        os: [linux, macos]
+        experimental: [false]
+        include:
+          - os: linux
+            experimental: true`,
+		},
+		{
+			what: "combination without value is excluded",
+			job: `
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        os: [linux, macos]
+        exclude:
+          - os: macos
+        include:
+          - os: linux
+            experimental: true`,
+		},
+		{
+			what: "property is case insensitive",
+			job: `
+    continue-on-error: ${{ matrix.Experimental }}
+    strategy:
+      matrix:
+        os: [linux, macos]
+        include:
+          - os: linux
+            experimental: true`,
+			want: []string{`matrix value "experimental" referenced at "continue-on-error"`},
+		},
+		{
+			what: "combinations cannot be known",
+			job: `
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        os: ${{ fromJSON(inputs.os) }}
+        include:
+          - os: linux
+            experimental: true`,
+		},
+		{
+			what: "no matrix",
+			job: `
+    continue-on-error: ${{ matrix.experimental }}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:" + tc.job + "\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			errs := testCheckRule(t, NewRuleMatrix(), nil, src)
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %v", len(tc.want), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Message, tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Message, tc.want[i])
+				}
+			}
+		})
+	}
+}
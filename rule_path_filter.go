@@ -0,0 +1,192 @@
+package actionlint
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Command changing the directory at start of line like "cd backend"
+var rePathFilterCd = regexp.MustCompile(`(?m)^\s*cd\s+(['"]?)([\w./-]+)(['"]?)\s*(?:$|&&|;)`)
+
+// Names of action inputs which specify a directory in the repository
+var pathFilterDirInputs = []string{"working-directory", "working_directory", "workdir", "context"}
+
+// pathFilterEvent is an event filtered by "paths:" filter.
+type pathFilterEvent struct {
+	name string
+	// prefixes is the list of literal prefixes of the path patterns split into components. For
+	// example, "packages/web/**" is ["packages", "web"].
+	prefixes [][]string
+	filter   *WebhookEventFilter
+}
+
+// covers returns true when some path pattern of the filter may match files in the directory.
+func (e *pathFilterEvent) covers(dir []string) bool {
+	for _, p := range e.prefixes {
+		n := len(p)
+		if len(dir) < n {
+			n = len(dir)
+		}
+		ok := true
+		for i := 0; i < n; i++ {
+			if p[i] != dir[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RulePathFilter is a rule to check directories used in jobs are matched by "paths:" filters of
+// "push" and "pull_request" events. In a monorepo, workflows are often copied for each package and
+// a workflow triggered by changes in "frontend/**" but building "backend" is likely a copy-paste
+// mistake.
+type RulePathFilter struct {
+	RuleBase
+	events []*pathFilterEvent
+	// Working directories set by "defaults:" of workflow and job. "cd" commands are relative to them
+	workflowDir *String
+	jobDir      *String
+}
+
+// NewRulePathFilter creates a new RulePathFilter instance.
+func NewRulePathFilter() *RulePathFilter {
+	return &RulePathFilter{
+		RuleBase: RuleBase{
+			name: "path-filter",
+			desc: "Checks directories used in jobs are matched by \"paths:\" filters of the workflow triggers",
+		},
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RulePathFilter) VisitWorkflowPre(n *Workflow) error {
+	rule.events = nil
+	for _, e := range n.On {
+		w, ok := e.(*WebhookEvent)
+		if !ok {
+			continue
+		}
+		if h := w.Hook.Value; h != "push" && h != "pull_request" && h != "pull_request_target" {
+			continue
+		}
+		if w.Paths.IsEmpty() {
+			continue
+		}
+		if ev, ok := newPathFilterEvent(w.Hook.Value, w.Paths); ok {
+			rule.events = append(rule.events, ev)
+		}
+	}
+
+	rule.workflowDir = nil
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		rule.workflowDir = n.Defaults.Run.WorkingDirectory
+		rule.checkDir(rule.workflowDir)
+	}
+	return nil
+}
+
+// newPathFilterEvent creates pathFilterEvent from the "paths:" filter. It returns false when some
+// pattern may match files in any directory like "**.go".
+func newPathFilterEvent(name string, f *WebhookEventFilter) (*pathFilterEvent, bool) {
+	ps := make([][]string, 0, len(f.Values))
+	for _, v := range f.Values {
+		p := v.Value
+		if strings.HasPrefix(p, "!") {
+			continue
+		}
+		if ContainsExpression(p) {
+			return nil, false
+		}
+		cs := []string{}
+		for _, c := range strings.Split(strings.TrimPrefix(p, "./"), "/") {
+			if strings.ContainsAny(c, "*?[+!") {
+				break
+			}
+			cs = append(cs, c)
+		}
+		if len(cs) == 0 {
+			return nil, false
+		}
+		ps = append(ps, cs)
+	}
+	if len(ps) == 0 {
+		return nil, false
+	}
+	return &pathFilterEvent{name, ps, f}, true
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RulePathFilter) VisitJobPre(n *Job) error {
+	rule.jobDir = nil
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		rule.jobDir = n.Defaults.Run.WorkingDirectory
+		rule.checkDir(rule.jobDir)
+	}
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RulePathFilter) VisitStep(n *Step) error {
+	if len(rule.events) == 0 {
+		return nil
+	}
+	switch e := n.Exec.(type) {
+	case *ExecRun:
+		rule.checkDir(e.WorkingDirectory)
+		if e.Run == nil || e.Run.ContainsExpression() || e.WorkingDirectory != nil || rule.jobDir != nil || rule.workflowDir != nil {
+			return nil
+		}
+		// Only the first "cd" is checked since the following "cd" commands are relative to the directory
+		if m := rePathFilterCd.FindStringSubmatch(e.Run.Value); m != nil && m[1] == m[3] {
+			rule.checkDir(&String{Value: m[2], Pos: e.Run.Pos})
+		}
+	case *ExecAction:
+		for _, name := range pathFilterDirInputs {
+			if i, ok := e.Inputs[name]; ok {
+				rule.checkDir(i.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *RulePathFilter) checkDir(dir *String) {
+	if dir == nil || len(rule.events) == 0 || dir.ContainsExpression() {
+		return
+	}
+	d := dir.Value
+	if d == "" || strings.HasPrefix(d, "/") || strings.HasPrefix(d, "~") || strings.HasPrefix(d, "$") {
+		return
+	}
+	d = path.Clean(d)
+	if d == "." || d == ".." || strings.HasPrefix(d, "../") || d == ".github" || strings.HasPrefix(d, ".github/") {
+		return
+	}
+	cs := strings.Split(d, "/")
+
+	for _, e := range rule.events {
+		if e.covers(cs) {
+			continue
+		}
+		ps := make([]string, 0, len(e.filter.Values))
+		for _, v := range e.filter.Values {
+			ps = append(ps, v.Value)
+		}
+		rule.Errorf(
+			dir.Pos,
+			"directory %q used in this workflow is not matched by any pattern at \"paths\" filter of %q event at line:%d,col:%d (%s). changes in the directory do not trigger this workflow. this may be a copy-paste mistake",
+			d,
+			e.name,
+			e.filter.Name.Pos.Line,
+			e.filter.Name.Pos.Col,
+			sortedQuotes(ps),
+		)
+		return
+	}
+}
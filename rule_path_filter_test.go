@@ -0,0 +1,32 @@
+package actionlint
+
+import "testing"
+
+func TestRulePathFilterSkipGlobalPatterns(t *testing.T) {
+	testCases := []string{
+		"'**.go'",
+		"'*/src/**'",
+		"'**/package.json'",
+		"'${{ inputs.dir }}/**'",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			src := `on:
+  pull_request:
+    paths:
+      - 'frontend/**'
+      - ` + tc + `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm test
+        working-directory: backend
+`
+			if errs := testCheckRule(t, NewRulePathFilter(), nil, src); len(errs) != 0 {
+				t.Fatal("no error was expected but got", errs)
+			}
+		})
+	}
+}
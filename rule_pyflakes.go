@@ -2,7 +2,10 @@ package actionlint
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -25,13 +28,57 @@ func getShellIsPythonKind(shell *String) shellIsPythonKind {
 	return shellIsPythonKindNotPython
 }
 
-// RulePyflakes is a rule to check Python scripts at 'run:' using pyflakes.
+// pythonLinter is an external command to check Python scripts. The command reads the script from
+// stdin and outputs each error in the format of "{prefix}{line}:{col}: {message}".
+type pythonLinter struct {
+	// name is a command name of the linter. It is also used in error messages.
+	name string
+	// args is arguments to read the script from stdin.
+	args []string
+	// prefix is a prefix of each error in the output.
+	prefix string
+}
+
+// pythonLinters is a list of supported linters for Python scripts. The first one is the default.
+// Only errors detected by pyflakes (F) and syntax errors (E9) are reported with ruff and flake8 since
+// code style is not important for scripts in workflows. Their config files are ignored as well as
+// shellcheck.
+var pythonLinters = []*pythonLinter{
+	{"pyflakes", []string{}, "<stdin>:"},
+	{"ruff", []string{"check", "--isolated", "--no-cache", "--output-format=concise", "--select=E9,F", "--stdin-filename=stdin.py", "-"}, "stdin.py:"},
+	{"flake8", []string{"--isolated", "--select=E9,F", "-"}, "stdin:"},
+}
+
+// detectPythonLinter detects the linter from the command name. "python -m ruff" is also detected
+// as ruff. Unknown commands are assumed to be compatible with pyflakes.
+func detectPythonLinter(exe string, args []string) *pythonLinter {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(exe)), ".exe")
+	if strings.HasPrefix(name, "python") {
+		for i, a := range args {
+			if a == "-m" && i+1 < len(args) {
+				name = args[i+1]
+				break
+			}
+		}
+	}
+	for _, l := range pythonLinters {
+		if l.name == name {
+			return l
+		}
+	}
+	return pythonLinters[0]
+}
+
+// RulePyflakes is a rule to check Python scripts at 'run:' using pyflakes. ruff and flake8 are also
+// available instead of pyflakes.
 // https://github.com/PyCQA/pyflakes
 type RulePyflakes struct {
 	RuleBase
 	cmd                   *externalCommand
+	linter                *pythonLinter
 	workflowShellIsPython shellIsPythonKind
 	jobShellIsPython      shellIsPythonKind
+	lines                 sourceLines
 	mu                    sync.Mutex
 }
 
@@ -42,6 +89,7 @@ func newRulePyflakes(cmd *externalCommand) *RulePyflakes {
 			desc: "Checks for Python script when \"shell: python\" is configured using Pyflakes",
 		},
 		cmd:                   cmd,
+		linter:                detectPythonLinter(cmd.exe, cmd.args),
 		workflowShellIsPython: shellIsPythonKindUnspecified,
 		jobShellIsPython:      shellIsPythonKindUnspecified,
 	}
@@ -49,7 +97,7 @@ func newRulePyflakes(cmd *externalCommand) *RulePyflakes {
 
 // NewRulePyflakes creates new RulePyflakes instance. Parameter executable can be command name
 // or relative/absolute file path. When the given executable is not found in system, it returns
-// an error.
+// an error. When the executable is ruff or flake8, it is run with the arguments for the command.
 func NewRulePyflakes(executable string, proc *concurrentProcess) (*RulePyflakes, error) {
 	// Combine output because pyflakes outputs lint errors to stdout and outputs syntax errors to stderr. (#411)
 	cmd, err := proc.newCommandRunner(executable, true)
@@ -98,7 +146,7 @@ func (rule *RulePyflakes) VisitStep(n *Step) error {
 		return nil
 	}
 
-	rule.runPyflakes(run.Run.Value, run.RunPos)
+	rule.runPyflakes(run.Run, run.RunPos)
 	return nil
 }
 
@@ -114,11 +162,18 @@ func (rule *RulePyflakes) isPythonShell(r *ExecRun) bool {
 	return rule.workflowShellIsPython == shellIsPythonKindPython
 }
 
-func (rule *RulePyflakes) runPyflakes(src string, pos *Pos) {
-	src = sanitizeExpressionsInScript(src) // Defined at rule_shellcheck.go
+func (rule *RulePyflakes) runPyflakes(str *String, pos *Pos) {
+	src := sanitizeExpressionsInScript(str.Value) // Defined at rule_shellcheck.go
 	rule.Debug("%s: Running %s for Python script:\n%s", pos, rule.cmd.exe, src)
 
-	rule.cmd.run([]string{}, src, func(stdout []byte, err error) error {
+	rule.cmd.run(rule.linter.args, src, func(stdout []byte, err error) error {
+		var timeout *processTimeoutError
+		if errors.As(err, &timeout) {
+			rule.mu.Lock()
+			rule.reportTimeout(pos, timeout)
+			rule.mu.Unlock()
+			return nil
+		}
 		if err != nil {
 			rule.Debug("Command %s failed: %v", rule.cmd.exe, err)
 			return fmt.Errorf("`%s` did not run successfully while checking script at %s: %w", rule.cmd.exe, pos, err)
@@ -127,8 +182,11 @@ func (rule *RulePyflakes) runPyflakes(src string, pos *Pos) {
 			return nil
 		}
 
+		// Errors are reported at the precise positions in the workflow when possible. See the comment
+		// in RuleShellcheck.runShellcheck.
+		loc := newScriptLocation(rule.lines, str, src, pos)
 		for len(stdout) > 0 {
-			if stdout, err = rule.parseNextError(stdout, pos); err != nil {
+			if stdout, err = rule.parseNextError(stdout, loc); err != nil {
 				return err
 			}
 		}
@@ -136,11 +194,11 @@ func (rule *RulePyflakes) runPyflakes(src string, pos *Pos) {
 	})
 }
 
-func (rule *RulePyflakes) parseNextError(stdout []byte, pos *Pos) ([]byte, error) {
+func (rule *RulePyflakes) parseNextError(stdout []byte, loc *scriptLocation) ([]byte, error) {
 	b := stdout
 
 	// Search the start of error message.
-	idx := bytes.Index(b, []byte("<stdin>:"))
+	idx := bytes.Index(b, []byte(rule.linter.prefix))
 	if idx == -1 {
 		// Syntax errors from pyflake consist of multiple lines. Skip subsequent lines. (#411)
 		// ```
@@ -150,11 +208,11 @@ func (rule *RulePyflakes) parseNextError(stdout []byte, pos *Pos) ([]byte, error
 		// ```
 		return nil, nil
 	}
-	b = b[idx+len("<stdin>:"):]
+	b = b[idx+len(rule.linter.prefix):]
 
 	idx = bytes.IndexByte(b, '\n')
 	if idx == -1 {
-		return nil, fmt.Errorf(`error message from pyflakes does not end with \n nor \r\n while checking script at %s. output: %q`, pos, stdout)
+		return nil, fmt.Errorf(`error message from %s does not end with \n nor \r\n while checking script at %s. output: %q`, rule.linter.name, loc.run, stdout)
 	}
 
 	msg := b[:idx]
@@ -164,9 +222,35 @@ func (rule *RulePyflakes) parseNextError(stdout []byte, pos *Pos) ([]byte, error
 	b = b[idx+1:]
 
 	// This method needs to be thread-safe since concurrentProcess.run calls its callback in a different goroutine.
+	pos := loc.run
+	if l, c, ok := parsePyflakesLocation(msg); ok {
+		pos = loc.at(l, c)
+	}
+
 	rule.mu.Lock()
-	rule.Errorf(pos, "pyflakes reported issue in this script: %s", msg)
+	rule.Errorf(pos, "%s reported issue in this script: %s", rule.linter.name, msg)
 	rule.mu.Unlock()
 
 	return b, nil
 }
+
+// parsePyflakesLocation parses the line and the column at the head of the error message like
+// "1:7: undefined name 'foo'". The column is 0 when it is omitted like "1: invalid syntax".
+func parsePyflakesLocation(msg []byte) (int, int, bool) {
+	ss := bytes.SplitN(msg, []byte{':'}, 3)
+	if len(ss) < 2 {
+		return 0, 0, false
+	}
+	l, err := strconv.Atoi(string(ss[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	if len(ss) < 3 {
+		return l, 0, true
+	}
+	c, err := strconv.Atoi(string(ss[1]))
+	if err != nil {
+		return l, 0, true
+	}
+	return l, c, true
+}
@@ -157,7 +157,7 @@ func TestRulePyflakesParsePyflakesOutputOK(t *testing.T) {
 			stdout := []byte(tc.input)
 			pos := &Pos{Line: 1, Col: 2}
 			for len(stdout) > 0 {
-				o, err := r.parseNextError(stdout, pos)
+				o, err := r.parseNextError(stdout, &scriptLocation{run: pos})
 				if err != nil {
 					t.Fatalf("Parse error %q while reading input %q", err, stdout)
 				}
@@ -185,7 +185,7 @@ func TestRulePyflakesParsePyflakesOutputOK(t *testing.T) {
 
 func TestRulePyflakesParsePyflakesOutputError(t *testing.T) {
 	r := newRulePyflakes(&externalCommand{})
-	_, err := r.parseNextError([]byte("<stdin>:1:7: undefined name 'foo'"), &Pos{})
+	_, err := r.parseNextError([]byte("<stdin>:1:7: undefined name 'foo'"), &scriptLocation{run: &Pos{}})
 	if err == nil {
 		t.Fatal("Error did not happen")
 	}
@@ -195,3 +195,67 @@ func TestRulePyflakesParsePyflakesOutputError(t *testing.T) {
 		t.Fatalf("Error %q does not contain expected message %q", have, want)
 	}
 }
+
+func TestRulePyflakesDetectLinter(t *testing.T) {
+	tests := []struct {
+		exe  string
+		args []string
+		want string
+	}{
+		{"", nil, "pyflakes"},
+		{"/usr/local/bin/pyflakes", nil, "pyflakes"},
+		{"/usr/local/bin/ruff", nil, "ruff"},
+		{"/opt/python/Scripts/flake8.exe", nil, "flake8"},
+		{"/usr/bin/python3", []string{"-m", "ruff"}, "ruff"},
+		{"/usr/bin/python3", []string{"-m", "pyflakes"}, "pyflakes"},
+		{"/path/to/my-linter", nil, "pyflakes"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.exe, func(t *testing.T) {
+			if have := detectPythonLinter(tc.exe, tc.args).name; have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRulePyflakesParseAlternativeLinterOutput(t *testing.T) {
+	tests := []struct {
+		linter string
+		input  string
+		want   string
+	}{
+		{
+			linter: "ruff",
+			input:  "stdin.py:1:7: F821 Undefined name `foo`\nFound 1 error.\n",
+			want:   ":1:2: ruff reported issue in this script: 1:7: F821 Undefined name `foo` [pyflakes]",
+		},
+		{
+			linter: "flake8",
+			input:  "stdin:1:7: F821 undefined name 'foo'\n",
+			want:   ":1:2: flake8 reported issue in this script: 1:7: F821 undefined name 'foo' [pyflakes]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.linter, func(t *testing.T) {
+			r := newRulePyflakes(&externalCommand{exe: tc.linter})
+			stdout := []byte(tc.input)
+			for len(stdout) > 0 {
+				o, err := r.parseNextError(stdout, &scriptLocation{run: &Pos{Line: 1, Col: 2}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				stdout = o
+			}
+			errs := r.Errs()
+			if len(errs) != 1 {
+				t.Fatal("wanted one error but got", errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package actionlint
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// releaseAction is an action to create GitHub Releases.
+type releaseAction struct {
+	// tagInput is the name of input for the tag name of the release. When the input is omitted, the
+	// release is created from github.ref. Empty means the input is required.
+	tagInput string
+	// tokenInput is the name of input for the token. Empty means the token is passed via
+	// GITHUB_TOKEN environment variable.
+	tokenInput string
+}
+
+// releaseActions is the table of popular actions to create GitHub Releases. Keys are
+// "{owner}/{repo}" in lower case.
+var releaseActions = map[string]*releaseAction{
+	"softprops/action-gh-release": {tagInput: "tag_name", tokenInput: "token"},
+	"ncipollo/release-action":     {tagInput: "tag", tokenInput: "token"},
+	"actions/create-release":      {},
+}
+
+var reReleaseGhCommand = regexp.MustCompile(`\bgh\s+release\s+(?:create|upload|edit|delete)\b`)
+
+// Conditions checking the ref is a tag like "startsWith(github.ref, 'refs/tags/')"
+var reReleaseTagCondition = regexp.MustCompile(`refs/tags/|github\.ref_type`)
+
+// releaseStep is a step to create or modify a release.
+type releaseStep struct {
+	file string
+	pos  *Pos
+	// what is a description of the step like "softprops/action-gh-release@v2" or "gh release".
+	what string
+	// token is the token used by the step. nil means the token is unknown.
+	token *String
+	// defaultToken is true when the step uses the default token, which is GITHUB_TOKEN.
+	defaultToken bool
+	// tokenAt is where the token is passed like "\"token\" input".
+	tokenAt string
+}
+
+// RuleRelease is a rule to check workflows creating GitHub Releases. The release actions like
+// softprops/action-gh-release and `gh release` command require "contents: write" permission. A
+// release created from the pushed ref requires the ref is a tag. And a release created with
+// GITHUB_TOKEN does not trigger workflows on "release" event. This rule checks all workflow files in
+// the project at once to know the workflows triggered by the releases.
+type RuleRelease struct {
+	RuleBase
+}
+
+// NewRuleRelease creates a new RuleRelease instance.
+func NewRuleRelease() *RuleRelease {
+	return &RuleRelease{
+		RuleBase: RuleBase{
+			name: "release",
+			desc: "Checks permissions, tag filters, and tokens of workflows creating GitHub Releases",
+		},
+	}
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleRelease) VisitProject(files []*ProjectFile) error {
+	steps := []*releaseStep{}
+	var downstream *ProjectFile
+	for _, f := range files {
+		w := f.Workflow
+		for _, j := range w.Jobs {
+			steps = append(steps, rule.checkJob(f.Path, w, j)...)
+		}
+		if downstream == nil && isTriggeredByRelease(w) {
+			downstream = f
+		}
+	}
+
+	if downstream == nil {
+		return nil
+	}
+	for _, s := range steps {
+		if !s.defaultToken && !isGitHubToken(s.token) {
+			continue
+		}
+		rule.FileErrorf(
+			s.file,
+			s.pos,
+			"release is updated by %s with GITHUB_TOKEN but it does not trigger workflow %q on \"release\" event since events caused by GITHUB_TOKEN do not create new workflow runs. pass a personal access token or a GitHub App token to %s",
+			s.what,
+			downstream.Path,
+			s.tokenAt,
+		)
+	}
+	return nil
+}
+
+func (rule *RuleRelease) checkJob(path string, w *Workflow, j *Job) []*releaseStep {
+	steps := []*releaseStep{}
+	for _, s := range j.Steps {
+		r := rule.releaseStep(path, w, j, s)
+		if r == nil {
+			continue
+		}
+		rule.checkPermissions(path, r, j.Permissions, w.Permissions)
+		steps = append(steps, r)
+
+		if e, ok := s.Exec.(*ExecAction); ok {
+			rule.checkTagFilter(path, r, w, j, s, e)
+		}
+	}
+	return steps
+}
+
+// releaseStep returns the information of the step when the step creates or modifies a release.
+// Otherwise it returns nil.
+func (rule *RuleRelease) releaseStep(path string, w *Workflow, j *Job, s *Step) *releaseStep {
+	switch e := s.Exec.(type) {
+	case *ExecAction:
+		if e.Spec == nil || e.Spec.Kind != ActionSpecKindRepository {
+			return nil
+		}
+		a, ok := releaseActions[strings.ToLower(e.Spec.Owner+"/"+e.Spec.Repo)]
+		if !ok {
+			return nil
+		}
+		r := &releaseStep{file: path, pos: e.Uses.Pos, what: strconv.Quote(e.Uses.Value)}
+		if a.tokenInput != "" {
+			r.tokenAt = strconv.Quote(a.tokenInput) + " input"
+			if i, ok := e.Inputs[a.tokenInput]; ok {
+				r.token = i.Value
+			} else {
+				r.defaultToken = true
+			}
+		} else {
+			r.tokenAt = "GITHUB_TOKEN environment variable"
+			r.token, _ = lookupEnvVar("github_token", s.Env, j.Env, w.Env)
+		}
+		return r
+	case *ExecRun:
+		if e.Run == nil || !reReleaseGhCommand.MatchString(e.Run.Value) {
+			return nil
+		}
+		r := &releaseStep{file: path, pos: e.Run.Pos, what: "\"gh release\" command"}
+		for _, n := range []string{"gh_token", "github_token"} {
+			if v, ok := lookupEnvVar(n, s.Env, j.Env, w.Env); ok {
+				r.token = v
+				r.tokenAt = strings.ToUpper(n) + " environment variable"
+				return r
+			}
+		}
+		return r
+	default:
+		return nil
+	}
+}
+
+func (rule *RuleRelease) checkPermissions(path string, r *releaseStep, perms ...*Permissions) {
+	var p *Permissions
+	for _, q := range perms {
+		if q != nil {
+			p = q
+			break
+		}
+	}
+	if p == nil {
+		return // Default permissions depend on the repository settings
+	}
+
+	v := "none"
+	if p.All != nil {
+		v = p.All.Value
+	} else if s, ok := p.Scopes["contents"]; ok && s.Value != nil {
+		v = s.Value.Value
+	}
+	if v == "write" || v == "write-all" || ContainsExpression(v) {
+		return
+	}
+	rule.FileErrorf(
+		path,
+		r.pos,
+		"%s requires \"contents: write\" permission to create or update a release but the permission of \"contents\" scope is %q in this job. add \"contents: write\" to \"permissions:\"",
+		r.what,
+		strings.TrimSuffix(v, "-all"),
+	)
+}
+
+func (rule *RuleRelease) checkTagFilter(path string, r *releaseStep, w *Workflow, j *Job, s *Step, e *ExecAction) {
+	a := releaseActions[strings.ToLower(e.Spec.Owner+"/"+e.Spec.Repo)]
+	if a.tagInput == "" {
+		return
+	}
+	if _, ok := e.Inputs[a.tagInput]; ok {
+		return
+	}
+	for _, c := range []*String{j.If, s.If} {
+		if c != nil && reReleaseTagCondition.MatchString(c.Value) {
+			return
+		}
+	}
+
+	for _, ev := range w.On {
+		p, ok := ev.(*WebhookEvent)
+		if !ok || p.Hook.Value != "push" {
+			continue
+		}
+		// When only tag filters are defined, pushing branches does not trigger the workflow
+		if p.Branches.IsEmpty() && p.BranchesIgnore.IsEmpty() && !(p.Tags.IsEmpty() && p.TagsIgnore.IsEmpty()) {
+			continue
+		}
+		rule.FileErrorf(
+			path,
+			r.pos,
+			"%s creates a release from the pushed ref since %q input is omitted, but the workflow is triggered by pushing branches at line:%d,col:%d. filter the \"push\" event by tags like \"tags: [v*]\" or check the ref with \"startsWith(github.ref, 'refs/tags/')\" at \"if:\"",
+			r.what,
+			a.tagInput,
+			p.Pos.Line,
+			p.Pos.Col,
+		)
+		return
+	}
+}
+
+// isTriggeredByRelease returns true when the workflow is triggered by "release" event.
+func isTriggeredByRelease(w *Workflow) bool {
+	for _, e := range w.On {
+		if e, ok := e.(*WebhookEvent); ok && e.Hook.Value == "release" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupEnvVar looks up the environment variable by the name in lower case from the innermost env.
+// It returns false as the second value when the variable is not defined or it is unknown.
+func lookupEnvVar(name string, envs ...*Env) (*String, bool) {
+	for _, e := range envs {
+		if e == nil {
+			continue
+		}
+		if e.Expression != nil {
+			return nil, false
+		}
+		if v, ok := e.Vars[name]; ok {
+			return v.Value, true
+		}
+	}
+	return nil, false
+}
+
+// isGitHubToken returns true when the token is GITHUB_TOKEN like "${{ secrets.GITHUB_TOKEN }}".
+func isGitHubToken(t *String) bool {
+	if t == nil {
+		return false
+	}
+	v := strings.ToLower(t.Value)
+	return strings.Contains(v, "secrets.github_token") || strings.Contains(v, "github.token")
+}
@@ -0,0 +1,63 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleReleaseGitHubTokenWithoutDownstreamWorkflow(t *testing.T) {
+	src := `on:
+  push:
+    tags: ['v*']
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+    steps:
+      - uses: actions/create-release@v1
+        env:
+          GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+        with:
+          tag_name: ${{ github.ref }}
+          release_name: Release ${{ github.ref }}
+`
+	if errs := testCheckRule(t, NewRuleRelease(), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected without workflows triggered by release event but got", errs)
+	}
+
+	downstream := "on: release\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	errs := testCheckRule(t, NewRuleRelease(), nil, src, downstream)
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	msg := errs[0].Message
+	if !strings.Contains(msg, `"actions/create-release@v1" with GITHUB_TOKEN`) || !strings.Contains(msg, `"b.yaml"`) || !strings.Contains(msg, "GITHUB_TOKEN environment variable") {
+		t.Fatal("unexpected error message:", msg)
+	}
+}
+
+func TestRuleReleaseTagFilter(t *testing.T) {
+	testCases := []struct {
+		what string
+		on   string
+		want bool
+	}{
+		{"no filter", "push", true},
+		{"branches", "push:\n    branches: [main]", true},
+		{"tags", "push:\n    tags: ['v*']", false},
+		{"tags-ignore", "push:\n    tags-ignore: ['nightly']", false},
+		{"paths", "push:\n    paths: ['src/**']", true},
+		{"not push", "workflow_dispatch", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on:\n  " + tc.on + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: ncipollo/release-action@v1\n"
+			errs := testCheckRule(t, NewRuleRelease(), nil, src)
+			if tc.want && len(errs) != 1 || !tc.want && len(errs) != 0 {
+				t.Fatalf("unexpected errors for %q: %v", tc.on, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,464 @@
+package actionlint
+
+import (
+	"strings"
+)
+
+// RuleRemote is a rule to check repository-specific references in workflows using GitHub API. It
+// checks that referenced secrets, configuration variables, and environments exist, that branch
+// filters match to actual branches, that reusable workflows exist at the specified refs, and that
+// runner labels and runner groups are registered. This rule is enabled only when the remote
+// repository is given to the linter.
+type RuleRemote struct {
+	RuleBase
+	workflowCall bool
+	inheritable  bool // True when the checked workflow can access the repository secrets
+	environment  string
+}
+
+// NewRuleRemote creates a new RuleRemote instance. The repository on GitHub where the checked
+// workflows are put is Remote of the capabilities set by SetCapabilities. Inputs and secrets of
+// calls of reusable workflows in other repositories are validated with RemoteReusableWorkflows of
+// the capabilities.
+func NewRuleRemote() *RuleRemote {
+	return &RuleRemote{
+		RuleBase: RuleBase{
+			name: "remote",
+			desc: "Checks for secrets, variables, environments, branches, reusable workflows, and runners which are referenced in workflows actually exist in the repository using GitHub API",
+		},
+	}
+}
+
+func (rule *RuleRemote) repo() *RemoteRepository {
+	return rule.Capabilities().Remote
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleRemote) VisitWorkflowPre(n *Workflow) error {
+	if rule.repo() == nil {
+		return nil
+	}
+	e, ok := n.FindWorkflowCallEvent()
+	rule.workflowCall = ok
+	rule.inheritable = !ok || e.Secrets == nil
+	rule.environment = ""
+
+	for _, e := range n.On {
+		w, ok := e.(*WebhookEvent)
+		if !ok {
+			continue
+		}
+		switch w.EventName() {
+		case "push", "pull_request", "pull_request_target":
+			if err := rule.checkBranchFilter(w.Branches); err != nil {
+				return err
+			}
+			if err := rule.checkBranchFilter(w.BranchesIgnore); err != nil {
+				return err
+			}
+		}
+	}
+
+	ss := []*String{n.Name, n.RunName}
+	if n.Concurrency != nil {
+		ss = append(ss, n.Concurrency.Group)
+	}
+	ss = appendEnvStrings(ss, n.Env)
+	return rule.checkStrings(ss)
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleRemote) VisitJobPre(n *Job) error {
+	if rule.repo() == nil {
+		return nil
+	}
+	rule.environment = ""
+	if e := n.Environment; e != nil && e.Name != nil && !e.Name.ContainsExpression() {
+		if err := rule.checkEnvironment(e.Name); err != nil {
+			return err
+		}
+	}
+
+	if n.RunsOn != nil {
+		if err := rule.checkRunner(n.RunsOn); err != nil {
+			return err
+		}
+	}
+
+	if n.WorkflowCall != nil && n.WorkflowCall.Uses != nil {
+		if err := rule.checkReusableWorkflow(n.WorkflowCall); err != nil {
+			return err
+		}
+	}
+
+	ss := []*String{n.Name, n.If}
+	if n.RunsOn != nil {
+		ss = append(ss, n.RunsOn.Labels...)
+		ss = append(ss, n.RunsOn.LabelsExpr, n.RunsOn.Group)
+	}
+	if n.Environment != nil {
+		ss = append(ss, n.Environment.Name, n.Environment.URL)
+	}
+	if n.Concurrency != nil {
+		ss = append(ss, n.Concurrency.Group)
+	}
+	for _, o := range n.Outputs {
+		ss = append(ss, o.Value)
+	}
+	ss = appendEnvStrings(ss, n.Env)
+	ss = appendContainerStrings(ss, n.Container)
+	if n.Services != nil {
+		for _, s := range n.Services.Value {
+			ss = appendContainerStrings(ss, s.Container)
+		}
+	}
+	if c := n.WorkflowCall; c != nil {
+		for _, i := range c.Inputs {
+			ss = append(ss, i.Value)
+		}
+		for _, s := range c.Secrets {
+			ss = append(ss, s.Value)
+		}
+	}
+	return rule.checkStrings(ss)
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleRemote) VisitStep(n *Step) error {
+	if rule.repo() == nil {
+		return nil
+	}
+	ss := []*String{n.Name, n.If}
+	switch e := n.Exec.(type) {
+	case *ExecRun:
+		ss = append(ss, e.Run, e.Shell, e.WorkingDirectory)
+	case *ExecAction:
+		for _, i := range e.Inputs {
+			ss = append(ss, i.Value)
+		}
+		ss = append(ss, e.Entrypoint, e.Args)
+	}
+	ss = appendEnvStrings(ss, n.Env)
+	return rule.checkStrings(ss)
+}
+
+func appendEnvStrings(ss []*String, env *Env) []*String {
+	if env == nil {
+		return ss
+	}
+	if env.Expression != nil {
+		return append(ss, env.Expression)
+	}
+	for _, v := range env.Vars {
+		ss = append(ss, v.Value)
+	}
+	return ss
+}
+
+func appendContainerStrings(ss []*String, c *Container) []*String {
+	if c == nil {
+		return ss
+	}
+	ss = append(ss, c.Image, c.Options)
+	if c.Credentials != nil {
+		ss = append(ss, c.Credentials.Username, c.Credentials.Password)
+	}
+	return appendEnvStrings(ss, c.Env)
+}
+
+func (rule *RuleRemote) checkBranchFilter(f *WebhookEventFilter) error {
+	if f.IsEmpty() {
+		return nil
+	}
+
+	branches, ok, err := rule.repo().Branches()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rule.Debug("Branches in %s are not available. Skipped checking %q filter", rule.repo(), f.Name.Value)
+		return nil
+	}
+
+	for _, v := range f.Values {
+		if v.ContainsExpression() {
+			continue
+		}
+		p := strings.TrimPrefix(v.Value, "!")
+		r, err := compileGlob(p)
+		if err != nil {
+			continue // Invalid glob pattern is reported by "glob" rule
+		}
+		found := false
+		for b := range branches {
+			if r.MatchString(b) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			rule.Errorf(
+				v.Pos,
+				"branch filter %q in %q does not match to any branch in repository %q",
+				v.Value,
+				f.Name.Value,
+				rule.repo().String(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (rule *RuleRemote) checkEnvironment(name *String) error {
+	envs, ok, err := rule.repo().Environments()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rule.Debug("Environments in %s are not available. Skipped checking environment %q", rule.repo(), name.Value)
+		return nil
+	}
+	for e := range envs {
+		if strings.EqualFold(e, name.Value) {
+			rule.environment = e
+			return nil
+		}
+	}
+	rule.Errorf(
+		name.Pos,
+		"environment %q is not found in repository %q. available environments are %s",
+		name.Value,
+		rule.repo().String(),
+		sortedQuotes(remoteNamesToSlice(envs)),
+	)
+	return nil
+}
+
+func (rule *RuleRemote) checkRunner(r *Runner) error {
+	if r.Group != nil && !r.Group.ContainsExpression() {
+		groups, ok, err := rule.repo().RunnerGroups()
+		if err != nil {
+			return err
+		}
+		if ok {
+			found := false
+			for g := range groups {
+				if strings.EqualFold(g, r.Group.Value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				rule.Errorf(
+					r.Group.Pos,
+					"runner group %q is not found in organization %q. available runner groups are %s",
+					r.Group.Value,
+					rule.repo().Owner,
+					sortedQuotes(remoteNamesToSlice(groups)),
+				)
+			}
+		}
+	}
+
+	var labels []*String
+	for _, l := range r.Labels {
+		if l.ContainsExpression() {
+			continue
+		}
+		if _, ok := defaultRunnerOSCompats[strings.ToLower(l.Value)]; ok {
+			continue // GitHub-hosted runner labels and OS labels of self-hosted runners
+		}
+		preset := false
+		for _, p := range selfHostedRunnerPresetOtherLabels {
+			if strings.EqualFold(p, l.Value) {
+				preset = true
+				break
+			}
+		}
+		if !preset {
+			labels = append(labels, l)
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	registered, ok, err := rule.repo().RunnerLabels()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rule.Debug("Self-hosted runners in %s are not available. Skipped checking runner labels", rule.repo())
+		return nil
+	}
+	for _, l := range labels {
+		if _, ok := registered[strings.ToLower(l.Value)]; !ok {
+			rule.Errorf(
+				l.Pos,
+				"runner label %q is not registered to any self-hosted runner in repository %q or its organization",
+				l.Value,
+				rule.repo().String(),
+			)
+		}
+	}
+	return nil
+}
+
+func (rule *RuleRemote) checkReusableWorkflow(call *WorkflowCall) error {
+	// Local reusable workflows are checked by "workflow-call" rule
+	u := call.Uses
+	workflows := rule.Capabilities().RemoteReusableWorkflows
+	if workflows == nil || u.ContainsExpression() || !isWorkflowCallUsesRepoFormat(u.Value) {
+		return nil
+	}
+
+	m, ok, err := workflows.FindMetadata(u.Value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// owner/repo/path/to/workflow.yml@ref
+		spec, ref, _ := strings.Cut(u.Value, "@")
+		ss := strings.SplitN(spec, "/", 3)
+		rule.Errorf(
+			u.Pos,
+			"reusable workflow %q is not found. file %q does not exist at ref %q in repository %q or the repository is not accessible",
+			u.Value,
+			ss[2],
+			ref,
+			ss[0]+"/"+ss[1],
+		)
+		return nil
+	}
+	if m == nil {
+		rule.Debug("Skip workflow call %q since it is not a valid reusable workflow", u.Value)
+		return nil
+	}
+
+	var inherited map[string]struct{}
+	var where string
+	if call.InheritSecrets && rule.inheritable {
+		inherited, where, err = inheritedSecrets(rule.Config(), rule.repo())
+		if err != nil {
+			return err
+		}
+	}
+
+	checkWorkflowCallWithMetadata(&rule.RuleBase, call, m, inherited, where)
+	return nil
+}
+
+func (rule *RuleRemote) checkStrings(ss []*String) error {
+	for _, s := range ss {
+		if s == nil || !s.ContainsExpression() {
+			continue
+		}
+		if err := rule.checkExprsIn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rule *RuleRemote) checkExprsIn(s *String) error {
+	var err error
+	visitExprNodesInString(s, func(n ExprNode, pos *Pos) {
+		if err != nil {
+			return
+		}
+		if ctx, name, ok := remoteContextReference(n); ok {
+			err = rule.checkReference(ctx, name, pos)
+		}
+	})
+	return err
+}
+
+// remoteContextReference returns the context name and the property name when the node is a
+// reference to secrets or vars context like `secrets.FOO` or `vars['FOO']`.
+func remoteContextReference(n ExprNode) (string, string, bool) {
+	var recv ExprNode
+	var name string
+	switch n := n.(type) {
+	case *ObjectDerefNode:
+		recv, name = n.Receiver, n.Property
+	case *IndexAccessNode:
+		s, ok := n.Index.(*StringNode)
+		if !ok {
+			return "", "", false
+		}
+		recv, name = n.Operand, s.Value
+	default:
+		return "", "", false
+	}
+	v, ok := recv.(*VariableNode)
+	if !ok || (v.Name != "secrets" && v.Name != "vars") {
+		return "", "", false
+	}
+	return v.Name, strings.ToUpper(name), true
+}
+
+func (rule *RuleRemote) checkReference(ctx, name string, pos *Pos) error {
+	// Secrets and variables in reusable workflows are passed from caller workflows
+	if rule.workflowCall {
+		return nil
+	}
+	if ctx == "secrets" && name == "GITHUB_TOKEN" {
+		return nil
+	}
+
+	var names, envNames map[string]struct{}
+	var ok bool
+	var err error
+	if ctx == "secrets" {
+		names, ok, err = rule.repo().Secrets()
+	} else {
+		names, ok, err = rule.repo().Variables()
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rule.Debug("%s in %s are not available. Skipped checking %q", ctx, rule.repo(), name)
+		return nil
+	}
+	if _, ok := names[name]; ok {
+		return nil
+	}
+
+	if rule.environment != "" {
+		if ctx == "secrets" {
+			envNames, ok, err = rule.repo().EnvironmentSecrets(rule.environment)
+		} else {
+			envNames, ok, err = rule.repo().EnvironmentVariables(rule.environment)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if _, ok := envNames[name]; ok {
+			return nil
+		}
+	}
+
+	what := "secret"
+	if ctx == "vars" {
+		what = "configuration variable"
+	}
+	if rule.environment != "" {
+		rule.Errorf(pos, "%s %q is not defined in repository %q, its organization, or environment %q", what, name, rule.repo().String(), rule.environment)
+	} else {
+		rule.Errorf(pos, "%s %q is not defined in repository %q or its organization", what, name, rule.repo().String())
+	}
+	return nil
+}
+
+func remoteNamesToSlice(m map[string]struct{}) []string {
+	ss := make([]string, 0, len(m))
+	for n := range m {
+		ss = append(ss, n)
+	}
+	return ss
+}
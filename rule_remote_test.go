@@ -0,0 +1,129 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testNewRuleRemote(repo *RemoteRepository, c *GitHubClient) *RuleRemote {
+	r := NewRuleRemote()
+	r.SetCapabilities(&Capabilities{
+		Remote:                  repo,
+		RemoteReusableWorkflows: NewRemoteReusableWorkflowCache(c, nil),
+	})
+	return r
+}
+
+func TestRuleRemoteChecks(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/branches":                                 `[{"name":"main"},{"name":"release/v1"}]`,
+		"/repos/o/r/actions/secrets":                          `{"secrets":[{"name":"NPM_TOKEN"}]}`,
+		"/repos/o/r/actions/organization-secrets":             `{"secrets":[]}`,
+		"/repos/o/r/actions/variables":                        `{"variables":[{"name":"REGION"}]}`,
+		"/repos/o/r/actions/organization-variables":           `{"variables":[]}`,
+		"/repos/o/r/environments":                             `{"environments":[{"name":"production"}]}`,
+		"/repos/o/r/environments/production/secrets":          `{"secrets":[{"name":"DEPLOY_KEY"}]}`,
+		"/repos/o/r/environments/production/variables":        `{"variables":[]}`,
+		"/repos/o/r/actions/runners":                          `{"runners":[{"labels":[{"name":"self-hosted"},{"name":"gpu"}]}]}`,
+		"/orgs/o/actions/runners":                             "403",
+		"/orgs/o/actions/runner-groups":                       `{"runner_groups":[{"name":"Default"}]}`,
+		"/repos/o/r/contents/.github/workflows/ci.yml?ref=v1": `{"type":"file","encoding":"base64","content":"b246CiAgd29ya2Zsb3dfY2FsbDoKICAgIGlucHV0czoKICAgICAgbmFtZToKICAgICAgICByZXF1aXJlZDogdHJ1ZQogICAgc2VjcmV0czoKICAgICAgdG9rZW46CiAgICAgICAgcmVxdWlyZWQ6IHRydWUKam9iczoKICB0ZXN0OgogICAgcnVucy1vbjogdWJ1bnR1LWxhdGVzdAogICAgc3RlcHM6CiAgICAgIC0gcnVuOiBlY2hvCg=="}`,
+	})
+	repo, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := `on:
+  push:
+    branches: [main, 'release/**', develop]
+    branches-ignore: ['!feature/*']
+jobs:
+  build:
+    runs-on: [self-hosted, linux, gpu, tpu]
+    env:
+      TOKEN: ${{ secrets.NPM_TOKEN }}
+      GITHUB: ${{ secrets.GITHUB_TOKEN }}
+      REGION: ${{ vars.REGION }}
+      ZONE: ${{ vars.ZONE }}
+    steps:
+      - run: echo ${{ secrets.DEPLOY_KEY }}
+  deploy:
+    runs-on:
+      group: large
+    environment: production
+    steps:
+      - run: echo ${{ secrets.DEPLOY_KEY }} ${{ secrets['UNKNOWN'] }}
+  ok:
+    uses: o/r/.github/workflows/ci.yml@v1
+    with:
+      name: foo
+    secrets:
+      token: ${{ secrets.NPM_TOKEN }}
+  wrong-args:
+    uses: o/r/.github/workflows/ci.yml@v1
+    with:
+      nam: foo
+    secrets: inherit
+  missing:
+    uses: o/r/.github/workflows/ci.yml@v2
+`
+	errs := testCheckRule(t, testNewRuleRemote(repo, c), nil, src)
+	want := []string{
+		`3:36: branch filter "develop" in "branches" does not match to any branch in repository "o/r"`,
+		`4:23: branch filter "!feature/*" in "branches-ignore" does not match to any branch in repository "o/r"`,
+		`7:40: runner label "tpu" is not registered to any self-hosted runner in repository "o/r" or its organization`,
+		`12:17: configuration variable "ZONE" is not defined in repository "o/r" or its organization`,
+		`14:23: secret "DEPLOY_KEY" is not defined in repository "o/r" or its organization`,
+		`17:14: runner group "large" is not found in organization "o". available runner groups are "Default"`,
+		`20:49: secret "UNKNOWN" is not defined in repository "o/r", its organization, or environment "production"`,
+		`28:11: input "name" is required by "o/r/.github/workflows/ci.yml@v1" reusable workflow`,
+		`28:11: secret "token" is required by "o/r/.github/workflows/ci.yml@v1" reusable workflow but it is not inherited with "secrets: inherit" since it is not defined in repository "o/r" and its organization`,
+		`30:7: input "nam" is not defined in "o/r/.github/workflows/ci.yml@v1" reusable workflow. defined input is "name"`,
+		`33:11: reusable workflow "o/r/.github/workflows/ci.yml@v2" is not found`,
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	have := make([]string, 0, len(errs))
+	for _, err := range errs {
+		have = append(have, err.Error())
+	}
+
+	if len(have) != len(want) {
+		t.Fatalf("wanted %d errors but got %d errors: %v", len(want), len(have), strings.Join(have, "\n"))
+	}
+	for i, h := range have {
+		if !strings.Contains(h, want[i]) {
+			t.Errorf("error %q does not contain %q", h, want[i])
+		}
+	}
+}
+
+func TestRuleRemoteSkipReusableWorkflow(t *testing.T) {
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/actions/secrets":              `{"secrets":[]}`,
+		"/repos/o/r/actions/organization-secrets": `{"secrets":[]}`,
+	})
+	repo, err := NewRemoteRepository("o/r", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := `on:
+  workflow_call:
+    secrets:
+      token:
+        required: true
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ secrets.token }}
+`
+	if errs := testCheckRule(t, testNewRuleRemote(repo, c), nil, src); len(errs) > 0 {
+		t.Fatal("no error was expected but got", errs)
+	}
+}
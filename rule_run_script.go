@@ -0,0 +1,363 @@
+package actionlint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	reRunScriptLoop        = regexp.MustCompile(`(?m)(^|[;&|]\s*|\bdo\s+|\bthen\s+)\s*(for|while|until|foreach)\b`)
+	reRunScriptConditional = regexp.MustCompile(`(?m)(^|[;&|]\s*|\bdo\s+|\bthen\s+)\s*(if|case|switch)\b`)
+	reRunScriptHeredoc     = regexp.MustCompile(`<<-?\s*['"]?[A-Za-z_][A-Za-z0-9_]*['"]?`)
+	reRunScriptPropAccess  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*)+$`)
+)
+
+// RuleRunScript is a rule to check length and complexity of scripts at "run:". Long or complex
+// scripts are hard to maintain in workflow files. Extracting them into script files or composite
+// actions is recommended. This rule is optional and enabled by the "run-script" configuration in the
+// "rules" section of the configuration file. Each error has a fix to extract the script into a
+// script file under ".github/scripts/" which can be applied with -fix.
+type RuleRunScript struct {
+	RuleBase
+	src           *SourceFile
+	jobID         string
+	stepIndex     int
+	workflowShell string
+	jobShell      string
+	workflowDir   bool
+	jobDir        bool
+}
+
+// NewRuleRunScript creates a new RuleRunScript instance. The src parameter is the source file of the
+// workflow to make fixes to extract scripts. When it is nil, no fix is suggested.
+func NewRuleRunScript(src *SourceFile) *RuleRunScript {
+	return &RuleRunScript{
+		RuleBase: RuleBase{
+			name: "run-script",
+			desc: "Checks for long or complex scripts at \"run:\" which should be extracted into script files",
+		},
+		src: src,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleRunScript) VisitWorkflowPre(n *Workflow) error {
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		if n.Defaults.Run.Shell != nil {
+			rule.workflowShell = n.Defaults.Run.Shell.Value
+		}
+		rule.workflowDir = n.Defaults.Run.WorkingDirectory != nil
+	}
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleRunScript) VisitJobPre(n *Job) error {
+	rule.jobID = n.ID.Value
+	rule.stepIndex = 0
+	rule.jobShell = ""
+	rule.jobDir = false
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		if n.Defaults.Run.Shell != nil {
+			rule.jobShell = n.Defaults.Run.Shell.Value
+		}
+		rule.jobDir = n.Defaults.Run.WorkingDirectory != nil
+	}
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleRunScript) VisitStep(n *Step) error {
+	rule.stepIndex++
+
+	if rule.config == nil {
+		return nil
+	}
+	c := &rule.config.Rules.RunScript
+	if c.MaxLines <= 0 && c.MaxComplexity <= 0 {
+		return nil
+	}
+
+	run, ok := n.Exec.(*ExecRun)
+	if !ok || run.Run == nil {
+		return nil
+	}
+	src := strings.TrimRight(run.Run.Value, "\n")
+
+	shell := rule.shellName(run)
+	if i := strings.IndexAny(shell, " \t"); i >= 0 {
+		shell = shell[:i] // e.g. "bash -e {0}"
+	}
+	path := rule.scriptPath(n, shell)
+
+	if l := strings.Count(src, "\n") + 1; c.MaxLines > 0 && l > c.MaxLines {
+		rule.Errorf(
+			run.RunPos,
+			"script has %d lines, which exceeds the maximum %d lines. consider extracting it into a script file like %q or a composite action",
+			l,
+			c.MaxLines,
+			"./"+path,
+		)
+	} else if x := runScriptComplexity(src); c.MaxComplexity > 0 && x > c.MaxComplexity {
+		rule.Errorf(
+			run.RunPos,
+			"script contains %d loops, conditionals, and heredocs, which exceeds the maximum complexity %d. consider extracting it into a script file like %q or a composite action",
+			x,
+			c.MaxComplexity,
+			"./"+path,
+		)
+	} else {
+		return nil
+	}
+	if s := rule.extraction(n, run, path, shell); s != nil {
+		rule.Suggest(s)
+	}
+
+	return nil
+}
+
+func (rule *RuleRunScript) shellName(run *ExecRun) string {
+	if run.Shell != nil {
+		return run.Shell.Value
+	}
+	if rule.jobShell != "" {
+		return rule.jobShell
+	}
+	if rule.workflowShell != "" {
+		return rule.workflowShell
+	}
+	return "bash"
+}
+
+// scriptPath returns the path of the script file to extract the script into. The path is relative
+// to the repository root.
+func (rule *RuleRunScript) scriptPath(step *Step, shell string) string {
+	name := fmt.Sprintf("%s-%d", rule.jobID, rule.stepIndex)
+	if step.ID != nil && !step.ID.ContainsExpression() {
+		name = fmt.Sprintf("%s-%s", rule.jobID, step.ID.Value)
+	}
+
+	ext := ".sh"
+	switch shell {
+	case "pwsh", "powershell":
+		ext = ".ps1"
+	case "python":
+		ext = ".py"
+	case "cmd":
+		ext = ".cmd"
+	}
+
+	return ".github/scripts/" + name + ext
+}
+
+// extraction returns the fix to extract the script into the script file at the path. ${{ }}
+// expressions in the script are not evaluated in the script file so they are passed to the script
+// through environment variables at "env:" of the step. It returns nil when the script cannot be
+// extracted mechanically.
+func (rule *RuleRunScript) extraction(step *Step, run *ExecRun, path, shell string) *Suggestion {
+	if rule.src == nil || run.WorkingDirectory != nil || rule.jobDir || rule.workflowDir {
+		return nil // Path of the script file is relative to the working directory
+	}
+	end := rule.runValueEnd(run)
+	if end == nil {
+		return nil
+	}
+
+	script, vars, ok := runScriptPassExpressions(run.Run.Value, shell)
+	if !ok || len(vars) > 0 && step.Env != nil {
+		return nil // Merging variables into existing "env:" is not supported
+	}
+	if !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+	if strings.HasSuffix(path, ".sh") {
+		script = "#!/usr/bin/env " + shell + "\n\n" + script
+	}
+
+	cmd := "./" + path
+	switch shell {
+	case "bash", "sh", "python":
+		cmd = shell + " " + cmd
+	case "cmd":
+		cmd = "call " + strings.ReplaceAll(cmd, "/", `\`)
+	}
+	if len(vars) > 0 {
+		indent := strings.Repeat(" ", run.RunPos.Col-1)
+		var b strings.Builder
+		b.WriteString(cmd)
+		b.WriteString("\n" + indent + "env:")
+		for _, v := range vars {
+			val := "${{ " + v.expr + " }}"
+			if strings.Contains(val, ": ") || strings.Contains(val, " #") {
+				val = strconv.Quote(val)
+			}
+			fmt.Fprintf(&b, "\n%s  %s: %s", indent, v.name, val)
+		}
+		cmd = b.String()
+	}
+
+	return &Suggestion{
+		Message:     fmt.Sprintf("extract the script into %q", "./"+path),
+		Start:       run.Run.Pos,
+		End:         end,
+		Replacement: cmd,
+		NewFile:     &SuggestionFile{Path: path, Content: script},
+	}
+}
+
+// runValueEnd returns the end position of the value at "run:" in the source. Only a block scalar
+// and a plain scalar in a single line are supported. It returns nil when the end is unknown.
+func (rule *RuleRunScript) runValueEnd(run *ExecRun) *Pos {
+	lines := rule.src.Lines()
+	start, key := run.Run.Pos, run.RunPos
+	if start == nil || key == nil || start.Line <= 0 || start.Line > len(lines) || key.Line != start.Line {
+		return nil
+	}
+	l := lines[start.Line-1]
+	if strings.Trim(l[:ColumnUnitByte.FromRune(l, key.Col)-1], " -") != "" {
+		return nil // Not in block mapping (e.g. "- { run: ... }")
+	}
+	v := l[ColumnUnitByte.FromRune(l, start.Col)-1:]
+
+	if !strings.HasPrefix(v, "|") && !strings.HasPrefix(v, ">") {
+		if run.Run.Quoted || strings.Contains(run.Run.Value, "\n") {
+			return nil
+		}
+		return &Pos{Line: start.Line, Col: start.Col + utf8.RuneCountInString(run.Run.Value)}
+	}
+
+	// Content of block scalar is indented deeper than the "run:" key
+	last := start.Line - 1
+	for i := start.Line; i < len(lines); i++ {
+		c := lines[i]
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		if len(c)-len(strings.TrimLeft(c, " ")) < key.Col {
+			break
+		}
+		last = i
+	}
+	return &Pos{Line: last + 1, Col: utf8.RuneCountInString(lines[last]) + 1}
+}
+
+type runScriptVar struct {
+	name string
+	expr string
+}
+
+// runScriptPassExpressions replaces ${{ }} expressions in the script with references to
+// environment variables in the shell and returns the replaced script with the variables. It returns
+// false when the expressions cannot be replaced safely. For example, variables are not expanded in
+// heredocs with quoted delimiters.
+func runScriptPassExpressions(script, shell string) (string, []*runScriptVar, bool) {
+	vars := []*runScriptVar{}
+	if !strings.Contains(script, "${{") {
+		return script, vars, true
+	}
+	if shell == "python" || reRunScriptHeredoc.MatchString(script) {
+		return "", nil, false
+	}
+
+	names := map[string]string{}
+	used := map[string]struct{}{}
+	var b strings.Builder
+	q := &runScriptQuotes{}
+	for {
+		s := strings.Index(script, "${{")
+		if s < 0 {
+			break
+		}
+		e := strings.Index(script[s:], "}}")
+		if e < 0 {
+			break
+		}
+		expr := strings.TrimSpace(script[s+len("${{") : s+e])
+		name, ok := names[expr]
+		if !ok {
+			name = runScriptVarName(expr)
+			if _, ok := used[name]; ok || name == "" {
+				name = fmt.Sprintf("EXPR_%d", len(vars)+1)
+			}
+			names[expr] = name
+			used[name] = struct{}{}
+			vars = append(vars, &runScriptVar{name, expr})
+		}
+
+		q.scan(script[:s])
+		b.WriteString(script[:s])
+		switch shell {
+		case "pwsh", "powershell":
+			if q.single {
+				return "", nil, false
+			}
+			b.WriteString("${env:" + name + "}")
+		case "cmd":
+			b.WriteString("%" + name + "%")
+		default:
+			if q.single {
+				b.WriteString(`'"${` + name + `}"'`) // Variables are not expanded in single quotes
+			} else {
+				b.WriteString("${" + name + "}")
+			}
+		}
+		script = script[s+e+len("}}"):]
+	}
+	b.WriteString(script)
+	return b.String(), vars, true
+}
+
+// runScriptQuotes tracks quotes in a shell script roughly to know whether a position is in a
+// single-quoted string.
+type runScriptQuotes struct {
+	single  bool
+	double  bool
+	comment bool
+	escaped bool
+	prev    rune
+}
+
+func (q *runScriptQuotes) scan(s string) {
+	for _, r := range s {
+		switch {
+		case q.escaped:
+			q.escaped = false
+		case q.comment:
+			q.comment = r != '\n'
+		case q.single:
+			q.single = r != '\''
+		case r == '\\':
+			q.escaped = true
+		case q.double:
+			q.double = r != '"'
+		case r == '\'':
+			q.single = true
+		case r == '"':
+			q.double = true
+		case r == '#' && (q.prev == 0 || q.prev == ' ' || q.prev == '\t' || q.prev == '\n'):
+			q.comment = true
+		}
+		q.prev = r
+	}
+}
+
+// runScriptVarName makes a name of environment variable from the property access expression. For
+// example, "github.event.issue.title" is converted to "EVENT_ISSUE_TITLE". "GITHUB_" prefix is
+// removed since it is reserved by GitHub. It returns an empty string when the expression is not a
+// simple property access.
+func runScriptVarName(expr string) string {
+	if !reRunScriptPropAccess.MatchString(expr) {
+		return ""
+	}
+	n := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(expr))
+	return strings.TrimPrefix(n, "GITHUB_")
+}
+
+func runScriptComplexity(src string) int {
+	return len(reRunScriptLoop.FindAllStringIndex(src, -1)) +
+		len(reRunScriptConditional.FindAllStringIndex(src, -1)) +
+		len(reRunScriptHeredoc.FindAllStringIndex(src, -1))
+}
@@ -0,0 +1,148 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleRunScriptComplexity(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{"echo hello", 0},
+		{"for f in *; do echo $f; done", 1},
+		{"if true; then echo; fi\nwhile read l; do echo; done", 2},
+		{"cat <<EOF > out\nhello\nEOF", 1},
+		{"cat <<-'EOS'\nhello\nEOS\ncase $x in\n  a) ;;\nesac", 2},
+		{"echo 'iffy forward'", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			if have := runScriptComplexity(tc.src); have != tc.want {
+				t.Fatalf("wanted %d but got %d", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRuleRunScriptExtraction(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hello
+      - id: build
+        run: |
+          make
+          make test
+          make install
+      - shell: pwsh
+        run: |
+          foreach ($x in $xs) { echo $x }
+          if ($x) { echo $x }
+      - name: Greet
+        run: |
+          echo "${{ github.event.issue.title }}"
+          echo '${{ github.event.issue.title }}'
+          echo ${{ inputs.name || 'x' }}
+        shell: bash
+`
+	cfg, err := ParseConfig([]byte("rules:\n  run-script:\n    max-lines: 2\n    max-complexity: 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := testCheckRule(t, NewRuleRunScript(NewSourceFile("test.yaml", []byte(src))), cfg, src)
+	if len(errs) != 3 {
+		t.Fatalf("wanted 3 errors but got %v", errs)
+	}
+	for i, want := range []string{
+		`8:9: script has 3 lines, which exceeds the maximum 2 lines. consider extracting it into a script file like "./.github/scripts/test-build.sh"`,
+		`13:9: script contains 2 loops, conditionals, and heredocs, which exceeds the maximum complexity 1. consider extracting it into a script file like "./.github/scripts/test-3.ps1"`,
+		`17:9: script has 3 lines, which exceeds the maximum 2 lines. consider extracting it into a script file like "./.github/scripts/test-4.sh"`,
+	} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("error %q does not contain %q", errs[i].Error(), want)
+		}
+	}
+
+	ss := []*Suggestion{}
+	files := map[string]string{}
+	for _, e := range errs {
+		if len(e.Suggestions) != 1 || e.Suggestions[0].NewFile == nil {
+			t.Fatalf("error should have one suggestion creating a file: %v", e)
+		}
+		s := e.Suggestions[0]
+		ss = append(ss, s)
+		files[s.NewFile.Path] = s.NewFile.Content
+	}
+
+	wantFiles := map[string]string{
+		".github/scripts/test-build.sh": "#!/usr/bin/env bash\n\nmake\nmake test\nmake install\n",
+		".github/scripts/test-3.ps1":    "foreach ($x in $xs) { echo $x }\nif ($x) { echo $x }\n",
+		".github/scripts/test-4.sh":     "#!/usr/bin/env bash\n\necho \"${EVENT_ISSUE_TITLE}\"\necho ''\"${EVENT_ISSUE_TITLE}\"''\necho ${EXPR_2}\n",
+	}
+	if diff := cmp.Diff(wantFiles, files); diff != "" {
+		t.Fatal(diff)
+	}
+
+	fixed, applied := applySuggestions([]byte(src), ss)
+	if len(applied) != 3 {
+		t.Fatalf("wanted 3 suggestions to be applied but got %d", len(applied))
+	}
+	want := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hello
+      - id: build
+        run: bash ./.github/scripts/test-build.sh
+      - shell: pwsh
+        run: ./.github/scripts/test-3.ps1
+      - name: Greet
+        run: bash ./.github/scripts/test-4.sh
+        env:
+          EVENT_ISSUE_TITLE: ${{ github.event.issue.title }}
+          EXPR_2: ${{ inputs.name || 'x' }}
+        shell: bash
+`
+	if have := string(fixed); have != want {
+		t.Fatalf("wanted fixed workflow\n%s\nbut got\n%s", want, have)
+	}
+}
+
+func TestRuleRunScriptNoExtraction(t *testing.T) {
+	testCases := []struct {
+		what string
+		step string
+	}{
+		{"working directory", "      - working-directory: ./sub\n        run: |\n          make\n          make test\n          make install\n"},
+		{"existing env", "      - env:\n          FOO: foo\n        run: |\n          make ${{ matrix.target }}\n          make test\n          make install\n"},
+		{"expression in heredoc", "      - run: |\n          cat <<'EOS'\n          ${{ github.ref }}\n          EOS\n"},
+		{"expression in single quotes in pwsh", "      - shell: pwsh\n        run: |\n          echo '${{ github.ref }}'\n          echo 1\n          echo 2\n"},
+		{"expression in python", "      - shell: python\n        run: |\n          print('${{ github.ref }}')\n          print(1)\n          print(2)\n"},
+		{"flow mapping", "      - { run: \"make\\nmake test\\nmake install\" }\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n" + tc.step
+			cfg, err := ParseConfig([]byte("rules:\n  run-script:\n    max-lines: 2\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := testCheckRule(t, NewRuleRunScript(NewSourceFile("test.yaml", []byte(src))), cfg, src)
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %v", errs)
+			}
+			if len(errs[0].Suggestions) > 0 {
+				t.Fatalf("no fix should be suggested: %#v", errs[0].Suggestions[0])
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package actionlint
 import (
 	"path"
 	"strings"
+	"time"
 )
 
 type runnerOSCompat uint
@@ -113,6 +114,34 @@ var defaultRunnerOSCompats = map[string]runnerOSCompat{
 	"windows":                compatWindows2022 | compatWindows2019,
 }
 
+// runnerLabelLifecycle is a lifecycle of GitHub-hosted runner label. Dates are in "YYYY-MM-DD" format.
+type runnerLabelLifecycle struct {
+	// deprecated is the date when the deprecation (brownouts) of the label begins.
+	deprecated string
+	// retired is the date when the label is no longer available.
+	retired string
+	// replacement is a label recommended instead of the label.
+	replacement string
+}
+
+// runnerLabelNearRetirement is a period before the retirement date when the label is reported as
+// near retirement even if its deprecation does not begin yet.
+const runnerLabelNearRetirement = 90 * 24 * time.Hour
+
+// https://github.com/actions/runner-images#available-images
+var runnerLabelLifecycles = map[string]runnerLabelLifecycle{
+	"ubuntu-20.04":    {"2025-02-01", "2025-04-15", "ubuntu-latest"},
+	"macos-13-xl":     {"2025-09-01", "2025-12-04", "macos-latest-xlarge"},
+	"macos-13-xlarge": {"2025-09-01", "2025-12-04", "macos-latest-xlarge"},
+	"macos-13-large":  {"2025-09-01", "2025-12-04", "macos-latest-large"},
+	"macos-13":        {"2025-09-01", "2025-12-04", "macos-latest"},
+	"macos-12-xl":     {"2024-10-07", "2024-12-03", "macos-latest-xlarge"},
+	"macos-12-xlarge": {"2024-10-07", "2024-12-03", "macos-latest-xlarge"},
+	"macos-12-large":  {"2024-10-07", "2024-12-03", "macos-latest-large"},
+	"macos-12":        {"2024-10-07", "2024-12-03", "macos-latest"},
+	"windows-2019":    {"2025-06-01", "2025-06-30", "windows-latest"},
+}
+
 // RuleRunnerLabel is a rule to check runner label like "ubuntu-latest". There are two types of
 // runners, GitHub-hosted runner and Self-hosted runner. GitHub-hosted runner is described at
 // https://docs.github.com/en/actions/using-github-hosted-runners/about-github-hosted-runners .
@@ -123,7 +152,9 @@ type RuleRunnerLabel struct {
 	// Note: Using only one compatibility integer is enough to check compatibility. But we remember
 	// all past compatibility values here for better error message. If accumulating all compatibility
 	// values into one integer, we can no longer know what labels are conflicting.
-	compats map[runnerOSCompat]*String
+	compats    map[runnerOSCompat]*String
+	selfHosted bool
+	now        func() time.Time
 }
 
 // NewRuleRunnerLabel creates new RuleRunnerLabel instance.
@@ -134,6 +165,7 @@ func NewRuleRunnerLabel() *RuleRunnerLabel {
 			desc: "Checks for GitHub-hosted and preset self-hosted runner labels in \"runs-on:\"",
 		},
 		compats: nil,
+		now:     time.Now,
 	}
 }
 
@@ -143,6 +175,13 @@ func (rule *RuleRunnerLabel) VisitJobPre(n *Job) error {
 		return nil
 	}
 
+	rule.selfHosted = false
+	for _, l := range n.RunsOn.Labels {
+		if strings.EqualFold(l.Value, "self-hosted") {
+			rule.selfHosted = true
+		}
+	}
+
 	var m *Matrix
 	if n.Strategy != nil {
 		m = n.Strategy.Matrix
@@ -198,6 +237,7 @@ func (rule *RuleRunnerLabel) checkLabel(l *String, m *Matrix) {
 func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String) runnerOSCompat {
 	l := label.Value
 	if c, ok := defaultRunnerOSCompats[strings.ToLower(l)]; ok {
+		rule.checkLifecycle(label)
 		return c
 	}
 
@@ -234,6 +274,55 @@ func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String) runnerOSCompat {
 	return compatInvalid
 }
 
+func (rule *RuleRunnerLabel) checkLifecycle(label *String) {
+	if rule.config == nil || rule.config.Rules.RunnerLabel.Lifecycle == "" || rule.selfHosted {
+		return
+	}
+	lc, ok := runnerLabelLifecycles[strings.ToLower(label.Value)]
+	if !ok {
+		return
+	}
+	retired, err := time.Parse("2006-01-02", lc.retired)
+	if err != nil {
+		return
+	}
+
+	now := rule.now()
+	if !now.Before(retired) {
+		rule.Errorf(
+			label.Pos,
+			"label %q was retired on %s and jobs with the label no longer run. use newer label like %q",
+			label.Value,
+			lc.retired,
+			lc.replacement,
+		)
+		return
+	}
+
+	if rule.config.Rules.RunnerLabel.Lifecycle != "deprecated" {
+		return
+	}
+	deprecated, err := time.Parse("2006-01-02", lc.deprecated)
+	if err != nil {
+		deprecated = retired
+	}
+	state := "is deprecated and will be"
+	if now.Before(deprecated) {
+		if retired.Sub(now) > runnerLabelNearRetirement {
+			return
+		}
+		state = "will be"
+	}
+	rule.Errorf(
+		label.Pos,
+		"label %q %s retired on %s. migrate to newer label like %q before the retirement",
+		label.Value,
+		state,
+		lc.retired,
+		lc.replacement,
+	)
+}
+
 func (rule *RuleRunnerLabel) tryToGetLabelsInMatrix(label *String, m *Matrix) []*String {
 	if m == nil {
 		return nil
@@ -291,6 +380,120 @@ func (rule *RuleRunnerLabel) tryToGetLabelsInMatrix(label *String, m *Matrix) []
 	return labels
 }
 
+// runnerOSOfLabel returns the value of runner.os on runners with the label like "Linux". It returns
+// an empty string when the OS is unknown from the label.
+func runnerOSOfLabel(label string) string {
+	l := strings.ToLower(label)
+	switch {
+	case l == "linux" || strings.HasPrefix(l, "ubuntu-"):
+		return "Linux"
+	case l == "windows" || strings.HasPrefix(l, "windows-"):
+		return "Windows"
+	case l == "macos" || strings.HasPrefix(l, "macos-"):
+		return "macOS"
+	default:
+		return ""
+	}
+}
+
+// runnerOSesOfJob returns all possible values of runner.os in the job from its "runs-on" section.
+// When the label is a matrix value like ${{ matrix.os }}, OSes of all the values in the matrix are
+// returned. It returns nil when the OSes cannot be known statically.
+func runnerOSesOfJob(n *Job) []string {
+	if n.RunsOn == nil {
+		return nil
+	}
+	var m *Matrix
+	if n.Strategy != nil {
+		m = n.Strategy.Matrix
+	}
+
+	labels := n.RunsOn.Labels
+	if n.RunsOn.LabelsExpr != nil {
+		labels = []*String{n.RunsOn.LabelsExpr}
+	}
+
+	for _, l := range labels {
+		if !l.ContainsExpression() {
+			if os := runnerOSOfLabel(l.Value); os != "" {
+				return []string{os}
+			}
+			continue
+		}
+		return runnerOSesInMatrix(l, m)
+	}
+	return nil
+}
+
+// runnerOSesInMatrix returns OSes of all the values of the matrix referred by the label like
+// ${{ matrix.os }}. Unlike tryToGetLabelsInMatrix, it returns nil when some value is unknown since
+// the result is used for reporting comparisons which are always false.
+func runnerOSesInMatrix(label *String, m *Matrix) []string {
+	if m == nil || m.Expression != nil || !label.IsExpressionAssigned() {
+		return nil
+	}
+
+	l := strings.TrimSpace(label.Value)
+	expr, err := NewExprParser().Parse(NewExprLexer(l[3:])) // 3 means omit first "${{"
+	if err != nil {
+		return nil
+	}
+	deref, ok := expr.(*ObjectDerefNode)
+	if !ok || !isContextProp(deref, "matrix", deref.Property) {
+		return nil
+	}
+	prop := strings.ToLower(deref.Property)
+
+	found := map[string]struct{}{}
+	add := func(v RawYAMLValue) bool {
+		s, ok := v.(*RawYAMLString)
+		if !ok || ContainsExpression(s.Value) {
+			return false
+		}
+		os := runnerOSOfLabel(s.Value)
+		if os == "" {
+			return false
+		}
+		found[os] = struct{}{}
+		return true
+	}
+
+	if row, ok := m.Rows[prop]; ok {
+		if row.Expression != nil {
+			return nil
+		}
+		for _, v := range row.Values {
+			if !add(v) {
+				return nil
+			}
+		}
+	}
+	if m.Include != nil {
+		if m.Include.Expression != nil {
+			return nil
+		}
+		for _, c := range m.Include.Combinations {
+			if c.Expression != nil {
+				return nil
+			}
+			if a, ok := c.Assigns[prop]; ok && !add(a.Value) {
+				return nil
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(found))
+	for _, os := range runnerContextValues["os"] {
+		if _, ok := found[os]; ok {
+			ret = append(ret, os)
+		}
+	}
+	return ret
+}
+
 func (rule *RuleRunnerLabel) checkConflict(comp runnerOSCompat, label *String) bool {
 	for c, l := range rule.compats {
 		if c&comp == 0 {
@@ -3,6 +3,9 @@ package actionlint
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestRuleRunnerLabelCheckLabels(t *testing.T) {
@@ -332,3 +335,174 @@ func TestRuleRunnerLabelAllGitHubHostedRunnerLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestRuleRunnerLabelLifecycle(t *testing.T) {
+	testCases := []struct {
+		what      string
+		labels    []string
+		lifecycle string
+		now       string
+		err       string
+	}{
+		{
+			what:      "retired label",
+			labels:    []string{"ubuntu-20.04"},
+			lifecycle: "retired",
+			now:       "2025-04-15",
+			err:       `label "ubuntu-20.04" was retired on 2025-04-15 and jobs with the label no longer run. use newer label like "ubuntu-latest"`,
+		},
+		{
+			what:      "deprecated label with retired strictness",
+			labels:    []string{"windows-2019"},
+			lifecycle: "retired",
+			now:       "2025-06-10",
+		},
+		{
+			what:      "deprecated label with deprecated strictness",
+			labels:    []string{"windows-2019"},
+			lifecycle: "deprecated",
+			now:       "2025-06-10",
+			err:       `label "windows-2019" is deprecated and will be retired on 2025-06-30. migrate to newer label like "windows-latest" before the retirement`,
+		},
+		{
+			what:      "label in deprecation period",
+			labels:    []string{"macos-13-large"},
+			lifecycle: "deprecated",
+			now:       "2025-09-10",
+			err:       `label "macos-13-large" is deprecated and will be retired on 2025-12-04. migrate to newer label like "macos-latest-large"`,
+		},
+		{
+			what:      "label near retirement before deprecation",
+			labels:    []string{"ubuntu-20.04"},
+			lifecycle: "deprecated",
+			now:       "2025-01-20",
+			err:       `label "ubuntu-20.04" will be retired on 2025-04-15. migrate to newer label like "ubuntu-latest"`,
+		},
+		{
+			what:      "label far from retirement",
+			labels:    []string{"macos-13"},
+			lifecycle: "deprecated",
+			now:       "2025-01-01",
+		},
+		{
+			what:      "label without lifecycle",
+			labels:    []string{"ubuntu-latest"},
+			lifecycle: "deprecated",
+			now:       "2030-01-01",
+		},
+		{
+			what:   "check is disabled",
+			labels: []string{"ubuntu-20.04"},
+			now:    "2030-01-01",
+		},
+		{
+			what:      "self-hosted runner",
+			labels:    []string{"self-hosted", "ubuntu-20.04"},
+			lifecycle: "retired",
+			now:       "2030-01-01",
+		},
+		{
+			what:      "label in upper case",
+			labels:    []string{"macOS-12"},
+			lifecycle: "retired",
+			now:       "2030-01-01",
+			err:       `label "macOS-12" was retired on 2024-12-03`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			pos := &Pos{}
+			labels := make([]*String, 0, len(tc.labels))
+			for _, l := range tc.labels {
+				labels = append(labels, &String{l, false, pos})
+			}
+			now, err := time.Parse("2006-01-02", tc.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rule := NewRuleRunnerLabel()
+			rule.now = func() time.Time { return now }
+			cfg := Config{}
+			cfg.Rules.RunnerLabel.Lifecycle = tc.lifecycle
+			rule.SetConfig(&cfg)
+			if err := rule.VisitJobPre(&Job{RunsOn: &Runner{Labels: labels}}); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := rule.Errs()
+			if tc.err == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if have := errs[0].Message; !strings.Contains(have, tc.err) {
+				t.Fatalf("%q is not contained in error message %q", tc.err, have)
+			}
+		})
+	}
+}
+
+func TestRuleRunnerLabelRunnerOSesOfJob(t *testing.T) {
+	testCases := []struct {
+		what string
+		job  string
+		want []string
+	}{
+		{
+			what: "GitHub-hosted runner",
+			job:  "runs-on: ubuntu-latest",
+			want: []string{"Linux"},
+		},
+		{
+			what: "self-hosted runner",
+			job:  "runs-on: [self-hosted, Windows, x64]",
+			want: []string{"Windows"},
+		},
+		{
+			what: "custom label",
+			job:  "runs-on: [self-hosted, my-runner]",
+		},
+		{
+			what: "matrix",
+			job:  "strategy:\n      matrix:\n        os: [ubuntu-latest, macos-latest, ubuntu-22.04]\n    runs-on: ${{ matrix.os }}",
+			want: []string{"Linux", "macOS"},
+		},
+		{
+			what: "matrix with include",
+			job:  "strategy:\n      matrix:\n        os: [ubuntu-latest]\n        include:\n          - os: windows-latest\n    runs-on: ${{ matrix.os }}",
+			want: []string{"Linux", "Windows"},
+		},
+		{
+			what: "matrix with unknown label",
+			job:  "strategy:\n      matrix:\n        os: [ubuntu-latest, my-runner]\n    runs-on: ${{ matrix.os }}",
+		},
+		{
+			what: "matrix with expression",
+			job:  "strategy:\n      matrix:\n        os: ${{ fromJSON(vars.OSES) }}\n        include:\n          - os: ubuntu-latest\n    runs-on: ${{ matrix.os }}",
+		},
+		{
+			what: "expression which is not matrix value",
+			job:  "runs-on: ${{ vars.RUNNER }}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    " + tc.job + "\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			have := runnerOSesOfJob(w.Jobs["test"])
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package actionlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// runnerImageTools is a dataset of commands which are preinstalled only on some of GitHub-hosted
+// runner images. Keys are command names and values are OS families ("linux", "macos", "windows")
+// of images where the command is available. Commands available on all images are not listed here
+// since they never cause an error.
+// https://github.com/actions/runner-images
+var runnerImageTools = map[string][]string{
+	// Package managers
+	"apt":            {"linux"},
+	"apt-get":        {"linux"},
+	"apt-cache":      {"linux"},
+	"dpkg":           {"linux"},
+	"snap":           {"linux"},
+	"brew":           {"linux", "macos"},
+	"choco":          {"windows"},
+	"yum":            {},
+	"dnf":            {},
+	"apk":            {},
+	"pacman":         {"windows"}, // MSYS2
+	"zypper":         {},
+	"vcpkg":          {"linux", "windows"},
+	"scoop":          {},
+	"nuget":          {"windows", "macos"},
+	"softwareupdate": {"macos"},
+	// Containers
+	"docker":         {"linux", "windows"},
+	"docker-compose": {"linux", "windows"},
+	"podman":         {"linux"},
+	"buildah":        {"linux"},
+	"skopeo":         {"linux"},
+	"minikube":       {"linux"},
+	// System tools
+	"sudo":         {"linux", "macos"},
+	"systemctl":    {"linux"},
+	"journalctl":   {"linux"},
+	"ldd":          {"linux", "windows"},
+	"lsb_release":  {"linux"},
+	"xvfb-run":     {"linux"},
+	"launchctl":    {"macos"},
+	"sw_vers":      {"macos"},
+	"hdiutil":      {"macos"},
+	"diskutil":     {"macos"},
+	"otool":        {"macos"},
+	"codesign":     {"macos"},
+	"pkgbuild":     {"macos"},
+	"productbuild": {"macos"},
+	"xcodebuild":   {"macos"},
+	"xcrun":        {"macos"},
+	"xcode-select": {"macos"},
+	"powershell":   {"windows"},
+	"cmd":          {"windows"},
+	"msbuild":      {"windows"},
+	"signtool":     {"windows"},
+	"certutil":     {"windows"},
+	"wsl":          {"windows"},
+}
+
+var (
+	reRunnerToolsCommand = regexp.MustCompile(`(?:^|[;&|(\x60]|\$\()\s*(?:[A-Za-z_][A-Za-z0-9_]*=\S*\s+)*([A-Za-z0-9_./-]+)(?:\s|$)`)
+	reRunnerToolsQuoted  = regexp.MustCompile(`"[^"]*"|'[^']*'|\$\{\{[^}]*\}\}`)
+)
+
+// Words followed by other commands. Wrapper commands like "sudo" and shell keywords like "then"
+var runnerToolsPrefixes = map[string]struct{}{
+	"sudo":    {},
+	"time":    {},
+	"exec":    {},
+	"nohup":   {},
+	"command": {},
+	"if":      {},
+	"then":    {},
+	"else":    {},
+	"elif":    {},
+	"do":      {},
+	"while":   {},
+	"until":   {},
+}
+
+// RuleRunnerTools is a rule to check commands at "run:" are available on GitHub-hosted runner
+// images. For example, Docker is not installed on macOS runners and apt-get is not available on
+// Windows runners. Self-hosted runners are not checked unless their labels are configured in the
+// "runner-tools" configuration in the "rules" section of the configuration file.
+type RuleRunnerTools struct {
+	RuleBase
+	os        string
+	runner    *String
+	available map[string]struct{}
+}
+
+// NewRuleRunnerTools creates a new RuleRunnerTools instance.
+func NewRuleRunnerTools() *RuleRunnerTools {
+	return &RuleRunnerTools{
+		RuleBase: RuleBase{
+			name: "runner-tools",
+			desc: "Checks for commands at \"run:\" which are not installed on the runner image",
+		},
+	}
+}
+
+func runnerOSFamily(c runnerOSCompat) string {
+	switch {
+	case c == compatInvalid:
+		return ""
+	case c&(compatUbuntu2004|compatUbuntu2204|compatUbuntu2404) == c:
+		return "linux"
+	case c&(compatWindows2019|compatWindows2022) == c:
+		return "windows"
+	case c&(compatMacOS120|compatMacOS120L|compatMacOS120XL|compatMacOS130|compatMacOS130L|compatMacOS130XL|compatMacOS140|compatMacOS140L|compatMacOS140XL|compatMacOS150|compatMacOS150L|compatMacOS150XL) == c:
+		return "macos"
+	default:
+		return ""
+	}
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleRunnerTools) VisitJobPre(n *Job) error {
+	rule.os = ""
+	rule.runner = nil
+	rule.available = map[string]struct{}{}
+
+	// Steps run in the container. Commands on the runner image are not relevant
+	if n.RunsOn == nil || n.RunsOn.LabelsExpr != nil || n.RunsOn.Group != nil || n.Container != nil {
+		return nil
+	}
+
+	var conf map[string][]string
+	if rule.config != nil {
+		conf = rule.config.Rules.RunnerTools.Labels
+	}
+
+	selfHosted := false
+	configured := false
+	for _, l := range n.RunsOn.Labels {
+		if l.ContainsExpression() {
+			return nil
+		}
+		v := strings.ToLower(l.Value)
+		if v == "self-hosted" {
+			selfHosted = true
+		}
+		for k, cmds := range conf {
+			if strings.EqualFold(k, v) {
+				configured = true
+				for _, c := range cmds {
+					rule.available[c] = struct{}{}
+				}
+			}
+		}
+		if c, ok := defaultRunnerOSCompats[v]; ok && rule.os == "" {
+			if os := runnerOSFamily(c); os != "" {
+				rule.os = os
+				rule.runner = l
+			}
+		}
+	}
+
+	if selfHosted && !configured {
+		rule.os = "" // Tools on self-hosted runners are unknown
+	}
+
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleRunnerTools) VisitStep(n *Step) error {
+	if rule.os == "" {
+		return nil
+	}
+
+	switch e := n.Exec.(type) {
+	case *ExecAction:
+		// Assume actions like "docker/setup-docker-action" install the command
+		if e.Uses != nil {
+			for c := range runnerImageTools {
+				if strings.Contains(strings.ToLower(e.Uses.Value), "setup-"+c) {
+					rule.available[c] = struct{}{}
+				}
+			}
+		}
+	case *ExecRun:
+		if e.Shell != nil && strings.HasPrefix(e.Shell.Value, "python") {
+			return nil
+		}
+		if e.Run != nil {
+			rule.checkScript(e.Run)
+			// Commands in directories added to $GITHUB_PATH are available in subsequent steps
+			if strings.Contains(e.Run.Value, "GITHUB_PATH") {
+				rule.os = ""
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rule *RuleRunnerTools) checkScript(run *String) {
+	src := strings.ReplaceAll(run.Value, "\\\n", " ") // Join continued lines
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Arguments in quotes are not commands
+		line = reRunnerToolsQuoted.ReplaceAllString(line, `""`)
+
+		for _, m := range reRunnerToolsCommand.FindAllStringSubmatchIndex(line, -1) {
+			// Check the command and the commands following prefixes like "sudo apt-get install ..."
+			words := strings.Fields(line[m[2]:])
+			for _, w := range words {
+				if strings.HasPrefix(w, "-") {
+					continue // Option of wrapper command like "sudo -E"
+				}
+				cmd := rule.commandName(w)
+				rule.checkCommand(cmd, run)
+				if _, ok := runnerToolsPrefixes[cmd]; !ok {
+					break
+				}
+			}
+		}
+
+		// Commands installed in the script (e.g. "brew install docker") are available in later steps
+		if i := strings.Index(line, " install "); i >= 0 {
+			for _, w := range strings.Fields(line[i+len(" install "):]) {
+				rule.available[w] = struct{}{}
+			}
+		}
+	}
+}
+
+func (rule *RuleRunnerTools) commandName(w string) string {
+	if strings.HasPrefix(w, "/") {
+		w = w[strings.LastIndexByte(w, '/')+1:] // e.g. /usr/bin/docker
+	}
+	if rule.os == "windows" {
+		w = strings.TrimSuffix(strings.ToLower(w), ".exe")
+	}
+	return w
+}
+
+func (rule *RuleRunnerTools) checkCommand(cmd string, run *String) {
+	oses, ok := runnerImageTools[cmd]
+	if !ok {
+		return
+	}
+	if _, ok := rule.available[cmd]; ok {
+		return
+	}
+	for _, os := range oses {
+		if os == rule.os {
+			return
+		}
+	}
+	rule.Errorf(
+		run.Pos,
+		"command %q is not installed on the image of runner %q. install it in previous steps or use another runner. if it is installed on your self-hosted runner, add it to \"labels\" of \"runner-tools\" rule in actionlint.yaml",
+		cmd,
+		rule.runner.Value,
+	)
+	rule.available[cmd] = struct{}{} // Report each command only once in a job
+}
@@ -0,0 +1,76 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleRunnerToolsConfig(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: [self-hosted, macos, docker]
+    steps:
+      - run: docker info
+      - run: apt-get install -y foo
+  unknown:
+    runs-on: [self-hosted, linux]
+    steps:
+      - run: xcodebuild -version
+`
+	cfg, err := ParseConfig([]byte("rules:\n  runner-tools:\n    labels:\n      docker: [docker]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := testCheckRule(t, NewRuleRunnerTools(), cfg, src)
+	if len(errs) != 1 {
+		t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+	}
+	if msg := errs[0].Message; !strings.Contains(msg, `command "apt-get" is not installed on the image of runner "macos"`) {
+		t.Fatalf("unexpected error message %q", msg)
+	}
+	if errs[0].Line != 7 {
+		t.Fatalf("unexpected line %d", errs[0].Line)
+	}
+}
+
+func TestRuleRunnerToolsCommandsInScript(t *testing.T) {
+	tests := []struct {
+		what   string
+		script string
+		want   []string
+	}{
+		{"simple", "docker build .", []string{"docker"}},
+		{"env assignment", "FOO=1 BAR=2 docker build .", []string{"docker"}},
+		{"sudo", "sudo -E docker build .", []string{"docker"}},
+		{"absolute path", "/usr/local/bin/docker build .", []string{"docker"}},
+		{"pipeline", "cat Dockerfile | docker build -", []string{"docker"}},
+		{"command substitution", "echo $(docker ps -q)", []string{"docker"}},
+		{"then", "if true; then docker info; fi", []string{"docker"}},
+		{"continued line", "brew install \\\n  docker", []string{}},
+		{"argument", "echo docker", []string{}},
+		{"quoted", "echo 'a; docker'", []string{}},
+		{"comment", "# docker build .", []string{}},
+		{"relative path", "./docker build .", []string{}},
+		{"key", "docker: foo", []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			r := NewRuleRunnerTools()
+			r.os = "macos"
+			r.runner = &String{Value: "macos-latest", Pos: &Pos{}}
+			r.available = map[string]struct{}{}
+			r.checkScript(&String{Value: tc.script, Pos: &Pos{}})
+
+			have := []string{}
+			for _, err := range r.Errs() {
+				have = append(have, strings.SplitN(err.Message, `"`, 3)[1])
+			}
+			if strings.Join(have, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("wanted %v but got %v", tc.want, have)
+			}
+		})
+	}
+}
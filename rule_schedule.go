@@ -0,0 +1,441 @@
+package actionlint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// scheduleZone is a time zone abbreviation which may appear in comments of schedules.
+type scheduleZone struct {
+	offset int  // Offset from UTC in minutes
+	dst    bool // True when the region of the zone observes daylight saving time
+}
+
+var scheduleZoneAbbrs = map[string]scheduleZone{
+	"HST":  {-10 * 60, false},
+	"AKST": {-9 * 60, true},
+	"AKDT": {-8 * 60, true},
+	"PST":  {-8 * 60, true},
+	"PDT":  {-7 * 60, true},
+	"MST":  {-7 * 60, true},
+	"MDT":  {-6 * 60, true},
+	"CST":  {-6 * 60, true},
+	"CDT":  {-5 * 60, true},
+	"EST":  {-5 * 60, true},
+	"EDT":  {-4 * 60, true},
+	"BST":  {60, true},
+	"CET":  {60, true},
+	"CEST": {2 * 60, true},
+	"EET":  {2 * 60, true},
+	"EEST": {3 * 60, true},
+	"IST":  {5*60 + 30, false},
+	"JST":  {9 * 60, false},
+	"KST":  {9 * 60, false},
+	"AEST": {10 * 60, true},
+	"AEDT": {11 * 60, true},
+	"NZST": {12 * 60, true},
+	"NZDT": {13 * 60, true},
+}
+
+var (
+	reScheduleZone     = regexp.MustCompile(`\b(?:(?:UTC|GMT)([+-])(\d{1,2})(?::?(\d{2}))?|(` + scheduleZoneAlternation() + `)|((?i:local[ -]?time)))\b`)
+	reScheduleTime12h  = regexp.MustCompile(`(?i)\b(\d{1,2})(?::([0-5]\d))?\s*([ap])\.?m\b`)
+	reScheduleTime24h  = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+	scheduleTimeSample = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC) // Fixed date to make outputs deterministic
+)
+
+func scheduleZoneAlternation() string {
+	ss := make([]string, 0, len(scheduleZoneAbbrs))
+	for a := range scheduleZoneAbbrs {
+		ss = append(ss, a)
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, "|")
+}
+
+// scheduleHint is a hint of local time found in a comment or a workflow name near a schedule like
+// "9am PST".
+type scheduleHint struct {
+	text   string // Text of the comment or the name
+	zone   string // Name of the time zone like "PST" or "Asia/Tokyo". Empty when only "local time" is mentioned
+	offset int    // Offset from UTC in minutes
+	dst    bool   // True when the zone observes daylight saving time
+	hour   int    // Hour mentioned in the text. -1 when no time is mentioned
+	min    int    // Minute mentioned in the text
+}
+
+// RuleSchedule is a rule to check schedules at "on.schedule". Since cron schedules are always
+// evaluated in UTC, comments or a workflow name implying local time near schedules are reported.
+// Schedules which trigger the workflow at the same minute are also reported when it is enabled in
+// the configuration.
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#schedule
+type RuleSchedule struct {
+	RuleBase
+	src *SourceFile
+	loc *time.Location
+}
+
+// NewRuleSchedule creates a new RuleSchedule instance. The src parameter is the source file of the
+// workflow to check. When it is nil, comments near schedules are not checked.
+func NewRuleSchedule(src *SourceFile) *RuleSchedule {
+	return &RuleSchedule{
+		RuleBase: RuleBase{
+			name: "schedule",
+			desc: "Checks schedules at \"on.schedule\" for local times and collisions",
+		},
+		src: src,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleSchedule) VisitWorkflowPre(n *Workflow) error {
+	var sched *ScheduledEvent
+	for _, e := range n.On {
+		if e, ok := e.(*ScheduledEvent); ok {
+			sched = e
+			break
+		}
+	}
+	if sched == nil || len(sched.Cron) == 0 {
+		return nil
+	}
+
+	rule.loc = nil
+	if cfg := rule.Config(); cfg != nil && cfg.Rules.Schedule.AssumeTimezone != "" {
+		if l, err := time.LoadLocation(cfg.Rules.Schedule.AssumeTimezone); err == nil {
+			rule.loc = l // The time zone was validated when parsing the config
+		}
+	}
+
+	comments, shared := rule.scheduleComments()
+	if len(sched.Cron) == 1 {
+		// The workflow name and comments of "schedule:" describe the schedule only when it is single
+		if n.Name != nil && n.Name.Value != "" {
+			shared = append(shared, n.Name.Value)
+		}
+		c := sched.Cron[0]
+		rule.checkLocalTime(c, append(comments[c.Pos.Line], shared...))
+	} else {
+		for _, c := range sched.Cron {
+			rule.checkLocalTime(c, comments[c.Pos.Line])
+		}
+	}
+
+	if cfg := rule.Config(); cfg != nil && cfg.Rules.Schedule.Collisions {
+		rule.checkCollisions(sched.Cron)
+	}
+	return nil
+}
+
+// scheduleComments collects comments in "schedule:" section of the source. The first return value
+// maps line numbers of cron values to comments of the schedule items. The second return value is
+// comments of the "schedule:" key.
+func (rule *RuleSchedule) scheduleComments() (map[int][]string, []string) {
+	ret := map[int][]string{}
+	if rule.src == nil {
+		return ret, nil
+	}
+
+	root, err := rule.src.Node()
+	if err != nil || len(root.Content) == 0 {
+		return ret, nil
+	}
+	on := findYAMLMappingValue(root.Content[0], "on")
+	if on == nil || on.Kind != yaml.MappingNode {
+		return ret, nil
+	}
+
+	var shared []string
+	var seq *yaml.Node
+	for i := 0; i+1 < len(on.Content); i += 2 {
+		if k := on.Content[i]; k.Value == "schedule" {
+			shared = appendYAMLComments(shared, k, on.Content[i+1])
+			seq = on.Content[i+1]
+			break
+		}
+	}
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return ret, shared
+	}
+
+	for _, item := range seq.Content {
+		v := findYAMLMappingValue(item, "cron")
+		if v == nil {
+			continue
+		}
+		cs := appendYAMLComments(nil, item)
+		for _, c := range item.Content {
+			cs = appendYAMLComments(cs, c)
+		}
+		ret[v.Line] = cs
+	}
+	return ret, shared
+}
+
+func findYAMLMappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func appendYAMLComments(cs []string, ns ...*yaml.Node) []string {
+	for _, n := range ns {
+		for _, c := range []string{n.HeadComment, n.LineComment, n.FootComment} {
+			for _, l := range strings.Split(c, "\n") {
+				if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "#")); l != "" {
+					cs = append(cs, l)
+				}
+			}
+		}
+	}
+	return cs
+}
+
+// findHint finds the hint of local time from the given texts. It returns nil when no text implies
+// local time.
+func (rule *RuleSchedule) findHint(texts []string) *scheduleHint {
+	for _, t := range texts {
+		h := &scheduleHint{text: t, hour: -1}
+		h.hour, h.min = parseScheduleTimeOfDay(t)
+
+		m := reScheduleZone.FindStringSubmatch(t)
+		switch {
+		case m == nil:
+			// When the time zone is assumed, time in the text without time zone is in the time zone
+			if rule.loc == nil || h.hour < 0 {
+				continue
+			}
+			// Daylight saving time is not warned since the annotation of local time shows it
+			std, _ := scheduleLocationOffsets(rule.loc)
+			h.zone, h.offset = rule.loc.String(), std
+		case m[1] != "":
+			hours, _ := strconv.Atoi(m[2])
+			mins, _ := strconv.Atoi(m[3])
+			h.zone, h.offset = m[0], hours*60+mins
+			if m[1] == "-" {
+				h.offset = -h.offset
+			}
+		case m[4] != "":
+			z := scheduleZoneAbbrs[m[4]]
+			h.zone, h.offset, h.dst = m[4], z.offset, z.dst
+		default:
+			// Only "local time" is mentioned
+		}
+		return h
+	}
+	return nil
+}
+
+// parseScheduleTimeOfDay parses time of day like "9am" or "21:30" in the text. It returns -1 as hour
+// when no time is found.
+func parseScheduleTimeOfDay(s string) (int, int) {
+	if m := reScheduleTime12h.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		min, _ := strconv.Atoi(m[2])
+		if h < 1 || h > 12 {
+			return -1, 0
+		}
+		h %= 12
+		if strings.EqualFold(m[3], "p") {
+			h += 12
+		}
+		return h, min
+	}
+	if m := reScheduleTime24h.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		min, _ := strconv.Atoi(m[2])
+		return h, min
+	}
+	return -1, 0
+}
+
+// scheduleLocationOffsets returns offsets of the location from UTC in minutes in winter and summer.
+func scheduleLocationOffsets(loc *time.Location) (int, int) {
+	_, w := time.Date(2024, time.January, 15, 12, 0, 0, 0, loc).Zone()
+	_, s := time.Date(2024, time.July, 15, 12, 0, 0, 0, loc).Zone()
+	if s < w {
+		w, s = s, w // Southern hemisphere
+	}
+	return w / 60, s / 60
+}
+
+// parseScheduleTime returns hour and minute when the cron spec runs once at the fixed time of day.
+func parseScheduleTime(spec string) (int, int, bool) {
+	fs := strings.Fields(spec)
+	if len(fs) != 5 {
+		return 0, 0, false
+	}
+	m, err := strconv.Atoi(fs[0])
+	if err != nil || m < 0 || m > 59 {
+		return 0, 0, false
+	}
+	h, err := strconv.Atoi(fs[1])
+	if err != nil || h < 0 || h > 23 {
+		return 0, 0, false
+	}
+	return h, m, true
+}
+
+func formatScheduleClock(mins int) string {
+	mins = (mins%(24*60) + 24*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", mins/60, mins%60)
+}
+
+// localTime returns the annotation of the time in UTC with the local time in the assumed time zone
+// like " (09:00 in Asia/Tokyo)". It returns an empty string when no time zone is assumed.
+func (rule *RuleSchedule) localTime(hour, min int) string {
+	if rule.loc == nil {
+		return ""
+	}
+	std, dst := scheduleLocationOffsets(rule.loc)
+	t := hour*60 + min
+	if std == dst {
+		return fmt.Sprintf(" (%s in %s)", formatScheduleClock(t+std), rule.loc)
+	}
+	return fmt.Sprintf(" (%s or %s in %s depending on daylight saving time)", formatScheduleClock(t+std), formatScheduleClock(t+dst), rule.loc)
+}
+
+func (rule *RuleSchedule) checkLocalTime(spec *String, texts []string) {
+	h := rule.findHint(texts)
+	if h == nil {
+		return
+	}
+
+	hour, min, fixed := parseScheduleTime(spec.Value)
+	at := ""
+	if fixed {
+		at = fmt.Sprintf(" at %02d:%02d UTC%s", hour, min, rule.localTime(hour, min))
+	}
+
+	if h.zone == "" {
+		rule.Errorf(
+			spec.Pos,
+			"schedule %q runs%s but %q mentions local time. schedules of GitHub Actions are always evaluated in UTC. convert the time to UTC",
+			spec.Value,
+			at,
+			h.text,
+		)
+		return
+	}
+
+	// Check the schedule was written in local time like `0 9 * * *  # 9am PST`
+	if fixed && h.hour == hour && h.min == min && h.offset != 0 {
+		utc := hour*60 + min - h.offset
+		rule.Errorf(
+			spec.Pos,
+			"schedule %q runs%s but %q implies it is written in local time %02d:%02d %s. schedules of GitHub Actions are always evaluated in UTC. the time in UTC is %s",
+			spec.Value,
+			at,
+			h.text,
+			h.hour,
+			h.min,
+			h.zone,
+			formatScheduleClock(utc),
+		)
+		fs := strings.Fields(spec.Value)
+		// Converting the time may change the day. Only suggest the fix when the day is not restricted
+		if fs[2] == "*" && fs[3] == "*" && fs[4] == "*" && !strings.ContainsAny(spec.Value, "\\'\"\n") {
+			utc = (utc%(24*60) + 24*60) % (24 * 60)
+			fixed := fmt.Sprintf("%d %d * * *", utc%60, utc/60)
+			start := spec.Pos
+			if spec.Quoted {
+				start = start.advance("'") // Skip the opening quote
+			}
+			rule.Suggest(&Suggestion{
+				Message:     fmt.Sprintf("convert the schedule to UTC %q", fixed),
+				Start:       start,
+				End:         start.advance(spec.Value),
+				Replacement: fixed,
+			})
+		}
+		return
+	}
+
+	if h.dst {
+		rule.Errorf(
+			spec.Pos,
+			"schedule %q runs%s but %q implies local time in %s which observes daylight saving time. schedules of GitHub Actions are always evaluated in UTC so the local time of the run shifts by one hour when daylight saving time starts or ends",
+			spec.Value,
+			at,
+			h.text,
+			h.zone,
+		)
+	}
+}
+
+// checkCollisions checks multiple schedules do not trigger the workflow at the same minute. In the
+// case, the workflow runs multiple times at the same time.
+func (rule *RuleSchedule) checkCollisions(specs []*String) {
+	if len(specs) < 2 {
+		return
+	}
+
+	p := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	scheds := make([]cron.Schedule, len(specs))
+	for i, s := range specs {
+		if c, err := p.Parse(s.Value); err == nil {
+			scheds[i] = c // Invalid format is reported by "events" rule
+		}
+	}
+
+	for i := 1; i < len(specs); i++ {
+		if scheds[i] == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if scheds[j] == nil {
+				continue
+			}
+			t, ok := firstScheduleCollision(scheds[i], scheds[j])
+			if !ok {
+				continue
+			}
+			const layout = "Mon, 02 Jan 15:04"
+			local := ""
+			if rule.loc != nil {
+				local = fmt.Sprintf(" which is %s in %s", t.In(rule.loc).Format(layout), rule.loc)
+			}
+			rule.Errorf(
+				specs[i].Pos,
+				"schedule %q collides with schedule %q at line %d. both trigger the workflow at the same minute (e.g. %s UTC%s) so the workflow runs twice",
+				specs[i].Value,
+				specs[j].Value,
+				specs[j].Pos.Line,
+				t.Format(layout),
+				local,
+			)
+			break
+		}
+	}
+}
+
+// firstScheduleCollision finds the first time when both schedules trigger the workflow in a year.
+func firstScheduleCollision(a, b cron.Schedule) (time.Time, bool) {
+	start := scheduleTimeSample.Add(-time.Minute)
+	end := scheduleTimeSample.AddDate(1, 0, 0)
+	ta, tb := a.Next(start), b.Next(start)
+	for !ta.IsZero() && !tb.IsZero() && ta.Before(end) && tb.Before(end) {
+		switch {
+		case ta.Equal(tb):
+			return ta, true
+		case ta.Before(tb):
+			ta = a.Next(tb.Add(-time.Second))
+		default:
+			tb = b.Next(ta.Add(-time.Second))
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,144 @@
+package actionlint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleScheduleSuggestUTC(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "quoted",
+			input: "- cron: '0 9 * * *' # 9am PST",
+			want:  "- cron: '0 17 * * *' # 9am PST",
+		},
+		{
+			what:  "unquoted",
+			input: "- cron: 30 23 * * * # 23:30 JST",
+			want:  "- cron: 30 14 * * * # 23:30 JST",
+		},
+		{
+			what:  "previous day",
+			input: "- cron: '0 1 * * *' # 1am UTC+2",
+			want:  "- cron: '0 23 * * *' # 1am UTC+2",
+		},
+		{
+			what:  "offset with minutes",
+			input: "- cron: '0 9 * * *' # 9:00 IST",
+			want:  "- cron: '30 3 * * *' # 9:00 IST",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on:\n  schedule:\n    " + tc.input + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), nil, src)
+			if len(errs) != 1 || len(errs[0].Suggestions) != 1 {
+				t.Fatalf("one error with one suggestion was expected but got %v", errs)
+			}
+			b, err := errs[0].Suggestions[0].Apply([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "on:\n  schedule:\n    " + tc.want + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			if string(b) != want {
+				t.Fatalf("wanted %q but got %q", want, string(b))
+			}
+		})
+	}
+}
+
+func TestRuleScheduleAssumeTimezone(t *testing.T) {
+	cfg, err := ParseConfig([]byte("rules:\n  schedule:\n    assume-timezone: Asia/Tokyo\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := `name: Nightly at 9am
+on:
+  schedule:
+    - cron: '0 9 * * *'
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), cfg, src)
+	msgs := []string{}
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{
+		`schedule "0 9 * * *" runs at 09:00 UTC (18:00 in Asia/Tokyo) but "Nightly at 9am" implies it is written in local time 09:00 Asia/Tokyo. schedules of GitHub Actions are always evaluated in UTC. the time in UTC is 00:00`,
+	}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Without the assumed time zone, the time in the workflow name is not checked
+	if errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected without assumed time zone but got", errs)
+	}
+}
+
+func TestRuleScheduleNoCollision(t *testing.T) {
+	src := `on:
+  schedule:
+    - cron: '3 0 * * 1'
+    - cron: '3 0 * * 2'
+    - cron: '5 0 * * *'
+    - cron: '*/10 * * * *'
+    - cron: '7 0 29 2 *'
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	cfg := &Config{}
+	cfg.Rules.Schedule.Collisions = true
+	if errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), cfg, src); len(errs) != 0 {
+		t.Fatal("no error was expected but got", errs)
+	}
+}
+
+func TestRuleScheduleCollisions(t *testing.T) {
+	src := `on:
+  schedule:
+    - cron: '0 0 * * *'
+    - cron: '0 0 * * 1'
+    - cron: '*/5 * * * *'
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	cfg, err := ParseConfig([]byte("rules:\n  schedule:\n    assume-timezone: Asia/Tokyo\n    collisions: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), cfg, src)
+	msgs := []string{}
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{
+		`schedule "0 0 * * 1" collides with schedule "0 0 * * *" at line 3. both trigger the workflow at the same minute (e.g. Mon, 01 Jan 00:00 UTC which is Mon, 01 Jan 09:00 in Asia/Tokyo) so the workflow runs twice`,
+		`schedule "*/5 * * * *" collides with schedule "0 0 * * *" at line 3. both trigger the workflow at the same minute (e.g. Mon, 01 Jan 00:00 UTC which is Mon, 01 Jan 09:00 in Asia/Tokyo) so the workflow runs twice`,
+	}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Collisions are not checked by default
+	if errs := testCheckRule(t, NewRuleSchedule(NewSourceFile("test.yaml", []byte(src))), nil, src); len(errs) != 0 {
+		t.Fatal("no error was expected without enabling the check but got", errs)
+	}
+}
@@ -0,0 +1,182 @@
+package actionlint
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Location at the head of each error line output by script linters. The file name and the column
+// are optional. For example, "stdin:1:7: message", "-:1: message", and "1:7: message".
+var reScriptLinterError = regexp.MustCompile(`^(?:[^:]*:)??(\d+):(?:(\d+):)?\s*(.+)$`)
+
+type scriptLinter struct {
+	name string
+	cmd  *externalCommand
+}
+
+// RuleScriptLinter is a rule to check scripts at 'run:' with external linters configured at
+// "script-linters" in the configuration file. Each linter is selected by the shell name at
+// "shell:" and it reads the script from stdin.
+type RuleScriptLinter struct {
+	RuleBase
+	linters       map[string]*scriptLinter
+	workflowShell string
+	jobShell      string
+	runnerShell   string
+	lines         sourceLines
+	mu            sync.Mutex
+}
+
+// NewRuleScriptLinter creates new RuleScriptLinter instance. The linters argument is a mapping
+// from shell names to command lines of the linters. When some command is not found in system, it
+// returns an error as 2nd return value.
+func NewRuleScriptLinter(linters map[string][]string, proc *concurrentProcess) (*RuleScriptLinter, error) {
+	shells := make([]string, 0, len(linters))
+	for s := range linters {
+		shells = append(shells, s)
+	}
+	sort.Strings(shells)
+
+	m := make(map[string]*scriptLinter, len(linters))
+	for _, s := range shells {
+		cmdline := linters[s]
+		cmd, err := proc.newCommandRunner(cmdline[0], true) // Linters like `ruby -c` output errors to stderr
+		if err != nil {
+			return nil, fmt.Errorf("command for shell %q at \"script-linters\" was not found: %w", s, err)
+		}
+		cmd.args = append(cmd.args, cmdline[1:]...)
+		m[s] = &scriptLinter{path.Base(cmdline[0]), cmd}
+	}
+
+	return &RuleScriptLinter{
+		RuleBase: RuleBase{
+			name: "script-linter",
+			desc: "Checks for scripts in \"run:\" using external linters configured at \"script-linters\"",
+		},
+		linters: m,
+	}, nil
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleScriptLinter) VisitWorkflowPre(n *Workflow) error {
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.workflowShell = n.Defaults.Run.Shell.Value
+	}
+	return nil
+}
+
+// VisitWorkflowPost is callback when visiting Workflow node after visiting its children.
+func (rule *RuleScriptLinter) VisitWorkflowPost(n *Workflow) error {
+	rule.workflowShell = ""
+	var err error
+	for _, l := range rule.linters {
+		if e := l.cmd.wait(); e != nil && err == nil {
+			err = e // Wait until all processes running for this rule
+		}
+	}
+	return err
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleScriptLinter) VisitJobPre(n *Job) error {
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.jobShell = n.Defaults.Run.Shell.Value
+	}
+	if n.RunsOn != nil {
+		for _, label := range n.RunsOn.Labels {
+			l := strings.ToLower(label.Value)
+			if l == "windows" || strings.HasPrefix(l, "windows-") {
+				rule.runnerShell = "pwsh"
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// VisitJobPost is callback when visiting Job node after visiting its children.
+func (rule *RuleScriptLinter) VisitJobPost(n *Job) error {
+	rule.jobShell = ""
+	rule.runnerShell = ""
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleScriptLinter) VisitStep(n *Step) error {
+	run, ok := n.Exec.(*ExecRun)
+	if !ok || run.Run == nil {
+		return nil
+	}
+
+	shell := "bash"
+	switch {
+	case run.Shell != nil:
+		shell = run.Shell.Value
+	case rule.jobShell != "":
+		shell = rule.jobShell
+	case rule.workflowShell != "":
+		shell = rule.workflowShell
+	case rule.runnerShell != "":
+		shell = rule.runnerShell
+	}
+	// Custom shell is in the format of "command [options] {0}". "/usr/bin/ruby {0}" is the same as "ruby"
+	if i := strings.IndexAny(shell, " \t"); i >= 0 {
+		shell = shell[:i]
+	}
+	shell = path.Base(shell)
+
+	if l, ok := rule.linters[shell]; ok {
+		rule.runLinter(l, run.Run, run.RunPos)
+	}
+	return nil
+}
+
+func (rule *RuleScriptLinter) runLinter(linter *scriptLinter, str *String, pos *Pos) {
+	src := sanitizeExpressionsInScript(str.Value) // Defined at rule_shellcheck.go
+	rule.Debug("%s: Running %s for script:\n%s", pos, linter.cmd.exe, src)
+
+	linter.cmd.run(nil, src, func(stdout []byte, err error) error {
+		var timeout *processTimeoutError
+		if errors.As(err, &timeout) {
+			rule.mu.Lock()
+			rule.reportTimeout(pos, timeout)
+			rule.mu.Unlock()
+			return nil
+		}
+		if err != nil {
+			rule.Debug("Command %s failed: %v", linter.cmd.exe, err)
+			return fmt.Errorf("`%s` did not run successfully while checking script at %s: %w", linter.cmd.exe, pos, err)
+		}
+		if len(stdout) == 0 {
+			return nil
+		}
+
+		loc := newScriptLocation(rule.lines, str, src, pos)
+
+		rule.mu.Lock()
+		defer rule.mu.Unlock()
+		s := bufio.NewScanner(bytes.NewReader(stdout))
+		for s.Scan() {
+			m := reScriptLinterError.FindStringSubmatch(strings.TrimSuffix(s.Text(), "\r"))
+			if m == nil {
+				continue // Skip lines which are not errors such as a summary or a source snippet
+			}
+			line, _ := strconv.Atoi(m[1])
+			col, where := 1, m[1]
+			if m[2] != "" {
+				col, _ = strconv.Atoi(m[2])
+				where += ":" + m[2]
+			}
+			rule.Errorf(loc.at(line, col), "%s reported issue in this script: %s: %s", linter.name, where, m[3])
+		}
+		return nil
+	})
+}
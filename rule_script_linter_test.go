@@ -0,0 +1,124 @@
+package actionlint
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRuleScriptLinterRunLinters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter script does not work on Windows")
+	}
+
+	exe := filepath.Join(t.TempDir(), "fake-lint")
+	script := "#!/bin/sh\ncat > /dev/null\necho 'checking...'\necho 'stdin:2:6: W: unused variable'\necho '-:1: warning: ambiguous first argument' >&2\n"
+	if err := os.WriteFile(exe, []byte(script), 0755); err != nil {
+		panic(err)
+	}
+
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{
+		ScriptLinters: map[string][]string{
+			"ruby": {exe, "--stdin"},
+		},
+	}
+
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hello
+      - shell: ruby {0}
+        run: |
+          puts 1
+          puts x
+  test-default:
+    runs-on: ubuntu-latest
+    defaults:
+      run:
+        shell: /usr/bin/ruby {0}
+    steps:
+      - run: "puts 1\nputs y"
+`
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"test.yaml:9:11: fake-lint reported issue in this script: 1: warning: ambiguous first argument [script-linter]",
+		"test.yaml:10:16: fake-lint reported issue in this script: 2:6: W: unused variable [script-linter]",
+		"test.yaml:17:15: fake-lint reported issue in this script: 1: warning: ambiguous first argument [script-linter]",
+		"test.yaml:17:28: fake-lint reported issue in this script: 2:6: W: unused variable [script-linter]",
+	}
+	have := make([]string, 0, len(errs))
+	for _, e := range errs {
+		have = append(have, e.Error())
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("wanted errors:\n%s\nbut got:\n%s", strings.Join(want, "\n"), strings.Join(have, "\n"))
+	}
+}
+
+func TestRuleScriptLinterCommandNotFound(t *testing.T) {
+	_, err := NewRuleScriptLinter(map[string][]string{"ruby": {"this-command-does-not-exist"}}, newConcurrentProcess(1))
+	if err == nil || !strings.Contains(err.Error(), `command for shell "ruby" at "script-linters" was not found`) {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestRuleScriptLinterCommandsInProjectConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linter script does not work on Windows")
+	}
+
+	dir := t.TempDir()
+	ran := filepath.Join(dir, "ran.txt")
+	exe := filepath.Join(dir, "fake-lint")
+	script := "#!/bin/sh\ncat > /dev/null\ntouch " + ran + "\necho 'stdin:1:1: W: issue'\n"
+	if err := os.WriteFile(exe, []byte(script), 0755); err != nil {
+		panic(err)
+	}
+	cfg := &Config{
+		ScriptLinters: map[string][]string{"ruby": {exe}},
+		Rules:         RulesConfig{Shellcheck: ShellcheckRuleConfig{Flags: []string{"-S", "warning"}}},
+	}
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - shell: ruby {0}\n        run: puts 1\n"
+
+	for _, allow := range []bool{false, true} {
+		var log strings.Builder
+		l, err := NewLinter(io.Discard, &LinterOptions{AllowConfigCommands: allow, LogWriter: &log, Verbose: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, err := l.Lint("test.yaml", []byte(src), &Project{dir, cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, statErr := os.Stat(ran)
+		if allow {
+			if len(errs) != 1 || errs[0].Kind != "script-linter" || statErr != nil {
+				t.Fatalf("linter in config should run with AllowConfigCommands: %v", errs)
+			}
+			continue
+		}
+		if len(errs) != 0 || statErr == nil {
+			t.Fatalf("linter in config should not run without AllowConfigCommands: %v", errs)
+		}
+		if !strings.Contains(log.String(), "are ignored. use -allow-config-commands to run them") {
+			t.Fatalf("ignored commands were not logged: %q", log.String())
+		}
+	}
+
+	if c := cfg.withoutCommands(); c.hasCommands() || !cfg.hasCommands() {
+		t.Fatal("commands were not removed from the copy of config:", c)
+	}
+}
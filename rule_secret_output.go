@@ -0,0 +1,144 @@
+package actionlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	reSecretOutputSecrets = regexp.MustCompile(`\$\{\{[^}]*\bsecrets\s*[.\[][^}]*\}\}`)
+	reSecretOutputAssign  = regexp.MustCompile(`^(?:export\s+|local\s+|declare\s+(?:-\w+\s+)?)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	reSecretOutputWrite   = regexp.MustCompile(`\bGITHUB_OUTPUT\b|::set-output\s`)
+	reSecretOutputVar     = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)|\benv\.([A-Za-z_][A-Za-z0-9_-]*)`)
+)
+
+// RuleSecretOutput is a rule to check secrets leaking through outputs of jobs and steps. Job outputs
+// containing secrets are redacted and not passed to downstream jobs. Values derived from secrets
+// and written to step outputs in "run:" scripts are visible to other steps and jobs, and they are no
+// longer masked once they are transformed. Job outputs are checked only when "job-outputs" is enabled
+// in the configuration of the rule.
+type RuleSecretOutput struct {
+	RuleBase
+	// Names of environment variables at workflow and job levels whose values are derived from secrets
+	workflowEnv map[string]struct{}
+	jobEnv      map[string]struct{}
+}
+
+// NewRuleSecretOutput creates a new RuleSecretOutput instance.
+func NewRuleSecretOutput() *RuleSecretOutput {
+	return &RuleSecretOutput{
+		RuleBase: RuleBase{
+			name: "secret-output",
+			desc: "Checks for secrets which leak through outputs of jobs and steps",
+		},
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleSecretOutput) VisitWorkflowPre(n *Workflow) error {
+	rule.workflowEnv = secretEnvVars(n.Env, nil)
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleSecretOutput) VisitJobPre(n *Job) error {
+	rule.jobEnv = secretEnvVars(n.Env, rule.workflowEnv)
+
+	if rule.config == nil || !rule.config.Rules.SecretOutput.JobOutputs {
+		return nil
+	}
+	for _, o := range n.Outputs {
+		if o.Value != nil && reSecretOutputSecrets.MatchString(o.Value.Value) {
+			rule.Errorf(
+				o.Value.Pos,
+				"output %q of job %q contains secrets. job outputs containing secrets are redacted and not passed to downstream jobs. use the secret in the downstream jobs directly via secrets context",
+				o.Name.Value,
+				n.ID.Value,
+			)
+		}
+	}
+
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleSecretOutput) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*ExecRun)
+	if !ok || e.Run == nil {
+		return nil
+	}
+
+	tainted := secretEnvVars(n.Env, rule.jobEnv)
+
+	src := strings.ReplaceAll(e.Run.Value, "\\\n", " ") // Join continued lines
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Shell variables assigned from secrets are also derived from secrets like `X=$(echo "$TOKEN" | base64)`
+		if m := reSecretOutputAssign.FindStringSubmatch(line); m != nil {
+			if _, ok := secretReference(m[2], tainted); ok {
+				tainted[m[1]] = struct{}{}
+			}
+			continue
+		}
+
+		if !reSecretOutputWrite.MatchString(line) {
+			continue
+		}
+		if what, ok := secretReference(line, tainted); ok {
+			rule.Errorf(
+				e.Run.Pos,
+				"%s is written to step outputs at line %q. step outputs are visible to other steps and jobs and values transformed from secrets are not masked. pass the secret via \"env:\" to each step which needs it instead",
+				what,
+				line,
+			)
+		}
+	}
+
+	return nil
+}
+
+// secretReference returns the description of the reference to secrets in the string. The tainted
+// parameter is a set of names of variables derived from secrets.
+func secretReference(s string, tainted map[string]struct{}) (string, bool) {
+	if m := reSecretOutputSecrets.FindString(s); m != "" {
+		return fmt.Sprintf("secret %q", m), true
+	}
+	for _, m := range reSecretOutputVar.FindAllStringSubmatch(s, -1) {
+		v := m[1]
+		if v == "" {
+			v = m[2]
+		}
+		if _, ok := tainted[v]; ok {
+			return fmt.Sprintf("variable %q derived from secrets", v), true
+		}
+	}
+	return "", false
+}
+
+// secretEnvVars returns a set of names of environment variables which are derived from secrets. The
+// parent parameter is a set of the names at the outer scope.
+func secretEnvVars(env *Env, parent map[string]struct{}) map[string]struct{} {
+	ret := make(map[string]struct{}, len(parent))
+	for k := range parent {
+		ret[k] = struct{}{}
+	}
+	if env == nil {
+		return ret
+	}
+	for _, v := range env.Vars {
+		if v.Value == nil {
+			continue
+		}
+		if _, ok := secretReference(v.Value.Value, parent); ok {
+			ret[v.Name.Value] = struct{}{}
+		} else {
+			delete(ret, v.Name.Value) // Overridden by the inner scope
+		}
+	}
+	return ret
+}
@@ -0,0 +1,85 @@
+package actionlint
+
+import (
+	"testing"
+)
+
+func TestRuleSecretOutputRunScript(t *testing.T) {
+	testCases := []struct {
+		what   string
+		run    string
+		jobEnv map[string]string
+		env    map[string]string
+		want   int
+	}{
+		{
+			what: "no output",
+			run:  "echo \"$TOKEN\"",
+			env:  map[string]string{"TOKEN": "${{ secrets.TOKEN }}"},
+		},
+		{
+			what: "output not derived from secrets",
+			run:  "echo \"foo=$FOO\" >> \"$GITHUB_OUTPUT\"",
+			env:  map[string]string{"FOO": "${{ github.sha }}", "TOKEN": "${{ secrets.TOKEN }}"},
+		},
+		{
+			what: "secret written to output",
+			run:  "echo \"token=${{ secrets.TOKEN }}\" >> $GITHUB_OUTPUT",
+			want: 1,
+		},
+		{
+			what: "environment variable written to output",
+			run:  "echo \"token=${TOKEN}\" >> $GITHUB_OUTPUT",
+			env:  map[string]string{"TOKEN": "${{ secrets.TOKEN }}"},
+			want: 1,
+		},
+		{
+			what: "shell variables propagate secrets",
+			run:  "export A=\"$TOKEN\"\nB=$(echo \"$A\" | base64)\necho \"b=$B\" >> $GITHUB_OUTPUT",
+			env:  map[string]string{"TOKEN": "${{ secrets['TOKEN'] }}"},
+			want: 1,
+		},
+		{
+			what:   "environment variables propagate secrets",
+			run:    "echo \"b=$B\" >> $GITHUB_OUTPUT\necho \"c=$C\" >> $GITHUB_OUTPUT",
+			jobEnv: map[string]string{"A": "${{ secrets.TOKEN }}"},
+			env:    map[string]string{"B": "${{ env.A }}", "C": "${{ github.sha }}"},
+			want:   1,
+		},
+		{
+			what: "multiple lines",
+			run:  "echo \"a=${{ secrets.A }}\" >> $GITHUB_OUTPUT\necho \"b=${{ secrets.B }}\" >> $GITHUB_OUTPUT",
+			want: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			env := func(vars map[string]string) *Env {
+				e := &Env{Vars: map[string]*EnvVar{}}
+				for k, v := range vars {
+					e.Vars[k] = &EnvVar{Name: &String{Value: k}, Value: &String{Value: v}}
+				}
+				return e
+			}
+			s := &Step{
+				Exec: &ExecRun{Run: &String{Value: tc.run, Pos: &Pos{}}},
+				Env:  env(tc.env),
+			}
+
+			r := NewRuleSecretOutput()
+			if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.VisitJobPre(&Job{ID: &String{Value: "test"}, Env: env(tc.jobEnv)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.VisitStep(s); err != nil {
+				t.Fatal(err)
+			}
+			if errs := r.Errs(); len(errs) != tc.want {
+				t.Fatalf("wanted %d errors but got %d errors: %v", tc.want, len(errs), errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package actionlint
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var reServicesLocalhostPort = regexp.MustCompile(`\b(?:localhost|127\.0\.0\.1):([0-9]+)\b`)
+
+// RuleServices is a rule to check "services:" configuration of jobs. It checks health check options
+// at "options:" and host ports mapped by multiple services. Ports of localhost used in "run:" scripts
+// which are not exposed by any service are also checked when it is enabled in the configuration.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#jobsjob_idservices
+type RuleServices struct {
+	RuleBase
+}
+
+// NewRuleServices creates new RuleServices instance.
+func NewRuleServices() *RuleServices {
+	return &RuleServices{
+		RuleBase: RuleBase{
+			name: "services",
+			desc: "Checks for health check options, port collisions, and ports used via localhost in \"services:\" configuration",
+		},
+	}
+}
+
+// servicePort is a port of host mapped to a port of service container.
+type servicePort struct {
+	// host is a port number of the host. This is empty when the port of host is randomly assigned.
+	host string
+	// proto is a protocol of the port like "tcp" or "udp".
+	proto string
+	pos   *Pos
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleServices) VisitJobPre(n *Job) error {
+	if n.Services == nil || len(n.Services.Value) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(n.Services.Value))
+	for id := range n.Services.Value {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// Map from host port like "5432/tcp" to the service which maps the port
+	mapped := map[string]string{}
+	known := true // false when some ports cannot be known statically
+	for _, id := range ids {
+		s := n.Services.Value[id]
+		c := s.Container
+		if c == nil {
+			continue
+		}
+		if c.Options != nil && !c.Options.ContainsExpression() {
+			rule.checkHealthOptions(s.Name.Value, c.Options)
+		}
+		for _, p := range c.Ports {
+			sp, ok := parseServicePort(p)
+			if !ok {
+				known = false
+				continue
+			}
+			if sp.host == "" {
+				continue
+			}
+			k := sp.host + "/" + sp.proto
+			if other, ok := mapped[k]; ok {
+				rule.Errorf(
+					sp.pos,
+					"host port %s of %q service is already mapped by %q service. services in the same job cannot share the same port of host",
+					k,
+					s.Name.Value,
+					other,
+				)
+				continue
+			}
+			mapped[k] = s.Name.Value
+		}
+	}
+
+	// When the job runs in a container, services are accessed via their names instead of localhost
+	if rule.config == nil || !rule.config.Rules.Services.LocalhostPorts || n.Container != nil || !known {
+		return nil
+	}
+	for _, s := range n.Steps {
+		e, ok := s.Exec.(*ExecRun)
+		if !ok || e.Run == nil {
+			continue
+		}
+		reported := map[string]struct{}{}
+		for _, m := range reServicesLocalhostPort.FindAllStringSubmatch(e.Run.Value, -1) {
+			p := m[1]
+			if _, ok := mapped[p+"/tcp"]; ok {
+				continue
+			}
+			if _, ok := reported[p]; ok {
+				continue
+			}
+			reported[p] = struct{}{}
+			rule.Errorf(
+				e.Run.Pos,
+				"%q is used in \"run:\" script but port %s is not exposed to host by any service. map the port with \"ports:\" of the service like \"%s:%s\"",
+				m[0],
+				p,
+				p,
+				p,
+			)
+		}
+	}
+
+	return nil
+}
+
+// https://docs.docker.com/engine/reference/run/#healthcheck
+func (rule *RuleServices) checkHealthOptions(name string, opts *String) {
+	args, ok := splitContainerOptions(opts.Value)
+	if !ok {
+		rule.Errorf(opts.Pos, "quotes are not closed in \"options:\" of %q service: %q", name, opts.Value)
+		return
+	}
+
+	health, disabled := false, false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "--health-") && a != "--no-healthcheck" {
+			continue
+		}
+		if a == "--no-healthcheck" {
+			disabled = true
+			continue
+		}
+
+		opt, val, hasVal := strings.Cut(a, "=")
+		if !hasVal {
+			if i+1 >= len(args) {
+				rule.Errorf(opts.Pos, "value of %q option is missing in \"options:\" of %q service", opt, name)
+				continue
+			}
+			i++
+			val = args[i]
+		}
+		health = true
+
+		switch opt {
+		case "--health-cmd":
+			if strings.TrimSpace(val) == "" {
+				rule.Errorf(opts.Pos, "command of \"--health-cmd\" option should not be empty in \"options:\" of %q service", name)
+			}
+		case "--health-interval", "--health-timeout", "--health-start-period", "--health-start-interval":
+			if d, err := time.ParseDuration(val); err != nil || d < 0 {
+				rule.Errorf(
+					opts.Pos,
+					"value %q of %q option is not a valid duration in \"options:\" of %q service. it should be a duration like \"10s\" or \"1m30s\"",
+					val,
+					opt,
+					name,
+				)
+			}
+		case "--health-retries":
+			if r, err := strconv.Atoi(val); err != nil || r < 0 {
+				rule.Errorf(
+					opts.Pos,
+					"value %q of \"--health-retries\" option is not a valid number of retries in \"options:\" of %q service. it should be a non-negative integer",
+					val,
+					name,
+				)
+			}
+		default:
+			rule.Errorf(
+				opts.Pos,
+				"unknown health check option %q in \"options:\" of %q service. available options are \"--health-cmd\", \"--health-interval\", \"--health-retries\", \"--health-start-interval\", \"--health-start-period\", \"--health-timeout\"",
+				opt,
+				name,
+			)
+		}
+	}
+
+	if health && disabled {
+		rule.Errorf(opts.Pos, "\"--no-healthcheck\" option conflicts with other health check options in \"options:\" of %q service", name)
+	}
+}
+
+// parseServicePort parses the port mapping like "8080:80/tcp". The second return value is false
+// when the port cannot be parsed statically.
+// https://docs.docker.com/engine/reference/commandline/run/#publish
+func parseServicePort(p *String) (*servicePort, bool) {
+	if p == nil || p.ContainsExpression() {
+		return nil, false
+	}
+	s, proto, ok := strings.Cut(strings.TrimSpace(p.Value), "/")
+	if !ok {
+		proto = "tcp"
+	}
+	ss := strings.Split(s, ":")
+	host := ""
+	if len(ss) >= 2 {
+		host = ss[len(ss)-2]
+	}
+	for _, n := range []string{host, ss[len(ss)-1]} {
+		if n == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(n, 10, 16); err != nil {
+			return nil, false // Port range like "8000-8010" or invalid port
+		}
+	}
+	return &servicePort{host, strings.ToLower(proto), p.Pos}, true
+}
+
+// splitContainerOptions splits options string of container into arguments as shell does. The second
+// return value is false when some quote is not closed.
+func splitContainerOptions(s string) ([]string, bool) {
+	args := []string{}
+	var b strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, false
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, true
+}
@@ -0,0 +1,105 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleServicesSplitContainerOptions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+		ok    bool
+	}{
+		{"", []string{}, true},
+		{"--health-cmd pg_isready", []string{"--health-cmd", "pg_isready"}, true},
+		{"  --health-retries=5\n--health-timeout 5s ", []string{"--health-retries=5", "--health-timeout", "5s"}, true},
+		{`--health-cmd "redis-cli ping" --name 'my service'`, []string{"--health-cmd", "redis-cli ping", "--name", "my service"}, true},
+		{`--health-cmd "echo \"hi\"" --a 'b\c'`, []string{"--health-cmd", `echo "hi"`, "--a", `b\c`}, true},
+		{`--health-cmd ""`, []string{"--health-cmd", ""}, true},
+		{`--health-cmd foo\ bar`, []string{"--health-cmd", "foo bar"}, true},
+		{`--health-cmd "foo`, nil, false},
+		{`--health-cmd 'foo`, nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			have, ok := splitContainerOptions(tc.input)
+			if ok != tc.ok {
+				t.Fatalf("wanted ok=%v but got ok=%v", tc.ok, ok)
+			}
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestRuleServicesParsePort(t *testing.T) {
+	tests := []struct {
+		input string
+		host  string
+		proto string
+		ok    bool
+	}{
+		{"5432", "", "tcp", true},
+		{"5432:5432", "5432", "tcp", true},
+		{"8080:80/udp", "8080", "udp", true},
+		{"127.0.0.1:8080:80", "8080", "tcp", true},
+		{"127.0.0.1::80", "", "tcp", true},
+		{"8000-8010:8000-8010", "", "", false},
+		{"foo:80", "", "", false},
+		{"99999:80", "", "", false},
+		{"${{ matrix.port }}:80", "", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			p, ok := parseServicePort(&String{Value: tc.input, Pos: &Pos{}})
+			if ok != tc.ok {
+				t.Fatalf("wanted ok=%v but got ok=%v", tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if p.host != tc.host || p.proto != tc.proto {
+				t.Fatalf("wanted host=%q proto=%q but got host=%q proto=%q", tc.host, tc.proto, p.host, p.proto)
+			}
+		})
+	}
+}
+
+func TestRuleServicesLocalhostPorts(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    services:
+      postgres:
+        image: postgres:16
+        ports:
+          - 5432:5432
+    steps:
+      - run: psql -h localhost -p 5432
+      - run: |
+          npm start &
+          npx wait-on http://localhost:3000
+`
+	for _, enabled := range []bool{true, false} {
+		cfg := &Config{}
+		cfg.Rules.Services.LocalhostPorts = enabled
+		errs := testCheckRule(t, NewRuleServices(), cfg, src)
+		if !enabled {
+			if len(errs) > 0 {
+				t.Fatal("wanted no error when the option is disabled but got", errs)
+			}
+			continue
+		}
+		want := `"localhost:3000" is used in "run:" script but port 3000 is not exposed to host by any service`
+		if len(errs) != 1 || !strings.HasPrefix(errs[0].Message, want) {
+			t.Fatalf("wanted one error starting with %q but got %v", want, errs)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// setupCacheAction is a setup action which has built-in caching of dependencies.
+type setupCacheAction struct {
+	// lockfiles is the list of lockfiles at the repository root with the values of "cache" input to
+	// cache the dependencies. They are ordered by the priority.
+	lockfiles []setupCacheLockfile
+	// cacheByDefault is the first major version where caching is enabled by default. 0 means caching
+	// is never enabled by default.
+	cacheByDefault int
+}
+
+type setupCacheLockfile struct {
+	name  string // File name like "package-lock.json"
+	cache string // Value of "cache" input like "npm"
+}
+
+// setupCacheActions is the table of setup actions which have built-in caching. Keys are
+// "{owner}/{repo}" in lower case.
+var setupCacheActions = map[string]*setupCacheAction{
+	"actions/setup-node": {
+		lockfiles: []setupCacheLockfile{
+			{"package-lock.json", "npm"},
+			{"npm-shrinkwrap.json", "npm"},
+			{"yarn.lock", "yarn"},
+			{"pnpm-lock.yaml", "pnpm"},
+		},
+	},
+	"actions/setup-python": {
+		lockfiles: []setupCacheLockfile{
+			{"requirements.txt", "pip"},
+			{"Pipfile.lock", "pipenv"},
+			{"poetry.lock", "poetry"},
+		},
+	},
+	"actions/setup-go": {
+		lockfiles: []setupCacheLockfile{
+			{"go.sum", "true"},
+		},
+		cacheByDefault: 4,
+	},
+}
+
+var reSetupCacheMajorVersion = regexp.MustCompile(`^v(\d+)(?:\.|$)`)
+
+// RuleSetupCache is a rule to check setup actions like actions/setup-node cache dependencies when a
+// lockfile exists in the repository, and to check files at "cache-dependency-path" input exist in
+// the repository. This rule checks files in the project so it does nothing when the project is
+// unknown. The rule can be disabled by the "setup-cache" configuration.
+type RuleSetupCache struct {
+	RuleBase
+	project *Project
+}
+
+// NewRuleSetupCache creates a new RuleSetupCache instance. The project parameter is the project
+// which the checked workflows belong to.
+func NewRuleSetupCache(project *Project) *RuleSetupCache {
+	return &RuleSetupCache{
+		RuleBase: RuleBase{
+			name: "setup-cache",
+			desc: "Checks setup actions cache dependencies and \"cache-dependency-path\" input refers existing files",
+		},
+		project: project,
+	}
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleSetupCache) VisitProject(files []*ProjectFile) error {
+	if rule.project == nil {
+		return nil
+	}
+	if cfg := rule.Config(); cfg != nil && cfg.Rules.SetupCache.Disable {
+		return nil
+	}
+
+	for _, f := range files {
+		for _, j := range f.Workflow.Jobs {
+			rule.checkJob(f.Path, j)
+		}
+	}
+	return nil
+}
+
+func (rule *RuleSetupCache) checkJob(path string, j *Job) {
+	cached := false
+	for _, s := range j.Steps {
+		e, ok := s.Exec.(*ExecAction)
+		if !ok || e.Spec == nil || e.Spec.Kind != ActionSpecKindRepository {
+			continue
+		}
+		switch strings.ToLower(e.Spec.Owner + "/" + e.Spec.Repo) {
+		case "actions/checkout":
+			// Paths in the repository are not relative to the workspace when it is checked out into
+			// some directory or when another repository is checked out
+			if _, ok := e.Inputs["path"]; ok {
+				return
+			}
+			if _, ok := e.Inputs["repository"]; ok {
+				return
+			}
+		case "actions/cache":
+			cached = true
+		}
+	}
+
+	for _, s := range j.Steps {
+		e, ok := s.Exec.(*ExecAction)
+		if !ok || e.Spec == nil || e.Spec.Kind != ActionSpecKindRepository {
+			continue
+		}
+		a, ok := setupCacheActions[strings.ToLower(e.Spec.Owner+"/"+e.Spec.Repo)]
+		if !ok || e.Spec.Path != "" {
+			continue
+		}
+		if i, ok := e.Inputs["cache-dependency-path"]; ok {
+			rule.checkDependencyPath(path, e, i)
+		}
+		if !cached {
+			rule.checkCacheEnabled(path, e, a)
+		}
+	}
+}
+
+func (rule *RuleSetupCache) checkCacheEnabled(path string, e *ExecAction, a *setupCacheAction) {
+	if i, ok := e.Inputs["cache"]; ok {
+		if i.Value == nil || i.Value.ContainsExpression() {
+			return
+		}
+		// "cache" input is a boolean for actions which cache by default. Otherwise it is a package manager
+		if v := i.Value.Value; a.cacheByDefault > 0 && v != "false" || a.cacheByDefault == 0 && v != "" {
+			return
+		}
+	} else if a.cacheByDefault > 0 {
+		// The version is unknown when the ref is a commit SHA or a branch. Assume it is recent
+		m := reSetupCacheMajorVersion.FindStringSubmatch(e.Spec.Ref)
+		if m == nil {
+			return
+		}
+		if v, err := strconv.Atoi(m[1]); err != nil || v >= a.cacheByDefault {
+			return
+		}
+	}
+
+	for _, l := range a.lockfiles {
+		if _, err := os.Stat(filepath.Join(rule.project.RootDir(), l.name)); err != nil {
+			continue
+		}
+		rule.FileErrorf(
+			path,
+			e.Uses.Pos,
+			"%q does not cache dependencies though lockfile %q exists in the repository. enable the built-in cache with \"cache: %s\" input or add \"actions/cache\" step to make the job faster",
+			e.Uses.Value,
+			l.name,
+			l.cache,
+		)
+		return
+	}
+}
+
+func (rule *RuleSetupCache) checkDependencyPath(path string, e *ExecAction, i *Input) {
+	if i.Value == nil || i.Value.ContainsExpression() {
+		return
+	}
+	root := rule.project.RootDir()
+	for _, l := range strings.Split(i.Value.Value, "\n") {
+		p := strings.TrimSpace(l)
+		if p == "" || strings.HasPrefix(p, "!") || strings.Contains(p, "**") {
+			continue
+		}
+		p = strings.TrimPrefix(p, "./")
+		if filepath.IsAbs(p) || strings.HasPrefix(p, "..") {
+			continue // Paths outside the repository are unknown
+		}
+		if m, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(p))); err != nil || len(m) > 0 {
+			continue
+		}
+		rule.FileErrorf(
+			path,
+			i.Value.Pos,
+			"file %q at \"cache-dependency-path\" input of %q does not exist in the repository. the cache cannot be keyed by the lockfile",
+			p,
+			e.Uses.Value,
+		)
+	}
+}
@@ -0,0 +1,58 @@
+package actionlint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// testdata/setup_cache is a project root which has a lock file of npm
+var testRuleSetupCacheProject = &Project{root: filepath.Join("testdata", "setup_cache")}
+
+func TestRuleSetupCacheDisable(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+`
+	errs := testCheckRule(t, NewRuleSetupCache(testRuleSetupCacheProject), nil, src)
+	if len(errs) != 1 {
+		t.Fatal("one error was expected but got", errs)
+	}
+	if errs[0].Filepath != "a.yaml" || errs[0].Line != 7 || errs[0].Column != 15 {
+		t.Fatal("unexpected error", errs[0])
+	}
+
+	cfg, err := ParseConfig([]byte("rules:\n  setup-cache:\n    disable: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := testCheckRule(t, NewRuleSetupCache(testRuleSetupCacheProject), cfg, src); len(errs) != 0 {
+		t.Fatal("no error was expected when the rule is disabled but got", errs)
+	}
+}
+
+func TestRuleSetupCacheSkipCheckoutToOtherDirectory(t *testing.T) {
+	for _, input := range []string{"path: app", "repository: owner/app"} {
+		t.Run(input, func(t *testing.T) {
+			src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ` + input + `
+      - uses: actions/setup-node@v4
+        with:
+          cache: npm
+          cache-dependency-path: yarn.lock
+`
+			if errs := testCheckRule(t, NewRuleSetupCache(testRuleSetupCacheProject), nil, src); len(errs) != 0 {
+				t.Fatal("no error was expected but got", errs)
+			}
+		})
+	}
+}
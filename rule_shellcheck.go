@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -23,11 +24,15 @@ type RuleShellcheck struct {
 	workflowShell string
 	jobShell      string
 	runnerShell   string
+	paths         []PathConfig
+	lines         sourceLines
+	flags         []string
+	exclude       string
 	mu            sync.Mutex
 }
 
 func newRuleShellcheck(cmd *externalCommand) *RuleShellcheck {
-	return &RuleShellcheck{
+	r := &RuleShellcheck{
 		RuleBase: RuleBase{
 			name: "shellcheck",
 			desc: "Checks for shell script sources in \"run:\" using shellcheck",
@@ -37,6 +42,8 @@ func newRuleShellcheck(cmd *externalCommand) *RuleShellcheck {
 		jobShell:      "",
 		runnerShell:   "",
 	}
+	r.flags, r.exclude = r.extraArgs()
+	return r
 }
 
 // NewRuleShellcheck creates new RuleShellcheck instance. The executable argument can be command
@@ -57,7 +64,7 @@ func (rule *RuleShellcheck) VisitStep(n *Step) error {
 		return nil
 	}
 
-	rule.runShellcheck(run.Run.Value, rule.getShellName(run), run.RunPos)
+	rule.runShellcheck(run.Run, rule.getShellName(run), run.RunPos)
 	return nil
 }
 
@@ -91,6 +98,7 @@ func (rule *RuleShellcheck) VisitJobPost(n *Job) error {
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleShellcheck) VisitWorkflowPre(n *Workflow) error {
+	rule.flags, rule.exclude = rule.extraArgs()
 	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
 		rule.workflowShell = n.Defaults.Run.Shell.Value
 	}
@@ -103,6 +111,41 @@ func (rule *RuleShellcheck) VisitWorkflowPost(n *Workflow) error {
 	return rule.cmd.wait() // Wait until all processes running for this rule
 }
 
+// extraArgs returns the extra flags and the comma-separated list of excluded rules configured in
+// the "shellcheck" rule config and the configs of the paths matching to the checked file.
+func (rule *RuleShellcheck) extraArgs() ([]string, string) {
+	// Reasons to exclude the rules:
+	//
+	// - SC1091: File not found. Scripts are for CI environment. Not suitable for checking this in current local
+	//           environment
+	// - SC2194: The word is constant. This sometimes happens at constants by replacing ${{ }} with underscores.
+	//           For example, `if ${{ matrix.foo }}; then ...` -> `if _________________; then ...`
+	// - SC2050: The expression is constant. This sometimes happens at `if` condition by replacing ${{ }} with
+	//           underscores (#45). For example, `if [ "${{ matrix.foo }}" = "x" ]` -> `if [ "_________________" = "x" ]`
+	// - SC2154: The var is referenced but not assigned. Script at `run:` can refer variables defined in `env:` section
+	//           so this rule can cause false positives (#53).
+	// - SC2157: Argument to -z is always false due to literal strings. When the argument of -z is replaced from ${{ }},
+	//           this can happen. For example, `if [ -z ${{ env.FOO }} ]` -> `if [ -z ______________ ]` (#113).
+	// - SC2043: Loop can be detected as only running once when the target of iteration is a placeholder. (#355)
+	//           e.g. `for foo in ${{ inputs.foo }}; do`
+	exclude := []string{"SC1091", "SC2194", "SC2050", "SC2154", "SC2157", "SC2043"}
+
+	cfgs := make([]*ShellcheckRuleConfig, 0, len(rule.paths)+1)
+	if c := rule.Config(); c != nil {
+		cfgs = append(cfgs, &c.Rules.Shellcheck)
+	}
+	for i := range rule.paths {
+		cfgs = append(cfgs, &rule.paths[i].Shellcheck)
+	}
+
+	var flags []string
+	for _, c := range cfgs {
+		flags = append(flags, c.Flags...)
+		exclude = append(exclude, c.Exclude...)
+	}
+	return flags, strings.Join(exclude, ",")
+}
+
 func (rule *RuleShellcheck) getShellName(exec *ExecRun) string {
 	if exec.Shell != nil {
 		return exec.Shell.Value
@@ -160,7 +203,60 @@ func sanitizeExpressionsInScript(src string) string {
 	}
 }
 
-func (rule *RuleShellcheck) runShellcheck(src, shell string, pos *Pos) {
+// insertShellcheckSetup inserts the setup line to the script and returns the script and the line
+// number of the setup line. The setup line is inserted after the leading comments since shellcheck
+// applies directives like "# shellcheck disable=SC2086" before the first command to the entire
+// script.
+func insertShellcheckSetup(src, setup string) (string, int) {
+	line := 1
+	rest := src
+	for rest != "" {
+		l := rest
+		i := strings.IndexByte(rest, '\n')
+		if i >= 0 {
+			l = rest[:i]
+		}
+		if t := strings.TrimSpace(l); t != "" && !strings.HasPrefix(t, "#") {
+			break
+		}
+		line++
+		if i < 0 {
+			rest = ""
+			break
+		}
+		rest = rest[i+1:]
+	}
+
+	head := src[:len(src)-len(rest)]
+	if head != "" && !strings.HasSuffix(head, "\n") {
+		head += "\n"
+	}
+	return fmt.Sprintf("%s%s\n%s\n", head, setup, rest), line
+}
+
+// shellcheckOffset returns the byte offset in the script of the 1-based line and column reported by
+// shellcheck. shellcheck counts a tab as the width to the next tab stop of every 8 columns. It
+// returns -1 when the line is out of the script.
+func shellcheckOffset(src string, line, col int) int {
+	offset := scriptLineOffset(src, line)
+	if offset < 0 {
+		return -1
+	}
+	w := 1
+	for i, r := range src[offset:] {
+		if w >= col || r == '\n' {
+			return offset + i
+		}
+		if r == '\t' {
+			w += 8 - (w-1)%8
+		} else {
+			w++
+		}
+	}
+	return len(src)
+}
+
+func (rule *RuleShellcheck) runShellcheck(str *String, shell string, pos *Pos) {
 	var sh string
 	if shell == "bash" || shell == "sh" {
 		sh = shell
@@ -172,24 +268,12 @@ func (rule *RuleShellcheck) runShellcheck(src, shell string, pos *Pos) {
 		return // Skip checking this shell script since shellcheck doesn't support it
 	}
 
-	src = sanitizeExpressionsInScript(src)
+	src := sanitizeExpressionsInScript(str.Value)
 	rule.Debug("%s: Run shellcheck for %s script:\n%s", pos, sh, src)
 
-	// Reasons to exclude the rules:
-	//
-	// - SC1091: File not found. Scripts are for CI environment. Not suitable for checking this in current local
-	//           environment
-	// - SC2194: The word is constant. This sometimes happens at constants by replacing ${{ }} with underscores.
-	//           For example, `if ${{ matrix.foo }}; then ...` -> `if _________________; then ...`
-	// - SC2050: The expression is constant. This sometimes happens at `if` condition by replacing ${{ }} with
-	//           underscores (#45). For example, `if [ "${{ matrix.foo }}" = "x" ]` -> `if [ "_________________" = "x" ]`
-	// - SC2154: The var is referenced but not assigned. Script at `run:` can refer variables defined in `env:` section
-	//           so this rule can cause false positives (#53).
-	// - SC2157: Argument to -z is always false due to literal strings. When the argument of -z is replaced from ${{ }},
-	//           this can happen. For example, `if [ -z ${{ env.FOO }} ]` -> `if [ -z ______________ ]` (#113).
-	// - SC2043: Loop can be detected as only running once when the target of iteration is a placeholder. (#355)
-	//           e.g. `for foo in ${{ inputs.foo }}; do`
-	args := []string{"--norc", "-f", "json", "-x", "--shell", sh, "-e", "SC1091,SC2194,SC2050,SC2154,SC2157,SC2043", "-"}
+	args := []string{"--norc", "-f", "json", "-x", "--shell", sh, "-e", rule.exclude}
+	args = append(args, rule.flags...)
+	args = append(args, "-")
 	rule.Debug("%s: Running %s command with %s", pos, rule.cmd.exe, args)
 
 	// Use same options to run shell process described at document
@@ -198,9 +282,16 @@ func (rule *RuleShellcheck) runShellcheck(src, shell string, pos *Pos) {
 	if sh == "bash" {
 		setup = "set -eo pipefail"
 	}
-	script := fmt.Sprintf("%s\n%s\n", setup, src)
+	script, setupLine := insertShellcheckSetup(src, setup)
 
 	rule.cmd.run(args, script, func(stdout []byte, err error) error {
+		var timeout *processTimeoutError
+		if errors.As(err, &timeout) {
+			rule.mu.Lock()
+			rule.reportTimeout(pos, timeout)
+			rule.mu.Unlock()
+			return nil
+		}
 		if err != nil {
 			rule.Debug("Command %s %s failed: %v", rule.cmd.exe, args, err)
 			return fmt.Errorf("`%s %s` did not run successfully while checking script at %s: %w", rule.cmd.exe, strings.Join(args, " "), pos, err)
@@ -214,20 +305,27 @@ func (rule *RuleShellcheck) runShellcheck(src, shell string, pos *Pos) {
 			return nil
 		}
 
+		// Errors are reported at the precise positions in the workflow. It is not possible when the
+		// source is not available (e.g. template mode) or the script is in a form which cannot be
+		// decoded. In the case, the position of 'run:' is used instead. The location in the script
+		// reported by shellcheck is also shown in the error message.
+		loc := newScriptLocation(rule.lines, str, src, pos)
+
 		// Synchronize rule.Errorf calls
 		rule.mu.Lock()
 		defer rule.mu.Unlock()
-		// It's better to show source location in the script as position of error, but it's not
-		// possible easily. YAML has multiple block styles with '|', '>', '|+', '>+', '|-', '>-'. Some
-		// of them remove indentation and/or blank lines. So restoring source position in block string
-		// is not possible. Sourcemap is necessary to do it.
-		// Instead, actionlint shows position of 'run:' as position of error. And separately show
-		// location in script which is reported by shellcheck in error message.
 		for _, err := range errs {
-			// Consider the first line is setup for running shell which was implicitly added for better check
-			line := err.Line - 1
+			// Consider the setup line for running shell which was implicitly added for better check
+			line := err.Line
+			if line >= setupLine {
+				line--
+			}
+			p := pos
+			if err.Line != setupLine {
+				p = loc.atOffset(shellcheckOffset(src, line, err.Column))
+			}
 			msg := strings.TrimSuffix(err.Message, ".") // Trim period aligning style of error message
-			rule.Errorf(pos, "shellcheck reported issue in this script: SC%d:%s:%d:%d: %s", err.Code, err.Level, line, err.Column, msg)
+			rule.Errorf(p, "shellcheck reported issue in this script: SC%d:%s:%d:%d: %s", err.Code, err.Level, line, err.Column, msg)
 		}
 
 		return nil
@@ -2,7 +2,10 @@ package actionlint
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestRuleShellcheckSanitizeExpressionsInScript(t *testing.T) {
@@ -197,3 +200,84 @@ func TestRuleShellcheckDetectShell(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleShellcheckInsertSetupAfterDirectives(t *testing.T) {
+	tests := []struct {
+		what  string
+		input string
+		want  string
+		line  int
+	}{
+		{
+			what:  "no comment",
+			input: "echo hi",
+			want:  "set -e\necho hi\n",
+			line:  1,
+		},
+		{
+			what:  "directive at top",
+			input: "# shellcheck disable=SC2086\necho $FOO",
+			want:  "# shellcheck disable=SC2086\nset -e\necho $FOO\n",
+			line:  2,
+		},
+		{
+			what:  "comments and blank lines at top",
+			input: "# Print foo\n\n  # shellcheck disable=SC2086\necho $FOO\n# shellcheck disable=SC2046\necho $(foo)\n",
+			want:  "# Print foo\n\n  # shellcheck disable=SC2086\nset -e\necho $FOO\n# shellcheck disable=SC2046\necho $(foo)\n\n",
+			line:  4,
+		},
+		{
+			what:  "only comments",
+			input: "# shellcheck disable=SC2086",
+			want:  "# shellcheck disable=SC2086\nset -e\n\n",
+			line:  2,
+		},
+		{
+			what:  "empty",
+			input: "",
+			want:  "set -e\n\n",
+			line:  1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			have, line := insertShellcheckSetup(tc.input, "set -e")
+			if have != tc.want {
+				t.Errorf("wanted script %q but got %q", tc.want, have)
+			}
+			if line != tc.line {
+				t.Errorf("wanted setup line %d but got %d", tc.line, line)
+			}
+		})
+	}
+}
+
+func TestRuleShellcheckExtraArgsFromConfig(t *testing.T) {
+	r := newRuleShellcheck(&externalCommand{})
+	r.SetConfig(&Config{
+		Rules: RulesConfig{
+			Shellcheck: ShellcheckRuleConfig{
+				Flags:   []string{"-S", "warning"},
+				Exclude: []string{"SC2086"},
+			},
+		},
+	})
+	r.paths = []PathConfig{
+		{
+			Shellcheck: ShellcheckRuleConfig{
+				Flags:   []string{"-o", "all"},
+				Exclude: []string{"SC2046"},
+			},
+		},
+	}
+	r.VisitWorkflowPre(&Workflow{})
+
+	want := []string{"-S", "warning", "-o", "all"}
+	if !cmp.Equal(r.flags, want) {
+		t.Errorf("wanted flags %q but got %q", want, r.flags)
+	}
+	if !strings.HasSuffix(r.exclude, ",SC2043,SC2086,SC2046") {
+		t.Errorf("configured rules are not excluded: %q", r.exclude)
+	}
+}
@@ -0,0 +1,53 @@
+package actionlint
+
+// RuleStepName is a rule to check naming conventions of step names. This rule is optional and each
+// check is enabled by the "step-name" configuration in the "rules" section of the configuration file.
+type RuleStepName struct {
+	RuleBase
+	names map[string]*Pos
+}
+
+// NewRuleStepName creates a new RuleStepName instance.
+func NewRuleStepName() *RuleStepName {
+	return &RuleStepName{
+		RuleBase: RuleBase{
+			name: "step-name",
+			desc: "Checks for missing step names, duplicate step names in a job, and naming conventions of step names",
+		},
+	}
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleStepName) VisitJobPre(n *Job) error {
+	rule.names = map[string]*Pos{}
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleStepName) VisitStep(n *Step) error {
+	if rule.config == nil {
+		return nil
+	}
+	c := &rule.config.Rules.StepName
+
+	if n.Name == nil {
+		if c.Require {
+			rule.Error(n.Pos, "step should have \"name:\" to make workflow logs readable")
+		}
+		return nil
+	}
+
+	if c.Unique {
+		if prev, ok := rule.names[n.Name.Value]; ok {
+			rule.Errorf(n.Name.Pos, "step name %q is duplicated in the job. previously defined at %s", n.Name.Value, prev)
+		} else {
+			rule.names[n.Name.Value] = n.Name.Pos
+		}
+	}
+
+	if c.Pattern != nil && !c.Pattern.MatchString(n.Name.Value) {
+		rule.Errorf(n.Name.Pos, "step name %q does not match to the pattern /%s/ configured in \"step-name\" rule", n.Name.Value, c.Pattern)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleStepNameChecks(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+      - name: Build
+        run: make
+      - name: build
+        run: make
+      - name: Build
+        run: make
+  other:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Build
+        run: make
+`
+	tests := []struct {
+		what string
+		cfg  string
+		want []string
+	}{
+		{
+			what: "default",
+			cfg:  "",
+		},
+		{
+			what: "require",
+			cfg:  "rules:\n  step-name:\n    require: true",
+			want: []string{
+				`6:9: step should have "name:"`,
+			},
+		},
+		{
+			what: "unique",
+			cfg:  "rules:\n  step-name:\n    unique: true",
+			want: []string{
+				`11:15: step name "Build" is duplicated in the job. previously defined at line:7,col:15`,
+			},
+		},
+		{
+			what: "pattern",
+			cfg:  "rules:\n  step-name:\n    pattern: '^[A-Z]'",
+			want: []string{
+				`9:15: step name "build" does not match to the pattern /^[A-Z]/`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tc.cfg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := testCheckRule(t, NewRuleStepName(), cfg, src)
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+			}
+		})
+	}
+}
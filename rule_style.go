@@ -0,0 +1,161 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// https://yaml.org/type/bool.html
+// Values are the canonical boolean values suggested as replacements.
+var yamlTruthyValues = map[string]string{
+	"y": "true", "Y": "true", "yes": "true", "Yes": "true", "YES": "true",
+	"n": "false", "N": "false", "no": "false", "No": "false", "NO": "false",
+	"True": "true", "TRUE": "true", "False": "false", "FALSE": "false",
+	"on": "true", "On": "true", "ON": "true", "off": "false", "Off": "false", "OFF": "false",
+}
+
+// RuleStyle is a rule to check coding style of workflow files. This rule is optional and each check
+// is enabled by the "style" configuration in the "rules" section of the configuration file. The
+// source is checked directly so that the positions are reported consistently with other rules.
+type RuleStyle struct {
+	RuleBase
+	src *SourceFile
+}
+
+// NewRuleStyle creates a new RuleStyle instance. The src parameter is the source file of the
+// workflow to check. When it is nil, no style check is done.
+func NewRuleStyle(src *SourceFile) *RuleStyle {
+	return &RuleStyle{
+		RuleBase: RuleBase{
+			name: "style",
+			desc: "Checks for coding style of workflow files such as indentation, line length, and truthy values",
+		},
+		src: src,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleStyle) VisitWorkflowPre(n *Workflow) error {
+	if rule.config == nil || rule.src == nil {
+		return nil
+	}
+	c := &rule.config.Rules.Style
+
+	rule.checkLines(c)
+
+	if c.Indentation > 0 || c.Truthy {
+		root, err := rule.src.Node()
+		if err != nil {
+			return nil // Syntax error was already reported by parser
+		}
+		rule.checkNode(c, root, nil)
+	}
+
+	return nil
+}
+
+func (rule *RuleStyle) checkLines(c *StyleRuleConfig) {
+	if c.LineLength <= 0 && !c.TrailingSpaces && c.DocumentStart == "" {
+		return
+	}
+
+	lines := rule.src.Lines()
+	for i, l := range lines {
+		lnum := i + 1
+
+		if c.LineLength > 0 {
+			if w := utf8.RuneCountInString(l); w > c.LineLength {
+				rule.Errorf(&Pos{Line: lnum, Col: c.LineLength + 1}, "line is too long. %d characters exceed the maximum length %d", w, c.LineLength)
+			}
+		}
+
+		if c.TrailingSpaces {
+			t := strings.TrimRight(l, " \t")
+			if len(t) < len(l) {
+				start := &Pos{Line: lnum, Col: utf8.RuneCountInString(t) + 1}
+				rule.Error(start, "trailing spaces at end of line")
+				rule.Suggest(&Suggestion{
+					Message: "remove trailing spaces",
+					Start:   start,
+					End:     &Pos{Line: lnum, Col: utf8.RuneCountInString(l) + 1},
+				})
+			}
+		}
+	}
+
+	switch c.DocumentStart {
+	case "require":
+		for i, l := range lines {
+			s := strings.TrimSpace(l)
+			if s == "" || strings.HasPrefix(s, "#") || strings.HasPrefix(s, "%") {
+				continue
+			}
+			if !strings.HasPrefix(s, "---") {
+				rule.Error(&Pos{Line: i + 1, Col: 1}, "document start marker \"---\" is missing at top of the workflow")
+			}
+			break
+		}
+	case "forbid":
+		for i, l := range lines {
+			if strings.HasPrefix(l, "---") {
+				rule.Error(&Pos{Line: i + 1, Col: 1}, "document start marker \"---\" is not allowed")
+			}
+		}
+	}
+}
+
+func (rule *RuleStyle) checkNode(c *StyleRuleConfig, n *yaml.Node, key *yaml.Node) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		// Check the plain value regardless of its tag since YAML 1.2 parsers also resolve "True" or
+		// "FALSE" as boolean
+		if c.Truthy && n.Style == 0 {
+			if b, ok := yamlTruthyValues[n.Value]; ok {
+				pos := posAt(n)
+				rule.Errorf(
+					pos,
+					"truthy value %q should be \"true\" or \"false\". YAML 1.1 parsers treat it as boolean but YAML 1.2 parsers treat it as string. quote it if it is intended as string",
+					n.Value,
+				)
+				rule.Suggest(&Suggestion{
+					Message:     fmt.Sprintf("replace %q with %q", n.Value, b),
+					Start:       pos,
+					End:         &Pos{Line: pos.Line, Col: pos.Col + utf8.RuneCountInString(n.Value)},
+					Replacement: b,
+				})
+			}
+		}
+	case yaml.MappingNode:
+		if c.Indentation > 0 && key != nil && n.Style&yaml.FlowStyle == 0 && n.Line > key.Line {
+			if want := key.Column + c.Indentation; n.Column != want {
+				rule.Errorf(posAt(n), "indentation of this mapping should be %d but found %d", want-1, n.Column-1)
+			}
+		}
+		for i := 0; i < len(n.Content); i += 2 {
+			rule.checkNode(c, n.Content[i+1], n.Content[i])
+		}
+		return
+	case yaml.SequenceNode:
+		if c.Indentation > 0 && key != nil && n.Style&yaml.FlowStyle == 0 && n.Line > key.Line {
+			// Both indented and non-indented sequences are allowed
+			//   foo:
+			//     - bar
+			//   foo:
+			//   - bar
+			if want := key.Column + c.Indentation; n.Column != want && n.Column != key.Column {
+				rule.Errorf(posAt(n), "indentation of this sequence should be %d but found %d", want-1, n.Column-1)
+			}
+		}
+		for _, e := range n.Content {
+			rule.checkNode(c, e, nil)
+		}
+		return
+	}
+
+	for _, e := range n.Content {
+		rule.checkNode(c, e, nil)
+	}
+}
@@ -0,0 +1,169 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRuleStyleChecks(t *testing.T) {
+	tests := []struct {
+		what string
+		cfg  StyleRuleConfig
+		src  string
+		want []string
+	}{
+		{
+			what: "no check by default",
+			src:  "on: yes   \njobs:\n      test:\n        runs-on: ubuntu-latest\n",
+		},
+		{
+			what: "indentation",
+			cfg:  StyleRuleConfig{Indentation: 2},
+			src: `jobs:
+   test:
+    steps:
+    - run: echo
+      with:
+        foo: bar
+    env:
+        FOO: bar
+    outputs: {foo: bar}
+`,
+			want: []string{
+				"2:4: indentation of this mapping should be 2 but found 3",
+				"3:5: indentation of this mapping should be 5 but found 4",
+				"8:9: indentation of this mapping should be 6 but found 8",
+			},
+		},
+		{
+			what: "sequence indentation",
+			cfg:  StyleRuleConfig{Indentation: 2},
+			src:  "steps:\n   - run: echo\nfoo:\n  - bar\nbaz:\n- qux\n",
+			want: []string{
+				"2:4: indentation of this sequence should be 2 but found 3",
+			},
+		},
+		{
+			what: "truthy values",
+			cfg:  StyleRuleConfig{Truthy: true},
+			src:  "on: push\nfoo: yes\nbar: 'no'\nbaz: [On, true, false]\nqux: [True, FALSE, \"True\", !!str False]\n",
+			want: []string{
+				`2:6: truthy value "yes" should be "true" or "false"`,
+				`4:7: truthy value "On" should be "true" or "false"`,
+				`5:7: truthy value "True" should be "true" or "false"`,
+				`5:13: truthy value "FALSE" should be "true" or "false"`,
+			},
+		},
+		{
+			what: "line length",
+			cfg:  StyleRuleConfig{LineLength: 10},
+			src:  "name: 1234\nname: 12345\n",
+			want: []string{
+				"2:11: line is too long. 11 characters exceed the maximum length 10",
+			},
+		},
+		{
+			what: "trailing spaces",
+			cfg:  StyleRuleConfig{TrailingSpaces: true},
+			src:  "on: push \r\nname: foo\t\n",
+			want: []string{
+				"1:9: trailing spaces at end of line",
+				"2:10: trailing spaces at end of line",
+			},
+		},
+		{
+			what: "require document start",
+			cfg:  StyleRuleConfig{DocumentStart: "require"},
+			src:  "# comment\n\non: push\n",
+			want: []string{
+				`3:1: document start marker "---" is missing`,
+			},
+		},
+		{
+			what: "document start exists",
+			cfg:  StyleRuleConfig{DocumentStart: "require"},
+			src:  "# comment\n---\non: push\n",
+		},
+		{
+			what: "forbid document start",
+			cfg:  StyleRuleConfig{DocumentStart: "forbid"},
+			src:  "---\non: push\n",
+			want: []string{
+				`1:1: document start marker "---" is not allowed`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			r := NewRuleStyle(NewSourceFile("test.yaml", []byte(tc.src)))
+			cfg := &Config{}
+			cfg.Rules.Style = tc.cfg
+			r.SetConfig(cfg)
+			if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := r.Errs()
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				want := tc.want[i]
+				have := err.Error()
+				if !strings.Contains(have, want) {
+					t.Errorf("error %q does not contain %q", have, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleStyleNoSource(t *testing.T) {
+	r := NewRuleStyle(nil)
+	cfg := &Config{}
+	cfg.Rules.Style.TrailingSpaces = true
+	r.SetConfig(cfg)
+	if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+		t.Fatal(err)
+	}
+	if errs := r.Errs(); len(errs) > 0 {
+		t.Fatal("no error was expected but got", errs)
+	}
+}
+
+func TestRuleStyleSuggestions(t *testing.T) {
+	src := "on: push \r\nfoo: yes  \nbar: [Off, ほげ]\t\n"
+	r := NewRuleStyle(NewSourceFile("test.yaml", []byte(src)))
+	cfg := &Config{}
+	cfg.Rules.Style.Truthy = true
+	cfg.Rules.Style.TrailingSpaces = true
+	r.SetConfig(cfg)
+	if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+		t.Fatal(err)
+	}
+
+	fixes := []*SuggestionTemplateFields{}
+	for _, err := range r.Errs() {
+		if len(err.Suggestions) != 1 {
+			t.Fatalf("error should have one suggestion: %v", err)
+		}
+		fixes = append(fixes, err.GetTemplateFields([]byte(src)).Suggestions...)
+	}
+	sort.Slice(fixes, func(i, j int) bool {
+		return fixes[i].Offset > fixes[j].Offset
+	})
+
+	have := src
+	for _, f := range fixes {
+		if f.Offset < 0 || f.EndOffset < f.Offset {
+			t.Fatalf("invalid range of suggestion: %#v", f)
+		}
+		have = have[:f.Offset] + f.Replacement + have[f.EndOffset:]
+	}
+	want := "on: push\r\nfoo: true\nbar: [false, ほげ]\n"
+	if have != want {
+		t.Fatalf("wanted %q but have %q", want, have)
+	}
+}
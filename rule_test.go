@@ -2,9 +2,11 @@ package actionlint
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestRuleBaseSetGetConfig(t *testing.T) {
@@ -45,7 +47,7 @@ func TestRuleBaseErrorfAndErrs(t *testing.T) {
 			Kind:    "dummy name",
 		},
 	}
-	if diff := cmp.Diff(errs, want); diff != "" {
+	if diff := cmp.Diff(errs, want, cmpopts.IgnoreUnexported(Error{})); diff != "" {
 		t.Error("unexpected errors from Errs() method:", diff)
 	}
 }
@@ -74,3 +76,38 @@ func TestRuleBaseNameAndDescription(t *testing.T) {
 		t.Errorf("description is unexpected: %q", r.Description())
 	}
 }
+
+// testCheckRule checks the workflow sources with the rule and returns the reported errors. When the
+// rule implements ProjectRule, the sources are checked as workflow files "a.yaml", "b.yaml", ... in
+// one project. Otherwise exactly one source must be given and it is checked as Pass.
+func testCheckRule(t *testing.T, r Rule, cfg *Config, srcs ...string) []*Error {
+	t.Helper()
+	files := []*ProjectFile{}
+	for i, src := range srcs {
+		w, errs := Parse([]byte(src))
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		files = append(files, &ProjectFile{Path: fmt.Sprintf("%c.yaml", 'a'+i), Workflow: w})
+	}
+	if cfg != nil {
+		r.SetConfig(cfg)
+	}
+
+	if p, ok := r.(ProjectRule); ok {
+		if err := p.VisitProject(files); err != nil {
+			t.Fatal(err)
+		}
+		return r.Errs()
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("rule %q checks one workflow at once but %d sources were given", r.Name(), len(files))
+	}
+	v := NewVisitor()
+	v.AddPass(r)
+	if err := v.Visit(files[0].Workflow); err != nil {
+		t.Fatal(err)
+	}
+	return r.Errs()
+}
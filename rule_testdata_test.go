@@ -0,0 +1,244 @@
+package actionlint
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/execabs"
+	"gopkg.in/yaml.v3"
+)
+
+var updateRuleTestdata = flag.Bool("update", false, "update expected errors annotated in testdata/rules/")
+
+// Workflows in testdata/rules/{rule}/*.yaml have their expected errors as comments. Each comment
+// line like "# want: 19: message [kind]" expects an error at the column of the closest preceding
+// line which is not an annotation. When the error cannot be annotated after its line (e.g. the line
+// is in a block scalar), the line number is also written like "# want: 12:19: message [kind]". The
+// message can be a regular expression surrounded by "/". Run `go test -run TestRuleTestdata -update`
+// to update the annotations with the actual errors.
+var reRuleTestdataAnnotation = regexp.MustCompile(`^\s*# want: (?:(\d+):)?(\d+): (.+)$`)
+
+type ruleTestdataWant struct {
+	line int
+	col  int
+	msg  string
+}
+
+func (w *ruleTestdataWant) match(line int, err *Error) bool {
+	if w.line != line || w.col != err.Column {
+		return false
+	}
+	have := fmt.Sprintf("%s [%s]", err.Message, err.Kind)
+	if len(w.msg) > 1 && strings.HasPrefix(w.msg, "/") && strings.HasSuffix(w.msg, "/") {
+		return regexp.MustCompile(w.msg[1 : len(w.msg)-1]).MatchString(have)
+	}
+	return w.msg == have
+}
+
+func (w *ruleTestdataWant) String() string {
+	return fmt.Sprintf("%d:%d: %s", w.line, w.col, w.msg)
+}
+
+// parseRuleTestdataAnnotations returns the expected errors annotated in the lines, the lines
+// without the annotations, and the mapping from line numbers of the latter to the former.
+func parseRuleTestdataAnnotations(t *testing.T, lines []string) ([]*ruleTestdataWant, []string, []int) {
+	t.Helper()
+	wants := []*ruleTestdataWant{}
+	stripped := make([]string, 0, len(lines))
+	mapped := []int{0}
+	prev := 0
+	for i, l := range lines {
+		m := reRuleTestdataAnnotation.FindStringSubmatch(l)
+		if m == nil {
+			prev = i + 1
+			stripped = append(stripped, l)
+			mapped = append(mapped, prev)
+			continue
+		}
+		w := &ruleTestdataWant{line: prev, msg: m[3]}
+		if m[1] != "" {
+			w.line, _ = strconv.Atoi(m[1])
+		}
+		w.col, _ = strconv.Atoi(m[2])
+		if w.line == 0 {
+			t.Fatalf("annotation at line %d has no preceding line: %q", i+1, l)
+		}
+		wants = append(wants, w)
+	}
+	return wants, stripped, mapped
+}
+
+func lintRuleTestdata(t *testing.T, dir, path string, src []byte) []*Error {
+	t.Helper()
+
+	opts := LinterOptions{}
+	switch filepath.Base(dir) {
+	case "shellcheck":
+		p, err := execabs.LookPath("shellcheck")
+		if err != nil {
+			t.Skip("skipped because \"shellcheck\" command does not exist in system")
+		}
+		opts.Shellcheck = p
+	case "pyflakes":
+		p, err := execabs.LookPath("pyflakes")
+		if err != nil {
+			t.Skip("skipped because \"pyflakes\" command does not exist in system")
+		}
+		opts.Pyflakes = p
+	}
+
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+	if cfg, err := ReadConfigFile(filepath.Join(dir, "actionlint.yaml")); err == nil {
+		l.defaultConfig = cfg
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+
+	errs, err := l.Lint(filepath.Base(path), src, &Project{root: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Stable(ByErrorPosition(errs))
+	return errs
+}
+
+// annotateRuleTestdata inserts the annotations of the errors into the lines. An annotation is put
+// after its error line as long as it does not change the YAML value. Otherwise it is put at the end
+// with the line number.
+func annotateRuleTestdata(lines []string, errs []*Error) []string {
+	var want any
+	wantErr := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &want)
+
+	byLine := map[int][]*Error{}
+	for _, err := range errs {
+		byLine[err.Line] = append(byLine[err.Line], err)
+	}
+
+	ret := make([]string, 0, len(lines)+len(errs))
+	mapped := make([]int, len(lines)+1) // Line numbers in the source to line numbers in the output
+	trailing := []*Error{}
+	for i, l := range lines {
+		ret = append(ret, l)
+		mapped[i+1] = len(ret)
+		es, ok := byLine[i+1]
+		if !ok {
+			continue
+		}
+
+		indent := l[:len(l)-len(strings.TrimLeft(l, " "))]
+		as := make([]string, 0, len(es))
+		for _, err := range es {
+			as = append(as, fmt.Sprintf("%s# want: %d: %s [%s]", indent, err.Column, err.Message, err.Kind))
+		}
+
+		ok = false
+		if wantErr == nil {
+			src := append(append(append([]string{}, lines[:i+1]...), as...), lines[i+1:]...)
+			var have any
+			ok = yaml.Unmarshal([]byte(strings.Join(src, "\n")), &have) == nil && reflect.DeepEqual(want, have)
+		}
+		if ok {
+			ret = append(ret, as...)
+		} else {
+			trailing = append(trailing, es...)
+		}
+	}
+
+	// Keep the empty last line for the newline at end of file
+	last := ""
+	if len(ret) > 0 && ret[len(ret)-1] == "" {
+		last = ret[len(ret)-1]
+		ret = ret[:len(ret)-1]
+	}
+	for _, err := range trailing {
+		ret = append(ret, fmt.Sprintf("# want: %d:%d: %s [%s]", mapped[err.Line], err.Column, err.Message, err.Kind))
+	}
+	return append(ret, last)
+}
+
+func TestRuleTestdata(t *testing.T) {
+	root := filepath.Join("testdata", "rules")
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		rule := d.Name()
+		dir := filepath.Join(root, rule)
+		if _, ok := errorCodes[rule]; !ok {
+			t.Errorf("directory %q does not match to any rule name", dir)
+			continue
+		}
+
+		es, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range es {
+			n := e.Name()
+			if e.IsDir() || n == "actionlint.yaml" || !(strings.HasSuffix(n, ".yaml") || strings.HasSuffix(n, ".yml")) {
+				continue
+			}
+			path := filepath.Join(dir, n)
+			t.Run(rule+"/"+n, func(t *testing.T) {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines := strings.Split(string(b), "\n")
+				wants, stripped, mapped := parseRuleTestdataAnnotations(t, lines)
+
+				// Lint the workflow without the annotations so that line numbers in error messages
+				// don't depend on the annotations
+				errs := lintRuleTestdata(t, dir, path, []byte(strings.Join(stripped, "\n")))
+
+				if *updateRuleTestdata {
+					out := strings.Join(annotateRuleTestdata(stripped, errs), "\n")
+					if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+
+				matched := make([]bool, len(errs))
+			Wants:
+				for _, w := range wants {
+					for i, err := range errs {
+						if !matched[i] && w.match(mapped[err.Line], err) {
+							matched[i] = true
+							continue Wants
+						}
+					}
+					t.Errorf("expected error was not reported: %s", w)
+				}
+				for i, err := range errs {
+					if !matched[i] {
+						t.Errorf("unexpected error was reported: %d:%d: %s [%s]", mapped[err.Line], err.Column, err.Message, err.Kind)
+					}
+				}
+				if t.Failed() {
+					t.Log("run `go test -run TestRuleTestdata -update` to update the expected errors in", path)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package actionlint
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleUnused is a rule to check local reusable workflows and local actions in ".github/actions"
+// are used by some workflow in the repository. Workflows triggered by events other than
+// workflow_call are entry points and reusable workflows and actions which are not reachable from
+// them are reported. This rule checks all workflow files in the project at once. Since the
+// references from workflows which are not checked are unknown, this rule does nothing unless all
+// workflow files in the project are checked. Each check is disabled by default.
+type RuleUnused struct {
+	RuleBase
+	project *Project
+	cwd     string
+}
+
+// NewRuleUnused creates a new RuleUnused instance. The project parameter is the project which the
+// checked workflows belong to. The cwd parameter is a working directory which file paths of the
+// workflows are relative to.
+func NewRuleUnused(project *Project, cwd string) *RuleUnused {
+	return &RuleUnused{
+		RuleBase: RuleBase{
+			name: "unused",
+			desc: "Checks local reusable workflows and local actions are used by some workflow in the repository",
+		},
+		project: project,
+		cwd:     cwd,
+	}
+}
+
+// unusedLocalAction is a local action found in ".github/actions" directory.
+type unusedLocalAction struct {
+	dir  string // Slash-separated path relative to the repository root
+	file string
+	used bool
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleUnused) VisitProject(files []*ProjectFile) error {
+	cfg := rule.Config()
+	if cfg == nil || rule.project == nil {
+		return nil
+	}
+	conf := &cfg.Rules.Unused
+	if !conf.ReusableWorkflows && !conf.Actions {
+		return nil
+	}
+
+	// Key is a slash-separated path relative to the repository root like ".github/workflows/ci.yaml"
+	workflows := map[string]*ProjectFile{}
+	paths := map[*ProjectFile]string{}
+	for _, f := range files {
+		p := f.Path
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(rule.cwd, p)
+		}
+		r, err := filepath.Rel(rule.project.RootDir(), p)
+		if err != nil || strings.HasPrefix(r, "..") {
+			continue
+		}
+		workflows[filepath.ToSlash(r)] = f
+		paths[f] = filepath.ToSlash(r)
+	}
+
+	all, err := findWorkflowFiles(rule.project.WorkflowsDir())
+	if err != nil {
+		rule.Debug("Skip checking unused reusable workflows and actions: %s", err)
+		return nil
+	}
+	for _, p := range all {
+		r, err := filepath.Rel(rule.project.RootDir(), p)
+		if err != nil {
+			return nil
+		}
+		if _, ok := workflows[filepath.ToSlash(r)]; !ok {
+			rule.Debug("Skip checking unused reusable workflows and actions since workflow %q is not checked", r)
+			return nil
+		}
+	}
+
+	actions := rule.findLocalActions()
+	dynamic := false // True when some local action is used with ${{ }}
+
+	// Traverse the reference graph from workflows triggered by events other than workflow_call
+	called := map[string]bool{}
+	queue := []string{}
+	for p, f := range workflows {
+		if !isReusableWorkflowOnly(f.Workflow) {
+			called[p] = true
+			queue = append(queue, p)
+		}
+	}
+	useAction := func(spec string) []string {
+		if !strings.HasPrefix(spec, "./") {
+			return nil
+		}
+		if ContainsExpression(spec) {
+			dynamic = true
+			return nil
+		}
+		a, ok := actions[path.Clean(spec)]
+		if !ok || a.used {
+			return nil
+		}
+		a.used = true
+		return rule.localActionsUsedBy(a)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, j := range workflows[p].Workflow.Jobs {
+			if j.WorkflowCall != nil && j.WorkflowCall.Uses != nil {
+				if u := j.WorkflowCall.Uses.Value; strings.HasPrefix(u, "./") {
+					w := path.Clean(u)
+					if _, ok := workflows[w]; ok && !called[w] {
+						called[w] = true
+						queue = append(queue, w)
+					}
+				}
+			}
+			for _, s := range j.Steps {
+				e, ok := s.Exec.(*ExecAction)
+				if !ok || e.Uses == nil {
+					continue
+				}
+				specs := []string{e.Uses.Value}
+				for len(specs) > 0 {
+					s := specs[0]
+					specs = append(specs[1:], useAction(s)...)
+				}
+			}
+		}
+	}
+
+	if conf.ReusableWorkflows {
+		for _, f := range files {
+			p, ok := paths[f]
+			if !ok || called[p] {
+				continue
+			}
+			for _, e := range f.Workflow.On {
+				if e, ok := e.(*WorkflowCallEvent); ok {
+					rule.FileErrorf(f.Path, e.Pos, "reusable workflow %q is not called by any workflow triggered in this repository. remove it if it is no longer used", "./"+p)
+				}
+			}
+		}
+	}
+
+	if conf.Actions && !dynamic {
+		for _, a := range actions {
+			if a.used {
+				continue
+			}
+			p := filepath.Join(rule.project.RootDir(), filepath.FromSlash(a.dir), a.file)
+			if r, err := filepath.Rel(rule.cwd, p); err == nil {
+				p = r
+			}
+			rule.FileErrorf(p, rule.actionNamePos(a), "local action %q is not used by any workflow triggered in this repository. remove it if it is no longer used", "./"+a.dir)
+		}
+	}
+
+	return nil
+}
+
+// isReusableWorkflowOnly returns true when the workflow is triggered only by workflow_call event.
+func isReusableWorkflowOnly(w *Workflow) bool {
+	if len(w.On) == 0 {
+		return false
+	}
+	for _, e := range w.On {
+		if _, ok := e.(*WorkflowCallEvent); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// findLocalActions finds all actions in ".github/actions" directory of the project. Keys of the
+// returned map are paths of the action directories like ".github/actions/setup".
+func (rule *RuleUnused) findLocalActions() map[string]*unusedLocalAction {
+	ret := map[string]*unusedLocalAction{}
+	root := rule.project.RootDir()
+	filepath.Walk(filepath.Join(root, ".github", "actions"), func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if n := info.Name(); n == "action.yml" || n == "action.yaml" {
+			if r, err := filepath.Rel(root, filepath.Dir(p)); err == nil {
+				d := filepath.ToSlash(r)
+				ret[d] = &unusedLocalAction{dir: d, file: n}
+			}
+		}
+		return nil
+	})
+	return ret
+}
+
+// localActionsUsedBy returns "uses:" of steps in the local composite action.
+func (rule *RuleUnused) localActionsUsedBy(a *unusedLocalAction) []string {
+	b, err := os.ReadFile(filepath.Join(rule.project.RootDir(), filepath.FromSlash(a.dir), a.file))
+	if err != nil {
+		return nil
+	}
+	var m ActionMetadata
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	ret := []string{}
+	for _, s := range m.Runs.Steps {
+		if s, ok := s.(map[string]any); ok {
+			if u, ok := s["uses"].(string); ok {
+				ret = append(ret, u)
+			}
+		}
+	}
+	return ret
+}
+
+// actionNamePos returns the position of "name:" key in the action metadata file. When it is not
+// found, the position of the head of the file is returned.
+func (rule *RuleUnused) actionNamePos(a *unusedLocalAction) *Pos {
+	pos := &Pos{Line: 1, Col: 1}
+	b, err := os.ReadFile(filepath.Join(rule.project.RootDir(), filepath.FromSlash(a.dir), a.file))
+	if err != nil {
+		return pos
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil || len(n.Content) == 0 || n.Content[0].Kind != yaml.MappingNode {
+		return pos
+	}
+	m := n.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if k := m.Content[i]; k.Value == "name" {
+			return &Pos{Line: k.Line, Col: k.Column}
+		}
+	}
+	return pos
+}
@@ -0,0 +1,20 @@
+package actionlint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Other cases are tested with testdata/projects/unused*
+func TestRuleUnusedSkipWhenSomeWorkflowIsNotChecked(t *testing.T) {
+	errs := testLintProjectFiles(
+		t,
+		filepath.Join("testdata", "projects", "unused"),
+		".github/workflows/nested.yaml",
+		".github/workflows/unused.yaml",
+		".github/workflows/used.yaml",
+	)
+	if len(errs) != 0 {
+		t.Fatal("no error should be reported when ci.yaml is not checked:", errs)
+	}
+}
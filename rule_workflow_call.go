@@ -2,20 +2,38 @@ package actionlint
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// RuleWorkflowCall is a rule checker to check workflow call at jobs.<job_id>.
+const (
+	// maxReusableWorkflowLevels is the maximum number of levels of workflows connected by reusable
+	// workflow calls including the top-level caller workflow.
+	// https://docs.github.com/en/actions/using-workflows/reusing-workflows#nesting-reusable-workflows
+	maxReusableWorkflowLevels = 4
+	// maxReusableWorkflowCalls is the maximum number of unique reusable workflows which one workflow
+	// file can call including nested calls.
+	maxReusableWorkflowCalls = 20
+)
+
+// RuleWorkflowCall is a rule checker to check workflow call at jobs.<job_id>. It also tracks chains
+// of nested reusable workflow calls to check GitHub's limits of nesting and recursive calls.
+// Reusable workflows in other repositories are tracked only when RemoteReusableWorkflows of the
+// capabilities is available.
 type RuleWorkflowCall struct {
 	RuleBase
 	workflowCallEventPos *Pos
 	workflowPath         string
-	cache                *LocalReusableWorkflowCache
+	self                 string              // Spec of the checked workflow like "./.github/workflows/ci.yaml"
+	called               map[string]struct{} // Unique reusable workflows called by the checked workflow
+	tooManyCalls         bool
+	inheritable          bool // True when the checked workflow can access the repository secrets
 }
 
 // NewRuleWorkflowCall creates a new RuleWorkflowCall instance. 'workflowPath' is a file path to
-// the workflow which is relative to a project root directory or an absolute path.
-func NewRuleWorkflowCall(workflowPath string, cache *LocalReusableWorkflowCache) *RuleWorkflowCall {
+// the workflow which is relative to a project root directory or an absolute path. Local reusable
+// workflows are resolved with LocalReusableWorkflows of the capabilities set by SetCapabilities.
+func NewRuleWorkflowCall(workflowPath string) *RuleWorkflowCall {
 	return &RuleWorkflowCall{
 		RuleBase: RuleBase{
 			name: "workflow-call",
@@ -23,18 +41,28 @@ func NewRuleWorkflowCall(workflowPath string, cache *LocalReusableWorkflowCache)
 		},
 		workflowCallEventPos: nil,
 		workflowPath:         workflowPath,
-		cache:                cache,
+		self:                 workflowPath,
+		called:               map[string]struct{}{},
 	}
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleWorkflowCall) VisitWorkflowPre(n *Workflow) error {
+	e, ok := n.FindWorkflowCallEvent()
+	rule.inheritable = !ok || e.Secrets == nil
+	c := rule.Capabilities().LocalReusableWorkflows
+	if c == nil {
+		return nil
+	}
+	if s, ok := c.convWorkflowPathToSpec(rule.workflowPath); ok {
+		rule.self = s
+	}
 	for _, e := range n.On {
 		if e, ok := e.(*WorkflowCallEvent); ok {
 			rule.workflowCallEventPos = e.Pos
 			// Register this reusable workflow in cache so that it does not need to parse this workflow
 			// file again when this workflow is called by other workflows.
-			rule.cache.WriteWorkflowCallEvent(rule.workflowPath, e)
+			c.WriteWorkflowCallEvent(rule.workflowPath, e)
 			break
 		}
 	}
@@ -54,18 +82,20 @@ func (rule *RuleWorkflowCall) VisitJobPre(n *Job) error {
 
 	if isWorkflowCallUsesLocalFormat(u.Value) {
 		rule.checkWorkflowCallUsesLocal(n.WorkflowCall)
+		rule.checkNestedWorkflowCalls(u)
 		return nil
 	}
 
 	if isWorkflowCallUsesRepoFormat(u.Value) {
+		rule.checkNestedWorkflowCalls(u)
 		return nil
 	}
 
-	if strings.HasPrefix(u.Value, "./") {
+	if c := rule.Capabilities().LocalReusableWorkflows; strings.HasPrefix(u.Value, "./") && c != nil {
 		// When the specification is invalid and it is local reusable workflow call, remember it caused
 		// an error by setting `nil` to cache. This can prevent redundant 'could not read workflow call'
 		// error.
-		rule.cache.writeCache(u.Value, nil)
+		c.writeCache(u.Value, nil)
 	}
 
 	rule.Errorf(
@@ -78,7 +108,11 @@ func (rule *RuleWorkflowCall) VisitJobPre(n *Job) error {
 
 func (rule *RuleWorkflowCall) checkWorkflowCallUsesLocal(call *WorkflowCall) {
 	u := call.Uses
-	m, err := rule.cache.FindMetadata(u.Value)
+	c := rule.Capabilities().LocalReusableWorkflows
+	if c == nil {
+		return
+	}
+	m, err := c.FindMetadata(u.Value)
 	if err != nil {
 		rule.Error(u.Pos, err.Error())
 		return
@@ -88,6 +122,131 @@ func (rule *RuleWorkflowCall) checkWorkflowCallUsesLocal(call *WorkflowCall) {
 		return
 	}
 
+	var inherited map[string]struct{}
+	var where string
+	if call.InheritSecrets && rule.inheritable {
+		inherited, where, err = inheritedSecrets(rule.Config(), rule.Capabilities().Remote)
+		if err != nil {
+			rule.Debug("Could not list secrets inherited by %q: %v", u.Value, err)
+		}
+	}
+
+	checkWorkflowCallWithMetadata(&rule.RuleBase, call, m, inherited, where)
+	rule.Debug("Validated reusable workflow %q", u.Value)
+}
+
+// inheritedSecrets returns names of secrets passed to reusable workflows with `secrets: inherit` in
+// upper case. The names are taken from "secrets" in the configuration, or listed with GitHub API when
+// the repository is given. The second return value describes where the names came from. It returns
+// nil when the secrets are unknown.
+func inheritedSecrets(cfg *Config, repo *RemoteRepository) (map[string]struct{}, string, error) {
+	if cfg != nil && cfg.Secrets != nil {
+		names := make(map[string]struct{}, len(cfg.Secrets))
+		for _, n := range cfg.Secrets {
+			names[strings.ToUpper(n)] = struct{}{}
+		}
+		return names, `"secrets" in actionlint.yaml`, nil
+	}
+	if repo == nil {
+		return nil, "", nil
+	}
+	names, ok, err := repo.Secrets()
+	if err != nil || !ok {
+		return nil, "", err
+	}
+	return names, fmt.Sprintf("repository %q and its organization", repo.String()), nil
+}
+
+// checkNestedWorkflowCalls traverses reusable workflows called from the workflow call at 'u'
+// recursively and checks the nesting level, the number of called workflows, and recursive calls.
+func (rule *RuleWorkflowCall) checkNestedWorkflowCalls(u *String) {
+	var deep, recursive []string
+	var visit func(chain []string)
+	visit = func(chain []string) {
+		spec := chain[len(chain)-1]
+		rule.called[spec] = struct{}{}
+		if len(chain) > maxReusableWorkflowLevels {
+			if deep == nil {
+				deep = chain
+			}
+			return
+		}
+		for _, c := range rule.findCalls(spec) {
+			if containsString(chain, c) {
+				if recursive == nil {
+					recursive = append(chain[:len(chain):len(chain)], c)
+				}
+				continue
+			}
+			visit(append(chain[:len(chain):len(chain)], c))
+		}
+	}
+
+	// When the checked workflow is a remote reusable workflow, local calls are in its repository
+	spec := resolveRemoteWorkflowCall(u.Value, rule.self)
+	if spec == rule.self {
+		recursive = []string{rule.self, spec}
+	} else {
+		visit([]string{rule.self, spec})
+	}
+
+	if recursive != nil {
+		rule.Errorf(
+			u.Pos,
+			"reusable workflow is called recursively: %s. GitHub does not allow reusable workflows to call themselves",
+			strings.Join(recursive, " -> "),
+		)
+	}
+	if deep != nil {
+		rule.Errorf(
+			u.Pos,
+			"reusable workflow call %q nests workflows more than %d levels: %s. GitHub allows connecting up to %d levels of workflows including the caller workflow",
+			u.Value,
+			maxReusableWorkflowLevels,
+			strings.Join(deep, " -> "),
+			maxReusableWorkflowLevels,
+		)
+	}
+	if n := len(rule.called); n > maxReusableWorkflowCalls && !rule.tooManyCalls {
+		rule.tooManyCalls = true
+		rule.Errorf(
+			u.Pos,
+			"this workflow calls %d unique reusable workflows including nested calls at this job. GitHub allows one workflow file to call up to %d unique reusable workflows",
+			n,
+			maxReusableWorkflowCalls,
+		)
+	}
+}
+
+// findCalls returns reusable workflows called by the reusable workflow. Workflows in other
+// repositories are resolved only when the remote cache is available.
+func (rule *RuleWorkflowCall) findCalls(spec string) []string {
+	if isWorkflowCallUsesLocalFormat(spec) {
+		c := rule.Capabilities().LocalReusableWorkflows
+		if c == nil {
+			return nil
+		}
+		return c.findCalls(spec)
+	}
+	c := rule.Capabilities().RemoteReusableWorkflows
+	if c == nil || !isWorkflowCallUsesRepoFormat(spec) {
+		return nil
+	}
+	calls, err := c.findCalls(spec)
+	if err != nil {
+		rule.Debug("Could not find reusable workflows called by %q: %v", spec, err)
+		return nil
+	}
+	return calls
+}
+
+// checkWorkflowCallWithMetadata validates inputs and secrets of the workflow call with the metadata
+// of the called reusable workflow. This is shared by rules which check local and remote reusable
+// workflows. The 'inherited' parameter is names of secrets passed with `secrets: inherit` in upper
+// case and 'where' describes where the names came from. It is nil when the secrets are unknown.
+func checkWorkflowCallWithMetadata(rule *RuleBase, call *WorkflowCall, m *ReusableWorkflowMetadata, inherited map[string]struct{}, where string) {
+	u := call.Uses
+
 	// Validate inputs
 	for n, i := range m.Inputs {
 		if i != nil && i.Required {
@@ -140,9 +299,20 @@ func (rule *RuleWorkflowCall) checkWorkflowCallUsesLocal(call *WorkflowCall) {
 				rule.Errorf(s.Name.Pos, "secret %q is not defined in %q reusable workflow. %s", s.Name.Value, u.Value, note)
 			}
 		}
+	} else if inherited != nil {
+		missing := []string{}
+		for _, s := range m.Secrets {
+			if s.Required {
+				if _, ok := inherited[strings.ToUpper(s.Name)]; !ok {
+					missing = append(missing, s.Name)
+				}
+			}
+		}
+		sort.Strings(missing)
+		for _, n := range missing {
+			rule.Errorf(u.Pos, "secret %q is required by %q reusable workflow but it is not inherited with \"secrets: inherit\" since it is not defined in %s", n, u.Value, where)
+		}
 	}
-
-	rule.Debug("Validated reusable workflow %q", u.Value)
 }
 
 // Parse ./{path/{filename}
@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"encoding/base64"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -36,7 +37,8 @@ func TestRuleWorkflowCallCheckWorkflowCallUsesFormat(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.uses, func(t *testing.T) {
 			c := NewLocalReusableWorkflowCache(nil, "", nil)
-			r := NewRuleWorkflowCall("", c)
+			r := NewRuleWorkflowCall("")
+			r.SetCapabilities(&Capabilities{LocalReusableWorkflows: c})
 			j := &Job{
 				WorkflowCall: &WorkflowCall{
 					Uses: &String{
@@ -81,7 +83,8 @@ func TestRuleWorkflowCallNestedWorkflowCalls(t *testing.T) {
 	}
 
 	c := NewLocalReusableWorkflowCache(nil, "", nil)
-	r := NewRuleWorkflowCall("", c)
+	r := NewRuleWorkflowCall("")
+	r.SetCapabilities(&Capabilities{LocalReusableWorkflows: c})
 
 	if err := r.VisitWorkflowPre(w); err != nil {
 		t.Fatal(err)
@@ -124,7 +127,8 @@ func TestRuleWorkflowCallWriteEventNodeToMetadataCache(t *testing.T) {
 
 	cwd := filepath.Join("path", "to", "project")
 	c := NewLocalReusableWorkflowCache(&Project{cwd, nil}, cwd, nil)
-	r := NewRuleWorkflowCall("test-workflow.yaml", c)
+	r := NewRuleWorkflowCall("test-workflow.yaml")
+	r.SetCapabilities(&Capabilities{LocalReusableWorkflows: c})
 
 	if err := r.VisitWorkflowPre(w); err != nil {
 		t.Fatal(err)
@@ -321,7 +325,8 @@ func TestRuleWorkflowCallCheckReusableWorkflowCall(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.what, func(t *testing.T) {
-			r := NewRuleWorkflowCall("this-workflow.yaml", cache)
+			r := NewRuleWorkflowCall("this-workflow.yaml")
+			r.SetCapabilities(&Capabilities{LocalReusableWorkflows: cache})
 
 			w := &Workflow{
 				On: []Event{
@@ -383,3 +388,165 @@ func TestRuleWorkflowCallCheckReusableWorkflowCall(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleWorkflowCallNestingLimits(t *testing.T) {
+	calls := func(uses ...string) string {
+		var b strings.Builder
+		b.WriteString("on: workflow_call\njobs:\n")
+		for i, u := range uses {
+			fmt.Fprintf(&b, "  call%d:\n    uses: %s\n", i, u)
+		}
+		return b.String()
+	}
+	many := []string{}
+	for i := 0; i < 20; i++ {
+		many = append(many, fmt.Sprintf("./w%d.yaml", i))
+	}
+
+	tests := []struct {
+		what  string
+		uses  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			what: "nested up to limit",
+			uses: "./a.yaml",
+			files: map[string]string{
+				"a.yaml": calls("./b.yaml"),
+				"b.yaml": calls("./c.yaml", "${{ inputs.workflow }}"),
+				"c.yaml": calls(),
+			},
+		},
+		{
+			what: "nested beyond limit",
+			uses: "./a.yaml",
+			files: map[string]string{
+				"a.yaml": calls("./b.yaml"),
+				"b.yaml": calls("./c.yaml"),
+				"c.yaml": calls("./d.yaml"),
+				"d.yaml": calls(),
+			},
+			want: []string{
+				`reusable workflow call "./a.yaml" nests workflows more than 4 levels: ./ci.yaml -> ./a.yaml -> ./b.yaml -> ./c.yaml -> ./d.yaml.`,
+			},
+		},
+		{
+			what: "self call",
+			uses: "./ci.yaml",
+			files: map[string]string{
+				"ci.yaml": calls("./ci.yaml"),
+			},
+			want: []string{
+				`reusable workflow is called recursively: ./ci.yaml -> ./ci.yaml.`,
+			},
+		},
+		{
+			what: "recursive calls",
+			uses: "./a.yaml",
+			files: map[string]string{
+				"a.yaml": calls("./b.yaml"),
+				"b.yaml": calls("./a.yaml"),
+			},
+			want: []string{
+				`reusable workflow is called recursively: ./ci.yaml -> ./a.yaml -> ./b.yaml -> ./a.yaml.`,
+			},
+		},
+		{
+			what: "too many workflows",
+			uses: "./a.yaml",
+			files: map[string]string{
+				"a.yaml": calls(many...),
+			},
+			want: []string{
+				`this workflow calls 21 unique reusable workflows including nested calls at this job.`,
+			},
+		},
+		{
+			what: "workflow not found",
+			uses: "./a.yaml",
+			want: []string{
+				`could not read reusable workflow file for "./a.yaml"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			root := filepath.Join("path", "to", "project")
+			c := NewLocalReusableWorkflowCache(&Project{root, nil}, root, nil)
+			c.read = func(p string) ([]byte, error) {
+				r, err := filepath.Rel(root, p)
+				if err != nil {
+					return nil, err
+				}
+				if s, ok := tc.files[filepath.ToSlash(r)]; ok {
+					return []byte(s), nil
+				}
+				if strings.HasPrefix(r, "w") {
+					return []byte(calls()), nil
+				}
+				return nil, fmt.Errorf("%s not found", r)
+			}
+			r := NewRuleWorkflowCall("ci.yaml")
+			r.SetCapabilities(&Capabilities{LocalReusableWorkflows: c})
+			if err := r.VisitWorkflowPre(&Workflow{}); err != nil {
+				t.Fatal(err)
+			}
+			j := &Job{
+				WorkflowCall: &WorkflowCall{
+					Uses: &String{Value: tc.uses, Pos: &Pos{}},
+				},
+			}
+			if err := r.VisitJobPre(j); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := r.Errs()
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %v", len(tc.want), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Message, tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Message, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRuleWorkflowCallNestingLimitsRemote(t *testing.T) {
+	content := func(s string) string {
+		return fmt.Sprintf(`{"type":"file","encoding":"base64","content":%q}`, base64.StdEncoding.EncodeToString([]byte(s)))
+	}
+	c, _ := testGitHubServer(t, map[string]string{
+		"/repos/o/r/contents/.github/workflows/a.yml?ref=v1": content("on: workflow_call\njobs:\n  call:\n    uses: ./.github/workflows/b.yml\n"),
+		"/repos/o/r/contents/.github/workflows/b.yml?ref=v1": content("on: workflow_call\njobs:\n  call:\n    uses: o/r/.github/workflows/a.yml@v1\n"),
+	})
+	cache := NewRemoteReusableWorkflowCache(c, nil)
+
+	r := NewRuleWorkflowCall("ci.yaml")
+	r.SetCapabilities(&Capabilities{RemoteReusableWorkflows: cache})
+	j := &Job{
+		WorkflowCall: &WorkflowCall{
+			Uses: &String{Value: "o/r/.github/workflows/a.yml@v1", Pos: &Pos{}},
+		},
+	}
+	if err := r.VisitJobPre(j); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := r.Errs()
+	if len(errs) != 1 {
+		t.Fatal("wanted one error but got", errs)
+	}
+	want := "reusable workflow is called recursively: ci.yaml -> o/r/.github/workflows/a.yml@v1 -> o/r/.github/workflows/b.yml@v1 -> o/r/.github/workflows/a.yml@v1."
+	if !strings.Contains(errs[0].Message, want) {
+		t.Fatalf("error %q does not contain %q", errs[0].Message, want)
+	}
+
+	// Workflows fetched only for tracking nested calls are not linted with -remote-lint
+	if ws := cache.Workflows(); len(ws) != 0 {
+		t.Fatal("no workflow should be requested:", ws)
+	}
+}
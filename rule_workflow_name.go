@@ -0,0 +1,41 @@
+package actionlint
+
+// RuleWorkflowName is a rule to check workflow names are unique in the repository. Workflows with
+// the same name are hard to distinguish in GitHub UI and required status checks. This rule checks
+// all workflow files in the project at once. The rule is disabled by default.
+type RuleWorkflowName struct {
+	RuleBase
+}
+
+// NewRuleWorkflowName creates a new RuleWorkflowName instance.
+func NewRuleWorkflowName() *RuleWorkflowName {
+	return &RuleWorkflowName{
+		RuleBase: RuleBase{
+			name: "workflow-name",
+			desc: "Checks workflow names are unique across workflow files in the repository",
+		},
+	}
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleWorkflowName) VisitProject(files []*ProjectFile) error {
+	if cfg := rule.Config(); cfg == nil || !cfg.Rules.WorkflowName.Unique {
+		return nil
+	}
+
+	seen := map[string]string{}
+	for _, f := range files {
+		n := f.Workflow.Name
+		if n == nil || n.Value == "" {
+			continue // Workflow file path is shown as name in GitHub UI
+		}
+		if prev, ok := seen[n.Value]; ok {
+			if prev != f.Path {
+				rule.FileErrorf(f.Path, n.Pos, "workflow name %q is duplicated. it is also used in %q. workflows with the same name are hard to distinguish in GitHub UI and required status checks", n.Value, prev)
+			}
+			continue
+		}
+		seen[n.Value] = f.Path
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// RuleWorkflowRun is a rule to check workflows referenced at "workflows:" filter of workflow_run
+// event exist in the repository. workflow_run event refers other workflows by their names so a typo
+// or renaming the referenced workflow silently stops triggering the workflow. This rule checks all
+// workflow files in the project at once. Workflow files which are not checked are read from the
+// project directory.
+type RuleWorkflowRun struct {
+	RuleBase
+	project *Project
+	cwd     string
+	head    map[string]string // Workflow names at HEAD commit mapped to their file paths
+}
+
+// NewRuleWorkflowRun creates a new RuleWorkflowRun instance. The project parameter is the project
+// which the checked workflows belong to. The cwd parameter is a working directory which file paths
+// of the workflows are relative to.
+func NewRuleWorkflowRun(project *Project, cwd string) *RuleWorkflowRun {
+	return &RuleWorkflowRun{
+		RuleBase: RuleBase{
+			name: "workflow-run",
+			desc: "Checks workflows referenced by workflow_run event exist in the repository",
+		},
+		project: project,
+		cwd:     cwd,
+	}
+}
+
+// workflowRunTarget is a workflow in the repository which can be referenced by workflow_run event.
+type workflowRunTarget struct {
+	path     string // Slash-separated path relative to the repository root
+	reusable bool   // True when the workflow is triggered only by workflow_call event
+}
+
+// VisitProject is callback when checking all workflow files in the project.
+func (rule *RuleWorkflowRun) VisitProject(files []*ProjectFile) error {
+	if rule.project == nil {
+		return nil
+	}
+
+	dir := rule.project.WorkflowsDir()
+	checked := map[string]*Workflow{}
+	events := []*WebhookEvent{}
+	paths := map[*WebhookEvent]string{}
+	for _, f := range files {
+		p := f.Path
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(rule.cwd, p)
+		}
+		// Files outside the workflows directory are not workflows run on GitHub
+		if filepath.Dir(p) != dir {
+			continue
+		}
+		checked[p] = f.Workflow
+		for _, e := range f.Workflow.On {
+			if e, ok := e.(*WebhookEvent); ok && e.Hook.Value == "workflow_run" && len(e.Workflows) > 0 {
+				events = append(events, e)
+				paths[e] = f.Path
+			}
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	all, err := findWorkflowFiles(dir)
+	if err != nil {
+		rule.Debug("Skip checking workflows referenced by workflow_run event: %s", err)
+		return nil
+	}
+	names := map[string]*workflowRunTarget{}
+	for _, p := range all {
+		if filepath.Dir(p) != dir {
+			continue
+		}
+		w, ok := checked[p]
+		if !ok {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				rule.Debug("Could not read workflow file %q: %s", p, err)
+				continue
+			}
+			w, _ = Parse(b)
+			if w == nil {
+				continue
+			}
+		}
+		r, err := filepath.Rel(rule.project.RootDir(), p)
+		if err != nil {
+			continue
+		}
+		t := &workflowRunTarget{filepath.ToSlash(r), isReusableWorkflowOnly(w)}
+		if w.Name != nil && w.Name.Value != "" {
+			names[w.Name.Value] = t
+		} else {
+			names[t.path] = t // Workflow file path is used as its name when "name:" is omitted
+		}
+	}
+
+	for _, e := range events {
+		for _, n := range e.Workflows {
+			rule.checkReference(paths[e], n, names)
+		}
+	}
+
+	return nil
+}
+
+func (rule *RuleWorkflowRun) checkReference(path string, ref *String, names map[string]*workflowRunTarget) {
+	n := ref.Value
+	if ContainsExpression(n) {
+		return
+	}
+
+	if t, ok := names[n]; ok {
+		if t.reusable {
+			rule.FileErrorf(
+				path,
+				ref.Pos,
+				"workflow %q referenced by workflow_run event is triggered only by workflow_call event. reusable workflow runs as a part of its caller workflow so it never triggers workflow_run event",
+				n,
+			)
+		}
+		return
+	}
+
+	if prev, ok := rule.headWorkflowNames()[n]; ok {
+		for cur, t := range names {
+			if t.path == prev {
+				rule.FileErrorf(
+					path,
+					ref.Pos,
+					"workflow %q referenced by workflow_run event is renamed to %q in %q. update the name in \"workflows\" filter",
+					n,
+					cur,
+					prev,
+				)
+				return
+			}
+		}
+		rule.FileErrorf(
+			path,
+			ref.Pos,
+			"workflow %q referenced by workflow_run event is not found in this repository. it was defined in %q at HEAD but the file was removed or moved",
+			n,
+			prev,
+		)
+		return
+	}
+
+	rule.FileErrorf(
+		path,
+		ref.Pos,
+		"workflow %q referenced by workflow_run event is not found in this repository. \"workflows\" filter must be the name of workflow at \"name:\" or the workflow file path when \"name:\" is omitted",
+		n,
+	)
+}
+
+// headWorkflowNames returns names of workflows at HEAD commit of the Git repository mapped to their
+// file paths. It returns an empty map when the project is not a Git repository or has no commit.
+// The result is cached since running git commands is slow.
+func (rule *RuleWorkflowRun) headWorkflowNames() map[string]string {
+	if rule.head != nil {
+		return rule.head
+	}
+	rule.head = map[string]string{}
+
+	g := rule.Capabilities().Git
+	if g == nil {
+		rule.Debug("Skip reading workflows at HEAD since Git repository is not available")
+		return rule.head
+	}
+	fs, err := g.FilesAtHead(".github/workflows")
+	if err != nil {
+		rule.Debug("Skip reading workflows at HEAD: %s", err)
+		return rule.head
+	}
+	for _, f := range fs {
+		if !isStagedWorkflowFile(f) {
+			continue
+		}
+		b, err := g.ReadFileAtHead(f)
+		if err != nil {
+			continue
+		}
+		w, _ := Parse(b)
+		if w == nil {
+			continue
+		}
+		n := f
+		if w.Name != nil && w.Name.Value != "" {
+			n = w.Name.Value
+		}
+		rule.head[n] = f
+	}
+	rule.Debug("Found %d workflows at HEAD", len(rule.head))
+	return rule.head
+}
@@ -0,0 +1,120 @@
+package actionlint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/execabs"
+)
+
+// Other cases are tested with testdata/projects/workflow_run
+func TestRuleWorkflowRunReadOtherWorkflowsFromProject(t *testing.T) {
+	repo := filepath.Join("testdata", "projects", "workflow_run")
+	errs := testLintProjectFiles(t, repo, ".github/workflows/deploy.yaml")
+	checkErrors(t, repo+".out", errs)
+}
+
+func TestRuleWorkflowRunIgnoreFilesOutsideWorkflowsDir(t *testing.T) {
+	errs := testLintProjectFiles(t, filepath.Join("testdata", "projects", "workflow_run"), "test/deploy.yaml")
+	if len(errs) != 0 {
+		t.Fatal("no error should be reported for workflow outside workflows directory:", errs)
+	}
+}
+
+func TestRuleWorkflowRunRenamedWorkflow(t *testing.T) {
+	if _, err := execabs.LookPath("git"); err != nil {
+		t.Skip("git command is not available")
+	}
+
+	dir := t.TempDir()
+	testStagedGit(t, dir, "init", "-q")
+	src := filepath.Join("testdata", "projects", "workflow_run", ".github", "workflows")
+	for _, n := range []string{"ci.yaml", "deploy.yaml", "nameless.yaml", "reusable.yaml"} {
+		b, err := os.ReadFile(filepath.Join(src, n))
+		if err != nil {
+			panic(err)
+		}
+		testStagedWrite(t, dir, ".github/workflows/"+n, string(b))
+	}
+	testStagedGit(t, dir, "add", "-A")
+	testStagedGit(t, dir, "commit", "-q", "-m", "init")
+
+	// Rename "CI" workflow to "Build" and move "Reusable" workflow in the same change
+	testStagedWrite(t, dir, ".github/workflows/ci.yaml", "name: Build\non: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n")
+	testStagedGit(t, dir, "mv", ".github/workflows/reusable.yaml", "reusable.yaml")
+
+	msgs := []string{}
+	for _, e := range testLintProjectFiles(t, dir, ".github/workflows/deploy.yaml") {
+		e.Filepath = filepath.ToSlash(e.Filepath)
+		msgs = append(msgs, e.Error())
+	}
+	want := []string{
+		`.github/workflows/deploy.yaml:4:9: workflow "CI" referenced by workflow_run event is renamed to "Build" in ".github/workflows/ci.yaml". update the name in "workflows" filter [workflow-run]`,
+		`.github/workflows/deploy.yaml:6:9: workflow "Reusable" referenced by workflow_run event is not found in this repository. it was defined in ".github/workflows/reusable.yaml" at HEAD but the file was removed or moved [workflow-run]`,
+		`.github/workflows/deploy.yaml:7:9: workflow "Unknown" referenced by workflow_run event is not found in this repository. "workflows" filter must be the name of workflow at "name:" or the workflow file path when "name:" is omitted [workflow-run]`,
+	}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+type testGitRepository map[string]string
+
+func (g testGitRepository) FilesAtHead(dir string) ([]string, error) {
+	fs := []string{}
+	for f := range g {
+		if strings.HasPrefix(f, dir+"/") {
+			fs = append(fs, f)
+		}
+	}
+	sort.Strings(fs)
+	return fs, nil
+}
+
+func (g testGitRepository) ReadFileAtHead(path string) ([]byte, error) {
+	if s, ok := g[path]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("%q does not exist at HEAD", path)
+}
+
+func TestRuleWorkflowRunReadHeadFromGitCapability(t *testing.T) {
+	root := filepath.Join("testdata", "projects", "workflow_run")
+	path := filepath.Join(root, ".github", "workflows", "deploy.yaml")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	w, errs := Parse(b)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	r := NewRuleWorkflowRun(&Project{root: root}, "")
+	r.SetCapabilities(&Capabilities{
+		Git: testGitRepository{
+			".github/workflows/ci.yaml":       "name: Unknown\non: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n",
+			".github/workflows/nested/x.yaml": "name: Nested\non: push\n",
+		},
+	})
+	if err := r.VisitProject([]*ProjectFile{{Path: path, Workflow: w}}); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := []string{}
+	for _, e := range r.Errs() {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{
+		`workflow "Reusable" referenced by workflow_run event is triggered only by workflow_call event. reusable workflow runs as a part of its caller workflow so it never triggers workflow_run event`,
+		`workflow "Unknown" referenced by workflow_run event is renamed to "CI" in ".github/workflows/ci.yaml". update the name in "workflows" filter`,
+	}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,33 @@
+package actionlint
+
+// RuleYAMLAnchor is a rule to check YAML anchors and aliases in workflows. The parser expands
+// aliases and merge keys so that other rules can check the expanded values, but GitHub Actions
+// itself may reject workflows which use them.
+type RuleYAMLAnchor struct {
+	RuleBase
+}
+
+// NewRuleYAMLAnchor creates a new RuleYAMLAnchor instance.
+func NewRuleYAMLAnchor() *RuleYAMLAnchor {
+	return &RuleYAMLAnchor{
+		RuleBase: RuleBase{
+			name: "yaml-anchor",
+			desc: "Checks for YAML anchors and aliases which GitHub Actions may reject",
+		},
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleYAMLAnchor) VisitWorkflowPre(n *Workflow) error {
+	if rule.config != nil && rule.config.Rules.YAMLAnchor.Disable {
+		return nil
+	}
+	for _, a := range n.Aliases {
+		rule.Errorf(
+			a.Pos,
+			"YAML alias %q is used. GitHub Actions may reject workflows using YAML anchors and aliases. disable this rule with \"yaml-anchor\" configuration when the workflow is preprocessed by some tool",
+			"*"+a.Value,
+		)
+	}
+	return nil
+}
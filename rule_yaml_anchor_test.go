@@ -0,0 +1,115 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleYAMLAnchorReportAliases(t *testing.T) {
+	src := `on: push
+env: &env
+  FOO: foo
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env: *env
+    steps:
+      - run: echo
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal("parse error:", errs)
+	}
+	if len(w.Aliases) != 1 {
+		t.Fatal("wanted one alias but got", w.Aliases)
+	}
+	a := w.Aliases[0]
+	if a.Value != "env" || a.Pos.Line != 7 || a.Pos.Col != 10 {
+		t.Fatalf("unexpected alias %q at %s", a.Value, a.Pos)
+	}
+	if w.Jobs["test"].Env == nil || w.Jobs["test"].Env.Vars["foo"] == nil {
+		t.Fatalf("alias was not expanded: %#v", w.Jobs["test"].Env)
+	}
+
+	for _, disable := range []bool{false, true} {
+		r := NewRuleYAMLAnchor()
+		cfg := &Config{}
+		cfg.Rules.YAMLAnchor.Disable = disable
+		r.SetConfig(cfg)
+		if err := r.VisitWorkflowPre(w); err != nil {
+			t.Fatal(err)
+		}
+		errs := r.Errs()
+		if disable && len(errs) > 0 {
+			t.Errorf("no error was expected when the rule is disabled but got %v", errs)
+		}
+		if !disable && len(errs) != 1 {
+			t.Errorf("one error was expected but got %v", errs)
+		}
+	}
+}
+
+func TestParseRecursiveAlias(t *testing.T) {
+	src := `on: push
+jobs:
+  test: &job
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+    foo: *job
+`
+	_, errs := Parse([]byte(src))
+	for _, err := range errs {
+		if err.Line == 7 && err.Column == 10 {
+			return
+		}
+	}
+	t.Fatal("recursive alias was not reported:", errs)
+}
+
+func TestParseAliasBomb(t *testing.T) {
+	src := `on: push
+x0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol, lol]
+x1: &a1 [*a0, *a0, *a0, *a0, *a0, *a0, *a0, *a0, *a0, *a0]
+x2: &a2 [*a1, *a1, *a1, *a1, *a1, *a1, *a1, *a1, *a1, *a1]
+x3: &a3 [*a2, *a2, *a2, *a2, *a2, *a2, *a2, *a2, *a2, *a2]
+x4: &a4 [*a3, *a3, *a3, *a3, *a3, *a3, *a3, *a3, *a3, *a3]
+x5: &a5 [*a4, *a4, *a4, *a4, *a4, *a4, *a4, *a4, *a4, *a4]
+x6: &a6 [*a5, *a5, *a5, *a5, *a5, *a5, *a5, *a5, *a5, *a5]
+x7: &a7 [*a6, *a6, *a6, *a6, *a6, *a6, *a6, *a6, *a6, *a6]
+x8: &a8 [*a7, *a7, *a7, *a7, *a7, *a7, *a7, *a7, *a7, *a7]
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+    foo: *a8
+`
+	_, errs := Parse([]byte(src))
+	found := 0
+	for _, err := range errs {
+		if strings.Contains(err.Message, "too many nodes are expanded by aliases") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Fatal("expanding too many aliases was not reported once:", errs)
+	}
+
+	// Aliases in usual workflows are expanded
+	src = `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - &step
+        run: echo
+` + strings.Repeat("      - *step\n", 1000)
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal("unexpected errors:", errs)
+	}
+	if n := len(w.Jobs["test"].Steps); n != 1001 {
+		t.Fatal("aliases were not expanded:", n)
+	}
+}
@@ -0,0 +1,329 @@
+package actionlint
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// scriptPositions maps byte offsets in a script at "run:" to positions in the workflow source.
+// YAML strips indentation of block scalars, folds lines, and unescapes quoted strings. So offsets
+// in the script are not simply related to the positions in the source. The i-th element is the
+// position of the i-th byte of the script.
+type scriptPositions []Pos
+
+// newScriptPositions builds the positions of the script in the string node by decoding the YAML
+// scalar in the source again. It returns nil when the decoded string does not match to the value
+// of the node. For example, when the scalar has a tag like "!!str" or an explicit indentation
+// indicator.
+func newScriptPositions(lines sourceLines, s *String) scriptPositions {
+	if len(lines) == 0 || s == nil || s.Value == "" {
+		return nil
+	}
+	start := lines.valueStart(s.Pos)
+	if start == nil || start.Line > len(lines) {
+		return nil
+	}
+
+	d := &scriptDecoder{lines: lines, value: s.Value}
+	switch lines.charAt(start) {
+	case '|':
+		d.block(start, false)
+	case '>':
+		d.block(start, true)
+	case '\'', '"':
+		d.flow(start, lines.charAt(start))
+	default:
+		d.flow(start, 0)
+	}
+
+	if strings.TrimRight(string(d.out), "\n") != strings.TrimRight(s.Value, "\n") {
+		return nil
+	}
+	return d.pos
+}
+
+// at returns the position of the byte offset in the script. When the offset is at the end of the
+// script, the position next to the last character is returned.
+func (ps scriptPositions) at(offset int) *Pos {
+	if offset < 0 || len(ps) == 0 {
+		return nil
+	}
+	if offset >= len(ps) {
+		p := ps[len(ps)-1]
+		return &Pos{Line: p.Line, Col: p.Col + 1}
+	}
+	p := ps[offset]
+	return &p
+}
+
+type scriptDecoder struct {
+	lines sourceLines
+	value string
+	out   []byte
+	pos   []Pos
+}
+
+func (d *scriptDecoder) emit(r rune, p Pos) {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	d.out = append(d.out, b[:n]...)
+	for i := 0; i < n; i++ {
+		d.pos = append(d.pos, p)
+	}
+}
+
+// block decodes the literal (|) or folded (>) block scalar whose header is at the position.
+func (d *scriptDecoder) block(header *Pos, folded bool) {
+	indent := d.lines.blockIndent(header.Line)
+	if indent == 0 {
+		return
+	}
+
+	var end Pos // Position of the line break after the last content line
+	prev, prevMore, empties := false, false, 0
+	for l := header.Line; l < len(d.lines); l++ {
+		line := d.lines[l]
+		t := strings.TrimLeft(line, " ")
+		if t == "" {
+			empties++
+			continue
+		}
+		if len(line)-len(t) < indent {
+			break
+		}
+		text := line[indent:]
+		more := text[0] == ' ' || text[0] == '\t'
+
+		// Folded block scalar replaces a line break between normal lines with a space and removes
+		// the first line break before empty lines. More-indented lines are kept as-is.
+		// https://yaml.org/spec/1.2.2/#813-folded-style
+		breaks := empties
+		if prev {
+			breaks++
+			if folded && !prevMore && !more {
+				breaks--
+				if empties == 0 {
+					d.emit(' ', end)
+				}
+			}
+		}
+		for i := 0; i < breaks; i++ {
+			d.emit('\n', end)
+		}
+
+		col := indent + 1
+		for _, r := range text {
+			d.emit(r, Pos{Line: l + 1, Col: col})
+			col++
+		}
+		end = Pos{Line: l + 1, Col: col}
+		prev, prevMore, empties = true, more, 0
+	}
+}
+
+var yamlEscapes = map[rune]rune{
+	'0':  0,
+	'a':  '\a',
+	'b':  '\b',
+	't':  '\t',
+	'\t': '\t',
+	'n':  '\n',
+	'v':  '\v',
+	'f':  '\f',
+	'r':  '\r',
+	'e':  0x1b,
+	' ':  ' ',
+	'"':  '"',
+	'/':  '/',
+	'\\': '\\',
+	'N':  0x85,
+	'_':  0xa0,
+	'L':  0x2028,
+	'P':  0x2029,
+}
+
+// flow decodes the plain, single-quoted, or double-quoted scalar starting at the position. The
+// quote is 0 for plain scalar.
+func (d *scriptDecoder) flow(start *Pos, quote rune) {
+	l := start.Line - 1
+	rs := []rune(d.lines[l])
+	i := start.Col - 1
+	if quote != 0 {
+		i++
+	}
+
+	type space struct {
+		r rune
+		p Pos
+	}
+	var spaces []space // Trailing spaces of lines are removed
+	flush := func() {
+		for _, s := range spaces {
+			d.emit(s.r, s.p)
+		}
+		spaces = spaces[:0]
+	}
+
+	for {
+		if quote == 0 && len(d.out) >= len(d.value) {
+			return // End of plain scalar
+		}
+
+		if i >= len(rs) {
+			// Line break is folded into a space. When empty lines follow, the line break is removed
+			// and each empty line is a line break.
+			spaces = spaces[:0]
+			end := Pos{Line: l + 1, Col: len(rs) + 1}
+			l++
+			empties := 0
+			for l < len(d.lines) && strings.TrimSpace(d.lines[l]) == "" {
+				empties++
+				l++
+			}
+			if l >= len(d.lines) {
+				return
+			}
+			rs = []rune(d.lines[l])
+			i = skipSpaces(rs, 0)
+			if empties == 0 {
+				d.emit(' ', end)
+			}
+			for j := 0; j < empties; j++ {
+				d.emit('\n', end)
+			}
+			continue
+		}
+
+		r := rs[i]
+		p := Pos{Line: l + 1, Col: i + 1}
+		switch {
+		case quote == '\'' && r == '\'':
+			if i+1 < len(rs) && rs[i+1] == '\'' {
+				flush()
+				d.emit('\'', p)
+				i += 2
+				continue
+			}
+			flush()
+			return
+		case quote == '"' && r == '"':
+			flush()
+			return
+		case quote == '"' && r == '\\':
+			flush()
+			if i+1 >= len(rs) {
+				// Escaped line break continues the line without a space
+				l++
+				if l >= len(d.lines) {
+					return
+				}
+				rs = []rune(d.lines[l])
+				i = skipSpaces(rs, 0)
+				continue
+			}
+			e := rs[i+1]
+			i += 2
+			if c, ok := yamlEscapes[e]; ok {
+				d.emit(c, p)
+				continue
+			}
+			n := 0
+			switch e {
+			case 'x':
+				n = 2
+			case 'u':
+				n = 4
+			case 'U':
+				n = 8
+			default:
+				return // Invalid escape
+			}
+			if i+n > len(rs) {
+				return
+			}
+			c, err := strconv.ParseUint(string(rs[i:i+n]), 16, 32)
+			if err != nil {
+				return
+			}
+			d.emit(rune(c), p)
+			i += n
+			continue
+		case r == ' ' || r == '\t':
+			spaces = append(spaces, space{r, p})
+			i++
+			continue
+		}
+
+		flush()
+		d.emit(r, p)
+		i++
+	}
+}
+
+func skipSpaces(rs []rune, i int) int {
+	for i < len(rs) && (rs[i] == ' ' || rs[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// scriptLineOffset returns the byte offset of the start of the 1-based line in the script. It
+// returns -1 when the line is out of the script.
+func scriptLineOffset(src string, line int) int {
+	if line <= 0 {
+		return -1
+	}
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset
+}
+
+// scriptLocation locates the positions in the workflow source from the offsets in a script at
+// "run:" reported by external commands like shellcheck. When the precise position is not
+// available, the position of "run:" is used instead.
+type scriptLocation struct {
+	// src is the script passed to the external command. Its byte offsets are the same as the
+	// script at "run:" since placeholders of expressions are replaced with the same number of
+	// underscores.
+	src  string
+	poss scriptPositions
+	run  *Pos
+}
+
+func newScriptLocation(lines sourceLines, s *String, src string, run *Pos) *scriptLocation {
+	return &scriptLocation{src, newScriptPositions(lines, s), run}
+}
+
+// at returns the position of the 1-based line and the 1-based column in bytes of the script.
+// Columns beyond the end of the line are clamped to the end.
+func (l *scriptLocation) at(line, col int) *Pos {
+	offset := scriptLineOffset(l.src, line)
+	if offset < 0 {
+		return l.run
+	}
+	end := len(l.src)
+	if i := strings.IndexByte(l.src[offset:], '\n'); i >= 0 {
+		end = offset + i
+	}
+	if col > 1 {
+		offset += col - 1
+	}
+	if offset > end {
+		offset = end
+	}
+	return l.atOffset(offset)
+}
+
+func (l *scriptLocation) atOffset(offset int) *Pos {
+	if p := l.poss.at(offset); p != nil {
+		return p
+	}
+	return l.run
+}
@@ -0,0 +1,139 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScriptPositionsOfRunScripts(t *testing.T) {
+	tests := []struct {
+		what string
+		run  string
+		// Offset of the first occurrence of this text in the script is mapped to the position
+		find string
+		want Pos
+	}{
+		{
+			what: "plain",
+			run:  "run: echo $FOO",
+			find: "$FOO",
+			want: Pos{Line: 6, Col: 19},
+		},
+		{
+			what: "plain multi-line",
+			run:  "run: echo foo\n          bar $FOO",
+			find: "$FOO",
+			want: Pos{Line: 7, Col: 15},
+		},
+		{
+			what: "single-quoted",
+			run:  "run: 'echo ''foo'' $FOO'",
+			find: "$FOO",
+			want: Pos{Line: 6, Col: 28},
+		},
+		{
+			what: "double-quoted with escapes",
+			run:  `run: "echo \"foo\" é $FOO"`,
+			find: "$FOO",
+			want: Pos{Line: 6, Col: 30},
+		},
+		{
+			what: "double-quoted with line continuation",
+			run:  "run: \"echo foo \\\n          bar $FOO\"",
+			find: "$FOO",
+			want: Pos{Line: 7, Col: 15},
+		},
+		{
+			what: "literal block",
+			run:  "run: |\n          echo foo\n\n            echo $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 9, Col: 18},
+		},
+		{
+			what: "literal block with anchor and chomping",
+			run:  "run: &script |-\n          echo foo\n          echo $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 8, Col: 16},
+		},
+		{
+			what: "folded block",
+			run:  "run: >\n          echo foo\n          bar\n\n          echo $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 10, Col: 16},
+		},
+		{
+			what: "folded block with more-indented lines",
+			run:  "run: >\n          echo foo\n            bar\n          echo $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 9, Col: 16},
+		},
+		{
+			what: "expression is kept",
+			run:  "run: |\n          echo ${{ 'é' }} $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 7, Col: 27},
+		},
+		{
+			what: "multi-line expression",
+			run:  "run: |\n          echo ${{\n            'foo' }} $FOO\n",
+			find: "$FOO",
+			want: Pos{Line: 8, Col: 22},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - " + tc.run + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			run := w.Jobs["test"].Steps[0].Exec.(*ExecRun).Run
+			ps := newScriptPositions(splitLines(src), run)
+			if ps == nil {
+				t.Fatalf("positions were not built for %q", run.Value)
+			}
+			s := sanitizeExpressionsInScript(run.Value)
+			i := strings.Index(s, tc.find)
+			if i < 0 {
+				t.Fatalf("%q is not found in %q", tc.find, s)
+			}
+			if have := ps.at(i); *have != tc.want {
+				t.Fatalf("wanted position %s but got %s", tc.want.String(), have.String())
+			}
+		})
+	}
+}
+
+func TestScriptPositionsNotAvailable(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: !!str echo $FOO\n"
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	run := w.Jobs["test"].Steps[0].Exec.(*ExecRun).Run
+	if ps := newScriptPositions(splitLines(src), run); ps != nil {
+		t.Fatal("positions should not be available for tagged string:", ps)
+	}
+	if ps := newScriptPositions(nil, run); ps != nil {
+		t.Fatal("positions should not be available without source:", ps)
+	}
+
+	loc := newScriptLocation(nil, run, run.Value, &Pos{Line: 6, Col: 9})
+	if p := loc.at(1, 6); *p != (Pos{Line: 6, Col: 9}) {
+		t.Fatal("position of run: should be used but got", p)
+	}
+}
+
+func TestScriptPositionsShellcheckColumnWithTab(t *testing.T) {
+	src := "a\n\tb\tc"
+	if have, want := shellcheckOffset(src, 2, 9), strings.Index(src, "b"); have != want {
+		t.Errorf("wanted offset %d but got %d", want, have)
+	}
+	if have, want := shellcheckOffset(src, 2, 17), strings.Index(src, "c"); have != want {
+		t.Errorf("wanted offset %d but got %d", want, have)
+	}
+	if have := shellcheckOffset(src, 3, 1); have != -1 {
+		t.Errorf("wanted -1 for line out of script but got %d", have)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rhysd/actionlint"
+)
+
+var dbg = log.New(io.Discard, "", log.LstdFlags)
+
+func generate(dir, version, schema string) error {
+	sets, err := actionlint.EmbeddedDatasets()
+	if err != nil {
+		return err
+	}
+
+	if schema != "" {
+		b, err := os.ReadFile(schema)
+		if err != nil {
+			return fmt.Errorf("could not read workflow schema: %w", err)
+		}
+		sets[filepath.Base(actionlint.WorkflowSchemaURL)] = b
+	}
+
+	m, err := actionlint.WriteDatasets(dir, version, sets)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(m.Datasets))
+	for n := range m.Datasets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		dbg.Printf("Wrote dataset %q to %s (sha256: %s)", n, filepath.Join(dir, m.Datasets[n].URL), m.Datasets[n].SHA256)
+	}
+
+	return nil
+}
+
+func run(args []string, stdout, stderr, dbgout io.Writer) int {
+	dbg.SetOutput(dbgout)
+
+	flags := flag.NewFlagSet("generate-data-manifest", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	version := flags.String("version", actionlint.EmbeddedDataVersion, "Version of the datasets written to the manifest")
+	schema := flags.String("schema", "", "Path to JSON schema of workflow files to include in the datasets")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: generate-data-manifest [-version VERSION] [-schema FILE] [dir]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 1
+	}
+
+	dir := "data"
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	dbg.Println("Start generate-data-manifest script")
+
+	if err := generate(dir, *version, *schema); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "Wrote datasets version %s to %s\n", *version, dir)
+	dbg.Println("Done generate-data-manifest script successfully")
+
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Stderr))
+}
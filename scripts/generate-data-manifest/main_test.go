@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testRunMain(args []string) (string, string, int) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	status := run(args, stdout, stderr, io.Discard)
+	return stdout.String(), stderr.String(), status
+}
+
+func TestOKWriteDatasets(t *testing.T) {
+	dir := t.TempDir()
+	stdout, stderr, status := testRunMain([]string{"-version", "2099-01-01", dir})
+	if status != 0 {
+		t.Fatalf("status was non-zero: %d: %q", status, stderr)
+	}
+	if !strings.Contains(stdout, "Wrote datasets version 2099-01-01") {
+		t.Fatalf("unexpected stdout: %q", stdout)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Version  string `json:"version"`
+		Datasets map[string]struct {
+			URL string `json:"url"`
+		} `json:"datasets"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "2099-01-01" {
+		t.Fatalf("unexpected version %q", m.Version)
+	}
+	for _, n := range []string{"popular-actions", "runner-labels", "webhooks", "runner-tools", "limits"} {
+		e, ok := m.Datasets[n]
+		if !ok {
+			t.Errorf("dataset %q is missing in manifest: %s", n, b)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, e.URL)); err != nil {
+			t.Errorf("file of dataset %q was not written: %s", n, err)
+		}
+	}
+	if _, ok := m.Datasets["workflow-schema"]; ok {
+		t.Error("workflow schema should not be included without -schema")
+	}
+}
+
+func TestOKWriteDatasetsWithSchema(t *testing.T) {
+	dir := t.TempDir()
+	schema := filepath.Join("..", "generate-workflow-keys", "testdata", "ok.json")
+	_, stderr, status := testRunMain([]string{"-schema", schema, dir})
+	if status != 0 {
+		t.Fatalf("status was non-zero: %d: %q", status, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "github-workflow.json")); err != nil {
+		t.Fatal("workflow schema was not written:", err)
+	}
+}
+
+func TestErrTooManyArgs(t *testing.T) {
+	_, stderr, status := testRunMain([]string{"a", "b"})
+	if status == 0 {
+		t.Fatal("status was zero")
+	}
+	if !strings.Contains(stderr, "usage: generate-data-manifest") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+}
+
+func TestErrBrokenSchema(t *testing.T) {
+	schema := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schema, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "data")
+	_, stderr, status := testRunMain([]string{"-schema", schema, dir})
+	if status == 0 {
+		t.Fatal("status was zero")
+	}
+	if stderr == "" {
+		t.Fatal("stderr is empty")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Fatal("nothing should be written on error")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/rhysd/actionlint"
+)
+
+var dbg = log.New(io.Discard, "", log.LstdFlags)
+
+func generate(schema []byte, out io.Writer) error {
+	keys, err := actionlint.ParseWorkflowSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	secs := make([]string, 0, len(keys))
+	for s := range keys {
+		secs = append(secs, s)
+	}
+	sort.Strings(secs)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `// Code generated by actionlint/scripts/generate-workflow-keys. DO NOT EDIT.
+
+package actionlint
+
+// AllWorkflowKeys is a table of allowed keys of sections in workflow files. This variable was
+// generated by script at ./scripts/generate-workflow-keys based on the JSON schema at
+// %s
+var AllWorkflowKeys = map[string][]string{
+`, actionlint.WorkflowSchemaURL)
+
+	for _, s := range secs {
+		dbg.Printf("Section %q has %d keys", s, len(keys[s]))
+		fmt.Fprintf(buf, "%q: {", s)
+		for i, k := range keys[s] {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(buf, "%q", k)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not format Go source: %w", err)
+	}
+
+	if _, err := out.Write(src); err != nil {
+		return fmt.Errorf("could not write output: %w", err)
+	}
+
+	return nil
+}
+
+func fetch(url string) ([]byte, error) {
+	var c http.Client
+
+	dbg.Println("Fetching", url)
+
+	res, err := c.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", url, err)
+	}
+	if res.StatusCode < 200 || 300 <= res.StatusCode {
+		return nil, fmt.Errorf("request was not successful for %s: %s", url, res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch body for %s: %w", url, err)
+	}
+	res.Body.Close()
+
+	dbg.Printf("Fetched %d bytes from %s", len(body), url)
+	return body, nil
+}
+
+func run(args []string, stdout, stderr, dbgout io.Writer, srcURL string) int {
+	dbg.SetOutput(dbgout)
+
+	if len(args) > 2 {
+		fmt.Fprintln(stderr, "usage: generate-workflow-keys [[srcfile] dstfile]")
+		return 1
+	}
+
+	dbg.Println("Start generate-workflow-keys script")
+
+	var src []byte
+	var err error
+	if len(args) == 2 {
+		src, err = os.ReadFile(args[0])
+	} else {
+		src, err = fetch(srcURL)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var out io.Writer
+	var dst string
+	if len(args) == 0 || args[len(args)-1] == "-" {
+		out = stdout
+		dst = "stdout"
+	} else {
+		n := args[len(args)-1]
+		f, err := os.Create(n)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+		dst = n
+	}
+
+	if err := generate(src, out); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	dbg.Println("Wrote output to", dst)
+	dbg.Println("Done generate-workflow-keys script successfully")
+
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Stderr, actionlint.WorkflowSchemaURL))
+}
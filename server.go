@@ -0,0 +1,546 @@
+package actionlint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxServerArchiveSize = 100 << 20 // Max size of request body and each file in archive
+	maxServerExtractSize = 500 << 20 // Max total size of extracted files
+)
+
+// CheckRunAnnotation is an annotation of a check run in GitHub Checks API.
+// https://docs.github.com/en/rest/checks/runs#create-a-check-run
+type CheckRunAnnotation struct {
+	// Path is a slash-separated file path relative to the repository root.
+	Path string `json:"path"`
+	// StartLine is a line number where the annotation starts.
+	StartLine int `json:"start_line"`
+	// EndLine is a line number where the annotation ends.
+	EndLine int `json:"end_line"`
+	// StartColumn is a column number where the annotation starts.
+	StartColumn int `json:"start_column,omitempty"`
+	// EndColumn is a column number where the annotation ends.
+	EndColumn int `json:"end_column,omitempty"`
+	// AnnotationLevel is a level of the annotation. actionlint always uses "failure".
+	AnnotationLevel string `json:"annotation_level"`
+	// Message is a message of the annotation.
+	Message string `json:"message"`
+	// Title is a title of the annotation. It is a rule name and an error code.
+	Title string `json:"title,omitempty"`
+}
+
+// CheckRunOutput is an output of a check run in GitHub Checks API.
+type CheckRunOutput struct {
+	// Title is a title of the check run.
+	Title string `json:"title"`
+	// Summary is a summary of the check run in Markdown format.
+	Summary string `json:"summary"`
+	// Annotations is a list of annotations of errors.
+	Annotations []*CheckRunAnnotation `json:"annotations"`
+}
+
+// NewCheckRunOutput converts the errors into the output of a check run. The summary is a table of
+// numbers of errors aggregated by rules.
+func NewCheckRunOutput(errs []*Error) *CheckRunOutput {
+	annots := make([]*CheckRunAnnotation, 0, len(errs))
+	for _, err := range errs {
+		t := err.Kind
+		if c := err.Code(); c != "" {
+			t = fmt.Sprintf("%s (%s)", err.Kind, c)
+		}
+		annots = append(annots, &CheckRunAnnotation{
+			Path:            filepath.ToSlash(err.Filepath),
+			StartLine:       err.Line,
+			EndLine:         err.Line,
+			StartColumn:     err.Column,
+			EndColumn:       err.Column,
+			AnnotationLevel: "failure",
+			Message:         err.Message,
+			Title:           t,
+		})
+	}
+
+	if len(errs) == 0 {
+		return &CheckRunOutput{"actionlint found no error", "No error was found in workflows.", annots}
+	}
+
+	r := NewReport(errs, ReportGroupByRule)
+	var b strings.Builder
+	fmt.Fprintf(&b, "actionlint found %s in %s by %s.\n\n", pluralize(r.Total, "error"), pluralize(r.Files, "file"), pluralize(r.Rules, "rule"))
+	b.WriteString("| Rule | Errors | Files |\n|-|-|-|\n")
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "| `%s` | %d | %d |\n", g.Key, len(g.Errors), len(g.Breakdown))
+	}
+	return &CheckRunOutput{fmt.Sprintf("actionlint found %s", pluralize(len(errs), "error")), b.String(), annots}
+}
+
+// ServerResult is a response of the server. It is a conclusion and an output of a check run.
+type ServerResult struct {
+	// Conclusion is "success" when no error was found, otherwise "failure".
+	Conclusion string `json:"conclusion"`
+	// Output is an output of the check run.
+	Output *CheckRunOutput `json:"output"`
+	// CheckRunID is an ID of the check run created by the webhook. This is zero for "/lint" endpoint.
+	CheckRunID int64 `json:"check_run_id,omitempty"`
+}
+
+// ServerOptions is a set of options for Server.
+type ServerOptions struct {
+	// Linter is options of linters to check workflows.
+	Linter LinterOptions
+	// WebhookSecret is a secret to verify signatures of webhook payloads. When this value is empty,
+	// "/webhook" endpoint is disabled.
+	WebhookSecret string
+	// LintToken is a token which clients of "/lint" endpoint must send in Authorization header as
+	// "Bearer {token}". When this value is empty, "/lint" endpoint is disabled. At least one of
+	// WebhookSecret and LintToken must be set.
+	LintToken string
+	// Token is a token to access GitHub API on handling webhooks. This is not used when AppID is set.
+	Token string
+	// AppID is an ID of GitHub App. When this value is not zero, an installation access token of the
+	// app is used to access GitHub API on handling webhooks.
+	AppID int64
+	// AppPrivateKey is a private key of GitHub App in PEM format.
+	AppPrivateKey []byte
+	// LogWriter is a writer to output logs of the server. When this value is nil, logs are not output.
+	LogWriter io.Writer
+}
+
+// Server is an HTTP server to run actionlint as a centralized service. It lints workflows in
+// repository archives and returns the errors as annotations in the format of GitHub Checks API.
+// Config files in the archives are not used since they are sent by clients. Only the config file
+// given by ConfigFile of the linter options is used.
+// It provides the following endpoints:
+//
+//   - POST /lint: Lints a repository archive (tar, tar.gz, or zip) in the request body.
+//   - POST /webhook: Handles "check_suite" webhook of GitHub. It downloads the archive of the
+//     commit, lints it, and creates a check run with the annotations.
+//   - GET /healthz: Returns 200 for health checks.
+type Server struct {
+	opts   ServerOptions
+	appKey *rsa.PrivateKey
+	apiURL string
+	log    io.Writer
+}
+
+// NewServer creates a new Server instance.
+func NewServer(opts *ServerOptions) (*Server, error) {
+	if opts.WebhookSecret == "" && opts.LintToken == "" {
+		return nil, errors.New("secret of webhooks or token of \"/lint\" endpoint must be set to start the server so that it does not lint archives sent by anyone")
+	}
+	s := &Server{opts: *opts, apiURL: githubAPIURL, log: opts.LogWriter}
+	if s.log == nil {
+		s.log = io.Discard
+	}
+	s.opts.Linter.Color = ColorOptionKindNever
+	s.opts.Linter.Format = ""
+	s.opts.Linter.GroupBy = ReportGroupByNone
+	// Commands in config files of linted repositories are never run
+	s.opts.Linter.AllowConfigCommands = false
+
+	if opts.AppID != 0 {
+		k, err := parseGitHubAppKey(opts.AppPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		s.appKey = k
+	}
+	return s, nil
+}
+
+func parseGitHubAppKey(b []byte) (*rsa.PrivateKey, error) {
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, errors.New("private key of GitHub App is not in PEM format")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(p.Bytes); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(p.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key of GitHub App: %w", err)
+	}
+	rk, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key of GitHub App must be RSA key")
+	}
+	return rk, nil
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.log, "[%s] %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// ServeHTTP implements http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		w.Write([]byte("ok\n"))
+	case "/lint":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleLint(w, r)
+	case "/webhook":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleWebhook(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) respond(w http.ResponseWriter, res *ServerResult) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logf("could not write response: %s", err)
+	}
+}
+
+func (s *Server) handleLint(w http.ResponseWriter, r *http.Request) {
+	if s.opts.LintToken == "" {
+		http.Error(w, "\"/lint\" endpoint is disabled since its token is not set", http.StatusForbidden)
+		return
+	}
+	if !verifyBearerToken(r.Header.Get("Authorization"), s.opts.LintToken) {
+		http.Error(w, "token in Authorization header is invalid", http.StatusUnauthorized)
+		return
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxServerArchiveSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read request body: %s", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+	errs, err := s.lintArchive(b)
+	if err != nil {
+		s.logf("could not lint archive: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logf("linted archive from %s: %d errors", r.RemoteAddr, len(errs))
+	s.respond(w, newServerResult(errs))
+}
+
+func newServerResult(errs []*Error) *ServerResult {
+	c := "success"
+	if len(errs) > 0 {
+		c = "failure"
+	}
+	return &ServerResult{Conclusion: c, Output: NewCheckRunOutput(errs)}
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxServerArchiveSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read request body: %s", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if s.opts.WebhookSecret == "" {
+		http.Error(w, "\"/webhook\" endpoint is disabled since its secret is not set", http.StatusForbidden)
+		return
+	}
+	if !verifyWebhookSignature(b, s.opts.WebhookSecret, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "signature of webhook payload is invalid", http.StatusUnauthorized)
+		return
+	}
+
+	switch ev := r.Header.Get("X-GitHub-Event"); ev {
+	case "ping":
+		w.Write([]byte("pong\n"))
+		return
+	case "check_suite":
+	default:
+		s.logf("ignored %q event", ev)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload struct {
+		Action     string `json:"action"`
+		CheckSuite struct {
+			HeadSHA string `json:"head_sha"`
+		} `json:"check_suite"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Installation struct {
+			ID int64 `json:"id"`
+		} `json:"installation"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		http.Error(w, fmt.Sprintf("could not parse webhook payload: %s", err), http.StatusBadRequest)
+		return
+	}
+	if payload.Action != "requested" && payload.Action != "rerequested" {
+		s.logf("ignored %q action of check_suite event", payload.Action)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	repo, sha := payload.Repository.FullName, payload.CheckSuite.HeadSHA
+	if repo == "" || sha == "" {
+		http.Error(w, "repository or head commit is missing in webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	res, err := s.checkSuite(repo, sha, payload.Installation.ID)
+	if err != nil {
+		s.logf("could not check %s at %s: %s", repo, sha, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	s.logf("created check run %d for %s at %s: %s", res.CheckRunID, repo, sha, res.Conclusion)
+	s.respond(w, res)
+}
+
+func (s *Server) checkSuite(repo, sha string, installation int64) (*ServerResult, error) {
+	token := s.opts.Token
+	if s.appKey != nil {
+		t, err := s.installationToken(installation)
+		if err != nil {
+			return nil, err
+		}
+		token = t
+	}
+	c := NewGitHubClient(token)
+	c.baseURL = s.apiURL
+
+	b, err := c.Tarball(repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := s.lintArchive(b)
+	if err != nil {
+		return nil, err
+	}
+	res := newServerResult(errs)
+	id, err := c.CreateCheckRun(repo, sha, res.Conclusion, res.Output)
+	if err != nil {
+		return nil, err
+	}
+	res.CheckRunID = id
+	return res, nil
+}
+
+func verifyBearerToken(header, token string) bool {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header[len("Bearer "):]), []byte(token)) == 1
+}
+
+func verifyWebhookSignature(payload []byte, secret, sig string) bool {
+	if !strings.HasPrefix(sig, "sha256=") {
+		return false
+	}
+	want, err := hex.DecodeString(sig[len("sha256="):])
+	if err != nil {
+		return false
+	}
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(payload)
+	return hmac.Equal(m.Sum(nil), want)
+}
+
+// installationToken creates an installation access token of the GitHub App. A JSON Web Token signed
+// with the private key of the app is used for the authentication.
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation
+func (s *Server) installationToken(installation int64) (string, error) {
+	if installation == 0 {
+		return "", errors.New("installation ID is missing in webhook payload")
+	}
+	jwt, err := s.appJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	c := NewGitHubClient(jwt)
+	c.baseURL = s.apiURL
+	p := fmt.Sprintf("/app/installations/%d/access_tokens", installation)
+	status, body, err := c.request(http.MethodPost, p, nil)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusCreated {
+		return "", fmt.Errorf("could not create installation access token for installation %d: status %d", installation, status)
+	}
+	var res struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", fmt.Errorf("could not parse response from %s: %w", p, err)
+	}
+	return res.Token, nil
+}
+
+func (s *Server) appJWT(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	h := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	// Issued 60 seconds in the past to allow for clock drift. Expiration must be at most 10 minutes
+	c := enc.EncodeToString([]byte(fmt.Sprintf(`{"iat":%d,"exp":%d,"iss":"%d"}`, now.Add(-time.Minute).Unix(), now.Add(9*time.Minute).Unix(), s.opts.AppID)))
+	d := sha256.Sum256([]byte(h + "." + c))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.appKey, crypto.SHA256, d[:])
+	if err != nil {
+		return "", fmt.Errorf("could not sign JSON Web Token for GitHub App: %w", err)
+	}
+	return h + "." + c + "." + enc.EncodeToString(sig), nil
+}
+
+// lintArchive extracts the repository archive into a temporary directory and lints workflows in it.
+func (s *Server) lintArchive(b []byte) ([]*Error, error) {
+	d, err := os.MkdirTemp("", "actionlint-server-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(d)
+
+	if err := extractArchive(b, d); err != nil {
+		return nil, err
+	}
+
+	// Archives downloaded from GitHub contain a top-level directory like "owner-repo-sha/"
+	root := d
+	if _, err := os.Stat(filepath.Join(root, ".github", "workflows")); err != nil {
+		es, err := os.ReadDir(d)
+		if err != nil || len(es) != 1 || !es[0].IsDir() {
+			return nil, errors.New("\".github/workflows\" directory was not found in the archive")
+		}
+		root = filepath.Join(d, es[0].Name())
+		if _, err := os.Stat(filepath.Join(root, ".github", "workflows")); err != nil {
+			return nil, errors.New("\".github/workflows\" directory was not found in the archive")
+		}
+	}
+	// Archives do not contain .git directory. It is necessary to detect the project
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		return nil, fmt.Errorf("could not create .git directory: %w", err)
+	}
+
+	opts := s.opts.Linter
+	opts.WorkingDir = root
+	opts.LogWriter = nil
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if l.defaultConfig == nil {
+		// Config file in the archive is not trusted. It can configure arbitrary external commands
+		l.defaultConfig = &Config{}
+	}
+	return l.LintRepository(root)
+}
+
+type extractedFile struct {
+	path string
+	mode os.FileMode
+	body io.Reader
+}
+
+// extractArchive extracts the tar, tar.gz, or zip archive into the directory. The format is
+// detected from the content. Only regular files are extracted.
+func extractArchive(b []byte, dir string) error {
+	var files func(func(*extractedFile) error) error
+	switch {
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")):
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return fmt.Errorf("could not read zip archive: %w", err)
+		}
+		files = func(f func(*extractedFile) error) error {
+			for _, z := range zr.File {
+				if !z.Mode().IsRegular() {
+					continue
+				}
+				r, err := z.Open()
+				if err != nil {
+					return fmt.Errorf("could not read %q in zip archive: %w", z.Name, err)
+				}
+				err = f(&extractedFile{z.Name, z.Mode(), r})
+				r.Close()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		var r io.Reader = bytes.NewReader(b)
+		if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+			gr, err := gzip.NewReader(r)
+			if err != nil {
+				return fmt.Errorf("could not read gzip archive: %w", err)
+			}
+			r = gr
+		}
+		tr := tar.NewReader(r)
+		files = func(f func(*extractedFile) error) error {
+			for {
+				h, err := tr.Next()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("could not read tar archive: %w", err)
+				}
+				if h.Typeflag != tar.TypeReg {
+					continue
+				}
+				if err := f(&extractedFile{h.Name, h.FileInfo().Mode(), tr}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	total := int64(0)
+	return files(func(f *extractedFile) error {
+		p := path.Clean(strings.TrimPrefix(f.path, "./"))
+		// Backslashes and drive letters are path separators and volumes on Windows
+		if strings.ContainsRune(f.path, '\\') || strings.ContainsRune(strings.SplitN(p, "/", 2)[0], ':') ||
+			path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return fmt.Errorf("invalid file path %q in archive", f.path)
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("could not create directory for %q: %w", p, err)
+		}
+		w, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.mode.Perm()|0600)
+		if err != nil {
+			return fmt.Errorf("could not create file %q: %w", p, err)
+		}
+		n, err := io.Copy(w, io.LimitReader(f.body, maxServerArchiveSize+1))
+		w.Close()
+		if err != nil {
+			return fmt.Errorf("could not extract %q: %w", p, err)
+		}
+		total += n
+		if n > maxServerArchiveSize || total > maxServerExtractSize {
+			return errors.New("archive is too large to extract")
+		}
+		return nil
+	})
+}
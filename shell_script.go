@@ -0,0 +1,634 @@
+package actionlint
+
+import (
+	"strings"
+)
+
+// Dynamic parts of shell words such as "$FOO", "$(cmd)", and "${{ expr }}" are replaced with their
+// sources surrounded by these markers so that only static parts of the words are checked.
+const (
+	shellDynamicStart = '\x00'
+	shellDynamicEnd   = '\x01'
+)
+
+// shellWord is a word of a shell command after removing quotes and escapes.
+type shellWord struct {
+	// value is the value of the word. Its dynamic parts are surrounded by shellDynamicStart and
+	// shellDynamicEnd.
+	value string
+	// quoted is true when some part of the word is quoted.
+	quoted bool
+	// offset is a byte offset of the word in the script.
+	offset int
+}
+
+// shellRedirect is a redirection of a shell command like `>> "$GITHUB_OUTPUT"`.
+type shellRedirect struct {
+	// fd is a file descriptor number before the operator like "2" of `2>&1`. It is empty when omitted.
+	fd string
+	// op is the redirection operator like ">>" or "<<".
+	op     string
+	target *shellWord
+}
+
+// shellCommand is a simple command in a shell script.
+type shellCommand struct {
+	words     []*shellWord
+	redirects []*shellRedirect
+	// heredoc is a body of the here document given to the command via `<<`. Its dynamic parts are
+	// surrounded by the markers as well as shellWord.
+	heredoc    string
+	hasHeredoc bool
+	offset     int
+	// openGroup is true when the command starts with `{`.
+	openGroup bool
+	// closeGroup is true when the command is `}`. Its redirections are applied to all commands in
+	// the group.
+	closeGroup bool
+}
+
+// normalize removes keywords of compound commands like "then" and "do" at head of the command
+// words and marks the braces of group commands.
+func (c *shellCommand) normalize() {
+	for len(c.words) > 0 {
+		w := c.words[0]
+		if w.quoted {
+			return
+		}
+		switch w.value {
+		case "{":
+			c.openGroup = true
+		case "}":
+			c.closeGroup = true
+		case "if", "then", "elif", "else", "while", "until", "do", "!", "time":
+		default:
+			return
+		}
+		c.words = c.words[1:]
+		if len(c.words) > 0 {
+			c.offset = c.words[0].offset
+		}
+		if c.closeGroup {
+			return
+		}
+	}
+}
+
+// name returns the command name like "echo". It returns an empty string when the name is unknown.
+func (c *shellCommand) name() string {
+	if len(c.words) == 0 || isShellDynamic(c.words[0].value) {
+		return ""
+	}
+	return c.words[0].value
+}
+
+// output returns what the command writes to stdout. The second return value is false when the
+// output cannot be known statically.
+func (c *shellCommand) output() (string, bool) {
+	args := c.words
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	switch c.name() {
+	case "echo":
+		newline, escape := true, false
+		for len(args) > 0 {
+			f := args[0].value
+			if len(f) < 2 || f[0] != '-' || strings.Trim(f[1:], "neE") != "" {
+				break
+			}
+			newline = newline && !strings.Contains(f, "n")
+			escape = strings.Contains(f, "e")
+			args = args[1:]
+		}
+		ss := make([]string, 0, len(args))
+		for _, a := range args {
+			ss = append(ss, a.value)
+		}
+		s := strings.Join(ss, " ")
+		if escape {
+			s = unescapeShellString(s)
+		}
+		if newline {
+			s += "\n"
+		}
+		return s, true
+	case "printf":
+		if len(args) == 0 {
+			return "", false
+		}
+		f := args[0].value
+		if f == `%s\n` {
+			var b strings.Builder
+			for _, a := range args[1:] {
+				b.WriteString(a.value)
+				b.WriteByte('\n')
+			}
+			return b.String(), true
+		}
+		if len(args) > 1 || isShellDynamic(f) || strings.Contains(strings.ReplaceAll(f, "%%", ""), "%") {
+			return "", false
+		}
+		return strings.ReplaceAll(unescapeShellString(f), "%%", "%"), true
+	case "cat":
+		if len(args) > 0 {
+			return "", false
+		}
+		if c.hasHeredoc {
+			return c.heredoc, true
+		}
+		for _, r := range c.redirects {
+			if r.op == "<<<" {
+				return r.target.value + "\n", true
+			}
+		}
+		return "", false
+	case ":", "true":
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+// shellStatement is a pipeline of commands in a shell script like `echo foo | tee -a "$FILE"`.
+type shellStatement struct {
+	cmds []*shellCommand
+}
+
+// isShellDynamic returns true when the value of shell word contains dynamic parts.
+func isShellDynamic(s string) bool {
+	return strings.IndexByte(s, shellDynamicStart) >= 0
+}
+
+// indexShellStatic returns the index of the first occurrence of the substring in the static parts
+// of the value of shell word. It returns -1 when the substring is not found.
+func indexShellStatic(s, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == shellDynamicStart {
+			if j := strings.IndexByte(s[i:], shellDynamicEnd); j >= 0 {
+				i += j
+				continue
+			}
+			return -1
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// displayShellValue returns the value of shell word for showing it in error messages. Dynamic parts
+// are shown as their sources.
+func displayShellValue(s string) string {
+	return strings.NewReplacer(string(shellDynamicStart), "", string(shellDynamicEnd), "").Replace(s)
+}
+
+// unescapeShellString unescapes the backslash escapes like `\n` in the string as `echo -e` and
+// `printf` do.
+func unescapeShellString(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// shellHeredoc is a here document waiting for its body at the next line.
+type shellHeredoc struct {
+	cmd    *shellCommand
+	delim  string
+	tabs   bool // true for `<<-`
+	expand bool // false when the delimiter is quoted
+}
+
+// shellScriptParser is a minimal parser of POSIX shell scripts at "run:". It splits the script into
+// statements and commands to know what each command writes to which file. It does not understand
+// compound commands like `if` or `for`. Their keywords are simply skipped.
+type shellScriptParser struct {
+	src      string
+	i        int
+	heredocs []*shellHeredoc
+}
+
+// parseShellScript parses the shell script into statements.
+func parseShellScript(src string) []*shellStatement {
+	p := &shellScriptParser{src: src}
+	ret := []*shellStatement{}
+	stmt := &shellStatement{}
+	cmd := &shellCommand{offset: -1}
+
+	endCommand := func() {
+		if cmd.offset >= 0 {
+			cmd.normalize()
+			stmt.cmds = append(stmt.cmds, cmd)
+		}
+		cmd = &shellCommand{offset: -1}
+	}
+	endStatement := func() {
+		endCommand()
+		if len(stmt.cmds) > 0 {
+			ret = append(ret, stmt)
+		}
+		stmt = &shellStatement{}
+	}
+
+	for p.i < len(p.src) {
+		c := p.src[p.i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.i++
+		case c == '\\' && p.i+1 < len(p.src) && p.src[p.i+1] == '\n':
+			p.i += 2
+		case c == '#':
+			if j := strings.IndexByte(p.src[p.i:], '\n'); j >= 0 {
+				p.i += j
+			} else {
+				p.i = len(p.src)
+			}
+		case c == '\n':
+			p.i++
+			endStatement()
+			p.readHeredocs()
+		case c == ';' || c == '(' || c == ')':
+			p.i++
+			endStatement()
+		case c == '&' || c == '|':
+			if strings.HasPrefix(p.src[p.i:], "&>") {
+				p.readRedirect(cmd, "")
+				continue
+			}
+			if c == '|' && !strings.HasPrefix(p.src[p.i:], "||") {
+				p.i++
+				endCommand()
+				continue
+			}
+			p.i++
+			if p.i < len(p.src) && p.src[p.i] == c {
+				p.i++
+			}
+			endStatement()
+		case c == '<' || c == '>':
+			p.readRedirect(cmd, "")
+		default:
+			w := p.readWord()
+			if w == nil {
+				p.i++ // Unexpected character
+				continue
+			}
+			if p.i < len(p.src) && (p.src[p.i] == '<' || p.src[p.i] == '>') && !w.quoted && isShellFD(w.value) {
+				p.readRedirect(cmd, w.value)
+				continue
+			}
+			if cmd.offset < 0 {
+				cmd.offset = w.offset
+			}
+			cmd.words = append(cmd.words, w)
+		}
+	}
+	endStatement()
+	return ret
+}
+
+func isShellFD(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || '9' < r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *shellScriptParser) readRedirect(cmd *shellCommand, fd string) {
+	ops := []string{"&>>", "&>", "<<<", "<<-", "<<", "<&", "<>", "<", ">>", ">&", ">|", ">"}
+	op := ""
+	for _, o := range ops {
+		if strings.HasPrefix(p.src[p.i:], o) {
+			op = o
+			break
+		}
+	}
+	start := p.i
+	p.i += len(op)
+	for p.i < len(p.src) && (p.src[p.i] == ' ' || p.src[p.i] == '\t') {
+		p.i++
+	}
+
+	if cmd.offset < 0 {
+		cmd.offset = start
+	}
+
+	if op == "<<" || op == "<<-" {
+		begin := p.i
+		w := p.readWord()
+		if w == nil {
+			return
+		}
+		d := &shellHeredoc{cmd: cmd, tabs: op == "<<-", expand: !w.quoted}
+		// Delimiter is not expanded. Use the source with quotes removed
+		d.delim = strings.NewReplacer(`"`, "", `'`, "", `\`, "").Replace(p.src[begin:p.i])
+		cmd.hasHeredoc = true
+		p.heredocs = append(p.heredocs, d)
+		return
+	}
+
+	w := p.readWord()
+	if w == nil {
+		return
+	}
+	cmd.redirects = append(cmd.redirects, &shellRedirect{fd, op, w})
+}
+
+// readHeredocs reads bodies of here documents started at the previous line.
+func (p *shellScriptParser) readHeredocs() {
+	for _, h := range p.heredocs {
+		var b strings.Builder
+		for p.i < len(p.src) {
+			end := strings.IndexByte(p.src[p.i:], '\n')
+			next := p.i + end + 1
+			if end < 0 {
+				end = len(p.src) - p.i
+				next = len(p.src)
+			}
+			line := strings.TrimSuffix(p.src[p.i:p.i+end], "\r")
+			if h.tabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == h.delim {
+				p.i = next
+				break
+			}
+			if h.expand {
+				line = p.expandLine(p.i, line)
+			} else {
+				line = p.expandGitHubExpressions(line)
+			}
+			b.WriteString(line)
+			b.WriteByte('\n')
+			p.i = next
+		}
+		h.cmd.heredoc = b.String()
+	}
+	p.heredocs = p.heredocs[:0]
+}
+
+// expandLine replaces expansions in the line of here document with dynamic parts.
+func (p *shellScriptParser) expandLine(offset int, line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "${{"):
+			s, n := readGitHubExpression(line[i:])
+			b.WriteString(s)
+			i += n
+		case c == '$' || c == '`':
+			s, n := readShellExpansion(line[i:])
+			b.WriteString(s)
+			i += n
+		case c == '\\' && i+1 < len(line) && strings.IndexByte("$`\\", line[i+1]) >= 0:
+			b.WriteByte(line[i+1])
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func (p *shellScriptParser) expandGitHubExpressions(line string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(line, "${{")
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		s, n := readGitHubExpression(line[i:])
+		b.WriteString(s)
+		line = line[i+n:]
+	}
+}
+
+// readWord reads a shell word at the current position. It returns nil when no word is at the
+// position.
+func (p *shellScriptParser) readWord() *shellWord {
+	w := &shellWord{offset: p.i}
+	var b strings.Builder
+	start := p.i
+Loop:
+	for p.i < len(p.src) {
+		c := p.src[p.i]
+		switch {
+		case strings.IndexByte(" \t\r\n;&|()<>", c) >= 0:
+			break Loop
+		case strings.HasPrefix(p.src[p.i:], "${{"):
+			s, n := readGitHubExpression(p.src[p.i:])
+			b.WriteString(s)
+			p.i += n
+		case c == '\\':
+			if p.i+1 < len(p.src) && p.src[p.i+1] != '\n' {
+				b.WriteByte(p.src[p.i+1])
+			}
+			p.i += 2
+		case c == '\'':
+			w.quoted = true
+			p.i++
+			for p.i < len(p.src) && p.src[p.i] != '\'' {
+				if strings.HasPrefix(p.src[p.i:], "${{") {
+					s, n := readGitHubExpression(p.src[p.i:])
+					b.WriteString(s)
+					p.i += n
+					continue
+				}
+				b.WriteByte(p.src[p.i])
+				p.i++
+			}
+			p.i++
+		case c == '"':
+			w.quoted = true
+			p.i++
+			p.readDoubleQuoted(&b)
+		case c == '$' && strings.HasPrefix(p.src[p.i:], "$'"):
+			w.quoted = true
+			p.i += 2
+			var q strings.Builder
+			for p.i < len(p.src) && p.src[p.i] != '\'' {
+				if p.src[p.i] == '\\' && p.i+1 < len(p.src) {
+					q.WriteByte('\\')
+					p.i++
+				}
+				q.WriteByte(p.src[p.i])
+				p.i++
+			}
+			p.i++
+			b.WriteString(unescapeShellString(q.String()))
+		case c == '$' || c == '`':
+			s, n := readShellExpansion(p.src[p.i:])
+			b.WriteString(s)
+			p.i += n
+		default:
+			b.WriteByte(c)
+			p.i++
+		}
+	}
+	if p.i == start {
+		return nil
+	}
+	if p.i > len(p.src) {
+		p.i = len(p.src)
+	}
+	w.value = b.String()
+	return w
+}
+
+func (p *shellScriptParser) readDoubleQuoted(b *strings.Builder) {
+	for p.i < len(p.src) {
+		c := p.src[p.i]
+		switch {
+		case c == '"':
+			p.i++
+			return
+		case strings.HasPrefix(p.src[p.i:], "${{"):
+			s, n := readGitHubExpression(p.src[p.i:])
+			b.WriteString(s)
+			p.i += n
+		case c == '\\' && p.i+1 < len(p.src):
+			switch n := p.src[p.i+1]; n {
+			case '\n':
+			case '$', '`', '"', '\\':
+				b.WriteByte(n)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(n)
+			}
+			p.i += 2
+		case c == '$' || c == '`':
+			s, n := readShellExpansion(p.src[p.i:])
+			b.WriteString(s)
+			p.i += n
+		default:
+			b.WriteByte(c)
+			p.i++
+		}
+	}
+}
+
+func shellDynamic(src string) string {
+	return string(shellDynamicStart) + src + string(shellDynamicEnd)
+}
+
+// readGitHubExpression reads ${{ }} placeholder at the head of the string. It returns the dynamic
+// part and the number of bytes read.
+func readGitHubExpression(s string) (string, int) {
+	e := strings.Index(s, "}}")
+	if e < 0 {
+		return s, len(s)
+	}
+	e += 2
+	return shellDynamic(s[:e]), e
+}
+
+// readShellExpansion reads an expansion like "$FOO", "${FOO}", "$(cmd)", or "`cmd`" at the head of
+// the string. It returns the dynamic part and the number of bytes read. "$FOO" and "${FOO}" are
+// normalized to the same dynamic part.
+func readShellExpansion(s string) (string, int) {
+	if s[0] == '`' {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '`' {
+				return shellDynamic(s[:i+1]), i + 1
+			}
+		}
+		return shellDynamic(s), len(s)
+	}
+
+	if len(s) < 2 {
+		return s, len(s)
+	}
+	switch c := s[1]; {
+	case c == '{' || c == '(':
+		open, close := c, byte('}')
+		if c == '(' {
+			close = ')'
+		}
+		depth := 0
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '\'':
+				if j := strings.IndexByte(s[i+1:], '\''); j >= 0 && open == '(' {
+					i += j + 1
+				}
+			case open:
+				depth++
+			case close:
+				depth--
+				if depth == 0 {
+					src := s[:i+1]
+					if n := src[2:i]; open == '{' && isShellName(n) {
+						src = "$" + n
+					}
+					return shellDynamic(src), i + 1
+				}
+			}
+		}
+		return shellDynamic(s), len(s)
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		i := 2
+		for i < len(s) && isShellNameChar(s[i]) {
+			i++
+		}
+		return shellDynamic(s[:i]), i
+	case strings.IndexByte("0123456789@*#?$!-", c) >= 0:
+		return shellDynamic(s[:2]), 2
+	default:
+		return "$", 1
+	}
+}
+
+func isShellNameChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isShellName(s string) bool {
+	if s == "" || '0' <= s[0] && s[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isShellNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
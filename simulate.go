@@ -0,0 +1,689 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// simulateEvent is a hypothetical event to trigger workflows in simulation.
+type simulateEvent struct {
+	// name is a name of the event like "push".
+	name string
+	// ref is a Git ref like "refs/heads/main". For pull_request events, this is a base branch. Empty
+	// means filters for branches and tags are not checked.
+	ref string
+	// typ is an activity type of the event like "opened". Empty means "types:" is not checked.
+	typ string
+	// paths is a list of changed file paths. Empty means filters for paths are not checked.
+	paths []string
+	// contexts is a map from context names to their mock values used for evaluating expressions.
+	contexts map[string]any
+}
+
+// simulatedJob is a result of simulating a job.
+type simulatedJob struct {
+	id string
+	// result is "success" when the job would run, otherwise "skipped".
+	result string
+	// reason is why the job would be skipped.
+	reason string
+	// matrix is a list of combinations of matrix expanded for the job. This is nil when the job has no matrix.
+	matrix []map[string]any
+	// matrixErr is an error message when the matrix could not be expanded.
+	matrixErr string
+}
+
+// simulatedWorkflow is a result of simulating a workflow.
+type simulatedWorkflow struct {
+	triggered bool
+	// reason is why the workflow would be triggered or not.
+	reason string
+	// jobs is results of jobs in the order of running them. This is empty when the workflow is not triggered.
+	jobs []*simulatedJob
+}
+
+// defaultPullRequestTypes is a list of activity types which trigger the workflow when "types:" is
+// omitted at pull_request and pull_request_target events.
+// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request
+var defaultPullRequestTypes = []string{"opened", "synchronize", "reopened"}
+
+// simulateWorkflow simulates which jobs in the workflow would run when the event occurs. It
+// evaluates filters at "on:", "if:" conditions of jobs, and matrix expansion. All jobs which run are
+// assumed to succeed.
+func simulateWorkflow(w *Workflow, ev *simulateEvent) *simulatedWorkflow {
+	if ok, reason := simulateTrigger(w, ev); !ok {
+		return &simulatedWorkflow{triggered: false, reason: reason}
+	}
+
+	jobs := make([]*Job, 0, len(w.Jobs))
+	for _, j := range w.Jobs {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Pos.IsBefore(jobs[j].Pos)
+	})
+
+	ret := &simulatedWorkflow{
+		triggered: true,
+		reason:    fmt.Sprintf("triggered by %q event", ev.name),
+		jobs:      make([]*simulatedJob, 0, len(jobs)),
+	}
+	results := make(map[string]*simulatedJob, len(jobs))
+	visiting := map[string]struct{}{}
+	var visit func(j *Job) *simulatedJob
+	visit = func(j *Job) *simulatedJob {
+		id := strings.ToLower(j.ID.Value)
+		if r, ok := results[id]; ok {
+			return r
+		}
+		if _, ok := visiting[id]; ok {
+			return &simulatedJob{id: j.ID.Value, result: "skipped", reason: "cyclic dependency was detected at \"needs:\""}
+		}
+		visiting[id] = struct{}{}
+		needs := make([]*simulatedJob, 0, len(j.Needs))
+		for _, n := range j.Needs {
+			if d, ok := w.Jobs[strings.ToLower(n.Value)]; ok {
+				needs = append(needs, visit(d))
+			} else {
+				needs = append(needs, &simulatedJob{id: n.Value, result: "skipped"})
+			}
+		}
+		r := simulateJob(j, needs, ev)
+		delete(visiting, id)
+		results[id] = r
+		ret.jobs = append(ret.jobs, r)
+		return r
+	}
+	for _, j := range jobs {
+		visit(j)
+	}
+
+	return ret
+}
+
+// simulateTrigger checks the workflow is triggered by the event and returns the reason.
+func simulateTrigger(w *Workflow, ev *simulateEvent) (bool, string) {
+	var found Event
+	for _, e := range w.On {
+		if e.EventName() == ev.name {
+			found = e
+			break
+		}
+	}
+	if found == nil {
+		return false, fmt.Sprintf("%q event is not listed at \"on:\"", ev.name)
+	}
+	e, ok := found.(*WebhookEvent)
+	if !ok {
+		return true, ""
+	}
+
+	if ev.typ != "" {
+		types := make([]string, 0, len(e.Types))
+		for _, t := range e.Types {
+			types = append(types, t.Value)
+		}
+		if len(types) == 0 && (ev.name == "pull_request" || ev.name == "pull_request_target") {
+			types = defaultPullRequestTypes
+		}
+		if len(types) > 0 && !containsString(types, ev.typ) {
+			return false, fmt.Sprintf("activity type %q is not included in \"types:\" %s", ev.typ, quotes(types))
+		}
+	}
+
+	isTag := false
+	if ev.ref != "" {
+		name := ev.ref
+		if strings.HasPrefix(name, "refs/tags/") {
+			isTag = true
+			name = strings.TrimPrefix(name, "refs/tags/")
+		} else {
+			name = strings.TrimPrefix(name, "refs/heads/")
+		}
+
+		branches, tags := []*WebhookEventFilter{e.Branches, e.BranchesIgnore}, []*WebhookEventFilter{e.Tags, e.TagsIgnore}
+		if isTag {
+			branches, tags = tags, branches
+		}
+		// Only filters for tags are specified at push event, pushing branches does not trigger the workflow and vice versa
+		if ev.name == "push" && branches[0] == nil && branches[1] == nil && (tags[0] != nil || tags[1] != nil) {
+			kind, other := "branch", "tags"
+			if isTag {
+				kind, other = "tag", "branches"
+			}
+			return false, fmt.Sprintf("%s %q is pushed but only filters for %s are specified", kind, name, other)
+		}
+		if f := branches[0]; f != nil && !simulateMatchFilter(f, name) {
+			return false, fmt.Sprintf("%q does not match %q filter", name, f.Name.Value)
+		}
+		if f := branches[1]; f != nil && simulateMatchFilter(f, name) {
+			return false, fmt.Sprintf("%q matches %q filter", name, f.Name.Value)
+		}
+	}
+
+	// Path filters are not evaluated for pushing tags
+	if len(ev.paths) > 0 && !isTag {
+		if f := e.Paths; f != nil {
+			matched := false
+			for _, p := range ev.paths {
+				if simulateMatchFilter(f, p) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false, fmt.Sprintf("none of the changed paths matches %q filter", f.Name.Value)
+			}
+		}
+		if f := e.PathsIgnore; f != nil {
+			ignored := true
+			for _, p := range ev.paths {
+				if !simulateMatchFilter(f, p) {
+					ignored = false
+					break
+				}
+			}
+			if ignored {
+				return false, fmt.Sprintf("all the changed paths match %q filter", f.Name.Value)
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// simulateMatchFilter returns true when the value matches the filter. When multiple patterns match
+// to the value, the last one takes precedence. A pattern starting with '!' excludes the value.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet
+func simulateMatchFilter(f *WebhookEventFilter, v string) bool {
+	matched := false
+	for _, p := range f.Values {
+		pat := p.Value
+		neg := strings.HasPrefix(pat, "!")
+		if neg {
+			pat = pat[1:]
+		}
+		r, err := compileGlob(pat)
+		if err != nil {
+			continue // Invalid glob pattern is reported by "glob" rule
+		}
+		if r.MatchString(v) {
+			matched = !neg
+		}
+	}
+	return matched
+}
+
+func simulateJob(j *Job, needs []*simulatedJob, ev *simulateEvent) *simulatedJob {
+	ret := &simulatedJob{id: j.ID.Value, result: "skipped"}
+
+	// Status of the job is determined by results of the jobs in "needs:"
+	status := "success"
+	var blocker *simulatedJob
+	for _, n := range needs {
+		if n.result == "success" {
+			continue
+		}
+		if blocker == nil || (n.result == "failure" && status != "failure") {
+			blocker = n
+			status = n.result
+		}
+	}
+	if status != "success" && status != "failure" {
+		status = "skipped"
+	}
+
+	ctx := simulateContexts(ev, needs, status)
+	if j.If != nil {
+		cond := strings.TrimSpace(j.If.Value)
+		v, err := EvalExpression(cond, ctx)
+		if err != nil {
+			ret.reason = fmt.Sprintf("\"if:\" condition %q could not be evaluated: %s", cond, err.(*ExprError).Message)
+			return ret
+		}
+		if !evalTruthy(v) {
+			ret.reason = fmt.Sprintf("\"if:\" condition %q was evaluated to false", cond)
+			return ret
+		}
+	}
+	// success() is implicitly added to the condition when it does not call any status check function
+	// https://docs.github.com/en/actions/learn-github-actions/expressions#status-check-functions
+	if status != "success" && (j.If == nil || !containsStatusCheckFunc(strings.TrimSpace(j.If.Value))) {
+		ret.reason = fmt.Sprintf("required job %q was %s", blocker.id, blocker.result)
+		return ret
+	}
+
+	ret.result = "success"
+	if j.Strategy != nil && j.Strategy.Matrix != nil {
+		var err error
+		ret.matrix, err = simulateMatrix(j.Strategy.Matrix, ctx)
+		if err != nil {
+			ret.matrixErr = err.Error()
+		}
+	}
+	return ret
+}
+
+// simulateContexts creates values of contexts for the job from the event. Values given by users
+// take precedence over the generated ones.
+func simulateContexts(ev *simulateEvent, needs []*simulatedJob, status string) map[string]any {
+	ret := make(map[string]any, len(ev.contexts)+3)
+	for n, v := range ev.contexts {
+		ret[strings.ToLower(n)] = v
+	}
+
+	github := map[string]any{"event_name": ev.name}
+	if ev.ref != "" {
+		if strings.HasPrefix(ev.ref, "refs/tags/") {
+			github["ref"] = ev.ref
+			github["ref_name"] = strings.TrimPrefix(ev.ref, "refs/tags/")
+			github["ref_type"] = "tag"
+		} else {
+			b := strings.TrimPrefix(ev.ref, "refs/heads/")
+			github["ref"] = "refs/heads/" + b
+			github["ref_name"] = b
+			github["ref_type"] = "branch"
+			if ev.name == "pull_request" || ev.name == "pull_request_target" {
+				github["base_ref"] = b
+			}
+		}
+	}
+	if ev.typ != "" {
+		github["event"] = map[string]any{"action": ev.typ}
+	}
+	if m, ok := ret["github"].(map[string]any); ok {
+		for k, v := range m {
+			github[strings.ToLower(k)] = v
+		}
+	}
+	ret["github"] = github
+
+	results := make(map[string]any, len(needs))
+	for _, n := range needs {
+		results[n.id] = map[string]any{"result": n.result, "outputs": map[string]any{}}
+	}
+	if m, ok := ret["needs"].(map[string]any); ok {
+		for k, v := range m {
+			results[k] = v
+		}
+	}
+	ret["needs"] = results
+
+	ret["job"] = map[string]any{"status": status}
+	return ret
+}
+
+// containsStatusCheckFunc returns true when the "if:" condition calls one of status check functions
+// like always().
+func containsStatusCheckFunc(cond string) bool {
+	if strings.HasPrefix(cond, "${{") && strings.HasSuffix(cond, "}}") {
+		cond = cond[3 : len(cond)-2]
+	}
+	expr, err := NewExprParser().Parse(NewExprLexer(cond + "}}"))
+	if err != nil {
+		return false
+	}
+	found := false
+	VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+		if c, ok := n.(*FuncCallNode); ok && entering {
+			switch strings.ToLower(c.Callee) {
+			case "success", "failure", "cancelled", "always":
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// simulateMatrix expands the matrix into combinations. Rows are combined, combinations matching
+// to "exclude:" are removed, and then "include:" is applied.
+// https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs
+func simulateMatrix(m *Matrix, ctx map[string]any) ([]map[string]any, error) {
+	if m.Expression != nil {
+		v, err := simulateEvalValue(m.Expression, ctx)
+		if err != nil {
+			return nil, err
+		}
+		o, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("matrix must be an object but got %s", typeOfJSONValue(v).String())
+		}
+		return simulateExpandMatrix(o, nil)
+	}
+
+	rows := make([]*MatrixRow, 0, len(m.Rows))
+	for _, r := range m.Rows {
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Name.Pos.IsBefore(rows[j].Name.Pos)
+	})
+
+	o := make(map[string]any, len(rows)+2)
+	keys := make([]string, 0, len(rows))
+	for _, r := range rows {
+		keys = append(keys, r.Name.Value)
+		if r.Expression != nil {
+			v, err := simulateEvalValue(r.Expression, ctx)
+			if err != nil {
+				return nil, err
+			}
+			o[r.Name.Value] = v
+			continue
+		}
+		vs := make([]any, 0, len(r.Values))
+		for _, v := range r.Values {
+			vs = append(vs, rawYAMLToJSONValue(v))
+		}
+		o[r.Name.Value] = vs
+	}
+	if m.Include != nil {
+		v, err := simulateCombinations(m.Include, ctx)
+		if err != nil {
+			return nil, err
+		}
+		o["include"] = v
+	}
+	if m.Exclude != nil {
+		v, err := simulateCombinations(m.Exclude, ctx)
+		if err != nil {
+			return nil, err
+		}
+		o["exclude"] = v
+	}
+
+	return simulateExpandMatrix(o, keys)
+}
+
+func simulateCombinations(cs *MatrixCombinations, ctx map[string]any) (any, error) {
+	if cs.Expression != nil {
+		return simulateEvalValue(cs.Expression, ctx)
+	}
+	ret := make([]any, 0, len(cs.Combinations))
+	for _, c := range cs.Combinations {
+		if c.Expression != nil {
+			v, err := simulateEvalValue(c.Expression, ctx)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+			continue
+		}
+		o := make(map[string]any, len(c.Assigns))
+		for _, a := range c.Assigns {
+			o[a.Key.Value] = rawYAMLToJSONValue(a.Value)
+		}
+		ret = append(ret, o)
+	}
+	return ret, nil
+}
+
+// simulateExpandMatrix expands the matrix object which is the same structure as the value of
+// "matrix:". The keys parameter is names of rows in the order of expansion. When it is nil, rows
+// are expanded in alphabetical order.
+func simulateExpandMatrix(m map[string]any, keys []string) ([]map[string]any, error) {
+	var include, exclude any
+	if keys == nil {
+		for k, v := range m {
+			switch strings.ToLower(k) {
+			case "include":
+				include = v
+			case "exclude":
+				exclude = v
+			default:
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+	} else {
+		include, exclude = m["include"], m["exclude"]
+	}
+
+	combos := []map[string]any{{}}
+	for _, k := range keys {
+		vs, ok := m[k].([]any)
+		if !ok {
+			return nil, fmt.Errorf("values of matrix row %q must be an array but got %s", k, typeOfJSONValue(m[k]).String())
+		}
+		next := make([]map[string]any, 0, len(combos)*len(vs))
+		for _, c := range combos {
+			for _, v := range vs {
+				n := make(map[string]any, len(c)+1)
+				for ck, cv := range c {
+					n[ck] = cv
+				}
+				n[k] = v
+				next = append(next, n)
+			}
+		}
+		combos = next
+	}
+	if len(keys) == 0 {
+		combos = nil
+	}
+
+	if exclude != nil {
+		excludes, ok := exclude.([]any)
+		if !ok {
+			return nil, fmt.Errorf("\"exclude:\" must be an array but got %s", typeOfJSONValue(exclude).String())
+		}
+		kept := combos[:0]
+		for _, c := range combos {
+			excluded := false
+			for _, e := range excludes {
+				if simulateIsSubset(c, e) {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				kept = append(kept, c)
+			}
+		}
+		combos = kept
+	}
+
+	if include != nil {
+		includes, ok := include.([]any)
+		if !ok {
+			return nil, fmt.Errorf("\"include:\" must be an array but got %s", typeOfJSONValue(include).String())
+		}
+		orig := len(combos)
+		for _, i := range includes {
+			inc, ok := i.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("element of \"include:\" must be an object but got %s", typeOfJSONValue(i).String())
+			}
+			// An object in "include:" is added to all original combinations whose original values are not
+			// overwritten. When it cannot be added to any combination, it is added as a new combination.
+			added := false
+			for _, c := range combos[:orig] {
+				ok := true
+				for _, k := range keys {
+					if v, exists := inc[k]; exists && !reflect.DeepEqual(c[k], v) {
+						ok = false
+						break
+					}
+				}
+				if !ok {
+					continue
+				}
+				for k, v := range inc {
+					c[k] = v
+				}
+				added = true
+			}
+			if !added {
+				n := make(map[string]any, len(inc))
+				for k, v := range inc {
+					n[k] = v
+				}
+				combos = append(combos, n)
+			}
+		}
+	}
+
+	return combos, nil
+}
+
+// simulateIsSubset returns true when the combination contains all values in the filter of "exclude:".
+// Objects in the filter can match to objects in the combination as subset of them.
+func simulateIsSubset(v, sub any) bool {
+	s, ok := sub.(map[string]any)
+	if !ok {
+		return reflect.DeepEqual(v, sub)
+	}
+	var o map[string]any
+	switch v := v.(type) {
+	case map[string]any:
+		o = v
+	default:
+		return false
+	}
+	for k, sv := range s {
+		found := false
+		for ok, ov := range o {
+			if strings.EqualFold(ok, k) {
+				found = simulateIsSubset(ov, sv)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func simulateEvalValue(s *String, ctx map[string]any) (any, error) {
+	v, err := EvalExpression(s.Value, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate %q: %s", s.Value, err.(*ExprError).Message)
+	}
+	return v, nil
+}
+
+// rawYAMLToJSONValue converts the raw YAML value into the value decoded by json.Unmarshal. Scalar
+// values are converted into strings.
+func rawYAMLToJSONValue(v RawYAMLValue) any {
+	switch v := v.(type) {
+	case *RawYAMLObject:
+		o := make(map[string]any, len(v.Props))
+		for k, p := range v.Props {
+			o[k] = rawYAMLToJSONValue(p)
+		}
+		return o
+	case *RawYAMLArray:
+		a := make([]any, 0, len(v.Elems))
+		for _, e := range v.Elems {
+			a = append(a, rawYAMLToJSONValue(e))
+		}
+		return a
+	case *RawYAMLString:
+		return v.Value
+	default:
+		return nil
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// printSimulatedWorkflow prints the result of the simulation in human-readable format.
+func printSimulatedWorkflow(out io.Writer, path string, w *simulatedWorkflow) {
+	if !w.triggered {
+		fmt.Fprintf(out, "%s: not triggered since %s\n", path, w.reason)
+		return
+	}
+	fmt.Fprintf(out, "%s: %s\n", path, w.reason)
+	for _, j := range w.jobs {
+		if j.result != "success" {
+			fmt.Fprintf(out, "  %s: skipped since %s\n", j.id, j.reason)
+			continue
+		}
+		switch {
+		case j.matrixErr != "":
+			fmt.Fprintf(out, "  %s: runs but matrix could not be expanded: %s\n", j.id, j.matrixErr)
+		case j.matrix != nil:
+			fmt.Fprintf(out, "  %s: runs %d times by matrix\n", j.id, len(j.matrix))
+			for _, c := range j.matrix {
+				b, _ := json.Marshal(c)
+				fmt.Fprintf(out, "    - %s\n", b)
+			}
+		default:
+			fmt.Fprintf(out, "  %s: runs\n", j.id)
+		}
+	}
+}
+
+// simulateEventPayload creates a mock of the webhook event payload from the event. The payload can
+// be passed to nektos/act via -e option. "github.event" in the mock contexts is used as the base of
+// the payload.
+// https://nektosact.com/usage/index.html#using-event-file-to-provide-complete-event-payload
+func simulateEventPayload(ev *simulateEvent) map[string]any {
+	ret := map[string]any{}
+	if g, ok := ev.contexts["github"].(map[string]any); ok {
+		for k, v := range g {
+			if strings.EqualFold(k, "event") {
+				if e, ok := v.(map[string]any); ok {
+					for k, v := range e {
+						ret[k] = v
+					}
+				}
+			}
+		}
+	}
+
+	if ev.typ != "" {
+		ret["action"] = ev.typ
+	}
+	if ev.ref == "" {
+		return ret
+	}
+	switch ev.name {
+	case "pull_request", "pull_request_target":
+		pr, ok := ret["pull_request"].(map[string]any)
+		if !ok {
+			pr = map[string]any{}
+			ret["pull_request"] = pr
+		}
+		base, ok := pr["base"].(map[string]any)
+		if !ok {
+			base = map[string]any{}
+			pr["base"] = base
+		}
+		base["ref"] = strings.TrimPrefix(ev.ref, "refs/heads/")
+	default:
+		ref := ev.ref
+		if !strings.HasPrefix(ref, "refs/") {
+			ref = "refs/heads/" + ref
+		}
+		ret["ref"] = ref
+	}
+	return ret
+}
+
+// actArgs returns arguments of nektos/act command to run the job in the workflow file with the
+// event payload file.
+// https://github.com/nektos/act
+func actArgs(ev *simulateEvent, workflow, job, payload string) []string {
+	return []string{ev.name, "-W", workflow, "-j", job, "-e", payload}
+}
+
+// shellQuote quotes the argument for POSIX shell when it contains some special characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,396 @@
+package actionlint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/execabs"
+)
+
+const testSimulateWorkflow = `on:
+  push:
+    branches: [main, 'release/**', '!release/old']
+    paths: ['src/**', '!src/docs/**']
+  pull_request:
+    branches: [main]
+    paths-ignore: ['**.md']
+  release:
+    types: [published]
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+        node: [18, 20]
+        exclude:
+          - os: macos-latest
+            node: 18
+        include:
+          - os: ubuntu-latest
+            experimental: true
+          - os: windows-latest
+            node: 20
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: echo
+  deploy:
+    needs: build
+    if: github.ref == 'refs/heads/main' && github.event_name == 'push'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  notify:
+    needs: deploy
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  report:
+    needs: [deploy]
+    if: ${{ always() && needs.deploy.result == 'skipped' }}
+    runs-on: ubuntu-latest
+    strategy:
+      matrix: ${{ fromJSON(vars.MATRIX) }}
+    steps:
+      - run: echo
+`
+
+func TestSimulateWorkflow(t *testing.T) {
+	w, errs := Parse([]byte(testSimulateWorkflow))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	testCases := []struct {
+		what string
+		ev   simulateEvent
+		want string
+	}{
+		{
+			what: "push to main",
+			ev:   simulateEvent{name: "push", ref: "refs/heads/main", paths: []string{"README.md", "src/a.go"}},
+			want: `ci.yaml: triggered by "push" event
+  build: runs 4 times by matrix
+    - {"experimental":"true","node":"18","os":"ubuntu-latest"}
+    - {"experimental":"true","node":"20","os":"ubuntu-latest"}
+    - {"node":"20","os":"macos-latest"}
+    - {"node":"20","os":"windows-latest"}
+  deploy: runs
+  notify: runs
+  report: skipped since "if:" condition "${{ always() && needs.deploy.result == 'skipped' }}" was evaluated to false
+`,
+		},
+		{
+			what: "push to release branch",
+			ev: simulateEvent{
+				name:     "push",
+				ref:      "release/v1",
+				contexts: map[string]any{"vars": map[string]any{"MATRIX": `{"v": [1, 2]}`}},
+			},
+			want: `ci.yaml: triggered by "push" event
+  build: runs 4 times by matrix
+    - {"experimental":"true","node":"18","os":"ubuntu-latest"}
+    - {"experimental":"true","node":"20","os":"ubuntu-latest"}
+    - {"node":"20","os":"macos-latest"}
+    - {"node":"20","os":"windows-latest"}
+  deploy: skipped since "if:" condition "github.ref == 'refs/heads/main' && github.event_name == 'push'" was evaluated to false
+  notify: skipped since required job "deploy" was skipped
+  report: runs 2 times by matrix
+    - {"v":1}
+    - {"v":2}
+`,
+		},
+		{
+			what: "matrix cannot be expanded",
+			ev:   simulateEvent{name: "push", ref: "refs/heads/release/v1"},
+			want: `ci.yaml: triggered by "push" event
+  build: runs 4 times by matrix
+    - {"experimental":"true","node":"18","os":"ubuntu-latest"}
+    - {"experimental":"true","node":"20","os":"ubuntu-latest"}
+    - {"node":"20","os":"macos-latest"}
+    - {"node":"20","os":"windows-latest"}
+  deploy: skipped since "if:" condition "github.ref == 'refs/heads/main' && github.event_name == 'push'" was evaluated to false
+  notify: skipped since required job "deploy" was skipped
+  report: runs but matrix could not be expanded: could not evaluate "${{ fromJSON(vars.MATRIX) }}": could not parse JSON string "": unexpected end of JSON input
+`,
+		},
+		{
+			what: "excluded branch",
+			ev:   simulateEvent{name: "push", ref: "refs/heads/release/old"},
+			want: "ci.yaml: not triggered since \"release/old\" does not match \"branches\" filter\n",
+		},
+		{
+			what: "pushing tag",
+			ev:   simulateEvent{name: "push", ref: "refs/tags/v1.0.0"},
+			want: "ci.yaml: not triggered since tag \"v1.0.0\" is pushed but only filters for branches are specified\n",
+		},
+		{
+			what: "excluded paths",
+			ev:   simulateEvent{name: "push", ref: "refs/heads/main", paths: []string{"src/docs/a.md", "README.md"}},
+			want: "ci.yaml: not triggered since none of the changed paths matches \"paths\" filter\n",
+		},
+		{
+			what: "ignored paths",
+			ev:   simulateEvent{name: "pull_request", ref: "main", paths: []string{"README.md", "docs/usage.md"}},
+			want: "ci.yaml: not triggered since all the changed paths match \"paths-ignore\" filter\n",
+		},
+		{
+			what: "default types of pull_request",
+			ev:   simulateEvent{name: "pull_request", ref: "main", typ: "closed"},
+			want: "ci.yaml: not triggered since activity type \"closed\" is not included in \"types:\" \"opened\", \"synchronize\", \"reopened\"\n",
+		},
+		{
+			what: "types",
+			ev:   simulateEvent{name: "release", typ: "created"},
+			want: "ci.yaml: not triggered since activity type \"created\" is not included in \"types:\" \"published\"\n",
+		},
+		{
+			what: "event not listed",
+			ev:   simulateEvent{name: "workflow_dispatch"},
+			want: "ci.yaml: not triggered since \"workflow_dispatch\" event is not listed at \"on:\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var b strings.Builder
+			printSimulatedWorkflow(&b, "ci.yaml", simulateWorkflow(w, &tc.ev))
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSimulateExpandMatrix(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix map[string]any
+		want   []map[string]any
+	}{
+		{
+			what:   "empty",
+			matrix: map[string]any{},
+			want:   nil,
+		},
+		{
+			what:   "only include",
+			matrix: map[string]any{"include": []any{map[string]any{"a": 1.0}, map[string]any{"b": 2.0}}},
+			want:   []map[string]any{{"a": 1.0}, {"b": 2.0}},
+		},
+		{
+			what: "include overwrites added values",
+			matrix: map[string]any{
+				"fruit": []any{"apple", "pear"},
+				"include": []any{
+					map[string]any{"color": "green"},
+					map[string]any{"color": "pink", "fruit": "apple"},
+				},
+			},
+			want: []map[string]any{{"fruit": "apple", "color": "pink"}, {"fruit": "pear", "color": "green"}},
+		},
+		{
+			what: "exclude object partially",
+			matrix: map[string]any{
+				"os":      []any{map[string]any{"name": "linux", "arch": "x64"}, map[string]any{"name": "mac", "arch": "arm"}},
+				"Exclude": []any{map[string]any{"os": map[string]any{"arch": "arm"}}},
+			},
+			want: []map[string]any{{"os": map[string]any{"name": "linux", "arch": "x64"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			have, err := simulateExpandMatrix(tc.matrix, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSimulateExpandMatrixError(t *testing.T) {
+	for _, tc := range []struct {
+		matrix map[string]any
+		want   string
+	}{
+		{map[string]any{"os": "linux"}, `values of matrix row "os" must be an array but got string`},
+		{map[string]any{"include": "foo"}, `"include:" must be an array but got string`},
+		{map[string]any{"include": []any{1.0}}, `element of "include:" must be an object but got number`},
+		{map[string]any{"exclude": true}, `"exclude:" must be an array but got bool`},
+	} {
+		_, err := simulateExpandMatrix(tc.matrix, nil)
+		if err == nil {
+			t.Errorf("error did not occur for %v", tc.matrix)
+			continue
+		}
+		if err.Error() != tc.want {
+			t.Errorf("wanted error %q but got %q", tc.want, err.Error())
+		}
+	}
+}
+
+func TestCommandSimulate(t *testing.T) {
+	dir := t.TempDir()
+	ci := filepath.Join(dir, "ci.yaml")
+	if err := os.WriteFile(ci, []byte(testSimulateWorkflow), 0644); err != nil {
+		panic(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := Command{Stdin: strings.NewReader(`{"vars": {"matrix": "{\"v\": [true]}"}}`), Stdout: &stdout, Stderr: &stderr}
+	args := []string{"actionlint", "simulate", "-event", "push", "-ref", "refs/heads/dev", "-paths", "src/a.go,README.md", "-contexts", "-", ci}
+	if status := cmd.Main(args); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, stderr.String())
+	}
+	if want := ci + ": not triggered since \"dev\" does not match \"branches\" filter\n"; stdout.String() != want {
+		t.Fatalf("wanted %q but got %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	args = []string{"actionlint", "simulate", "-event", "pull_request", "-ref", "main", "-paths", "src/a.go", ci}
+	if status := cmd.Main(args); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, stderr.String())
+	}
+	if want := "  notify: skipped since required job \"deploy\" was skipped\n"; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	args = []string{"actionlint", "simulate", filepath.Join(dir, "missing.yaml")}
+	if status := cmd.Main(args); status != ExitStatusFailure {
+		t.Fatal("exit status should be", ExitStatusFailure, "but got", status)
+	}
+	if want := "could not read"; !strings.Contains(stderr.String(), want) {
+		t.Fatalf("%q is not included in stderr %q", want, stderr.String())
+	}
+}
+
+func TestSimulateEventPayload(t *testing.T) {
+	testCases := []struct {
+		what string
+		ev   simulateEvent
+		want map[string]any
+	}{
+		{
+			what: "push branch",
+			ev:   simulateEvent{name: "push", ref: "main"},
+			want: map[string]any{"ref": "refs/heads/main"},
+		},
+		{
+			what: "push tag",
+			ev:   simulateEvent{name: "push", ref: "refs/tags/v1.0.0"},
+			want: map[string]any{"ref": "refs/tags/v1.0.0"},
+		},
+		{
+			what: "pull_request",
+			ev: simulateEvent{
+				name: "pull_request",
+				ref:  "refs/heads/main",
+				typ:  "opened",
+				contexts: map[string]any{
+					"github": map[string]any{
+						"Event": map[string]any{"number": 1.0, "pull_request": map[string]any{"draft": true}},
+					},
+				},
+			},
+			want: map[string]any{
+				"action":       "opened",
+				"number":       1.0,
+				"pull_request": map[string]any{"draft": true, "base": map[string]any{"ref": "main"}},
+			},
+		},
+		{
+			what: "no ref",
+			ev:   simulateEvent{name: "workflow_dispatch"},
+			want: map[string]any{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, simulateEventPayload(&tc.ev)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestCommandSimulateWithAct(t *testing.T) {
+	dir := t.TempDir()
+	ci := filepath.Join(dir, "ci.yaml")
+	if err := os.WriteFile(ci, []byte(testSimulateWorkflow), 0644); err != nil {
+		panic(err)
+	}
+	export := filepath.Join(dir, "act")
+
+	var stdout, stderr bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &stdout, Stderr: &stderr}
+	args := []string{"actionlint", "simulate", "-ref", "release/v1", "-export-act", export, ci}
+	if status := cmd.Main(args); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, stderr.String())
+	}
+	payload := filepath.Join(export, "event.json")
+	b, err := os.ReadFile(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"ref\": \"refs/heads/release/v1\"\n}"; string(b) != want {
+		t.Fatalf("wanted payload %q but got %q", want, b)
+	}
+	for _, job := range []string{"build", "report"} {
+		want := "  act push -W " + shellQuote(ci) + " -j " + job + " -e " + shellQuote(payload) + "\n"
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("%q is not included in output %q", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "-j deploy") {
+		t.Errorf("skipped job is included in output %q", stdout.String())
+	}
+
+	if _, err := execabs.LookPath("echo"); err != nil {
+		t.Skipf("echo command is necessary to run this test: %s", err)
+	}
+	stdout.Reset()
+	args = []string{"actionlint", "simulate", "-ref", "main", "-run-with", "echo", ci}
+	if status := cmd.Main(args); status != 0 {
+		t.Fatal("exit status should be 0 but got", status, stderr.String())
+	}
+	for _, job := range []string{"build", "deploy", "notify"} {
+		want := "Running job \"" + job + "\" in " + ci + " with echo\npush -W " + ci + " -j " + job + " -e "
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("%q is not included in output %q", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	args = []string{"actionlint", "simulate", "-run-with", "this-command-does-not-exist", ci}
+	if status := cmd.Main(args); status != ExitStatusFailure {
+		t.Fatal("exit status should be", ExitStatusFailure, "but got", status)
+	}
+	if want := "could not find nektos/act command"; !strings.Contains(stderr.String(), want) {
+		t.Fatalf("%q is not included in stderr %q", want, stderr.String())
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"push":              "push",
+		"/path/to/ci.yaml":  "/path/to/ci.yaml",
+		"":                  "''",
+		"has space":         "'has space'",
+		"it's":              `'it'\''s'`,
+		"C:\\path\\ci.yaml": `'C:\path\ci.yaml'`,
+	} {
+		if have := shellQuote(in); have != want {
+			t.Errorf("wanted %s for %q but got %s", want, in, have)
+		}
+	}
+}
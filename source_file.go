@@ -0,0 +1,95 @@
+package actionlint
+
+import (
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceFile is a source of a workflow file shared by the parser, rules, and the linter while
+// checking the file. It retains the source buffer, the lines of the source, and the YAML node tree
+// so that features which need the raw source such as style checks, fixes, and inline
+// "actionlint-ignore" comments don't split or parse the source again. The lines and the node tree
+// are created lazily on the first access. The node tree must not be modified by its users.
+type SourceFile struct {
+	// Path is the file path of the source. It is empty when the source was not read from a file.
+	Path string
+	// Content is the content of the source. It must not be modified.
+	Content []byte
+
+	linesOnce   sync.Once
+	lines       sourceLines
+	offsetsOnce sync.Once
+	offsets     []int
+	nodeOnce    sync.Once
+	node        *yaml.Node
+	nodeErr     error
+}
+
+// NewSourceFile creates a new SourceFile instance for the content of the file at the path.
+func NewSourceFile(path string, content []byte) *SourceFile {
+	return &SourceFile{Path: path, Content: content}
+}
+
+// Lines returns the lines of the source. Both "\n" and "\r\n" are accepted as line breaks. The
+// returned slice must not be modified.
+func (f *SourceFile) Lines() []string {
+	return f.sourceLines()
+}
+
+func (f *SourceFile) sourceLines() sourceLines {
+	f.linesOnce.Do(func() {
+		f.lines = splitLines(string(f.Content))
+	})
+	return f.lines
+}
+
+// offsetAt returns the 1-based byte offset of the 1-based line and column in the source as
+// Pos.Offset. It returns 0 when the position is out of the source.
+func (f *SourceFile) offsetAt(line, col int) int {
+	f.offsetsOnce.Do(func() {
+		f.offsets = lineOffsets(f.Content)
+	})
+	o := byteOffsetAt(f.Content, f.offsets, &Pos{Line: line, Col: col})
+	if o < 0 {
+		return 0
+	}
+	return o + 1
+}
+
+// pos returns the position of the 1-based line and column in the source with its byte offset.
+func (f *SourceFile) pos(line, col int) *Pos {
+	return &Pos{Line: line, Col: col, Offset: f.offsetAt(line, col)}
+}
+
+// Node returns the YAML node tree of the source. Aliases and merge keys in the tree are not
+// resolved. It returns an error when the source cannot be parsed as YAML. The returned tree must not
+// be modified.
+func (f *SourceFile) Node() (*yaml.Node, error) {
+	f.nodeOnce.Do(func() {
+		var n yaml.Node
+		if err := unmarshalYAML(f.Content, &n); err != nil {
+			f.nodeErr = err
+			return
+		}
+		f.node = &n
+	})
+	return f.node, f.nodeErr
+}
+
+// hasYAMLAliases returns true when the tree contains aliases or merge keys, which are resolved by
+// modifying the tree in place.
+func hasYAMLAliases(n *yaml.Node) bool {
+	if n.Kind == yaml.AliasNode {
+		return true
+	}
+	for i, c := range n.Content {
+		if n.Kind == yaml.MappingNode && i%2 == 0 && isMergeKey(c) {
+			return true
+		}
+		if hasYAMLAliases(c) {
+			return true
+		}
+	}
+	return false
+}
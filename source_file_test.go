@@ -0,0 +1,105 @@
+package actionlint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSourceFileLinesAndNode(t *testing.T) {
+	f := NewSourceFile("test.yaml", []byte("on: push\r\njobs: {}\n"))
+
+	want := []string{"on: push", "jobs: {}", ""}
+	if diff := cmp.Diff(want, f.Lines()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	n, err := f.Node()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Content) != 1 || n.Content[0].Kind != yaml.MappingNode {
+		t.Fatalf("unexpected node tree: %#v", n)
+	}
+	if n2, _ := f.Node(); n2 != n {
+		t.Fatal("node tree was parsed again")
+	}
+
+	f = NewSourceFile("broken.yaml", []byte("foo: [\n"))
+	if n, err := f.Node(); err == nil {
+		t.Fatalf("wanted error but got %#v", n)
+	}
+}
+
+func TestSourceFileParseKeepsSharedTree(t *testing.T) {
+	testCases := []struct {
+		what string
+		src  string
+	}{
+		{
+			what: "no alias",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`,
+		},
+		{
+			what: "alias",
+			src: `on: push
+jobs:
+  test:
+    runs-on: &os ubuntu-latest
+    steps:
+      - run: echo
+  test2:
+    runs-on: *os
+    steps:
+      - run: echo
+`,
+		},
+		{
+			what: "merge key",
+			src: `on: push
+jobs:
+  test:
+    <<: {runs-on: ubuntu-latest}
+    steps:
+      - run: echo
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var want yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.src), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			f := NewSourceFile("test.yaml", []byte(tc.src))
+			w, errs := ParseSourceFile(f)
+			if len(errs) > 0 {
+				t.Fatal("unexpected errors:", errs)
+			}
+			if len(w.Jobs) == 0 {
+				t.Fatal("jobs were not parsed")
+			}
+			w2, _ := Parse([]byte(tc.src))
+			if diff := cmp.Diff(w2, w); diff != "" {
+				t.Fatal("result is different from Parse:", diff)
+			}
+
+			have, err := f.Node()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(&want, have); diff != "" {
+				t.Fatal("shared node tree was modified by parser:", diff)
+			}
+		})
+	}
+}
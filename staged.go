@@ -0,0 +1,227 @@
+package actionlint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/execabs"
+)
+
+// gitIndex reads files staged in the Git index of the repository. Files are read from the index
+// instead of the working tree so that partially staged files are checked as they will be committed.
+type gitIndex struct {
+	root string
+}
+
+func (g *gitIndex) git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	c := execabs.Command("git", append([]string{"-C", g.root}, args...)...)
+	c.Stderr = &stderr
+	b, err := c.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("`git %s` failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("could not run `git %s`: %w", strings.Join(args, " "), err)
+	}
+	return b, nil
+}
+
+// list returns slash-separated paths relative to the repository root. The args are passed to the
+// git command which outputs NUL-separated paths.
+func (g *gitIndex) list(args ...string) ([]string, error) {
+	b, err := g.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	ret := []string{}
+	for _, p := range strings.Split(string(b), "\x00") {
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
+
+// staged returns the files which are added, copied, modified, or renamed in the index.
+func (g *gitIndex) staged() ([]string, error) {
+	return g.list("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+}
+
+// workflows returns all workflow files in the index.
+func (g *gitIndex) workflows() ([]string, error) {
+	fs, err := g.list("ls-files", "--cached", "-z", "--", ".github/workflows")
+	if err != nil {
+		return nil, err
+	}
+	ret := fs[:0]
+	for _, f := range fs {
+		if isStagedWorkflowFile(f) {
+			ret = append(ret, f)
+		}
+	}
+	return ret, nil
+}
+
+// config reads the actionlint config file in the index. It returns nil when no config file exists in
+// the index.
+func (g *gitIndex) config() (*Config, error) {
+	for _, f := range []string{".github/actionlint.yaml", ".github/actionlint.yml"} {
+		b, err := g.git("cat-file", "blob", ":"+f)
+		if err != nil {
+			continue // The file does not exist in the index
+		}
+		c, err := ParseConfig(b)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse config file %q in Git index: %w", f, err)
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// readFile reads the content of the file in the index. The path is an absolute file path.
+func (g *gitIndex) readFile(p string) ([]byte, error) {
+	r, err := filepath.Rel(g.root, p)
+	if err != nil || strings.HasPrefix(r, "..") {
+		return nil, fmt.Errorf("%q is outside of the repository %q", p, g.root)
+	}
+	b, err := g.git("cat-file", "blob", ":"+filepath.ToSlash(r))
+	if err != nil {
+		return nil, fmt.Errorf("%q does not exist in Git index", filepath.ToSlash(r))
+	}
+	return b, nil
+}
+
+// FilesAtHead returns files in the directory at HEAD commit. See GitRepository for more details.
+func (g *gitIndex) FilesAtHead(dir string) ([]string, error) {
+	// Ensure the root is the root of the Git repository. Otherwise, Git would look for the repository
+	// in parent directories
+	b, err := g.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	if !isSameFile(strings.TrimSpace(string(b)), g.root) {
+		return nil, fmt.Errorf("%q is not the root of Git repository", g.root)
+	}
+	return g.list("ls-tree", "-r", "-z", "--name-only", "HEAD", "--", dir)
+}
+
+// ReadFileAtHead reads the content of the file at HEAD commit. See GitRepository for more details.
+func (g *gitIndex) ReadFileAtHead(path string) ([]byte, error) {
+	return g.git("show", "HEAD:"+path)
+}
+
+func isSameFile(a, b string) bool {
+	sa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	sb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(sa, sb)
+}
+
+// isStagedWorkflowFile returns true when the slash-separated path relative to the repository root is
+// a workflow file. Files in subdirectories of .github/workflows are not workflows.
+func isStagedWorkflowFile(p string) bool {
+	return path.Dir(p) == ".github/workflows" && (strings.HasSuffix(p, ".yml") || strings.HasSuffix(p, ".yaml"))
+}
+
+func isStagedActionFile(p string) bool {
+	b := path.Base(p)
+	return b == "action.yml" || b == "action.yaml"
+}
+
+// LintStaged lints workflow files staged in the Git index of the repository which the given
+// directory belongs to. When the directory path is empty, the current working directory is used.
+// The workflows, local actions, and local reusable workflows are read from the index instead of the
+// working tree so that exactly what will be committed is checked. The config file of the repository
+// is also read from the index. When some action metadata file
+// (action.yml) is staged, all workflows in the index are checked since any of them may use the
+// action. This method is useful for Git pre-commit hooks.
+func (l *Linter) LintStaged(dir string) ([]*Error, error) {
+	if dir == "" {
+		dir = l.cwd
+	}
+
+	root := findProjectRoot(dir)
+	if root == "" {
+		return nil, fmt.Errorf("no project was found in any parent directories of %q. check workflows directory is put correctly in your Git repository", dir)
+	}
+	l.log("Linting staged files in repository:", root)
+
+	idx := &gitIndex{root}
+	staged, err := idx.staged()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := idx.config()
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{root, cfg}
+
+	files := []string{}
+	for _, f := range staged {
+		if isStagedActionFile(f) {
+			l.log("Action metadata", f, "is staged. Checking all workflows in the index")
+			files, err = idx.workflows()
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		if isStagedWorkflowFile(f) {
+			files = append(files, f)
+		}
+	}
+	l.log("Collected", len(files), "staged workflow files")
+
+	proc := l.newProcess(runtime.NumCPU())
+	dbg := l.debugWriter()
+	localActions := NewLocalActionsCache(p, dbg)
+	localActions.read = idx.readFile
+	localReusableWorkflows := NewLocalReusableWorkflowCache(p, l.cwd, dbg)
+	localReusableWorkflows.read = idx.readFile
+
+	ws := make([]workspace, 0, len(files))
+	for _, f := range files {
+		abs := filepath.Join(p.RootDir(), filepath.FromSlash(f))
+		src, err := idx.readFile(abs)
+		if err != nil {
+			proc.wait()
+			return nil, err
+		}
+		path := abs
+		if r, err := filepath.Rel(l.cwd, abs); err == nil {
+			path = r // Use relative path if possible
+		}
+		w, err := l.check(path, src, p, proc, localActions, localReusableWorkflows)
+		if err != nil {
+			proc.wait()
+			return nil, fmt.Errorf("fatal error while checking %s: %w", path, err)
+		}
+		ws = append(ws, *w)
+	}
+	ws, err = l.checkProjects(ws)
+	if err != nil {
+		proc.wait()
+		return nil, err
+	}
+
+	rws, err := l.lintRemoteWorkflows(proc)
+	proc.wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.printWorkspaces(append(ws, rws...))
+}
@@ -0,0 +1,193 @@
+package actionlint
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/execabs"
+)
+
+func testStagedGit(t *testing.T, dir string, args ...string) {
+	c := execabs.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %s: %s", args, err, out)
+	}
+}
+
+func testStagedWrite(t *testing.T, dir, path, content string) {
+	p := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testStagedLint(t *testing.T, dir string) []string {
+	l, err := NewLinter(io.Discard, &LinterOptions{WorkingDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.LintStaged(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := []string{}
+	for _, e := range errs {
+		ret = append(ret, filepath.ToSlash(e.Filepath)+":"+e.Kind)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestLintStagedFilesInIndex(t *testing.T) {
+	if _, err := execabs.LookPath("git"); err != nil {
+		t.Skip("git command is not available")
+	}
+
+	d := t.TempDir()
+	testStagedGit(t, d, "init", "-q")
+
+	action := "name: My action\ndescription: test\ninputs:\n  foo:\n    description: test\nruns:\n  using: node20\n  main: index.js\n"
+	good := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	bad := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n"
+	usesAction := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: ./act\n        with:\n          foo: bar\n"
+
+	testStagedWrite(t, d, ".github/workflows/committed.yaml", bad)
+	testStagedWrite(t, d, ".github/workflows/uses_action.yaml", usesAction)
+	testStagedWrite(t, d, "act/action.yml", action)
+	testStagedWrite(t, d, "act/index.js", "")
+	testStagedGit(t, d, "add", "-A")
+	testStagedGit(t, d, "commit", "-q", "-m", "init")
+
+	if have := testStagedLint(t, d); len(have) != 0 {
+		t.Fatal("no error should be reported when nothing is staged:", have)
+	}
+
+	// Staged content has an error but it is fixed only in the working tree
+	testStagedWrite(t, d, ".github/workflows/partial.yaml", bad)
+	testStagedGit(t, d, "add", ".github/workflows/partial.yaml")
+	testStagedWrite(t, d, ".github/workflows/partial.yaml", good)
+	// Staged content is fine but the working tree has an error
+	testStagedWrite(t, d, ".github/workflows/fixed.yaml", good)
+	testStagedGit(t, d, "add", ".github/workflows/fixed.yaml")
+	testStagedWrite(t, d, ".github/workflows/fixed.yaml", bad)
+	// Not staged
+	testStagedWrite(t, d, ".github/workflows/untracked.yaml", bad)
+	testStagedWrite(t, d, ".github/workflows/committed.yaml", bad+"\n")
+	// Staged but not a workflow
+	testStagedWrite(t, d, "README.md", "hello")
+	testStagedGit(t, d, "add", "README.md")
+
+	want := []string{".github/workflows/partial.yaml:expression"}
+	if diff := cmp.Diff(want, testStagedLint(t, d)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// When action metadata is staged, all workflows in the index are checked with the staged metadata
+	testStagedWrite(t, d, "act/action.yml", "name: My action\ndescription: test\nruns:\n  using: node20\n  main: index.js\n")
+	testStagedGit(t, d, "add", "act/action.yml")
+	testStagedWrite(t, d, "act/action.yml", action)
+
+	want = []string{
+		".github/workflows/committed.yaml:expression",
+		".github/workflows/partial.yaml:expression",
+		".github/workflows/uses_action.yaml:action",
+	}
+	if diff := cmp.Diff(want, testStagedLint(t, d)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLintStagedOnlyDirectChildrenOfWorkflowsDir(t *testing.T) {
+	if _, err := execabs.LookPath("git"); err != nil {
+		t.Skip("git command is not available")
+	}
+
+	d := t.TempDir()
+	testStagedGit(t, d, "init", "-q")
+
+	bad := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n"
+	testStagedWrite(t, d, ".github/workflows/ci.yaml", bad)
+	testStagedWrite(t, d, ".github/workflows/sub/nested.yaml", bad)
+	testStagedGit(t, d, "add", "-A")
+
+	want := []string{".github/workflows/ci.yaml:expression"}
+	if diff := cmp.Diff(want, testStagedLint(t, d)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// All workflows in the index are checked when action metadata is staged
+	testStagedWrite(t, d, "act/action.yml", "name: My action\ndescription: test\nruns:\n  using: node20\n  main: index.js\n")
+	testStagedGit(t, d, "add", "act/action.yml")
+
+	if diff := cmp.Diff(want, testStagedLint(t, d)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLintStagedConfigInIndex(t *testing.T) {
+	if _, err := execabs.LookPath("git"); err != nil {
+		t.Skip("git command is not available")
+	}
+
+	d := t.TempDir()
+	testStagedGit(t, d, "init", "-q")
+
+	src := "on: push\njobs:\n  a:\n    runs-on: staged-runner\n    steps:\n      - run: echo\n  b:\n    runs-on: worktree-runner\n    steps:\n      - run: echo\n"
+	testStagedWrite(t, d, ".github/workflows/ci.yaml", src)
+	testStagedWrite(t, d, ".github/actionlint.yaml", "self-hosted-runner:\n  labels: [staged-runner]\n")
+	testStagedGit(t, d, "add", "-A")
+	testStagedWrite(t, d, ".github/actionlint.yaml", "self-hosted-runner:\n  labels: [worktree-runner]\n")
+
+	want := []string{".github/workflows/ci.yaml:runner-label"}
+	have := testStagedLint(t, d)
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Broken config file in the working tree does not matter
+	testStagedWrite(t, d, ".github/actionlint.yaml", "self-hosted-runner: [")
+	have = testStagedLint(t, d)
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Broken config file in the index is reported
+	testStagedGit(t, d, "add", ".github/actionlint.yaml")
+	l, err := NewLinter(io.Discard, &LinterOptions{WorkingDir: d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.LintStaged(d); err == nil || !strings.Contains(err.Error(), "in Git index") {
+		t.Fatal("error was not reported for broken config file in index:", err)
+	}
+}
+
+func TestLintStagedNotGitRepository(t *testing.T) {
+	if _, err := execabs.LookPath("git"); err != nil {
+		t.Skip("git command is not available")
+	}
+
+	d := t.TempDir()
+	// Project is detected by .git but it is not an actual repository
+	if err := os.MkdirAll(filepath.Join(d, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(d, ".github", "workflows"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLinter(io.Discard, &LinterOptions{WorkingDir: d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.LintStaged(d); err == nil {
+		t.Fatal("error did not occur")
+	}
+}
@@ -0,0 +1,119 @@
+package actionlint
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// TemplateMode is a kind of templating language used for generating workflow files. When a template
+// mode is set, templating constructs in the source are neutralized before parsing so that templated
+// workflow files can be checked on a best-effort basis.
+type TemplateMode string
+
+const (
+	// TemplateModeNone is the default mode. Workflow files are parsed as-is.
+	TemplateModeNone TemplateMode = ""
+	// TemplateModeHelm neutralizes Helm chart templates. The syntax is the same as Go template.
+	TemplateModeHelm TemplateMode = "helm"
+	// TemplateModeJinja neutralizes Jinja templates. {{ }}, {% %}, and {# #} are handled.
+	TemplateModeJinja TemplateMode = "jinja"
+	// TemplateModeGoTemplate neutralizes Go templates (text/template). {{ }} is handled.
+	TemplateModeGoTemplate TemplateMode = "gotemplate"
+)
+
+// ParseTemplateMode parses the given string as TemplateMode. An empty string is parsed as
+// TemplateModeNone.
+func ParseTemplateMode(s string) (TemplateMode, error) {
+	switch m := TemplateMode(s); m {
+	case TemplateModeNone, TemplateModeHelm, TemplateModeJinja, TemplateModeGoTemplate:
+		return m, nil
+	default:
+		return TemplateModeNone, fmt.Errorf("unknown template mode %q. it must be one of \"helm\", \"jinja\", or \"gotemplate\"", s)
+	}
+}
+
+func (m TemplateMode) delimiters() [][2]string {
+	switch m {
+	case TemplateModeHelm, TemplateModeGoTemplate:
+		return [][2]string{{"{{", "}}"}}
+	case TemplateModeJinja:
+		return [][2]string{{"{{", "}}"}, {"{%", "%}"}, {"{#", "#}"}}
+	default:
+		return nil
+	}
+}
+
+// NeutralizeTemplate replaces templating constructs in the given source with characters which
+// don't break YAML syntax. Line and column numbers of the other parts are preserved so that errors
+// are reported at the correct positions in the original source.
+//
+// A templating construct which occupies entire line (e.g. `{{- if .Values.foo }}`) is replaced with
+// spaces. Other constructs (e.g. `runs-on: {{ .Values.runner }}`) are replaced with the same number
+// of '_' characters so that they are parsed as plain strings. Expressions of GitHub Actions `${{ }}`
+// are not treated as templating constructs.
+func NeutralizeTemplate(src []byte, mode TemplateMode) []byte {
+	delims := mode.delimiters()
+	if len(delims) == 0 {
+		return src
+	}
+
+	out := make([]byte, len(src))
+	copy(out, src)
+
+	i := 0
+	for i < len(out) {
+		start, end := -1, ""
+		for _, d := range delims {
+			j := bytes.Index(out[i:], []byte(d[0]))
+			if j < 0 {
+				continue
+			}
+			j += i
+			// Skip ${{ }} placeholder of GitHub Actions expression
+			for j > 0 && out[j-1] == '$' && d[0] == "{{" {
+				k := bytes.Index(out[j+2:], []byte("{{"))
+				if k < 0 {
+					j = -1
+					break
+				}
+				j += k + 2
+			}
+			if j >= 0 && (start < 0 || j < start) {
+				start, end = j, d[1]
+			}
+		}
+		if start < 0 {
+			break
+		}
+
+		stop := bytes.Index(out[start+2:], []byte(end))
+		if stop < 0 {
+			stop = len(out)
+		} else {
+			stop += start + 2 + len(end)
+		}
+
+		// Check the construct occupies entire line
+		ls := bytes.LastIndexByte(out[:start], '\n') + 1
+		le := bytes.IndexByte(out[stop:], '\n')
+		if le < 0 {
+			le = len(out)
+		} else {
+			le += stop
+		}
+		fill := byte('_')
+		if strings.TrimSpace(string(out[ls:start])) == "" && strings.TrimSpace(string(out[stop:le])) == "" {
+			fill = ' '
+		}
+
+		for k := start; k < stop; k++ {
+			if out[k] != '\n' && out[k] != '\r' {
+				out[k] = fill
+			}
+		}
+		i = stop
+	}
+
+	return out
+}
@@ -0,0 +1,122 @@
+package actionlint
+
+import (
+	"io"
+	"testing"
+)
+
+func TestTemplateModeNeutralize(t *testing.T) {
+	tests := []struct {
+		what string
+		mode TemplateMode
+		in   string
+		want string
+	}{
+		{
+			what: "none",
+			mode: TemplateModeNone,
+			in:   "runs-on: {{ .Values.runner }}",
+			want: "runs-on: {{ .Values.runner }}",
+		},
+		{
+			what: "inline go template",
+			mode: TemplateModeGoTemplate,
+			in:   "runs-on: {{ .Values.runner }}",
+			want: "runs-on: ____________________",
+		},
+		{
+			what: "whole line helm template",
+			mode: TemplateModeHelm,
+			in:   "jobs:\n  {{- if .Values.test }}\n  test:",
+			want: "jobs:\n                        \n  test:",
+		},
+		{
+			what: "multi-line template",
+			mode: TemplateModeHelm,
+			in:   "{{/*\ncomment\n*/}}\non: push",
+			want: "    \n       \n    \non: push",
+		},
+		{
+			what: "github expression",
+			mode: TemplateModeGoTemplate,
+			in:   "run: echo ${{ github.sha }} {{ .x }}",
+			want: "run: echo ${{ github.sha }} ________",
+		},
+		{
+			what: "jinja statements and comments",
+			mode: TemplateModeJinja,
+			in:   "{% for x in xs %}\nname: {{ x }} {# comment #}",
+			want: "                 \nname: _______ _____________",
+		},
+		{
+			what: "jinja does not match go template actions only",
+			mode: TemplateModeGoTemplate,
+			in:   "name: {% x %}",
+			want: "name: {% x %}",
+		},
+		{
+			what: "unclosed template",
+			mode: TemplateModeGoTemplate,
+			in:   "name: foo {{ .x",
+			want: "name: foo _____",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			have := string(NeutralizeTemplate([]byte(tc.in), tc.mode))
+			if have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestTemplateModeParseError(t *testing.T) {
+	for _, s := range []string{"", "helm", "jinja", "gotemplate"} {
+		if _, err := ParseTemplateMode(s); err != nil {
+			t.Errorf("%q caused an error: %s", s, err)
+		}
+	}
+	if _, err := ParseTemplateMode("erb"); err == nil {
+		t.Error("no error occurred for unknown template mode")
+	}
+	if _, err := NewLinter(io.Discard, &LinterOptions{TemplateMode: "erb"}); err == nil {
+		t.Error("no error occurred for unknown template mode in linter options")
+	}
+}
+
+func TestTemplateModeLintMultipleDocuments(t *testing.T) {
+	src := `on: push
+jobs:
+{{- range .Values.jobs }}
+  {{ .name }}:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "{{ .msg }}"
+{{- end }}
+---
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ unknown.foo }}
+`
+	l, err := NewLinter(io.Discard, &LinterOptions{TemplateMode: TemplateModeHelm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("wanted exactly one error but got %v", errs)
+	}
+	if errs[0].Line != 15 || errs[0].Kind != "expression" {
+		t.Fatalf("unexpected error: %v", errs[0])
+	}
+}
@@ -0,0 +1,576 @@
+package actionlint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"github.com/mattn/go-shellwords"
+	"golang.org/x/sys/execabs"
+)
+
+var reverse = color.New(color.ReverseVideo)
+
+const tuiHelp = "j/k: move  enter: edit  f: apply fix  b: add to baseline  g: regroup  q: quit"
+
+// tuiRow is a row of the list in TUI. A row is a header of group or an error in the group.
+type tuiRow struct {
+	header string
+	err    *Error
+}
+
+// tui is a state of terminal UI to triage errors. Errors are listed in groups aggregated by Report.
+// Rendering and handling key inputs are separated from the terminal so that they can be tested.
+type tui struct {
+	errs         []*Error
+	by           ReportGroupBy
+	rows         []tuiRow
+	cursor       int // Index of the selected row. It always points to an error row
+	top          int // Index of the first row shown in the list
+	status       string
+	sources      map[string][]byte
+	baseline     *Baseline
+	baselinePath string
+	lint         func(path string) ([]*Error, error)
+	edit         func(path string, line, col int) error
+}
+
+func newTUI(errs []*Error, baseline *Baseline, baselinePath string, lint func(string) ([]*Error, error), edit func(string, int, int) error) *tui {
+	t := &tui{
+		errs:         errs,
+		by:           ReportGroupByFile,
+		sources:      map[string][]byte{},
+		baseline:     baseline,
+		baselinePath: baselinePath,
+		lint:         lint,
+		edit:         edit,
+	}
+	t.update()
+	return t
+}
+
+// update rebuilds the rows from the errors keeping the current selection as much as possible.
+func (t *tui) update() {
+	sel := t.selected()
+	prev := t.cursor
+
+	t.rows = t.rows[:0]
+	for _, g := range NewReport(t.errs, t.by).Groups {
+		t.rows = append(t.rows, tuiRow{header: fmt.Sprintf("%s (%s)", g.Key, pluralize(len(g.Errors), "error"))})
+		for _, e := range g.Errors {
+			t.rows = append(t.rows, tuiRow{err: e})
+		}
+	}
+
+	t.cursor = -1
+	for i, r := range t.rows {
+		if r.err != nil && r.err == sel {
+			t.cursor = i
+			break
+		}
+	}
+	if t.cursor < 0 {
+		t.cursor = prev
+		if t.cursor >= len(t.rows) {
+			t.cursor = len(t.rows) - 1
+		}
+		t.move(0)
+	}
+}
+
+func (t *tui) selected() *Error {
+	if t.cursor < 0 || t.cursor >= len(t.rows) {
+		return nil
+	}
+	return t.rows[t.cursor].err
+}
+
+// move moves the cursor by the delta skipping group headers. When the delta is zero, the cursor is
+// adjusted to the nearest error row.
+func (t *tui) move(delta int) {
+	if len(t.rows) == 0 {
+		t.cursor = 0
+		return
+	}
+	i := t.cursor + delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(t.rows) {
+		i = len(t.rows) - 1
+	}
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	for t.rows[i].err == nil {
+		if i+step < 0 || i+step >= len(t.rows) {
+			step = -step
+		}
+		i += step
+	}
+	t.cursor = i
+}
+
+// handle handles the key input. It returns true when the TUI should quit.
+func (t *tui) handle(key string) bool {
+	t.status = ""
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "j", "down":
+		t.move(1)
+	case "k", "up":
+		t.move(-1)
+	case "pgdown":
+		t.move(10)
+	case "pgup":
+		t.move(-10)
+	case "g":
+		if t.by == ReportGroupByFile {
+			t.by = ReportGroupByRule
+		} else {
+			t.by = ReportGroupByFile
+		}
+		t.top = 0
+		t.update()
+	case "enter", "e":
+		t.openEditor()
+	case "f":
+		t.applyFix()
+	case "b":
+		t.addToBaseline()
+	}
+	return false
+}
+
+func (t *tui) openEditor() {
+	e := t.selected()
+	if e == nil {
+		return
+	}
+	if err := t.edit(e.Filepath, e.Line, e.Column); err != nil {
+		t.status = err.Error()
+		return
+	}
+	t.relint(e.Filepath)
+}
+
+func (t *tui) applyFix() {
+	e := t.selected()
+	if e == nil {
+		return
+	}
+	if len(e.Suggestions) == 0 {
+		t.status = "no fix is available for this error"
+		return
+	}
+	s := e.Suggestions[0]
+	if s.NewFile != nil {
+		t.status = fmt.Sprintf("fix creating %q can be applied only by -fix", s.NewFile.Path)
+		return
+	}
+	src, err := os.ReadFile(e.Filepath)
+	if err != nil {
+		t.status = fmt.Sprintf("could not read %q: %s", e.Filepath, err)
+		return
+	}
+	fixed, err := s.Apply(src)
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	if err := os.WriteFile(e.Filepath, fixed, 0644); err != nil {
+		t.status = fmt.Sprintf("could not write fix to %q: %s", e.Filepath, err)
+		return
+	}
+	path, line, msg := e.Filepath, e.Line, s.Message
+	if t.relint(path) {
+		t.status = fmt.Sprintf("applied fix at %s:%d: %s", path, line, msg)
+	}
+}
+
+func (t *tui) addToBaseline() {
+	e := t.selected()
+	if e == nil {
+		return
+	}
+	t.baseline.AddWithSource(e, t.source(e.Filepath))
+	if err := t.baseline.WriteFile(t.baselinePath); err != nil {
+		t.status = err.Error()
+		return
+	}
+	errs := make([]*Error, 0, len(t.errs))
+	for _, err := range t.errs {
+		if !t.baseline.MatchWithSource(err, t.source(err.Filepath)) {
+			errs = append(errs, err)
+		}
+	}
+	t.errs = errs
+	t.update()
+	t.status = fmt.Sprintf("added to baseline %s", t.baselinePath)
+}
+
+// relint lints the file again and replaces the errors in the file with the new ones. It returns
+// false when linting the file failed.
+func (t *tui) relint(path string) bool {
+	delete(t.sources, path)
+	errs, err := t.lint(path)
+	if err != nil {
+		t.status = err.Error()
+		return false
+	}
+	all := make([]*Error, 0, len(t.errs)+len(errs))
+	for _, e := range t.errs {
+		if e.Filepath != path {
+			all = append(all, e)
+		}
+	}
+	for _, e := range errs {
+		if e.Filepath == path && !t.baseline.MatchWithSource(e, t.source(e.Filepath)) {
+			all = append(all, e)
+		}
+	}
+	sort.Stable(ByErrorPosition(all))
+	t.errs = all
+	t.update()
+	return true
+}
+
+func (t *tui) source(path string) []byte {
+	if b, ok := t.sources[path]; ok {
+		return b
+	}
+	b, _ := os.ReadFile(path) // Errors in remote reusable workflows cannot be read
+	t.sources[path] = b
+	return b
+}
+
+// render renders the whole screen with the given size. The screen consists of a header, a list of
+// errors, a preview of the selected error, a status line, and a help line.
+func (t *tui) render(w io.Writer, width, height int) {
+	preview := 8
+	if height < 20 {
+		preview = height / 3
+	}
+	list := height - preview - 4
+	if list < 1 {
+		list = 1
+	}
+
+	lines := make([]string, 0, height)
+	line := func(c *color.Color, s string) {
+		s = runewidth.Truncate(strings.ReplaceAll(s, "\t", "  "), width, "")
+		if c != nil {
+			s = c.Sprint(s)
+		}
+		lines = append(lines, s)
+	}
+
+	r := NewReport(t.errs, t.by)
+	line(bold, fmt.Sprintf("actionlint: %s in %s by %s (grouped by %s)", pluralize(r.Total, "error"), pluralize(r.Files, "file"), pluralize(r.Rules, "rule"), t.by))
+
+	if t.cursor < t.top {
+		t.top = t.cursor
+		if t.top > 0 && t.rows[t.top-1].err == nil {
+			t.top-- // Show the group header of the selected error
+		}
+	}
+	if t.cursor >= t.top+list {
+		t.top = t.cursor - list + 1
+	}
+	for i := t.top; i < t.top+list; i++ {
+		if i >= len(t.rows) {
+			if len(t.rows) == 0 && i == 0 {
+				line(green, "No error was found")
+			} else {
+				line(nil, "")
+			}
+			continue
+		}
+		row := t.rows[i]
+		if row.err == nil {
+			line(yellow, row.header)
+			continue
+		}
+		e := row.err
+		s := fmt.Sprintf("  %d:%d: %s [%s]", e.Line, e.Column, e.Message, e.Kind)
+		if t.by == ReportGroupByRule {
+			s = fmt.Sprintf("  %s:%d:%d: %s", e.Filepath, e.Line, e.Column, e.Message)
+		}
+		if i == t.cursor {
+			line(reverse, runewidth.FillRight(s, width))
+		} else {
+			line(nil, s)
+		}
+	}
+
+	line(gray, strings.Repeat("─", width))
+	start := len(lines)
+	if e := t.selected(); e != nil {
+		s := fmt.Sprintf("%s:%d:%d [%s]", e.Filepath, e.Line, e.Column, e.Kind)
+		if c := e.Code(); c != "" {
+			s += " " + c
+		}
+		line(yellow, s)
+		for _, l := range wrapTUIText(e.Message, width) {
+			line(bold, l)
+		}
+		t.renderSnippet(e, line)
+		for _, s := range e.Suggestions {
+			line(green, "fix: "+s.Message)
+		}
+	}
+	if len(lines) > start+preview {
+		lines = lines[:start+preview]
+	}
+	for len(lines) < start+preview {
+		lines = append(lines, "")
+	}
+
+	line(nil, t.status)
+	line(gray, tuiHelp)
+
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+	fmt.Fprint(w, strings.Join(lines, "\n"))
+}
+
+func wrapTUIText(s string, width int) []string {
+	ret := []string{}
+	l := ""
+	for _, w := range strings.Fields(s) {
+		if l == "" {
+			l = w
+			continue
+		}
+		if runewidth.StringWidth(l)+1+runewidth.StringWidth(w) > width {
+			ret = append(ret, l)
+			l = w
+			continue
+		}
+		l += " " + w
+	}
+	return append(ret, l)
+}
+
+func (t *tui) renderSnippet(e *Error, line func(*color.Color, string)) {
+	src := t.source(e.Filepath)
+	if len(src) == 0 || e.Line <= 0 {
+		return
+	}
+	lines := e.getLines(src, 1)
+	l, ok := lines[e.Line]
+	if !ok || utf8.RuneCountInString(l) < e.Column-1 {
+		return
+	}
+	indicator, _ := getIndicator(l, e.Column)
+	width := len(strconv.Itoa(e.Line + 1))
+	for n := e.Line - 1; n <= e.Line+1; n++ {
+		s, ok := lines[n]
+		if !ok {
+			continue
+		}
+		line(nil, fmt.Sprintf("%*d | %s", width, n, s))
+		if n == e.Line {
+			line(green, fmt.Sprintf("%s | %s", strings.Repeat(" ", width), indicator))
+		}
+	}
+}
+
+// run runs the main loop of TUI until "q" key is input.
+func (t *tui) run(term *tuiTerminal, in io.Reader, out io.Writer) error {
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l") // Enter alternate screen and hide cursor
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	r := bufio.NewReader(in)
+	for {
+		w, h := term.size()
+		var b bytes.Buffer
+		t.render(&b, w, h)
+		if _, err := out.Write(b.Bytes()); err != nil {
+			return err
+		}
+		k, err := readTUIKey(r)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if t.handle(k) {
+			return nil
+		}
+	}
+}
+
+// readTUIKey reads one key input from the terminal in raw mode. Special keys are returned as their
+// names like "up" or "enter".
+func readTUIKey(r *bufio.Reader) (string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		if b, _ := r.ReadByte(); b != '[' && b != 'O' {
+			return "esc", nil
+		}
+		b, _ := r.ReadByte()
+		switch b {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case '5', '6':
+			r.ReadByte() // Skip '~'
+			if b == '5' {
+				return "pgup", nil
+			}
+			return "pgdown", nil
+		}
+		return "", nil
+	default:
+		return string(rune(c)), nil
+	}
+}
+
+// tuiEditorCommand creates a command to open the file at the line with the editor. The editor is
+// a command line like "vim" or "code --reuse-window".
+func tuiEditorCommand(editor, path string, line, col int) (*execabs.Cmd, error) {
+	args, err := shellwords.Parse(editor)
+	if err != nil || len(args) == 0 {
+		return nil, fmt.Errorf("could not parse editor command %q", editor)
+	}
+	switch filepath.Base(args[0]) {
+	case "code", "code-insiders", "codium":
+		args = append(args, "--wait", "--goto", fmt.Sprintf("%s:%d:%d", path, line, col))
+	default:
+		// vi, vim, nvim, emacs, nano, micro, and many other editors accept this form
+		args = append(args, fmt.Sprintf("+%d", line), path)
+	}
+	return execabs.Command(args[0], args[1:]...), nil
+}
+
+// runTUI lints the given files (or the repository when no file is given) and starts the TUI to
+// triage the errors. It returns the errors which remain after the TUI quits.
+func (cmd *Command) runTUI(args []string, opts *LinterOptions) ([]*Error, error) {
+	if len(args) == 1 && args[0] == "-" {
+		return nil, fmt.Errorf("-tui cannot check workflow read from stdin")
+	}
+	in, ok := cmd.Stdin.(*os.File)
+	if !ok {
+		return nil, fmt.Errorf("-tui requires a terminal")
+	}
+
+	path := opts.Baseline
+	if path == "" {
+		p, err := NewProjects().At(".")
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, fmt.Errorf("no project was found to put baseline file. specify the file path with -baseline option")
+		}
+		path = filepath.Join(p.RootDir(), ".github", "actionlint-baseline.json")
+	}
+	baseline := &Baseline{}
+	if _, err := os.Stat(path); err == nil {
+		b, err := ReadBaselineFile(path)
+		if err != nil {
+			return nil, err
+		}
+		baseline = b
+	}
+
+	o := *opts
+	o.Baseline = ""
+	o.Format = ""
+	o.GroupBy = ReportGroupByNone
+	o.ColumnUnit = ColumnUnitRune // TUI renders snippets by counting characters
+	l, err := NewLinter(io.Discard, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []*Error
+	if len(args) == 0 {
+		errs, err = l.LintRepository("")
+	} else {
+		errs, err = l.LintFiles(args, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	srcs := map[string][]byte{}
+	filtered := make([]*Error, 0, len(errs))
+	for _, e := range errs {
+		src, ok := srcs[e.Filepath]
+		if !ok {
+			src, _ = os.ReadFile(e.Filepath) // Errors in remote reusable workflows cannot be read
+			srcs[e.Filepath] = src
+		}
+		if !baseline.MatchWithSource(e, src) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	term, err := openTUITerminal(in)
+	if err != nil {
+		return nil, err
+	}
+	defer term.restore()
+
+	edit := func(path string, line, col int) error {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		c, err := tuiEditorCommand(editor, path, line, col)
+		if err != nil {
+			return err
+		}
+		c.Stdin, c.Stdout, c.Stderr = in, cmd.Stdout, cmd.Stderr
+
+		if err := term.restore(); err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.Stdout, "\x1b[?25h\x1b[?1049l")
+		err = c.Run()
+		fmt.Fprint(cmd.Stdout, "\x1b[?1049h\x1b[?25l")
+		if rerr := term.raw(); rerr != nil {
+			return rerr
+		}
+		if err != nil {
+			return fmt.Errorf("could not open %q with editor %q: %w", path, editor, err)
+		}
+		return nil
+	}
+	lint := func(path string) ([]*Error, error) {
+		return l.LintFile(path, nil)
+	}
+
+	t := newTUI(filtered, baseline, path, lint, edit)
+	if err := t.run(term, in, cmd.Stdout); err != nil {
+		return nil, err
+	}
+	return t.errs, nil
+}
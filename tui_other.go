@@ -0,0 +1,26 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package actionlint
+
+import (
+	"errors"
+	"os"
+)
+
+type tuiTerminal struct{}
+
+func openTUITerminal(f *os.File) (*tuiTerminal, error) {
+	return nil, errors.New("-tui is not supported on this platform")
+}
+
+func (term *tuiTerminal) raw() error {
+	return nil
+}
+
+func (term *tuiTerminal) restore() error {
+	return nil
+}
+
+func (term *tuiTerminal) size() (int, int) {
+	return 80, 24
+}
@@ -0,0 +1,264 @@
+package actionlint
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func testTUIErrors(dir string) []*Error {
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	return []*Error{
+		{Filepath: a, Line: 1, Column: 5, Kind: "style", Message: "trailing spaces at end of line", Suggestions: []*Suggestion{
+			{Message: "remove trailing spaces", Start: &Pos{Line: 1, Col: 9}, End: &Pos{Line: 1, Col: 12}},
+		}},
+		{Filepath: a, Line: 3, Column: 1, Kind: "expression", Message: "error in a.yaml"},
+		{Filepath: b, Line: 2, Column: 3, Kind: "expression", Message: "error in b.yaml"},
+	}
+}
+
+func TestTUIMoveAndGroup(t *testing.T) {
+	errs := testTUIErrors("")
+	ui := newTUI(errs, &Baseline{}, "", nil, nil)
+
+	// Rows: a.yaml header, error 0, error 1, b.yaml header, error 2
+	if ui.selected() != errs[0] {
+		t.Fatal("first error is not selected:", ui.selected())
+	}
+	for _, tc := range []struct {
+		key  string
+		want *Error
+	}{
+		{"k", errs[0]},
+		{"j", errs[1]},
+		{"down", errs[2]}, // Group header is skipped
+		{"j", errs[2]},
+		{"up", errs[1]},
+		{"pgdown", errs[2]},
+		{"pgup", errs[0]},
+		{"unknown", errs[0]},
+	} {
+		if ui.handle(tc.key) {
+			t.Fatal("TUI quit by key", tc.key)
+		}
+		if have := ui.selected(); have != tc.want {
+			t.Fatalf("wanted %q selected after key %q but got %q", tc.want.Message, tc.key, have.Message)
+		}
+	}
+
+	ui.handle("j")
+	ui.handle("g")
+	if ui.by != ReportGroupByRule {
+		t.Fatal("errors were not grouped by rule:", ui.by)
+	}
+	if ui.selected() != errs[1] {
+		t.Fatal("selection was not kept after grouping:", ui.selected())
+	}
+	headers := []string{}
+	for _, r := range ui.rows {
+		if r.err == nil {
+			headers = append(headers, r.header)
+		}
+	}
+	if diff := cmp.Diff([]string{"expression (2 errors)", "style (1 error)"}, headers); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if !ui.handle("q") || !ui.handle("ctrl-c") {
+		t.Fatal("TUI did not quit")
+	}
+}
+
+func TestTUIApplyFix(t *testing.T) {
+	dir := t.TempDir()
+	errs := testTUIErrors(dir)
+	path := errs[0].Filepath
+	if err := os.WriteFile(path, []byte("on: push   \njobs:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	linted := []string{}
+	lint := func(p string) ([]*Error, error) {
+		linted = append(linted, p)
+		return []*Error{errs[1]}, nil
+	}
+	ui := newTUI(errs, &Baseline{}, "", lint, nil)
+
+	ui.handle("f")
+	if !strings.HasPrefix(ui.status, "applied fix at ") {
+		t.Fatal("unexpected status:", ui.status)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "on: push\njobs:\n" {
+		t.Fatalf("fix was not applied: %q", b)
+	}
+	if diff := cmp.Diff([]string{path}, linted); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]*Error{errs[1], errs[2]}, ui.errs, cmpopts.IgnoreUnexported(Error{})); diff != "" {
+		t.Fatal(diff)
+	}
+
+	ui.handle("f")
+	if ui.status != "no fix is available for this error" {
+		t.Fatal("unexpected status:", ui.status)
+	}
+}
+
+func TestTUIAddToBaseline(t *testing.T) {
+	dir := t.TempDir()
+	errs := testTUIErrors(dir)
+	path := filepath.Join(dir, "baseline.json")
+	ui := newTUI(errs, &Baseline{}, path, nil, nil)
+
+	ui.handle("j")
+	ui.handle("b")
+	if ui.status != "added to baseline "+path {
+		t.Fatal("unexpected status:", ui.status)
+	}
+	if diff := cmp.Diff([]*Error{errs[0], errs[2]}, ui.errs, cmpopts.IgnoreUnexported(Error{})); diff != "" {
+		t.Fatal(diff)
+	}
+	if ui.selected() != errs[2] {
+		t.Fatal("next error was not selected:", ui.selected())
+	}
+
+	bl, err := ReadBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bl.Entries) != 1 || !bl.Match(errs[1]) {
+		t.Fatal("error was not written to baseline:", bl.Entries)
+	}
+}
+
+func TestTUIOpenEditor(t *testing.T) {
+	errs := testTUIErrors("")
+	edited := ""
+	edit := func(p string, l, c int) error {
+		edited = p
+		if l != 2 || c != 3 {
+			t.Errorf("unexpected position %d:%d", l, c)
+		}
+		return nil
+	}
+	lint := func(p string) ([]*Error, error) {
+		return nil, nil
+	}
+	ui := newTUI(errs, &Baseline{}, "", lint, edit)
+	ui.handle("j")
+	ui.handle("j")
+	ui.handle("enter")
+	if edited != "b.yaml" {
+		t.Fatal("editor was not opened:", edited)
+	}
+	if diff := cmp.Diff(errs[:2], ui.errs, cmpopts.IgnoreUnexported(Error{})); diff != "" {
+		t.Fatal("errors were not updated after editing:", diff)
+	}
+	if ui.selected() != errs[1] {
+		t.Fatal("selection was not adjusted:", ui.selected())
+	}
+
+	ui.edit = func(string, int, int) error { return errors.New("editor failed") }
+	ui.handle("e")
+	if ui.status != "editor failed" {
+		t.Fatal("unexpected status:", ui.status)
+	}
+}
+
+func TestTUIRender(t *testing.T) {
+	defer func(saved bool) { color.NoColor = saved }(color.NoColor)
+	color.NoColor = true
+
+	dir := t.TempDir()
+	errs := testTUIErrors(dir)
+	if err := os.WriteFile(errs[0].Filepath, []byte("on: push   \njobs:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ui := newTUI(errs, &Baseline{}, "", nil, nil)
+
+	var b strings.Builder
+	ui.render(&b, 80, 24)
+	out := b.String()
+	lines := strings.Split(out, "\n")
+	if len(lines) != 24 {
+		t.Fatalf("wanted 24 lines but got %d lines: %q", len(lines), out)
+	}
+	for _, want := range []string{
+		"actionlint: 3 errors in 2 files by 2 rules (grouped by file)",
+		"a.yaml (2 errors)",
+		"  1:5: trailing spaces at end of line [style]",
+		":1:5 [style] AL1020",
+		"1 | on: push   ",
+		"  |     ^~~~",
+		"fix: remove trailing spaces",
+		tuiHelp,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not included in output: %q", want, out)
+		}
+	}
+
+	ui = newTUI(nil, &Baseline{}, "", nil, nil)
+	b.Reset()
+	ui.render(&b, 40, 10)
+	if out := b.String(); !strings.Contains(out, "No error was found") || strings.Count(out, "\n") != 9 {
+		t.Fatalf("unexpected output for no error: %q", out)
+	}
+}
+
+func TestTUIReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("j\x1b[A\x1b[B\x1b[5~\x1b[6~\r\x03q"))
+	want := []string{"j", "up", "down", "pgup", "pgdown", "enter", "ctrl-c", "q"}
+	have := []string{}
+	for {
+		k, err := readTUIKey(r)
+		if err != nil {
+			break
+		}
+		have = append(have, k)
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTUIEditorCommand(t *testing.T) {
+	for _, tc := range []struct {
+		editor string
+		want   []string
+	}{
+		{"vim", []string{"vim", "+3", "a.yaml"}},
+		{"emacs -nw", []string{"emacs", "-nw", "+3", "a.yaml"}},
+		{"/usr/local/bin/code -n", []string{"/usr/local/bin/code", "-n", "--wait", "--goto", "a.yaml:3:5"}},
+	} {
+		c, err := tuiEditorCommand(tc.editor, "a.yaml", 3, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tc.want, c.Args); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if _, err := tuiEditorCommand("", "a.yaml", 3, 5); err == nil {
+		t.Fatal("error did not occur for empty editor")
+	}
+}
+
+func TestTUIWrapText(t *testing.T) {
+	have := wrapTUIText("undefined variable \"foo\". available variables are \"env\"", 20)
+	want := []string{"undefined variable", "\"foo\". available", "variables are \"env\""}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package actionlint
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+type tuiTerminal struct {
+	fd    int
+	saved *unix.Termios
+}
+
+// openTUITerminal puts the terminal into raw mode so that key inputs are read without waiting for
+// a newline. The terminal must be restored with restore method.
+func openTUITerminal(f *os.File) (*tuiTerminal, error) {
+	fd := int(f.Fd())
+	t, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, fmt.Errorf("-tui requires a terminal: %w", err)
+	}
+	term := &tuiTerminal{fd, t}
+	if err := term.raw(); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+func (term *tuiTerminal) raw() error {
+	// Configure the same as cfmakeraw(3) except for output processing so that "\n" is still
+	// translated into "\r\n"
+	t := *term.saved
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(term.fd, ioctlSetTermios, &t); err != nil {
+		return fmt.Errorf("could not enable raw mode of terminal: %w", err)
+	}
+	return nil
+}
+
+func (term *tuiTerminal) restore() error {
+	if err := unix.IoctlSetTermios(term.fd, ioctlSetTermios, term.saved); err != nil {
+		return fmt.Errorf("could not restore terminal: %w", err)
+	}
+	return nil
+}
+
+// size returns the width and the height of the terminal.
+func (term *tuiTerminal) size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(term.fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
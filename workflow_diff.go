@@ -0,0 +1,554 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of changes reported by DiffWorkflows.
+const (
+	// WorkflowChangeJobAdded is a kind of change when a job is added.
+	WorkflowChangeJobAdded = "job-added"
+	// WorkflowChangeJobRemoved is a kind of change when a job is removed.
+	WorkflowChangeJobRemoved = "job-removed"
+	// WorkflowChangePermission is a kind of change when permissions of GITHUB_TOKEN of a job are
+	// escalated.
+	WorkflowChangePermission = "permission-escalated"
+	// WorkflowChangeSecret is a kind of change when a secret which was not used is newly used.
+	WorkflowChangeSecret = "secret-added"
+	// WorkflowChangeAction is a kind of change when a third-party action or reusable workflow which
+	// was not used is newly used.
+	WorkflowChangeAction = "action-added"
+	// WorkflowChangeTriggerAdded is a kind of change when an event to trigger the workflow is added.
+	WorkflowChangeTriggerAdded = "trigger-added"
+	// WorkflowChangeTriggerRemoved is a kind of change when an event to trigger the workflow is
+	// removed.
+	WorkflowChangeTriggerRemoved = "trigger-removed"
+	// WorkflowChangeTriggerChanged is a kind of change when the configuration of an event to trigger
+	// the workflow such as branch filters is changed.
+	WorkflowChangeTriggerChanged = "trigger-changed"
+	// WorkflowChangeActionUnpinned is a kind of change when a third-party action or reusable workflow
+	// is newly used with a version which is not pinned to a full-length commit SHA.
+	WorkflowChangeActionUnpinned = "action-unpinned"
+)
+
+// Risk categories of changes checked by the policy gate. See PolicyGateConfig.
+const (
+	// PolicyRiskWritePermission is a risk category of changes which newly give write permissions to
+	// GITHUB_TOKEN.
+	PolicyRiskWritePermission = "write-permission"
+	// PolicyRiskUnpinnedAction is a risk category of changes which newly use third-party actions or
+	// reusable workflows without pinning them to full-length commit SHAs.
+	PolicyRiskUnpinnedAction = "unpinned-action"
+	// PolicyRiskPullRequestTarget is a risk category of changes which introduce pull_request_target
+	// event.
+	PolicyRiskPullRequestTarget = "pull-request-target"
+)
+
+// allPolicyRisks is a list of all risk categories checked by the policy gate.
+var allPolicyRisks = []string{
+	PolicyRiskWritePermission,
+	PolicyRiskUnpinnedAction,
+	PolicyRiskPullRequestTarget,
+}
+
+// privilegedEvents is a set of events which run workflows with write permissions and secrets even if
+// they are triggered by pull requests from forks.
+var privilegedEvents = map[string]struct{}{
+	"pull_request_target": {},
+	"workflow_run":        {},
+	"issue_comment":       {},
+}
+
+// WorkflowChange is a semantic change between two versions of a workflow.
+type WorkflowChange struct {
+	// Kind is a kind of the change like "job-added". See WorkflowChange* constants.
+	Kind string `json:"kind"`
+	// Message is a human-readable description of the change.
+	Message string `json:"message"`
+	// File is a file path of the workflow version where the change is located. It is the path of
+	// the old version for removals and the path of the new version otherwise.
+	File string `json:"file"`
+	// Line is a line number of the change.
+	Line int `json:"line"`
+	// Column is a column number of the change.
+	Column int `json:"column"`
+	// Risk is a risk category of the change checked by the policy gate like "write-permission". It is
+	// empty when the change is not risky. See PolicyRisk* constants.
+	Risk string `json:"risk,omitempty"`
+	// action is the name of the third-party action of the change. It is used for matching the
+	// action to the patterns in the policy gate config.
+	action string
+}
+
+// WorkflowDiff is a report of semantic changes between two versions of a workflow. It is useful for
+// reviewing pull requests which modify workflows.
+type WorkflowDiff struct {
+	// Changes is a list of the changes.
+	Changes []*WorkflowChange `json:"changes"`
+}
+
+// workflowVersion is a version of the workflow compared by DiffWorkflows.
+type workflowVersion struct {
+	path     string
+	src      []byte
+	workflow *Workflow
+}
+
+func parseWorkflowVersion(path string, src []byte) (*workflowVersion, error) {
+	w, errs := Parse(src)
+	if w == nil {
+		msg := "unknown error"
+		if len(errs) > 0 {
+			msg = errs[0].Message
+		}
+		return nil, fmt.Errorf("could not parse workflow %q: %s", path, msg)
+	}
+	return &workflowVersion{path, src, w}, nil
+}
+
+// DiffWorkflows compares two versions of a workflow and returns the semantic changes between them.
+// Jobs added or removed, escalations of permissions, secrets newly used, third-party actions newly
+// used, and changes of triggers are reported. The paths are used for the positions of the changes.
+// It returns an error when some version cannot be parsed as a workflow at all.
+func DiffWorkflows(oldPath string, oldSrc []byte, newPath string, newSrc []byte) (*WorkflowDiff, error) {
+	o, err := parseWorkflowVersion(oldPath, oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseWorkflowVersion(newPath, newSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &WorkflowDiff{Changes: []*WorkflowChange{}}
+	d.diffTriggers(o, n)
+	d.diffJobs(o, n)
+	d.diffPermissions(o, n)
+	d.diffSecrets(o, n)
+	d.diffActions(o, n)
+	return d, nil
+}
+
+func (d *WorkflowDiff) add(kind string, v *workflowVersion, pos *Pos, format string, args ...interface{}) *WorkflowChange {
+	c := &WorkflowChange{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		File:    v.path,
+	}
+	if pos != nil {
+		c.Line, c.Column = pos.Line, pos.Col
+	}
+	d.Changes = append(d.Changes, c)
+	return c
+}
+
+// workflowTriggers returns the events at "on:" mapped to their canonical configurations and
+// positions. The configurations are compared as YAML values so that changes of any filter are
+// detected.
+func workflowTriggers(src []byte) (map[string]string, map[string]*Pos) {
+	configs, poses := map[string]string{}, map[string]*Pos{}
+	var root yaml.Node
+	if err := unmarshalYAML(src, &root); err != nil || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return configs, poses
+	}
+	m := root.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if k := m.Content[i]; k.Value != "on" {
+			continue
+		}
+		on := m.Content[i+1]
+		switch on.Kind {
+		case yaml.ScalarNode:
+			configs[on.Value] = ""
+			poses[on.Value] = &Pos{Line: on.Line, Col: on.Column}
+		case yaml.SequenceNode:
+			for _, e := range on.Content {
+				configs[e.Value] = ""
+				poses[e.Value] = &Pos{Line: e.Line, Col: e.Column}
+			}
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(on.Content); j += 2 {
+				k, v := on.Content[j], on.Content[j+1]
+				var c string
+				var val interface{}
+				if err := v.Decode(&val); err == nil && val != nil {
+					if b, err := json.Marshal(val); err == nil {
+						c = string(b)
+					}
+				}
+				configs[k.Value] = c
+				poses[k.Value] = &Pos{Line: k.Line, Col: k.Column}
+			}
+		}
+	}
+	return configs, poses
+}
+
+func (d *WorkflowDiff) diffTriggers(o, n *workflowVersion) {
+	oc, op := workflowTriggers(o.src)
+	nc, np := workflowTriggers(n.src)
+
+	for _, e := range sortedKeys(nc) {
+		c := nc[e]
+		prev, ok := oc[e]
+		if !ok {
+			note := ""
+			if _, ok := privilegedEvents[e]; ok {
+				note = ". note that this event runs the workflow with write permissions and secrets even for pull requests from forks"
+			}
+			c := d.add(WorkflowChangeTriggerAdded, n, np[e], "workflow is now triggered by %q event%s", e, note)
+			if e == "pull_request_target" {
+				c.Risk = PolicyRiskPullRequestTarget
+			}
+			continue
+		}
+		if c != prev {
+			d.add(WorkflowChangeTriggerChanged, n, np[e], "configuration of %q event to trigger the workflow was changed", e)
+		}
+	}
+	for _, e := range sortedKeys(oc) {
+		if _, ok := nc[e]; !ok {
+			d.add(WorkflowChangeTriggerRemoved, o, op[e], "workflow is no longer triggered by %q event", e)
+		}
+	}
+}
+
+func (d *WorkflowDiff) diffJobs(o, n *workflowVersion) {
+	for _, id := range sortedKeys(n.workflow.Jobs) {
+		if _, ok := o.workflow.Jobs[id]; !ok {
+			j := n.workflow.Jobs[id]
+			d.add(WorkflowChangeJobAdded, n, j.ID.Pos, "job %q was added", j.ID.Value)
+		}
+	}
+	for _, id := range sortedKeys(o.workflow.Jobs) {
+		if _, ok := n.workflow.Jobs[id]; !ok {
+			j := o.workflow.Jobs[id]
+			d.add(WorkflowChangeJobRemoved, o, j.ID.Pos, "job %q was removed", j.ID.Value)
+		}
+	}
+}
+
+// Levels of permissions of GITHUB_TOKEN
+const (
+	permissionNone = iota
+	permissionRead
+	permissionWrite
+)
+
+var permissionLevelNames = []string{"none", "read", "write"}
+
+// permissionLevels returns the levels of all permission scopes. When the permissions are not
+// configured, it returns nil since the default permissions configured in the repository settings are
+// used.
+func permissionLevels(p *Permissions) map[string]int {
+	if p == nil {
+		return nil
+	}
+	all := permissionNone
+	if p.All != nil {
+		switch p.All.Value {
+		case "read-all":
+			all = permissionRead
+		case "write-all":
+			all = permissionWrite
+		}
+	}
+	ret := make(map[string]int, len(allPermissionScopes))
+	for s := range allPermissionScopes {
+		ret[s] = all
+	}
+	for s, c := range p.Scopes {
+		if c.Value == nil {
+			continue
+		}
+		switch c.Value.Value {
+		case "read":
+			ret[s] = permissionRead
+		case "write":
+			ret[s] = permissionWrite
+		default:
+			ret[s] = permissionNone
+		}
+	}
+	return ret
+}
+
+// effectivePermissions returns the permissions of the job and the position where they are
+// configured.
+func effectivePermissions(w *Workflow, j *Job) (*Permissions, *Pos) {
+	if j.Permissions != nil {
+		return j.Permissions, j.Permissions.Pos
+	}
+	if w.Permissions != nil {
+		return w.Permissions, w.Permissions.Pos
+	}
+	return nil, j.ID.Pos
+}
+
+func (d *WorkflowDiff) diffPermissions(o, n *workflowVersion) {
+	for _, id := range sortedKeys(n.workflow.Jobs) {
+		nj := n.workflow.Jobs[id]
+		np, pos := effectivePermissions(n.workflow, nj)
+		nl := permissionLevels(np)
+
+		oj, ok := o.workflow.Jobs[id]
+		if !ok {
+			// The job was added
+			if nl == nil {
+				continue // The default permissions are not changed by the added job
+			}
+			ws := []string{}
+			for _, s := range sortedKeys(nl) {
+				if nl[s] == permissionWrite {
+					ws = append(ws, s)
+				}
+			}
+			if len(ws) > 0 {
+				c := d.add(WorkflowChangePermission, n, pos, "added job %q has write permissions to %s", nj.ID.Value, strings.Join(ws, ", "))
+				c.Risk = PolicyRiskWritePermission
+			}
+			continue
+		}
+
+		op, _ := effectivePermissions(o.workflow, oj)
+		ol := permissionLevels(op)
+		if ol == nil {
+			continue // The default permissions of the repository were used
+		}
+		if nl == nil {
+			c := d.add(WorkflowChangePermission, n, pos, "permissions of job %q were removed. the default permissions of GITHUB_TOKEN in the repository settings, which may be write permissions to all scopes, are now used", nj.ID.Value)
+			c.Risk = PolicyRiskWritePermission
+			continue
+		}
+		for _, s := range sortedKeys(nl) {
+			if nl[s] > ol[s] {
+				c := d.add(WorkflowChangePermission, n, pos, "permission of job %q to %q was escalated from %q to %q", nj.ID.Value, s, permissionLevelNames[ol[s]], permissionLevelNames[nl[s]])
+				if nl[s] == permissionWrite {
+					c.Risk = PolicyRiskWritePermission
+				}
+			}
+		}
+	}
+}
+
+// workflowSecrets returns names of secrets referenced in the workflow mapped to the first positions
+// where they are referenced. Names are in upper case since they are case-insensitive. Reusable
+// workflow calls with "secrets: inherit" are mapped to "inherit:{job}" keys.
+func workflowSecrets(v *workflowVersion) (map[string]*Pos, map[string]string) {
+	poses, names := map[string]*Pos{}, map[string]string{}
+	r := NewNameReport()
+	if err := r.AddWorkflow(v.path, v.src); err == nil {
+		for _, e := range r.Entries {
+			if e.Context != "secrets" {
+				continue
+			}
+			k := strings.ToUpper(e.Name)
+			if _, ok := poses[k]; !ok {
+				poses[k] = &Pos{Line: e.Line, Col: e.Column}
+				names[k] = e.Name
+			}
+		}
+	}
+	for id, j := range v.workflow.Jobs {
+		if j.WorkflowCall != nil && j.WorkflowCall.InheritSecrets {
+			k := "inherit:" + id
+			poses[k] = j.WorkflowCall.Uses.Pos
+			names[k] = j.ID.Value
+		}
+	}
+	return poses, names
+}
+
+func (d *WorkflowDiff) diffSecrets(o, n *workflowVersion) {
+	op, _ := workflowSecrets(o)
+	np, names := workflowSecrets(n)
+	for _, k := range sortedKeys(np) {
+		if _, ok := op[k]; ok {
+			continue
+		}
+		if strings.HasPrefix(k, "inherit:") {
+			d.add(WorkflowChangeSecret, n, np[k], "job %q now passes all secrets to the reusable workflow with \"secrets: inherit\"", names[k])
+		} else {
+			d.add(WorkflowChangeSecret, n, np[k], "secret %q is newly used", names[k])
+		}
+	}
+}
+
+// thirdPartyActionOf returns the name of the action or reusable workflow without its version like
+// "owner/repo/path" when it is provided by a third party. Local actions and actions provided by
+// GitHub are not third-party. The second return value is true when the version is pinned to a
+// full-length commit SHA or an image digest.
+func thirdPartyActionOf(uses *String) (string, bool, bool) {
+	if uses == nil || strings.Contains(uses.Value, "${{") {
+		return "", false, false
+	}
+	s, err := ParseActionSpec(uses.Value)
+	if err != nil {
+		return "", false, false
+	}
+	switch s.Kind {
+	case ActionSpecKindRepository:
+		o := strings.ToLower(s.Owner)
+		if o == "actions" || o == "github" {
+			return "", false, false
+		}
+		n := o + "/" + strings.ToLower(s.Repo)
+		if s.Path != "" {
+			n += "/" + s.Path
+		}
+		return n, s.IsCommitSHA(), true
+	case ActionSpecKindDocker:
+		i := s.Image
+		pinned := false
+		if j := strings.IndexByte(i, '@'); j >= 0 {
+			i = i[:j] // Remove digest
+			pinned = true
+		}
+		if j := strings.LastIndexByte(i, ':'); j > strings.LastIndexByte(i, '/') {
+			i = i[:j] // Remove tag
+		}
+		return "docker://" + i, pinned, true
+	default:
+		return "", false, false
+	}
+}
+
+// thirdPartyActionUse is a use of a third-party action or reusable workflow in a workflow.
+type thirdPartyActionUse struct {
+	name   string
+	pinned bool
+	pos    *Pos
+}
+
+// workflowActions returns third-party actions and reusable workflows used in the workflow. The keys
+// are "uses:" values and the values are their first uses.
+func workflowActions(w *Workflow) map[string]*thirdPartyActionUse {
+	ret := map[string]*thirdPartyActionUse{}
+	add := func(uses *String) {
+		n, pinned, ok := thirdPartyActionOf(uses)
+		if !ok {
+			return
+		}
+		if u, ok := ret[uses.Value]; !ok || uses.Pos.IsBefore(u.pos) {
+			ret[uses.Value] = &thirdPartyActionUse{n, pinned, uses.Pos}
+		}
+	}
+	for _, j := range w.Jobs {
+		if j.WorkflowCall != nil {
+			add(j.WorkflowCall.Uses)
+		}
+		for _, s := range j.Steps {
+			if a, ok := s.Exec.(*ExecAction); ok {
+				add(a.Uses)
+			}
+		}
+	}
+	return ret
+}
+
+func (d *WorkflowDiff) diffActions(o, n *workflowVersion) {
+	oa := workflowActions(o.workflow)
+	na := workflowActions(n.workflow)
+
+	olds := map[string]struct{}{}
+	for _, u := range oa {
+		olds[u.name] = struct{}{}
+	}
+	news := map[string]*Pos{}
+	for _, u := range na {
+		if p, ok := news[u.name]; !ok || u.pos.IsBefore(p) {
+			news[u.name] = u.pos
+		}
+	}
+	for _, a := range sortedKeys(news) {
+		if _, ok := olds[a]; !ok {
+			d.add(WorkflowChangeAction, n, news[a], "third-party action %q is newly used", a).action = a
+		}
+	}
+
+	for _, uses := range sortedKeys(na) {
+		u := na[uses]
+		if _, ok := oa[uses]; ok || u.pinned {
+			continue
+		}
+		pin := "a full-length commit SHA"
+		if strings.HasPrefix(u.name, "docker://") {
+			pin = "an image digest"
+		}
+		c := d.add(WorkflowChangeActionUnpinned, n, u.pos, "third-party action %q is used without pinning its version to %s", uses, pin)
+		c.Risk = PolicyRiskUnpinnedAction
+		c.action = u.name
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// PolicyViolations returns the changes which violate the policy gate configuration. When the
+// configuration is nil, changes of all risk categories violate the policy.
+func (d *WorkflowDiff) PolicyViolations(cfg *PolicyGateConfig) []*WorkflowChange {
+	deny := allPolicyRisks
+	var allowed []string
+	if cfg != nil {
+		if cfg.Deny != nil {
+			deny = cfg.Deny
+		}
+		allowed = cfg.AllowUnpinnedActions
+	}
+
+	vs := []*WorkflowChange{}
+	for _, c := range d.Changes {
+		if c.Risk == "" || !containsString(deny, c.Risk) {
+			continue
+		}
+		if c.Risk == PolicyRiskUnpinnedAction && matchActionPatterns(allowed, c.action) {
+			continue
+		}
+		vs = append(vs, c)
+	}
+	return vs
+}
+
+func matchActionPatterns(pats []string, action string) bool {
+	for _, p := range pats {
+		// Patterns were validated in `ParseConfig()`
+		if doublestar.MatchUnvalidated(strings.ToLower(p), action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Print prints the changes to the writer in the format. The format is one of "text" or "json".
+// "text" format prints one change per line like "path:line:col: message [kind]".
+func (d *WorkflowDiff) Print(w io.Writer, format string) error {
+	switch format {
+	case "text", "":
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "%s:%d:%d: %s [%s]\n", c.File, c.Line, c.Column, c.Message, c.Kind)
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return fmt.Errorf("could not encode workflow diff to JSON: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q of workflow diff. it must be one of \"text\" or \"json\"", format)
+	}
+}
@@ -0,0 +1,307 @@
+package actionlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowDiffTestdata(t *testing.T) {
+	dir := filepath.Join("testdata", "diff")
+	oldPath, newPath := filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.yaml")
+	oldSrc, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSrc, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := DiffWorkflows(oldPath, oldSrc, newPath, newSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		kind string
+		file string
+		line int
+		msg  string
+	}{
+		{WorkflowChangeTriggerAdded, newPath, 4, `"pull_request_target" event. note that`},
+		{WorkflowChangeTriggerChanged, newPath, 2, `configuration of "push" event`},
+		{WorkflowChangeTriggerRemoved, oldPath, 4, `no longer triggered by "pull_request" event`},
+		{WorkflowChangeJobAdded, newPath, 17, `job "deploy" was added`},
+		{WorkflowChangeJobRemoved, oldPath, 14, `job "test" was removed`},
+		{WorkflowChangePermission, newPath, 5, `job "build" to "contents" was escalated from "read" to "write"`},
+		{WorkflowChangePermission, newPath, 5, `job "build" to "issues" was escalated from "none" to "read"`},
+		{WorkflowChangePermission, newPath, 5, `added job "deploy" has write permissions to contents`},
+		{WorkflowChangeSecret, newPath, 16, `secret "NEW_TOKEN" is newly used`},
+		{WorkflowChangeSecret, newPath, 18, `job "deploy" now passes all secrets`},
+		{WorkflowChangeAction, newPath, 15, `"docker://alpine" is newly used`},
+		{WorkflowChangeAction, newPath, 14, `"evil/action" is newly used`},
+		{WorkflowChangeAction, newPath, 18, `"org/repo/.github/workflows/deploy.yml" is newly used`},
+		{WorkflowChangeActionUnpinned, newPath, 15, `"docker://alpine:3.8" is used without pinning its version to an image digest`},
+		{WorkflowChangeActionUnpinned, newPath, 14, `"evil/action@main" is used without pinning`},
+		{WorkflowChangeActionUnpinned, newPath, 13, `"foo/bar@v2" is used without pinning`},
+		{WorkflowChangeActionUnpinned, newPath, 18, `"org/repo/.github/workflows/deploy.yml@v1" is used without pinning`},
+	}
+
+	if len(d.Changes) != len(want) {
+		var b bytes.Buffer
+		d.Print(&b, "text")
+		t.Fatalf("wanted %d changes but got %d:\n%s", len(want), len(d.Changes), b.String())
+	}
+	for i, w := range want {
+		c := d.Changes[i]
+		if c.Kind != w.kind || c.File != w.file || c.Line != w.line || !strings.Contains(c.Message, w.msg) {
+			t.Errorf("change #%d: wanted %q at %s:%d containing %q but got %#v", i, w.kind, w.file, w.line, w.msg, c)
+		}
+	}
+}
+
+func TestWorkflowDiffPermissions(t *testing.T) {
+	testCases := []struct {
+		what string
+		old  string
+		new  string
+		want []string
+	}{
+		{
+			what: "not changed",
+			old:  "permissions:\n  contents: read\n",
+			new:  "permissions:\n  contents: read\n",
+		},
+		{
+			what: "reduced",
+			old:  "permissions: write-all\n",
+			new:  "permissions:\n  contents: read\n",
+		},
+		{
+			what: "read-all to write-all",
+			old:  "permissions: read-all\n",
+			new:  "permissions: write-all\n",
+			want: []string{`"actions" was escalated from "read" to "write"`},
+		},
+		{
+			what: "removed",
+			old:  "permissions: {}\n",
+			new:  "",
+			want: []string{`permissions of job "test" were removed`},
+		},
+		{
+			what: "default to explicit",
+			old:  "",
+			new:  "permissions: write-all\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			jobs := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			d, err := DiffWorkflows("old.yaml", []byte(tc.old+jobs), "new.yaml", []byte(tc.new+jobs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs := []string{}
+			for _, c := range d.Changes {
+				if c.Kind != WorkflowChangePermission {
+					t.Fatalf("unexpected change: %#v", c)
+				}
+				msgs = append(msgs, c.Message)
+			}
+			if len(tc.want) == 0 {
+				if len(msgs) > 0 {
+					t.Fatal("wanted no change but got", msgs)
+				}
+				return
+			}
+			all := strings.Join(msgs, "\n")
+			for _, w := range tc.want {
+				if !strings.Contains(all, w) {
+					t.Errorf("%q is not included in changes %q", w, msgs)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkflowDiffPolicyViolations(t *testing.T) {
+	before := []byte(`on: push
+permissions:
+  contents: read
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: foo/bar@v1
+`)
+	after := []byte(`on: [push, pull_request_target]
+permissions:
+  contents: write
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: foo/bar@v1
+      - uses: foo/baz@v1
+      - uses: trusted/action@main
+      - uses: pinned/action@0123456789abcdef0123456789abcdef01234567
+      - uses: docker://alpine@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+`)
+	d, err := DiffWorkflows("old.yaml", before, "new.yaml", after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		what string
+		cfg  *PolicyGateConfig
+		want []string
+	}{
+		{
+			what: "default",
+			cfg:  nil,
+			want: []string{
+				PolicyRiskPullRequestTarget + ":" + WorkflowChangeTriggerAdded,
+				PolicyRiskWritePermission + ":" + WorkflowChangePermission,
+				PolicyRiskUnpinnedAction + ":" + WorkflowChangeActionUnpinned,
+				PolicyRiskUnpinnedAction + ":" + WorkflowChangeActionUnpinned,
+			},
+		},
+		{
+			what: "deny only permissions",
+			cfg:  &PolicyGateConfig{Deny: []string{PolicyRiskWritePermission}},
+			want: []string{PolicyRiskWritePermission + ":" + WorkflowChangePermission},
+		},
+		{
+			what: "allow unpinned actions",
+			cfg:  &PolicyGateConfig{Deny: []string{PolicyRiskUnpinnedAction}, AllowUnpinnedActions: []string{"Trusted/*"}},
+			want: []string{PolicyRiskUnpinnedAction + ":" + WorkflowChangeActionUnpinned},
+		},
+		{
+			what: "deny nothing",
+			cfg:  &PolicyGateConfig{Deny: []string{}},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			have := []string{}
+			for _, c := range d.PolicyViolations(tc.cfg) {
+				have = append(have, c.Risk+":"+c.Kind)
+			}
+			if strings.Join(have, " ") != strings.Join(tc.want, " ") {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestWorkflowDiffNoChange(t *testing.T) {
+	src := []byte(`on:
+  push:
+    branches: [main]
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/local
+      - run: echo ${{ secrets.TOKEN }}
+`)
+	d, err := DiffWorkflows("old.yaml", src, "new.yaml", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Changes) > 0 {
+		t.Fatal("wanted no change but got", d.Changes)
+	}
+
+	var b bytes.Buffer
+	if err := d.Print(&b, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var j WorkflowDiff
+	if err := json.Unmarshal(b.Bytes(), &j); err != nil {
+		t.Fatal(err)
+	}
+	if j.Changes == nil || len(j.Changes) != 0 {
+		t.Fatalf("changes should be an empty array in JSON: %s", b.String())
+	}
+	if err := d.Print(&b, "yaml"); err == nil {
+		t.Fatal("error was not returned for unknown format")
+	}
+}
+
+func TestWorkflowDiffParseError(t *testing.T) {
+	ok := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n")
+	if _, err := DiffWorkflows("old.yaml", []byte("foo: [\n"), "new.yaml", ok); err == nil || !strings.Contains(err.Error(), `"old.yaml"`) {
+		t.Fatal("wanted parse error of old.yaml but got", err)
+	}
+}
+
+func TestCommandDiff(t *testing.T) {
+	dir := filepath.Join("testdata", "diff")
+	oldPath, newPath := filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.yaml")
+
+	var stdout, stderr bytes.Buffer
+	cmd := Command{Stdin: os.Stdin, Stdout: &stdout, Stderr: &stderr}
+	status := cmd.Main([]string{"actionlint", "diff", oldPath, newPath})
+	if status != ExitStatusSuccessProblemFound {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessProblemFound, status, stderr.String())
+	}
+	if want := newPath + `:14:15: third-party action "evil/action" is newly used [action-added]`; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	status = cmd.Main([]string{"actionlint", "diff", "-format", "json", oldPath, oldPath})
+	if status != ExitStatusSuccessNoProblem {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessNoProblem, status, stderr.String())
+	}
+	if want := `"changes": []`; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	cfg := filepath.Join(dir, "policy.yaml")
+	status = cmd.Main([]string{"actionlint", "diff", "-policy-gate", "-config-file", cfg, oldPath, newPath})
+	if status != ExitStatusSuccessProblemFound {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessProblemFound, status, stderr.String())
+	}
+	if have := strings.Count(stdout.String(), "\n"); have != 1 {
+		t.Fatalf("wanted only one violation but got %q", stdout.String())
+	}
+	if want := `"pull_request_target" event`; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("%q is not included in output %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	status = cmd.Main([]string{"actionlint", "diff", "-policy-gate", "-config-file", cfg, newPath, oldPath})
+	if status != ExitStatusSuccessNoProblem {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusSuccessNoProblem, status, stdout.String())
+	}
+
+	stderr.Reset()
+	status = cmd.Main([]string{"actionlint", "diff", oldPath, filepath.Join(dir, "this-file-does-not-exist.yaml")})
+	if status != ExitStatusFailure {
+		t.Fatalf("exit status should be %d but got %d: %s", ExitStatusFailure, status, stderr.String())
+	}
+
+	for _, args := range [][]string{
+		{"-format", "xml", oldPath, newPath},
+		{oldPath},
+	} {
+		stderr.Reset()
+		status := cmd.Main(append([]string{"actionlint", "diff"}, args...))
+		if status != ExitStatusInvalidCommandOption {
+			t.Fatalf("exit status should be %d with %v but got %d: %s", ExitStatusInvalidCommandOption, args, status, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package actionlint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:generate go run ./scripts/generate-workflow-keys ./workflow_keys.go
+
+// WorkflowSchemaURL is a URL of JSON schema of workflow files maintained by SchemaStore. Allowed keys
+// of sections in workflow files are imported from the schema.
+const WorkflowSchemaURL = "https://json.schemastore.org/github-workflow.json"
+
+// workflowSchemaSections maps section names in error messages of the parser to JSON pointers to the
+// objects in the workflow schema whose properties are the allowed keys of the sections. When
+// multiple pointers are listed, the keys are merged.
+var workflowSchemaSections = map[string][]string{
+	"workflow":    {""},
+	"job":         {"/definitions/normalJob", "/definitions/reusableWorkflowCallJob"},
+	"step":        {"/definitions/normalJob/properties/steps/items"},
+	"strategy":    {"/definitions/normalJob/properties/strategy"},
+	"container":   {"/definitions/container"},
+	"services":    {"/definitions/container"},
+	"credentials": {"/definitions/container/properties/credentials"},
+	"defaults":    {"/definitions/defaults"},
+	"run":         {"/definitions/defaults/properties/run"},
+	"concurrency": {"/definitions/concurrency"},
+	"environment": {"/definitions/environment"},
+	"runs-on":     {"/definitions/normalJob/properties/runs-on"},
+}
+
+// ParseWorkflowSchema parses JSON schema of workflow files like the one at WorkflowSchemaURL and
+// returns allowed keys of sections in workflow files. Keys of the returned map are section names
+// like "job" and "step" and the values are sorted key names.
+func ParseWorkflowSchema(b []byte) (map[string][]string, error) {
+	var root interface{}
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("could not parse workflow schema as JSON: %w", err)
+	}
+
+	secs := make([]string, 0, len(workflowSchemaSections))
+	for sec := range workflowSchemaSections {
+		secs = append(secs, sec)
+	}
+	sort.Strings(secs) // Make the error deterministic
+
+	ret := make(map[string][]string, len(secs))
+	for _, sec := range secs {
+		keys := map[string]struct{}{}
+		for _, ptr := range workflowSchemaSections[sec] {
+			v, err := lookupJSONSchema(root, root, ptr, 0)
+			if err != nil {
+				return nil, fmt.Errorf("could not find %q section in workflow schema: %w", sec, err)
+			}
+			collectJSONSchemaProperties(root, v, keys, 0)
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no property of %q section is defined in workflow schema", sec)
+		}
+		ks := make([]string, 0, len(keys))
+		for k := range keys {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		ret[sec] = ks
+	}
+	return ret, nil
+}
+
+// LoadWorkflowSchema reads the JSON schema of workflow files at the path and replaces the allowed
+// keys of sections in workflow files with the keys defined in the schema.
+func LoadWorkflowSchema(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read workflow schema: %w", err)
+	}
+	keys, err := ParseWorkflowSchema(b)
+	if err != nil {
+		return fmt.Errorf("invalid workflow schema at %q: %w", path, err)
+	}
+	AllWorkflowKeys = keys
+	return nil
+}
+
+// FutureSyntaxMode is a mode to treat keys which are defined in the workflow syntax but not supported
+// by this version of actionlint yet. Such keys are usually new features of GitHub Actions shipped
+// after the release of actionlint. They are reported as errors of "future-syntax" kind.
+type FutureSyntaxMode string
+
+const (
+	// FutureSyntaxModeError reports the keys as errors. This is the default mode.
+	FutureSyntaxModeError FutureSyntaxMode = "error"
+	// FutureSyntaxModeWarn prints the keys as warnings to the log output. They do not make the
+	// linting fail.
+	FutureSyntaxModeWarn FutureSyntaxMode = "warn"
+	// FutureSyntaxModeIgnore ignores the keys silently.
+	FutureSyntaxModeIgnore FutureSyntaxMode = "ignore"
+)
+
+// ParseFutureSyntaxMode parses the given string as FutureSyntaxMode. An empty string is parsed as
+// FutureSyntaxModeError.
+func ParseFutureSyntaxMode(s string) (FutureSyntaxMode, error) {
+	switch m := FutureSyntaxMode(s); m {
+	case "":
+		return FutureSyntaxModeError, nil
+	case FutureSyntaxModeError, FutureSyntaxModeWarn, FutureSyntaxModeIgnore:
+		return m, nil
+	default:
+		return FutureSyntaxModeError, fmt.Errorf("unknown mode %q for keys of future workflow syntax. it must be one of \"error\", \"warn\", or \"ignore\"", s)
+	}
+}
+
+// parseWorkflowSchemaData parses the workflow schema dataset.
+func parseWorkflowSchemaData(b []byte) (func(), error) {
+	keys, err := ParseWorkflowSchema(b)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		AllWorkflowKeys = keys
+	}, nil
+}
+
+// isKnownWorkflowKey returns true when the key is allowed in the section by the workflow schema.
+func isKnownWorkflowKey(sec, key string) bool {
+	for _, k := range AllWorkflowKeys[sec] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupJSONSchema finds the value at the JSON pointer. References with "$ref" on the way are
+// resolved. depth is the number of references resolved so far.
+func lookupJSONSchema(root, v interface{}, ptr string, depth int) (interface{}, error) {
+	if ptr == "" {
+		return resolveJSONSchemaRef(root, v, depth)
+	}
+	for _, tok := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		r, err := resolveJSONSchemaRef(root, v, depth)
+		if err != nil {
+			return nil, err
+		}
+		o, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value at %q is not an object", tok)
+		}
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		if v, ok = o[tok]; !ok {
+			return nil, fmt.Errorf("key %q is not found", tok)
+		}
+	}
+	return resolveJSONSchemaRef(root, v, depth)
+}
+
+// maxJSONSchemaRefDepth is the maximum depth of resolving "$ref" to avoid infinite recursion.
+const maxJSONSchemaRefDepth = 32
+
+func resolveJSONSchemaRef(root, v interface{}, depth int) (interface{}, error) {
+	o, ok := v.(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+	ref, ok := o["$ref"].(string)
+	if !ok {
+		return v, nil
+	}
+	if depth > maxJSONSchemaRefDepth {
+		return nil, errors.New("too deep \"$ref\" references")
+	}
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local reference is supported for \"$ref\" but got %q", ref)
+	}
+	// The referenced value is resolved recursively by lookupJSONSchema
+	return lookupJSONSchema(root, root, strings.TrimPrefix(ref, "#"), depth+1)
+}
+
+// collectJSONSchemaProperties collects property names of the object schema. Properties of the
+// alternatives in "oneOf", "anyOf", and "allOf" are also collected.
+func collectJSONSchemaProperties(root, v interface{}, keys map[string]struct{}, depth int) {
+	if depth > maxJSONSchemaRefDepth {
+		return
+	}
+	r, err := resolveJSONSchemaRef(root, v, 0)
+	if err != nil {
+		return
+	}
+	o, ok := r.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if ps, ok := o["properties"].(map[string]interface{}); ok {
+		for k := range ps {
+			keys[k] = struct{}{}
+		}
+	}
+	for _, c := range []string{"oneOf", "anyOf", "allOf"} {
+		if as, ok := o[c].([]interface{}); ok {
+			for _, a := range as {
+				collectJSONSchemaProperties(root, a, keys, depth+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package actionlint
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWorkflowSchemaParseOK(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("scripts", "generate-workflow-keys", "testdata", "ok.json"))
+	if err != nil {
+		panic(err)
+	}
+	have, err := ParseWorkflowSchema(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(AllWorkflowKeys, have); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestWorkflowSchemaParseError(t *testing.T) {
+	testCases := []struct {
+		what   string
+		schema string
+		want   string
+	}{
+		{
+			what:   "broken JSON",
+			schema: `{"properties":`,
+			want:   "could not parse workflow schema as JSON",
+		},
+		{
+			what:   "no definitions",
+			schema: `{"properties": {"on": {}}}`,
+			want:   "could not find \"concurrency\" section in workflow schema: key \"definitions\" is not found",
+		},
+		{
+			what:   "remote reference",
+			schema: `{"definitions": {"concurrency": {"$ref": "https://example.com/concurrency.json"}}}`,
+			want:   "only local reference is supported",
+		},
+		{
+			what:   "recursive reference",
+			schema: `{"definitions": {"concurrency": {"$ref": "#/definitions/concurrency"}}}`,
+			want:   "too deep \"$ref\" references",
+		},
+		{
+			what:   "no property",
+			schema: `{"definitions": {"concurrency": {"type": "object"}}}`,
+			want:   "no property of \"concurrency\" section",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			_, err := ParseWorkflowSchema([]byte(tc.schema))
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("wanted %q in error message %q", tc.want, err.Error())
+			}
+		})
+	}
+}
+
+// Keys supported by the parser must be defined in the workflow schema. Otherwise the table at
+// workflow_keys.go is outdated or the section is mapped to a wrong object in the schema.
+func TestWorkflowKeysCoverParser(t *testing.T) {
+	job := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n"
+	testCases := map[string]string{
+		"workflow":    "on: push\nthis-key-is-unknown: 1\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n",
+		"job":         job + "    this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"step":        job + "    steps:\n      - run: echo\n        this-key-is-unknown: 1\n",
+		"strategy":    job + "    strategy:\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"container":   job + "    container:\n      image: alpine\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"services":    job + "    services:\n      redis:\n        image: redis\n        this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"credentials": job + "    container:\n      image: alpine\n      credentials:\n        this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"defaults":    job + "    defaults:\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"run":         job + "    defaults:\n      run:\n        this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"concurrency": job + "    concurrency:\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"environment": job + "    environment:\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+		"runs-on":     "on: push\njobs:\n  test:\n    runs-on:\n      this-key-is-unknown: 1\n    steps:\n      - run: echo\n",
+	}
+
+	reExpected := regexp.MustCompile(`unexpected key "this-key-is-unknown" for "([^"]+)" section\. expected one of (.+)$|expected ("[^"]+") key for "([^"]+)" section`)
+	reKey := regexp.MustCompile(`"([^"]+)"`)
+	for sec, src := range testCases {
+		t.Run(sec, func(t *testing.T) {
+			_, errs := Parse([]byte(src))
+			var expected []string
+			for _, err := range errs {
+				m := reExpected.FindStringSubmatch(err.Message)
+				if m == nil {
+					continue
+				}
+				s, ks := m[1], m[2]
+				if s == "" {
+					s, ks = m[4], m[3]
+				}
+				if s != sec {
+					t.Fatalf("wanted %q section but got %q: %s", sec, s, err.Message)
+				}
+				for _, k := range reKey.FindAllStringSubmatch(ks, -1) {
+					expected = append(expected, k[1])
+				}
+			}
+			if len(expected) == 0 {
+				t.Fatalf("unexpected key error was not reported: %v", errs)
+			}
+			for _, k := range expected {
+				if !isKnownWorkflowKey(sec, k) {
+					t.Errorf("key %q is supported by the parser but not defined in %q section of the table: %v", k, sec, AllWorkflowKeys[sec])
+				}
+			}
+		})
+	}
+}
+
+func TestWorkflowSchemaKeyNotSupportedYet(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	AllWorkflowKeys = map[string][]string{
+		"step": append([]string{"snapshot"}, AllWorkflowKeys["step"]...),
+	}
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        snapshot: foo\n        this-key-is-unknown: 1\n"
+	_, errs := Parse([]byte(src))
+	if len(errs) != 2 {
+		t.Fatalf("wanted 2 errors but got %d: %v", len(errs), errs)
+	}
+	want := `key "snapshot" for "step" section is defined in the workflow syntax but not supported by this version of actionlint yet`
+	if !strings.Contains(errs[0].Message, want) {
+		t.Errorf("wanted %q in error message %q", want, errs[0].Message)
+	}
+	want = `unexpected key "this-key-is-unknown" for "step" section`
+	if !strings.Contains(errs[1].Message, want) {
+		t.Errorf("wanted %q in error message %q", want, errs[1].Message)
+	}
+}
+
+func TestWorkflowSchemaCommandFlag(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	b, err := os.ReadFile(filepath.Join("scripts", "generate-workflow-keys", "testdata", "ok.json"))
+	if err != nil {
+		panic(err)
+	}
+	b = bytes.Replace(b, []byte(`"id": { "type": "string" },`), []byte(`"id": { "type": "string" }, "snapshot": { "type": "string" },`), 1)
+	schema := filepath.Join(t.TempDir(), "github-workflow.json")
+	if err := os.WriteFile(schema, b, 0644); err != nil {
+		panic(err)
+	}
+	stdin := strings.NewReader("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        snapshot: foo\n")
+
+	var output bytes.Buffer
+	cmd := Command{Stdin: stdin, Stdout: &output, Stderr: &output}
+	status := cmd.Main([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-oneline", "-workflow-schema", schema, "-"})
+	if status != 1 {
+		t.Fatalf("exit status should be 1 but got %d: %s", status, output.String())
+	}
+	if out := output.String(); !strings.Contains(out, `key "snapshot" for "step" section is defined in the workflow syntax`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	output.Reset()
+	cmd = Command{Stdin: strings.NewReader(""), Stdout: &output, Stderr: &output}
+	status = cmd.Main([]string{"actionlint", "-workflow-schema", filepath.Join("testdata", "this-file-does-not-exist.json"), "-"})
+	if status != 3 {
+		t.Fatalf("exit status should be 3 but got %d: %s", status, output.String())
+	}
+	if out := output.String(); !strings.Contains(out, "could not read workflow schema") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestWorkflowSchemaParseFutureSyntaxMode(t *testing.T) {
+	for _, s := range []string{"", "error", "warn", "ignore"} {
+		if _, err := ParseFutureSyntaxMode(s); err != nil {
+			t.Errorf("mode %q should be valid: %v", s, err)
+		}
+	}
+	if _, err := ParseFutureSyntaxMode("fatal"); err == nil {
+		t.Error("mode \"fatal\" should be invalid")
+	}
+}
+
+func TestWorkflowSchemaFutureSyntaxMode(t *testing.T) {
+	restoreEmbeddedData(t)
+
+	AllWorkflowKeys = map[string][]string{
+		"step": append([]string{"snapshot"}, AllWorkflowKeys["step"]...),
+	}
+	src := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        snapshot: foo\n")
+
+	testCases := []struct {
+		mode FutureSyntaxMode
+		errs int
+		log  string
+	}{
+		{"", 1, ""},
+		{FutureSyntaxModeError, 1, ""},
+		{FutureSyntaxModeWarn, 0, "warning: test.yaml:7:9: key \"snapshot\" for \"step\" section is defined in the workflow syntax"},
+		{FutureSyntaxModeIgnore, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			var log bytes.Buffer
+			l, err := NewLinter(io.Discard, &LinterOptions{FutureSyntax: tc.mode, LogWriter: &log})
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs, err := l.Lint("test.yaml", src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) != tc.errs {
+				t.Fatalf("wanted %d errors but got %d: %v", tc.errs, len(errs), errs)
+			}
+			if tc.errs > 0 && errs[0].Kind != "future-syntax" {
+				t.Fatalf("wanted \"future-syntax\" error but got %v", errs[0])
+			}
+			if tc.log == "" {
+				if log.Len() > 0 {
+					t.Fatalf("nothing should be logged but got %q", log.String())
+				}
+			} else if !strings.Contains(log.String(), tc.log) {
+				t.Fatalf("wanted %q in log output %q", tc.log, log.String())
+			}
+		})
+	}
+
+	if _, err := NewLinter(io.Discard, &LinterOptions{FutureSyntax: "fatal"}); err == nil {
+		t.Fatal("invalid mode should cause an error")
+	}
+}